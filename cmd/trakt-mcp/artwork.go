@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/kofifort/trakt-mcp-go/internal/config"
+	"github.com/kofifort/trakt-mcp-go/internal/fanart"
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+	"github.com/kofifort/trakt-mcp-go/internal/tmdb"
+)
+
+// withArtwork wraps api to enrich search results from TMDB when
+// TMDB_API_KEY is set, and to take their artwork from the source
+// MCP_ARTWORK names. A source whose key is missing falls back to Trakt's
+// artwork.
+func withArtwork(api mcp.TraktAPI, cfg *config.Config, logger *slog.Logger) mcp.TraktAPI {
+	var tmdbClient *tmdb.Client
+	if key := cfg.Get("TMDB_API_KEY"); key != "" {
+		tmdbClient = tmdb.NewClient(key)
+		api = mcp.Enriched(api, tmdbClient)
+	}
+
+	source, err := mcp.ParseArtworkSource(cfg.Get("MCP_ARTWORK"))
+	if err != nil {
+		logger.Warn("invalid MCP_ARTWORK, using default", "error", err, "default", mcp.ArtworkTrakt)
+	}
+	switch source {
+	case mcp.ArtworkTMDB:
+		if tmdbClient == nil {
+			logger.Warn("MCP_ARTWORK is tmdb but TMDB_API_KEY isn't set, using Trakt's artwork")
+			break
+		}
+		api = mcp.WithArtwork(api, mcp.TMDBArtwork(tmdbClient))
+	case mcp.ArtworkFanart:
+		key := cfg.Get("FANART_API_KEY")
+		if key == "" {
+			logger.Warn("MCP_ARTWORK is fanart but FANART_API_KEY isn't set, using Trakt's artwork")
+			break
+		}
+		api = mcp.WithArtwork(api, mcp.FanartArtwork(fanart.NewClient(key)))
+	}
+	return api
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kofifort/trakt-mcp-go/internal/backup"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// restoreOptions are the flags and argument of the restore subcommand.
+type restoreOptions struct {
+	dryRun  bool
+	archive string // "" for the newest
+}
+
+func parseRestoreFlags(args []string) restoreOptions {
+	var opts restoreOptions
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "Only compare the backup with the account, changing nothing")
+	_ = fs.Parse(args)
+	opts.archive = fs.Arg(0)
+	return opts
+}
+
+// backupDir returns the archive directory: TRAKT_BACKUP_DIR, or the
+// default.
+func backupDir(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	return backup.DefaultDir()
+}
+
+// runBackup snapshots the signed-in user's account to a new archive in dir
+// for the backup subcommand, printing its path.
+func runBackup(ctx context.Context, client *trakt.Client, dir string) error {
+	if !client.IsAuthenticated() {
+		return errors.New("not signed in to Trakt: authenticate through the server first")
+	}
+	snap, err := backup.Take(ctx, client)
+	if err != nil {
+		return err
+	}
+	path, err := backup.Save(dir, snap)
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	fmt.Fprintf(os.Stderr, "backed up %d plays, %d ratings, %d watchlist entries, %d collection entries and %d lists\n",
+		len(snap.History), len(snap.Ratings), len(snap.Watchlist), len(snap.Collection), len(snap.Lists))
+	return nil
+}
+
+// runRestore puts back what the account lost since a backup, or with
+// -dry-run lists the differences, for the restore subcommand. The account
+// is backed up first.
+func runRestore(ctx context.Context, client *trakt.Client, dir string, opts restoreOptions) error {
+	if !client.IsAuthenticated() {
+		return errors.New("not signed in to Trakt: authenticate through the server first")
+	}
+	// On the command line an archive may be anywhere; only a bare name is
+	// looked up in the backup directory
+	path := opts.archive
+	if path == "" || filepath.Base(path) == path {
+		var err error
+		if path, err = backup.Find(dir, opts.archive); err != nil {
+			return err
+		}
+	}
+	snap, err := backup.Load(path)
+	if err != nil {
+		return err
+	}
+	current, err := backup.Take(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	diff := backup.Compare(snap, current)
+	if opts.dryRun {
+		printChanges("restore would put back", diff.Removed)
+		printChanges("restore would set back", diff.Changed)
+		printChanges("added since, kept", diff.Added)
+		if diff.Empty() {
+			fmt.Fprintf(os.Stderr, "no differences from %s\n", filepath.Base(path))
+		}
+		return nil
+	}
+	if len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		fmt.Fprintf(os.Stderr, "nothing to restore from %s\n", filepath.Base(path))
+		return nil
+	}
+
+	saved, err := backup.Save(dir, current)
+	if err != nil {
+		return fmt.Errorf("back up before restoring: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "saved the account as it was to %s\n", saved)
+
+	restored, err := backup.Restore(ctx, client, snap, current)
+	fmt.Fprintf(os.Stderr, "restored %d plays, %d ratings, %d watchlist entries, %d collection entries and %d list items from %s\n",
+		restored.Plays, restored.Ratings, restored.Watchlist, restored.Collection, restored.ListItems, filepath.Base(path))
+	return err
+}
+
+func printChanges(heading string, changes []backup.Change) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d):\n", heading, len(changes))
+	for _, c := range changes {
+		if c.Detail != "" {
+			fmt.Printf("  %s: %s, %s\n", c.Section, c.Title, c.Detail)
+		} else {
+			fmt.Printf("  %s: %s\n", c.Section, c.Title)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// startupCheckTimeout bounds the configuration checks run at startup.
+const startupCheckTimeout = 15 * time.Second
+
+// setupChecks returns the configuration checks run at startup and by the
+// diagnose tool. store and cacheDir are skipped when unset.
+func setupChecks(client *trakt.Client, store *trakt.FileTokenStore, cacheDir string) []mcp.Check {
+	checks := mcp.ClientChecks(client)
+	if store != nil {
+		checks = append(checks, mcp.Check{
+			Name: "token file",
+			Fix: fmt.Sprintf("Set TRAKT_TOKEN_PASSPHRASE to the passphrase the token was saved with, "+
+				"or delete %s and authenticate again.", store.Path),
+			Run: func(ctx context.Context) error {
+				_, err := store.Load()
+				return err
+			},
+		})
+	}
+	if cacheDir != "" {
+		checks = append(checks, mcp.Check{
+			Name: "cache directory",
+			Fix:  "Make TRAKT_CACHE_DIR writable, point it somewhere else, or unset it to cache in memory only.",
+			Run: func(ctx context.Context) error {
+				return checkWritable(cacheDir)
+			},
+		})
+	}
+	return checks
+}
+
+// checkWritable confirms a file can be created in dir.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// logStartupChecks runs the checks once and logs each failure with its fix.
+// Failures are warnings only; the diagnose tool reports them on demand.
+func logStartupChecks(ctx context.Context, logger *slog.Logger, checks []mcp.Check) {
+	ctx, cancel := context.WithTimeout(ctx, startupCheckTimeout)
+	defer cancel()
+
+	for _, r := range mcp.RunChecks(ctx, checks) {
+		if r.Err != nil {
+			logger.Warn("configuration check failed", "check", r.Name, "error", r.Err, "fix", r.Fix)
+		}
+	}
+}
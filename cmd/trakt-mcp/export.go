@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kofifort/trakt-mcp-go/internal/export"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// exportOptions are the flags of the export subcommand.
+type exportOptions struct {
+	format string
+	typ    string
+	output string // "" or "-" for stdout
+}
+
+func parseExportFlags(args []string) exportOptions {
+	var opts exportOptions
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.StringVar(&opts.format, "format", "", "csv, json or letterboxd (default: json if -o ends in .json, otherwise csv)")
+	fs.StringVar(&opts.typ, "type", "", "Export only shows or only movies (default: both)")
+	fs.StringVar(&opts.output, "o", "", "File to write (default: stdout)")
+	_ = fs.Parse(args)
+	return opts
+}
+
+// runExport writes the signed-in user's watch history, or their Letterboxd
+// diary, for the export subcommand and reports the row count on stderr.
+func runExport(ctx context.Context, client *trakt.Client, opts exportOptions) error {
+	if !client.IsAuthenticated() {
+		return errors.New("not signed in to Trakt: authenticate through the server first")
+	}
+	if opts.typ != "" && opts.typ != "shows" && opts.typ != "movies" {
+		return fmt.Errorf(`-type must be "shows" or "movies"`)
+	}
+	if opts.format == "" {
+		opts.format = export.FormatFor(opts.output)
+	}
+
+	out := os.Stdout
+	if opts.output != "" && opts.output != "-" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	n, err := export.Write(ctx, client, out, opts.format, opts.typ)
+	if err != nil {
+		return err
+	}
+	if out != os.Stdout {
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "exported %d rows\n", n)
+	return nil
+}
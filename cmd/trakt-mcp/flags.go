@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/kofifort/trakt-mcp-go/internal/config"
+)
+
+// settingFlags are command-line flags that override a setting, taking
+// precedence over both the environment and the config file. Host configs
+// can pass these as arguments instead of an environment block.
+var settingFlags = []struct {
+	name    string
+	env     string
+	usage   string
+	boolean bool
+}{
+	{name: "log-level", env: "LOG_LEVEL", usage: "Log level: debug, info, warn, or error"},
+	{name: "log-format", env: "LOG_FORMAT", usage: "Log format: json or text"},
+	{name: "log-color", env: "LOG_COLOR", usage: "Color text logs: true, false, or auto"},
+	{name: "timezone", env: "TZ", usage: "Timezone for dates in tool output"},
+	{name: "client-id", env: "TRAKT_CLIENT_ID", usage: "Trakt API client ID"},
+	{name: "client-secret", env: "TRAKT_CLIENT_SECRET", usage: "Trakt API client secret"},
+	{name: "api-url", env: "TRAKT_API_URL", usage: "Trakt API base URL"},
+	{name: "base-url", env: "TRAKT_API_URL", usage: "Alias for -api-url"},
+	{name: "oauth-url", env: "TRAKT_OAUTH_URL", usage: "Site serving the browser sign-in page"},
+	{name: "redirect-uri", env: "TRAKT_REDIRECT_URI", usage: "Callback for browser sign-in"},
+	{name: "token-file", env: "TRAKT_TOKEN_FILE", usage: "Where tokens are saved"},
+	{name: "token-passphrase", env: "TRAKT_TOKEN_PASSPHRASE", usage: "Encrypts the token file at rest"},
+	{name: "cache-dir", env: "TRAKT_CACHE_DIR", usage: "Directory for a persistent lookup cache"},
+	{name: "cache-ttl", env: "TRAKT_CACHE_TTL", usage: "How long lookups are cached in memory, 0 to disable"},
+	{name: "max-retries", env: "TRAKT_MAX_RETRIES", usage: "Retries for rate-limited requests"},
+	{name: "mirror-dir", env: "TRAKT_MIRROR_DIR", usage: "Directory for a local mirror of history, ratings, watchlist and collection"},
+	{name: "backup-dir", env: "TRAKT_BACKUP_DIR", usage: "Directory of account backups"},
+	{name: "queue-file", env: "TRAKT_QUEUE_FILE", usage: "Journal of writes made while Trakt was unreachable"},
+	{name: "tmdb-api-key", env: "TMDB_API_KEY", usage: "TMDB API key for artwork and overviews in search results"},
+	{name: "fanart-api-key", env: "FANART_API_KEY", usage: "fanart.tv API key for -artwork fanart"},
+	{name: "plex-account", env: "PLEX_ACCOUNT", usage: "Plex user whose scrobbles the Plex webhook logs (default: all)"},
+	{name: "kodi-url", env: "KODI_URL", usage: "Kodi web server to scrobble from, e.g. http://192.168.1.20:8080"},
+	{name: "kodi-username", env: "KODI_USERNAME", usage: "Kodi web server user name"},
+	{name: "kodi-password", env: "KODI_PASSWORD", usage: "Kodi web server password"},
+	{name: "strict", env: "MCP_STRICT", usage: "Require the full initialize handshake before tool calls", boolean: true},
+	{name: "tool-timeout", env: "MCP_TOOL_TIMEOUT", usage: "Maximum duration of a single tool call"},
+	{name: "max-concurrency", env: "MCP_MAX_CONCURRENCY", usage: "Maximum simultaneous tool calls, 0 for unlimited"},
+	{name: "tools", env: "MCP_TOOLS", usage: "Comma-separated allowlist of tools to expose"},
+	{name: "read-only", env: "MCP_READ_ONLY", usage: "Leave out tools that modify the Trakt account", boolean: true},
+	{name: "confirm-destructive", env: "MCP_CONFIRM_DESTRUCTIVE", usage: "Make destructive tools preview and ask for a confirmation token first", boolean: true},
+	{name: "output-style", env: "MCP_OUTPUT_STYLE", usage: "Tool output style: rich (emoji and Markdown) or plain"},
+	{name: "max-response-size", env: "MCP_MAX_RESPONSE_SIZE", usage: "Bytes of text a list tool returns before continuing with a cursor, 0 for unlimited"},
+	{name: "language", env: "MCP_LANGUAGE", usage: "Language of tool output: en, de or es"},
+	{name: "template-dir", env: "MCP_TEMPLATE_DIR", usage: "Directory of <tool>.tmpl output templates"},
+	{name: "trace", env: "MCP_TRACE", usage: "Log every JSON-RPC message to a trace file", boolean: true},
+	{name: "trace-file", env: "MCP_TRACE_FILE", usage: "Trace file path"},
+	{name: "artwork", env: "MCP_ARTWORK", usage: "Source of search result artwork: trakt, tmdb or fanart"},
+	{name: "admin", env: "MCP_ADMIN_ADDR", usage: "Listen address for /healthz, /readyz and /metrics, e.g. :9090"},
+	{name: "new-episode-interval", env: "MCP_NEW_EPISODE_INTERVAL", usage: "How often HTTP transports check for newly aired episodes, 0 to disable"},
+	{name: "feed-token", env: "MCP_FEED_TOKEN", usage: "Secret path segment of the feeds HTTP transports serve"},
+	{name: "webhook-token", env: "MCP_WEBHOOK_TOKEN", usage: "Secret token query parameter of the webhooks HTTP transports receive"},
+	{name: "event-webhook-url", env: "MCP_EVENT_WEBHOOK_URL", usage: "URL to post each change made to the Trakt account to"},
+	{name: "event-webhook-secret", env: "MCP_EVENT_WEBHOOK_SECRET", usage: "Secret signing the posts to the event webhook"},
+}
+
+// defineSettingFlags registers settingFlags on fs.
+func defineSettingFlags(fs *flag.FlagSet) {
+	for _, f := range settingFlags {
+		if f.boolean {
+			fs.Bool(f.name, false, f.usage)
+		} else {
+			fs.String(f.name, "", f.usage)
+		}
+	}
+}
+
+// applySettingFlags copies the setting flags given on the command line
+// into cfg. Flags left unset don't override anything.
+func applySettingFlags(fs *flag.FlagSet, cfg *config.Config) {
+	envs := make(map[string]string, len(settingFlags))
+	for _, f := range settingFlags {
+		envs[f.name] = f.env
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if env, ok := envs[f.Name]; ok {
+			cfg.Set(env, f.Value.String())
+		}
+	})
+}
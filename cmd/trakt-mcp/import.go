@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/importer"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// importOptions are the flags and argument of the import subcommand.
+type importOptions struct {
+	source string
+	dryRun bool
+	path   string
+}
+
+func parseImportFlags(args []string) importOptions {
+	var opts importOptions
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.StringVar(&opts.source, "from", "", "Service the export is from: "+strings.Join(importer.Sources(), ", "))
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "Only report what would be imported, changing nothing")
+	_ = fs.Parse(args)
+	opts.path = fs.Arg(0)
+	return opts
+}
+
+// runImport adds the plays and ratings in another service's export to the
+// account, or with -dry-run reports what it would add, for the import
+// subcommand. What couldn't be matched to Trakt is listed.
+func runImport(ctx context.Context, client *trakt.Client, opts importOptions) error {
+	if !client.IsAuthenticated() {
+		return errors.New("not signed in to Trakt: authenticate through the server first")
+	}
+	if opts.source == "" || opts.path == "" {
+		return fmt.Errorf("usage: trakt-mcp import -from %s [-dry-run] file", strings.Join(importer.Sources(), "|"))
+	}
+	f, err := os.Open(opts.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	exp, err := importer.Parse(opts.source, f)
+	if err != nil {
+		return err
+	}
+
+	r, err := importer.Import(ctx, client, exp, opts.dryRun)
+	if len(r.Unmatched) > 0 {
+		fmt.Printf("not found on Trakt (%d):\n", len(r.Unmatched))
+		for _, u := range r.Unmatched {
+			fmt.Printf("  %s: %s\n", u.Title, u.Reason)
+		}
+	}
+	verb := "imported"
+	if opts.dryRun {
+		verb = "would import"
+	}
+	fmt.Fprintf(os.Stderr, "%s %d plays and %d ratings from %s; %d plays were already in the history\n",
+		verb, r.Plays, r.Ratings, importer.Name(opts.source), r.Skipped)
+	return err
+}
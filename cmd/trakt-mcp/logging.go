@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+// ANSI colors for log levels in text output.
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorGray   = "\x1b[90m"
+)
+
+// newLogger builds the stderr logger. format is "json" (the default) or
+// "text". color applies to text output: "true" or "false" force it, and
+// anything else enables it when w is a terminal and NO_COLOR isn't set.
+// Passing a *slog.LevelVar as level lets the level change later.
+func newLogger(w io.Writer, format, color string, level slog.Leveler) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		if useColor(w, color) {
+			w = colorWriter{w: w}
+		}
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return slog.New(slog.NewJSONHandler(w, opts)), fmt.Errorf("unknown log format %q, using json", format)
+	}
+}
+
+func useColor(w io.Writer, setting string) bool {
+	if b, err := strconv.ParseBool(setting); err == nil {
+		return b
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorWriter tints each line from slog's text handler by its level. The
+// handler writes every record with a single Write call, so each call is
+// one complete line.
+type colorWriter struct {
+	w io.Writer
+}
+
+func (c colorWriter) Write(p []byte) (int, error) {
+	var color string
+	switch {
+	case bytes.Contains(p, []byte(" level=ERROR")):
+		color = colorRed
+	case bytes.Contains(p, []byte(" level=WARN")):
+		color = colorYellow
+	case bytes.Contains(p, []byte(" level=DEBUG")):
+		color = colorGray
+	default:
+		return c.w.Write(p)
+	}
+
+	line := make([]byte, 0, len(p)+len(color)+len(colorReset))
+	line = append(line, color...)
+	line = append(line, bytes.TrimSuffix(p, []byte("\n"))...)
+	line = append(line, colorReset+"\n"...)
+	if _, err := c.w.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -1,41 +1,346 @@
 // trakt-mcp is an MCP server for Trakt.tv integration with Claude.
 //
 // It communicates over stdio using JSON-RPC 2.0 per the MCP specification.
+// Pass -transport=sse to serve the legacy HTTP+SSE transport on -addr instead,
+// or -transport=ws for the experimental WebSocket transport at /ws. On stdio,
+// newline-delimited and Content-Length framed messages are both detected
+// automatically; pass -framing=line or -framing=content-length to force one.
+//
+// At startup the configuration is checked (credentials, sign-in, Trakt
+// connectivity, token file and cache directory) and problems are logged as
+// warnings; the diagnose tool re-runs the same checks on demand.
+//
+// Run "trakt-mcp version" to print the build's version, commit and
+// supported protocol revisions, or "trakt-mcp tools [-json]" to list the
+// tools the server offers, with their arguments, without starting it.
+// "trakt-mcp export [-format csv|json|letterboxd] [-type shows|movies] [-o file]"
+// writes the signed-in user's complete watch history, to stdout by default,
+// and "trakt-mcp import -from simkl|tvtime [-dry-run] file" adds the plays and
+// ratings in another service's export to it.
+// "trakt-mcp backup" snapshots the account to a new archive in the backup
+// directory, and "trakt-mcp restore [-dry-run] [archive]" puts back what
+// the account lost since the newest or the named one. On Linux,
+// "trakt-mcp scrobbled [-players mpv,vlc] [-interval 15s]" scrobbles what
+// MPRIS media players play, read through playerctl, until interrupted.
+//
 // Configure with environment variables:
 //   - TRAKT_CLIENT_ID: Your Trakt API client ID
 //   - TRAKT_CLIENT_SECRET: Your Trakt API client secret
 //   - TRAKT_ACCESS_TOKEN: OAuth access token (after authentication)
 //   - TRAKT_REFRESH_TOKEN: OAuth refresh token (optional)
+//   - TRAKT_API_URL: API base URL, e.g. a staging endpoint or proxy (default: https://api.trakt.tv)
+//   - TRAKT_OAUTH_URL: Site serving the browser sign-in page (default: https://trakt.tv)
+//   - TRAKT_REDIRECT_URI: Callback for browser sign-in (default: http://127.0.0.1:8976/callback)
+//   - TRAKT_TOKEN_FILE: Where tokens are saved (default: trakt-mcp/token.json in the user's config directory)
+//   - TRAKT_TOKEN_PASSPHRASE: Encrypts the token file at rest (optional)
+//   - TRAKT_CACHE_TTL: How long search and metadata lookups are cached in memory (default: 15m, 0 to disable)
+//   - TRAKT_CACHE_DIR: Directory for a persistent lookup cache that survives restarts (optional)
+//   - TRAKT_MAX_RETRIES: Retries for rate-limited (429) requests, honoring Retry-After (default: 3)
+//   - TRAKT_MIRROR_DIR: Directory for a local mirror of history, ratings, watchlist and collection that analytics tools read instead of the API (optional)
+//   - TRAKT_BACKUP_DIR: Directory of account backups made by the backup tool and subcommand (default: trakt-mcp/backups in the user's config directory)
+//   - TRAKT_QUEUE_FILE: Journal of watches and ratings logged while Trakt was unreachable, replayed once it's back (default: trakt-mcp/queue.jsonl in the user's config directory)
+//   - TMDB_API_KEY: TMDB API key or read access token, adding TMDB's posters, backdrops, taglines and overviews to search results (optional)
+//   - FANART_API_KEY: fanart.tv API key, for MCP_ARTWORK=fanart (optional)
+//   - PLEX_ACCOUNT: Plex user whose scrobbles the Plex webhook logs (default: every user of the Plex server)
+//   - KODI_URL: Kodi web server whose playback is scrobbled to Trakt, such as http://192.168.1.20:8080 (optional)
+//   - KODI_USERNAME, KODI_PASSWORD: Credentials of the Kodi web server (optional)
+//   - TZ: Timezone for dates in tool output (default: system timezone)
+//
+// Tokens obtained through the authenticate tool, and any refreshed tokens,
+// are saved to the token file and take precedence over the environment on
+// later runs. Access tokens are refreshed
+// automatically shortly before they expire or when Trakt rejects them.
+//
+// Server settings:
+//   - MCP_STRICT: Set to "true" to require the full initialize handshake before tool calls
+//   - MCP_TOOL_TIMEOUT: Maximum duration of a single tool call (default: 60s)
+//   - MCP_MAX_CONCURRENCY: Maximum simultaneous tool calls (default: 4, 0 for unlimited)
+//   - MCP_MAX_RESPONSE_SIZE: Bytes of text a list tool returns before cutting off with a continuation cursor (default: 50000, 0 for unlimited)
+//   - MCP_TOOLS: Comma-separated allowlist of tools to expose (default: all)
+//   - MCP_READ_ONLY: Set to "true" to leave out tools that modify the Trakt account, such as log_watch
+//   - MCP_CONFIRM_DESTRUCTIVE: Set to "true" to make destructive tools, such as undo_last_watch and restore, return a preview and a one-time token that must be passed back to act
+//   - MCP_OUTPUT_STYLE: rich, or plain for output without emoji and Markdown (default: rich)
+//   - MCP_LANGUAGE: Language of tool output messages: en, de or es (default: en)
+//   - MCP_TEMPLATE_DIR: Directory of <tool>.tmpl templates that reshape tool output (default: trakt-mcp/templates in the user's config directory)
+//   - MCP_TRACE: Set to "1" to log every JSON-RPC message (credentials redacted) to a trace file
+//   - MCP_TRACE_FILE: Trace file path (default: trakt-mcp-trace.log in the temp directory)
+//   - MCP_ARTWORK: Where search results' artwork comes from: trakt, tmdb (needs TMDB_API_KEY) or fanart (needs FANART_API_KEY) (default: trakt)
+//   - MCP_ADMIN_ADDR: Listen address for the /healthz, /readyz and /metrics admin endpoints (optional)
+//   - MCP_NEW_EPISODE_INTERVAL: How often the sse and ws transports check the calendar to notify clients of newly aired episodes (default: 15m, 0 to disable)
+//   - MCP_FEED_TOKEN: Secret path segment of the feeds the sse and ws transports serve, such as /feeds/<token>/calendar.ics (optional; feeds are off without it)
+//   - MCP_WEBHOOK_TOKEN: Secret token query parameter of the webhooks the sse and ws transports receive, such as /webhooks/plex?token=<token> (optional; webhooks are off without it)
+//   - MCP_EVENT_WEBHOOK_URL: URL each change made to the Trakt account, such as a watch logged or a rating added, is posted to as JSON (optional)
+//   - MCP_EVENT_WEBHOOK_SECRET: Signs the event webhook's posts with an HMAC-SHA256 in the X-Trakt-MCP-Signature-256 header (optional)
+//   - LOG_LEVEL: debug, info, warn, or error (default: info)
+//   - LOG_FORMAT: json, or text for human-readable logs when debugging (default: json)
+//   - LOG_COLOR: Color text logs by level: true, false, or auto to color only on a terminal (default: auto)
+//
+// Any of these except the access and refresh tokens can instead be set in a
+// config file, trakt-mcp/config.toml in the user's config directory or the
+// path in -config or TRAKT_MCP_CONFIG. Environment variables override the
+// file; see package config for the format.
+//
+// Each setting also has a command-line flag, such as -client-id or
+// -tool-timeout, which overrides both; run with -h for the list.
+//
+// Send SIGHUP to reload the config file and the saved token without
+// dropping the session. Credentials, cache TTL, retries, log level and the
+// MCP_STRICT, MCP_CONFIRM_DESTRUCTIVE, MCP_TOOL_TIMEOUT, MCP_MAX_CONCURRENCY,
+// MCP_MAX_RESPONSE_SIZE, MCP_TOOLS, MCP_OUTPUT_STYLE, MCP_LANGUAGE and
+// MCP_TEMPLATE_DIR settings take effect immediately, and output templates are re-read; clients are
+// notified if the exposed tools change. The rest need a restart.
 package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log/slog"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"syscall"
+	"time"
 
+	"github.com/kofifort/trakt-mcp-go/internal/config"
+	"github.com/kofifort/trakt-mcp-go/internal/kodi"
 	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+	"github.com/kofifort/trakt-mcp-go/internal/mirror"
+	"github.com/kofifort/trakt-mcp-go/internal/queue"
 	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+	"github.com/kofifort/trakt-mcp-go/internal/webhook"
 )
 
 func main() {
+	transport := flag.String("transport", "stdio", "Transport to serve: stdio, sse, or ws")
+	addr := flag.String("addr", "localhost:8080", "Listen address for HTTP transports")
+	framingFlag := flag.String("framing", "auto", "Stdio message framing: auto, line, or content-length")
+	configPath := flag.String("config", "", "Config file path (default: trakt-mcp/config.toml in the user's config directory)")
+	defineSettingFlags(flag.CommandLine)
+	flag.Parse()
+
+	var listTools, toolsJSON, exportHistory, importHistory, backupAccount, restoreAccount, scrobbleDaemon bool
+	var exportOpts exportOptions
+	var importOpts importOptions
+	var restoreOpts restoreOptions
+	var scrobbledOpts scrobbledOptions
+	switch cmd := flag.Arg(0); cmd {
+	case "":
+	case "version":
+		printVersion(os.Stdout)
+		return
+	case "tools":
+		toolsFlags := flag.NewFlagSet("tools", flag.ExitOnError)
+		toolsFlags.BoolVar(&toolsJSON, "json", false, "Print tools as JSON, in the shape of a tools/list result")
+		_ = toolsFlags.Parse(flag.Args()[1:])
+		listTools = true
+	case "export":
+		exportOpts = parseExportFlags(flag.Args()[1:])
+		exportHistory = true
+	case "import":
+		importOpts = parseImportFlags(flag.Args()[1:])
+		importHistory = true
+	case "backup":
+		backupAccount = true
+	case "restore":
+		restoreOpts = parseRestoreFlags(flag.Args()[1:])
+		restoreAccount = true
+	case "scrobbled":
+		scrobbledOpts = parseScrobbledFlags(flag.Args()[1:])
+		scrobbleDaemon = true
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", cmd)
+		os.Exit(2)
+	}
+
+	// Settings come from flags, then the environment, then the config file
+	cfg, cfgErr := loadConfig(*configPath)
+	if cfgErr == nil {
+		applySettingFlags(flag.CommandLine, cfg)
+	}
+
 	// Configure structured logging to stderr (stdout is for MCP protocol)
-	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
-		Level: getLogLevel(),
-	}))
+	// The level is a LevelVar so a reload can change it
+	var level slog.LevelVar
+	level.Set(getLogLevel(cfg.Get("LOG_LEVEL")))
+	logger, logErr := newLogger(os.Stderr, cfg.Get("LOG_FORMAT"), cfg.Get("LOG_COLOR"), &level)
+	if logErr != nil {
+		logger.Warn("invalid LOG_FORMAT", "error", logErr)
+	}
+
+	if cfgErr != nil {
+		logger.Error("failed to load config file", "error", cfgErr)
+		os.Exit(1)
+	}
+	if cfg.Path != "" {
+		logger.Info("loaded config file", "path", cfg.Path)
+	}
+
+	if tz := cfg.Get("TZ"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			logger.Warn("invalid timezone, using system default", "value", tz, "error", err)
+		} else {
+			time.Local = loc
+		}
+	}
 
-	// Load Trakt configuration from environment
-	config := trakt.ConfigFromEnv()
-	client := trakt.NewClient(config, logger)
+	client := trakt.NewClient(trakt.ConfigFromLookup(cfg.Get), logger)
 
-	if !client.IsConfigured() {
-		logger.Warn("TRAKT_CLIENT_ID not set - some tools will not work")
+	if dir := cfg.Get("TRAKT_CACHE_DIR"); dir != "" {
+		if err := client.SetCacheDir(dir); err != nil {
+			logger.Warn("disk cache disabled", "dir", dir, "error", err)
+		}
 	}
 
-	// Create MCP server and register tools
+	// Persist tokens so authentication and refreshes survive restarts
+	tokenPath := cfg.Get("TRAKT_TOKEN_FILE")
+	if tokenPath == "" {
+		var err error
+		if tokenPath, err = trakt.DefaultTokenPath(); err != nil {
+			logger.Warn("token persistence disabled", "error", err)
+		}
+	}
+	var store *trakt.FileTokenStore
+	if tokenPath != "" {
+		store = &trakt.FileTokenStore{Path: tokenPath, Passphrase: cfg.Get("TRAKT_TOKEN_PASSPHRASE")}
+		if err := client.SetTokenStore(store); err != nil {
+			logger.Warn("failed to load saved token", "path", tokenPath, "error", err)
+		}
+	}
+
+	if exportHistory {
+		if err := runExport(context.Background(), client, exportOpts); err != nil {
+			logger.Error("export failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if importHistory {
+		if err := runImport(context.Background(), client, importOpts); err != nil {
+			logger.Error("import failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if scrobbleDaemon {
+		if err := runScrobbled(client, cfg, logger, scrobbledOpts); err != nil {
+			logger.Error("scrobbled failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	dir, err := backupDir(cfg.Get("TRAKT_BACKUP_DIR"))
+	if err != nil {
+		logger.Warn("backups disabled", "error", err)
+	}
+	if backupAccount || restoreAccount {
+		if dir == "" {
+			os.Exit(1)
+		}
+		if backupAccount {
+			err = runBackup(context.Background(), client, dir)
+		} else {
+			err = runRestore(context.Background(), client, dir, restoreOpts)
+		}
+		if err != nil {
+			logger.Error("command failed", "command", flag.Arg(0), "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Create MCP server and register tools and resources
 	server := mcp.NewServer(logger)
-	mcp.RegisterTools(server, client)
+	client.Use(server.Metrics().TraktMiddleware())
+	server.SetReadOnly(isTrue(cfg.Get("MCP_READ_ONLY")))
+
+	framing, err := mcp.ParseFraming(*framingFlag)
+	if err != nil {
+		logger.Error("invalid framing", "error", err)
+		os.Exit(2)
+	}
+	server.SetFraming(framing)
+
+	if isTrue(cfg.Get("MCP_TRACE")) {
+		tracePath := cfg.Get("MCP_TRACE_FILE")
+		if tracePath == "" {
+			tracePath = filepath.Join(os.TempDir(), "trakt-mcp-trace.log")
+		}
+		tracer, err := mcp.NewTracer(tracePath, mcp.DefaultTraceMaxSize)
+		if err != nil {
+			logger.Error("failed to enable tracing", "error", err)
+			os.Exit(1)
+		}
+		defer tracer.Close()
+		server.SetTracer(tracer)
+		logger.Info("protocol tracing enabled", "file", tracePath)
+	}
+	// Watches and ratings logged while Trakt is unreachable are journaled
+	// and sent once it's back
+	var api mcp.TraktAPI = client
+	var queued *queue.Queue
+	if !listTools {
+		if queued = openQueue(cfg, logger); queued != nil {
+			api = mcp.Queued(api, queued)
+		}
+	}
+
+	// Changes that reach Trakt are announced to the event webhook
+	hook := openWebhook(cfg, logger)
+	if hook != nil && !listTools {
+		api = mcp.Notifying(api, hook)
+		defer hook.Wait()
+	}
+
+	// With a mirror, bulk reads of history, ratings and the watchlist are
+	// answered from a local copy; sync_diff still needs the API itself
+	live := api
+	var mirrored *mirror.Mirror
+	if dir := cfg.Get("TRAKT_MIRROR_DIR"); dir != "" && !listTools {
+		if mirrored, err = mirror.Open(dir, client, logger); err != nil {
+			logger.Warn("mirror disabled", "dir", dir, "error", err)
+		} else {
+			api = mcp.Mirrored(api, mirrored)
+		}
+	}
+
+	// Search results can be filled in from TMDB and take their artwork
+	// from TMDB or fanart.tv
+	api = withArtwork(api, cfg, logger)
+
+	checks := setupChecks(client, store, cfg.Get("TRAKT_CACHE_DIR"))
+	mcp.RegisterTools(server, api)
+	if *transport != "stdio" {
+		// The client may be on another machine, so it mustn't choose
+		// paths to read or write on this one
+		server.UnregisterTool("export_history")
+		server.UnregisterTool("import_history")
+	}
+	mcp.RegisterDiagnoseTool(server, checks)
+	mcp.RegisterStatusTool(server, api)
+	if dir != "" {
+		mcp.RegisterBackupTools(server, api, dir)
+	}
+	mcp.RegisterSyncDiffTool(server, live, mirrored, dir)
+	mcp.RegisterResources(server, api)
+	mcp.RegisterPrompts(server, api)
+	applySettings(cfg, logger, &level, client, server)
+
+	if listTools {
+		if err := printTools(os.Stdout, server.Tools(), toolsJSON); err != nil {
+			logger.Error("failed to print tools", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -50,15 +355,166 @@ func main() {
 		cancel()
 	}()
 
+	// SIGHUP reloads the configuration without ending the session
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-hupCh:
+				reload(*configPath, flag.CommandLine, logger, &level, client, server)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go logStartupChecks(ctx, logger, checks)
+
+	// Replayed writes are announced and refresh the mirror like any other,
+	// but mustn't go back through the queue
+	if queued != nil {
+		var replay mcp.TraktAPI = client
+		if hook != nil {
+			replay = mcp.Notifying(replay, hook)
+		}
+		if mirrored != nil {
+			replay = mcp.Mirrored(replay, mirrored)
+		}
+		go queued.Run(ctx, replay)
+	}
+
+	// Bring the mirror up to date now rather than on the first tool call
+	if mirrored != nil && client.IsAuthenticated() {
+		go func() { _ = mirrored.Refresh(ctx) }()
+	}
+
+	// Scrobble what Kodi plays; scrobbles write to history, so not when
+	// read-only
+	if kodiURL := cfg.Get("KODI_URL"); kodiURL != "" && !server.ReadOnly() {
+		k := kodi.NewClient(kodiURL, cfg.Get("KODI_USERNAME"), cfg.Get("KODI_PASSWORD"))
+		go server.WatchKodi(ctx, api, k, mcp.KodiPollInterval)
+	}
+
+	if adminAddr := cfg.Get("MCP_ADMIN_ADDR"); adminAddr != "" {
+		go func() {
+			if err := server.RunAdmin(ctx, adminAddr, client); err != nil {
+				logger.Error("admin endpoint stopped", "error", err)
+			}
+		}()
+	}
+
+	// Over HTTP the server outlives any one conversation, so it can tell
+	// connected clients when new episodes air
+	if *transport != "stdio" {
+		interval := mcp.DefaultNewEpisodeInterval
+		if v := cfg.Get("MCP_NEW_EPISODE_INTERVAL"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				logger.Warn("invalid MCP_NEW_EPISODE_INTERVAL, using default", "value", v, "default", mcp.DefaultNewEpisodeInterval)
+			} else {
+				interval = d
+			}
+		}
+		if interval > 0 {
+			go server.WatchNewEpisodes(ctx, api, interval)
+		}
+
+		// Feeds for calendar apps and feed readers, at URLs only those
+		// given the token know
+		if token := cfg.Get("MCP_FEED_TOKEN"); token != "" {
+			mcp.RegisterFeeds(server, api, token)
+		}
+
+		// Plex reports what was played here, for the server to scrobble
+		if token := cfg.Get("MCP_WEBHOOK_TOKEN"); token != "" {
+			mcp.RegisterPlexWebhook(server, api, token, cfg.Get("PLEX_ACCOUNT"))
+		}
+	}
+
 	// Run the server
-	if err := server.Run(ctx); err != nil {
+	switch *transport {
+	case "stdio":
+		err = server.Run(ctx)
+	case "sse":
+		err = server.RunSSE(ctx, *addr)
+	case "ws":
+		err = server.RunWebSocket(ctx, *addr)
+	default:
+		logger.Error("unknown transport", "transport", *transport)
+		os.Exit(2)
+	}
+	// Cancellation is the normal shutdown path once in-flight calls have drained
+	if err != nil && !errors.Is(err, context.Canceled) {
 		logger.Error("server error", "error", err)
 		os.Exit(1)
 	}
 }
 
-func getLogLevel() slog.Level {
-	switch os.Getenv("LOG_LEVEL") {
+// loadConfig reads the config file at path, falling back to the one named
+// by TRAKT_MCP_CONFIG, or the one in the default location if it exists.
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		path = os.Getenv(config.EnvPath)
+	}
+	if path != "" {
+		return config.Load(path)
+	}
+
+	path, err := config.DefaultPath()
+	if err != nil {
+		return &config.Config{}, nil
+	}
+	cfg, err := config.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config.Config{}, nil
+	}
+	return cfg, err
+}
+
+// openQueue opens the write queue at TRAKT_QUEUE_FILE or the default
+// path, or returns nil, with a warning, if it can't.
+func openQueue(cfg *config.Config, logger *slog.Logger) *queue.Queue {
+	path := cfg.Get("TRAKT_QUEUE_FILE")
+	if path == "" {
+		var err error
+		if path, err = queue.DefaultPath(); err != nil {
+			logger.Warn("write queue disabled", "error", err)
+			return nil
+		}
+	}
+	q, err := queue.Open(path, logger)
+	if err != nil {
+		logger.Warn("write queue disabled", "path", path, "error", err)
+		return nil
+	}
+	return q
+}
+
+// openWebhook returns the sender of the event webhook, or nil if there's
+// none or its URL isn't an HTTP one.
+func openWebhook(cfg *config.Config, logger *slog.Logger) *webhook.Sender {
+	raw := cfg.Get("MCP_EVENT_WEBHOOK_URL")
+	if raw == "" {
+		return nil
+	}
+	if u, err := url.Parse(raw); err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		logger.Warn("event webhook disabled: not an http or https URL", "url", raw)
+		return nil
+	}
+	return webhook.New(raw, cfg.Get("MCP_EVENT_WEBHOOK_SECRET"), logger)
+}
+
+// isTrue reports whether a boolean setting is enabled, accepting the forms
+// strconv.ParseBool does, such as "1" and "true".
+func isTrue(v string) bool {
+	b, _ := strconv.ParseBool(v)
+	return b
+}
+
+func getLogLevel(level string) slog.Level {
+	switch level {
 	case "debug":
 		return slog.LevelDebug
 	case "warn":
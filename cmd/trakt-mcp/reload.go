@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/config"
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// applySettings applies the settings that can change while the server runs.
+// It's called once at startup and again on every reload, so a setting
+// removed from the config goes back to its default.
+func applySettings(cfg *config.Config, logger *slog.Logger, level *slog.LevelVar, client *trakt.Client, server *mcp.Server) {
+	level.Set(getLogLevel(cfg.Get("LOG_LEVEL")))
+
+	client.SetCredentials(cfg.Get("TRAKT_CLIENT_ID"), cfg.Get("TRAKT_CLIENT_SECRET"))
+
+	ttl := trakt.DefaultCacheTTL
+	if v := cfg.Get("TRAKT_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			logger.Warn("invalid TRAKT_CACHE_TTL, using default", "value", v, "default", trakt.DefaultCacheTTL)
+		} else {
+			ttl = d
+		}
+	}
+	client.SetCacheTTL(ttl)
+
+	retries := trakt.DefaultMaxRetries
+	if v := cfg.Get("TRAKT_MAX_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			logger.Warn("invalid TRAKT_MAX_RETRIES, using default", "value", v, "default", trakt.DefaultMaxRetries)
+		} else {
+			retries = n
+		}
+	}
+	client.SetMaxRetries(retries)
+
+	server.SetStrict(isTrue(cfg.Get("MCP_STRICT")))
+	server.SetConfirmDestructive(isTrue(cfg.Get("MCP_CONFIRM_DESTRUCTIVE")))
+
+	style, err := mcp.ParseOutputStyle(cfg.Get("MCP_OUTPUT_STYLE"))
+	if err != nil {
+		logger.Warn("invalid MCP_OUTPUT_STYLE, using default", "error", err, "default", mcp.OutputRich)
+	}
+	server.SetOutputStyle(style)
+
+	lang, err := mcp.ParseLanguage(cfg.Get("MCP_LANGUAGE"))
+	if err != nil {
+		logger.Warn("invalid MCP_LANGUAGE, using default", "error", err, "default", mcp.DefaultLanguage)
+	}
+	server.SetLanguage(lang)
+
+	templateDir := cfg.Get("MCP_TEMPLATE_DIR")
+	if templateDir == "" {
+		templateDir, _ = mcp.DefaultTemplateDir()
+	}
+	if templateDir != "" {
+		templates, err := mcp.LoadTemplates(templateDir)
+		if err != nil {
+			logger.Warn("some output templates failed to load", "dir", templateDir, "error", err)
+		}
+		if tools := templates.Tools(); len(tools) > 0 {
+			logger.Info("loaded output templates", "dir", templateDir, "tools", tools)
+		}
+		server.SetTemplates(templates)
+	}
+
+	timeout := mcp.DefaultToolTimeout
+	if v := cfg.Get("MCP_TOOL_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			logger.Warn("invalid MCP_TOOL_TIMEOUT, using default", "value", v, "default", mcp.DefaultToolTimeout)
+		} else {
+			timeout = d
+		}
+	}
+	server.SetToolTimeout(timeout)
+
+	concurrency := mcp.DefaultMaxConcurrentTools
+	if v := cfg.Get("MCP_MAX_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			logger.Warn("invalid MCP_MAX_CONCURRENCY, using default", "value", v, "default", mcp.DefaultMaxConcurrentTools)
+		} else {
+			concurrency = n
+		}
+	}
+	server.SetMaxConcurrentTools(concurrency)
+
+	budget := mcp.DefaultResponseBudget
+	if v := cfg.Get("MCP_MAX_RESPONSE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			logger.Warn("invalid MCP_MAX_RESPONSE_SIZE, using default", "value", v, "default", mcp.DefaultResponseBudget)
+		} else {
+			budget = n
+		}
+	}
+	server.SetResponseBudget(budget)
+
+	// Clients are told when the set of exposed tools changes
+	server.SetToolAllowlist(cfg.List("MCP_TOOLS"))
+}
+
+// reload re-reads the config file at path and the saved token, then
+// reapplies the reloadable settings. Command-line flags keep overriding the
+// file. A config file that fails to load leaves the running settings alone.
+//
+// The transport, timezone, log format, tracing, admin address, cache
+// directory, token file and read-only mode are fixed at startup and need a
+// restart to change.
+func reload(path string, fs *flag.FlagSet, logger *slog.Logger, level *slog.LevelVar, client *trakt.Client, server *mcp.Server) {
+	cfg, err := loadConfig(path)
+	if err != nil {
+		logger.Error("reload failed, keeping current settings", "error", err)
+		return
+	}
+	applySettingFlags(fs, cfg)
+	applySettings(cfg, logger, level, client, server)
+
+	if err := client.ReloadToken(); err != nil {
+		logger.Warn("failed to reload saved token", "error", err)
+	}
+	logger.Info("configuration reloaded", "path", cfg.Path)
+}
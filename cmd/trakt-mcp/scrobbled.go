@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+)
+
+// scrobbledOptions are the flags of the scrobbled subcommand.
+type scrobbledOptions struct {
+	players  []string // MPRIS player names; empty for all
+	interval time.Duration
+}
+
+func parseScrobbledFlags(args []string) scrobbledOptions {
+	var opts scrobbledOptions
+	var players string
+	fs := flag.NewFlagSet("scrobbled", flag.ExitOnError)
+	fs.StringVar(&players, "players", "mpv,vlc", "Comma-separated MPRIS players to scrobble, empty for all")
+	fs.DurationVar(&opts.interval, "interval", mcp.MPRISPollInterval, "How often to ask the players what they're playing")
+	_ = fs.Parse(args)
+	for _, p := range strings.Split(players, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			opts.players = append(opts.players, p)
+		}
+	}
+	return opts
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kofifort/trakt-mcp-go/internal/config"
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+	"github.com/kofifort/trakt-mcp-go/internal/mpris"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// runScrobbled scrobbles what the desktop's MPRIS players play until
+// interrupted, for the scrobbled subcommand. Watches Trakt can't be
+// reached to record are queued, and those it records are posted to the
+// event webhook, as the server's are.
+func runScrobbled(client *trakt.Client, cfg *config.Config, logger *slog.Logger, opts scrobbledOptions) error {
+	if !client.IsAuthenticated() {
+		return errors.New("not signed in to Trakt: authenticate through the server first")
+	}
+	if _, err := mpris.Players(context.Background(), opts.players); errors.Is(err, mpris.ErrNoPlayerctl) {
+		return fmt.Errorf("%w: install playerctl to read MPRIS players", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var api mcp.TraktAPI = client
+	if q := openQueue(cfg, logger); q != nil {
+		api = mcp.Queued(api, q)
+		go q.Run(ctx, client)
+	}
+	if hook := openWebhook(cfg, logger); hook != nil {
+		api = mcp.Notifying(api, hook)
+		defer hook.Wait()
+	}
+
+	sc := mcp.NewScrobbler(api, logger)
+	logger.Info("scrobbling MPRIS players", "players", opts.players, "interval", opts.interval)
+	mcp.WatchPlayers(ctx, sc, opts.players, opts.interval)
+
+	// Stop what was playing at its last progress rather than leave Trakt
+	// showing it as watching
+	sc.Update(context.Background(), nil)
+	return nil
+}
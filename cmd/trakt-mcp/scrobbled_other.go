@@ -0,0 +1,17 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/kofifort/trakt-mcp-go/internal/config"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// runScrobbled fails: players only share what they play over MPRIS on
+// Linux.
+func runScrobbled(client *trakt.Client, cfg *config.Config, logger *slog.Logger, opts scrobbledOptions) error {
+	return errors.New("scrobbled needs Linux, where players share what they play over MPRIS")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+)
+
+// printTools writes the tools the server offers for the tools subcommand,
+// either as readable text or in the shape of a tools/list result.
+func printTools(w io.Writer, tools []mcp.Tool, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(mcp.ToolsListResult{Tools: tools})
+	}
+
+	for i, tool := range tools {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, tool.Name)
+		if tool.Description != "" {
+			fmt.Fprintf(w, "  %s\n", tool.Description)
+		}
+
+		props := tool.InputSchema.Properties
+		if len(props) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		required := make(map[string]bool, len(tool.InputSchema.Required))
+		for _, name := range tool.InputSchema.Required {
+			required[name] = true
+		}
+
+		fmt.Fprintln(w, "  Arguments:")
+		for _, name := range names {
+			prop := props[name]
+			kind := prop.Type
+			if required[name] {
+				kind += ", required"
+			}
+			line := fmt.Sprintf("    %s (%s)", name, kind)
+			if prop.Description != "" {
+				line += ": " + prop.Description
+			}
+			if len(prop.Enum) > 0 {
+				line += fmt.Sprintf(" [%s]", strings.Join(prop.Enum, "|"))
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
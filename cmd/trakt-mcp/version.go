@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+)
+
+// printVersion writes the build metadata for the version subcommand.
+func printVersion(w io.Writer) {
+	b := mcp.CurrentBuild()
+	commit := b.Commit
+	if commit == "" {
+		commit = "unknown"
+	} else if b.Modified {
+		commit += " (modified)"
+	}
+	date := b.Date
+	if date == "" {
+		date = "unknown"
+	}
+
+	fmt.Fprintf(w, "trakt-mcp %s\n", b.Version)
+	fmt.Fprintf(w, "  commit:   %s\n", commit)
+	fmt.Fprintf(w, "  built:    %s\n", date)
+	fmt.Fprintf(w, "  protocol: %s (also %s)\n", mcp.LatestProtocolVersion,
+		strings.Join(mcp.SupportedProtocolVersions[1:], ", "))
+	fmt.Fprintf(w, "  go:       %s\n", b.GoVersion)
+}
@@ -0,0 +1,230 @@
+// Package backup snapshots a Trakt account, its history, ratings,
+// watchlist, collection and personal lists, to a local archive, compares
+// snapshots, and restores what the account has lost since one was taken.
+//
+// Archives are gzipped JSON files named after the time they were taken,
+// e.g. trakt-backup-20261016T183000Z.json.gz, so a directory of them keeps
+// every version. Each carries a format version, and an archive written by
+// a newer release is refused rather than misread.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// FormatVersion is the archive layout this release writes and the newest
+// it reads.
+const FormatVersion = 1
+
+// Archive file names are filePrefix, the time taken in timeLayout, and
+// fileSuffix.
+const (
+	filePrefix = "trakt-backup-"
+	fileSuffix = ".json.gz"
+	timeLayout = "20060102T150405Z"
+)
+
+// Source is the Trakt API a snapshot is taken from, as trakt.Client does.
+type Source interface {
+	ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error
+	GetUserRatings(ctx context.Context, user, ratingType string) ([]trakt.Rating, error)
+	GetWatchlist(ctx context.Context, watchlistType string, opts ...trakt.RequestOption) ([]trakt.WatchlistItem, error)
+	ForEachCollected(ctx context.Context, collectionType string, fn func(trakt.CollectionEntry) error) error
+	GetLists(ctx context.Context) ([]trakt.List, error)
+	GetListItems(ctx context.Context, listID string) ([]trakt.ListItem, error)
+}
+
+// Snapshot is the state of an account at one point in time.
+type Snapshot struct {
+	Version    int                     `json:"version"`
+	TakenAt    time.Time               `json:"taken_at"`
+	History    []trakt.HistoryItem     `json:"history"` // newest first
+	Ratings    []trakt.Rating          `json:"ratings"`
+	Watchlist  []trakt.WatchlistItem   `json:"watchlist"`
+	Collection []trakt.CollectionEntry `json:"collection"`
+	Lists      []List                  `json:"lists"`
+}
+
+// List is a personal list and its items.
+type List struct {
+	trakt.List
+	Items []trakt.ListItem `json:"items"`
+}
+
+// Take snapshots the signed-in user's account.
+func Take(ctx context.Context, src Source) (*Snapshot, error) {
+	snap := &Snapshot{Version: FormatVersion, TakenAt: time.Now().UTC()}
+
+	err := src.ForEachHistoryItem(ctx, "", func(h trakt.HistoryItem) error {
+		snap.History = append(snap.History, h)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	if snap.Ratings, err = src.GetUserRatings(ctx, "me", ""); err != nil {
+		return nil, fmt.Errorf("read ratings: %w", err)
+	}
+	if snap.Watchlist, err = src.GetWatchlist(ctx, ""); err != nil {
+		return nil, fmt.Errorf("read watchlist: %w", err)
+	}
+	for _, kind := range []string{"movies", "shows"} {
+		err := src.ForEachCollected(ctx, kind, func(e trakt.CollectionEntry) error {
+			snap.Collection = append(snap.Collection, e)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("read collection: %w", err)
+		}
+	}
+
+	lists, err := src.GetLists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read lists: %w", err)
+	}
+	for _, l := range lists {
+		items, err := src.GetListItems(ctx, fmt.Sprint(l.IDs.Trakt))
+		if err != nil {
+			return nil, fmt.Errorf("read list %q: %w", l.Name, err)
+		}
+		snap.Lists = append(snap.Lists, List{List: l, Items: items})
+	}
+	return snap, nil
+}
+
+// DefaultDir returns the default archive directory, trakt-mcp/backups in
+// the user's config directory.
+func DefaultDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "trakt-mcp", "backups"), nil
+}
+
+// Save writes snap to a new archive in dir and returns its path.
+func Save(dir string, snap *Snapshot) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create backup directory: %w", err)
+	}
+	path := filepath.Join(dir, filePrefix+snap.TakenAt.UTC().Format(timeLayout)+fileSuffix)
+
+	tmp, err := os.CreateTemp(dir, ".backup-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(zw).Encode(snap); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Load reads the archive at path.
+func Load(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a backup archive: %w", path, err)
+	}
+	var snap Snapshot
+	if err := json.NewDecoder(zr).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	if snap.Version < 1 || snap.Version > FormatVersion {
+		return nil, fmt.Errorf("%s has format version %d; this release reads up to %d", path, snap.Version, FormatVersion)
+	}
+	return &snap, nil
+}
+
+// Archive is a backup archive on disk.
+type Archive struct {
+	Path    string
+	TakenAt time.Time
+	Size    int64
+}
+
+// Archives lists the archives in dir, newest first. A missing directory
+// has none.
+func Archives(dir string) ([]Archive, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var archives []Archive
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		taken, err := time.Parse(timeLayout, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		archives = append(archives, Archive{Path: filepath.Join(dir, name), TakenAt: taken, Size: info.Size()})
+	}
+	sort.Slice(archives, func(i, j int) bool { return archives[i].TakenAt.After(archives[j].TakenAt) })
+	return archives, nil
+}
+
+// Find resolves name to an archive path: the newest archive in dir for an
+// empty name, or else the file of that name in dir. Names that would reach
+// outside dir, with a separator or as "..", are rejected.
+func Find(dir, name string) (string, error) {
+	if name == "" {
+		archives, err := Archives(dir)
+		if err != nil {
+			return "", err
+		}
+		if len(archives) == 0 {
+			return "", fmt.Errorf("no backups in %s", dir)
+		}
+		return archives[0].Path, nil
+	}
+	if strings.ContainsRune(name, filepath.Separator) || strings.ContainsRune(name, '/') || name == "." || name == ".." {
+		return "", fmt.Errorf("%q isn't a backup name: give the file name of an archive in %s", name, dir)
+	}
+	return filepath.Join(dir, name), nil
+}
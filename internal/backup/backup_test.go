@@ -0,0 +1,257 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// fakeAccount is a Trakt account that can be snapshotted and restored to.
+type fakeAccount struct {
+	snap Snapshot
+
+	history    []trakt.WatchedItem
+	ratings    []trakt.RatingItem
+	watchlist  []trakt.SyncItems
+	collection []trakt.SyncItems
+	created    []trakt.List
+	listItems  map[string]trakt.SyncItems
+}
+
+func (f *fakeAccount) ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error {
+	for _, h := range f.snap.History {
+		if err := fn(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeAccount) GetUserRatings(ctx context.Context, user, ratingType string) ([]trakt.Rating, error) {
+	return f.snap.Ratings, nil
+}
+
+func (f *fakeAccount) GetWatchlist(ctx context.Context, watchlistType string, opts ...trakt.RequestOption) ([]trakt.WatchlistItem, error) {
+	return f.snap.Watchlist, nil
+}
+
+func (f *fakeAccount) ForEachCollected(ctx context.Context, collectionType string, fn func(trakt.CollectionEntry) error) error {
+	for _, e := range f.snap.Collection {
+		if (e.Movie != nil) == (collectionType == "movies") {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeAccount) GetLists(ctx context.Context) ([]trakt.List, error) {
+	var lists []trakt.List
+	for _, l := range f.snap.Lists {
+		lists = append(lists, l.List)
+	}
+	return lists, nil
+}
+
+func (f *fakeAccount) GetListItems(ctx context.Context, listID string) ([]trakt.ListItem, error) {
+	for _, l := range f.snap.Lists {
+		if listID == "42" && l.IDs.Trakt == 42 {
+			return l.Items, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeAccount) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	f.history = append(f.history, item)
+	return &trakt.SyncResponse{}, nil
+}
+
+func (f *fakeAccount) AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error) {
+	f.ratings = append(f.ratings, item)
+	return &trakt.SyncResponse{}, nil
+}
+
+func (f *fakeAccount) AddToWatchlist(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error) {
+	f.watchlist = append(f.watchlist, items)
+	return &trakt.SyncResponse{}, nil
+}
+
+func (f *fakeAccount) AddToCollection(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error) {
+	f.collection = append(f.collection, items)
+	return &trakt.SyncResponse{}, nil
+}
+
+func (f *fakeAccount) CreateList(ctx context.Context, list trakt.List) (*trakt.List, error) {
+	list.IDs = trakt.ListIDs{Trakt: 99}
+	f.created = append(f.created, list)
+	return &list, nil
+}
+
+func (f *fakeAccount) AddListItems(ctx context.Context, listID string, items trakt.SyncItems) (*trakt.SyncResponse, error) {
+	if f.listItems == nil {
+		f.listItems = make(map[string]trakt.SyncItems)
+	}
+	f.listItems[listID] = items
+	return &trakt.SyncResponse{}, nil
+}
+
+var (
+	severance = &trakt.Show{Title: "Severance", IDs: trakt.ShowIDs{Trakt: 1}}
+	dune      = &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 2}}
+	arrival   = &trakt.Movie{Title: "Arrival", Year: 2016, IDs: trakt.MovieIDs{Trakt: 3}}
+	march     = func(d int) time.Time { return time.Date(2026, 3, d, 20, 0, 0, 0, time.UTC) }
+)
+
+func episode(n int) *trakt.Episode {
+	return &trakt.Episode{Season: 1, Number: n, IDs: trakt.EpisodeIDs{Trakt: 100 + n}}
+}
+
+// fullAccount is the account as backed up.
+func fullAccount() Snapshot {
+	return Snapshot{
+		Version: FormatVersion,
+		TakenAt: march(10),
+		History: []trakt.HistoryItem{
+			{ID: 3, Type: "movie", WatchedAt: march(3), Movie: dune},
+			{ID: 2, Type: "episode", WatchedAt: march(2), Show: severance, Episode: episode(2)},
+			{ID: 1, Type: "episode", WatchedAt: march(1), Show: severance, Episode: episode(1)},
+		},
+		Ratings:   []trakt.Rating{{Rating: 9, Type: "movie", RatedAt: march(3), Movie: dune}, {Rating: 8, Type: "show", Show: severance}},
+		Watchlist: []trakt.WatchlistItem{{Type: "movie", Movie: arrival}},
+		Collection: []trakt.CollectionEntry{
+			{Movie: dune},
+			{Show: severance, Seasons: []trakt.CollectedSeason{{Number: 1, Episodes: []trakt.CollectedEpisode{{Number: 1}, {Number: 2}}}}},
+		},
+		Lists: []List{{List: trakt.List{Name: "Sci-fi", IDs: trakt.ListIDs{Trakt: 42}}, Items: []trakt.ListItem{{Type: "movie", Movie: arrival}}}},
+	}
+}
+
+func TestSaveLoadAndFind(t *testing.T) {
+	dir := t.TempDir()
+	src := &fakeAccount{snap: fullAccount()}
+
+	snap, err := Take(context.Background(), src)
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	if len(snap.History) != 3 || len(snap.Collection) != 2 || len(snap.Lists) != 1 || len(snap.Lists[0].Items) != 1 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+
+	snap.TakenAt = march(10)
+	older, err := Save(dir, snap)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if filepath.Base(older) != "trakt-backup-20260310T200000Z.json.gz" {
+		t.Errorf("unexpected archive name %s", older)
+	}
+	snap.TakenAt = march(11)
+	newer, err := Save(dir, snap)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	// Stray files are ignored
+	_ = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hi"), 0o600)
+
+	archives, err := Archives(dir)
+	if err != nil || len(archives) != 2 || archives[0].Path != newer {
+		t.Fatalf("expected 2 archives, newest first, got %+v (%v)", archives, err)
+	}
+	if path, _ := Find(dir, ""); path != newer {
+		t.Errorf("expected the newest archive by default, got %s", path)
+	}
+	if path, _ := Find(dir, filepath.Base(older)); path != older {
+		t.Errorf("expected a file name to resolve in the directory, got %s", path)
+	}
+	for _, name := range []string{older, "../" + filepath.Base(older), "..", "/etc/passwd"} {
+		if path, err := Find(dir, name); err == nil {
+			t.Errorf("expected %q rejected, got %s", name, path)
+		}
+	}
+
+	loaded, err := Load(older)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !Compare(snap, loaded).Empty() {
+		t.Errorf("expected the loaded snapshot to match, got %+v", Compare(snap, loaded))
+	}
+}
+
+func TestLoad_RefusesNewerFormat(t *testing.T) {
+	snap := fullAccount()
+	snap.Version = FormatVersion + 1
+	path, err := Save(t.TempDir(), &snap)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an archive of a newer format to be refused")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	before := fullAccount()
+	after := fullAccount()
+	after.History = after.History[:2] // S01E01 removed
+	after.History = append([]trakt.HistoryItem{{ID: 4, Type: "movie", WatchedAt: march(4), Movie: arrival}}, after.History...)
+	after.Ratings[0].Rating = 6
+	after.Lists = nil
+
+	d := Compare(&before, &after)
+	if len(d.Added) != 1 || d.Added[0].Title != "Arrival (2016)" {
+		t.Errorf("expected the new play added, got %+v", d.Added)
+	}
+	if len(d.Removed) != 2 || d.Removed[0].Title != "Severance S01E01" || d.Removed[1].Section != "list Sci-fi" {
+		t.Errorf("expected the removed play and list item, got %+v", d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].Detail != "9 → 6" {
+		t.Errorf("expected the rating change, got %+v", d.Changed)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	snap := fullAccount()
+	now := fullAccount()
+	now.History = now.History[:1]
+	now.Ratings = []trakt.Rating{{Rating: 4, Type: "movie", Movie: dune}}
+	now.Watchlist = nil
+	now.Collection = now.Collection[:1]
+	now.Lists = nil
+
+	sink := &fakeAccount{}
+	r, err := Restore(context.Background(), sink, &snap, &now)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if r.Plays != 2 || r.Ratings != 2 || r.Watchlist != 1 || r.Collection != 2 || r.Lists != 1 || r.ListItems != 1 {
+		t.Errorf("unexpected counts: %+v", r)
+	}
+
+	// Plays go back oldest first, each at its own time
+	if len(sink.history) != 2 || sink.history[0].WatchedAt != "2026-03-01T20:00:00Z" || sink.history[0].Episodes[0].IDs.Trakt != 101 {
+		t.Errorf("unexpected history writes: %+v", sink.history)
+	}
+	if rated := sink.ratings[0]; len(rated.Movies) != 1 || rated.Movies[0].Rating != 9 || len(rated.Shows) != 1 {
+		t.Errorf("expected the changed and the removed rating restored, got %+v", rated)
+	}
+	if shows := sink.collection[0].Shows; len(shows) != 1 || len(shows[0].Seasons) != 1 || len(shows[0].Seasons[0].Episodes) != 2 {
+		t.Errorf("expected collected episodes grouped by show and season, got %+v", sink.collection[0])
+	}
+	if len(sink.created) != 1 || sink.created[0].Name != "Sci-fi" || len(sink.listItems["99"].Movies) != 1 {
+		t.Errorf("expected the deleted list made again with its item, got %+v, %+v", sink.created, sink.listItems)
+	}
+
+	// Restoring onto an account that has everything does nothing
+	sink = &fakeAccount{}
+	if r, _ := Restore(context.Background(), sink, &snap, &snap); r.Total() != 0 || len(sink.history) != 0 {
+		t.Errorf("expected nothing restored, got %+v", r)
+	}
+}
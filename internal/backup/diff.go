@@ -0,0 +1,257 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Sections of a snapshot, as named in a Change.
+const (
+	SectionHistory    = "history"
+	SectionRatings    = "ratings"
+	SectionWatchlist  = "watchlist"
+	SectionCollection = "collection"
+	SectionList       = "list" // followed by the list's name
+)
+
+// Change is one difference between two snapshots.
+type Change struct {
+	Section string // one of the Section constants; "list <name>" for list items
+	Title   string // e.g. "Severance S01E01" or "Dune (2021)"
+	Detail  string // e.g. when a play was watched, or "8 → 6" for a rating
+}
+
+// Diff is what changed from one snapshot to a later one.
+type Diff struct {
+	Added   []Change // only in the later snapshot
+	Removed []Change // only in the earlier one
+	Changed []Change // in both but different, which only ratings can be
+}
+
+// Empty reports whether the snapshots hold the same things.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Compare returns what changed from snapshot from to snapshot to.
+// Season ratings and watchlist entries, and people on lists, aren't
+// compared.
+func Compare(from, to *Snapshot) Diff {
+	var d Diff
+	for _, section := range []func(*Snapshot) []entry{historyEntries, ratingEntries, watchlistEntries, collectionEntries, listEntries} {
+		before, after := section(from), section(to)
+		was := index(before)
+		now := index(after)
+		for _, e := range after {
+			old, ok := was[e.key]
+			switch {
+			case !ok:
+				d.Added = append(d.Added, e.change(e.detail))
+			case old.rating != e.rating:
+				d.Changed = append(d.Changed, e.change(fmt.Sprintf("%d → %d", old.rating, e.rating)))
+			}
+		}
+		for _, e := range before {
+			if _, ok := now[e.key]; !ok {
+				d.Removed = append(d.Removed, e.change(e.detail))
+			}
+		}
+	}
+	return d
+}
+
+// entry is an item of a snapshot section, keyed so the same item has the
+// same key in every snapshot.
+type entry struct {
+	key     string
+	section string
+	title   string
+	detail  string
+
+	show    *trakt.Show
+	movie   *trakt.Movie
+	episode *trakt.Episode
+
+	watchedAt      time.Time   // plays
+	rating         int         // ratings
+	ratedAt        time.Time   // ratings
+	season, number int         // collected episodes
+	list           *trakt.List // list items
+}
+
+func (e entry) change(detail string) Change {
+	return Change{Section: e.section, Title: e.title, Detail: detail}
+}
+
+func index(entries []entry) map[string]entry {
+	m := make(map[string]entry, len(entries))
+	for _, e := range entries {
+		m[e.key] = e
+	}
+	return m
+}
+
+// itemKey identifies a show, movie or episode by Trakt ID, or "" if it
+// has none.
+func itemKey(show *trakt.Show, movie *trakt.Movie, episode *trakt.Episode) string {
+	switch {
+	case episode != nil && episode.IDs.Trakt != 0:
+		return fmt.Sprintf("episode/%d", episode.IDs.Trakt)
+	case episode != nil && show != nil:
+		return fmt.Sprintf("show/%d/%d/%d", show.IDs.Trakt, episode.Season, episode.Number)
+	case movie != nil:
+		return fmt.Sprintf("movie/%d", movie.IDs.Trakt)
+	case show != nil:
+		return fmt.Sprintf("show/%d", show.IDs.Trakt)
+	}
+	return ""
+}
+
+// itemTitle names a show, movie or episode, e.g. "Severance S01E01" or
+// "Dune (2021)".
+func itemTitle(show *trakt.Show, movie *trakt.Movie, episode *trakt.Episode) string {
+	switch {
+	case episode != nil && show != nil:
+		return fmt.Sprintf("%s S%02dE%02d", show.Title, episode.Season, episode.Number)
+	case movie != nil && movie.Year > 0:
+		return fmt.Sprintf("%s (%d)", movie.Title, movie.Year)
+	case movie != nil:
+		return movie.Title
+	case show != nil:
+		return show.Title
+	}
+	return "an unknown item"
+}
+
+func historyEntries(s *Snapshot) []entry {
+	var entries []entry
+	for _, h := range s.History {
+		key := itemKey(h.Show, h.Movie, h.Episode)
+		if key == "" {
+			continue
+		}
+		entries = append(entries, entry{
+			key:       key + "@" + h.WatchedAt.UTC().Format(time.RFC3339),
+			section:   SectionHistory,
+			title:     itemTitle(h.Show, h.Movie, h.Episode),
+			detail:    "watched " + h.WatchedAt.Local().Format("2006-01-02 15:04"),
+			show:      h.Show,
+			movie:     h.Movie,
+			episode:   h.Episode,
+			watchedAt: h.WatchedAt,
+		})
+	}
+	return entries
+}
+
+func ratingEntries(s *Snapshot) []entry {
+	var entries []entry
+	for _, r := range s.Ratings {
+		// Ratings of seasons don't say which season
+		if r.Type == "season" {
+			continue
+		}
+		key := itemKey(r.Show, r.Movie, r.Episode)
+		if key == "" {
+			continue
+		}
+		entries = append(entries, entry{
+			key:     key,
+			section: SectionRatings,
+			title:   itemTitle(r.Show, r.Movie, r.Episode),
+			detail:  fmt.Sprintf("%d/10", r.Rating),
+			show:    r.Show,
+			movie:   r.Movie,
+			episode: r.Episode,
+			rating:  r.Rating,
+			ratedAt: r.RatedAt,
+		})
+	}
+	return entries
+}
+
+func watchlistEntries(s *Snapshot) []entry {
+	var entries []entry
+	for _, w := range s.Watchlist {
+		if w.Type == "season" {
+			continue
+		}
+		key := itemKey(w.Show, w.Movie, w.Episode)
+		if key == "" {
+			continue
+		}
+		entries = append(entries, entry{
+			key:     key,
+			section: SectionWatchlist,
+			title:   itemTitle(w.Show, w.Movie, w.Episode),
+			show:    w.Show,
+			movie:   w.Movie,
+			episode: w.Episode,
+		})
+	}
+	return entries
+}
+
+// collectionEntries lists collected movies and episodes; a show is
+// collected episode by episode.
+func collectionEntries(s *Snapshot) []entry {
+	var entries []entry
+	for _, c := range s.Collection {
+		if c.Movie != nil {
+			entries = append(entries, entry{
+				key:     itemKey(nil, c.Movie, nil),
+				section: SectionCollection,
+				title:   itemTitle(nil, c.Movie, nil),
+				movie:   c.Movie,
+			})
+			continue
+		}
+		if c.Show == nil {
+			continue
+		}
+		for _, season := range c.Seasons {
+			for _, ep := range season.Episodes {
+				episode := &trakt.Episode{Season: season.Number, Number: ep.Number}
+				entries = append(entries, entry{
+					key:     itemKey(c.Show, nil, episode),
+					section: SectionCollection,
+					title:   itemTitle(c.Show, nil, episode),
+					show:    c.Show,
+					season:  season.Number,
+					number:  ep.Number,
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// listEntries lists the items of every list, keyed by the list's name so
+// a list that was deleted and made again still matches.
+func listEntries(s *Snapshot) []entry {
+	var entries []entry
+	for i := range s.Lists {
+		l := &s.Lists[i]
+		for _, item := range l.Items {
+			if item.Type == "season" || item.Type == "person" {
+				continue
+			}
+			key := itemKey(item.Show, item.Movie, item.Episode)
+			if key == "" {
+				continue
+			}
+			entries = append(entries, entry{
+				key:     l.Name + "\x00" + key,
+				section: SectionList + " " + l.Name,
+				title:   itemTitle(item.Show, item.Movie, item.Episode),
+				show:    item.Show,
+				movie:   item.Movie,
+				episode: item.Episode,
+				list:    &l.List,
+			})
+		}
+	}
+	return entries
+}
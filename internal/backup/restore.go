@@ -0,0 +1,204 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Sink is the Trakt API a snapshot is restored to, as trakt.Client does.
+type Sink interface {
+	AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error)
+	AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error)
+	AddToWatchlist(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error)
+	AddToCollection(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error)
+	CreateList(ctx context.Context, list trakt.List) (*trakt.List, error)
+	AddListItems(ctx context.Context, listID string, items trakt.SyncItems) (*trakt.SyncResponse, error)
+}
+
+// Restored counts what Restore sent back to Trakt.
+type Restored struct {
+	Plays      int
+	Ratings    int
+	Watchlist  int
+	Collection int
+	Lists      int // lists made again
+	ListItems  int
+}
+
+// Total is the number of items restored, not counting lists made again.
+func (r Restored) Total() int {
+	return r.Plays + r.Ratings + r.Watchlist + r.Collection + r.ListItems
+}
+
+// Restore puts back what snap holds and current, a snapshot of the account
+// now, doesn't: removed plays, ratings, watchlist and collection entries
+// and list items, making deleted lists again. Ratings changed since snap
+// are set back. Nothing added since snap is removed, so restoring is safe
+// to repeat. Restored plays keep their original watch times.
+//
+// If a write fails, Restore stops and returns what it restored so far with
+// the error.
+func Restore(ctx context.Context, sink Sink, snap, current *Snapshot) (Restored, error) {
+	var r Restored
+
+	plays := missing(historyEntries(snap), historyEntries(current), false)
+	if err := restorePlays(ctx, sink, plays, &r); err != nil {
+		return r, fmt.Errorf("restore history: %w", err)
+	}
+
+	if ratings := missing(ratingEntries(snap), ratingEntries(current), true); len(ratings) > 0 {
+		var item trakt.RatingItem
+		for _, e := range ratings {
+			var ratedAt string
+			if !e.ratedAt.IsZero() {
+				ratedAt = e.ratedAt.UTC().Format(time.RFC3339)
+			}
+			switch {
+			case e.episode != nil:
+				item.Episodes = append(item.Episodes, trakt.RatedEpisode{Rating: e.rating, RatedAt: ratedAt, IDs: e.episode.IDs})
+			case e.movie != nil:
+				item.Movies = append(item.Movies, trakt.RatedMovie{Rating: e.rating, RatedAt: ratedAt, IDs: e.movie.IDs})
+			case e.show != nil:
+				item.Shows = append(item.Shows, trakt.RatedShow{Rating: e.rating, RatedAt: ratedAt, IDs: e.show.IDs})
+			}
+		}
+		if _, err := sink.AddRatings(ctx, item); err != nil {
+			return r, fmt.Errorf("restore ratings: %w", err)
+		}
+		r.Ratings = len(ratings)
+	}
+
+	if watchlist := missing(watchlistEntries(snap), watchlistEntries(current), false); len(watchlist) > 0 {
+		if _, err := sink.AddToWatchlist(ctx, syncItems(watchlist)); err != nil {
+			return r, fmt.Errorf("restore watchlist: %w", err)
+		}
+		r.Watchlist = len(watchlist)
+	}
+
+	if collection := missing(collectionEntries(snap), collectionEntries(current), false); len(collection) > 0 {
+		if _, err := sink.AddToCollection(ctx, syncItems(collection)); err != nil {
+			return r, fmt.Errorf("restore collection: %w", err)
+		}
+		r.Collection = len(collection)
+	}
+
+	if err := restoreLists(ctx, sink, snap, current, &r); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// missing returns the entries of want that have doesn't, and with
+// changed, those whose rating differs too.
+func missing(want, have []entry, changed bool) []entry {
+	had := index(have)
+	var out []entry
+	for _, e := range want {
+		h, ok := had[e.key]
+		if !ok || (changed && h.rating != e.rating) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// restorePlays adds plays back, oldest first, in one request per watch
+// time, since a history write carries a single time.
+func restorePlays(ctx context.Context, sink Sink, plays []entry, r *Restored) error {
+	sort.SliceStable(plays, func(i, j int) bool { return plays[i].watchedAt.Before(plays[j].watchedAt) })
+
+	for start := 0; start < len(plays); {
+		at := plays[start].watchedAt
+		item := trakt.WatchedItem{WatchedAt: at.UTC().Format(time.RFC3339)}
+		end := start
+		for ; end < len(plays) && plays[end].watchedAt.Equal(at); end++ {
+			switch e := plays[end]; {
+			case e.episode != nil && e.episode.IDs.Trakt != 0:
+				item.Episodes = append(item.Episodes, trakt.Episode{IDs: e.episode.IDs})
+			case e.movie != nil:
+				item.Movies = append(item.Movies, trakt.Movie{IDs: e.movie.IDs})
+			}
+		}
+		start = end
+
+		n := len(item.Episodes) + len(item.Movies)
+		if n == 0 {
+			continue
+		}
+		if _, err := sink.AddToHistory(ctx, item); err != nil {
+			return err
+		}
+		r.Plays += n
+	}
+	return nil
+}
+
+// restoreLists adds list items back, making lists deleted since snap again.
+func restoreLists(ctx context.Context, sink Sink, snap, current *Snapshot, r *Restored) error {
+	byList := make(map[string][]entry)
+	var names []string
+	for _, e := range missing(listEntries(snap), listEntries(current), false) {
+		if _, ok := byList[e.list.Name]; !ok {
+			names = append(names, e.list.Name)
+		}
+		byList[e.list.Name] = append(byList[e.list.Name], e)
+	}
+
+	existing := make(map[string]trakt.List)
+	for _, l := range current.Lists {
+		existing[l.Name] = l.List
+	}
+
+	for _, name := range names {
+		entries := byList[name]
+		list, ok := existing[name]
+		if !ok {
+			created, err := sink.CreateList(ctx, *entries[0].list)
+			if err != nil {
+				return fmt.Errorf("make list %q again: %w", name, err)
+			}
+			list = *created
+			r.Lists++
+		}
+		if _, err := sink.AddListItems(ctx, fmt.Sprint(list.IDs.Trakt), syncItems(entries)); err != nil {
+			return fmt.Errorf("restore list %q: %w", name, err)
+		}
+		r.ListItems += len(entries)
+	}
+	return nil
+}
+
+// syncItems builds the body adding entries to the watchlist, collection or
+// a list. Collected episodes are named by show, season and number.
+func syncItems(entries []entry) trakt.SyncItems {
+	var items trakt.SyncItems
+	shows := make(map[int]int) // show Trakt ID to index in items.Shows
+	for _, e := range entries {
+		switch {
+		case e.movie != nil:
+			items.Movies = append(items.Movies, trakt.Movie{IDs: e.movie.IDs})
+		case e.episode != nil:
+			items.Episodes = append(items.Episodes, trakt.Episode{IDs: e.episode.IDs})
+		case e.show != nil && e.section == SectionCollection:
+			i, ok := shows[e.show.IDs.Trakt]
+			if !ok {
+				i = len(items.Shows)
+				shows[e.show.IDs.Trakt] = i
+				items.Shows = append(items.Shows, trakt.SyncShow{IDs: e.show.IDs})
+			}
+			show := &items.Shows[i]
+			if n := len(show.Seasons); n == 0 || show.Seasons[n-1].Number != e.season {
+				show.Seasons = append(show.Seasons, trakt.SyncSeason{Number: e.season})
+			}
+			season := &show.Seasons[len(show.Seasons)-1]
+			season.Episodes = append(season.Episodes, trakt.SyncEpisode{Number: e.number})
+		case e.show != nil:
+			items.Shows = append(items.Shows, trakt.SyncShow{IDs: e.show.IDs})
+		}
+	}
+	return items
+}
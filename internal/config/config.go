@@ -0,0 +1,296 @@
+// Package config loads the optional trakt-mcp configuration file.
+//
+// The file is a small subset of TOML: bare keys, [section] headers, and
+// string, number, boolean or single-line string array values. Every setting
+// mirrors an environment variable, which takes precedence when set, so the
+// file can hold defaults that a host's environment block overrides.
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EnvPath names the environment variable that points at the config file.
+const EnvPath = "TRAKT_MCP_CONFIG"
+
+// settings maps configuration file keys to the environment variables they
+// stand in for.
+var settings = map[string]string{
+	"log_level":  "LOG_LEVEL",
+	"log_format": "LOG_FORMAT",
+	"log_color":  "LOG_COLOR",
+	"timezone":   "TZ",
+
+	"trakt.client_id":        "TRAKT_CLIENT_ID",
+	"trakt.client_secret":    "TRAKT_CLIENT_SECRET",
+	"trakt.api_url":          "TRAKT_API_URL",
+	"trakt.oauth_url":        "TRAKT_OAUTH_URL",
+	"trakt.redirect_uri":     "TRAKT_REDIRECT_URI",
+	"trakt.token_file":       "TRAKT_TOKEN_FILE",
+	"trakt.token_passphrase": "TRAKT_TOKEN_PASSPHRASE",
+	"trakt.cache_dir":        "TRAKT_CACHE_DIR",
+	"trakt.cache_ttl":        "TRAKT_CACHE_TTL",
+	"trakt.max_retries":      "TRAKT_MAX_RETRIES",
+	"trakt.mirror_dir":       "TRAKT_MIRROR_DIR",
+	"trakt.backup_dir":       "TRAKT_BACKUP_DIR",
+	"trakt.queue_file":       "TRAKT_QUEUE_FILE",
+
+	"tmdb.api_key":   "TMDB_API_KEY",
+	"fanart.api_key": "FANART_API_KEY",
+	"plex.account":   "PLEX_ACCOUNT",
+	"kodi.url":       "KODI_URL",
+	"kodi.username":  "KODI_USERNAME",
+	"kodi.password":  "KODI_PASSWORD",
+
+	"server.strict":               "MCP_STRICT",
+	"server.tool_timeout":         "MCP_TOOL_TIMEOUT",
+	"server.max_concurrency":      "MCP_MAX_CONCURRENCY",
+	"server.tools":                "MCP_TOOLS",
+	"server.read_only":            "MCP_READ_ONLY",
+	"server.confirm_destructive":  "MCP_CONFIRM_DESTRUCTIVE",
+	"server.output_style":         "MCP_OUTPUT_STYLE",
+	"server.language":             "MCP_LANGUAGE",
+	"server.template_dir":         "MCP_TEMPLATE_DIR",
+	"server.max_response_size":    "MCP_MAX_RESPONSE_SIZE",
+	"server.trace":                "MCP_TRACE",
+	"server.trace_file":           "MCP_TRACE_FILE",
+	"server.admin_addr":           "MCP_ADMIN_ADDR",
+	"server.artwork":              "MCP_ARTWORK",
+	"server.new_episode_interval": "MCP_NEW_EPISODE_INTERVAL",
+	"server.feed_token":           "MCP_FEED_TOKEN",
+	"server.webhook_token":        "MCP_WEBHOOK_TOKEN",
+	"server.event_webhook_url":    "MCP_EVENT_WEBHOOK_URL",
+	"server.event_webhook_secret": "MCP_EVENT_WEBHOOK_SECRET",
+}
+
+// Config holds the settings read from a configuration file. The zero value
+// is an empty configuration that defers entirely to the environment.
+type Config struct {
+	// Path is the file the settings were loaded from, empty if none.
+	Path string
+
+	values    map[string]string // keyed by environment variable name
+	overrides map[string]string // set with Set, taking precedence over everything
+}
+
+// DefaultPath returns the default config file location,
+// trakt-mcp/config.toml in the user's config directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "trakt-mcp", "config.toml"), nil
+}
+
+// Load reads the configuration file at path. Unknown keys are an error so
+// that typos don't silently fall back to defaults.
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values, err := parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+	return &Config{Path: path, values: values}, nil
+}
+
+// Set overrides a setting, e.g. from a command-line flag. Overrides take
+// precedence over both the environment and the file.
+func (c *Config) Set(env, value string) {
+	if c.overrides == nil {
+		c.overrides = make(map[string]string)
+	}
+	c.overrides[env] = value
+}
+
+// Get returns a setting by its environment variable name. Overrides win,
+// then a non-empty environment variable, then the file.
+func (c *Config) Get(env string) string {
+	if c != nil {
+		if v, ok := c.overrides[env]; ok {
+			return v
+		}
+	}
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	if c == nil {
+		return ""
+	}
+	return c.values[env]
+}
+
+// List returns a comma-separated setting, such as a string array from the
+// file, split into its trimmed, non-empty elements.
+func (c *Config) List(env string) []string {
+	var list []string
+	for _, item := range strings.Split(c.Get(env), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parse reads the TOML subset into settings keyed by environment variable.
+// Errors are prefixed with the line number.
+func parse(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+	section := ""
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.IndexByte(line, ']')
+			if end < 0 || !isBlank(line[end+1:]) {
+				return nil, fmt.Errorf("%d: malformed section header", lineNo)
+			}
+			section = strings.TrimSpace(line[1:end])
+			if !isBareKey(section) {
+				return nil, fmt.Errorf("%d: invalid section name %q", lineNo, section)
+			}
+			continue
+		}
+
+		name, rawValue, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%d: expected key = value", lineNo)
+		}
+		name = strings.TrimSpace(name)
+		if !isBareKey(name) {
+			return nil, fmt.Errorf("%d: invalid key %q", lineNo, name)
+		}
+		key := name
+		if section != "" {
+			key = section + "." + name
+		}
+		env, known := settings[key]
+		if !known {
+			return nil, fmt.Errorf("%d: unknown setting %q", lineNo, key)
+		}
+
+		value, err := parseValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, fmt.Errorf("%d: %s: %w", lineNo, key, err)
+		}
+		values[env] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf(" %w", err)
+	}
+	return values, nil
+}
+
+// parseValue parses a value and any trailing comment. Arrays are flattened
+// to a comma-separated list, matching how list settings are given in the
+// environment.
+func parseValue(s string) (string, error) {
+	if strings.HasPrefix(s, "[") {
+		var items []string
+		rest := strings.TrimSpace(s[1:])
+		for !strings.HasPrefix(rest, "]") {
+			item, after, err := parseScalar(rest)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+			rest = strings.TrimSpace(after)
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimSpace(rest[1:])
+			} else if !strings.HasPrefix(rest, "]") {
+				return "", errors.New("expected , or ] in array")
+			}
+		}
+		if !isBlank(rest[1:]) {
+			return "", errors.New("unexpected text after array")
+		}
+		return strings.Join(items, ","), nil
+	}
+
+	value, rest, err := parseScalar(s)
+	if err != nil {
+		return "", err
+	}
+	if !isBlank(rest) {
+		return "", errors.New("unexpected text after value")
+	}
+	return value, nil
+}
+
+// parseScalar parses a string, number or boolean at the start of s and
+// returns the remainder.
+func parseScalar(s string) (value, rest string, err error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				value, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					return "", "", fmt.Errorf("invalid string %s", s[:i+1])
+				}
+				return value, s[i+1:], nil
+			}
+		}
+		return "", "", errors.New("unterminated string")
+	case strings.HasPrefix(s, "'"):
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", errors.New("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+
+	end := strings.IndexAny(s, ",]# \t")
+	if end < 0 {
+		end = len(s)
+	}
+	token := s[:end]
+	if token == "true" || token == "false" {
+		return token, s[end:], nil
+	}
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64); err == nil {
+		return strings.ReplaceAll(token, "_", ""), s[end:], nil
+	}
+	if token == "" {
+		return "", "", errors.New("missing value")
+	}
+	return "", "", fmt.Errorf("invalid value %q (strings must be quoted)", token)
+}
+
+// isBlank reports whether s is empty apart from whitespace and a comment.
+func isBlank(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || strings.HasPrefix(s, "#")
+}
+
+func isBareKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := `
+# top-level settings
+log_level = "debug"  # trailing comment
+timezone = 'Europe/Berlin'
+
+[trakt]
+client_id = "abc\"123"
+max_retries = 5
+cache_ttl = "30m"
+
+[server]
+strict = true
+tools = ["search_show", "get_history", ]
+`
+	values, err := parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := map[string]string{
+		"LOG_LEVEL":         "debug",
+		"TZ":                "Europe/Berlin",
+		"TRAKT_CLIENT_ID":   `abc"123`,
+		"TRAKT_MAX_RETRIES": "5",
+		"TRAKT_CACHE_TTL":   "30m",
+		"MCP_STRICT":        "true",
+		"MCP_TOOLS":         "search_show,get_history",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("parse() = %v, want %v", values, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"unknown key", "client_id = \"x\"", `1: unknown setting "client_id"`},
+		{"unknown section key", "[trakt]\nclientid = \"x\"", `2: unknown setting "trakt.clientid"`},
+		{"unquoted string", "[trakt]\ncache_ttl = 30m", "strings must be quoted"},
+		{"unterminated string", `log_level = "debug`, "unterminated string"},
+		{"trailing text", `log_level = "debug" info`, "unexpected text after value"},
+		{"missing equals", "log_level", "expected key = value"},
+		{"bad header", "[trakt", "malformed section header"},
+		{"bad array", `[server]` + "\n" + `tools = ["a" "b"]`, "expected , or ] in array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestConfig_EnvOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[trakt]\nclient_id = \"from-file\"\ncache_dir = \"/tmp/cache\"\n[server]\ntools = [\"a\", \"b\"]\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	t.Setenv("TRAKT_CLIENT_ID", "from-env")
+	t.Setenv("TRAKT_CACHE_DIR", "")
+
+	if got := cfg.Get("TRAKT_CLIENT_ID"); got != "from-env" {
+		t.Errorf("expected the environment to win, got %q", got)
+	}
+	if got := cfg.Get("TRAKT_CACHE_DIR"); got != "/tmp/cache" {
+		t.Errorf("expected an empty variable to fall back to the file, got %q", got)
+	}
+	if got := cfg.List("MCP_TOOLS"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("List() = %v", got)
+	}
+}
+
+func TestConfig_SetOverridesEnv(t *testing.T) {
+	t.Setenv("TRAKT_CLIENT_ID", "from-env")
+
+	cfg := &Config{}
+	cfg.Set("TRAKT_CLIENT_ID", "from-flag")
+	cfg.Set("MCP_STRICT", "")
+
+	if got := cfg.Get("TRAKT_CLIENT_ID"); got != "from-flag" {
+		t.Errorf("expected the override to win, got %q", got)
+	}
+	t.Setenv("MCP_STRICT", "true")
+	if got := cfg.Get("MCP_STRICT"); got != "" {
+		t.Errorf("expected an explicit empty override to win, got %q", got)
+	}
+}
+
+func TestConfig_Nil(t *testing.T) {
+	t.Setenv("TRAKT_CLIENT_ID", "from-env")
+
+	var cfg *Config
+	if got := cfg.Get("TRAKT_CLIENT_ID"); got != "from-env" {
+		t.Errorf("expected a nil config to read the environment, got %q", got)
+	}
+	if got := cfg.List("MCP_TOOLS"); got != nil {
+		t.Errorf("expected no tools, got %v", got)
+	}
+}
+
+func TestLoad_ReportsPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("\nbogus = 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil || !strings.HasPrefix(err.Error(), path+":2: ") {
+		t.Errorf("expected error prefixed with path and line, got %v", err)
+	}
+}
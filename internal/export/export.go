@@ -0,0 +1,57 @@
+// Package export writes Trakt data to files for backup and for use in other
+// tools.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Export formats. CSV and JSON carry the full history; Letterboxd is the
+// CSV Letterboxd's importer reads, at letterboxd.com/import, and has
+// movies only.
+const (
+	FormatCSV        = "csv"
+	FormatJSON       = "json"
+	FormatLetterboxd = "letterboxd"
+)
+
+// Formats lists the export formats.
+var Formats = []string{FormatCSV, FormatJSON, FormatLetterboxd}
+
+// Source is everything an export can read from, as trakt.Client does.
+type Source interface {
+	HistorySource
+	GetUserRatings(ctx context.Context, user, ratingType string) ([]trakt.Rating, error)
+}
+
+// FormatFor returns the format a file at path is exported in by default:
+// JSON for a .json file, otherwise CSV.
+func FormatFor(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatCSV
+}
+
+// Write writes an export in format to w and returns the number of rows
+// written. historyType is "shows" or "movies" to export only those, or
+// empty for both; Letterboxd exports are always movies.
+func Write(ctx context.Context, src Source, w io.Writer, format, historyType string) (int, error) {
+	switch format {
+	case FormatCSV, FormatJSON:
+		return History(ctx, src, w, format, historyType)
+	case FormatLetterboxd:
+		if historyType == "shows" {
+			return 0, fmt.Errorf("letterboxd exports have movies only")
+		}
+		return Letterboxd(ctx, src, w)
+	default:
+		return 0, fmt.Errorf("unknown export format %q: use %s", format, strings.Join(Formats, ", "))
+	}
+}
@@ -0,0 +1,144 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// HistorySource streams watch history, as trakt.Client does.
+type HistorySource interface {
+	ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error
+}
+
+// HistoryRecord is one play in a history export, flattened so CSV and
+// JSON exports carry the same fields.
+type HistoryRecord struct {
+	HistoryID    int64  `json:"history_id"`
+	WatchedAt    string `json:"watched_at"` // RFC 3339, UTC
+	Action       string `json:"action"`     // "watch", "scrobble" or "checkin"
+	Type         string `json:"type"`       // "episode" or "movie"
+	Title        string `json:"title"`      // the show's title for episodes
+	Year         int    `json:"year,omitempty"`
+	Season       int    `json:"season,omitempty"`
+	Episode      int    `json:"episode,omitempty"`
+	EpisodeTitle string `json:"episode_title,omitempty"`
+	TraktID      int    `json:"trakt_id"` // the show's ID for episodes
+	Slug         string `json:"slug,omitempty"`
+	IMDb         string `json:"imdb,omitempty"`
+	TMDB         int    `json:"tmdb,omitempty"`
+	TVDB         int    `json:"tvdb,omitempty"`
+	EpisodeID    int    `json:"episode_trakt_id,omitempty"`
+}
+
+// historyHeader names the CSV columns, in HistoryRecord's order.
+var historyHeader = []string{
+	"history_id", "watched_at", "action", "type", "title", "year", "season", "episode",
+	"episode_title", "trakt_id", "slug", "imdb", "tmdb", "tvdb", "episode_trakt_id",
+}
+
+// NewHistoryRecord flattens a history item.
+func NewHistoryRecord(h trakt.HistoryItem) HistoryRecord {
+	r := HistoryRecord{
+		HistoryID: h.ID,
+		WatchedAt: h.WatchedAt.UTC().Format(time.RFC3339),
+		Action:    h.Action,
+		Type:      h.Type,
+	}
+	switch {
+	case h.Show != nil:
+		r.Title, r.Year = h.Show.Title, h.Show.Year
+		r.TraktID, r.Slug, r.IMDb, r.TMDB, r.TVDB = h.Show.IDs.Trakt, h.Show.IDs.Slug, h.Show.IDs.IMDB, h.Show.IDs.TMDB, h.Show.IDs.TVDB
+	case h.Movie != nil:
+		r.Title, r.Year = h.Movie.Title, h.Movie.Year
+		r.TraktID, r.Slug, r.IMDb, r.TMDB = h.Movie.IDs.Trakt, h.Movie.IDs.Slug, h.Movie.IDs.IMDB, h.Movie.IDs.TMDB
+	}
+	if h.Episode != nil {
+		r.Season, r.Episode, r.EpisodeTitle, r.EpisodeID = h.Episode.Season, h.Episode.Number, h.Episode.Title, h.Episode.IDs.Trakt
+	}
+	return r
+}
+
+func (r HistoryRecord) row() []string {
+	itoa := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	return []string{
+		strconv.FormatInt(r.HistoryID, 10), r.WatchedAt, r.Action, r.Type, r.Title, itoa(r.Year),
+		itoa(r.Season), itoa(r.Episode), r.EpisodeTitle, itoa(r.TraktID), r.Slug, r.IMDb,
+		itoa(r.TMDB), itoa(r.TVDB), itoa(r.EpisodeID),
+	}
+}
+
+// History writes the complete watch history to w in format, newest first,
+// as it pages in, so memory stays flat however long the history is.
+// historyType is "shows" or "movies" to export only those, or empty for
+// both. It returns the number of plays written.
+func History(ctx context.Context, src HistorySource, w io.Writer, format, historyType string) (int, error) {
+	switch format {
+	case FormatCSV:
+		return historyCSV(ctx, src, w, historyType)
+	case FormatJSON:
+		return historyJSON(ctx, src, w, historyType)
+	default:
+		return 0, fmt.Errorf("unknown export format %q: use %s or %s", format, FormatCSV, FormatJSON)
+	}
+}
+
+func historyCSV(ctx context.Context, src HistorySource, w io.Writer, historyType string) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(historyHeader); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	err := src.ForEachHistoryItem(ctx, historyType, func(h trakt.HistoryItem) error {
+		n++
+		return cw.Write(NewHistoryRecord(h).row())
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	return n, err
+}
+
+func historyJSON(ctx context.Context, src HistorySource, w io.Writer, historyType string) (int, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	err := src.ForEachHistoryItem(ctx, historyType, func(h trakt.HistoryItem) error {
+		data, err := json.Marshal(NewHistoryRecord(h))
+		if err != nil {
+			return err
+		}
+		sep := ",\n  "
+		if n == 0 {
+			sep = "\n  "
+		}
+		n++
+		_, err = io.WriteString(w, sep+string(data))
+		return err
+	})
+	if err != nil {
+		return n, err
+	}
+
+	end := "\n]\n"
+	if n == 0 {
+		end = "]\n"
+	}
+	_, err = io.WriteString(w, end)
+	return n, err
+}
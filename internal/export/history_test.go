@@ -0,0 +1,103 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+type fakeHistory []trakt.HistoryItem
+
+func (f fakeHistory) ForEachHistoryItem(_ context.Context, historyType string, fn func(trakt.HistoryItem) error, _ ...trakt.RequestOption) error {
+	for _, h := range f {
+		if historyType == "movies" && h.Movie == nil || historyType == "shows" && h.Show == nil {
+			continue
+		}
+		if err := fn(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var testHistory = fakeHistory{
+	{
+		ID: 11, Action: "watch", Type: "episode", WatchedAt: time.Date(2026, 3, 2, 21, 0, 0, 0, time.UTC),
+		Show:    &trakt.Show{Title: "Severance", Year: 2022, IDs: trakt.ShowIDs{Trakt: 154997, Slug: "severance", IMDB: "tt11280740", TMDB: 95396, TVDB: 371980}},
+		Episode: &trakt.Episode{Season: 2, Number: 1, Title: "Hello, Ms. Cobel", IDs: trakt.EpisodeIDs{Trakt: 11}},
+	},
+	{
+		ID: 10, Action: "scrobble", Type: "movie", WatchedAt: time.Date(2026, 3, 1, 19, 30, 0, 0, time.UTC),
+		Movie: &trakt.Movie{Title: "Dune, Part Two", Year: 2024, IDs: trakt.MovieIDs{Trakt: 287071, Slug: "dune-part-two-2024"}},
+	},
+}
+
+func TestHistory_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := History(context.Background(), testHistory, &buf, FormatCSV, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 rows, got %d", n)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output isn't valid CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %d rows", len(rows))
+	}
+	if strings.Join(rows[0], ",") != strings.Join(historyHeader, ",") {
+		t.Errorf("unexpected header: %v", rows[0])
+	}
+	want := "11,2026-03-02T21:00:00Z,watch,episode,Severance,2022,2,1,Hello, Ms. Cobel,154997,severance,tt11280740,95396,371980,11"
+	if got := strings.Join(rows[1], ","); got != want {
+		t.Errorf("episode row:\n got %s\nwant %s", got, want)
+	}
+	if rows[2][4] != "Dune, Part Two" || rows[2][6] != "" || rows[2][9] != "287071" {
+		t.Errorf("unexpected movie row: %v", rows[2])
+	}
+}
+
+func TestHistory_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := History(context.Background(), testHistory, &buf, FormatJSON, "movies")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var records []HistoryRecord
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("output isn't valid JSON: %v\n%s", err, buf.String())
+	}
+	if n != 1 || len(records) != 1 {
+		t.Fatalf("expected only the movie, got %d records", len(records))
+	}
+	if r := records[0]; r.Title != "Dune, Part Two" || r.TraktID != 287071 || r.WatchedAt != "2026-03-01T19:30:00Z" {
+		t.Errorf("unexpected record: %+v", r)
+	}
+}
+
+func TestHistory_EmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := History(context.Background(), fakeHistory{}, &buf, FormatJSON, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("expected an empty array, got %q", buf.String())
+	}
+}
+
+func TestHistory_UnknownFormat(t *testing.T) {
+	if _, err := History(context.Background(), testHistory, &bytes.Buffer{}, "xml", ""); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
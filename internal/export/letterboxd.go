@@ -0,0 +1,89 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// letterboxdHeader names the columns, as Letterboxd's importer expects
+// them. Rating10 is the 1-10 scale Trakt uses.
+var letterboxdHeader = []string{"Title", "Year", "imdbID", "tmdbID", "WatchedDate", "Rating10", "Rewatch"}
+
+// Letterboxd writes the user's movie plays to w as a Letterboxd diary
+// import, oldest first: one row per play, with the movie's current rating
+// and every play after the first flagged as a rewatch. Rated movies with
+// no plays get a row without a date, which Letterboxd imports as rated
+// and watched. It returns the number of rows written.
+func Letterboxd(ctx context.Context, src Source, w io.Writer) (int, error) {
+	ratings, err := src.GetUserRatings(ctx, "me", "movies")
+	if err != nil {
+		return 0, err
+	}
+	rated := make(map[int]int, len(ratings))
+	for _, r := range ratings {
+		if r.Movie != nil {
+			rated[r.Movie.IDs.Trakt] = r.Rating
+		}
+	}
+
+	// History comes newest first and rewatches are only known oldest
+	// first, so the movie plays are read before any are written
+	var plays []trakt.HistoryItem
+	err = src.ForEachHistoryItem(ctx, "movies", func(h trakt.HistoryItem) error {
+		if h.Movie != nil {
+			plays = append(plays, h)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(letterboxdHeader); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	watched := make(map[int]bool)
+	for i := len(plays) - 1; i >= 0; i-- {
+		h := plays[i]
+		id := h.Movie.IDs.Trakt
+		row := letterboxdRow(h.Movie, h.WatchedAt.Local().Format("2006-01-02"), rated[id], watched[id])
+		if err := cw.Write(row); err != nil {
+			return n, err
+		}
+		watched[id] = true
+		n++
+	}
+	for _, r := range ratings {
+		if r.Movie == nil || watched[r.Movie.IDs.Trakt] {
+			continue
+		}
+		if err := cw.Write(letterboxdRow(r.Movie, "", r.Rating, false)); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	cw.Flush()
+	return n, cw.Error()
+}
+
+func letterboxdRow(m *trakt.Movie, watchedDate string, rating int, rewatch bool) []string {
+	row := []string{m.Title, "", m.IDs.IMDB, "", watchedDate, "", strconv.FormatBool(rewatch)}
+	if m.Year > 0 {
+		row[1] = strconv.Itoa(m.Year)
+	}
+	if m.IDs.TMDB > 0 {
+		row[3] = strconv.Itoa(m.IDs.TMDB)
+	}
+	if rating > 0 {
+		row[5] = strconv.Itoa(rating)
+	}
+	return row
+}
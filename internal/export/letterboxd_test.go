@@ -0,0 +1,73 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+type fakeSource struct {
+	fakeHistory
+	ratings []trakt.Rating
+}
+
+func (f fakeSource) GetUserRatings(_ context.Context, user, ratingType string) ([]trakt.Rating, error) {
+	return f.ratings, nil
+}
+
+func TestLetterboxd(t *testing.T) {
+	dune := &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 1, IMDB: "tt1160419", TMDB: 438631}}
+	arrival := &trakt.Movie{Title: "Arrival", Year: 2016, IDs: trakt.MovieIDs{Trakt: 2, TMDB: 329865}}
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.Local) }
+
+	src := fakeSource{
+		// Newest first, as Trakt returns history
+		fakeHistory: fakeHistory{
+			{Type: "movie", WatchedAt: day(20), Movie: dune},
+			{Type: "episode", WatchedAt: day(10), Show: &trakt.Show{Title: "Severance"}, Episode: &trakt.Episode{Season: 1, Number: 1}},
+			{Type: "movie", WatchedAt: day(5), Movie: dune},
+		},
+		ratings: []trakt.Rating{
+			{Rating: 9, Type: "movie", Movie: dune},
+			{Rating: 7, Type: "movie", Movie: arrival},
+		},
+	}
+
+	var buf bytes.Buffer
+	n, err := Write(context.Background(), src, &buf, FormatLetterboxd, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 rows, got %d", n)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output isn't valid CSV: %v", err)
+	}
+	var got []string
+	for _, row := range rows {
+		got = append(got, strings.Join(row, ","))
+	}
+	want := []string{
+		"Title,Year,imdbID,tmdbID,WatchedDate,Rating10,Rewatch",
+		"Dune,2021,tt1160419,438631,2026-03-05,9,false",
+		"Dune,2021,tt1160419,438631,2026-03-20,9,true",
+		"Arrival,2016,,329865,,7,false",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected export:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWrite_LetterboxdShows(t *testing.T) {
+	if _, err := Write(context.Background(), fakeSource{}, &bytes.Buffer{}, FormatLetterboxd, "shows"); err == nil {
+		t.Error("expected a shows-only Letterboxd export to be rejected")
+	}
+}
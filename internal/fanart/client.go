@@ -0,0 +1,233 @@
+// Package fanart is a small client for the fanart.tv API, which serves
+// community artwork such as transparent logos and backgrounds. Shows are
+// looked up by TVDB ID and movies by TMDB or IMDb ID, both of which Trakt
+// returns.
+package fanart
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BaseURL        = "https://webservice.fanart.tv/v3"
+	DefaultTimeout = 10 * time.Second
+
+	// cacheTTL is how long artwork is reused.
+	cacheTTL = 24 * time.Hour
+	// maxCacheEntries bounds the cache; it is emptied when full.
+	maxCacheEntries = 1024
+)
+
+// ErrNotFound is matched by an APIError for an item fanart.tv has no
+// artwork for.
+var ErrNotFound = errors.New("not found")
+
+// APIError is an error response from fanart.tv.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("fanart.tv: %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("fanart.tv: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is lets errors.Is match an APIError against ErrNotFound.
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// Image is one piece of artwork.
+type Image struct {
+	URL   string `json:"url"`
+	Lang  string `json:"lang"`  // ISO 639-1, "00" for none
+	Likes string `json:"likes"` // a number, as fanart.tv sends it
+}
+
+// Artwork is what fanart.tv has for a show or movie, each kind ordered as
+// fanart.tv returns it.
+type Artwork struct {
+	Logos       []Image
+	Backgrounds []Image
+	Posters     []Image
+}
+
+// Logo returns the best logo for lang, or "" if there is none.
+func (a *Artwork) Logo(lang string) string { return best(a.Logos, lang) }
+
+// Background returns the best background, or "" if there is none.
+// Backgrounds carry no text, so their language doesn't matter.
+func (a *Artwork) Background() string { return best(a.Backgrounds, "") }
+
+// Poster returns the best poster for lang, or "" if there is none.
+func (a *Artwork) Poster(lang string) string { return best(a.Posters, lang) }
+
+// best picks the most liked image in lang, else in English, else without
+// text, else any.
+func best(images []Image, lang string) string {
+	if len(images) == 0 {
+		return ""
+	}
+	rank := func(img Image) int {
+		switch img.Lang {
+		case lang:
+			return 0
+		case "en":
+			return 1
+		case "00", "":
+			return 2
+		}
+		return 3
+	}
+	sorted := append([]Image(nil), images...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if ri, rj := rank(sorted[i]), rank(sorted[j]); ri != rj {
+			return ri < rj
+		}
+		li, _ := strconv.Atoi(sorted[i].Likes)
+		lj, _ := strconv.Atoi(sorted[j].Likes)
+		return li > lj
+	})
+	return sorted[0].URL
+}
+
+// Client is a fanart.tv API client. It is safe for concurrent use.
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cached // keyed by request path
+}
+
+type cached struct {
+	artwork *Artwork
+	expires time.Time
+}
+
+// NewClient creates a client authenticating with a fanart.tv project API
+// key.
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:     apiKey,
+		baseURL:    BaseURL,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		now:        time.Now,
+		cache:      make(map[string]cached),
+	}
+}
+
+// SetBaseURL sets the base URL for API requests, e.g. a test server.
+func (c *Client) SetBaseURL(url string) {
+	c.baseURL = strings.TrimRight(url, "/")
+}
+
+// Show returns the artwork of the show with TVDB ID tvdbID.
+func (c *Client) Show(ctx context.Context, tvdbID int) (*Artwork, error) {
+	return c.artwork(ctx, fmt.Sprintf("/tv/%d", tvdbID), func(data []byte) (*Artwork, error) {
+		var r struct {
+			HDTVLogo       []Image `json:"hdtvlogo"`
+			ClearLogo      []Image `json:"clearlogo"`
+			ShowBackground []Image `json:"showbackground"`
+			TVPoster       []Image `json:"tvposter"`
+		}
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, err
+		}
+		return &Artwork{
+			Logos:       append(r.HDTVLogo, r.ClearLogo...),
+			Backgrounds: r.ShowBackground,
+			Posters:     r.TVPoster,
+		}, nil
+	})
+}
+
+// Movie returns the artwork of the movie with id, a TMDB ID or an IMDb ID
+// such as "tt1160419".
+func (c *Client) Movie(ctx context.Context, id string) (*Artwork, error) {
+	return c.artwork(ctx, "/movies/"+url.PathEscape(id), func(data []byte) (*Artwork, error) {
+		var r struct {
+			HDMovieLogo     []Image `json:"hdmovielogo"`
+			MovieLogo       []Image `json:"movielogo"`
+			MovieBackground []Image `json:"moviebackground"`
+			MoviePoster     []Image `json:"movieposter"`
+		}
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, err
+		}
+		return &Artwork{
+			Logos:       append(r.HDMovieLogo, r.MovieLogo...),
+			Backgrounds: r.MovieBackground,
+			Posters:     r.MoviePoster,
+		}, nil
+	})
+}
+
+func (c *Client) artwork(ctx context.Context, path string, decode func([]byte) (*Artwork, error)) (*Artwork, error) {
+	c.mu.Lock()
+	entry, ok := c.cache[path]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.artwork, nil
+	}
+
+	data, err := c.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	a, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("fanart.tv: decode response: %w", err)
+	}
+
+	c.mu.Lock()
+	if len(c.cache) >= maxCacheEntries {
+		clear(c.cache)
+	}
+	c.cache[path] = cached{artwork: a, expires: c.now().Add(cacheTTL)}
+	c.mu.Unlock()
+	return a, nil
+}
+
+func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?api_key="+url.QueryEscape(c.apiKey), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fanart.tv: GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("fanart.tv: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"error message"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: e.Message}
+	}
+	return body, nil
+}
@@ -0,0 +1,102 @@
+package fanart
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestClient creates a client with a mock server
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient("test-key")
+	client.SetBaseURL(server.URL)
+	return client
+}
+
+func TestClient_Show(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/tv/371980" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("api_key"); got != "test-key" {
+			t.Errorf("expected the key in the query, got %q", got)
+		}
+		_, _ = w.Write([]byte(`{"name":"Severance","thetvdb_id":"371980",
+			"hdtvlogo":[{"id":"1","url":"https://assets.fanart.tv/fanart/tv/371980/hdtvlogo/de.png","lang":"de","likes":"9"},
+				{"id":"2","url":"https://assets.fanart.tv/fanart/tv/371980/hdtvlogo/en-few.png","lang":"en","likes":"1"},
+				{"id":"3","url":"https://assets.fanart.tv/fanart/tv/371980/hdtvlogo/en-many.png","lang":"en","likes":"5"}],
+			"showbackground":[{"id":"4","url":"https://assets.fanart.tv/fanart/tv/371980/showbackground/a.jpg","lang":"","likes":"2"},
+				{"id":"5","url":"https://assets.fanart.tv/fanart/tv/371980/showbackground/b.jpg","lang":"","likes":"7"}]}`))
+	}))
+
+	a, err := client.Show(context.Background(), 371980)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := a.Logo("en"); got != "https://assets.fanart.tv/fanart/tv/371980/hdtvlogo/en-many.png" {
+		t.Errorf("expected the most liked English logo, got %q", got)
+	}
+	if got := a.Logo("de"); got != "https://assets.fanart.tv/fanart/tv/371980/hdtvlogo/de.png" {
+		t.Errorf("expected the German logo, got %q", got)
+	}
+	if got := a.Background(); got != "https://assets.fanart.tv/fanart/tv/371980/showbackground/b.jpg" {
+		t.Errorf("expected the most liked background, got %q", got)
+	}
+	if got := a.Poster("en"); got != "" {
+		t.Errorf("expected no poster, got %q", got)
+	}
+
+	// Served from the cache the second time
+	if _, err := client.Show(context.Background(), 371980); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestClient_Movie(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/movies/438631" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"name":"Dune","tmdb_id":"438631",
+			"movielogo":[{"url":"https://assets.fanart.tv/fanart/movies/438631/movielogo/sd.png","lang":"en","likes":"3"}],
+			"movieposter":[{"url":"https://assets.fanart.tv/fanart/movies/438631/movieposter/p.jpg","lang":"en","likes":"1"}]}`))
+	}))
+
+	a, err := client.Movie(context.Background(), "438631")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := a.Logo("en"); got != "https://assets.fanart.tv/fanart/movies/438631/movielogo/sd.png" {
+		t.Errorf("unexpected logo %q", got)
+	}
+	if got := a.Poster("en"); got != "https://assets.fanart.tv/fanart/movies/438631/movieposter/p.jpg" {
+		t.Errorf("unexpected poster %q", got)
+	}
+}
+
+func TestClient_NotFound(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status":"error","error message":"Not found"}`))
+	}))
+
+	_, err := client.Show(context.Background(), 1)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if got := err.Error(); got != "fanart.tv: 404: Not found" {
+		t.Errorf("unexpected message %q", got)
+	}
+}
@@ -0,0 +1,102 @@
+// Package guess works out what a video file is from its name, the way
+// tools such as guessit do: the title, and the year of a movie or the
+// season and episode of a show's episode. Names like
+// "Severance.S02E01.1080p.WEB.h264-GROUP.mkv" and
+// "Blade Runner 2049 (2017) [BluRay].mkv" are typical.
+package guess
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Guess is what a file name says about the video.
+type Guess struct {
+	Title   string // of the movie or show
+	Year    int    // 0 if the name has none
+	Season  int
+	Episode int // 0 for a movie
+}
+
+// IsEpisode reports whether the name is of a show's episode.
+func (g Guess) IsEpisode() bool { return g.Episode > 0 }
+
+var (
+	// episodePatterns match an episode marker; the title comes before it.
+	episodePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bS(\d{1,2}) ?E(\d{1,3})\b`),
+		regexp.MustCompile(`(?i)\b(\d{1,2})x(\d{2,3})\b`),
+		regexp.MustCompile(`(?i)\bSeason (\d{1,2}) Episode (\d{1,3})\b`),
+	}
+	yearPattern     = regexp.MustCompile(`\b(19\d\d|20\d\d)\b`)
+	bracketPattern  = regexp.MustCompile(`\[[^\]]*\]|\{[^}]*\}`)
+	separatorsRegex = regexp.MustCompile(`[._]+`)
+	spacePattern    = regexp.MustCompile(`\s+`)
+	// junkPattern matches the first of the release details that follow
+	// the title when there's no year or episode marker to end it.
+	junkPattern = regexp.MustCompile(`(?i)\b(480p|576p|720p|1080p|2160p|4k|uhd|hdr|bluray|blu-ray|bdrip|brrip|web-?dl|webrip|web|hdtv|dvdrip|dvd|xvid|x264|x265|h ?264|h ?265|hevc|aac|ac3|dts|remux|proper|repack|extended|unrated|directors cut|multi)\b`)
+)
+
+// videoExtensions are the extensions Parse strips.
+var videoExtensions = map[string]bool{
+	".mkv": true, ".mp4": true, ".m4v": true, ".avi": true, ".mov": true,
+	".wmv": true, ".webm": true, ".ts": true, ".m2ts": true, ".mpg": true,
+	".mpeg": true, ".ogv": true, ".flv": true,
+}
+
+// Parse guesses what the video named name is. name may be a path or a
+// file:// URL; only its last element is read.
+func Parse(name string) Guess {
+	name = path.Base(strings.TrimPrefix(name, "file://"))
+	if ext := path.Ext(name); videoExtensions[strings.ToLower(ext)] {
+		name = strings.TrimSuffix(name, ext)
+	}
+	name = bracketPattern.ReplaceAllString(name, " ")
+	name = separatorsRegex.ReplaceAllString(name, " ")
+	name = strings.NewReplacer("(", " ", ")", " ").Replace(name)
+	name = spacePattern.ReplaceAllString(name, " ")
+
+	for _, re := range episodePatterns {
+		if m := re.FindStringSubmatchIndex(name); m != nil && m[0] > 0 {
+			season, _ := strconv.Atoi(name[m[2]:m[3]])
+			episode, _ := strconv.Atoi(name[m[4]:m[5]])
+			g := Guess{Season: season, Episode: episode}
+			g.Title, g.Year = titleAndYear(name[:m[0]])
+			return g
+		}
+	}
+
+	title, year := titleAndYear(name)
+	return Guess{Title: title, Year: year}
+}
+
+// titleAndYear splits s into a title and the release year after it, if
+// any. The last plausible year with some title before it wins, so a title
+// that is or has a year, like "1917 (2019)" or "Blade Runner 2049", keeps
+// it.
+func titleAndYear(s string) (string, int) {
+	latest := time.Now().Year() + 1
+	matches := yearPattern.FindAllStringSubmatchIndex(s, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		year, _ := strconv.Atoi(s[m[2]:m[3]])
+		if year > latest {
+			continue
+		}
+		if title := cleanTitle(s[:m[0]]); title != "" {
+			return title, year
+		}
+	}
+	return cleanTitle(s), 0
+}
+
+// cleanTitle cuts release details off s and tidies what's left.
+func cleanTitle(s string) string {
+	if loc := junkPattern.FindStringIndex(s); loc != nil {
+		s = s[:loc[0]]
+	}
+	return strings.Trim(strings.TrimSpace(s), " -")
+}
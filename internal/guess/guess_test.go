@@ -0,0 +1,37 @@
+package guess
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		want Guess
+	}{
+		{"Severance.S02E01.1080p.WEB.h264-GROUP.mkv", Guess{Title: "Severance", Season: 2, Episode: 1}},
+		{"/media/tv/The Office (US)/The.Office.US.2005.S03E10.720p.mkv", Guess{Title: "The Office US", Year: 2005, Season: 3, Episode: 10}},
+		{"file:///home/me/Videos/doctor_who_2005_-_1x01_-_rose.avi", Guess{Title: "doctor who", Year: 2005, Season: 1, Episode: 1}},
+		{"Show Name - Season 1 Episode 4.mp4", Guess{Title: "Show Name", Season: 1, Episode: 4}},
+		{"Blade Runner 2049 (2017) [BluRay].mkv", Guess{Title: "Blade Runner 2049", Year: 2017}},
+		{"Blade.Runner.2049.2160p.UHD.mkv", Guess{Title: "Blade Runner 2049"}},
+		{"2001.A.Space.Odyssey.1968.REMASTERED.mkv", Guess{Title: "2001 A Space Odyssey", Year: 1968}},
+		{"1917.2019.1080p.BluRay.x264.mkv", Guess{Title: "1917", Year: 2019}},
+		{"[Group] Spirited Away.mkv", Guess{Title: "Spirited Away"}},
+		{"Dune.Part.Two.WEB-DL.mp4", Guess{Title: "Dune Part Two"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.name); got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuess_IsEpisode(t *testing.T) {
+	if Parse("Dune.2021.mkv").IsEpisode() {
+		t.Error("expected a movie")
+	}
+	if !Parse("Severance.S01E01.mkv").IsEpisode() {
+		t.Error("expected an episode")
+	}
+}
@@ -0,0 +1,117 @@
+// Package ical writes iCalendar (RFC 5545) feeds, the format calendar apps
+// such as Google Calendar and Apple Calendar subscribe to.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// ProductID identifies the software that wrote a calendar.
+const ProductID = "-//trakt-mcp//Trakt calendar//EN"
+
+// maxLineOctets is the longest a content line may be before it's folded.
+const maxLineOctets = 75
+
+// Event is one calendar entry.
+type Event struct {
+	UID         string // stable across feeds, so updates replace the event
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         time.Time // exclusive; for all-day events, the day after the last
+	AllDay      bool      // Start and End are dates, without a time
+}
+
+// Calendar is a named collection of events.
+type Calendar struct {
+	Name    string
+	Events  []Event
+	Updated time.Time // stamped on every event; defaults to now
+}
+
+// Write writes cal to w as an iCalendar stream.
+func Write(w io.Writer, cal Calendar) error {
+	stamp := cal.Updated
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", ProductID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", escape(cal.Name))
+	}
+	for _, e := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", utc(stamp))
+		if e.AllDay {
+			line("DTSTART;VALUE=DATE", e.Start.Format("20060102"))
+			line("DTEND;VALUE=DATE", e.End.Format("20060102"))
+		} else {
+			line("DTSTART", utc(e.Start))
+			line("DTEND", utc(e.End))
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// utc formats t as a UTC date-time.
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes a text value.
+var escape = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+).Replace
+
+// writeFolded writes a content line ending in CRLF, folding it onto
+// continuation lines that start with a space so that none is longer than
+// maxLineOctets, without splitting a UTF-8 character.
+func writeFolded(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !startsRune(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts toward the continuation line's length
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// startsRune reports whether b is the first byte of a UTF-8 character.
+func startsRune(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,72 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWrite(t *testing.T) {
+	var sb strings.Builder
+	err := Write(&sb, Calendar{
+		Name:    "Trakt",
+		Updated: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		Events: []Event{
+			{
+				UID:         "episode-101@trakt-mcp",
+				Summary:     "Severance S02E01: Hello, Ms. Cobel",
+				Description: "Mark returns; things have changed.\nA lot.",
+				URL:         "https://trakt.tv/shows/severance/seasons/2/episodes/1",
+				Start:       time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC),
+				End:         time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC),
+			},
+			{
+				UID:     "movie-7@trakt-mcp",
+				Summary: "Dune: Part Three",
+				Start:   time.Date(2026, 12, 18, 0, 0, 0, 0, time.UTC),
+				End:     time.Date(2026, 12, 19, 0, 0, 0, 0, time.UTC),
+				AllDay:  true,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//trakt-mcp//Trakt calendar//EN\r\nCALSCALE:GREGORIAN\r\nMETHOD:PUBLISH\r\nX-WR-CALNAME:Trakt\r\n" +
+		"BEGIN:VEVENT\r\nUID:episode-101@trakt-mcp\r\nDTSTAMP:20261016T120000Z\r\nDTSTART:20261017T010000Z\r\nDTEND:20261017T020000Z\r\n" +
+		"SUMMARY:Severance S02E01: Hello\\, Ms. Cobel\r\nDESCRIPTION:Mark returns\\; things have changed.\\nA lot.\r\n" +
+		"URL:https://trakt.tv/shows/severance/seasons/2/episodes/1\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:movie-7@trakt-mcp\r\nDTSTAMP:20261016T120000Z\r\nDTSTART;VALUE=DATE:20261218\r\nDTEND;VALUE=DATE:20261219\r\n" +
+		"SUMMARY:Dune: Part Three\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if got := sb.String(); got != want {
+		t.Errorf("Write() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWrite_FoldsLongLines(t *testing.T) {
+	var sb strings.Builder
+	description := strings.Repeat("Ünïcödé ", 30)
+	if err := Write(&sb, Calendar{Events: []Event{{UID: "x", Summary: "x", Description: description}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var unfolded strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(sb.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a character: %q", line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+		} else {
+			unfolded.WriteString("\n" + line)
+		}
+	}
+	if !strings.Contains(unfolded.String(), "\nDESCRIPTION:"+description+"\n") {
+		t.Errorf("unfolding didn't give back the description:\n%s", unfolded.String())
+	}
+}
@@ -0,0 +1,420 @@
+// Package importer brings watch history and ratings exported from other
+// tracking services into a Trakt account. Each service's export is read
+// into an Export, whose shows and movies are matched to Trakt by the TMDB,
+// TVDB and IMDb IDs the service keeps, and whatever matched is added to
+// history and ratings. Plays the account already has are skipped, so an
+// import can be run again.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// IDs are the IDs a service knows a show or movie by. Zero values are
+// unknown.
+type IDs struct {
+	IMDB string
+	TMDB int
+	TVDB int
+}
+
+// Title is a show or movie in an export.
+type Title struct {
+	Name string
+	Year int
+	Show bool // a show rather than a movie
+	IDs  IDs
+}
+
+// String names the title for reports.
+func (t Title) String() string {
+	if t.Year > 0 {
+		return fmt.Sprintf("%s (%d)", t.Name, t.Year)
+	}
+	return t.Name
+}
+
+// Play is one watch of a movie, or of an episode of a show.
+type Play struct {
+	Title      Title
+	Season     int // episodes only
+	Episode    int
+	EpisodeIDs IDs       // the episode's own, if the service knows them
+	WatchedAt  time.Time // zero if unknown, for Trakt to use the release date
+}
+
+// Rating is a 1-10 rating of a movie or a whole show.
+type Rating struct {
+	Title   Title
+	Rating  int
+	RatedAt time.Time // zero if unknown
+}
+
+// Export is the history and ratings read from a service's export.
+type Export struct {
+	Plays     []Play
+	Ratings   []Rating
+	Unmatched []Unmatched // entries too incomplete to match
+}
+
+// source is a service whose exports can be imported.
+type source struct {
+	name  string
+	parse func(io.Reader) (*Export, error)
+}
+
+// sources are the services Parse reads exports of, by the name it takes.
+var sources = map[string]source{
+	"simkl":  {"Simkl", ParseSimkl},
+	"tvtime": {"TV Time", ParseTVTime},
+}
+
+// Sources are the names of the services Parse reads exports of, in order.
+func Sources() []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name is the service named by source as its users know it, such as
+// "Simkl" for "simkl".
+func Name(source string) string {
+	if s, ok := sources[source]; ok {
+		return s.name
+	}
+	return source
+}
+
+// Parse reads an export of source, one of Sources.
+func Parse(source string, r io.Reader) (*Export, error) {
+	s, ok := sources[source]
+	if !ok {
+		return nil, fmt.Errorf("unknown source %q", source)
+	}
+	return s.parse(r)
+}
+
+// Sink is the Trakt API an export is imported to, as trakt.Client does.
+type Sink interface {
+	LookupID(ctx context.Context, idType, id, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error)
+	GetSeasons(ctx context.Context, showID string, opts ...trakt.RequestOption) ([]trakt.Season, error)
+	ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error
+	AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error)
+	AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error)
+}
+
+// Unmatched is something in an export that couldn't be found on Trakt.
+type Unmatched struct {
+	Title  string // the show or movie, with the episode if it's one
+	Reason string
+}
+
+// Result is what an import added, or with a dry run would add.
+type Result struct {
+	Plays     int
+	Ratings   int
+	Skipped   int // plays the account already had
+	Unmatched []Unmatched
+}
+
+// Import matches exp to Trakt and adds its plays and ratings to the
+// account, or with dryRun only works out what it would add. Plays are
+// added with their original watch times, and ratings replace any the
+// account has.
+//
+// If a write fails, Import stops and returns what it added so far with the
+// error.
+func Import(ctx context.Context, sink Sink, exp *Export, dryRun bool) (Result, error) {
+	r := Result{Unmatched: slices.Clone(exp.Unmatched)}
+	m := matcher{sink: sink, titles: make(map[Title]*match)}
+
+	var plays []resolvedPlay
+	for _, p := range exp.Plays {
+		rp, reason, err := m.play(ctx, p)
+		if err != nil {
+			return r, err
+		}
+		if reason != "" {
+			r.Unmatched = append(r.Unmatched, Unmatched{Title: playName(p), Reason: reason})
+			continue
+		}
+		plays = append(plays, rp)
+	}
+
+	var ratings trakt.RatingItem
+	for _, rating := range exp.Ratings {
+		t, err := m.title(ctx, rating.Title)
+		if err != nil {
+			return r, err
+		}
+		if t.reason != "" {
+			r.Unmatched = append(r.Unmatched, Unmatched{Title: rating.Title.String(), Reason: t.reason})
+			continue
+		}
+		ratedAt := ""
+		if !rating.RatedAt.IsZero() {
+			ratedAt = rating.RatedAt.UTC().Format(time.RFC3339)
+		}
+		if rating.Title.Show {
+			ratings.Shows = append(ratings.Shows, trakt.RatedShow{Rating: rating.Rating, RatedAt: ratedAt, IDs: trakt.ShowIDs{Trakt: t.traktID}})
+		} else {
+			ratings.Movies = append(ratings.Movies, trakt.RatedMovie{Rating: rating.Rating, RatedAt: ratedAt, IDs: trakt.MovieIDs{Trakt: t.traktID}})
+		}
+	}
+
+	matched := len(plays)
+	plays, err := newPlays(ctx, sink, plays)
+	if err != nil {
+		return r, err
+	}
+	r.Skipped = matched - len(plays)
+
+	if dryRun {
+		r.Plays = len(plays)
+		r.Ratings = len(ratings.Shows) + len(ratings.Movies)
+		return r, nil
+	}
+	if err := addPlays(ctx, sink, plays, &r); err != nil {
+		return r, err
+	}
+	if n := len(ratings.Shows) + len(ratings.Movies); n > 0 {
+		if _, err := sink.AddRatings(ctx, ratings); err != nil {
+			return r, fmt.Errorf("add ratings: %w", err)
+		}
+		r.Ratings = n
+	}
+	return r, nil
+}
+
+// resolvedPlay is a play matched to a Trakt movie or episode.
+type resolvedPlay struct {
+	movie     bool
+	traktID   int
+	watchedAt time.Time
+}
+
+// key identifies the play in the account's history.
+func (p resolvedPlay) key() string {
+	return fmt.Sprintf("%t/%d/%d", p.movie, p.traktID, p.watchedAt.Unix())
+}
+
+// match is a title matched to Trakt, or the reason it wasn't.
+type match struct {
+	traktID  int
+	reason   string
+	episodes map[[2]int]int // season and number to Trakt ID, for shows
+}
+
+// matcher matches titles to Trakt, each once.
+type matcher struct {
+	sink   Sink
+	titles map[Title]*match
+}
+
+// title matches t to a Trakt show or movie by the first of its TMDB, TVDB
+// and IMDb IDs that Trakt knows.
+func (m *matcher) title(ctx context.Context, t Title) (*match, error) {
+	if found, ok := m.titles[t]; ok {
+		return found, nil
+	}
+
+	kind := "movie"
+	if t.Show {
+		kind = "show"
+	}
+	found := &match{reason: "not found on Trakt by its IDs"}
+	lookups := []struct{ idType, id string }{
+		{"tmdb", nonZero(t.IDs.TMDB)},
+		{"tvdb", nonZero(t.IDs.TVDB)},
+		{"imdb", t.IDs.IMDB},
+	}
+	if t.IDs == (IDs{}) {
+		found.reason = "no TMDB, TVDB or IMDb ID"
+	}
+	for _, l := range lookups {
+		if l.id == "" {
+			continue
+		}
+		results, err := m.sink.LookupID(ctx, l.idType, l.id, kind)
+		if err != nil {
+			return nil, fmt.Errorf("look up %s: %w", t, err)
+		}
+		if id := resultID(results, t.Show); id != 0 {
+			found = &match{traktID: id}
+			break
+		}
+	}
+	m.titles[t] = found
+	return found, nil
+}
+
+// play matches p to a Trakt movie or episode, or returns why it can't be.
+// Episodes are found by number in their show's seasons, or else by their
+// own IDs, as services that number episodes differently may know them.
+func (m *matcher) play(ctx context.Context, p Play) (resolvedPlay, string, error) {
+	t, err := m.title(ctx, p.Title)
+	if err != nil {
+		return resolvedPlay{}, "", err
+	}
+	if !p.Title.Show {
+		if t.reason != "" {
+			return resolvedPlay{}, t.reason, nil
+		}
+		return resolvedPlay{movie: true, traktID: t.traktID, watchedAt: p.WatchedAt}, "", nil
+	}
+
+	reason := t.reason
+	if reason == "" {
+		if t.episodes == nil {
+			seasons, err := m.sink.GetSeasons(ctx, strconv.Itoa(t.traktID), trakt.WithExtended(trakt.ExtendedEpisodes))
+			if err != nil {
+				return resolvedPlay{}, "", fmt.Errorf("read seasons of %s: %w", p.Title, err)
+			}
+			t.episodes = make(map[[2]int]int)
+			for _, s := range seasons {
+				for _, e := range s.Episodes {
+					t.episodes[[2]int{s.Number, e.Number}] = e.IDs.Trakt
+				}
+			}
+		}
+		if id, ok := t.episodes[[2]int{p.Season, p.Episode}]; ok {
+			return resolvedPlay{traktID: id, watchedAt: p.WatchedAt}, "", nil
+		}
+		reason = "no such episode on Trakt"
+	}
+
+	id, err := m.episode(ctx, p)
+	if err != nil || id == 0 {
+		return resolvedPlay{}, reason, err
+	}
+	return resolvedPlay{traktID: id, watchedAt: p.WatchedAt}, "", nil
+}
+
+// episode matches p to a Trakt episode by the first of the episode's own
+// TVDB, TMDB and IMDb IDs that Trakt knows, or returns 0.
+func (m *matcher) episode(ctx context.Context, p Play) (int, error) {
+	lookups := []struct{ idType, id string }{
+		{"tvdb", nonZero(p.EpisodeIDs.TVDB)},
+		{"tmdb", nonZero(p.EpisodeIDs.TMDB)},
+		{"imdb", p.EpisodeIDs.IMDB},
+	}
+	for _, l := range lookups {
+		if l.id == "" {
+			continue
+		}
+		results, err := m.sink.LookupID(ctx, l.idType, l.id, "episode")
+		if err != nil {
+			return 0, fmt.Errorf("look up %s: %w", playName(p), err)
+		}
+		for _, r := range results {
+			if r.Episode != nil {
+				return r.Episode.IDs.Trakt, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// resultID returns the Trakt ID of the first show or movie in results.
+func resultID(results []trakt.SearchResult, show bool) int {
+	for _, r := range results {
+		switch {
+		case show && r.Show != nil:
+			return r.Show.IDs.Trakt
+		case !show && r.Movie != nil:
+			return r.Movie.IDs.Trakt
+		}
+	}
+	return 0
+}
+
+// newPlays leaves out plays the account's history already has, at the
+// same time, or for plays without a time, at any time.
+func newPlays(ctx context.Context, sink Sink, plays []resolvedPlay) ([]resolvedPlay, error) {
+	if len(plays) == 0 {
+		return nil, nil
+	}
+	have := make(map[string]bool)
+	err := sink.ForEachHistoryItem(ctx, "", func(h trakt.HistoryItem) error {
+		var p resolvedPlay
+		switch {
+		case h.Movie != nil:
+			p = resolvedPlay{movie: true, traktID: h.Movie.IDs.Trakt}
+		case h.Episode != nil:
+			p = resolvedPlay{traktID: h.Episode.IDs.Trakt}
+		default:
+			return nil
+		}
+		have[p.key()] = true
+		p.watchedAt = h.WatchedAt
+		have[p.key()] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	var out []resolvedPlay
+	for _, p := range plays {
+		if !have[p.key()] {
+			have[p.key()] = true // once, even if the export lists it twice
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// addPlays adds plays to history, oldest first, in one request per watch
+// time, since a history write carries a single time.
+func addPlays(ctx context.Context, sink Sink, plays []resolvedPlay, r *Result) error {
+	sort.SliceStable(plays, func(i, j int) bool { return plays[i].watchedAt.Before(plays[j].watchedAt) })
+
+	for start := 0; start < len(plays); {
+		at := plays[start].watchedAt
+		item := trakt.WatchedItem{WatchedAt: "released"}
+		if !at.IsZero() {
+			item.WatchedAt = at.UTC().Format(time.RFC3339)
+		}
+		end := start
+		for ; end < len(plays) && plays[end].watchedAt.Equal(at); end++ {
+			if p := plays[end]; p.movie {
+				item.Movies = append(item.Movies, trakt.Movie{IDs: trakt.MovieIDs{Trakt: p.traktID}})
+			} else {
+				item.Episodes = append(item.Episodes, trakt.Episode{IDs: trakt.EpisodeIDs{Trakt: p.traktID}})
+			}
+		}
+		start = end
+
+		if _, err := sink.AddToHistory(ctx, item); err != nil {
+			return fmt.Errorf("add plays: %w", err)
+		}
+		r.Plays += len(item.Movies) + len(item.Episodes)
+	}
+	return nil
+}
+
+// playName names a play for reports.
+func playName(p Play) string {
+	if p.Title.Show {
+		return fmt.Sprintf("%s S%02dE%02d", p.Title, p.Season, p.Episode)
+	}
+	return p.Title.String()
+}
+
+func nonZero(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
@@ -0,0 +1,182 @@
+package importer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// fakeAccount is a Trakt account an export can be imported to. It knows
+// Severance (Trakt 1, TVDB 371980) with one season of two episodes, and
+// Dune (Trakt 2, TMDB 438631), and a lone episode by its TVDB ID 7654321
+// (Trakt 99).
+type fakeAccount struct {
+	history []trakt.HistoryItem
+	added   []trakt.WatchedItem
+	ratings []trakt.RatingItem
+	lookups int
+}
+
+func (f *fakeAccount) LookupID(ctx context.Context, idType, id, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error) {
+	f.lookups++
+	switch {
+	case idType == "tvdb" && id == "371980" && searchType == "show":
+		return []trakt.SearchResult{{Type: "show", Show: &trakt.Show{IDs: trakt.ShowIDs{Trakt: 1}}}}, nil
+	case idType == "tmdb" && id == "438631" && searchType == "movie":
+		return []trakt.SearchResult{{Type: "movie", Movie: &trakt.Movie{IDs: trakt.MovieIDs{Trakt: 2}}}}, nil
+	case idType == "tvdb" && id == "7654321" && searchType == "episode":
+		return []trakt.SearchResult{{Type: "episode", Episode: &trakt.Episode{IDs: trakt.EpisodeIDs{Trakt: 99}}}}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeAccount) GetSeasons(ctx context.Context, showID string, opts ...trakt.RequestOption) ([]trakt.Season, error) {
+	return []trakt.Season{{Number: 1, Episodes: []trakt.Episode{
+		{Number: 1, IDs: trakt.EpisodeIDs{Trakt: 11}},
+		{Number: 2, IDs: trakt.EpisodeIDs{Trakt: 12}},
+	}}}, nil
+}
+
+func (f *fakeAccount) ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error {
+	for _, h := range f.history {
+		if err := fn(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeAccount) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	f.added = append(f.added, item)
+	return &trakt.SyncResponse{}, nil
+}
+
+func (f *fakeAccount) AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error) {
+	f.ratings = append(f.ratings, item)
+	return &trakt.SyncResponse{}, nil
+}
+
+var (
+	severance = Title{Name: "Severance", Year: 2022, Show: true, IDs: IDs{IMDB: "tt11280740", TMDB: 95396, TVDB: 371980}}
+	dune      = Title{Name: "Dune", Year: 2021, IDs: IDs{TMDB: 438631}}
+	march     = func(d int) time.Time { return time.Date(2026, 3, d, 20, 0, 0, 0, time.UTC) }
+)
+
+func TestImport(t *testing.T) {
+	acct := &fakeAccount{history: []trakt.HistoryItem{
+		{WatchedAt: march(1), Episode: &trakt.Episode{IDs: trakt.EpisodeIDs{Trakt: 11}}},
+	}}
+	exp := &Export{
+		Plays: []Play{
+			{Title: severance, Season: 1, Episode: 1, WatchedAt: march(1)}, // already in history
+			{Title: severance, Season: 1, Episode: 2, WatchedAt: march(2)},
+			{Title: severance, Season: 1, Episode: 9, WatchedAt: march(2)},
+			{Title: dune, WatchedAt: march(2)},
+			{Title: dune},
+			{Title: Title{Name: "Obscure", Year: 1999, IDs: IDs{IMDB: "tt0000001"}}},
+		},
+		Ratings: []Rating{
+			{Title: dune, Rating: 9, RatedAt: march(3)},
+			{Title: severance, Rating: 10},
+			{Title: Title{Name: "No IDs"}, Rating: 5},
+		},
+	}
+
+	r, err := Import(context.Background(), acct, exp, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Plays != 3 || r.Ratings != 2 || r.Skipped != 1 || len(r.Unmatched) != 3 {
+		t.Fatalf("unexpected dry run result %+v", r)
+	}
+	if len(acct.added) != 0 || len(acct.ratings) != 0 {
+		t.Fatal("expected a dry run to write nothing")
+	}
+	want := []Unmatched{
+		{Title: "Severance (2022) S01E09", Reason: "no such episode on Trakt"},
+		{Title: "Obscure (1999)", Reason: "not found on Trakt by its IDs"},
+		{Title: "No IDs", Reason: "no TMDB, TVDB or IMDb ID"},
+	}
+	for i, u := range want {
+		if r.Unmatched[i] != u {
+			t.Errorf("unmatched[%d] = %+v, want %+v", i, r.Unmatched[i], u)
+		}
+	}
+	// Severance by TMDB then TVDB, Dune, Obscure; each once
+	if acct.lookups != 4 {
+		t.Errorf("expected 4 lookups, got %d", acct.lookups)
+	}
+
+	r, err = Import(context.Background(), acct, exp, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Plays != 3 || r.Ratings != 2 {
+		t.Fatalf("unexpected result %+v", r)
+	}
+	// Undated plays first, then one write per watch time
+	if len(acct.added) != 2 {
+		t.Fatalf("expected 2 history writes, got %+v", acct.added)
+	}
+	if w := acct.added[0]; w.WatchedAt != "released" || len(w.Movies) != 1 || w.Movies[0].IDs.Trakt != 2 {
+		t.Errorf("unexpected first write %+v", w)
+	}
+	if w := acct.added[1]; w.WatchedAt != "2026-03-02T20:00:00Z" || len(w.Movies) != 1 || len(w.Episodes) != 1 || w.Episodes[0].IDs.Trakt != 12 {
+		t.Errorf("unexpected second write %+v", w)
+	}
+	if len(acct.ratings) != 1 {
+		t.Fatalf("expected one ratings write, got %+v", acct.ratings)
+	}
+	rated := acct.ratings[0]
+	if len(rated.Movies) != 1 || rated.Movies[0].Rating != 9 || rated.Movies[0].RatedAt != "2026-03-03T20:00:00Z" {
+		t.Errorf("unexpected movie ratings %+v", rated.Movies)
+	}
+	if len(rated.Shows) != 1 || rated.Shows[0].IDs.Trakt != 1 || rated.Shows[0].RatedAt != "" {
+		t.Errorf("unexpected show ratings %+v", rated.Shows)
+	}
+}
+
+func TestImport_SkipsUndatedPlaysAlreadyWatched(t *testing.T) {
+	acct := &fakeAccount{history: []trakt.HistoryItem{
+		{WatchedAt: march(5), Movie: &trakt.Movie{IDs: trakt.MovieIDs{Trakt: 2}}},
+	}}
+	exp := &Export{Plays: []Play{{Title: dune}, {Title: dune, WatchedAt: march(5)}}}
+
+	r, err := Import(context.Background(), acct, exp, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Plays != 0 || r.Skipped != 2 || len(acct.added) != 0 {
+		t.Errorf("expected both plays skipped, got %+v and writes %+v", r, acct.added)
+	}
+}
+
+func TestImport_MatchesEpisodesByTheirIDs(t *testing.T) {
+	acct := &fakeAccount{}
+	unknown := Title{Name: "Unknown", Show: true, IDs: IDs{TVDB: 1}}
+	exp := &Export{
+		Plays: []Play{
+			// Numbered differently on Trakt, but known by its ID
+			{Title: severance, Season: 2, Episode: 1, EpisodeIDs: IDs{TVDB: 7654321}, WatchedAt: march(1)},
+			{Title: unknown, Season: 1, Episode: 1, EpisodeIDs: IDs{TVDB: 1111}},
+		},
+		Unmatched: []Unmatched{{Title: "row 3: Severance", Reason: "no season and episode number"}},
+	}
+
+	r, err := Import(context.Background(), acct, exp, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Plays != 1 || len(acct.added) != 1 || acct.added[0].Episodes[0].IDs.Trakt != 99 {
+		t.Errorf("expected the episode matched by ID, got %+v and writes %+v", r, acct.added)
+	}
+	want := []Unmatched{
+		{Title: "row 3: Severance", Reason: "no season and episode number"},
+		{Title: "Unknown S01E01", Reason: "not found on Trakt by its IDs"},
+	}
+	if len(r.Unmatched) != len(want) || r.Unmatched[0] != want[0] || r.Unmatched[1] != want[1] {
+		t.Errorf("unmatched = %+v, want %+v", r.Unmatched, want)
+	}
+}
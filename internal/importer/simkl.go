@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// simklExport is Simkl's backup, the JSON of its all-items sync listing.
+type simklExport struct {
+	Shows  []simklItem `json:"shows"`
+	Anime  []simklItem `json:"anime"`
+	Movies []simklItem `json:"movies"`
+}
+
+type simklItem struct {
+	Status        string        `json:"status"` // "watching", "completed", "plantowatch", "hold", "dropped"
+	LastWatchedAt string        `json:"last_watched_at"`
+	UserRating    int           `json:"user_rating"`
+	UserRatedAt   string        `json:"user_rated_at"`
+	AnimeType     string        `json:"anime_type"` // "tv", "movie", ...
+	Show          *simklTitle   `json:"show"`
+	Movie         *simklTitle   `json:"movie"`
+	Seasons       []simklSeason `json:"seasons"`
+}
+
+type simklTitle struct {
+	Title string   `json:"title"`
+	Year  int      `json:"year"`
+	IDs   simklIDs `json:"ids"`
+}
+
+type simklIDs struct {
+	IMDB string  `json:"imdb"`
+	TMDB flexInt `json:"tmdb"`
+	TVDB flexInt `json:"tvdb"`
+}
+
+type simklSeason struct {
+	Number   int `json:"number"`
+	Episodes []struct {
+		Number    int    `json:"number"`
+		WatchedAt string `json:"watched_at"`
+	} `json:"episodes"`
+}
+
+// flexInt is an ID Simkl writes as either a number or a string.
+type flexInt int
+
+func (n *flexInt) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid ID %s", b)
+	}
+	*n = flexInt(v)
+	return nil
+}
+
+// ParseSimkl reads a Simkl backup: the watched episodes of its shows and
+// anime, its completed movies, and the ratings of both. Plan-to-watch
+// entries carry no plays. Plays without a date are left for Trakt to date
+// at release.
+func ParseSimkl(r io.Reader) (*Export, error) {
+	var in simklExport
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("read Simkl backup: %w", err)
+	}
+
+	exp := &Export{}
+	var items []simklItem
+	items = append(items, in.Shows...)
+	items = append(items, in.Anime...)
+	items = append(items, in.Movies...)
+	for _, it := range items {
+		src := it.Show
+		if src == nil {
+			src = it.Movie
+		}
+		if src == nil {
+			continue
+		}
+		title := Title{
+			Name: src.Title,
+			Year: src.Year,
+			Show: it.Movie == nil && it.AnimeType != "movie",
+			IDs:  IDs{IMDB: src.IDs.IMDB, TMDB: int(src.IDs.TMDB), TVDB: int(src.IDs.TVDB)},
+		}
+
+		if it.UserRating > 0 {
+			exp.Ratings = append(exp.Ratings, Rating{Title: title, Rating: it.UserRating, RatedAt: parseSimklTime(it.UserRatedAt)})
+		}
+		if it.Status == "plantowatch" {
+			continue
+		}
+		if !title.Show {
+			if it.Status == "completed" || it.LastWatchedAt != "" {
+				exp.Plays = append(exp.Plays, Play{Title: title, WatchedAt: parseSimklTime(it.LastWatchedAt)})
+			}
+			continue
+		}
+		for _, s := range it.Seasons {
+			for _, e := range s.Episodes {
+				exp.Plays = append(exp.Plays, Play{Title: title, Season: s.Number, Episode: e.Number, WatchedAt: parseSimklTime(e.WatchedAt)})
+			}
+		}
+	}
+	return exp, nil
+}
+
+// parseSimklTime parses one of Simkl's timestamps, zero if there's none.
+func parseSimklTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,85 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+const simklBackup = `{
+  "shows": [
+    {
+      "status": "watching",
+      "last_watched_at": "2026-03-02T20:00:00Z",
+      "user_rating": 10,
+      "user_rated_at": "2026-03-02T21:00:00Z",
+      "show": {"title": "Severance", "year": 2022, "ids": {"simkl": 1, "imdb": "tt11280740", "tmdb": "95396", "tvdb": 371980}},
+      "seasons": [{"number": 1, "episodes": [
+        {"number": 1, "watched_at": "2026-03-01T20:00:00Z"},
+        {"number": 2}
+      ]}]
+    },
+    {
+      "status": "plantowatch",
+      "show": {"title": "Andor", "year": 2022, "ids": {"tmdb": "83867"}}
+    }
+  ],
+  "anime": [
+    {
+      "status": "completed",
+      "anime_type": "movie",
+      "last_watched_at": "2026-01-10T12:00:00Z",
+      "show": {"title": "Spirited Away", "year": 2001, "ids": {"mal": "199", "tmdb": "129"}}
+    }
+  ],
+  "movies": [
+    {
+      "status": "completed",
+      "user_rating": 9,
+      "movie": {"title": "Dune", "year": 2021, "ids": {"tmdb": "438631", "imdb": "tt1160419"}}
+    },
+    {
+      "status": "plantowatch",
+      "movie": {"title": "Arrival", "year": 2016, "ids": {"tmdb": 329865}}
+    }
+  ]
+}`
+
+func TestParseSimkl(t *testing.T) {
+	exp, err := ParseSimkl(strings.NewReader(simklBackup))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exp.Plays) != 4 {
+		t.Fatalf("expected 4 plays, got %+v", exp.Plays)
+	}
+	ep := exp.Plays[0]
+	if ep.Title != severance || ep.Season != 1 || ep.Episode != 1 || !ep.WatchedAt.Equal(march(1)) {
+		t.Errorf("unexpected first play %+v", ep)
+	}
+	if ep := exp.Plays[1]; ep.Episode != 2 || !ep.WatchedAt.IsZero() {
+		t.Errorf("expected an undated second episode, got %+v", ep)
+	}
+	if p := exp.Plays[2]; p.Title.Show || p.Title.Name != "Spirited Away" || p.Title.IDs.TMDB != 129 {
+		t.Errorf("expected the anime film as a movie, got %+v", p)
+	}
+	if p := exp.Plays[3]; p.Title.IDs != (IDs{IMDB: "tt1160419", TMDB: 438631}) || !p.WatchedAt.IsZero() {
+		t.Errorf("unexpected movie play %+v", p)
+	}
+
+	if len(exp.Ratings) != 2 {
+		t.Fatalf("expected 2 ratings, got %+v", exp.Ratings)
+	}
+	if r := exp.Ratings[0]; !r.Title.Show || r.Rating != 10 || r.RatedAt.IsZero() {
+		t.Errorf("unexpected show rating %+v", r)
+	}
+	if r := exp.Ratings[1]; r.Title.Name != "Dune" || r.Rating != 9 || !r.RatedAt.IsZero() {
+		t.Errorf("unexpected movie rating %+v", r)
+	}
+}
+
+func TestParseSimkl_Invalid(t *testing.T) {
+	if _, err := ParseSimkl(strings.NewReader(`{"movies": [{"movie": {"ids": {"tmdb": "abc"}}}]}`)); err == nil {
+		t.Error("expected an error for a malformed ID")
+	}
+}
@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tvTimeColumns are the names each column has gone by in TV Time's
+// exports, most recent first.
+var tvTimeColumns = map[string][]string{
+	"show":      {"series_name", "tv_show_name", "show_name"},
+	"showID":    {"series_id", "s_id", "tv_show_id", "show_id"},
+	"episodeID": {"episode_id", "ep_id"},
+	"season":    {"season_number", "episode_season_number"},
+	"episode":   {"episode_number"},
+	"kind":      {"entity_type"},
+	"time":      {"watched_at", "created_at", "updated_at"},
+}
+
+// tvTimeLayouts are the timestamp layouts TV Time writes, all in UTC.
+var tvTimeLayouts = []string{"2006-01-02 15:04:05", time.RFC3339, "2006-01-02"}
+
+// ParseTVTime reads the watched episodes in a TV Time data export's
+// tracking-prod-records CSV. TV Time knows shows and episodes by their
+// TVDB IDs, which the plays carry. Rows for anything but episodes are
+// left out, and episode rows without a show or episode number are listed
+// as unmatched, by row.
+func ParseTVTime(r io.Reader) (*Export, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read TV Time export: %w", err)
+	}
+	col := make(map[string]int)
+	for field, names := range tvTimeColumns {
+		col[field] = -1
+		for _, name := range names {
+			if i := indexOf(header, name); i >= 0 {
+				col[field] = i
+				break
+			}
+		}
+	}
+	if col["show"] < 0 && col["showID"] < 0 || col["episode"] < 0 {
+		return nil, errors.New("read TV Time export: no show and episode columns; use tracking-prod-records.csv")
+	}
+
+	exp := &Export{}
+	for row := 2; ; row++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read TV Time export: %w", err)
+		}
+		get := func(field string) string {
+			if i := col[field]; i >= 0 && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if kind := get("kind"); kind != "" && kind != "episode" {
+			continue
+		}
+
+		title := Title{Name: get("show"), Show: true}
+		title.IDs.TVDB, _ = strconv.Atoi(get("showID"))
+		season, errS := strconv.Atoi(get("season"))
+		number, errE := strconv.Atoi(get("episode"))
+		p := Play{Title: title, Season: season, Episode: number}
+		p.EpisodeIDs.TVDB, _ = strconv.Atoi(get("episodeID"))
+		if (errS != nil || errE != nil) && p.EpisodeIDs.TVDB == 0 {
+			exp.Unmatched = append(exp.Unmatched, Unmatched{Title: fmt.Sprintf("row %d: %s", row, tvTimeName(title)), Reason: "no season and episode number"})
+			continue
+		}
+		p.WatchedAt = parseTVTime(get("time"))
+		exp.Plays = append(exp.Plays, p)
+	}
+	return exp, nil
+}
+
+// tvTimeName names a show from a row that may lack its name.
+func tvTimeName(t Title) string {
+	switch {
+	case t.Name != "":
+		return t.Name
+	case t.IDs.TVDB != 0:
+		return fmt.Sprintf("TVDB show %d", t.IDs.TVDB)
+	}
+	return "unnamed show"
+}
+
+// parseTVTime parses one of TV Time's timestamps, zero if there's none.
+func parseTVTime(s string) time.Time {
+	for _, layout := range tvTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// indexOf returns the index of the column named name, ignoring case and a
+// byte order mark, or -1.
+func indexOf(header []string, name string) int {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTVTime(t *testing.T) {
+	export := "\ufeffuser_id,entity_type,series_name,series_id,season_number,episode_number,episode_id,created_at\n" +
+		"1,episode,Severance,371980,1,1,7654321,2026-03-01 20:00:00\n" +
+		"1,episode,Severance,371980,1,2,,\n" +
+		"1,movie,,,,,,2026-03-02 20:00:00\n" +
+		"1,episode,Lost,,,,,2026-03-03 20:00:00\n"
+
+	exp, err := ParseTVTime(strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exp.Plays) != 2 {
+		t.Fatalf("expected 2 plays, got %+v", exp.Plays)
+	}
+	p := exp.Plays[0]
+	if p.Title.Name != "Severance" || !p.Title.Show || p.Title.IDs.TVDB != 371980 || p.Season != 1 || p.Episode != 1 {
+		t.Errorf("unexpected first play %+v", p)
+	}
+	if p.EpisodeIDs.TVDB != 7654321 || !p.WatchedAt.Equal(march(1)) {
+		t.Errorf("expected the episode's ID and watch time, got %+v", p)
+	}
+	if p := exp.Plays[1]; p.Episode != 2 || !p.WatchedAt.IsZero() {
+		t.Errorf("expected an undated second episode, got %+v", p)
+	}
+
+	if len(exp.Unmatched) != 1 || exp.Unmatched[0] != (Unmatched{Title: "row 5: Lost", Reason: "no season and episode number"}) {
+		t.Errorf("unexpected unmatched rows %+v", exp.Unmatched)
+	}
+}
+
+func TestParseTVTime_OlderColumns(t *testing.T) {
+	export := "episode_id,tv_show_name,episode_season_number,episode_number,updated_at\n" +
+		"7654321,Severance,1,1,2026-03-01 20:00:00\n"
+
+	exp, err := ParseTVTime(strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exp.Plays) != 1 || exp.Plays[0].Title.Name != "Severance" || exp.Plays[0].EpisodeIDs.TVDB != 7654321 {
+		t.Errorf("unexpected plays %+v", exp.Plays)
+	}
+}
+
+func TestParseTVTime_WrongFile(t *testing.T) {
+	if _, err := ParseTVTime(strings.NewReader("user_id,show_name,followed_at\n1,Lost,2020-01-01\n")); err == nil {
+		t.Error("expected an error for a file without episodes")
+	}
+}
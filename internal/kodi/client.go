@@ -0,0 +1,163 @@
+// Package kodi is a small client for the JSON-RPC API of the Kodi media
+// center, enough to tell what its video player is playing and how far
+// along it is. Kodi serves the API over HTTP once "Allow remote control
+// via HTTP" is enabled in its settings.
+package kodi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds each JSON-RPC call.
+const DefaultTimeout = 5 * time.Second
+
+// Error is an error a JSON-RPC call returned.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("kodi: %s (%d)", e.Message, e.Code)
+}
+
+// Item is a video Kodi is playing. Items from the library are typed and
+// carry their scraper's IDs; other files have Type "unknown".
+type Item struct {
+	Type      string            `json:"type"` // "movie", "episode", "unknown", ...
+	Title     string            `json:"title"`
+	ShowTitle string            `json:"showtitle"`
+	Season    int               `json:"season"`
+	Episode   int               `json:"episode"`
+	Year      int               `json:"year"`
+	File      string            `json:"file"`
+	UniqueIDs map[string]string `json:"uniqueid"` // e.g. "imdb", "tmdb", "tvdb"
+}
+
+// Playing is what the video player is playing.
+type Playing struct {
+	Item       Item
+	Percentage float64 // of the item played, 0-100
+	Paused     bool
+}
+
+// Client calls a Kodi instance's JSON-RPC API.
+type Client struct {
+	url        string
+	username   string
+	password   string
+	httpClient *http.Client
+	nextID     atomic.Int64
+}
+
+// NewClient creates a client for the Kodi web server at baseURL, such as
+// "http://192.168.1.20:8080", signing in as username if it's set.
+func NewClient(baseURL, username, password string) *Client {
+	return &Client{
+		url:        strings.TrimRight(baseURL, "/") + "/jsonrpc",
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// Playing returns what the video player is playing, or nil if it isn't
+// playing anything.
+func (c *Client) Playing(ctx context.Context) (*Playing, error) {
+	var players []struct {
+		PlayerID int    `json:"playerid"`
+		Type     string `json:"type"`
+	}
+	if err := c.call(ctx, "Player.GetActivePlayers", nil, &players); err != nil {
+		return nil, err
+	}
+	playerID := -1
+	for _, p := range players {
+		if p.Type == "video" {
+			playerID = p.PlayerID
+		}
+	}
+	if playerID < 0 {
+		return nil, nil
+	}
+
+	var item struct {
+		Item Item `json:"item"`
+	}
+	err := c.call(ctx, "Player.GetItem", map[string]any{
+		"playerid":   playerID,
+		"properties": []string{"title", "showtitle", "season", "episode", "year", "file", "uniqueid"},
+	}, &item)
+	if err != nil {
+		return nil, err
+	}
+	var props struct {
+		Percentage float64 `json:"percentage"`
+		Speed      int     `json:"speed"`
+	}
+	err = c.call(ctx, "Player.GetProperties", map[string]any{
+		"playerid":   playerID,
+		"properties": []string{"percentage", "speed"},
+	}, &props)
+	if err != nil {
+		return nil, err
+	}
+	return &Playing{Item: item.Item, Percentage: props.Percentage, Paused: props.Speed == 0}, nil
+}
+
+// call calls method with params and decodes its result into result.
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      c.nextID.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("kodi: %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("kodi: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kodi: %s: %s", method, resp.Status)
+	}
+	var r struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return fmt.Errorf("kodi: decode response: %w", err)
+	}
+	if r.Error != nil {
+		return r.Error
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("kodi: decode %s result: %w", method, err)
+	}
+	return nil
+}
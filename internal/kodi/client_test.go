@@ -0,0 +1,91 @@
+package kodi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClient creates a client for a mock Kodi answering each JSON-RPC
+// method with the result in results.
+func newTestClient(t *testing.T, results map[string]string) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jsonrpc" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "kodi" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			ID     int64  `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		result, ok := results[req.Method]
+		if !ok {
+			_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "error": map[string]any{"code": -32601, "message": "Method not found."}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": json.RawMessage(result)})
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(server.URL+"/", "kodi", "secret")
+}
+
+func TestClient_Playing(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"Player.GetActivePlayers": `[{"playerid":0,"playertype":"internal","type":"audio"},{"playerid":1,"playertype":"internal","type":"video"}]`,
+		"Player.GetItem":          `{"item":{"id":42,"type":"episode","label":"Good News About Hell","title":"Good News About Hell","showtitle":"Severance","season":1,"episode":1,"year":2022,"file":"/tv/Severance/S01E01.mkv","uniqueid":{"tvdb":"8266930","imdb":"tt11650492"}}}`,
+		"Player.GetProperties":    `{"percentage":42.5,"speed":0}`,
+	})
+
+	p, err := client.Playing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p == nil {
+		t.Fatal("expected something playing")
+	}
+	if p.Item.Type != "episode" || p.Item.ShowTitle != "Severance" || p.Item.Season != 1 || p.Item.Episode != 1 {
+		t.Errorf("unexpected item %+v", p.Item)
+	}
+	if p.Item.UniqueIDs["tvdb"] != "8266930" {
+		t.Errorf("expected the TVDB ID, got %v", p.Item.UniqueIDs)
+	}
+	if p.Percentage != 42.5 || !p.Paused {
+		t.Errorf("expected paused at 42.5%%, got %+v", p)
+	}
+}
+
+func TestClient_PlayingNothing(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"Player.GetActivePlayers": `[{"playerid":0,"playertype":"internal","type":"audio"}]`,
+	})
+
+	p, err := client.Playing(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p != nil {
+		t.Errorf("expected nothing playing, got %+v", p)
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"Player.GetActivePlayers": `[{"playerid":1,"type":"video"}]`,
+	})
+
+	_, err := client.Playing(context.Background())
+	var kerr *Error
+	if !errors.As(err, &kerr) || kerr.Code != -32601 {
+		t.Fatalf("expected a JSON-RPC error, got %v", err)
+	}
+}
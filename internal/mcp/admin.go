@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// readinessChecks are the checks behind /readyz: the server can only do
+// useful work with API credentials and a signed-in user.
+func readinessChecks(client TraktAPI) []Check {
+	return []Check{credentialsCheck(client), authenticationCheck(client)}
+}
+
+// NewAdminHandler serves the admin endpoints for container orchestrators
+// and monitoring:
+//
+//   - /healthz reports that the process is up.
+//   - /readyz runs the readiness checks, returning 503 if any fail.
+//   - /metrics exposes Metrics in the Prometheus text format.
+func NewAdminHandler(s *Server, client TraktAPI) http.Handler {
+	checks := readinessChecks(client)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		var report strings.Builder
+		ready := true
+		for _, c := range checks {
+			if err := c.Run(r.Context()); err != nil {
+				ready = false
+				fmt.Fprintf(&report, "%s: %v\n", c.Name, err)
+			} else {
+				fmt.Fprintf(&report, "%s: ok\n", c.Name)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		fmt.Fprint(w, report.String())
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.metrics.write(w, client)
+	})
+	return mux
+}
+
+// RunAdmin serves the admin endpoints on addr until ctx is cancelled.
+func (s *Server) RunAdmin(ctx context.Context, addr string, client TraktAPI) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           NewAdminHandler(s, client),
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("admin endpoint listening", "addr", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("admin server: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
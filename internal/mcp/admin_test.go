@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func adminGet(t *testing.T, h http.Handler, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	body, _ := io.ReadAll(rec.Body)
+	return rec.Code, string(body)
+}
+
+func TestAdminHandler_Readiness(t *testing.T) {
+	server := NewServer(nil)
+
+	status, _ := adminGet(t, NewAdminHandler(server, trakt.NewClient(trakt.Config{}, nil)), "/healthz")
+	if status != http.StatusOK {
+		t.Errorf("/healthz returned %d", status)
+	}
+
+	notReady := NewAdminHandler(server, trakt.NewClient(trakt.Config{ClientID: "id"}, nil))
+	status, body := adminGet(t, notReady, "/readyz")
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a token, got %d", status)
+	}
+	if !strings.Contains(body, "credentials: ok") || !strings.Contains(body, "authentication: not authenticated") {
+		t.Errorf("unexpected readiness report:\n%s", body)
+	}
+
+	ready := NewAdminHandler(server, trakt.NewClient(trakt.Config{ClientID: "id", AccessToken: "token"}, nil))
+	if status, body := adminGet(t, ready, "/readyz"); status != http.StatusOK {
+		t.Errorf("expected 200 when ready, got %d:\n%s", status, body)
+	}
+}
+
+func TestAdminHandler_Metrics(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit", `{"name":"AUTHED_API_GET_LIMIT","period":300,"limit":1000,"remaining":998}`)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer api.Close()
+
+	server := NewServer(nil)
+	client := trakt.NewClient(trakt.Config{ClientID: "id", AccessToken: "token", APIURL: api.URL}, nil)
+	client.Use(server.Metrics().TraktMiddleware())
+	server.RegisterTool(Tool{Name: "history", InputSchema: JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			_, err := client.GetHistory(ctx, "", 1)
+			return ToolCallResult{}, err
+		})
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	callReq := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"history","arguments":{}}}`
+	if err := server.RunWithIO(context.Background(), strings.NewReader(initReq+"\n"+callReq+"\n"), io.Discard); err != nil {
+		t.Fatalf("RunWithIO failed: %v", err)
+	}
+
+	status, body := adminGet(t, NewAdminHandler(server, client), "/metrics")
+	if status != http.StatusOK {
+		t.Fatalf("/metrics returned %d", status)
+	}
+	for _, want := range []string{
+		`trakt_mcp_tool_calls_total{tool="history",outcome="ok"} 1`,
+		`trakt_api_requests_total{status="200"} 1`,
+		`trakt_api_ratelimit_remaining{limit="AUTHED_API_GET_LIMIT"} 998`,
+		"# TYPE trakt_mcp_build_info gauge",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// DefaultNewEpisodeInterval is how often WatchNewEpisodes checks the
+// calendar by default.
+const DefaultNewEpisodeInterval = 15 * time.Minute
+
+// maxAiringLookback caps how far back a check looks for new episodes, so
+// a server that couldn't reach Trakt for a while doesn't announce a
+// backlog of old airings when it can again.
+const maxAiringLookback = 24 * time.Hour
+
+// WatchNewEpisodes checks the user's calendar every interval until ctx is
+// cancelled and tells the connected client about each episode of their
+// shows that aired since the previous check, with a notifications/message
+// such as "Severance S02E07 just aired." Episodes that aired before the
+// watcher started, or while no client was connected, aren't announced.
+func (s *Server) WatchNewEpisodes(ctx context.Context, client TraktAPI, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	since := time.Now()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if !client.IsAuthenticated() {
+			continue
+		}
+
+		now := time.Now()
+		aired, err := newlyAired(ctx, client, since, now)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Warn("checking for new episodes failed", "error", err)
+			}
+			continue
+		}
+		since = now
+		for _, e := range aired {
+			s.notifyNewEpisode(ctx, e)
+		}
+	}
+}
+
+// newlyAired returns the episodes of the user's shows that aired from
+// since up to now, oldest first, leaving out shows hidden from the
+// calendar.
+func newlyAired(ctx context.Context, client TraktAPI, since, now time.Time) ([]trakt.CalendarEntry, error) {
+	if now.Sub(since) > maxAiringLookback {
+		since = now.Add(-maxAiringLookback)
+	}
+
+	// Calendar days are UTC, so the days touching the window are fetched
+	// and filtered by air time
+	start := since.UTC().Truncate(24 * time.Hour)
+	days := int(now.UTC().Sub(start).Hours()/24) + 1
+	entries, err := client.GetMyShowsCalendar(ctx, start, days)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := client.GetHidden(ctx, "calendar", "show")
+	if err != nil {
+		return nil, err
+	}
+	return filterCalendar(entries, hidden, since, now), nil
+}
+
+// notifyNewEpisode tells the client an episode just aired, in the
+// configured language.
+func (s *Server) notifyNewEpisode(ctx context.Context, e trakt.CalendarEntry) {
+	text := msg(withLanguage(ctx, s.Language()), msgNewEpisode, e.Show.Title, e.Episode.Season, e.Episode.Number)
+	err := s.Notify("notifications/message", LoggingMessageParams{
+		Level:  "info",
+		Logger: ServerName,
+		Data:   text,
+	})
+	if err != nil {
+		s.logger.Error("failed to send notification", "error", err)
+	}
+}
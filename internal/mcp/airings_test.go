@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestNewlyAired(t *testing.T) {
+	now := time.Now()
+	since := now.Add(-15 * time.Minute)
+	severance := &trakt.Show{Title: "Severance", IDs: trakt.ShowIDs{Trakt: 154997}}
+	hidden := &trakt.Show{Title: "Hidden Show", IDs: trakt.ShowIDs{Trakt: 1}}
+
+	client := &suggestTrakt{
+		fakeTrakt: fakeTrakt{authenticated: true},
+		calendar: []trakt.CalendarEntry{
+			{FirstAired: since.Add(-time.Minute), Show: severance, Episode: &trakt.Episode{Season: 2, Number: 6}},
+			{FirstAired: since.Add(5 * time.Minute), Show: severance, Episode: &trakt.Episode{Season: 2, Number: 7}},
+			{FirstAired: since.Add(5 * time.Minute), Show: hidden, Episode: &trakt.Episode{Season: 1, Number: 1}},
+			{FirstAired: now.Add(time.Hour), Show: severance, Episode: &trakt.Episode{Season: 2, Number: 8}},
+		},
+		hidden: []trakt.HiddenItem{{Type: "show", Show: hidden}},
+	}
+
+	aired, err := newlyAired(context.Background(), client, since, now)
+	if err != nil {
+		t.Fatalf("newlyAired failed: %v", err)
+	}
+	if len(aired) != 1 || aired[0].Episode.Number != 7 {
+		t.Errorf("expected only S02E07, got %+v", aired)
+	}
+}
+
+func TestNotifyNewEpisode(t *testing.T) {
+	var buf bytes.Buffer
+	server := NewServer(nil)
+	server.out = &buf
+
+	server.notifyNewEpisode(context.Background(), trakt.CalendarEntry{
+		Show:    &trakt.Show{Title: "Severance"},
+		Episode: &trakt.Episode{Season: 2, Number: 7},
+	})
+
+	var note struct {
+		Method string               `json:"method"`
+		Params LoggingMessageParams `json:"params"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &note); err != nil {
+		t.Fatalf("failed to decode notification: %v", err)
+	}
+	if note.Method != "notifications/message" || note.Params.Data != "📺 Severance S02E07 just aired." {
+		t.Errorf("unexpected notification: %s", buf.String())
+	}
+}
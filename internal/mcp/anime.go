@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// maxAliasCandidates bounds how many search results have their aliases
+// fetched when a search is ambiguous.
+const maxAliasCandidates = 5
+
+// matchAlias looks for the result a poorly scored search meant among the
+// candidates' aliases, so a romanized or translated title ("Shingeki no
+// Kyojin" for "Attack on Titan") resolves to the show it names. It returns
+// nil unless exactly one candidate has query as an alias. A query that is
+// a candidate's own title is left to disambiguation, since then the
+// ambiguity is between items sharing a title.
+func matchAlias(ctx context.Context, client TraktAPI, contentType, query string, results []trakt.SearchResult) *trakt.SearchResult {
+	want := normalizeTitle(query)
+	if want == "" {
+		return nil
+	}
+
+	type candidate struct {
+		result *trakt.SearchResult
+		kind   string
+		id     int
+	}
+	var candidates []candidate
+	for i := range results[:min(len(results), maxAliasCandidates)] {
+		r := &results[i]
+		switch {
+		case contentType == "show" && r.Show != nil:
+			if normalizeTitle(r.Show.Title) == want {
+				return nil
+			}
+			candidates = append(candidates, candidate{r, "shows", r.Show.IDs.Trakt})
+		case contentType == "movie" && r.Movie != nil:
+			if normalizeTitle(r.Movie.Title) == want {
+				return nil
+			}
+			candidates = append(candidates, candidate{r, "movies", r.Movie.IDs.Trakt})
+		}
+	}
+
+	var match *trakt.SearchResult
+	for _, c := range candidates {
+		// A failed lookup only means this candidate can't be confirmed
+		aliases, err := client.GetAliases(ctx, c.kind, fmt.Sprint(c.id))
+		if err != nil {
+			continue
+		}
+		for _, a := range aliases {
+			if normalizeTitle(a.Title) == want {
+				if match != nil {
+					return nil
+				}
+				match = c.result
+				break
+			}
+		}
+	}
+	return match
+}
+
+// normalizeTitle reduces a title to lowercase letters and digits separated
+// by single spaces, so punctuation, case and spacing don't affect matching.
+func normalizeTitle(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// findAbsoluteEpisode finds the episode with absolute number n among a
+// show's seasons. Trakt's own absolute numbers are used when it has them;
+// otherwise episodes are counted in order across the regular seasons, so
+// specials never shift the count.
+func findAbsoluteEpisode(seasons []trakt.Season, n int) (trakt.Episode, bool) {
+	regular := make([]trakt.Season, 0, len(seasons))
+	for _, s := range seasons {
+		if s.Number > 0 {
+			regular = append(regular, s)
+		}
+	}
+	sort.Slice(regular, func(i, j int) bool { return regular[i].Number < regular[j].Number })
+
+	numbered := false
+	for _, s := range regular {
+		for _, ep := range s.Episodes {
+			if ep.NumberAbs == n {
+				return withSeason(ep, s.Number), true
+			}
+			numbered = numbered || ep.NumberAbs > 0
+		}
+	}
+	if numbered {
+		return trakt.Episode{}, false
+	}
+
+	count := 0
+	for _, s := range regular {
+		if n <= count+len(s.Episodes) {
+			return withSeason(s.Episodes[n-count-1], s.Number), true
+		}
+		count += len(s.Episodes)
+	}
+	return trakt.Episode{}, false
+}
+
+// withSeason fills in an episode's season, which Trakt leaves out of the
+// episodes nested in a season.
+func withSeason(ep trakt.Episode, season int) trakt.Episode {
+	if ep.Season == 0 {
+		ep.Season = season
+	}
+	return ep
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestFindAbsoluteEpisode(t *testing.T) {
+	episodes := func(first, count int, numbered bool) []trakt.Episode {
+		eps := make([]trakt.Episode, count)
+		for i := range eps {
+			eps[i].Number = i + 1
+			if numbered {
+				eps[i].NumberAbs = first + i
+			}
+		}
+		return eps
+	}
+
+	// Specials come first and don't count toward absolute numbers
+	seasons := []trakt.Season{
+		{Number: 0, Episodes: episodes(0, 5, false)},
+		{Number: 1, Episodes: episodes(1, 12, false)},
+		{Number: 2, Episodes: episodes(13, 13, false)},
+	}
+	ep, ok := findAbsoluteEpisode(seasons, 14)
+	if !ok || ep.Season != 2 || ep.Number != 2 {
+		t.Errorf("counted episode 14 = S%dE%d, %v; want S2E2", ep.Season, ep.Number, ok)
+	}
+	if _, ok := findAbsoluteEpisode(seasons, 26); ok {
+		t.Error("expected no episode past the last one")
+	}
+
+	// Trakt's absolute numbers win over counting, e.g. when a season is
+	// missing episodes
+	seasons = []trakt.Season{
+		{Number: 1, Episodes: episodes(1, 10, true)},
+		{Number: 2, Episodes: episodes(20, 5, true)},
+	}
+	ep, ok = findAbsoluteEpisode(seasons, 21)
+	if !ok || ep.Season != 2 || ep.Number != 2 {
+		t.Errorf("numbered episode 21 = S%dE%d, %v; want S2E2", ep.Season, ep.Number, ok)
+	}
+	if _, ok := findAbsoluteEpisode(seasons, 11); ok {
+		t.Error("expected a number Trakt doesn't list to be missing")
+	}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := map[string]string{
+		"Shingeki no Kyojin":   "shingeki no kyojin",
+		"  Re:ZERO -Starting-": "re zero starting",
+		"Steins;Gate 0":        "steins gate 0",
+		"進撃の巨人":                "進撃の巨人",
+		"!!!":                  "",
+	}
+	for in, want := range tests {
+		if got := normalizeTitle(in); got != want {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMatchAlias(t *testing.T) {
+	results := []trakt.SearchResult{
+		{Type: "show", Score: 300, Show: &trakt.Show{Title: "Attack on Titan: Junior High", IDs: trakt.ShowIDs{Trakt: 2}}},
+		{Type: "show", Score: 250, Show: &trakt.Show{Title: "Attack on Titan", IDs: trakt.ShowIDs{Trakt: 1}}},
+	}
+	client := &fakeTrakt{aliases: map[string][]trakt.Alias{
+		"shows/1": {{Title: "Shingeki no Kyojin", Country: "jp"}, {Title: "L'Attaque des Titans", Country: "fr"}},
+		"shows/2": {{Title: "Shingeki! Kyojin Chuugakkou", Country: "jp"}},
+	}}
+
+	got := matchAlias(context.Background(), client, "show", "shingeki no kyojin", results)
+	if got == nil || got.Show.IDs.Trakt != 1 {
+		t.Fatalf("expected the alias to pick Attack on Titan, got %+v", got)
+	}
+
+	if got := matchAlias(context.Background(), client, "show", "Something Else", results); got != nil {
+		t.Errorf("expected no match, got %+v", got)
+	}
+
+	// Two candidates sharing the alias stay ambiguous
+	client.aliases["shows/2"] = append(client.aliases["shows/2"], trakt.Alias{Title: "Shingeki no Kyojin"})
+	if got := matchAlias(context.Background(), client, "show", "Shingeki no Kyojin", results); got != nil {
+		t.Errorf("expected a shared alias to stay ambiguous, got %+v", got)
+	}
+
+	// A query that is a candidate's own title is ambiguity between shows
+	// sharing a title, which aliases can't settle
+	if got := matchAlias(context.Background(), client, "show", "Attack on Titan", results); got != nil {
+		t.Errorf("expected an exact title to be left to disambiguation, got %+v", got)
+	}
+}
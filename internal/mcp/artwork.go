@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/kofifort/trakt-mcp-go/internal/fanart"
+	"github.com/kofifort/trakt-mcp-go/internal/tmdb"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// ArtworkSource is where search results' artwork comes from.
+type ArtworkSource string
+
+const (
+	ArtworkTrakt  ArtworkSource = "trakt"  // Trakt's own images
+	ArtworkTMDB   ArtworkSource = "tmdb"   // TMDB posters and backdrops
+	ArtworkFanart ArtworkSource = "fanart" // fanart.tv logos, backgrounds and posters
+)
+
+// ParseArtworkSource validates an artwork source name. An empty name means
+// ArtworkTrakt.
+func ParseArtworkSource(s string) (ArtworkSource, error) {
+	switch source := ArtworkSource(s); source {
+	case "":
+		return ArtworkTrakt, nil
+	case ArtworkTrakt, ArtworkTMDB, ArtworkFanart:
+		return source, nil
+	default:
+		return ArtworkTrakt, fmt.Errorf("unknown artwork source %q (want trakt, tmdb or fanart)", s)
+	}
+}
+
+// ArtworkProvider finds artwork for shows and movies somewhere other than
+// Trakt. It returns nil images, and no error, for an item it can't look
+// up, such as one without the ID it needs.
+type ArtworkProvider interface {
+	ShowArtwork(ctx context.Context, show *trakt.Show) (*trakt.Images, error)
+	MovieArtwork(ctx context.Context, movie *trakt.Movie) (*trakt.Images, error)
+}
+
+// artworkTrakt replaces the artwork of search results with a provider's.
+// Kinds of image the provider has none of keep Trakt's, and results it
+// fails to look up are left as Trakt sent them.
+type artworkTrakt struct {
+	TraktAPI
+	provider ArtworkProvider
+}
+
+// WithArtwork returns client with its search results' artwork taken from p.
+func WithArtwork(client TraktAPI, p ArtworkProvider) TraktAPI {
+	return &artworkTrakt{TraktAPI: client, provider: p}
+}
+
+func (c *artworkTrakt) Search(ctx context.Context, query string, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error) {
+	results, err := c.TraktAPI.Search(ctx, query, searchType, opts...)
+	if err != nil {
+		return nil, err
+	}
+	forEachTitle(results, func(show *trakt.Show, movie *trakt.Movie) {
+		if show != nil {
+			if images, err := c.provider.ShowArtwork(ctx, show); err == nil {
+				replaceImages(&show.Images, images)
+			}
+			return
+		}
+		if images, err := c.provider.MovieArtwork(ctx, movie); err == nil {
+			replaceImages(&movie.Images, images)
+		}
+	})
+	return results, nil
+}
+
+// replaceImages overwrites each kind of image in *images that from has.
+func replaceImages(images **trakt.Images, from *trakt.Images) {
+	if from == nil {
+		return
+	}
+	if *images == nil {
+		*images = &trakt.Images{}
+	}
+	img := *images
+	if len(from.Poster) > 0 {
+		img.Poster = from.Poster
+	}
+	if len(from.Fanart) > 0 {
+		img.Fanart = from.Fanart
+	}
+	if len(from.Logo) > 0 {
+		img.Logo = from.Logo
+	}
+}
+
+// maxArtworkLookups bounds the lookups one search makes at a time.
+const maxArtworkLookups = 4
+
+// forEachTitle calls fn for each show and movie among results, with the
+// other nil, a few at a time, and waits for them all.
+func forEachTitle(results []trakt.SearchResult, fn func(show *trakt.Show, movie *trakt.Movie)) {
+	sem := make(chan struct{}, maxArtworkLookups)
+	var wg sync.WaitGroup
+	for _, r := range results {
+		var show *trakt.Show
+		var movie *trakt.Movie
+		switch {
+		case r.Type == "show" && r.Show != nil:
+			show = r.Show
+		case r.Type == "movie" && r.Movie != nil:
+			movie = r.Movie
+		default:
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			fn(show, movie)
+		}()
+	}
+	wg.Wait()
+}
+
+type tmdbArtwork struct{ client *tmdb.Client }
+
+// TMDBArtwork returns a provider of TMDB posters and backdrops, looked up
+// by TMDB ID.
+func TMDBArtwork(c *tmdb.Client) ArtworkProvider {
+	return tmdbArtwork{client: c}
+}
+
+func (p tmdbArtwork) ShowArtwork(ctx context.Context, show *trakt.Show) (*trakt.Images, error) {
+	if show.IDs.TMDB == 0 {
+		return nil, nil
+	}
+	d, err := p.client.Show(ctx, show.IDs.TMDB)
+	if err != nil {
+		return nil, err
+	}
+	return tmdbImages(d), nil
+}
+
+func (p tmdbArtwork) MovieArtwork(ctx context.Context, movie *trakt.Movie) (*trakt.Images, error) {
+	if movie.IDs.TMDB == 0 {
+		return nil, nil
+	}
+	d, err := p.client.Movie(ctx, movie.IDs.TMDB)
+	if err != nil {
+		return nil, err
+	}
+	return tmdbImages(d), nil
+}
+
+func tmdbImages(d *tmdb.Details) *trakt.Images {
+	return &trakt.Images{Poster: nonEmpty(d.PosterURL()), Fanart: nonEmpty(d.BackdropURL())}
+}
+
+type fanartArtwork struct{ client *fanart.Client }
+
+// FanartArtwork returns a provider of fanart.tv logos, backgrounds and
+// posters, looked up by TVDB ID for shows and by TMDB or IMDb ID for
+// movies. Logos and posters are picked in the output language when
+// fanart.tv has them.
+func FanartArtwork(c *fanart.Client) ArtworkProvider {
+	return fanartArtwork{client: c}
+}
+
+func (p fanartArtwork) ShowArtwork(ctx context.Context, show *trakt.Show) (*trakt.Images, error) {
+	if show.IDs.TVDB == 0 {
+		return nil, nil
+	}
+	a, err := p.client.Show(ctx, show.IDs.TVDB)
+	if err != nil {
+		return nil, err
+	}
+	return fanartImages(ctx, a), nil
+}
+
+func (p fanartArtwork) MovieArtwork(ctx context.Context, movie *trakt.Movie) (*trakt.Images, error) {
+	var id string
+	switch {
+	case movie.IDs.TMDB != 0:
+		id = strconv.Itoa(movie.IDs.TMDB)
+	case movie.IDs.IMDB != "":
+		id = movie.IDs.IMDB
+	default:
+		return nil, nil
+	}
+	a, err := p.client.Movie(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return fanartImages(ctx, a), nil
+}
+
+func fanartImages(ctx context.Context, a *fanart.Artwork) *trakt.Images {
+	lang, _ := ctx.Value(languageKey{}).(string)
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	return &trakt.Images{
+		Poster: nonEmpty(a.Poster(lang)),
+		Fanart: nonEmpty(a.Background()),
+		Logo:   nonEmpty(a.Logo(lang)),
+	}
+}
+
+// nonEmpty returns url as a one-element list, or nil if it's empty.
+func nonEmpty(url string) []string {
+	if url == "" {
+		return nil
+	}
+	return []string{url}
+}
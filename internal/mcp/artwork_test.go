@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/fanart"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestParseArtworkSource(t *testing.T) {
+	for in, want := range map[string]ArtworkSource{"": ArtworkTrakt, "trakt": ArtworkTrakt, "tmdb": ArtworkTMDB, "fanart": ArtworkFanart} {
+		if got, err := ParseArtworkSource(in); err != nil || got != want {
+			t.Errorf("ParseArtworkSource(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseArtworkSource("imdb"); err == nil {
+		t.Error("expected an unknown source to be rejected")
+	}
+}
+
+func TestWithArtwork_Fanart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tv/371980":
+			_, _ = w.Write([]byte(`{"hdtvlogo":[{"url":"https://assets.fanart.tv/severance-en.png","lang":"en","likes":"1"},
+				{"url":"https://assets.fanart.tv/severance-de.png","lang":"de","likes":"1"}],
+				"showbackground":[{"url":"https://assets.fanart.tv/severance-bg.jpg","lang":"","likes":"1"}]}`))
+		case "/movies/tt1160419":
+			_, _ = w.Write([]byte(`{"movieposter":[{"url":"https://assets.fanart.tv/dune-poster.jpg","lang":"en","likes":"1"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := fanart.NewClient("test-key")
+	client.SetBaseURL(server.URL)
+
+	api := WithArtwork(&searchTrakt{results: []trakt.SearchResult{
+		{Type: "show", Show: &trakt.Show{Title: "Severance", IDs: trakt.ShowIDs{Trakt: 1, TVDB: 371980},
+			Images: &trakt.Images{Poster: []string{"walter-r2.trakt.tv/severance.jpg.webp"}}}},
+		// No TMDB ID, so looked up by IMDb ID
+		{Type: "movie", Movie: &trakt.Movie{Title: "Dune", IDs: trakt.MovieIDs{Trakt: 2, IMDB: "tt1160419"},
+			Images: &trakt.Images{Poster: []string{"walter-r2.trakt.tv/dune.jpg.webp"}}}},
+	}}, FanartArtwork(client))
+
+	ctx := withLanguage(context.Background(), "de")
+	result, err := makeSearchHandler(NewServer(nil), api)(ctx, json.RawMessage(`{"query":"x"}`))
+	if err != nil || result.IsError {
+		t.Fatalf("search failed: %v %+v", err, result)
+	}
+	text := result.Content[0].Text
+	for _, want := range []string{
+		// Trakt's poster is kept where fanart.tv has none
+		"Poster: https://walter-r2.trakt.tv/severance.jpg.webp\n   Logo: https://assets.fanart.tv/severance-de.png\n   Backdrop: https://assets.fanart.tv/severance-bg.jpg",
+		"Poster: https://assets.fanart.tv/dune-poster.jpg\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	var links []string
+	for _, c := range result.Content[1:] {
+		links = append(links, c.Name+" "+c.MimeType)
+	}
+	if got, want := strings.Join(links, ", "), "Severance poster image/webp, Severance logo image/png, Severance background image/jpeg, Dune poster image/jpeg"; got != want {
+		t.Errorf("links = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// pendingAuth holds the device code from the most recent authenticate call
+// until it is exchanged for a token, denied, or expires.
+type pendingAuth struct {
+	mu        sync.Mutex
+	code      *trakt.DeviceCode
+	expiresAt time.Time
+	interval  int // seconds between polls, raised on slow_down
+}
+
+func (p *pendingAuth) set(code *trakt.DeviceCode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.code = code
+	p.expiresAt = time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	p.interval = code.Interval
+}
+
+// get returns the pending code, or nil if there is none or it has expired.
+func (p *pendingAuth) get() *trakt.DeviceCode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.code != nil && time.Now().After(p.expiresAt) {
+		p.code = nil
+	}
+	return p.code
+}
+
+// clear forgets code if it is still the pending one, so a finished poll
+// doesn't discard a newer authenticate call.
+func (p *pendingAuth) clear(code *trakt.DeviceCode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.code == code {
+		p.code = nil
+	}
+}
+
+// slowDown lengthens the polling interval after Trakt asks us to back off.
+func (p *pendingAuth) slowDown() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.interval += 5
+	return p.interval
+}
+
+func makeCompleteAuthHandler(client TraktAPI, pending *pendingAuth) ToolHandler {
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		code := pending.get()
+		if code == nil {
+			if client.IsAuthenticated() {
+				return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgAlreadyAuthenticated))}}, nil
+			}
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNoAuthInProgress))},
+				IsError: true,
+			}, nil
+		}
+
+		tok, err := client.PollForToken(ctx, code.DeviceCode)
+		switch {
+		case err == nil:
+			pending.clear(code)
+			client.SetToken(tok)
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgAuthenticated))}}, nil
+		case errors.Is(err, trakt.ErrAuthorizationPending):
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgAuthPending, code.VerificationURL, code.UserCode))}}, nil
+		case errors.Is(err, trakt.ErrSlowDown):
+			interval := pending.slowDown()
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgAuthSlowDown, interval))}}, nil
+		case errors.Is(err, trakt.ErrExpiredToken), errors.Is(err, trakt.ErrInvalidDeviceCode), errors.Is(err, trakt.ErrDeviceCodeUsed):
+			pending.clear(code)
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgAuthCodeInvalid))},
+				IsError: true,
+			}, nil
+		case errors.Is(err, trakt.ErrAccessDenied):
+			pending.clear(code)
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgAuthDenied))},
+				IsError: true,
+			}, nil
+		default:
+			return ErrorContent(ctx, err), nil
+		}
+	}
+}
+
+// completeDeviceAuth waits for the user to approve a device code, stores the
+// resulting token on the client and tells the MCP client how it went.
+func completeDeviceAuth(ctx context.Context, s *Server, client TraktAPI, pending *pendingAuth, code *trakt.DeviceCode, token any) {
+	tok, err := client.WaitForDeviceToken(ctx, code)
+	pending.clear(code)
+	if err != nil {
+		if client.IsAuthenticated() {
+			// complete_authentication won the race and already used the code
+			return
+		}
+		s.logger.Warn("device authentication did not complete", "error", err)
+
+		text := msg(ctx, msgAuthFailedNotice, err)
+		switch {
+		case errors.Is(err, trakt.ErrExpiredToken):
+			text = msg(ctx, msgAuthExpiredNotice)
+		case errors.Is(err, trakt.ErrAccessDenied):
+			text = msg(ctx, msgAuthDeniedNotice)
+		}
+		s.notifyProgress(token, 1, 1, text)
+		return
+	}
+
+	client.SetToken(tok)
+	s.logger.Info("device authentication completed")
+	s.notifyProgress(token, 1, 1, msg(ctx, msgAuthenticated))
+}
+
+func makeRefreshAuthHandler(client TraktAPI) ToolHandler {
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsConfigured() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgCredentialsMissing))},
+				IsError: true,
+			}, nil
+		}
+
+		tok, err := client.RefreshToken(ctx)
+		if errors.Is(err, trakt.ErrNoRefreshToken) {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNoRefreshToken))},
+				IsError: true,
+			}, nil
+		}
+		var apiErr *trakt.APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == 400 || apiErr.IsAuthError()) {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgRefreshRejected))},
+				IsError: true,
+			}, nil
+		}
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+
+		text := msg(ctx, msgRefreshed)
+		if tok.CreatedAt > 0 && tok.ExpiresIn > 0 {
+			expires := time.Unix(tok.CreatedAt, 0).Add(time.Duration(tok.ExpiresIn) * time.Second)
+			text += msg(ctx, msgTokenExpires, expires.UTC().Format("2006-01-02"))
+		}
+		return ToolCallResult{Content: []Content{TextContent(text)}}, nil
+	}
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestCompleteAuthHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantError  bool
+		wantText   string
+		wantAuthed bool
+		wantClear  bool
+	}{
+		{"success", http.StatusOK, false, "Authenticated", true, true},
+		{"authorization pending", http.StatusBadRequest, false, "Waiting for authorization", false, false},
+		{"slow down", http.StatusTooManyRequests, false, "Wait at least 10 seconds", false, false},
+		{"expired token", http.StatusGone, true, "no longer valid", false, true},
+		{"access denied", http.StatusTeapot, true, "denied", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newMockTraktServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/oauth/device/token" {
+					t.Errorf("unexpected request to %s", r.URL.Path)
+				}
+				if tt.status != http.StatusOK {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"access123","refresh_token":"refresh456"}`))
+			}))
+			client.SetToken(&trakt.Token{}) // start signed out
+
+			pending := &pendingAuth{}
+			code := &trakt.DeviceCode{DeviceCode: "device123", UserCode: "ABCD1234", ExpiresIn: 600, Interval: 5}
+			pending.set(code)
+
+			result, err := makeCompleteAuthHandler(client, pending)(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantError {
+				t.Errorf("IsError = %v, want %v: %s", result.IsError, tt.wantError, result.Content[0].Text)
+			}
+			if !strings.Contains(result.Content[0].Text, tt.wantText) {
+				t.Errorf("expected %q in result, got: %s", tt.wantText, result.Content[0].Text)
+			}
+			if client.IsAuthenticated() != tt.wantAuthed {
+				t.Errorf("IsAuthenticated = %v, want %v", client.IsAuthenticated(), tt.wantAuthed)
+			}
+			if cleared := pending.get() == nil; cleared != tt.wantClear {
+				t.Errorf("pending code cleared = %v, want %v", cleared, tt.wantClear)
+			}
+		})
+	}
+}
+
+func TestCompleteAuthHandler_NothingPending(t *testing.T) {
+	client := trakt.NewClient(trakt.Config{ClientID: "id"}, nil)
+
+	result, err := makeCompleteAuthHandler(client, &pendingAuth{})(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected error result when no authentication is pending")
+	}
+}
+
+func TestPendingAuth_Expires(t *testing.T) {
+	pending := &pendingAuth{}
+	code := &trakt.DeviceCode{DeviceCode: "device123", ExpiresIn: 600}
+	pending.set(code)
+
+	pending.mu.Lock()
+	pending.expiresAt = time.Now().Add(-time.Second)
+	pending.mu.Unlock()
+
+	if pending.get() != nil {
+		t.Error("expected expired code to be dropped")
+	}
+}
+
+func TestRefreshAuthHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		refresh   string
+		wantError bool
+		wantText  string
+	}{
+		{"success", http.StatusOK, "refresh456", false, "expires on 2024-03-31"},
+		{"rejected", http.StatusUnauthorized, "refresh456", true, "rejected the refresh token"},
+		{"no refresh token", http.StatusOK, "", true, "No refresh token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newMockTraktServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != http.StatusOK {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"new","refresh_token":"newer","expires_in":7776000,"created_at":1704067200}`))
+			}))
+			client.SetToken(&trakt.Token{AccessToken: "old", RefreshToken: tt.refresh})
+
+			result, err := makeRefreshAuthHandler(client)(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantError {
+				t.Errorf("IsError = %v, want %v: %s", result.IsError, tt.wantError, result.Content[0].Text)
+			}
+			if !strings.Contains(result.Content[0].Text, tt.wantText) {
+				t.Errorf("expected %q in result, got: %s", tt.wantText, result.Content[0].Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// defaultBacklogItems is how many watchlist items the breakdown lists.
+const defaultBacklogItems = 10
+
+// backlogItem is what's left to watch of one watchlist item.
+type backlogItem struct {
+	item     trakt.WatchlistItem
+	episodes int // episodes left, for shows
+	minutes  int
+}
+
+// title names the item, e.g. "📺 **Severance** (2022)".
+func (b backlogItem) title() string {
+	switch {
+	case b.item.Show != nil && b.item.Episode != nil:
+		return fmt.Sprintf("📺 **%s** S%02dE%02d", b.item.Show.Title, b.item.Episode.Season, b.item.Episode.Number)
+	case b.item.Show != nil:
+		return fmt.Sprintf("📺 **%s**%s", b.item.Show.Title, yearSuffix(b.item.Show.Year))
+	default:
+		return fmt.Sprintf("🎬 **%s**%s", b.item.Movie.Title, yearSuffix(b.item.Movie.Year))
+	}
+}
+
+// backlog totals what's left to watch on the watchlist.
+type backlog struct {
+	items          []backlogItem // longest first
+	shows, movies  int
+	episodes       int // episodes left across shows and listed episodes
+	minutes        int
+	unknown        int // items without a known runtime
+	skippedSeasons int // listed seasons, which aren't counted
+}
+
+// estimateBacklog reads the watchlist with runtimes and, for each show,
+// how many aired episodes the user hasn't watched yet.
+func estimateBacklog(ctx context.Context, client TraktAPI) (backlog, error) {
+	items, err := client.GetWatchlist(ctx, "", trakt.WithExtended(trakt.ExtendedFull))
+	if err != nil {
+		return backlog{}, err
+	}
+
+	var b backlog
+	var shows []trakt.WatchlistItem
+	for _, item := range items {
+		switch {
+		case item.Type == "show" && item.Show != nil:
+			shows = append(shows, item)
+		case item.Type == "movie" && item.Movie != nil:
+			b.movies++
+			b.add(backlogItem{item: item, minutes: item.Movie.Runtime})
+		case item.Type == "episode" && item.Show != nil && item.Episode != nil:
+			runtime := item.Episode.Runtime
+			if runtime == 0 {
+				runtime = item.Show.Runtime
+			}
+			b.episodes++
+			b.add(backlogItem{item: item, episodes: 1, minutes: runtime})
+		case item.Type == "season":
+			b.skippedSeasons++
+		}
+	}
+
+	progress, errs := trakt.Batch(ctx, shows, trakt.DefaultBatchParallelism, func(ctx context.Context, item trakt.WatchlistItem) (*trakt.ShowProgress, error) {
+		return client.GetShowProgress(ctx, strconv.Itoa(item.Show.IDs.Trakt))
+	})
+	for i, item := range shows {
+		if err := errs[i]; err != nil {
+			// A show Trakt no longer has can't be estimated, but shouldn't
+			// sink the rest
+			if errors.Is(err, trakt.ErrNotFound) {
+				continue
+			}
+			return backlog{}, err
+		}
+		left := max(progress[i].Aired-progress[i].Completed, 0)
+		if left == 0 {
+			continue
+		}
+		b.shows++
+		b.episodes += left
+		b.add(backlogItem{item: item, episodes: left, minutes: left * item.Show.Runtime})
+	}
+
+	sort.SliceStable(b.items, func(i, j int) bool { return b.items[i].minutes > b.items[j].minutes })
+	return b, nil
+}
+
+func (b *backlog) add(item backlogItem) {
+	if item.minutes == 0 {
+		b.unknown++
+	}
+	b.minutes += item.minutes
+	b.items = append(b.items, item)
+}
+
+func makeGetBacklogEstimateHandler(client TraktAPI) ToolHandler {
+	type backlogArgs struct {
+		HoursPerWeek float64 `json:"hoursPerWeek"`
+		Limit        int     `json:"limit"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a backlogArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		if a.HoursPerWeek < 0 || a.HoursPerWeek > 7*24 {
+			return ToolCallResult{
+				Content: []Content{TextContent("Error: hoursPerWeek must be between 0 and 168")},
+				IsError: true,
+			}, nil
+		}
+		if a.Limit <= 0 {
+			a.Limit = defaultBacklogItems
+		}
+
+		b, err := estimateBacklog(ctx, client)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		if len(b.items) == 0 {
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgBacklogEmpty))}}, nil
+		}
+
+		return ToolCallResult{
+			Content: []Content{TextContent(formatBacklog(b, a.HoursPerWeek, a.Limit, time.Now()))},
+		}, nil
+	}
+}
+
+func formatBacklog(b backlog, hoursPerWeek float64, limit int, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("📚 **Watchlist backlog**\n\n")
+	sb.WriteString(fmt.Sprintf("Total: %s (%s episodes of %s shows, %s movies)\n",
+		formatMinutes(b.minutes), formatCount(b.episodes), formatCount(b.shows), formatCount(b.movies)))
+	if hoursPerWeek > 0 && b.minutes > 0 {
+		weeks := float64(b.minutes) / 60 / hoursPerWeek
+		finish := now.Add(time.Duration(weeks * 7 * 24 * float64(time.Hour)))
+		sb.WriteString(fmt.Sprintf("At %s hours a week you'd finish by %s (%s weeks)\n",
+			formatHours(hoursPerWeek), finish.Local().Format("Jan 2, 2006"), formatCount(int(math.Ceil(weeks)))))
+	}
+	if b.unknown > 0 {
+		sb.WriteString(fmt.Sprintf("(%s items have no runtime on Trakt and aren't counted)\n", formatCount(b.unknown)))
+	}
+	if b.skippedSeasons > 0 {
+		sb.WriteString(fmt.Sprintf("(%s listed seasons aren't counted; list the show instead)\n", formatCount(b.skippedSeasons)))
+	}
+
+	sb.WriteString("\n**Biggest items**\n")
+	for i, item := range b.items {
+		if i >= limit {
+			sb.WriteString(fmt.Sprintf("... and %d more\n", len(b.items)-limit))
+			break
+		}
+		detail := formatMinutes(item.minutes)
+		if item.minutes == 0 {
+			detail = "runtime unknown"
+		}
+		if item.item.Type == "show" {
+			detail += fmt.Sprintf(" (%d episodes left)", item.episodes)
+		}
+		sb.WriteString(fmt.Sprintf("%s - %s\n", item.title(), detail))
+	}
+	return sb.String()
+}
+
+// formatHours renders an hours figure without a needless fraction, e.g.
+// "5" or "2.5".
+func formatHours(h float64) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", h), ".0")
+}
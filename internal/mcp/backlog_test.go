@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestGetBacklogEstimateHandler(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sync/watchlist":
+			if r.URL.Query().Get("extended") != "full" {
+				t.Errorf("expected runtimes to be requested, got %q", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode([]trakt.WatchlistItem{
+				{Type: "show", Show: &trakt.Show{Title: "Severance", Year: 2022, Runtime: 50, IDs: trakt.ShowIDs{Trakt: 1}}},
+				{Type: "show", Show: &trakt.Show{Title: "Dark", Year: 2017, Runtime: 55, IDs: trakt.ShowIDs{Trakt: 2}}},
+				{Type: "movie", Movie: &trakt.Movie{Title: "Dune", Year: 2021, Runtime: 155}},
+				{Type: "season", Show: &trakt.Show{Title: "The Bear"}},
+			})
+		case "/shows/1/progress/watched":
+			_ = json.NewEncoder(w).Encode(trakt.ShowProgress{Aired: 10, Completed: 4})
+		case "/shows/2/progress/watched":
+			// Finished, so nothing left
+			_ = json.NewEncoder(w).Encode(trakt.ShowProgress{Aired: 26, Completed: 26})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	backlogHandler := makeGetBacklogEstimateHandler(client)
+
+	result, err := backlogHandler(context.Background(), json.RawMessage(`{"hoursPerWeek":2.5}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	text := result.Content[0].Text
+
+	for _, want := range []string{
+		"Total: 7h 35m (6 episodes of 1 shows, 1 movies)",
+		"At 2.5 hours a week you'd finish by",
+		"(4 weeks)",
+		"1 listed seasons aren't counted",
+		"📺 **Severance** (2022) - 5h (6 episodes left)",
+		"🎬 **Dune** (2021) - 2h 35m",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Dark") {
+		t.Errorf("expected a finished show to be left out, got:\n%s", text)
+	}
+}
+
+func TestGetBacklogEstimateHandler_InvalidPace(t *testing.T) {
+	backlogHandler := makeGetBacklogEstimateHandler(&fakeTrakt{authenticated: true})
+	result, err := backlogHandler(context.Background(), json.RawMessage(`{"hoursPerWeek":-1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected a negative pace to be rejected, got %+v", result)
+	}
+}
@@ -0,0 +1,223 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/backup"
+)
+
+// diffListLimit is how many changes of each kind a diff lists before
+// summing up the rest.
+const diffListLimit = 20
+
+// RegisterBackupTools registers the backup tool, which snapshots the
+// account to an archive in dir, and unless the server is read-only the
+// restore tool, which puts back what the account has lost since one.
+func RegisterBackupTools(s *Server, client TraktAPI, dir string) {
+	s.RegisterTool(Tool{
+		Name:        "backup",
+		Description: "Snapshot the user's whole Trakt account (watch history, ratings, watchlist, collection and personal lists) to a new archive on this machine. Take one before bulk changes; the restore tool can compare the account with a snapshot or put back what was lost.",
+		Annotations: &ToolAnnotations{Title: "Back up account"},
+		InputSchema: JSONSchema{
+			Type:       "object",
+			Properties: map[string]JSONSchema{},
+		},
+	}, makeBackupHandler(client, dir))
+
+	if s.ReadOnly() {
+		return
+	}
+
+	s.RegisterTool(Tool{
+		Name:        "restore",
+		Description: "Restore the user's Trakt account from a backup: put back plays, ratings, watchlist and collection entries and list items that were removed since, set changed ratings back, and make deleted lists again. Nothing added since the backup is removed. With dryRun, only report the differences. The account is backed up first, so a restore can itself be undone.",
+		Annotations: &ToolAnnotations{Title: "Restore account", IdempotentHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"archive": {
+					Type:        "string",
+					Description: "File name of a backup in the backup directory (default: the newest backup)",
+				},
+				"dryRun": {
+					Type:        "boolean",
+					Description: "Only compare the backup with the account, changing nothing (default: false)",
+				},
+				"confirm": {
+					Type:        "string",
+					Description: "Confirmation token from an earlier call's preview, when the server asks to confirm restores. Only confirms the backup that was previewed",
+				},
+			},
+		},
+	}, makeRestoreHandler(s, client, dir))
+}
+
+func makeBackupHandler(client TraktAPI, dir string) ToolHandler {
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		snap, err := backup.Take(ctx, client)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		path, err := backup.Save(dir, snap)
+		if err != nil {
+			return ErrorContent(ctx, fmt.Errorf("save backup: %w", err)), nil
+		}
+
+		return ToolCallResult{
+			Content: []Content{TextContent(fmt.Sprintf("💾 Backed up to %s\n\n%s\n", path, describeSnapshot(snap)))},
+		}, nil
+	}
+}
+
+// describeSnapshot sums up what a snapshot holds.
+func describeSnapshot(snap *backup.Snapshot) string {
+	items := 0
+	for _, l := range snap.Lists {
+		items += len(l.Items)
+	}
+	return fmt.Sprintf("Plays: %s · Ratings: %s · Watchlist: %s · Collection: %s · Lists: %d (%s items)",
+		formatCount(len(snap.History)), formatCount(len(snap.Ratings)), formatCount(len(snap.Watchlist)),
+		formatCount(len(snap.Collection)), len(snap.Lists), formatCount(items))
+}
+
+func makeRestoreHandler(s *Server, client TraktAPI, dir string) ToolHandler {
+	type restoreArgs struct {
+		Archive string `json:"archive"`
+		DryRun  bool   `json:"dryRun"`
+		Confirm string `json:"confirm"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a restoreArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+
+		path, err := backup.Find(dir, a.Archive)
+		if err != nil {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNoBackup, err))},
+				IsError: true,
+			}, nil
+		}
+		snap, err := backup.Load(path)
+		if err != nil {
+			return ToolCallResult{
+				Content: []Content{TextContent("Error: " + err.Error())},
+				IsError: true,
+			}, nil
+		}
+		current, err := backup.Take(ctx, client)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+
+		name := filepath.Base(path)
+		diff := backup.Compare(snap, current)
+		if a.DryRun {
+			return ToolCallResult{Content: []Content{TextContent(formatRestorePreview(diff, name, snap))}}, nil
+		}
+		if len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+			return ToolCallResult{Content: []Content{TextContent(fmt.Sprintf("Nothing to restore: the account has everything in %s.", name))}}, nil
+		}
+
+		if s.ConfirmDestructive() {
+			action := "restore:" + path
+			if a.Confirm == "" {
+				token, err := s.confirmations.issue(action)
+				if err != nil {
+					return ErrorContent(ctx, fmt.Errorf("create confirmation token: %w", err)), nil
+				}
+				return ToolCallResult{
+					Content: []Content{TextContent(formatRestorePreview(diff, name, snap) + "\n" +
+						msg(ctx, msgConfirmRestore, token, int(confirmationTTL.Minutes())))},
+				}, nil
+			}
+			if !s.confirmations.redeem(a.Confirm, action) {
+				return ToolCallResult{
+					Content: []Content{TextContent(msg(ctx, msgConfirmRestoreInvalid))},
+					IsError: true,
+				}, nil
+			}
+		}
+
+		// Keep the state being restored over, so the restore can be undone
+		saved, err := backup.Save(dir, current)
+		if err != nil {
+			return ErrorContent(ctx, fmt.Errorf("back up before restoring: %w", err)), nil
+		}
+
+		restored, err := backup.Restore(ctx, client, snap, current)
+		text := formatRestored(restored, name, saved)
+		if err != nil {
+			return ToolCallResult{
+				Content: []Content{TextContent(text), TextContent("Error: " + err.Error() + ". Run restore again to finish; what's already back won't be added twice.")},
+				IsError: true,
+			}, nil
+		}
+		return ToolCallResult{Content: []Content{TextContent(text)}}, nil
+	}
+}
+
+// formatRestorePreview reports what restoring name would do.
+func formatRestorePreview(d backup.Diff, name string, snap *backup.Snapshot) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔍 **%s** (taken %s) compared with the account now\n", name, snap.TakenAt.Local().Format("2006-01-02 15:04")))
+	if d.Empty() {
+		sb.WriteString("\nNo differences.\n")
+		return sb.String()
+	}
+	writeChanges(&sb, "Restoring would put back", d.Removed)
+	writeChanges(&sb, "Restoring would set back these ratings", d.Changed)
+	writeChanges(&sb, "Added since, which restoring keeps", d.Added)
+	return sb.String()
+}
+
+// writeChanges lists changes under a heading, up to diffListLimit of them.
+func writeChanges(sb *strings.Builder, heading string, changes []backup.Change) {
+	if len(changes) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n**%s** (%s)\n", heading, formatCount(len(changes))))
+	for _, c := range changes[:min(len(changes), diffListLimit)] {
+		line := fmt.Sprintf("- %s: %s", c.Section, c.Title)
+		if c.Detail != "" {
+			line += ", " + c.Detail
+		}
+		sb.WriteString(line + "\n")
+	}
+	if n := len(changes) - diffListLimit; n > 0 {
+		sb.WriteString(fmt.Sprintf("- …and %s more\n", formatCount(n)))
+	}
+}
+
+// formatRestored reports what a restore put back.
+func formatRestored(r backup.Restored, name, saved string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("♻️ Restored %s items from %s\n\n", formatCount(r.Total()), name))
+	sb.WriteString(fmt.Sprintf("Plays: %s · Ratings: %s · Watchlist: %s · Collection: %s · List items: %s",
+		formatCount(r.Plays), formatCount(r.Ratings), formatCount(r.Watchlist), formatCount(r.Collection), formatCount(r.ListItems)))
+	if r.Lists > 0 {
+		sb.WriteString(fmt.Sprintf(" (%d lists made again)", r.Lists))
+	}
+	sb.WriteString(fmt.Sprintf("\n\nThe account as it was before is saved in %s.\n", saved))
+	return sb.String()
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/backup"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// backupTrakt is an account whose history can be changed between calls,
+// recording restored plays.
+type backupTrakt struct {
+	fakeTrakt
+	history  []trakt.HistoryItem
+	ratings  []trakt.Rating
+	restored []trakt.WatchedItem
+}
+
+func (f *backupTrakt) ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error {
+	for _, h := range f.history {
+		if err := fn(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *backupTrakt) GetUserRatings(ctx context.Context, user, ratingType string) ([]trakt.Rating, error) {
+	return f.ratings, nil
+}
+
+func (f *backupTrakt) GetWatchlist(ctx context.Context, watchlistType string, opts ...trakt.RequestOption) ([]trakt.WatchlistItem, error) {
+	return nil, nil
+}
+
+func (f *backupTrakt) ForEachCollected(ctx context.Context, collectionType string, fn func(trakt.CollectionEntry) error) error {
+	return nil
+}
+
+func (f *backupTrakt) GetLists(ctx context.Context) ([]trakt.List, error) { return nil, nil }
+
+func (f *backupTrakt) GetListItems(ctx context.Context, listID string) ([]trakt.ListItem, error) {
+	return nil, nil
+}
+
+func (f *backupTrakt) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	f.restored = append(f.restored, item)
+	return &trakt.SyncResponse{}, nil
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	severance := &trakt.Show{Title: "Severance", IDs: trakt.ShowIDs{Trakt: 1}}
+	watchedAt := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	client := &backupTrakt{
+		fakeTrakt: fakeTrakt{authenticated: true},
+		history: []trakt.HistoryItem{
+			{ID: 1, Type: "episode", WatchedAt: watchedAt, Show: severance, Episode: &trakt.Episode{Season: 1, Number: 1, IDs: trakt.EpisodeIDs{Trakt: 101}}},
+		},
+	}
+
+	result, err := makeBackupHandler(client, dir)(context.Background(), json.RawMessage(`{}`))
+	if err != nil || result.IsError {
+		t.Fatalf("backup failed: %v %+v", err, result)
+	}
+	if !strings.Contains(result.Content[0].Text, "Plays: 1 · Ratings: 0") {
+		t.Errorf("unexpected backup result: %s", result.Content[0].Text)
+	}
+
+	// A bad bulk operation wipes the history
+	client.history = nil
+	restore := makeRestoreHandler(NewServer(nil), client, dir)
+
+	result, err = restore(context.Background(), json.RawMessage(`{"dryRun":true}`))
+	if err != nil || result.IsError {
+		t.Fatalf("dry run failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "**Restoring would put back** (1)\n- history: Severance S01E01") {
+		t.Errorf("unexpected dry run:\n%s", text)
+	}
+	if len(client.restored) != 0 {
+		t.Fatal("expected a dry run to change nothing")
+	}
+
+	// Keep the archive names apart, since they have one-second precision
+	time.Sleep(time.Second)
+	result, err = restore(context.Background(), json.RawMessage(`{}`))
+	if err != nil || result.IsError {
+		t.Fatalf("restore failed: %v %+v", err, result)
+	}
+	if !strings.Contains(result.Content[0].Text, "Restored 1 items") {
+		t.Errorf("unexpected restore result: %s", result.Content[0].Text)
+	}
+	if len(client.restored) != 1 || client.restored[0].WatchedAt != "2026-03-01T20:00:00Z" {
+		t.Errorf("expected the play restored at its time, got %+v", client.restored)
+	}
+	if archives, _ := backup.Archives(dir); len(archives) != 2 {
+		t.Errorf("expected the account backed up before restoring, got %d archives", len(archives))
+	}
+}
+
+func TestRestore_Confirmation(t *testing.T) {
+	dir := t.TempDir()
+	client := &backupTrakt{
+		fakeTrakt: fakeTrakt{authenticated: true},
+		history: []trakt.HistoryItem{
+			{ID: 1, Type: "movie", WatchedAt: time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC), Movie: &trakt.Movie{Title: "Dune", IDs: trakt.MovieIDs{Trakt: 2}}},
+		},
+	}
+	if _, err := makeBackupHandler(client, dir)(context.Background(), json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	client.history = nil
+
+	server := NewServer(nil)
+	server.SetConfirmDestructive(true)
+	restore := makeRestoreHandler(server, client, dir)
+
+	result, err := restore(context.Background(), json.RawMessage(`{}`))
+	if err != nil || result.IsError {
+		t.Fatalf("preview failed: %v %+v", err, result)
+	}
+	text := result.Content[0].Text
+	match := regexp.MustCompile(`confirm set to "([0-9a-f]+)"`).FindStringSubmatch(text)
+	if match == nil || !strings.Contains(text, "history: Dune") {
+		t.Fatalf("expected a preview with a token, got:\n%s", text)
+	}
+	if len(client.restored) != 0 {
+		t.Fatal("expected the preview to change nothing")
+	}
+
+	result, _ = restore(context.Background(), json.RawMessage(`{"confirm":"0000"}`))
+	if !result.IsError || len(client.restored) != 0 {
+		t.Fatalf("expected a wrong token rejected, got %+v", result)
+	}
+
+	result, _ = restore(context.Background(), json.RawMessage(`{"confirm":"`+match[1]+`"}`))
+	if result.IsError || len(client.restored) != 1 {
+		t.Errorf("expected the confirmed restore to run, got %+v (%d restored)", result, len(client.restored))
+	}
+}
+
+func TestRegisterBackupTools_ReadOnly(t *testing.T) {
+	server := NewServer(nil)
+	server.SetReadOnly(true)
+	RegisterBackupTools(server, &fakeTrakt{}, t.TempDir())
+
+	if _, ok := server.tools["backup"]; !ok {
+		t.Error("expected backup in read-only mode")
+	}
+	if _, ok := server.tools["restore"]; ok {
+		t.Error("restore should not be registered in read-only mode")
+	}
+}
@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// browserAuthTimeout bounds how long the local redirect listener waits for
+// the user to approve the request in their browser.
+const browserAuthTimeout = 5 * time.Minute
+
+// authCallback is the outcome delivered to the redirect URI.
+type authCallback struct {
+	code string
+	err  error
+}
+
+// startBrowserAuth runs the authorization-code flow with PKCE: it listens on
+// the client's redirect URI, returns the authorize URL for the user to open,
+// and exchanges the code in the background once the browser is redirected.
+func startBrowserAuth(ctx context.Context, s *Server, client TraktAPI, token any) (string, error) {
+	redirect, err := url.Parse(client.RedirectURI())
+	if err != nil || redirect.Host == "" {
+		return "", fmt.Errorf("invalid redirect URI %q", client.RedirectURI())
+	}
+
+	ln, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return "", fmt.Errorf("start redirect listener: %w", err)
+	}
+	// Port 0 picks a free port, which only helps if Trakt accepts it, but
+	// keeps the redirect URI truthful either way
+	if redirect.Port() == "0" {
+		redirect.Host = net.JoinHostPort(redirect.Hostname(), strconv.Itoa(ln.Addr().(*net.TCPAddr).Port))
+	}
+	redirectURI := redirect.String()
+
+	verifier, challenge, err := trakt.NewPKCE()
+	if err != nil {
+		ln.Close()
+		return "", err
+	}
+	state, err := newSessionID()
+	if err != nil {
+		ln.Close()
+		return "", err
+	}
+
+	callbacks := make(chan authCallback, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			return
+		}
+
+		cb := authCallback{code: q.Get("code")}
+		switch {
+		case q.Get("error") == "access_denied":
+			cb.err = trakt.ErrAccessDenied
+		case q.Get("error") != "":
+			cb.err = fmt.Errorf("authorization failed: %s", q.Get("error"))
+		case cb.code == "":
+			cb.err = errors.New("authorization failed: no code in redirect")
+		}
+
+		select {
+		case callbacks <- cb:
+		default: // a result has already been delivered
+		}
+		if cb.err != nil {
+			fmt.Fprintln(w, "Trakt authorization failed. You can close this tab.")
+			return
+		}
+		fmt.Fprintln(w, "Trakt authorization complete. You can close this tab.")
+	})
+
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(ln)
+
+	go func() {
+		defer srv.Close()
+
+		var cb authCallback
+		select {
+		case cb = <-callbacks:
+		case <-time.After(browserAuthTimeout):
+			s.notifyProgress(token, 1, 1, msg(ctx, msgAuthTimedOutNotice))
+			return
+		}
+
+		if cb.err == nil {
+			var tok *trakt.Token
+			tok, cb.err = client.ExchangeCode(ctx, cb.code, redirectURI, verifier)
+			if cb.err == nil {
+				client.SetToken(tok)
+				s.logger.Info("browser authentication completed")
+				s.notifyProgress(token, 1, 1, msg(ctx, msgAuthenticated))
+				return
+			}
+		}
+
+		s.logger.Warn("browser authentication did not complete", "error", cb.err)
+		msg := "Trakt authentication failed: " + cb.err.Error()
+		if errors.Is(cb.err, trakt.ErrAccessDenied) {
+			msg = "Trakt authentication was denied."
+		}
+		s.notifyProgress(token, 1, 1, msg)
+	}()
+
+	authURL := client.AuthorizationURL(redirectURI, state, challenge)
+	s.logger.Info("waiting for browser authorization", "url", authURL)
+	return authURL, nil
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestStartBrowserAuth(t *testing.T) {
+	var mu sync.Mutex
+	var verifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["grant_type"] != "authorization_code" || body["code"] != "abc123" {
+			t.Errorf("unexpected token request: %v", body)
+		}
+		mu.Lock()
+		verifier = body["code_verifier"]
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"browser-token","refresh_token":"refresh"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	client := trakt.NewClient(trakt.Config{ClientID: "id", RedirectURI: "http://127.0.0.1:0/callback"}, nil)
+	client.SetBaseURL(ts.URL)
+
+	authURL, err := startBrowserAuth(context.Background(), NewServer(nil), client, nil)
+	if err != nil {
+		t.Fatalf("startBrowserAuth failed: %v", err)
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid authorize URL: %v", err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("state") == "" {
+		t.Fatalf("authorize URL missing PKCE parameters: %s", authURL)
+	}
+	redirect := q.Get("redirect_uri")
+
+	// A mismatched state must be rejected
+	resp, err := http.Get(redirect + "?code=abc123&state=wrong")
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for state mismatch, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(redirect + "?code=abc123&state=" + url.QueryEscape(q.Get("state")))
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from callback, got %d", resp.StatusCode)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !client.IsAuthenticated() {
+		if time.Now().After(deadline) {
+			t.Fatal("client was not authenticated after the callback")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	sum := sha256.Sum256([]byte(verifier))
+	if got := base64.RawURLEncoding.EncodeToString(sum[:]); got != q.Get("code_challenge") {
+		t.Errorf("code verifier does not match the challenge")
+	}
+}
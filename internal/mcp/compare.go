@@ -0,0 +1,281 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Most items compare_with_user lists in each section.
+const (
+	defaultCompareLimit = 10
+	maxCompareLimit     = 50
+)
+
+// disagreementGap is how many points apart two ratings must be to count as
+// a disagreement.
+const disagreementGap = 3
+
+// libraryItem is a show or movie a user has watched or rated.
+type libraryItem struct {
+	show   *trakt.Show
+	movie  *trakt.Movie
+	plays  int
+	rating int // 0 if unrated
+}
+
+func (i *libraryItem) key() string {
+	if i.show != nil {
+		return fmt.Sprintf("show:%d", i.show.IDs.Trakt)
+	}
+	return fmt.Sprintf("movie:%d", i.movie.IDs.Trakt)
+}
+
+// line renders the item's title and ID line around detail.
+func (i *libraryItem) line(detail string) string {
+	if i.show != nil {
+		return fmt.Sprintf("📺 **%s**%s - %s\n%s", i.show.Title, yearSuffix(i.show.Year), detail,
+			idLine(idPart("Show ID", int64(i.show.IDs.Trakt)), slugPart(i.show.IDs.Slug), i.show.URL()))
+	}
+	return fmt.Sprintf("🎬 **%s**%s - %s\n%s", i.movie.Title, yearSuffix(i.movie.Year), detail,
+		idLine(idPart("Movie ID", int64(i.movie.IDs.Trakt)), slugPart(i.movie.IDs.Slug), i.movie.URL()))
+}
+
+// library is everything a user has watched or rated, by item key.
+type library map[string]*libraryItem
+
+func (l library) add(item libraryItem) {
+	existing, ok := l[item.key()]
+	if !ok {
+		l[item.key()] = &item
+		return
+	}
+	existing.plays = max(existing.plays, item.plays)
+	existing.rating = max(existing.rating, item.rating)
+}
+
+// count returns how many shows and movies the library holds.
+func (l library) count() (shows, movies int) {
+	for _, item := range l {
+		if item.show != nil {
+			shows++
+		} else {
+			movies++
+		}
+	}
+	return shows, movies
+}
+
+// libraryPart is one of the lists a library is read from.
+type libraryPart struct {
+	user string
+	list string // "shows", "movies" or "ratings"
+}
+
+// fetchLibraryPart reads one list of a user's library.
+func fetchLibraryPart(ctx context.Context, client TraktAPI, part libraryPart) ([]libraryItem, error) {
+	if part.list == "ratings" {
+		ratings, err := client.GetUserRatings(ctx, part.user, "")
+		if err != nil {
+			return nil, err
+		}
+		var items []libraryItem
+		for _, r := range ratings {
+			switch {
+			case r.Type == "show" && r.Show != nil:
+				items = append(items, libraryItem{show: r.Show, rating: r.Rating})
+			case r.Type == "movie" && r.Movie != nil:
+				items = append(items, libraryItem{movie: r.Movie, rating: r.Rating})
+			}
+		}
+		return items, nil
+	}
+
+	var items []libraryItem
+	err := client.ForEachUserWatched(ctx, part.user, part.list, func(e trakt.WatchedEntry) error {
+		switch {
+		case e.Show != nil:
+			items = append(items, libraryItem{show: e.Show, plays: e.Plays})
+		case e.Movie != nil:
+			items = append(items, libraryItem{movie: e.Movie, plays: e.Plays})
+		}
+		return nil
+	})
+	return items, err
+}
+
+func makeCompareWithUserHandler(client TraktAPI) ToolHandler {
+	type compareArgs struct {
+		Username string `json:"username"`
+		Limit    int    `json:"limit"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a compareArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		a.Username = strings.TrimPrefix(strings.TrimSpace(a.Username), "@")
+		if a.Username == "" {
+			return ToolCallResult{
+				Content: []Content{TextContent("Error: username is required")},
+				IsError: true,
+			}, nil
+		}
+		if a.Limit <= 0 {
+			a.Limit = defaultCompareLimit
+		}
+		a.Limit = min(a.Limit, maxCompareLimit)
+
+		// Both users' lists are read at once; each is a single response
+		var parts []libraryPart
+		for _, user := range []string{"me", a.Username} {
+			for _, list := range []string{"shows", "movies", "ratings"} {
+				parts = append(parts, libraryPart{user: user, list: list})
+			}
+		}
+		results, errs := trakt.Batch(ctx, parts, trakt.DefaultBatchParallelism, func(ctx context.Context, part libraryPart) ([]libraryItem, error) {
+			return fetchLibraryPart(ctx, client, part)
+		})
+
+		mine, theirs := make(library), make(library)
+		for i, part := range parts {
+			if err := errs[i]; err != nil {
+				if part.user == "me" {
+					return ErrorContent(ctx, err), nil
+				}
+				switch {
+				case errors.Is(err, trakt.ErrNotFound):
+					return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNoSuchUser, a.Username))}, IsError: true}, nil
+				case errors.Is(err, trakt.ErrForbidden), errors.Is(err, trakt.ErrUnauthorized):
+					return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgPrivateProfile, a.Username))}, IsError: true}, nil
+				}
+				return ErrorContent(ctx, err), nil
+			}
+			lib := mine
+			if part.user != "me" {
+				lib = theirs
+			}
+			for _, item := range results[i] {
+				lib.add(item)
+			}
+		}
+
+		return ToolCallResult{
+			Content: []Content{TextContent(formatComparison(mine, theirs, a.Username, a.Limit))},
+		}, nil
+	}
+}
+
+// formatComparison reports what two users have in common, what the friend
+// has seen that the user hasn't, and where their ratings differ most.
+func formatComparison(mine, theirs library, friend string, limit int) string {
+	var onlyTheirs []*libraryItem
+	var disagreements [][2]*libraryItem // mine, theirs
+	commonShows, commonMovies, bothRated, ratingDiff := 0, 0, 0, 0
+	for key, t := range theirs {
+		m, ok := mine[key]
+		if !ok {
+			onlyTheirs = append(onlyTheirs, t)
+			continue
+		}
+		if t.show != nil {
+			commonShows++
+		} else {
+			commonMovies++
+		}
+		if m.rating > 0 && t.rating > 0 {
+			bothRated++
+			diff := abs(m.rating - t.rating)
+			ratingDiff += diff
+			if diff >= disagreementGap {
+				disagreements = append(disagreements, [2]*libraryItem{m, t})
+			}
+		}
+	}
+
+	// Sort by what the friend liked most, then watched most
+	sort.Slice(onlyTheirs, func(i, j int) bool {
+		a, b := onlyTheirs[i], onlyTheirs[j]
+		if a.rating != b.rating {
+			return a.rating > b.rating
+		}
+		if a.plays != b.plays {
+			return a.plays > b.plays
+		}
+		return a.key() < b.key()
+	})
+	sort.Slice(disagreements, func(i, j int) bool {
+		a, b := disagreements[i], disagreements[j]
+		if da, db := abs(a[0].rating-a[1].rating), abs(b[0].rating-b[1].rating); da != db {
+			return da > db
+		}
+		return a[0].key() < b[0].key()
+	})
+
+	var sb strings.Builder
+	myShows, myMovies := mine.count()
+	theirShows, theirMovies := theirs.count()
+	sb.WriteString(fmt.Sprintf("👥 **You and %s**\n\n", friend))
+	sb.WriteString(fmt.Sprintf("In common: %s shows and %s movies (you've seen %s shows and %s movies, %s has seen %s and %s)\n",
+		formatCount(commonShows), formatCount(commonMovies), formatCount(myShows), formatCount(myMovies), friend, formatCount(theirShows), formatCount(theirMovies)))
+	if bothRated > 0 {
+		sb.WriteString(fmt.Sprintf("Rated by both: %s, on average %.1f points apart\n", formatCount(bothRated), float64(ratingDiff)/float64(bothRated)))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n**%s has seen, you haven't**\n", friend))
+	if len(onlyTheirs) == 0 {
+		sb.WriteString("Nothing: you've seen everything they have.\n")
+	}
+	for i, item := range onlyTheirs {
+		if i >= limit {
+			sb.WriteString(fmt.Sprintf("... and %s more\n", formatCount(len(onlyTheirs)-limit)))
+			break
+		}
+		var details []string
+		if item.rating > 0 {
+			details = append(details, fmt.Sprintf("they rated it %d/10", item.rating))
+		}
+		if item.plays > 1 {
+			details = append(details, fmt.Sprintf("watched %d times", item.plays))
+		} else if item.plays == 0 {
+			details = append(details, "rated, not logged")
+		}
+		if len(details) == 0 {
+			details = append(details, "watched")
+		}
+		sb.WriteString(item.line(strings.Join(details, ", ")))
+	}
+
+	sb.WriteString("\n**Rating disagreements**\n")
+	if len(disagreements) == 0 {
+		sb.WriteString(fmt.Sprintf("None: your ratings are never %d or more points apart.\n", disagreementGap))
+	}
+	for i, d := range disagreements {
+		if i >= limit {
+			sb.WriteString(fmt.Sprintf("... and %s more\n", formatCount(len(disagreements)-limit)))
+			break
+		}
+		sb.WriteString(d[0].line(fmt.Sprintf("you %d/10, %s %d/10", d[0].rating, friend, d[1].rating)))
+	}
+	return sb.String()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
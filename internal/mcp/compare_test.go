@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestCompareWithUserHandler(t *testing.T) {
+	severance := &trakt.Show{Title: "Severance", Year: 2022, IDs: trakt.ShowIDs{Trakt: 154997, Slug: "severance"}}
+	bear := &trakt.Show{Title: "The Bear", Year: 2022, IDs: trakt.ShowIDs{Trakt: 191840}}
+	dark := &trakt.Show{Title: "Dark", Year: 2017, IDs: trakt.ShowIDs{Trakt: 70523}}
+	dune := &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 287071}}
+	arrival := &trakt.Movie{Title: "Arrival", Year: 2016, IDs: trakt.MovieIDs{Trakt: 150167}}
+
+	responses := map[string]any{
+		"/users/me/watched/shows":   []trakt.WatchedEntry{{Plays: 9, Show: severance}, {Plays: 28, Show: bear}},
+		"/users/me/watched/movies":  []trakt.WatchedEntry{{Plays: 1, Movie: dune}},
+		"/users/me/ratings":         []trakt.Rating{{Rating: 9, Type: "movie", Movie: dune}, {Rating: 8, Type: "show", Show: severance}},
+		"/users/sam/watched/shows":  []trakt.WatchedEntry{{Plays: 9, Show: severance}, {Plays: 26, Show: dark}},
+		"/users/sam/watched/movies": []trakt.WatchedEntry{{Plays: 2, Movie: dune}, {Plays: 3, Movie: arrival}},
+		"/users/sam/ratings":        []trakt.Rating{{Rating: 4, Type: "movie", Movie: dune}, {Rating: 7, Type: "show", Show: severance}, {Rating: 10, Type: "show", Show: dark}},
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/users/private/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	compareHandler := makeCompareWithUserHandler(client)
+
+	result, err := compareHandler(context.Background(), json.RawMessage(`{"username":"@sam"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	text := result.Content[0].Text
+
+	for _, want := range []string{
+		"**You and sam**",
+		"In common: 1 shows and 1 movies (you've seen 2 shows and 1 movies, sam has seen 2 and 2)",
+		"Rated by both: 2, on average 3.0 points apart",
+		"📺 **Dark** (2017) - they rated it 10/10, watched 26 times",
+		"🎬 **Arrival** (2016) - watched 3 times",
+		"🎬 **Dune** (2021) - you 9/10, sam 4/10",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "Dark") > strings.Index(text, "Arrival") {
+		t.Error("expected the friend's highest rated item first")
+	}
+	if strings.Contains(text, "The Bear") || strings.Contains(text, "you 8/10") {
+		t.Errorf("expected only the friend's items and big disagreements, got:\n%s", text)
+	}
+
+	result, _ = compareHandler(context.Background(), json.RawMessage(`{"username":"nobody"}`))
+	if !result.IsError || result.Content[0].Text != "No Trakt user found: nobody" {
+		t.Errorf("expected an unknown user error, got %+v", result)
+	}
+
+	result, _ = compareHandler(context.Background(), json.RawMessage(`{"username":"private"}`))
+	if !result.IsError || !strings.HasPrefix(result.Content[0].Text, "private's Trakt profile is private") {
+		t.Errorf("expected a private profile error, got %+v", result)
+	}
+}
@@ -0,0 +1,232 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Page sizes for the in-progress list of get_show_progress.
+const (
+	defaultProgressLimit = 25
+	maxProgressLimit     = 100
+)
+
+// showCompletion is how far the user is through a show.
+type showCompletion struct {
+	show      *trakt.Show
+	watched   int
+	aired     int
+	next      *trakt.Episode // nil when unknown
+	lastWatch time.Time
+}
+
+func (c showCompletion) percent() int {
+	if c.aired == 0 {
+		return 0
+	}
+	return c.watched * 100 / c.aired
+}
+
+// completionJSON is a showCompletion in JSON output.
+type completionJSON struct {
+	Show          *trakt.Show    `json:"show"`
+	Watched       int            `json:"watched"`
+	Aired         int            `json:"aired"`
+	Percent       int            `json:"percent"`
+	NextEpisode   *trakt.Episode `json:"next_episode,omitempty"`
+	LastWatchedAt *time.Time     `json:"last_watched_at,omitempty"`
+}
+
+func (c showCompletion) json() completionJSON {
+	out := completionJSON{Show: c.show, Watched: c.watched, Aired: c.aired, Percent: c.percent(), NextEpisode: c.next}
+	if !c.lastWatch.IsZero() {
+		out.LastWatchedAt = &c.lastWatch
+	}
+	return out
+}
+
+// completionsJSON converts shows for JSON output, as [] when there are none.
+func completionsJSON(shows []showCompletion) []completionJSON {
+	out := make([]completionJSON, len(shows))
+	for i, c := range shows {
+		out[i] = c.json()
+	}
+	return out
+}
+
+func makeGetShowProgressHandler(client TraktAPI, pick matchPicker) ToolHandler {
+	type showProgressArgs struct {
+		ShowName string `json:"showName"`
+		TraktID  int    `json:"traktId"`
+		Limit    int    `json:"limit"`
+		Format   string `json:"format"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a showProgressArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+
+		if a.ShowName != "" || a.TraktID > 0 {
+			ref := itemRef{Name: a.ShowName}
+			if a.TraktID > 0 {
+				ref.IDType, ref.ID = "trakt", strconv.Itoa(a.TraktID)
+			}
+			return oneShowProgress(ctx, client, pick, ref, a.Format), nil
+		}
+
+		if a.Limit <= 0 {
+			a.Limit = defaultProgressLimit
+		}
+		a.Limit = min(a.Limit, maxProgressLimit)
+		return inProgressShows(ctx, client, a.Limit, a.Format), nil
+	}
+}
+
+// oneShowProgress reports the progress of one show from Trakt's progress
+// endpoint, which also knows the next episode.
+func oneShowProgress(ctx context.Context, client TraktAPI, pick matchPicker, ref itemRef, format string) ToolCallResult {
+	match, failure := findItem(ctx, client, pick, "show", ref)
+	if failure != nil {
+		return *failure
+	}
+
+	progress, err := client.GetShowProgress(ctx, strconv.Itoa(match.Show.IDs.Trakt))
+	if err != nil {
+		return ErrorContent(ctx, err)
+	}
+
+	c := showCompletion{show: match.Show, watched: progress.Completed, aired: progress.Aired, next: progress.NextEpisode}
+	if format == formatJSON {
+		return jsonResult(c.json())
+	}
+	return ToolCallResult{Content: []Content{TextContent(formatCompletion(c))}}
+}
+
+// inProgressShows reports every show the user has started and not
+// finished, most recently watched first.
+func inProgressShows(ctx context.Context, client TraktAPI, limit int, format string) ToolCallResult {
+	shows, err := showsInProgress(ctx, client)
+	if err != nil {
+		return ErrorContent(ctx, err)
+	}
+	sort.SliceStable(shows, func(i, j int) bool { return shows[i].lastWatch.After(shows[j].lastWatch) })
+	if format == formatJSON {
+		return jsonResult(completionsJSON(shows[:min(limit, len(shows))]))
+	}
+	if len(shows) == 0 {
+		return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNothingInProgress))}}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📊 **In progress** (%d shows)\n\n", len(shows)))
+	for i, c := range shows {
+		if i >= limit {
+			sb.WriteString(fmt.Sprintf("\n... and %d more. Ask for a higher limit to see them.\n", len(shows)-limit))
+			break
+		}
+		sb.WriteString(formatCompletion(c))
+	}
+	return ToolCallResult{Content: []Content{TextContent(sb.String())}}
+}
+
+// showsInProgress lists the shows the user has started and not finished.
+// The counts come from the watched list, which holds every show in one
+// response, rather than one progress call per show. Shows hidden from
+// Trakt's progress page are left out.
+func showsInProgress(ctx context.Context, client TraktAPI) ([]showCompletion, error) {
+	hidden := make(map[int]bool)
+	// Leaving out hidden shows is a nicety; list them all rather than fail
+	if items, err := client.GetHidden(ctx, "progress_watched", "show"); err == nil {
+		for _, h := range items {
+			if h.Show != nil {
+				hidden[h.Show.IDs.Trakt] = true
+			}
+		}
+	}
+
+	var shows []showCompletion
+	err := client.ForEachWatched(ctx, "shows", func(e trakt.WatchedEntry) error {
+		if e.Show == nil || hidden[e.Show.IDs.Trakt] {
+			return nil
+		}
+		c := showCompletion{show: e.Show, watched: watchedEpisodes(e), aired: e.Show.AiredEpisodes, lastWatch: e.LastWatchedAt}
+		if c.watched > 0 && c.watched < c.aired {
+			shows = append(shows, c)
+		}
+		return nil
+	}, trakt.WithExtended(trakt.ExtendedFull))
+	if err != nil {
+		return nil, err
+	}
+	return shows, nil
+}
+
+// watchedEpisodes counts the distinct regular episodes in a watched show,
+// leaving out specials as Trakt's aired count does.
+func watchedEpisodes(e trakt.WatchedEntry) int {
+	n := 0
+	for _, s := range e.Seasons {
+		if s.Number == 0 {
+			continue
+		}
+		for _, ep := range s.Episodes {
+			if ep.Plays > 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// formatCompletion renders a show's progress, what's left to watch and how
+// long it will take. notes are added to the details line first.
+func formatCompletion(c showCompletion, notes ...string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📺 **%s** - %d/%d episodes watched (%d%%)\n", c.show.Title, c.watched, c.aired, c.percent()))
+
+	details := notes
+	if left := c.aired - c.watched; left > 0 {
+		remaining := fmt.Sprintf("%d left", left)
+		if c.show.Runtime > 0 {
+			remaining += ", about " + formatMinutes(left*c.show.Runtime)
+		}
+		details = append(details, remaining)
+	} else {
+		details = append(details, "all caught up")
+	}
+	if c.next != nil {
+		details = append(details, fmt.Sprintf("next: S%02dE%02d - %s", c.next.Season, c.next.Number, c.next.Title))
+	}
+	sb.WriteString(fmt.Sprintf("   %s\n", strings.Join(details, " · ")))
+	sb.WriteString(idLine(idPart("Show ID", int64(c.show.IDs.Trakt)), slugPart(c.show.IDs.Slug), c.show.URL()))
+	return sb.String()
+}
+
+// formatMinutes renders a duration in minutes as "45m", "3h" or "8h 20m".
+func formatMinutes(minutes int) string {
+	h, m := minutes/60, minutes%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh %dm", h, m)
+	}
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestGetShowProgressHandler_InProgress(t *testing.T) {
+	now := time.Now()
+	severance := &trakt.Show{Title: "Severance", Runtime: 50, AiredEpisodes: 19, IDs: trakt.ShowIDs{Trakt: 154997, Slug: "severance"}}
+	bear := &trakt.Show{Title: "The Bear", Runtime: 30, AiredEpisodes: 28, IDs: trakt.ShowIDs{Trakt: 191840}}
+	finished := &trakt.Show{Title: "Chernobyl", AiredEpisodes: 1, IDs: trakt.ShowIDs{Trakt: 1}}
+	hidden := &trakt.Show{Title: "Lost", AiredEpisodes: 121, IDs: trakt.ShowIDs{Trakt: 2}}
+
+	// seasons marks the first n episodes of season 1 watched, plus a special
+	seasons := func(n int) []trakt.WatchedSeason {
+		s := []trakt.WatchedSeason{{Number: 0, Episodes: []trakt.WatchedEpisode{{Number: 1, Plays: 1}}}, {Number: 1}}
+		for i := 1; i <= n; i++ {
+			s[1].Episodes = append(s[1].Episodes, trakt.WatchedEpisode{Number: i, Plays: 2})
+		}
+		return s
+	}
+
+	var progressCalls int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/sync/watched/shows":
+			if r.URL.Query().Get("extended") != "full" {
+				t.Errorf("expected extended=full, got %q", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode([]trakt.WatchedEntry{
+				{LastWatchedAt: now.Add(-30 * 24 * time.Hour), Show: bear, Seasons: seasons(27)},
+				{LastWatchedAt: now.Add(-48 * time.Hour), Show: severance, Seasons: seasons(9)},
+				{LastWatchedAt: now.Add(-time.Hour), Show: finished, Seasons: seasons(1)},
+				{LastWatchedAt: now, Show: hidden, Seasons: seasons(3)},
+			})
+		case r.URL.Path == "/users/hidden/progress_watched":
+			_ = json.NewEncoder(w).Encode([]trakt.HiddenItem{{Type: "show", Show: hidden}})
+		case strings.HasSuffix(r.URL.Path, "/progress/watched"):
+			progressCalls++
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	progressHandler := makeGetShowProgressHandler(client, nil)
+
+	result, err := progressHandler(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	text := result.Content[0].Text
+
+	if !strings.Contains(text, "**In progress** (2 shows)") {
+		t.Errorf("expected two shows in progress, got:\n%s", text)
+	}
+	if !strings.Contains(text, "📺 **Severance** - 9/19 episodes watched (47%)\n   10 left, about 8h 20m\n") {
+		t.Errorf("expected Severance's progress, got:\n%s", text)
+	}
+	if !strings.Contains(text, "📺 **The Bear** - 27/28 episodes watched (96%)\n   1 left, about 30m\n") {
+		t.Errorf("expected The Bear's progress, got:\n%s", text)
+	}
+	if strings.Index(text, "Severance") > strings.Index(text, "The Bear") {
+		t.Error("expected the most recently watched show first")
+	}
+	if strings.Contains(text, "Chernobyl") || strings.Contains(text, "Lost") {
+		t.Errorf("expected finished and hidden shows to be left out, got:\n%s", text)
+	}
+	if progressCalls != 0 {
+		t.Errorf("expected no per-show progress calls, got %d", progressCalls)
+	}
+
+	result, _ = progressHandler(context.Background(), json.RawMessage(`{"limit":1}`))
+	if !strings.Contains(result.Content[0].Text, "... and 1 more.") || strings.Contains(result.Content[0].Text, "The Bear") {
+		t.Errorf("expected the list to stop at the limit, got:\n%s", result.Content[0].Text)
+	}
+}
+
+func TestGetShowProgressHandler_OneShow(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/search/show":
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{{
+				Type:  "show",
+				Score: 1000,
+				Show:  &trakt.Show{Title: "Severance", Runtime: 50, IDs: trakt.ShowIDs{Trakt: 154997, Slug: "severance"}},
+			}})
+		case "/shows/154997/progress/watched":
+			_ = json.NewEncoder(w).Encode(trakt.ShowProgress{
+				Aired:       19,
+				Completed:   9,
+				NextEpisode: &trakt.Episode{Season: 2, Number: 1, Title: "Hello, Ms. Cobel"},
+			})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	progressHandler := makeGetShowProgressHandler(client, nil)
+
+	result, err := progressHandler(context.Background(), json.RawMessage(`{"showName":"Severance"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "📺 **Severance** - 9/19 episodes watched (47%)\n   10 left, about 8h 20m · next: S02E01 - Hello, Ms. Cobel\n"
+	if result.IsError || !strings.HasPrefix(result.Content[0].Text, want) {
+		t.Errorf("expected %q, got %+v", want, result)
+	}
+}
+
+func TestGetShowProgressHandler_NothingInProgress(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[]"))
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	result, err := makeGetShowProgressHandler(client, nil)(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || !strings.HasPrefix(result.Content[0].Text, "No shows in progress") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestFormatMinutes(t *testing.T) {
+	for minutes, want := range map[int]string{45: "45m", 180: "3h", 500: "8h 20m"} {
+		if got := formatMinutes(minutes); got != want {
+			t.Errorf("formatMinutes(%d) = %q, want %q", minutes, got, want)
+		}
+	}
+}
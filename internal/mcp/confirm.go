@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// confirmationTTL is how long a destructive tool's confirmation token can
+// be redeemed.
+const confirmationTTL = 5 * time.Minute
+
+// SetConfirmDestructive enables confirmation mode, in which destructive
+// tools first return a preview and a one-time token, and only act when
+// called again with the token. It guards against a model removing data the
+// user didn't mean to.
+func (s *Server) SetConfirmDestructive(confirm bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.confirmDestructive = confirm
+}
+
+// ConfirmDestructive reports whether confirmation mode is enabled.
+func (s *Server) ConfirmDestructive() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.confirmDestructive
+}
+
+// confirmations holds the outstanding confirmation tokens. Each token is
+// bound to the action it previewed, such as removing one history entry,
+// so it can't confirm anything else.
+type confirmations struct {
+	mu      sync.Mutex
+	pending map[string]pendingConfirmation
+	now     func() time.Time
+}
+
+type pendingConfirmation struct {
+	action  string
+	expires time.Time
+}
+
+func newConfirmations() *confirmations {
+	return &confirmations{pending: make(map[string]pendingConfirmation), now: time.Now}
+}
+
+// issue returns a new token that confirms action once.
+func (c *confirmations) issue(action string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for t, p := range c.pending {
+		if now.After(p.expires) {
+			delete(c.pending, t)
+		}
+	}
+	c.pending[token] = pendingConfirmation{action: action, expires: now.Add(confirmationTTL)}
+	return token, nil
+}
+
+// redeem reports whether token confirms action and hasn't expired. A
+// token is used up by any attempt to redeem it, so a guessed or stale one
+// can't be retried against another action.
+func (c *confirmations) redeem(token, action string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[token]
+	delete(c.pending, token)
+	return ok && p.action == action && !c.now().After(p.expires)
+}
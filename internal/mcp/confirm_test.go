@@ -0,0 +1,37 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfirmations_Redeem(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newConfirmations()
+	c.now = func() time.Time { return now }
+
+	token, err := c.issue("undo_last_watch:1")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	if c.redeem(token, "undo_last_watch:2") {
+		t.Error("expected a token not to confirm a different action")
+	}
+	if c.redeem(token, "undo_last_watch:1") {
+		t.Error("expected a failed redeem to use the token up")
+	}
+
+	token, _ = c.issue("undo_last_watch:1")
+	if !c.redeem(token, "undo_last_watch:1") {
+		t.Error("expected the token to confirm its action")
+	}
+	if c.redeem(token, "undo_last_watch:1") {
+		t.Error("expected a token to work only once")
+	}
+
+	token, _ = c.issue("undo_last_watch:1")
+	now = now.Add(confirmationTTL + time.Second)
+	if c.redeem(token, "undo_last_watch:1") {
+		t.Error("expected the token to expire")
+	}
+}
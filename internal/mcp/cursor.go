@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// errInvalidCursor is returned for a cursor that wasn't issued by the same
+// tool, or was altered.
+var errInvalidCursor = errors.New("invalid cursor; repeat the original call without it")
+
+// cursorState is what a continuation cursor carries: the tool that issued
+// it, the arguments of the call it continues, and how many items of that
+// call's result were already returned.
+type cursorState struct {
+	Tool   string          `json:"t"`
+	Args   json.RawMessage `json:"a"`
+	Offset int             `json:"o"`
+}
+
+// encodeCursor returns an opaque cursor that continues the call to tool
+// with args after the first offset items.
+func encodeCursor(tool string, args any, offset int) string {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+	state, err := json.Marshal(cursorState{Tool: tool, Args: data, Offset: offset})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(state)
+}
+
+// decodeCursor restores the arguments saved in a cursor from encodeCursor
+// into args and returns the offset to resume from.
+func decodeCursor(tool, cursor string, args any) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	var state cursorState
+	if err := json.Unmarshal(data, &state); err != nil || state.Tool != tool || state.Offset < 0 {
+		return 0, errInvalidCursor
+	}
+	if err := json.Unmarshal(state.Args, args); err != nil {
+		return 0, errInvalidCursor
+	}
+	return state.Offset, nil
+}
+
+// fitRendered returns how many of count items fit in budget bytes when
+// size reports the length of the output for the first n of them, keeping
+// at least one. The output is assumed to grow with n. A budget of zero or
+// less fits everything.
+func fitRendered(count, budget int, size func(n int) int) int {
+	if budget <= 0 || count == 0 || size(count) <= budget {
+		return count
+	}
+	lo, hi := 1, count-1 // lo always fits, or is the minimum
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if size(mid) <= budget {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
+
+// fitBudget returns how many of items fit in budget bytes, keeping at
+// least one so a single large item still makes progress. A budget of zero
+// or less fits everything.
+func fitBudget(items []string, budget int) int {
+	if budget <= 0 {
+		return len(items)
+	}
+	size := 0
+	for i, item := range items {
+		size += len(item)
+		if size > budget && i > 0 {
+			return i
+		}
+	}
+	return len(items)
+}
@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	type args struct {
+		Type  string `json:"type"`
+		Limit int    `json:"limit"`
+	}
+	cursor := encodeCursor("get_history", args{Type: "movies", Limit: 200}, 40)
+
+	var got args
+	offset, err := decodeCursor("get_history", cursor, &got)
+	if err != nil {
+		t.Fatalf("decodeCursor failed: %v", err)
+	}
+	if offset != 40 || got.Type != "movies" || got.Limit != 200 {
+		t.Errorf("decoded offset %d, args %+v", offset, got)
+	}
+
+	// A cursor only continues the tool that issued it
+	if _, err := decodeCursor("search_show", cursor, &got); !errors.Is(err, errInvalidCursor) {
+		t.Errorf("expected errInvalidCursor for another tool, got %v", err)
+	}
+	if _, err := decodeCursor("get_history", "not a cursor", &got); !errors.Is(err, errInvalidCursor) {
+		t.Errorf("expected errInvalidCursor for garbage, got %v", err)
+	}
+}
+
+func TestFitBudget(t *testing.T) {
+	items := []string{"aaaa", "bbbb", "cccc"}
+	tests := []struct {
+		budget int
+		want   int
+	}{
+		{0, 3},  // unlimited
+		{12, 3}, // exactly fits
+		{9, 2},
+		{2, 1}, // always makes progress
+	}
+	for _, tc := range tests {
+		if got := fitBudget(items, tc.budget); got != tc.want {
+			t.Errorf("fitBudget(budget %d) = %d, want %d", tc.budget, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Check is a named health check. Run returns nil when the check passes, or
+// an error explaining what is wrong; Fix tells the user how to resolve it.
+type Check struct {
+	Name string
+	Fix  string
+	Run  func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of one Check.
+type CheckResult struct {
+	Name string
+	Err  error
+	Fix  string
+}
+
+// RunChecks runs checks in order and reports each outcome.
+func RunChecks(ctx context.Context, checks []Check) []CheckResult {
+	results := make([]CheckResult, len(checks))
+	for i, c := range checks {
+		results[i] = CheckResult{Name: c.Name, Err: c.Run(ctx), Fix: c.Fix}
+	}
+	return results
+}
+
+// ClientChecks returns the checks for the Trakt client: credentials are
+// set, a user is signed in, the API answers, and the rate-limit budget
+// isn't nearly spent.
+func ClientChecks(client TraktAPI) []Check {
+	return []Check{
+		credentialsCheck(client),
+		authenticationCheck(client),
+		{
+			Name: "Trakt API",
+			Fix:  "Check the network connection and TRAKT_API_URL. A rejected token means you need to authenticate again.",
+			Run:  client.Ping,
+		},
+		{
+			Name: "rate limit",
+			Fix:  "Wait for the budget to reset before making many more requests.",
+			Run: func(ctx context.Context) error {
+				for _, rl := range client.RateLimits() {
+					if rl.Low() {
+						return fmt.Errorf("only %d of %d requests left in %s until %s",
+							rl.Remaining, rl.Limit, rl.Name, rl.Until.Local().Format("15:04"))
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func credentialsCheck(client TraktAPI) Check {
+	return Check{
+		Name: "credentials",
+		Fix:  "Set TRAKT_CLIENT_ID and TRAKT_CLIENT_SECRET from your application at https://trakt.tv/oauth/applications.",
+		Run: func(ctx context.Context) error {
+			if !client.IsConfigured() {
+				return errors.New("TRAKT_CLIENT_ID is not set")
+			}
+			return nil
+		},
+	}
+}
+
+func authenticationCheck(client TraktAPI) Check {
+	return Check{
+		Name: "authentication",
+		Fix:  "Use the authenticate tool to sign in to Trakt.tv.",
+		Run: func(ctx context.Context) error {
+			if !client.IsAuthenticated() {
+				return errNotAuthenticated
+			}
+			return nil
+		},
+	}
+}
+
+// formatChecks renders check results as a checklist, with fixes for the
+// failures.
+func formatChecks(results []CheckResult) string {
+	var passed int
+	var sb strings.Builder
+	for _, r := range results {
+		if r.Err == nil {
+			passed++
+			sb.WriteString(fmt.Sprintf("✅ %s\n", r.Name))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("❌ %s: %v\n", r.Name, r.Err))
+		if r.Fix != "" {
+			sb.WriteString(fmt.Sprintf("   Fix: %s\n", r.Fix))
+		}
+	}
+	return fmt.Sprintf("Diagnostics: %d of %d checks passed.\n\n%s", passed, len(results), sb.String())
+}
+
+// RegisterDiagnoseTool registers the diagnose tool, which runs checks on
+// demand and reports what is broken and how to fix it.
+func RegisterDiagnoseTool(s *Server, checks []Check) {
+	s.RegisterTool(Tool{
+		Name:        "diagnose",
+		Description: "Check the server's configuration: credentials, sign-in, token file, cache directory and whether Trakt is reachable. Returns a checklist of problems and how to fix them.",
+		Annotations: &ToolAnnotations{Title: "Diagnose configuration", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type:       "object",
+			Properties: map[string]JSONSchema{},
+		},
+	}, func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		return ToolCallResult{
+			Content: []Content{TextContent(formatChecks(RunChecks(ctx, checks)))},
+		}, nil
+	})
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestRunChecks_Format(t *testing.T) {
+	checks := []Check{
+		{Name: "good", Run: func(ctx context.Context) error { return nil }},
+		{Name: "bad", Fix: "Turn it off and on again.", Run: func(ctx context.Context) error { return errors.New("broken") }},
+	}
+
+	results := RunChecks(context.Background(), checks)
+	if len(results) != 2 || results[0].Err != nil || results[1].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	text := formatChecks(results)
+	for _, want := range []string{
+		"1 of 2 checks passed",
+		"✅ good\n",
+		"❌ bad: broken\n   Fix: Turn it off and on again.\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestClientChecks(t *testing.T) {
+	pinged := false
+	client := &fakeTrakt{
+		authenticated: true,
+		ping: func(ctx context.Context) error {
+			pinged = true
+			return nil
+		},
+		rateLimits: []trakt.RateLimit{{Name: "AUTHED_API_GET_LIMIT", Limit: 1000, Remaining: 12}},
+	}
+
+	results := RunChecks(context.Background(), ClientChecks(client))
+	var failed []string
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r.Name)
+		}
+	}
+	if strings.Join(failed, ",") != "credentials,rate limit" {
+		t.Errorf("expected the credentials and rate limit checks to fail, got %v", failed)
+	}
+	if !pinged {
+		t.Error("expected the API to be pinged")
+	}
+}
+
+func TestDiagnoseTool(t *testing.T) {
+	server := NewServer(nil)
+	RegisterDiagnoseTool(server, []Check{
+		{Name: "cache directory", Fix: "Fix it.", Run: func(ctx context.Context) error { return errors.New("read-only file system") }},
+	})
+
+	server.mu.RLock()
+	handler := server.handlers["diagnose"]
+	server.mu.RUnlock()
+	if handler == nil {
+		t.Fatal("diagnose tool not registered")
+	}
+
+	result, err := handler(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("diagnose failed: %v", err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "❌ cache directory: read-only file system") {
+		t.Errorf("unexpected report:\n%s", text)
+	}
+}
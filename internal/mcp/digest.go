@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// digestDays is how far back the weekly digest looks, and how far ahead.
+const digestDays = 7
+
+// weeklyDigest catches the user up on their week: what they watched, the
+// episodes of their shows that aired and they missed, and what airs in the
+// week ahead.
+func weeklyDigest(ctx context.Context, client TraktAPI, now time.Time) (string, error) {
+	since := now.AddDate(0, 0, -digestDays)
+	history, err := historyBetween(ctx, client, "", since, now)
+	if err != nil {
+		return "", err
+	}
+	upcoming, from, to, err := upcomingEpisodes(ctx, client, digestDays, now)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🗓️ **Your week on Trakt** (%s)\n\n", shortDateRange(since, now)))
+
+	sb.WriteString("**What you watched**\n")
+	if len(history) == 0 {
+		sb.WriteString(msg(ctx, msgNothingWatched) + "\n")
+	} else {
+		wt := sumWatchTime(history)
+		sb.WriteString(fmt.Sprintf("%s episodes and %s movies, %s in all\n",
+			formatCount(wt.episodes), formatCount(wt.movies), formatMinutes(wt.showMinutes+wt.movieMinutes)))
+		// Grouping expects history newest first, as Trakt lists it
+		slices.Reverse(history)
+		sb.WriteString(formatHistoryGroups(history, now))
+	}
+
+	sb.WriteString("\n")
+	if len(upcoming) == 0 {
+		sb.WriteString(msg(ctx, msgNothingUpcoming, digestDays, digestDays) + "\n")
+	} else {
+		sb.WriteString(formatUpcoming(upcoming, from, to, now))
+	}
+	return sb.String(), nil
+}
+
+// RegisterPrompts registers the prompts the server offers.
+func RegisterPrompts(s *Server, client TraktAPI) {
+	s.RegisterPrompt(Prompt{
+		Name:        "weekly_digest",
+		Description: "Catch me up on my week: what I watched, what aired that I missed, and what's coming next week.",
+	}, makeWeeklyDigestPromptHandler(client))
+}
+
+func makeWeeklyDigestPromptHandler(client TraktAPI) PromptHandler {
+	return func(ctx context.Context, args map[string]string) (PromptGetResult, error) {
+		if !client.IsAuthenticated() {
+			return PromptGetResult{}, errNotAuthenticated
+		}
+
+		digest, err := weeklyDigest(ctx, client, time.Now())
+		if err != nil {
+			return PromptGetResult{}, err
+		}
+
+		text := "Catch me up on my week of TV and movies from this Trakt digest. Briefly recap what I watched, point out anything I missed that's worth catching up on, and tell me what to look forward to next week.\n\n" + digest
+		return PromptGetResult{
+			Description: "Weekly Trakt digest",
+			Messages:    []PromptMessage{{Role: "user", Content: TextContent(text)}},
+		}, nil
+	}
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestWeeklyDigest(t *testing.T) {
+	now := time.Now()
+	severance := &trakt.Show{Title: "Severance", Runtime: 50, IDs: trakt.ShowIDs{Trakt: 154997, Slug: "severance"}}
+	bear := &trakt.Show{Title: "The Bear", IDs: trakt.ShowIDs{Trakt: 191840}}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/sync/history":
+			_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{
+				{Type: "episode", WatchedAt: now.Add(-24 * time.Hour), Show: severance, Episode: &trakt.Episode{Season: 1, Number: 2}},
+				{Type: "episode", WatchedAt: now.Add(-25 * time.Hour), Show: severance, Episode: &trakt.Episode{Season: 1, Number: 1}},
+			})
+		case strings.HasPrefix(r.URL.Path, "/calendars/my/shows/"):
+			_ = json.NewEncoder(w).Encode([]trakt.CalendarEntry{
+				{FirstAired: now.Add(-time.Minute), Show: bear, Episode: &trakt.Episode{Season: 4, Number: 1, Title: "Groundhog"}},
+				{FirstAired: now.Add(48 * time.Hour), Show: severance, Episode: &trakt.Episode{Season: 2, Number: 1, Title: "Hello, Ms. Cobel"}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/progress/watched"):
+			_ = json.NewEncoder(w).Encode(trakt.ShowProgress{})
+		default:
+			_, _ = w.Write([]byte("[]"))
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	server := NewServer(nil)
+	RegisterResources(server, client)
+	RegisterPrompts(server, client)
+
+	server.mu.RLock()
+	readHandler := server.resourceHandlers[weeklyDigestURI]
+	promptHandler := server.promptHandlers["weekly_digest"]
+	server.mu.RUnlock()
+
+	result, err := readHandler(context.Background(), weeklyDigestURI)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Contents[0].Text
+	for _, want := range []string{
+		"**Your week on Trakt**",
+		"2 episodes and 0 movies, 1h 40m in all",
+		"📺 **Severance** - 2 episodes, S01E01 to S01E02",
+		"**Out now, not watched yet**\n📺 **The Bear** S04E01 - Groundhog",
+		"**Coming up**\n📺 **Severance** S02E01 - Hello, Ms. Cobel",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q, got:\n%s", want, text)
+		}
+	}
+
+	prompt, err := promptHandler(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prompt.Messages) != 1 || prompt.Messages[0].Role != "user" || !strings.Contains(prompt.Messages[0].Content.Text, "**Your week on Trakt**") {
+		t.Errorf("expected the digest in a user message, got %+v", prompt.Messages)
+	}
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/export"
+)
+
+// exportFormat picks the format of an export to path: the one asked for,
+// or the one the file extension names, or CSV.
+func exportFormat(format, path string) (string, error) {
+	switch format {
+	case export.FormatCSV, export.FormatJSON, export.FormatLetterboxd:
+		return format, nil
+	case "":
+		return export.FormatFor(path), nil
+	}
+	return "", fmt.Errorf("format must be %q, %q or %q", export.FormatCSV, export.FormatJSON, export.FormatLetterboxd)
+}
+
+// expandPath resolves a path the model gives, which may start with "~/",
+// to an absolute one.
+func expandPath(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, rest)
+	}
+	return filepath.Abs(path)
+}
+
+// writeExport writes an export to path through a temporary file in the
+// same directory, so a failed export never leaves a truncated file behind
+// or clobbers an earlier backup.
+func writeExport(path string, overwrite bool, write func(f *os.File) (int, error)) (int, error) {
+	if !overwrite {
+		if _, err := os.Stat(path); err == nil {
+			return 0, fs.ErrExist
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, os.Rename(tmp.Name(), path)
+}
+
+func makeExportHistoryHandler(client TraktAPI) ToolHandler {
+	type exportArgs struct {
+		Path      string `json:"path"`
+		Format    string `json:"format"`
+		Type      string `json:"type"`
+		Overwrite bool   `json:"overwrite"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a exportArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		if strings.TrimSpace(a.Path) == "" {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgRequired, "path"))},
+				IsError: true,
+			}, nil
+		}
+		if a.Type != "" && a.Type != "shows" && a.Type != "movies" {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgExportType))},
+				IsError: true,
+			}, nil
+		}
+		format, err := exportFormat(a.Format, a.Path)
+		if err != nil {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgExportFormat, export.FormatCSV, export.FormatJSON, export.FormatLetterboxd))},
+				IsError: true,
+			}, nil
+		}
+		if format == export.FormatLetterboxd && a.Type == "shows" {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgLetterboxdMoviesOnly))},
+				IsError: true,
+			}, nil
+		}
+		path, err := expandPath(a.Path)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+
+		n, err := writeExport(path, a.Overwrite, func(f *os.File) (int, error) {
+			return export.Write(ctx, client, f, format, a.Type)
+		})
+		if errors.Is(err, fs.ErrExist) {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgFileExists, path))},
+				IsError: true,
+			}, nil
+		}
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+
+		if format == export.FormatLetterboxd {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgExportedLetterboxd, formatCount(n), path))},
+			}, nil
+		}
+		key := msgExported
+		switch a.Type {
+		case "shows":
+			key = msgExportedShows
+		case "movies":
+			key = msgExportedMovies
+		}
+		return ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, key, formatCount(n), path, strings.ToUpper(format)))},
+		}, nil
+	}
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestExportHistoryHandler(t *testing.T) {
+	pages := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync/history" {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+		pages++
+		page := r.URL.Query().Get("page")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Pagination-Page", page)
+		w.Header().Set("X-Pagination-Page-Count", "2")
+		item := trakt.HistoryItem{
+			ID: 1, Type: "movie", Action: "watch", WatchedAt: time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC),
+			Movie: &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 287071}},
+		}
+		if page == "2" {
+			item.ID = 2
+		}
+		_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{item})
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	exportHandler := makeExportHistoryHandler(client)
+	path := filepath.Join(t.TempDir(), "history.json")
+	args := json.RawMessage(`{"path":` + jsonString(path) + `}`)
+
+	result, err := exportHandler(context.Background(), args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "Exported 2 plays to "+path+" (JSON)") {
+		t.Errorf("unexpected result: %s", text)
+	}
+	if pages != 2 {
+		t.Errorf("expected both pages to be read, got %d", pages)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("export wasn't written: %v", err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal(data, &records); err != nil || len(records) != 2 {
+		t.Errorf("expected 2 JSON records, got %d (%v)", len(records), err)
+	}
+
+	// A second export must not replace the first without overwrite
+	result, _ = exportHandler(context.Background(), args)
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "already exists") {
+		t.Errorf("expected an existing file to be refused, got %+v", result)
+	}
+	result, _ = exportHandler(context.Background(), json.RawMessage(`{"path":`+jsonString(path)+`,"overwrite":true}`))
+	if result.IsError {
+		t.Errorf("expected overwrite to replace the file, got %+v", result)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*"))
+	if len(matches) > 0 {
+		t.Errorf("expected no temporary files left behind, got %v", matches)
+	}
+}
+
+func TestExportHistoryHandler_InvalidArgs(t *testing.T) {
+	client := &fakeTrakt{authenticated: true}
+	exportHandler := makeExportHistoryHandler(client)
+	for _, args := range []string{`{}`, `{"path":"h.csv","format":"xml"}`, `{"path":"h.csv","type":"episodes"}`} {
+		result, err := exportHandler(context.Background(), json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("expected %s to be rejected", args)
+		}
+	}
+
+	ctx := withLanguage(context.Background(), "de")
+	result, _ := exportHandler(ctx, json.RawMessage(`{"path":"h.csv","format":"letterboxd","type":"shows"}`))
+	if !result.IsError || result.Content[0].Text != catalogs["de"][msgLetterboxdMoviesOnly] {
+		t.Errorf("expected the German message, got %+v", result)
+	}
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,266 @@
+package mcp
+
+import (
+	"crypto/subtle"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/ical"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+const (
+	// calendarDaysBack is how far back the calendar feed starts, so
+	// episodes that just aired stay on it for a while.
+	calendarDaysBack = 7
+	// calendarDays is how many days the calendar feed covers, the most
+	// Trakt returns at once.
+	calendarDays = 33
+	// defaultEpisodeRuntime is the length given to episodes Trakt has no
+	// runtime for.
+	defaultEpisodeRuntime = 30 * time.Minute
+	// activityItems is how many plays and ratings the activity feed lists.
+	activityItems = 50
+)
+
+// RegisterFeeds serves feeds of the user's account beside the HTTP
+// transports, for apps that poll rather than speak MCP. Each URL includes
+// token, so only those given it can read the feed:
+//
+//   - /feeds/{token}/calendar.ics is an iCalendar feed of the episodes of
+//     the user's shows and the releases of their movies.
+//   - /feeds/{token}/activity.xml is an RSS feed of what the user recently
+//     watched and rated.
+func RegisterFeeds(s *Server, client TraktAPI, token string) {
+	s.HandleHTTP("GET /feeds/{token}/calendar.ics", feedToken(token, calendarFeed(client, time.Now)))
+	s.HandleHTTP("GET /feeds/{token}/activity.xml", feedToken(token, activityFeed(client, time.Now)))
+}
+
+// feedToken serves h only to requests whose {token} path segment is token,
+// answering others as if the feed didn't exist.
+func feedToken(token string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.PathValue("token")), []byte(token)) != 1 {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// calendarFeed serves the user's calendar from a week ago to a few weeks
+// ahead: episodes at their air time, leaving out shows hidden from the
+// calendar, and movies as all-day events on their release date.
+func calendarFeed(client TraktAPI, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !client.IsAuthenticated() {
+			http.Error(w, "not signed in to Trakt", http.StatusServiceUnavailable)
+			return
+		}
+
+		ctx := r.Context()
+		today := now().UTC().Truncate(24 * time.Hour)
+		start := today.AddDate(0, 0, -calendarDaysBack)
+		end := start.AddDate(0, 0, calendarDays)
+
+		episodes, err := client.GetMyShowsCalendar(ctx, start, calendarDays, trakt.WithExtended(trakt.ExtendedFull))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("read show calendar: %v", err), http.StatusBadGateway)
+			return
+		}
+		hidden, err := client.GetHidden(ctx, "calendar", "show")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("read hidden shows: %v", err), http.StatusBadGateway)
+			return
+		}
+		movies, err := client.GetMyMoviesCalendar(ctx, start, calendarDays, trakt.WithExtended(trakt.ExtendedFull))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("read movie calendar: %v", err), http.StatusBadGateway)
+			return
+		}
+
+		cal := ical.Calendar{Name: "Trakt", Updated: now()}
+		for _, e := range filterCalendar(episodes, hidden, start, end) {
+			cal.Events = append(cal.Events, episodeEvent(e))
+		}
+		for _, m := range movies {
+			if event, ok := movieEvent(m); ok {
+				cal.Events = append(cal.Events, event)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		_ = ical.Write(w, cal)
+	}
+}
+
+// episodeEvent is an episode airing, lasting its runtime.
+func episodeEvent(e trakt.CalendarEntry) ical.Event {
+	runtime := defaultEpisodeRuntime
+	switch {
+	case e.Episode.Runtime > 0:
+		runtime = time.Duration(e.Episode.Runtime) * time.Minute
+	case e.Show.Runtime > 0:
+		runtime = time.Duration(e.Show.Runtime) * time.Minute
+	}
+
+	summary := fmt.Sprintf("%s S%02dE%02d", e.Show.Title, e.Episode.Season, e.Episode.Number)
+	if e.Episode.Title != "" {
+		summary += ": " + e.Episode.Title
+	}
+	return ical.Event{
+		UID:         fmt.Sprintf("episode-%d-%d-%d@trakt-mcp", e.Show.IDs.Trakt, e.Episode.Season, e.Episode.Number),
+		Summary:     summary,
+		Description: e.Episode.Overview,
+		URL:         e.Show.EpisodeURL(e.Episode.Season, e.Episode.Number),
+		Start:       e.FirstAired,
+		End:         e.FirstAired.Add(runtime),
+	}
+}
+
+// movieEvent is a movie release, all day, or false if Trakt gave no
+// usable date.
+func movieEvent(m trakt.MovieCalendarEntry) (ical.Event, bool) {
+	released, err := time.Parse("2006-01-02", m.Released)
+	if err != nil || m.Movie == nil {
+		return ical.Event{}, false
+	}
+	return ical.Event{
+		UID:         fmt.Sprintf("movie-%d@trakt-mcp", m.Movie.IDs.Trakt),
+		Summary:     m.Movie.Title,
+		Description: m.Movie.Overview,
+		URL:         m.Movie.URL(),
+		Start:       released,
+		End:         released.AddDate(0, 0, 1),
+		AllDay:      true,
+	}, true
+}
+
+// rss is an RSS 2.0 document.
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	at          time.Time
+}
+
+type rssGUID struct {
+	ID        string `xml:",chardata"`
+	Permalink bool   `xml:"isPermaLink,attr"`
+}
+
+// activityFeed serves the user's latest plays and ratings, newest first.
+func activityFeed(client TraktAPI, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !client.IsAuthenticated() {
+			http.Error(w, "not signed in to Trakt", http.StatusServiceUnavailable)
+			return
+		}
+
+		ctx := r.Context()
+		history, err := client.GetHistory(ctx, "", activityItems)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("read history: %v", err), http.StatusBadGateway)
+			return
+		}
+		ratings, err := client.GetUserRatings(ctx, "me", "")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("read ratings: %v", err), http.StatusBadGateway)
+			return
+		}
+
+		var items []rssItem
+		for _, h := range history {
+			items = append(items, playItem(h))
+		}
+		for _, rating := range ratings {
+			if item, ok := ratingItem(rating); ok {
+				items = append(items, item)
+			}
+		}
+		sort.SliceStable(items, func(i, j int) bool { return items[i].at.After(items[j].at) })
+		if len(items) > activityItems {
+			items = items[:activityItems]
+		}
+
+		feed := rss{Version: "2.0", Channel: rssChannel{
+			Title:         "Trakt activity",
+			Link:          "https://trakt.tv",
+			Description:   "Recently watched and rated on Trakt",
+			LastBuildDate: now().UTC().Format(time.RFC1123Z),
+			Items:         items,
+		}}
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		_, _ = w.Write([]byte(xml.Header))
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		_ = enc.Encode(feed)
+	}
+}
+
+// playItem is a play from the user's history.
+func playItem(h trakt.HistoryItem) rssItem {
+	item := rssItem{
+		Title:   "Watched " + playTitle(h),
+		GUID:    rssGUID{ID: fmt.Sprintf("trakt-history-%d", h.ID)},
+		PubDate: h.WatchedAt.UTC().Format(time.RFC1123Z),
+		at:      h.WatchedAt,
+	}
+	switch {
+	case h.Show != nil && h.Episode != nil:
+		item.Link = h.Show.EpisodeURL(h.Episode.Season, h.Episode.Number)
+		item.Description = h.Episode.Title
+	case h.Movie != nil:
+		item.Link = h.Movie.URL()
+	}
+	return item
+}
+
+// ratingItem is one of the user's ratings, or false for one of a kind the
+// feed doesn't show.
+func ratingItem(r trakt.Rating) (rssItem, bool) {
+	var title, link, key string
+	switch {
+	case r.Type == "episode" && r.Show != nil && r.Episode != nil:
+		title = fmt.Sprintf("%s S%02dE%02d", r.Show.Title, r.Episode.Season, r.Episode.Number)
+		link = r.Show.EpisodeURL(r.Episode.Season, r.Episode.Number)
+		key = fmt.Sprintf("episode-%d-%d-%d", r.Show.IDs.Trakt, r.Episode.Season, r.Episode.Number)
+	case r.Type == "show" && r.Show != nil:
+		title = r.Show.Title + yearSuffix(r.Show.Year)
+		link = r.Show.URL()
+		key = fmt.Sprintf("show-%d", r.Show.IDs.Trakt)
+	case r.Type == "movie" && r.Movie != nil:
+		title = r.Movie.Title + yearSuffix(r.Movie.Year)
+		link = r.Movie.URL()
+		key = fmt.Sprintf("movie-%d", r.Movie.IDs.Trakt)
+	default:
+		return rssItem{}, false
+	}
+	return rssItem{
+		Title: fmt.Sprintf("Rated %s %d/10", title, r.Rating),
+		Link:  link,
+		// Rating an item again gives it a new entry
+		GUID:    rssGUID{ID: fmt.Sprintf("trakt-rating-%s-%d", key, r.RatedAt.Unix())},
+		PubDate: r.RatedAt.UTC().Format(time.RFC1123Z),
+		at:      r.RatedAt,
+	}, true
+}
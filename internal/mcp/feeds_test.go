@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// feedTrakt adds a movie calendar to suggestTrakt.
+type feedTrakt struct {
+	suggestTrakt
+	movieCalendar []trakt.MovieCalendarEntry
+}
+
+func (f *feedTrakt) GetMyMoviesCalendar(ctx context.Context, start time.Time, days int, opts ...trakt.RequestOption) ([]trakt.MovieCalendarEntry, error) {
+	return f.movieCalendar, nil
+}
+
+func TestCalendarFeed(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	severance := &trakt.Show{Title: "Severance", Runtime: 55, IDs: trakt.ShowIDs{Trakt: 1, Slug: "severance"}}
+	hiddenShow := &trakt.Show{Title: "Hidden", IDs: trakt.ShowIDs{Trakt: 2}}
+	client := &feedTrakt{
+		suggestTrakt: suggestTrakt{
+			fakeTrakt: fakeTrakt{authenticated: true},
+			calendar: []trakt.CalendarEntry{
+				{FirstAired: now.Add(13 * time.Hour), Show: severance, Episode: &trakt.Episode{Season: 2, Number: 1, Title: "Hello, Ms. Cobel"}},
+				{FirstAired: now.Add(time.Hour), Show: hiddenShow, Episode: &trakt.Episode{Season: 1, Number: 1}},
+			},
+			hidden: []trakt.HiddenItem{{Show: hiddenShow}},
+		},
+		movieCalendar: []trakt.MovieCalendarEntry{
+			{Released: "2026-12-18", Movie: &trakt.Movie{Title: "Dune: Part Three", IDs: trakt.MovieIDs{Trakt: 7, Slug: "dune-part-three-2026"}}},
+		},
+	}
+
+	server := NewServer(nil)
+	server.HandleHTTP("GET /feeds/{token}/calendar.ics", feedToken("s3cret", calendarFeed(client, func() time.Time { return now })))
+	mux := server.httpMux()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feeds/s3cret/calendar.ics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"UID:episode-1-2-1@trakt-mcp\r\n",
+		"DTSTART:20261017T010000Z\r\nDTEND:20261017T015500Z\r\nSUMMARY:Severance S02E01: Hello\\, Ms. Cobel\r\n",
+		"URL:https://trakt.tv/shows/severance/seasons/2/episodes/1\r\n",
+		"DTSTART;VALUE=DATE:20261218\r\nDTEND;VALUE=DATE:20261219\r\nSUMMARY:Dune: Part Three\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "Hidden") {
+		t.Errorf("expected the hidden show left out:\n%s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feeds/guess/calendar.ics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected a wrong token to get 404, got %d", rec.Code)
+	}
+}
+
+func TestActivityFeed(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	severance := &trakt.Show{Title: "Severance", Year: 2022, IDs: trakt.ShowIDs{Trakt: 1, Slug: "severance"}}
+	client := &backupTrakt{
+		fakeTrakt: fakeTrakt{
+			authenticated: true,
+			getHistory: func(ctx context.Context, historyType string, limit int) ([]trakt.HistoryItem, error) {
+				return []trakt.HistoryItem{
+					{ID: 11, WatchedAt: now.Add(-time.Hour), Type: "episode", Show: severance, Episode: &trakt.Episode{Season: 2, Number: 1, Title: "Hello, Ms. Cobel"}},
+					{ID: 10, WatchedAt: now.Add(-48 * time.Hour), Type: "movie", Movie: &trakt.Movie{Title: "Dune & Co", Year: 2021, IDs: trakt.MovieIDs{Trakt: 7, Slug: "dune-2021"}}},
+				}, nil
+			},
+		},
+		ratings: []trakt.Rating{
+			{Rating: 9, RatedAt: now.Add(-2 * time.Hour), Type: "show", Show: severance},
+			{Rating: 7, RatedAt: now.Add(-time.Hour), Type: "season", Show: severance},
+		},
+	}
+
+	server := NewServer(nil)
+	server.HandleHTTP("GET /feeds/{token}/activity.xml", feedToken("s3cret", activityFeed(client, func() time.Time { return now })))
+	rec := httptest.NewRecorder()
+	server.httpMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feeds/s3cret/activity.xml", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/rss+xml") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	var feed rss
+	if err := xml.Unmarshal(rec.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("invalid feed: %v\n%s", err, rec.Body.String())
+	}
+	var titles []string
+	for _, item := range feed.Channel.Items {
+		titles = append(titles, item.Title)
+	}
+	want := []string{"Watched Severance S02E01", "Rated Severance (2022) 9/10", "Watched Dune & Co (2021)"}
+	if strings.Join(titles, "|") != strings.Join(want, "|") {
+		t.Errorf("expected items %q, got %q", want, titles)
+	}
+	first := feed.Channel.Items[0]
+	if first.Link != "https://trakt.tv/shows/severance/seasons/2/episodes/1" || first.GUID.ID != "trakt-history-11" || first.GUID.Permalink {
+		t.Errorf("unexpected first item %+v", first)
+	}
+	if first.PubDate != "Fri, 16 Oct 2026 11:00:00 +0000" {
+		t.Errorf("unexpected pubDate %q", first.PubDate)
+	}
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxMessageSize bounds a single JSON-RPC message on any transport.
+const maxMessageSize = 10 * 1024 * 1024
+
+// Framing selects how JSON-RPC messages are delimited on a stream.
+type Framing string
+
+const (
+	// FramingAuto detects the framing from the first message: JSON starts
+	// with '{' or '[', anything else is treated as a Content-Length header.
+	FramingAuto Framing = "auto"
+	// FramingLine delimits messages with newlines (the MCP stdio default).
+	FramingLine Framing = "line"
+	// FramingContentLength prefixes each message with LSP-style headers.
+	FramingContentLength Framing = "content-length"
+)
+
+// ParseFraming converts a flag value to a Framing.
+func ParseFraming(s string) (Framing, error) {
+	switch f := Framing(s); f {
+	case FramingAuto, FramingLine, FramingContentLength:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown framing %q (want auto, line, or content-length)", s)
+	}
+}
+
+// frameSplitter is a bufio.SplitFunc source that understands both framings.
+// In auto mode the first message fixes the framing for the rest of the stream,
+// and onDetect is called if it turns out to be Content-Length.
+type frameSplitter struct {
+	mode     Framing
+	onDetect func()
+}
+
+func (f *frameSplitter) split(data []byte, atEOF bool) (int, []byte, error) {
+	if f.mode == FramingAuto {
+		trimmed := bytes.TrimLeft(data, " \t\r\n")
+		if len(trimmed) == 0 {
+			if atEOF {
+				return len(data), nil, nil
+			}
+			return 0, nil, nil
+		}
+		if trimmed[0] == '{' || trimmed[0] == '[' {
+			f.mode = FramingLine
+		} else {
+			f.mode = FramingContentLength
+			if f.onDetect != nil {
+				f.onDetect()
+			}
+		}
+	}
+
+	if f.mode == FramingLine {
+		return bufio.ScanLines(data, atEOF)
+	}
+	return splitContentLength(data, atEOF)
+}
+
+// splitContentLength extracts one message framed by Content-Length headers.
+func splitContentLength(data []byte, atEOF bool) (int, []byte, error) {
+	// Tolerate stray line breaks between messages
+	start := len(data) - len(bytes.TrimLeft(data, "\r\n"))
+	if start == len(data) {
+		if atEOF {
+			return len(data), nil, nil
+		}
+		return 0, nil, nil
+	}
+
+	headerEnd := bytes.Index(data[start:], []byte("\r\n\r\n"))
+	if headerEnd < 0 {
+		if atEOF {
+			return 0, nil, fmt.Errorf("incomplete message header")
+		}
+		return 0, nil, nil
+	}
+
+	length := -1
+	for _, line := range strings.Split(string(data[start:start+headerEnd]), "\r\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 0 {
+			return 0, nil, fmt.Errorf("invalid Content-Length %q", value)
+		}
+		length = n
+	}
+	if length < 0 {
+		return 0, nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	bodyStart := start + headerEnd + 4
+	if len(data) < bodyStart+length {
+		if atEOF {
+			return 0, nil, fmt.Errorf("truncated message body")
+		}
+		return 0, nil, nil
+	}
+
+	return bodyStart + length, data[bodyStart : bodyStart+length], nil
+}
@@ -3,9 +3,16 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/kofifort/trakt-mcp-go/internal/importer"
 	"github.com/kofifort/trakt-mcp-go/internal/trakt"
 )
 
@@ -14,22 +21,68 @@ import (
 // and require user disambiguation. Trakt API scores exact title matches at 1000+.
 const exactMatchScoreThreshold = 1000
 
+// Page sizes for search_show. Trakt returns 10 results per page by default.
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+)
+
+// samplingTimeout bounds how long match selection waits on the client's LLM.
+const samplingTimeout = 30 * time.Second
+
+// traktIDPattern finds the numbers in a sampled reply, one of which should
+// be a candidate's Trakt ID.
+var traktIDPattern = regexp.MustCompile(`\d+`)
+
 // RegisterTools registers all Trakt tools with the MCP server.
-func RegisterTools(s *Server, client *trakt.Client) {
+func RegisterTools(s *Server, client TraktAPI) {
+	pending := &pendingAuth{}
+
 	// authenticate - OAuth device flow
 	s.RegisterTool(Tool{
 		Name:        "authenticate",
-		Description: "Authenticate with Trakt.tv using OAuth device flow. Returns a verification URL and code for the user to authorize.",
+		Description: "Authenticate with Trakt.tv using OAuth device flow, or a browser redirect when running on the same machine as the browser. Returns a verification URL and code for the user to authorize.",
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"method": {
+					Type:        "string",
+					Description: "device (enter a code on any device, default) or browser (approve in a browser on this machine)",
+					Enum:        []string{"device", "browser"},
+				},
+				"autoComplete": {
+					Type:        "boolean",
+					Description: "Poll in the background and finish authentication once the code is approved (default: true)",
+				},
+			},
+		},
+	}, makeAuthenticateHandler(s, client, pending))
+
+	// complete_authentication - finish a pending device flow
+	s.RegisterTool(Tool{
+		Name:        "complete_authentication",
+		Description: "Check whether the code from authenticate has been approved and, if so, finish signing in to Trakt.tv.",
 		InputSchema: JSONSchema{
 			Type:       "object",
 			Properties: map[string]JSONSchema{},
 		},
-	}, makeAuthenticateHandler(client))
+	}, makeCompleteAuthHandler(client, pending))
+
+	// refresh_auth - rotate credentials on demand
+	s.RegisterTool(Tool{
+		Name:        "refresh_auth",
+		Description: "Exchange the stored refresh token for new Trakt.tv credentials. Tokens are also refreshed automatically before they expire.",
+		InputSchema: JSONSchema{
+			Type:       "object",
+			Properties: map[string]JSONSchema{},
+		},
+	}, makeRefreshAuthHandler(client))
 
 	// search_show - search for content
 	s.RegisterTool(Tool{
 		Name:        "search_show",
-		Description: "Search for TV shows, movies, or anime by title. Returns matching content with IDs and metadata.",
+		Description: "Search for TV shows, movies, or anime by title, or for episodes and people. Returns matching content with IDs and metadata.",
+		Annotations: &ToolAnnotations{Title: "Search shows and movies", ReadOnlyHint: true},
 		InputSchema: JSONSchema{
 			Type: "object",
 			Properties: map[string]JSONSchema{
@@ -39,18 +92,28 @@ func RegisterTools(s *Server, client *trakt.Client) {
 				},
 				"type": {
 					Type:        "string",
-					Description: "Content type filter (optional)",
-					Enum:        []string{"show", "movie"},
+					Description: "Content type filter (optional; shows and movies by default). Use episode to find an episode by its title, or person for cast and crew",
+					Enum:        []string{"show", "movie", "episode", "person"},
+				},
+				"limit": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Maximum number of results (default %d, at most %d)", defaultSearchLimit, maxSearchLimit),
 				},
+				"page": {
+					Type:        "integer",
+					Description: "Page of results to return, starting at 1 (default 1)",
+				},
+				"format": formatProperty,
 			},
 			Required: []string{"query"},
 		},
-	}, makeSearchHandler(client))
+	}, makeSearchHandler(s, client))
 
 	// get_history - retrieve watch history
 	s.RegisterTool(Tool{
 		Name:        "get_history",
 		Description: "Retrieve watch history with optional filters. Supports content type filtering.",
+		Annotations: &ToolAnnotations{Title: "Get watch history", ReadOnlyHint: true},
 		InputSchema: JSONSchema{
 			Type: "object",
 			Properties: map[string]JSONSchema{
@@ -60,232 +123,1123 @@ func RegisterTools(s *Server, client *trakt.Client) {
 					Enum:        []string{"shows", "movies"},
 				},
 				"limit": {
-					Type:        "number",
-					Description: "Maximum number of items to return",
+					Type:        "integer",
+					Description: fmt.Sprintf("Maximum number of items to return (default %d, at most %d)", defaultHistoryLimit, maxHistoryLimit),
+				},
+				"page": {
+					Type:        "integer",
+					Description: "Page of history to return, starting at 1 (default 1)",
+				},
+				"format": formatProperty,
+				"groupBy": {
+					Type:        "string",
+					Description: "show collapses each show's episodes into one line with a count and date range, e.g. to summarize a binge. session analyzes viewing sessions over the last days instead of listing a page: sessions per week, average session and biggest binge",
+					Enum:        []string{groupByShow, groupBySession},
+				},
+				"days": {
+					Type:        "integer",
+					Description: fmt.Sprintf("With groupBy session, days of history to analyze (default %d, at most %d)", defaultSessionDays, maxSessionDays),
+				},
+				"sessionGap": {
+					Type:        "integer",
+					Description: fmt.Sprintf("With groupBy session, the longest break in hours between plays of one session (default %d)", defaultSessionGap),
+				},
+				"cursor": {
+					Type:        "string",
+					Description: "Continue a result that was cut short, using the cursor it ended with. The other arguments are taken from the cursor.",
 				},
 			},
 		},
-	}, makeGetHistoryHandler(client))
+	}, makeGetHistoryHandler(s, client))
 
-	// log_watch - log a watch
+	// get_watchlist - list the watchlist
 	s.RegisterTool(Tool{
-		Name:        "log_watch",
-		Description: "Log a single episode or movie as watched. Accepts ISO 8601 dates. If no date provided, uses current time.",
+		Name:        "get_watchlist",
+		Description: "List the shows and movies on the user's Trakt watchlist.",
+		Annotations: &ToolAnnotations{Title: "Get watchlist", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"type": {
+					Type:        "string",
+					Description: "List only shows or only movies (optional)",
+					Enum:        []string{"shows", "movies"},
+				},
+				"format": formatProperty,
+				"cursor": {
+					Type:        "string",
+					Description: "Continue a result that was cut short, using the cursor it ended with. The other arguments are taken from the cursor.",
+				},
+			},
+		},
+	}, makeGetWatchlistHandler(s, client))
+
+	// what_should_i_watch - ranked suggestions from progress, calendar, watchlist and recommendations
+	s.RegisterTool(Tool{
+		Name:        "what_should_i_watch",
+		Description: "Suggest what to watch next, ranked with reasons, from shows in progress, episodes airing today, the watchlist, and Trakt's recommendations. One call instead of checking each.",
+		Annotations: &ToolAnnotations{Title: "What should I watch", ReadOnlyHint: true},
 		InputSchema: JSONSchema{
 			Type: "object",
 			Properties: map[string]JSONSchema{
 				"type": {
 					Type:        "string",
-					Description: "Content type",
-					Enum:        []string{"episode", "movie"},
+					Description: "Only suggest shows or only movies (optional)",
+					Enum:        []string{"show", "movie"},
+				},
+				"limit": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Number of suggestions (default %d, at most %d)", defaultSuggestionLimit, maxSuggestionLimit),
 				},
+				"format": formatProperty,
+			},
+		},
+	}, makeWhatShouldIWatchHandler(client))
+
+	// get_upcoming - unwatched episodes of the user's shows around this week
+	s.RegisterTool(Tool{
+		Name:        "get_upcoming",
+		Description: "List what's new for the user: episodes of their shows that recently aired and they haven't watched, and episodes airing soon. Leaves out shows hidden from the Trakt calendar and episodes already logged.",
+		Annotations: &ToolAnnotations{Title: "Get upcoming episodes", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"days": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Days to look back and ahead from today (default %d, at most %d)", defaultUpcomingDays, maxUpcomingDays),
+				},
+				"format": formatProperty,
+			},
+		},
+	}, makeGetUpcomingHandler(client))
+
+	// get_show_progress - how far the user is through one show or all of them
+	s.RegisterTool(Tool{
+		Name:        "get_show_progress",
+		Description: "Show how far the user is through a show: episodes watched out of those aired, percent complete, and roughly how long the rest takes to watch. Without a show, lists every show in progress, most recently watched first.",
+		Annotations: &ToolAnnotations{Title: "Get show progress", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
 				"showName": {
 					Type:        "string",
-					Description: "Show name (required for episodes)",
+					Description: "Name of the show (leave out to list every show in progress)",
 				},
-				"season": {
-					Type:        "number",
-					Description: "Season number (required for episodes)",
+				"traktId": {
+					Type:        "integer",
+					Description: "Trakt ID of the show, from a previous result",
+				},
+				"limit": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Most shows to list (default %d, at most %d)", defaultProgressLimit, maxProgressLimit),
+				},
+				"format": formatProperty,
+			},
+		},
+	}, makeGetShowProgressHandler(client, samplingPicker(s)))
+
+	// get_stalled_shows - shows in progress the user hasn't watched lately
+	s.RegisterTool(Tool{
+		Name:        "get_stalled_shows",
+		Description: "List the user's shows in progress with no plays in the last days, longest stalled first, with how far they got and what's left, to help decide what to resume or drop.",
+		Annotations: &ToolAnnotations{Title: "Get stalled shows", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"days": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Days without a play before a show counts as stalled (default %d)", defaultStalledDays),
+				},
+				"format": formatProperty,
+			},
+		},
+	}, makeGetStalledShowsHandler(client))
+
+	// compare_with_user - what the user and a friend have watched and rated
+	s.RegisterTool(Tool{
+		Name:        "compare_with_user",
+		Description: "Compare the user's watched shows, movies and ratings with another Trakt user's: what they have in common, what the other user has seen that the user hasn't, and where their ratings disagree most. The other user's profile must be public, or followed by the user.",
+		Annotations: &ToolAnnotations{Title: "Compare with user", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"username": {
+					Type:        "string",
+					Description: "Trakt username or profile slug of the other user",
+				},
+				"limit": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Most items to list in each section (default %d, at most %d)", defaultCompareLimit, maxCompareLimit),
+				},
+			},
+			Required: []string{"username"},
+		},
+	}, makeCompareWithUserHandler(client))
+
+	// get_watch_time - hours watched this week, month or year
+	s.RegisterTool(Tool{
+		Name:        "get_watch_time",
+		Description: "Total how long the user spent watching this week, month or year, from their history and each episode's or movie's runtime, with a breakdown by show.",
+		Annotations: &ToolAnnotations{Title: "Get watch time", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"period": {
+					Type:        "string",
+					Description: "Calendar period to date (default week, which starts on Monday)",
+					Enum:        []string{periodWeek, periodMonth, periodYear},
+				},
+				"limit": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Most shows in the breakdown (default %d)", defaultWatchTimeShows),
+				},
+			},
+		},
+	}, makeGetWatchTimeHandler(client))
+
+	// year_in_review - a recap of a year of watching
+	s.RegisterTool(Tool{
+		Name:        "year_in_review",
+		Description: "Recap a year of the user's watching from their full history: totals, top shows and genres, busiest month, longest binge, and the first and last watch of the year.",
+		Annotations: &ToolAnnotations{Title: "Year in review", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"year": {
+					Type:        "integer",
+					Description: "Year to recap (default this year, so far)",
 				},
-				"episode": {
+			},
+		},
+	}, makeYearInReviewHandler(client))
+
+	// get_streaks - consecutive days with something watched
+	s.RegisterTool(Tool{
+		Name:        "get_streaks",
+		Description: "Report the user's current and longest viewing streaks: runs of consecutive days with at least one play, from their full history.",
+		Annotations: &ToolAnnotations{Title: "Get viewing streaks", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"type": {
+					Type:        "string",
+					Description: "Count only episodes or only movies (optional)",
+					Enum:        []string{"episodes", "movies"},
+				},
+			},
+		},
+	}, makeGetStreaksHandler(client))
+
+	// monthly_report - a month's recap
+	s.RegisterTool(Tool{
+		Name:        "monthly_report",
+		Description: "Recap a month for the user's journal: plays and watch time, shows started and finished, ratings given and their average, most watched shows and the movies seen. Defaults to last month.",
+		Annotations: &ToolAnnotations{Title: "Monthly report", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"month": {
+					Type:        "string",
+					Description: "Month to report on as YYYY-MM, e.g. 2026-03 (default: last month)",
+				},
+			},
+		},
+	}, makeMonthlyReportHandler(client))
+
+	// get_backlog_estimate - how long the watchlist would take to watch
+	s.RegisterTool(Tool{
+		Name:        "get_backlog_estimate",
+		Description: "Estimate how long it would take to watch everything on the user's watchlist: the remaining aired episodes of each show plus movie runtimes. Give hoursPerWeek to get a finish date at that pace.",
+		Annotations: &ToolAnnotations{Title: "Estimate watchlist backlog", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"hoursPerWeek": {
 					Type:        "number",
-					Description: "Episode number (required for episodes)",
+					Description: "Hours a week the user watches, to estimate a finish date (optional)",
+				},
+				"limit": {
+					Type:        "integer",
+					Description: "How many of the biggest items to list (default: 10)",
+				},
+			},
+		},
+	}, makeGetBacklogEstimateHandler(client))
+
+	// Tools below modify the user's Trakt account, or write files
+	if s.ReadOnly() {
+		return
+	}
+
+	// export_history - write the full watch history to a file
+	s.RegisterTool(Tool{
+		Name:        "export_history",
+		Description: "Export the user's complete watch history to a CSV or JSON file on this machine, for backup or analysis in other tools. Each row has the play's IDs, title, season and episode numbers, and timestamp. The letterboxd format instead writes movie plays and ratings as a CSV Letterboxd can import, flagging rewatches.",
+		Annotations: &ToolAnnotations{Title: "Export watch history", DestructiveHint: boolPtr(true)},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"path": {
+					Type:        "string",
+					Description: "File to write, e.g. ~/trakt-history.csv",
+				},
+				"format": {
+					Type:        "string",
+					Description: "File format: csv, json, or letterboxd for a movie diary Letterboxd can import (default: json if path ends in .json, otherwise csv)",
+					Enum:        []string{"csv", "json", "letterboxd"},
+				},
+				"type": {
+					Type:        "string",
+					Description: "Export only episodes or only movies (optional)",
+					Enum:        []string{"shows", "movies"},
+				},
+				"overwrite": {
+					Type:        "boolean",
+					Description: "Replace the file if it already exists (default: false)",
+				},
+			},
+			Required: []string{"path"},
+		},
+	}, makeExportHistoryHandler(client))
+
+	// Models sometimes retry a write they think failed; the write tools
+	// share a record of recent writes so the retry doesn't log a second
+	// play, whichever tool it goes through
+	guard := newWriteGuard()
+
+	// log_watch - log a watch
+	s.RegisterTool(Tool{
+		Name:        "log_watch",
+		Description: "Log a single episode or movie as watched. Accepts ISO 8601 dates or phrases like \"yesterday\". If no date provided, uses current time.",
+		InputSchema: JSONSchema{
+			Type:       "object",
+			Properties: logWatchProperties(),
+			Required:   []string{"type"},
+		},
+	}, makeLogWatchHandler(client, samplingPicker(s), guard))
+
+	// rate_and_log - log a watch and rate it
+	rateAndLog := logWatchProperties()
+	rateAndLog["rating"] = JSONSchema{
+		Type:        "integer",
+		Description: "Rating from 1 to 10, e.g. 8 for \"8/10\"",
+	}
+	s.RegisterTool(Tool{
+		Name:        "rate_and_log",
+		Description: "Log an episode or movie as watched and rate it in one call, e.g. for \"I watched Dune last night, 8/10\". Takes the same arguments as log_watch plus a rating.",
+		InputSchema: JSONSchema{
+			Type:       "object",
+			Properties: rateAndLog,
+			Required:   []string{"type", "rating"},
+		},
+	}, makeRateAndLogHandler(client, samplingPicker(s), guard))
+
+	// undo_last_watch - remove the latest history entry
+	s.RegisterTool(Tool{
+		Name:        "undo_last_watch",
+		Description: "Remove the most recent entry from the watch history, e.g. after logging the wrong episode. Shows exactly what was removed.",
+		Annotations: &ToolAnnotations{Title: "Undo last watch"},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"force": {
+					Type:        "boolean",
+					Description: "Remove the latest entry even though an entry was undone moments ago, to undo several watches in a row",
+				},
+				"confirm": {
+					Type:        "string",
+					Description: "Confirmation token from an earlier call's preview, when the server asks to confirm removals. Only confirms the entry that was previewed",
 				},
-				"movieName": {
+			},
+		},
+	}, makeUndoLastWatchHandler(s, client, guard))
+
+	// import_history - bring in history and ratings from another service
+	s.RegisterTool(Tool{
+		Name:        "import_history",
+		Description: "Import watch history and ratings from another tracking service's export file on this machine, for users moving to Trakt. Shows and movies are matched by their TMDB, TVDB and IMDb IDs, plays keep their watch dates, and plays already in the history are skipped, so an import can be repeated. Reports what couldn't be matched. With dryRun, only report what would be imported.",
+		Annotations: &ToolAnnotations{Title: "Import history", IdempotentHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"source": {
 					Type:        "string",
-					Description: "Movie name (required for movies)",
+					Description: "Service the export is from: simkl for a Simkl backup (the JSON of all items), or tvtime for the tracking-prod-records CSV of a TV Time data export",
+					Enum:        importer.Sources(),
 				},
-				"watchedAt": {
+				"path": {
 					Type:        "string",
-					Description: "When it was watched. ISO 8601 format",
+					Description: "Export file to read, e.g. ~/Downloads/simkl-backup.json or ~/Downloads/tracking-prod-records.csv",
+				},
+				"dryRun": {
+					Type:        "boolean",
+					Description: "Only report what would be imported, changing nothing (default: false)",
 				},
 			},
-			Required: []string{"type"},
+			Required: []string{"source", "path"},
+		},
+	}, makeImportHistoryHandler(client))
+}
+
+// logWatchProperties returns the schema of the log_watch arguments. Each
+// call returns a new map, so a tool can add its own.
+func logWatchProperties() map[string]JSONSchema {
+	return map[string]JSONSchema{
+		"type": {
+			Type:        "string",
+			Description: "Content type",
+			Enum:        []string{"episode", "movie"},
+		},
+		"showName": {
+			Type:        "string",
+			Description: "Show name (required for episodes unless an ID is given)",
+		},
+		"season": {
+			Type:        "integer",
+			Description: "Season number (required for episodes unless absoluteEpisode is given)",
+		},
+		"episode": {
+			Type:        "integer",
+			Description: "Episode number (required for episodes unless absoluteEpisode is given)",
+		},
+		"absoluteEpisode": {
+			Type:        "integer",
+			Description: "Absolute episode number counted across seasons, e.g. One Piece episode 1071, instead of season and episode. Common for anime",
+		},
+		"movieName": {
+			Type:        "string",
+			Description: "Movie name (required for movies unless an ID is given)",
+		},
+		"traktId": {
+			Type:        "integer",
+			Description: "Trakt ID of the movie, or of the show for episodes, e.g. from a disambiguation list. Skips the name search",
+		},
+		"imdbId": {
+			Type:        "string",
+			Description: "IMDb ID of the movie or show, e.g. tt0903747. Skips the name search",
+		},
+		"tmdbId": {
+			Type:        "integer",
+			Description: "TMDB ID of the movie or show. Skips the name search",
 		},
-	}, makeLogWatchHandler(client))
+		"force": {
+			Type:        "boolean",
+			Description: "Log the watch even if the item was already logged the same day, for a genuine rewatch",
+		},
+		"watchedAt": {
+			Type:        "string",
+			Description: "When it was watched: ISO 8601 (e.g. 2024-03-10T20:00:00Z or 2024-03-10, local time without a zone), or \"yesterday\", \"last night\", \"3 hours ago\". Not in the future or before the release.",
+		},
+	}
+}
+
+// Output formats for the query tools. Text is prose for the model to relay;
+// JSON is the raw Trakt data for automations that would otherwise have to
+// parse the prose.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// formatProperty is the schema of the format argument shared by the query tools.
+var formatProperty = JSONSchema{
+	Type:        "string",
+	Description: "Output format: text (default) for a readable summary, or json for the raw data",
+	Enum:        []string{formatText, formatJSON},
+}
+
+// jsonResult returns v as pretty-printed JSON.
+func jsonResult(v any) ToolCallResult {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ErrorContent(context.Background(), fmt.Errorf("encode result: %w", err))
+	}
+	return ToolCallResult{Content: []Content{TextContent(string(data))}}
+}
+
+// orEmpty returns an empty slice for nil, so it encodes as [] rather than null.
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// errorMessage adds advice the model can act on to common Trakt API failures.
+func errorMessage(ctx context.Context, err error) string {
+	var apiErr *trakt.APIError
+	errors.As(err, &apiErr)
+
+	switch {
+	case errors.Is(err, trakt.ErrUnauthorized):
+		return msg(ctx, msgCredentialsRejected, err)
+	case errors.Is(err, trakt.ErrRateLimited) && apiErr.RetryAfter > 0:
+		return msg(ctx, msgRateLimitedWait, apiErr.RetryAfter.Round(time.Second), err)
+	case errors.Is(err, trakt.ErrRateLimited):
+		return msg(ctx, msgRateLimited, err)
+	case errors.Is(err, trakt.ErrNotFound):
+		return msg(ctx, msgNotFoundOnTrakt, err)
+	case apiErr != nil && apiErr.UpgradeURL != "":
+		return msg(ctx, msgAccountLimit, apiErr.UpgradeURL, err)
+	default:
+		return err.Error()
+	}
 }
 
 // Handler factories
 
-func makeAuthenticateHandler(client *trakt.Client) ToolHandler {
+func makeAuthenticateHandler(s *Server, client TraktAPI, pending *pendingAuth) ToolHandler {
+	type authenticateArgs struct {
+		Method       string `json:"method"`
+		AutoComplete *bool  `json:"autoComplete"`
+	}
+
 	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
 		if !client.IsConfigured() {
 			return ToolCallResult{
-				Content: []Content{TextContent("Error: TRAKT_CLIENT_ID and TRAKT_CLIENT_SECRET environment variables must be set")},
+				Content: []Content{TextContent(msg(ctx, msgCredentialsMissing))},
 				IsError: true,
 			}, nil
 		}
 
-		code, err := client.GetDeviceCode(ctx)
-		if err != nil {
-			return ErrorContent(err), nil
+		var a authenticateArgs
+		if len(args) > 0 {
+			if err := json.Unmarshal(args, &a); err != nil {
+				return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+			}
 		}
 
-		msg := fmt.Sprintf(`🔐 **Trakt Authentication**
+		if a.Method == "browser" {
+			authURL, err := startBrowserAuth(context.WithoutCancel(ctx), s, client, progressToken(ctx))
+			if err != nil {
+				return ErrorContent(ctx, err), nil
+			}
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgAuthBrowser, authURL))},
+			}, nil
+		}
 
-Please visit: %s
-Enter code: **%s**
+		code, err := client.GetDeviceCode(ctx)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
 
-The code expires in %d seconds.
+		pending.set(code)
 
-After authorizing, the access token will be displayed. Set it as TRAKT_ACCESS_TOKEN environment variable.`,
-			code.VerificationURL, code.UserCode, code.ExpiresIn)
+		next := msg(ctx, msgAuthManualComplete)
+		if a.AutoComplete == nil || *a.AutoComplete {
+			// The poll outlives this call, so detach it from the request's
+			// cancellation; WaitForDeviceToken stops when the code expires.
+			go completeDeviceAuth(context.WithoutCancel(ctx), s, client, pending, code, progressToken(ctx))
+			next = msg(ctx, msgAuthAutoComplete)
+		}
 
 		return ToolCallResult{
-			Content: []Content{TextContent(msg)},
+			Content: []Content{TextContent(msg(ctx, msgAuthDevice,
+				code.VerificationURL, code.UserCode, code.ExpiresIn, next))},
 		}, nil
 	}
 }
 
-func makeSearchHandler(client *trakt.Client) ToolHandler {
+func makeSearchHandler(s *Server, client TraktAPI) ToolHandler {
 	type searchArgs struct {
-		Query string `json:"query"`
-		Type  string `json:"type"`
+		Query  string `json:"query"`
+		Type   string `json:"type"`
+		Limit  int    `json:"limit"`
+		Page   int    `json:"page"`
+		Format string `json:"format"`
 	}
 
 	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
 		var a searchArgs
 		if err := json.Unmarshal(args, &a); err != nil {
-			return ErrorContent(fmt.Errorf("invalid arguments: %w", err)), nil
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
 		}
 
 		if a.Query == "" {
 			return ToolCallResult{
-				Content: []Content{TextContent("Error: query is required")},
+				Content: []Content{TextContent(msg(ctx, msgRequired, "query"))},
 				IsError: true,
 			}, nil
 		}
 
-		results, err := client.Search(ctx, a.Query, a.Type)
+		if a.Limit <= 0 {
+			a.Limit = defaultSearchLimit
+		}
+		a.Limit = min(a.Limit, maxSearchLimit)
+		if a.Page <= 0 {
+			a.Page = 1
+		}
+
+		results, err := client.Search(ctx, a.Query, a.Type,
+			trakt.WithExtended(trakt.ExtendedFull, trakt.ExtendedImages), trakt.WithPage(a.Page), trakt.WithLimit(a.Limit))
 		if err != nil {
-			return ErrorContent(err), nil
+			return ErrorContent(ctx, err), nil
+		}
+
+		if a.Format == formatJSON {
+			return jsonResult(orEmpty(results)), nil
 		}
 
 		if len(results) == 0 {
 			return ToolCallResult{
-				Content: []Content{TextContent(fmt.Sprintf("No results found for: %s", a.Query))},
+				Content: []Content{TextContent(msg(ctx, msgNoResults, a.Query))},
 			}, nil
 		}
 
-		// Format results
+		if text, ok := s.render("search_show", searchTemplateData{Query: a.Query, Page: a.Page, Limit: a.Limit, Results: results}); ok {
+			return ToolCallResult{Content: append([]Content{TextContent(text)}, posterLinks(results)...)}, nil
+		}
+
 		var output string
-		for i, r := range results {
-			if i >= 10 {
-				output += fmt.Sprintf("\n... and %d more results", len(results)-10)
-				break
-			}
+		for _, r := range results {
+			output += formatSearchResult(r)
+		}
 
-			switch r.Type {
-			case "show":
-				if r.Show != nil {
-					output += fmt.Sprintf("📺 **%s** (%d) - Trakt ID: %d\n",
-						r.Show.Title, r.Show.Year, r.Show.IDs.Trakt)
-				}
-			case "movie":
-				if r.Movie != nil {
-					output += fmt.Sprintf("🎬 **%s** (%d) - Trakt ID: %d\n",
-						r.Movie.Title, r.Movie.Year, r.Movie.IDs.Trakt)
-				}
-			}
+		// A full page means there may be more; say so rather than truncating silently
+		if len(results) == a.Limit {
+			output += "\n" + msg(ctx, msgMoreResults, len(results), a.Page, a.Page+1) + "\n"
 		}
 
 		return ToolCallResult{
-			Content: []Content{TextContent(output)},
+			Content: append([]Content{TextContent(output)}, posterLinks(results)...),
 		}, nil
 	}
 }
 
-func makeGetHistoryHandler(client *trakt.Client) ToolHandler {
+// posterLinks returns a resource link to each result's poster, logo and
+// background, so clients that render images can show the artwork inline.
+// The text output keeps the URLs for clients that don't.
+func posterLinks(results []trakt.SearchResult) []Content {
+	var links []Content
+	for _, r := range results {
+		var title string
+		var images *trakt.Images
+		switch {
+		case r.Type == "show" && r.Show != nil:
+			title, images = r.Show.Title, r.Show.Images
+		case r.Type == "movie" && r.Movie != nil:
+			title, images = r.Movie.Title, r.Movie.Images
+		default:
+			continue
+		}
+		for _, art := range []struct{ url, name string }{
+			{images.PosterURL(), " poster"},
+			{images.LogoURL(), " logo"},
+			{images.FanartURL(), " background"},
+		} {
+			if art.url != "" {
+				links = append(links, ResourceLinkContent(art.url, title+art.name, imageMimeType(art.url)))
+			}
+		}
+	}
+	return links
+}
+
+// imageMimeType guesses an image's type from its URL. Trakt serves its
+// artwork as WebP, named like "abc.jpg.webp".
+func imageMimeType(url string) string {
+	switch path.Ext(url) {
+	case ".webp":
+		return "image/webp"
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	default:
+		return ""
+	}
+}
+
+// overviewSnippetLength caps the overview shown for each search result.
+const overviewSnippetLength = 160
+
+// formatSearchResult renders one search result with whatever extended info
+// Trakt returned, so the user can pick the right item without looking up
+// its details.
+func formatSearchResult(r trakt.SearchResult) string {
+	var (
+		icon, kind, title, tagline, overview, url, poster, logo, backdrop string
+		year, id, runtime                                                 int
+		genres                                                            []string
+	)
+	switch {
+	case r.Type == "show" && r.Show != nil:
+		icon, kind = "📺", "Show"
+		title, year, id = r.Show.Title, r.Show.Year, r.Show.IDs.Trakt
+		overview, genres, url, poster = r.Show.Overview, r.Show.Genres, r.Show.URL(), r.Show.Images.PosterURL()
+		runtime, logo, backdrop = r.Show.Runtime, r.Show.Images.LogoURL(), r.Show.Images.FanartURL()
+	case r.Type == "movie" && r.Movie != nil:
+		icon, kind = "🎬", "Movie"
+		title, year, id = r.Movie.Title, r.Movie.Year, r.Movie.IDs.Trakt
+		overview, genres, url, poster = r.Movie.Overview, r.Movie.Genres, r.Movie.URL(), r.Movie.Images.PosterURL()
+		runtime, logo, backdrop = r.Movie.Runtime, r.Movie.Images.LogoURL(), r.Movie.Images.FanartURL()
+		tagline = r.Movie.Tagline
+	case r.Type == "episode" && r.Episode != nil && r.Show != nil:
+		return formatEpisodeResult(r.Show, r.Episode)
+	case r.Type == "person" && r.Person != nil:
+		return formatPersonResult(r.Person)
+	default:
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s **%s**", icon, title))
+	if year > 0 {
+		sb.WriteString(fmt.Sprintf(" (%d)", year))
+	}
+	sb.WriteString(fmt.Sprintf(" - %s - Trakt ID: %d\n", kind, id))
+
+	var details []string
+	if len(genres) > 0 {
+		details = append(details, strings.Join(genres, ", "))
+	}
+	if runtime > 0 {
+		if kind == "Show" {
+			details = append(details, fmt.Sprintf("%d min episodes", runtime))
+		} else {
+			details = append(details, fmt.Sprintf("%d min", runtime))
+		}
+	}
+	if len(details) > 0 {
+		sb.WriteString(fmt.Sprintf("   %s\n", strings.Join(details, " · ")))
+	}
+	if tagline != "" {
+		sb.WriteString(fmt.Sprintf("   \"%s\"\n", tagline))
+	}
+	if overview != "" {
+		sb.WriteString(fmt.Sprintf("   %s\n", snippet(overview, overviewSnippetLength)))
+	}
+	if url != "" {
+		sb.WriteString(fmt.Sprintf("   %s\n", url))
+	}
+	if poster != "" {
+		sb.WriteString(fmt.Sprintf("   Poster: %s\n", poster))
+	}
+	if logo != "" {
+		sb.WriteString(fmt.Sprintf("   Logo: %s\n", logo))
+	}
+	if backdrop != "" {
+		sb.WriteString(fmt.Sprintf("   Backdrop: %s\n", backdrop))
+	}
+	return sb.String()
+}
+
+// formatEpisodeResult renders an episode search result under its show, with
+// the IDs log_watch needs to log it.
+func formatEpisodeResult(show *trakt.Show, ep *trakt.Episode) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📺 **%s** S%02dE%02d - %s - Episode\n", show.Title, ep.Season, ep.Number, ep.Title))
+
+	var details []string
+	if ep.FirstAired != nil {
+		details = append(details, "aired "+ep.FirstAired.Local().Format("2006-01-02"))
+	}
+	if ep.Runtime > 0 {
+		details = append(details, fmt.Sprintf("%d min", ep.Runtime))
+	}
+	if len(details) > 0 {
+		sb.WriteString(fmt.Sprintf("   %s\n", strings.Join(details, " · ")))
+	}
+	if ep.Overview != "" {
+		sb.WriteString(fmt.Sprintf("   %s\n", snippet(ep.Overview, overviewSnippetLength)))
+	}
+	sb.WriteString(idLine(
+		idPart("Show ID", int64(show.IDs.Trakt)),
+		slugPart(show.IDs.Slug),
+		idPart("Episode ID", int64(ep.IDs.Trakt)),
+		show.EpisodeURL(ep.Season, ep.Number),
+	))
+	return sb.String()
+}
+
+// formatPersonResult renders a person search result.
+func formatPersonResult(p *trakt.Person) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("👤 **%s** - Person - Trakt ID: %d\n", p.Name, p.IDs.Trakt))
+
+	var details []string
+	if p.KnownForDepartment != "" {
+		details = append(details, "known for "+p.KnownForDepartment)
+	}
+	if p.Birthday != "" {
+		born := "born " + p.Birthday
+		if p.Birthplace != "" {
+			born += " in " + p.Birthplace
+		}
+		details = append(details, born)
+	}
+	if p.Death != "" {
+		details = append(details, "died "+p.Death)
+	}
+	if len(details) > 0 {
+		sb.WriteString(fmt.Sprintf("   %s\n", strings.Join(details, " · ")))
+	}
+	if p.Biography != "" {
+		sb.WriteString(fmt.Sprintf("   %s\n", snippet(p.Biography, overviewSnippetLength)))
+	}
+	if url := p.URL(); url != "" {
+		sb.WriteString(fmt.Sprintf("   %s\n", url))
+	}
+	return sb.String()
+}
+
+// snippet shortens text to at most limit runes, cutting at a word boundary
+// and adding an ellipsis when anything was left out.
+func snippet(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+// Page sizes of get_history.
+const (
+	defaultHistoryLimit = 10
+	maxHistoryLimit     = 100
+)
+
+func makeGetHistoryHandler(s *Server, client TraktAPI) ToolHandler {
 	type historyArgs struct {
-		Type  string `json:"type"`
-		Limit int    `json:"limit"`
+		Type    string `json:"type"`
+		Limit   int    `json:"limit"`
+		Page    int    `json:"page"`
+		Format  string `json:"format"`
+		GroupBy string `json:"groupBy,omitempty"`
+		Cursor  string `json:"cursor,omitempty"`
+
+		// Used with groupBy session only
+		Days       int `json:"days,omitempty"`
+		SessionGap int `json:"sessionGap,omitempty"`
 	}
 
 	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
 		if !client.IsAuthenticated() {
 			return ToolCallResult{
-				Content: []Content{TextContent("Error: Not authenticated. Use the authenticate tool first.")},
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
 				IsError: true,
 			}, nil
 		}
 
 		var a historyArgs
 		if err := json.Unmarshal(args, &a); err != nil {
-			return ErrorContent(fmt.Errorf("invalid arguments: %w", err)), nil
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+
+		// A cursor resumes an earlier call that didn't fit in one response
+		offset := 0
+		if cursor := a.Cursor; cursor != "" {
+			a = historyArgs{}
+			var err error
+			if offset, err = decodeCursor("get_history", cursor, &a); err != nil {
+				return ToolCallResult{
+					Content: []Content{TextContent(msg(ctx, msgError, err))},
+					IsError: true,
+				}, nil
+			}
 		}
 
 		if a.Limit <= 0 {
-			a.Limit = 10
+			a.Limit = defaultHistoryLimit
+		}
+		a.Limit = min(a.Limit, maxHistoryLimit)
+		if a.Page <= 0 {
+			a.Page = 1
 		}
 
-		history, err := client.GetHistory(ctx, a.Type, a.Limit)
+		if a.GroupBy == groupBySession {
+			return historySessions(ctx, client, a.Type, a.Days, a.SessionGap), nil
+		}
+
+		page, err := client.GetHistoryPage(ctx, a.Type, a.Page, a.Limit)
 		if err != nil {
-			return ErrorContent(err), nil
+			return ErrorContent(ctx, err), nil
+		}
+		history := page.Items[min(offset, len(page.Items)):]
+
+		// Every output is cut at an item boundary to fit the response
+		// budget, ending with a cursor that continues it
+		continuation := func(n int) string {
+			return msg(ctx, msgHistoryContinues, offset+1, offset+n, len(page.Items), a.Page, encodeCursor("get_history", a, offset+n)) + "\n"
+		}
+
+		if a.Format == formatJSON {
+			items := make([]string, len(history))
+			for i, h := range history {
+				data, _ := json.MarshalIndent(h, "  ", "  ")
+				items[i] = string(data)
+			}
+			n := fitBudget(items, s.ResponseBudget())
+			result := jsonResult(orEmpty(history[:n]))
+			if n < len(history) {
+				result.Content = append(result.Content, TextContent(continuation(n)))
+			}
+			return result, nil
 		}
 
 		if len(history) == 0 {
 			return ToolCallResult{
-				Content: []Content{TextContent("No watch history found.")},
+				Content: []Content{TextContent(msg(ctx, msgNoHistory))},
 			}, nil
 		}
 
-		var output string
-		for _, h := range history {
-			switch h.Type {
-			case "episode":
-				if h.Show != nil && h.Episode != nil {
-					output += fmt.Sprintf("📺 %s S%02dE%02d - %s (%s)\n",
-						h.Show.Title, h.Episode.Season, h.Episode.Number,
-						h.Episode.Title, h.WatchedAt.Format("2006-01-02"))
-				}
-			case "movie":
-				if h.Movie != nil {
-					output += fmt.Sprintf("🎬 %s (%s)\n",
-						h.Movie.Title, h.WatchedAt.Format("2006-01-02"))
+		render := func(n int) (string, bool) {
+			return s.render("get_history", historyTemplateData{Items: history[:n], Pagination: page.Pagination})
+		}
+		if text, ok := render(len(history)); ok {
+			n := fitRendered(len(history), s.ResponseBudget(), func(n int) int {
+				if n == len(history) {
+					return len(text)
 				}
+				text, _ := render(n)
+				return len(text)
+			})
+			if n < len(history) {
+				text, _ = render(n)
+				text += "\n" + continuation(n)
 			}
+			return ToolCallResult{Content: []Content{TextContent(text)}}, nil
+		}
+
+		now := time.Now()
+
+		// Grouped output is a line per show, short enough not to need the
+		// response budget
+		if a.GroupBy == groupByShow {
+			text := formatHistoryGroups(history, now) + "\n" + historyFooter(len(history), page.Pagination)
+			return ToolCallResult{Content: []Content{TextContent(text)}}, nil
+		}
+
+		items := make([]string, len(history))
+		for i, h := range history {
+			items[i] = formatHistoryItem(h, now)
 		}
 
+		// Cut a result too long for the response budget at an item boundary
+		n := fitBudget(items, s.ResponseBudget())
+		text := strings.Join(items[:n], "") + "\n"
+		if n < len(items) {
+			text += continuation(n)
+		} else {
+			text += historyFooter(len(history), page.Pagination)
+		}
 		return ToolCallResult{
-			Content: []Content{TextContent(output)},
+			Content: []Content{TextContent(text)},
 		}, nil
 	}
 }
 
-func makeLogWatchHandler(client *trakt.Client) ToolHandler {
-	type logWatchArgs struct {
-		Type      string `json:"type"`
-		ShowName  string `json:"showName"`
-		Season    int    `json:"season"`
-		Episode   int    `json:"episode"`
-		MovieName string `json:"movieName"`
-		WatchedAt string `json:"watchedAt"`
+// historyFooter says how much of the history a page covers, and how to get
+// the next page if there is one, so truncation is never silent.
+func historyFooter(shown int, p trakt.Pagination) string {
+	footer := fmt.Sprintf("Showing %s of %s items (page %d of %s)",
+		formatCount(shown), formatCount(p.ItemCount), p.Page, formatCount(max(p.PageCount, 1)))
+	if p.Page < p.PageCount {
+		return footer + fmt.Sprintf(". Ask for page %d to continue.\n", p.Page+1)
+	}
+	return footer + ".\n"
+}
+
+// formatCount renders n with thousands separators, e.g. 1,482.
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// formatHistory renders watch history items one per line, with each watch
+// date also given relative to now.
+func formatHistory(history []trakt.HistoryItem, now time.Time) string {
+	var sb strings.Builder
+	for _, h := range history {
+		sb.WriteString(formatHistoryItem(h, now))
+	}
+	return sb.String()
+}
+
+// formatHistoryItem renders one watch history item and its IDs.
+func formatHistoryItem(h trakt.HistoryItem, now time.Time) string {
+	var sb strings.Builder
+	switch h.Type {
+	case "episode":
+		if h.Show != nil && h.Episode != nil {
+			sb.WriteString(fmt.Sprintf("📺 %s S%02dE%02d - %s (%s)\n",
+				h.Show.Title, h.Episode.Season, h.Episode.Number,
+				h.Episode.Title, watchedDate(h.WatchedAt, now)))
+			sb.WriteString(idLine(
+				idPart("Show ID", int64(h.Show.IDs.Trakt)),
+				slugPart(h.Show.IDs.Slug),
+				idPart("Episode ID", int64(h.Episode.IDs.Trakt)),
+				idPart("History ID", h.ID),
+				h.Show.EpisodeURL(h.Episode.Season, h.Episode.Number),
+			))
+		}
+	case "movie":
+		if h.Movie != nil {
+			sb.WriteString(fmt.Sprintf("🎬 %s (%s)\n",
+				h.Movie.Title, watchedDate(h.WatchedAt, now)))
+			sb.WriteString(idLine(
+				idPart("Trakt ID", int64(h.Movie.IDs.Trakt)),
+				slugPart(h.Movie.IDs.Slug),
+				idPart("History ID", h.ID),
+				h.Movie.URL(),
+			))
+		}
+	}
+	return sb.String()
+}
+
+// idLine renders the identifiers follow-up tool calls can use to refer to
+// an item, indented under it and separated by dots. Empty parts are skipped.
+func idLine(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return "   " + strings.Join(kept, " · ") + "\n"
+}
+
+// idPart labels an ID for idLine, or returns "" for a missing one.
+func idPart(label string, id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s: %d", label, id)
+}
+
+// slugPart labels a slug for idLine, or returns "" for a missing one.
+func slugPart(slug string) string {
+	if slug == "" {
+		return ""
+	}
+	return "Slug: " + slug
+}
+
+// watchedDate renders a watch time as its local date followed by how long
+// ago that was, e.g. "2024-03-12, yesterday".
+func watchedDate(t, now time.Time) string {
+	return t.Local().Format("2006-01-02") + ", " + relativeDate(t, now)
+}
+
+// relativeDate describes the calendar day of t relative to now in
+// conversational terms: "today", "yesterday", "Tuesday, 3 days ago",
+// "2 weeks ago" and so on. Dates after today, usually a logging mistake,
+// are called out as "in the future".
+func relativeDate(t, now time.Time) string {
+	day := func(t time.Time) time.Time {
+		y, m, d := t.Local().Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
 	}
+	// Round rather than truncate so a DST change doesn't shift the count
+	days := int(math.Round(day(now).Sub(day(t)).Hours() / 24))
 
+	switch {
+	case days < 0:
+		return "in the future"
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case days < 7:
+		return fmt.Sprintf("%s, %d days ago", t.Local().Weekday(), days)
+	case days < 14:
+		return "last week"
+	case days < 30:
+		return fmt.Sprintf("%d weeks ago", days/7)
+	case days < 60:
+		return "last month"
+	case days < 365:
+		return fmt.Sprintf("%d months ago", days/30)
+	case days < 730:
+		return "last year"
+	default:
+		return fmt.Sprintf("%d years ago", days/365)
+	}
+}
+
+// logWatchArgs are the arguments of log_watch, which rate_and_log shares.
+type logWatchArgs struct {
+	Type      string `json:"type"`
+	ShowName  string `json:"showName"`
+	Season    int    `json:"season"`
+	Episode   int    `json:"episode"`
+	Absolute  int    `json:"absoluteEpisode"`
+	MovieName string `json:"movieName"`
+	TraktID   int    `json:"traktId"`
+	IMDBID    string `json:"imdbId"`
+	TMDBID    int    `json:"tmdbId"`
+	WatchedAt string `json:"watchedAt"`
+	Force     bool   `json:"force"`
+}
+
+func makeLogWatchHandler(client TraktAPI, pick matchPicker, guard *writeGuard) ToolHandler {
 	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
 		if !client.IsAuthenticated() {
 			return ToolCallResult{
-				Content: []Content{TextContent("Error: Not authenticated. Use the authenticate tool first.")},
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
 				IsError: true,
 			}, nil
 		}
 
 		var a logWatchArgs
 		if err := json.Unmarshal(args, &a); err != nil {
-			return ErrorContent(fmt.Errorf("invalid arguments: %w", err)), nil
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
 		}
 
-		switch a.Type {
-		case "episode":
-			return logEpisode(ctx, client, a.ShowName, a.Season, a.Episode, a.WatchedAt)
-		case "movie":
-			return logMovie(ctx, client, a.MovieName, a.WatchedAt)
-		default:
-			return ToolCallResult{
-				Content: []Content{TextContent("Error: type must be 'episode' or 'movie'")},
-				IsError: true,
-			}, nil
-		}
+		result, _ := logWatch(ctx, client, pick, guard, a)
+		return result, nil
+	}
+}
+
+// logWatch logs the episode or movie the arguments describe.
+func logWatch(ctx context.Context, client TraktAPI, pick matchPicker, guard *writeGuard, a logWatchArgs) (ToolCallResult, loggedItem) {
+	watchedAt, err := parseWatchedAt(a.WatchedAt, time.Now())
+	if err != nil {
+		return ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, msgError, err))},
+			IsError: true,
+		}, loggedItem{}
+	}
+
+	// An ID skips the search; for episodes it identifies the show
+	ref := itemRef{Force: a.Force}
+	switch {
+	case a.TraktID > 0:
+		ref.IDType, ref.ID = "trakt", strconv.Itoa(a.TraktID)
+	case a.IMDBID != "":
+		ref.IDType, ref.ID = "imdb", a.IMDBID
+	case a.TMDBID > 0:
+		ref.IDType, ref.ID = "tmdb", strconv.Itoa(a.TMDBID)
+	}
+
+	switch a.Type {
+	case "episode":
+		ref.Name = a.ShowName
+		return logEpisode(ctx, client, pick, guard, ref, a.Season, a.Episode, a.Absolute, watchedAt)
+	case "movie":
+		ref.Name = a.MovieName
+		return logMovie(ctx, client, pick, guard, ref, watchedAt)
+	default:
+		return ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, msgWatchType))},
+			IsError: true,
+		}, loggedItem{}
 	}
 }
 
@@ -293,7 +1247,7 @@ func makeLogWatchHandler(client *trakt.Client) ToolHandler {
 // for user disambiguation. Uses strings.Builder for efficient string concatenation.
 func formatDisambiguationMessage(contentType string, query string, results []trakt.SearchResult) string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Multiple %ss found for '%s'. Please be more specific or use the year:\n", contentType, query))
+	sb.WriteString(fmt.Sprintf("Multiple %ss found for '%s'. Please be more specific, use the year, or log it again with its traktId:\n", contentType, query))
 
 	for i, r := range results {
 		if i >= 5 {
@@ -314,59 +1268,218 @@ func formatDisambiguationMessage(contentType string, query string, results []tra
 	return sb.String()
 }
 
-// logEpisode searches for a show by name, verifies the episode exists,
-// and logs it to watch history. Returns disambiguation prompt if multiple shows match.
-func logEpisode(ctx context.Context, client *trakt.Client, showName string, season, episode int, watchedAt string) (ToolCallResult, error) {
-	if showName == "" {
-		return ToolCallResult{
-			Content: []Content{TextContent("Error: showName is required for episodes")},
-			IsError: true,
-		}, nil
+// matchPicker chooses among ambiguous search results. It returns nil when it
+// can't decide, in which case the user is asked to disambiguate.
+type matchPicker func(ctx context.Context, contentType, query string, results []trakt.SearchResult) *trakt.SearchResult
+
+// samplingPicker asks the client's LLM, which can see the conversation, which
+// candidate the user meant (e.g. "the 2018 one we discussed"). It declines to
+// pick when the client doesn't support sampling.
+func samplingPicker(s *Server) matchPicker {
+	return func(ctx context.Context, contentType, query string, results []trakt.SearchResult) *trakt.SearchResult {
+		ctx, cancel := context.WithTimeout(ctx, samplingTimeout)
+		defer cancel()
+
+		prompt := formatDisambiguationMessage(contentType, query, results) +
+			"\nWhich one did the user mean?"
+		result, err := s.CreateMessage(ctx, CreateMessageParams{
+			Messages: []SamplingMessage{{Role: "user", Content: TextContent(prompt)}},
+			SystemPrompt: "You match a user's request to a Trakt catalog entry using the conversation so far. " +
+				"Reply with only the Trakt ID of the intended entry, or \"none\" if it isn't clear.",
+			IncludeContext: "thisServer",
+			MaxTokens:      20,
+		})
+		if err != nil {
+			if !errors.Is(err, ErrSamplingUnsupported) {
+				s.logger.Warn("sampling failed, falling back to disambiguation", "error", err)
+			}
+			return nil
+		}
+
+		return pickByID(result.Content.Text, results)
 	}
-	// Season 0 is valid (specials), but episode must be positive
-	if season < 0 || episode <= 0 {
-		return ToolCallResult{
-			Content: []Content{TextContent("Error: season must be >= 0 and episode must be positive")},
-			IsError: true,
-		}, nil
+}
+
+// pickByID returns the result whose Trakt ID appears in reply. Other numbers,
+// such as a year echoed from the prompt, are skipped; nil means no candidate,
+// or more than one, was named.
+func pickByID(reply string, results []trakt.SearchResult) *trakt.SearchResult {
+	var picked *trakt.SearchResult
+	for _, match := range traktIDPattern.FindAllString(reply, -1) {
+		id, err := strconv.Atoi(match)
+		if err != nil {
+			continue
+		}
+		for i, r := range results {
+			if (r.Show != nil && r.Show.IDs.Trakt == id) || (r.Movie != nil && r.Movie.IDs.Trakt == id) {
+				if picked != nil && picked != &results[i] {
+					return nil
+				}
+				picked = &results[i]
+			}
+		}
+	}
+	return picked
+}
+
+// itemRef names the show or movie a write refers to: by ID when the caller
+// has one, otherwise by a name to search for.
+type itemRef struct {
+	Name   string
+	IDType string // "trakt", "imdb" or "tmdb"; empty to search by Name
+	ID     string
+	Force  bool // log even if the item was already logged that day
+}
+
+// idTypeLabels are the display names of the ID types in itemRef.
+var idTypeLabels = map[string]string{"trakt": "Trakt", "imdb": "IMDb", "tmdb": "TMDB"}
+
+// findItem resolves ref to a show or movie, as contentType says. An ID is
+// looked up directly. A name is searched for; if several items match, pick
+// may choose one, and otherwise the returned result asks the user to
+// disambiguate. A non-nil result means the item wasn't found and should be
+// returned as is.
+func findItem(ctx context.Context, client TraktAPI, pick matchPicker, contentType string, ref itemRef) (*trakt.SearchResult, *ToolCallResult) {
+	found := func(r trakt.SearchResult) bool {
+		return (contentType == "show" && r.Show != nil) || (contentType == "movie" && r.Movie != nil)
+	}
+	notFound := func(text string) *ToolCallResult {
+		return &ToolCallResult{Content: []Content{TextContent(text)}, IsError: true}
+	}
+
+	if ref.ID != "" {
+		results, err := client.LookupID(ctx, ref.IDType, ref.ID, contentType, trakt.WithExtended(trakt.ExtendedFull))
+		if err != nil {
+			result := ErrorContent(ctx, err)
+			return nil, &result
+		}
+		for i, r := range results {
+			if found(r) {
+				return &results[i], nil
+			}
+		}
+		key := msgNoShowWithID
+		if contentType == "movie" {
+			key = msgNoMovieWithID
+		}
+		return nil, notFound(msg(ctx, key, idTypeLabels[ref.IDType], ref.ID))
 	}
 
-	// Search for the show
-	results, err := client.Search(ctx, showName, "show")
+	results, err := client.Search(ctx, ref.Name, contentType, trakt.WithExtended(trakt.ExtendedFull))
 	if err != nil {
-		return ErrorContent(err), nil
+		result := ErrorContent(ctx, err)
+		return nil, &result
 	}
-	if len(results) == 0 || results[0].Show == nil {
-		return ToolCallResult{
-			Content: []Content{TextContent(fmt.Sprintf("No show found for: %s", showName))},
-			IsError: true,
-		}, nil
+	if len(results) == 0 || !found(results[0]) {
+		key := msgNoShowFound
+		if contentType == "movie" {
+			key = msgNoMovieFound
+		}
+		return nil, notFound(msg(ctx, key, ref.Name))
 	}
 
 	// Check for ambiguous results - require exact match or single result
 	if len(results) > 1 && results[0].Score < exactMatchScoreThreshold {
-		msg := formatDisambiguationMessage("show", showName, results)
+		if matched := matchAlias(ctx, client, contentType, ref.Name, results); matched != nil {
+			return matched, nil
+		}
+		picked := pick(ctx, contentType, ref.Name, results)
+		if picked == nil || !found(*picked) {
+			return nil, notFound(formatDisambiguationMessage(contentType, ref.Name, results))
+		}
+		return picked, nil
+	}
+	return &results[0], nil
+}
+
+// loggedItem is the episode or movie a log_watch call resolved to, whether
+// or not it was newly logged, so follow-up writes such as a rating know
+// what to apply to. Both are nil when the call failed.
+type loggedItem struct {
+	episode *trakt.Episode
+	movie   *trakt.Movie
+}
+
+// logEpisode finds a show by ID or name, verifies the episode exists and
+// had aired by watchedAt, and logs it to watch history. A positive absolute
+// episode number is mapped to its season and episode instead of using
+// season and episode. A zero watchedAt logs the episode as watched now.
+func logEpisode(ctx context.Context, client TraktAPI, pick matchPicker, guard *writeGuard, ref itemRef, season, episode, absolute int, watchedAt time.Time) (ToolCallResult, loggedItem) {
+	if ref.Name == "" && ref.ID == "" {
 		return ToolCallResult{
-			Content: []Content{TextContent(msg)},
+			Content: []Content{TextContent(msg(ctx, msgShowRequired))},
 			IsError: true,
-		}, nil
+		}, loggedItem{}
+	}
+	// Season 0 is valid (specials), but episode must be positive
+	if absolute < 0 || (absolute == 0 && (season < 0 || episode <= 0)) {
+		return ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, msgEpisodeNumbers))},
+			IsError: true,
+		}, loggedItem{}
 	}
 
-	show := results[0].Show
+	match, failure := findItem(ctx, client, pick, "show", ref)
+	if failure != nil {
+		return *failure, loggedItem{}
+	}
+	show := match.Show
+
+	if absolute > 0 {
+		seasons, err := client.GetSeasons(ctx, fmt.Sprint(show.IDs.Trakt), trakt.WithExtended(trakt.ExtendedFull, trakt.ExtendedEpisodes))
+		if err != nil {
+			return ErrorContent(ctx, err), loggedItem{}
+		}
+		ep, ok := findAbsoluteEpisode(seasons, absolute)
+		if !ok {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgAbsoluteNotFound, absolute, show.Title))},
+				IsError: true,
+			}, loggedItem{}
+		}
+		season, episode = ep.Season, ep.Number
+	}
 
 	// Get the episode to verify it exists and get its ID
-	ep, err := client.GetEpisode(ctx, fmt.Sprintf("%d", show.IDs.Trakt), season, episode)
+	ep, err := client.GetEpisode(ctx, fmt.Sprintf("%d", show.IDs.Trakt), season, episode, trakt.WithExtended(trakt.ExtendedFull))
 	if err != nil {
 		// User-friendly message (don't expose internal error details)
 		return ToolCallResult{
-			Content: []Content{TextContent(fmt.Sprintf("Episode S%02dE%02d not found for %s. Please verify the season and episode numbers.", season, episode, show.Title))},
+			Content: []Content{TextContent(msg(ctx, msgEpisodeNotFound, season, episode, show.Title))},
 			IsError: true,
-		}, nil
+		}, loggedItem{}
+	}
+
+	if err := checkReleased(watchedAt, ep.FirstAired, fmt.Sprintf("%s S%02dE%02d", show.Title, season, episode)); err != nil {
+		return ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, msgError, err))},
+			IsError: true,
+		}, loggedItem{}
+	}
+
+	logged := loggedItem{episode: ep}
+	key := newWriteKey("episode", fmt.Sprint(ep.IDs.Trakt), formatWatchedAt(watchedAt))
+	prev, repeated, err := guard.claim(ctx, key)
+	if err != nil {
+		return ErrorContent(ctx, err), loggedItem{}
+	}
+	if repeated {
+		return repeatedWrite(prev, msg(ctx, msgRepeatedWrite)), logged
+	}
+	defer guard.release(key)
+
+	if !ref.Force {
+		if play, ok := sameDayPlay(ctx, client, "episodes", ep.IDs.Trakt, watchedAt); ok {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgDuplicateEpisode,
+					show.Title, season, episode, play.Local().Format("2006-01-02"), play.Local().Format("15:04")))},
+			}, logged
+		}
 	}
 
 	// Sync to history
 	item := trakt.WatchedItem{
-		WatchedAt: watchedAt,
+		WatchedAt: formatWatchedAt(watchedAt),
 		Episodes: []trakt.Episode{
 			{
 				IDs: trakt.EpisodeIDs{Trakt: ep.IDs.Trakt},
@@ -375,65 +1488,86 @@ func logEpisode(ctx context.Context, client *trakt.Client, showName string, seas
 	}
 
 	resp, err := client.AddToHistory(ctx, item)
+	if errors.Is(err, errWriteQueued) {
+		result := ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, msgWriteQueued,
+				fmt.Sprintf("%s S%02dE%02d", show.Title, season, episode)))},
+		}
+		guard.remember(key, result)
+		return result, logged
+	}
 	if err != nil {
-		return ErrorContent(err), nil
+		return ErrorContent(ctx, err), loggedItem{}
 	}
 
 	if resp.Added.Episodes > 0 {
-		return ToolCallResult{
-			Content: []Content{TextContent(fmt.Sprintf("✅ Logged: **%s** S%02dE%02d - %s",
+		result := ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, msgLoggedEpisode,
 				show.Title, season, episode, ep.Title))},
-		}, nil
+		}
+		guard.remember(key, result)
+		return result, logged
 	}
 
 	if resp.Existing.Episodes > 0 {
 		return ToolCallResult{
-			Content: []Content{TextContent(fmt.Sprintf("ℹ️ Already watched: **%s** S%02dE%02d - %s",
+			Content: []Content{TextContent(msg(ctx, msgAlreadyWatchedEpisode,
 				show.Title, season, episode, ep.Title))},
-		}, nil
+		}, logged
 	}
 
 	return ToolCallResult{
-		Content: []Content{TextContent("⚠️ Episode was not added (unknown reason)")},
-	}, nil
+		Content: []Content{TextContent(msg(ctx, msgEpisodeNotAdded))},
+	}, loggedItem{}
 }
 
-// logMovie searches for a movie by name and logs it to watch history.
-// Returns disambiguation prompt if multiple movies match the query.
-func logMovie(ctx context.Context, client *trakt.Client, movieName string, watchedAt string) (ToolCallResult, error) {
-	if movieName == "" {
+// logMovie finds a movie by ID or name, checks it was released by
+// watchedAt, and logs it to watch history. A zero watchedAt logs the movie
+// as watched now.
+func logMovie(ctx context.Context, client TraktAPI, pick matchPicker, guard *writeGuard, ref itemRef, watchedAt time.Time) (ToolCallResult, loggedItem) {
+	if ref.Name == "" && ref.ID == "" {
 		return ToolCallResult{
-			Content: []Content{TextContent("Error: movieName is required for movies")},
+			Content: []Content{TextContent(msg(ctx, msgMovieRequired))},
 			IsError: true,
-		}, nil
+		}, loggedItem{}
 	}
 
-	// Search for the movie
-	results, err := client.Search(ctx, movieName, "movie")
-	if err != nil {
-		return ErrorContent(err), nil
+	match, failure := findItem(ctx, client, pick, "movie", ref)
+	if failure != nil {
+		return *failure, loggedItem{}
 	}
-	if len(results) == 0 || results[0].Movie == nil {
+	movie := match.Movie
+
+	if err := checkReleased(watchedAt, movieRelease(movie), movie.Title); err != nil {
 		return ToolCallResult{
-			Content: []Content{TextContent(fmt.Sprintf("No movie found for: %s", movieName))},
+			Content: []Content{TextContent(msg(ctx, msgError, err))},
 			IsError: true,
-		}, nil
+		}, loggedItem{}
 	}
 
-	// Check for ambiguous results - require exact match or single result
-	if len(results) > 1 && results[0].Score < exactMatchScoreThreshold {
-		msg := formatDisambiguationMessage("movie", movieName, results)
-		return ToolCallResult{
-			Content: []Content{TextContent(msg)},
-			IsError: true,
-		}, nil
+	logged := loggedItem{movie: movie}
+	key := newWriteKey("movie", fmt.Sprint(movie.IDs.Trakt), formatWatchedAt(watchedAt))
+	prev, repeated, err := guard.claim(ctx, key)
+	if err != nil {
+		return ErrorContent(ctx, err), loggedItem{}
+	}
+	if repeated {
+		return repeatedWrite(prev, msg(ctx, msgRepeatedWrite)), logged
 	}
+	defer guard.release(key)
 
-	movie := results[0].Movie
+	if !ref.Force {
+		if play, ok := sameDayPlay(ctx, client, "movies", movie.IDs.Trakt, watchedAt); ok {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgDuplicateMovie,
+					movie.Title, movie.Year, play.Local().Format("2006-01-02"), play.Local().Format("15:04")))},
+			}, logged
+		}
+	}
 
 	// Sync to history
 	item := trakt.WatchedItem{
-		WatchedAt: watchedAt,
+		WatchedAt: formatWatchedAt(watchedAt),
 		Movies: []trakt.Movie{
 			{
 				IDs: trakt.MovieIDs{Trakt: movie.IDs.Trakt},
@@ -442,25 +1576,66 @@ func logMovie(ctx context.Context, client *trakt.Client, movieName string, watch
 	}
 
 	resp, err := client.AddToHistory(ctx, item)
+	if errors.Is(err, errWriteQueued) {
+		result := ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, msgWriteQueued, movie.Title+yearSuffix(movie.Year)))},
+		}
+		guard.remember(key, result)
+		return result, logged
+	}
 	if err != nil {
-		return ErrorContent(err), nil
+		return ErrorContent(ctx, err), loggedItem{}
 	}
 
 	if resp.Added.Movies > 0 {
-		return ToolCallResult{
-			Content: []Content{TextContent(fmt.Sprintf("✅ Logged: **%s** (%d)",
+		result := ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, msgLoggedMovie,
 				movie.Title, movie.Year))},
-		}, nil
+		}
+		guard.remember(key, result)
+		return result, logged
 	}
 
 	if resp.Existing.Movies > 0 {
 		return ToolCallResult{
-			Content: []Content{TextContent(fmt.Sprintf("ℹ️ Already watched: **%s** (%d)",
+			Content: []Content{TextContent(msg(ctx, msgAlreadyWatchedMovie,
 				movie.Title, movie.Year))},
-		}, nil
+		}, logged
 	}
 
 	return ToolCallResult{
-		Content: []Content{TextContent("⚠️ Movie was not added (unknown reason)")},
-	}, nil
+		Content: []Content{TextContent(msg(ctx, msgMovieNotAdded))},
+	}, loggedItem{}
+}
+
+// sameDayPlay returns when the item was already watched on the local
+// calendar day of watchedAt (today for the zero time), according to the
+// user's history. Trakt's own duplicate detection only catches plays with
+// the exact same timestamp, so a rewatch logged twice would otherwise go
+// through. A failed lookup doesn't block the write.
+func sameDayPlay(ctx context.Context, client TraktAPI, kind string, id int, watchedAt time.Time) (time.Time, bool) {
+	day := watchedAt
+	if day.IsZero() {
+		day = time.Now()
+	}
+	y, m, d := day.Local().Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+
+	plays, err := client.GetHistory(ctx, fmt.Sprintf("%s/%d", kind, id), 1, trakt.WithPeriod(start, start.AddDate(0, 0, 1)))
+	if err != nil || len(plays) == 0 {
+		return time.Time{}, false
+	}
+	return plays[0].WatchedAt, true
+}
+
+// repeatedWrite returns the result of an earlier identical write, followed
+// by a note saying nothing new was written.
+func repeatedWrite(prev ToolCallResult, note string) ToolCallResult {
+	var sb strings.Builder
+	for _, c := range prev.Content {
+		sb.WriteString(c.Text)
+		sb.WriteString("\n")
+	}
+	sb.WriteString(note)
+	return ToolCallResult{Content: []Content{TextContent(sb.String())}}
 }
@@ -3,17 +3,67 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt/trakttest"
 )
 
-// mockTraktClient is a test double for the trakt.Client
-// Since we can't easily mock the real client without interfaces,
-// we test the handler logic with a real but unconfigured client
+// fakeTrakt is a TraktAPI test double. Tests set the function fields they
+// need; calling any other method panics via the nil embedded interface.
+type fakeTrakt struct {
+	TraktAPI
+	configured    bool
+	authenticated bool
+	getHistory    func(ctx context.Context, historyType string, limit int) ([]trakt.HistoryItem, error)
+	ping          func(ctx context.Context) error
+	rateLimits    []trakt.RateLimit
+	cacheStats    []trakt.CacheStats
+
+	historyPagination *trakt.Pagination
+	aliases           map[string][]trakt.Alias // keyed by "shows/<id>" or "movies/<id>"
+}
+
+func (f *fakeTrakt) IsAuthenticated() bool { return f.authenticated }
+
+func (f *fakeTrakt) IsConfigured() bool { return f.configured }
+
+func (f *fakeTrakt) Ping(ctx context.Context) error { return f.ping(ctx) }
+
+func (f *fakeTrakt) RateLimits() []trakt.RateLimit { return f.rateLimits }
+
+func (f *fakeTrakt) CacheStats() []trakt.CacheStats { return f.cacheStats }
+
+func (f *fakeTrakt) GetAliases(ctx context.Context, kind, id string) ([]trakt.Alias, error) {
+	return f.aliases[kind+"/"+id], nil
+}
+
+func (f *fakeTrakt) GetHistory(ctx context.Context, historyType string, limit int, opts ...trakt.RequestOption) ([]trakt.HistoryItem, error) {
+	return f.getHistory(ctx, historyType, limit)
+}
+
+// GetHistoryPage serves getHistory as a single page, or as page of
+// historyPagination when that is set.
+func (f *fakeTrakt) GetHistoryPage(ctx context.Context, historyType string, page, limit int) (*trakt.HistoryPage, error) {
+	items, err := f.getHistory(ctx, historyType, limit)
+	if err != nil {
+		return nil, err
+	}
+	pagination := trakt.Pagination{Page: page, Limit: limit, PageCount: page, ItemCount: len(items)}
+	if f.historyPagination != nil {
+		pagination = *f.historyPagination
+	}
+	return &trakt.HistoryPage{Items: items, Pagination: pagination}, nil
+}
 
 func TestRegisterTools(t *testing.T) {
 	server := NewServer(nil)
@@ -22,7 +72,7 @@ func TestRegisterTools(t *testing.T) {
 	RegisterTools(server, client)
 
 	// Verify all expected tools are registered
-	expectedTools := []string{"authenticate", "search_show", "get_history", "log_watch"}
+	expectedTools := []string{"authenticate", "complete_authentication", "refresh_auth", "search_show", "get_history", "get_watchlist", "what_should_i_watch", "get_upcoming", "get_show_progress", "get_stalled_shows", "compare_with_user", "get_watch_time", "year_in_review", "get_streaks", "monthly_report", "get_backlog_estimate", "export_history", "log_watch", "rate_and_log", "undo_last_watch", "import_history"}
 
 	server.mu.RLock()
 	defer server.mu.RUnlock()
@@ -41,6 +91,47 @@ func TestRegisterTools(t *testing.T) {
 	}
 }
 
+func TestRegisterTools_CountsAreIntegers(t *testing.T) {
+	server := NewServer(nil)
+	RegisterTools(server, trakt.NewClient(trakt.Config{}, nil))
+
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+
+	for tool, props := range map[string][]string{
+		"get_history":  {"limit", "page"},
+		"log_watch":    {"season", "episode", "absoluteEpisode"},
+		"rate_and_log": {"season", "episode", "rating"},
+	} {
+		for _, prop := range props {
+			if got := server.tools[tool].InputSchema.Properties[prop].Type; got != "integer" {
+				t.Errorf("%s %s has type %q, want integer", tool, prop, got)
+			}
+		}
+	}
+}
+
+func TestRegisterTools_ReadOnly(t *testing.T) {
+	server := NewServer(nil)
+	server.SetReadOnly(true)
+
+	RegisterTools(server, trakt.NewClient(trakt.Config{}, nil))
+
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+
+	for _, name := range []string{"log_watch", "export_history"} {
+		if _, ok := server.tools[name]; ok {
+			t.Errorf("tool %q should not be registered in read-only mode", name)
+		}
+	}
+	for _, name := range []string{"authenticate", "search_show", "get_history"} {
+		if _, ok := server.tools[name]; !ok {
+			t.Errorf("tool %q should still be registered", name)
+		}
+	}
+}
+
 func TestAuthenticateHandler_NotConfigured(t *testing.T) {
 	server := NewServer(nil)
 	client := trakt.NewClient(trakt.Config{}, nil) // No client ID
@@ -265,7 +356,7 @@ func TestTextContent(t *testing.T) {
 }
 
 func TestErrorContent(t *testing.T) {
-	result := ErrorContent(context.Canceled)
+	result := ErrorContent(context.Background(), context.Canceled)
 
 	if !result.IsError {
 		t.Error("IsError should be true")
@@ -353,6 +444,142 @@ func TestSearchHandler_Success(t *testing.T) {
 	}
 }
 
+func TestSearchHandler_LimitAndPage(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("limit") != "2" || q.Get("page") != "3" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		results := []trakt.SearchResult{
+			{Type: "show", Show: &trakt.Show{Title: "Dune", IDs: trakt.ShowIDs{Trakt: 1}}},
+			{Type: "movie", Movie: &trakt.Movie{Title: "Dune", IDs: trakt.MovieIDs{Trakt: 2}}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(results)
+	})
+
+	_, client := newMockTraktServer(t, handler)
+
+	result, err := makeSearchHandler(NewServer(nil), client)(context.Background(), json.RawMessage(`{"query":"dune","limit":2,"page":3}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "ask for page 4") {
+		t.Errorf("expected a footer for a full page, got: %s", text)
+	}
+}
+
+func TestFormatSearchResult_Extended(t *testing.T) {
+	text := formatSearchResult(trakt.SearchResult{
+		Type: "show",
+		Show: &trakt.Show{
+			Title:    "Breaking Bad",
+			Year:     2008,
+			IDs:      trakt.ShowIDs{Trakt: 1388, Slug: "breaking-bad"},
+			Overview: strings.Repeat("A chemistry teacher turns to crime. ", 10),
+			Runtime:  45,
+			Genres:   []string{"drama", "crime"},
+		},
+	})
+
+	for _, want := range []string{
+		"📺 **Breaking Bad** (2008) - Show - Trakt ID: 1388\n",
+		"   drama, crime · 45 min episodes\n",
+		"https://trakt.tv/shows/breaking-bad\n",
+		"…\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if len(text) > 400 {
+		t.Errorf("expected the overview to be shortened, got %d bytes", len(text))
+	}
+
+	// Without extended info only the basics are shown
+	text = formatSearchResult(trakt.SearchResult{Type: "movie", Movie: &trakt.Movie{Title: "Untitled", IDs: trakt.MovieIDs{Trakt: 7}}})
+	if text != "🎬 **Untitled** - Movie - Trakt ID: 7\n   https://trakt.tv/movies/7\n" {
+		t.Errorf("unexpected minimal rendering %q", text)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	if got := snippet("short  text", 20); got != "short text" {
+		t.Errorf("snippet() = %q", got)
+	}
+	if got := snippet("one two three four", 12); got != "one two…" {
+		t.Errorf("snippet() = %q, want cut at a word boundary", got)
+	}
+}
+
+func TestSearchHandler_Posters(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("extended"); got != "full,images" {
+			t.Errorf("expected extended=full,images, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"type":"show","score":1000,"show":{"title":"Breaking Bad","year":2008,"ids":{"trakt":1388},
+			"images":{"poster":["walter-r2.trakt.tv/images/shows/000/001/388/posters/medium/bb.jpg.webp"]}}}]`))
+	})
+
+	_, client := newMockTraktServer(t, handler)
+
+	result, err := makeSearchHandler(NewServer(nil), client)(context.Background(), json.RawMessage(`{"query":"breaking bad"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "Poster: https://walter-r2.trakt.tv/images/shows/000/001/388/posters/medium/bb.jpg.webp"
+	if !strings.Contains(result.Content[0].Text, want) {
+		t.Errorf("expected poster URL in result, got: %s", result.Content[0].Text)
+	}
+
+	if len(result.Content) != 2 {
+		t.Fatalf("expected a poster link after the text, got %d content items", len(result.Content))
+	}
+	link := result.Content[1]
+	if link.Type != "resource_link" || link.URI != strings.TrimPrefix(want, "Poster: ") || link.MimeType != "image/webp" {
+		t.Errorf("unexpected poster link %+v", link)
+	}
+}
+
+func TestSearchHandler_EpisodesAndPeople(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/episode,person" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"type":"episode","score":90,"show":{"title":"Breaking Bad","year":2008,"ids":{"trakt":1388,"slug":"breaking-bad"}},
+			 "episode":{"season":5,"number":14,"title":"Ozymandias","ids":{"trakt":73640},"runtime":47,"first_aired":"2013-09-16T01:00:00.000Z"}},
+			{"type":"person","score":80,"person":{"name":"Bryan Cranston","ids":{"trakt":1,"slug":"bryan-cranston"},
+			 "birthday":"1956-03-07","birthplace":"Hollywood, California, USA","known_for_department":"acting"}}]`))
+	})
+
+	_, client := newMockTraktServer(t, handler)
+
+	result, err := makeSearchHandler(NewServer(nil), client)(context.Background(),
+		json.RawMessage(`{"query":"ozymandias","type":"episode,person"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	text := result.Content[0].Text
+	for _, want := range []string{
+		"📺 **Breaking Bad** S05E14 - Ozymandias - Episode",
+		"47 min",
+		"Show ID: 1388 · Slug: breaking-bad · Episode ID: 73640",
+		"https://trakt.tv/shows/breaking-bad/seasons/5/episodes/14",
+		"👤 **Bryan Cranston** - Person - Trakt ID: 1",
+		"known for acting · born 1956-03-07 in Hollywood, California, USA",
+		"https://trakt.tv/people/bryan-cranston",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in result, got:\n%s", want, text)
+		}
+	}
+}
+
 func TestSearchHandler_NoResults(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -430,6 +657,205 @@ func TestGetHistoryHandler_Success(t *testing.T) {
 	}
 }
 
+func TestGetHistoryHandler_PassesFilters(t *testing.T) {
+	var gotType string
+	var gotLimit int
+	client := &fakeTrakt{
+		authenticated: true,
+		getHistory: func(ctx context.Context, historyType string, limit int) ([]trakt.HistoryItem, error) {
+			gotType, gotLimit = historyType, limit
+			return nil, nil
+		},
+	}
+
+	result, err := makeGetHistoryHandler(NewServer(nil), client)(context.Background(), json.RawMessage(`{"type":"movies","limit":5}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("unexpected error result: %s", result.Content[0].Text)
+	}
+	if gotType != "movies" || gotLimit != 5 {
+		t.Errorf("expected movies/5, got %s/%d", gotType, gotLimit)
+	}
+}
+
+func TestGetHistoryHandler_PaginationFooter(t *testing.T) {
+	var gotLimit int
+	client := &fakeTrakt{
+		authenticated: true,
+		getHistory: func(ctx context.Context, historyType string, limit int) ([]trakt.HistoryItem, error) {
+			gotLimit = limit
+			return []trakt.HistoryItem{{Type: "movie", Movie: &trakt.Movie{Title: "Inception"}}}, nil
+		},
+		historyPagination: &trakt.Pagination{Page: 2, Limit: 1, PageCount: 1482, ItemCount: 1482},
+	}
+
+	result, err := makeGetHistoryHandler(NewServer(nil), client)(context.Background(), json.RawMessage(`{"limit":1,"page":2}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotLimit != 1 {
+		t.Errorf("expected limit 1, got %d", gotLimit)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "Showing 1 of 1,482 items (page 2 of 1,482). Ask for page 3 to continue.") {
+		t.Errorf("unexpected footer in:\n%s", text)
+	}
+}
+
+func TestHistoryFooter_LastPage(t *testing.T) {
+	got := historyFooter(3, trakt.Pagination{Page: 2, Limit: 10, PageCount: 2, ItemCount: 13})
+	if got != "Showing 3 of 13 items (page 2 of 2).\n" {
+		t.Errorf("historyFooter() = %q", got)
+	}
+}
+
+func TestGetHistoryHandler_JSONFormat(t *testing.T) {
+	history := []trakt.HistoryItem{{
+		ID:    42,
+		Type:  "movie",
+		Movie: &trakt.Movie{Title: "Inception", Year: 2010, IDs: trakt.MovieIDs{Trakt: 16662}},
+	}}
+	client := &fakeTrakt{
+		authenticated: true,
+		getHistory: func(ctx context.Context, historyType string, limit int) ([]trakt.HistoryItem, error) {
+			return history, nil
+		},
+	}
+	handler := makeGetHistoryHandler(NewServer(nil), client)
+
+	result, err := handler(context.Background(), json.RawMessage(`{"format":"json"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []trakt.HistoryItem
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", result.Content[0].Text, err)
+	}
+	if len(got) != 1 || got[0].ID != 42 || got[0].Movie.IDs.Trakt != 16662 {
+		t.Errorf("unexpected history %+v", got)
+	}
+	if !strings.Contains(result.Content[0].Text, "\n  ") {
+		t.Errorf("expected pretty-printed JSON, got %q", result.Content[0].Text)
+	}
+
+	// No history is an empty array rather than prose
+	history = nil
+	result, err = handler(context.Background(), json.RawMessage(`{"format":"json"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := result.Content[0].Text; text != "[]" {
+		t.Errorf("expected [], got %q", text)
+	}
+}
+
+func TestGetHistoryHandler_CursorContinuesTruncatedResult(t *testing.T) {
+	var history []trakt.HistoryItem
+	for i := 1; i <= 5; i++ {
+		history = append(history, trakt.HistoryItem{
+			ID:    int64(i),
+			Type:  "movie",
+			Movie: &trakt.Movie{Title: fmt.Sprintf("Movie %d", i)},
+		})
+	}
+	var gotType string
+	var gotLimit int
+	client := &fakeTrakt{
+		authenticated: true,
+		getHistory: func(ctx context.Context, historyType string, limit int) ([]trakt.HistoryItem, error) {
+			gotType, gotLimit = historyType, limit
+			return history, nil
+		},
+	}
+	server := NewServer(nil)
+	itemSize := len(formatHistoryItem(history[0], time.Now()))
+	server.SetResponseBudget(2*itemSize + 1)
+	handler := makeGetHistoryHandler(server, client)
+
+	result, err := handler(context.Background(), json.RawMessage(`{"type":"movies","limit":5}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].Text
+	if !strings.Contains(text, "Movie 2") || strings.Contains(text, "Movie 3") {
+		t.Fatalf("expected the first two items only, got: %s", text)
+	}
+	match := regexp.MustCompile(`cursor "([^"]+)"`).FindStringSubmatch(text)
+	if match == nil {
+		t.Fatalf("expected a continuation cursor, got: %s", text)
+	}
+
+	result, err = handler(context.Background(), json.RawMessage(`{"cursor":"`+match[1]+`"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text = result.Content[0].Text
+	if strings.Contains(text, "Movie 2") || !strings.Contains(text, "Movie 3") || !strings.Contains(text, "Movie 4") {
+		t.Errorf("expected the continuation to start at the third item, got: %s", text)
+	}
+	if gotType != "movies" || gotLimit != 5 {
+		t.Errorf("expected the cursor to restore type and limit, got %q and %d", gotType, gotLimit)
+	}
+
+	result, err = handler(context.Background(), json.RawMessage(`{"cursor":"bogus"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected an error for an invalid cursor, got: %s", result.Content[0].Text)
+	}
+}
+
+func TestGetHistoryHandler_BudgetCutsJSONAndTemplates(t *testing.T) {
+	var history []trakt.HistoryItem
+	for i := 1; i <= 5; i++ {
+		history = append(history, trakt.HistoryItem{ID: int64(i), Type: "movie", Movie: &trakt.Movie{Title: fmt.Sprintf("Movie %d", i)}})
+	}
+	var gotLimit int
+	client := &fakeTrakt{
+		authenticated: true,
+		getHistory: func(ctx context.Context, historyType string, limit int) ([]trakt.HistoryItem, error) {
+			gotLimit = limit
+			return history, nil
+		},
+	}
+	server := NewServer(nil)
+	server.SetResponseBudget(300)
+	handler := makeGetHistoryHandler(server, client)
+
+	result, err := handler(context.Background(), json.RawMessage(`{"format":"json","limit":5000}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotLimit != maxHistoryLimit {
+		t.Errorf("expected limit capped at %d, got %d", maxHistoryLimit, gotLimit)
+	}
+	var items []trakt.HistoryItem
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &items); err != nil {
+		t.Fatalf("expected a JSON array first, got %q: %v", result.Content[0].Text, err)
+	}
+	if len(items) == 0 || len(items) == len(history) || len(result.Content) != 2 || !strings.Contains(result.Content[1].Text, "cursor") {
+		t.Errorf("expected the JSON cut with a cursor, got %d items and %+v", len(items), result.Content)
+	}
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "get_history", `{{range .Items}}{{.Movie.Title}} was watched and this line pads it out to a good length.
+{{end}}`)
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SetTemplates(templates)
+	server.SetResponseBudget(150)
+
+	result, _ = handler(context.Background(), json.RawMessage(`{}`))
+	text := result.Content[0].Text
+	if !strings.Contains(text, "Movie 2 was watched") || strings.Contains(text, "Movie 3 was watched") || !strings.Contains(text, "cursor") {
+		t.Errorf("expected the template output cut after two items with a cursor, got:\n%s", text)
+	}
+}
+
 func TestGetHistoryHandler_Empty(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -520,6 +946,44 @@ func TestAuthenticateHandler_Success(t *testing.T) {
 	}
 }
 
+func TestAuthenticateHandler_AutoComplete(t *testing.T) {
+	ts := trakttest.NewServer(t)
+	client := trakt.NewClient(trakt.Config{ClientID: trakttest.ClientID, ClientSecret: trakttest.ClientSecret}, nil)
+	client.SetBaseURL(ts.URL)
+
+	server := NewServer(nil)
+	RegisterTools(server, client)
+
+	// Keep the session open until the completion notification arrives
+	pr, pw := io.Pipe()
+	out := &notifyWriter{match: `"notifications/progress"`, seen: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() { done <- server.RunWithIO(context.Background(), pr, out) }()
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	callReq := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"authenticate","arguments":{},"_meta":{"progressToken":"auth-1"}}}`
+	if _, err := io.WriteString(pw, initReq+"\n"+callReq+"\n"); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	select {
+	case <-out.seen:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no progress notification; output: %s", out.String())
+	}
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("RunWithIO failed: %v", err)
+	}
+
+	if !client.IsAuthenticated() {
+		t.Error("expected client to be authenticated after the poll succeeded")
+	}
+	if !strings.Contains(out.String(), `"progressToken":"auth-1"`) {
+		t.Errorf("expected notification to carry the progress token, got: %s", out.String())
+	}
+}
+
 func TestLogWatchHandler_EpisodeSuccess(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -591,6 +1055,38 @@ func TestLogWatchHandler_EpisodeSuccess(t *testing.T) {
 	}
 }
 
+func TestLogWatchHandler_AddsToHistory(t *testing.T) {
+	ts := trakttest.NewServer(t)
+	server := NewServer(nil)
+	RegisterTools(server, ts.Client())
+
+	server.mu.RLock()
+	logHandler := server.handlers["log_watch"]
+	server.mu.RUnlock()
+
+	result, err := logHandler(context.Background(), json.RawMessage(`{
+		"type": "episode",
+		"showName": "Severance",
+		"season": 1,
+		"episode": 2,
+		"watchedAt": "2026-03-01T20:00:00Z"
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+
+	history := ts.History()
+	if len(history) != 1 || history[0].Episode == nil || history[0].Episode.Title != "Half Loop" {
+		t.Fatalf("expected Half Loop in history, got %+v", history)
+	}
+	if want := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC); !history[0].WatchedAt.Equal(want) {
+		t.Errorf("watched at %v, want %v", history[0].WatchedAt, want)
+	}
+}
+
 func TestLogWatchHandler_MovieSuccess(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -648,6 +1144,209 @@ func TestLogWatchHandler_MovieSuccess(t *testing.T) {
 	}
 }
 
+func TestLogWatchHandler_RetryDoesNotDuplicate(t *testing.T) {
+	var writes atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/search"):
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{{
+				Type:  "movie",
+				Score: 1000,
+				Movie: &trakt.Movie{Title: "Inception", Year: 2010, IDs: trakt.MovieIDs{Trakt: 16662}},
+			}})
+
+		case r.URL.Path == "/sync/history":
+			writes.Add(1)
+			var resp trakt.SyncResponse
+			resp.Added.Movies = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+
+	server := NewServer(nil)
+	RegisterTools(server, client)
+
+	server.mu.RLock()
+	logHandler := server.handlers["log_watch"]
+	server.mu.RUnlock()
+
+	args := json.RawMessage(`{"type": "movie", "movieName": "Inception"}`)
+	for i := 0; i < 2; i++ {
+		if _, err := logHandler(context.Background(), args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	result, err := logHandler(context.Background(), args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := writes.Load(); got != 1 {
+		t.Errorf("expected 1 history write for repeated calls, got %d", got)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, "Inception") || !strings.Contains(text, "already logged") {
+		t.Errorf("expected the earlier result with a note, got: %s", text)
+	}
+
+	// A different watch time is a separate play
+	if _, err := logHandler(context.Background(), json.RawMessage(`{"type": "movie", "movieName": "Inception", "watchedAt": "2024-01-01T20:00:00Z"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := writes.Load(); got != 2 {
+		t.Errorf("expected a second write for a different watchedAt, got %d", got)
+	}
+}
+
+func TestLogWatchHandler_ByID(t *testing.T) {
+	var searched, posted atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/search/trakt/1388":
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{{
+				Type: "show",
+				Show: &trakt.Show{Title: "Breaking Bad", Year: 2008, IDs: trakt.ShowIDs{Trakt: 1388}},
+			}})
+		case r.URL.Path == "/search/imdb/tt0000000":
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{})
+		case strings.HasPrefix(r.URL.Path, "/search"):
+			searched.Add(1)
+		case strings.Contains(r.URL.Path, "/shows/1388/seasons/1/episodes/1"):
+			_ = json.NewEncoder(w).Encode(trakt.Episode{Title: "Pilot", Season: 1, Number: 1, IDs: trakt.EpisodeIDs{Trakt: 62085}})
+		case r.URL.Path == "/sync/history":
+			posted.Add(1)
+			var resp trakt.SyncResponse
+			resp.Added.Episodes = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	logHandler := makeLogWatchHandler(client, func(context.Context, string, string, []trakt.SearchResult) *trakt.SearchResult { return nil }, newWriteGuard())
+
+	result, err := logHandler(context.Background(), json.RawMessage(`{"type":"episode","traktId":1388,"season":1,"episode":1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || !strings.Contains(result.Content[0].Text, "Breaking Bad") {
+		t.Errorf("expected the episode to be logged, got: %s", result.Content[0].Text)
+	}
+	if searched.Load() != 0 || posted.Load() != 1 {
+		t.Errorf("expected one write and no name search, got %d searches and %d writes", searched.Load(), posted.Load())
+	}
+
+	result, err = logHandler(context.Background(), json.RawMessage(`{"type":"movie","imdbId":"tt0000000"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "IMDb ID tt0000000") {
+		t.Errorf("expected a not-found error naming the ID, got: %s", result.Content[0].Text)
+	}
+}
+
+func TestLogWatchHandler_AbsoluteEpisode(t *testing.T) {
+	var gotEpisode string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/search"):
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{{
+				Type:  "show",
+				Score: 1000,
+				Show:  &trakt.Show{Title: "One Piece", Year: 1999, IDs: trakt.ShowIDs{Trakt: 37696}},
+			}})
+		case r.URL.Path == "/shows/37696/seasons":
+			_ = json.NewEncoder(w).Encode([]trakt.Season{
+				{Number: 21, Episodes: []trakt.Episode{{Number: 179, NumberAbs: 1070}, {Number: 180, NumberAbs: 1071}}},
+			})
+		case strings.HasPrefix(r.URL.Path, "/shows/37696/seasons/"):
+			gotEpisode = r.URL.Path
+			_ = json.NewEncoder(w).Encode(trakt.Episode{Title: "Luffy's Peak", Season: 21, Number: 180, IDs: trakt.EpisodeIDs{Trakt: 1}})
+		case r.URL.Path == "/sync/history":
+			var resp trakt.SyncResponse
+			resp.Added.Episodes = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	logHandler := makeLogWatchHandler(client, func(context.Context, string, string, []trakt.SearchResult) *trakt.SearchResult { return nil }, newWriteGuard())
+
+	result, err := logHandler(context.Background(), json.RawMessage(`{"type":"episode","showName":"One Piece","absoluteEpisode":1071}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || !strings.Contains(result.Content[0].Text, "S21E180") {
+		t.Errorf("expected S21E180 to be logged, got: %s", result.Content[0].Text)
+	}
+	if gotEpisode != "/shows/37696/seasons/21/episodes/180" {
+		t.Errorf("expected the mapped episode to be fetched, got %q", gotEpisode)
+	}
+
+	result, err = logHandler(context.Background(), json.RawMessage(`{"type":"episode","showName":"One Piece","absoluteEpisode":5000}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "Episode 5000 not found") {
+		t.Errorf("expected a not-found error, got: %s", result.Content[0].Text)
+	}
+}
+
+func TestLogWatchHandler_SameDayDuplicate(t *testing.T) {
+	earlier := time.Now().Add(-time.Minute)
+	var period string
+	var writes atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/search"):
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{{
+				Type:  "movie",
+				Score: 1000,
+				Movie: &trakt.Movie{Title: "Inception", Year: 2010, IDs: trakt.MovieIDs{Trakt: 16662}},
+			}})
+		case r.URL.Path == "/sync/history/movies/16662":
+			period = r.URL.Query().Get("start_at") + "/" + r.URL.Query().Get("end_at")
+			_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{{Type: "movie", WatchedAt: earlier}})
+		case r.URL.Path == "/sync/history":
+			writes.Add(1)
+			var resp trakt.SyncResponse
+			resp.Added.Movies = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	logHandler := makeLogWatchHandler(client, func(context.Context, string, string, []trakt.SearchResult) *trakt.SearchResult { return nil }, newWriteGuard())
+
+	result, err := logHandler(context.Background(), json.RawMessage(`{"type":"movie","movieName":"Inception"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if writes.Load() != 0 || !strings.Contains(result.Content[0].Text, "already logged") || !strings.Contains(result.Content[0].Text, "force") {
+		t.Errorf("expected a duplicate warning without a write, got: %s", result.Content[0].Text)
+	}
+	y, m, d := time.Now().Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	if want := start.UTC().Format(time.RFC3339) + "/" + start.AddDate(0, 0, 1).UTC().Format(time.RFC3339); period != want {
+		t.Errorf("expected today's plays to be checked (%s), got %s", want, period)
+	}
+
+	result, err = logHandler(context.Background(), json.RawMessage(`{"type":"movie","movieName":"Inception","force":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if writes.Load() != 1 || !strings.Contains(result.Content[0].Text, "Logged") {
+		t.Errorf("expected force to log the rewatch, got: %s", result.Content[0].Text)
+	}
+}
+
 func TestLogWatchHandler_EpisodeAlreadyWatched(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -806,3 +1505,78 @@ func TestLogWatchHandler_AmbiguousShow(t *testing.T) {
 		t.Errorf("expected disambiguation message, got: %s", text)
 	}
 }
+
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"unauthorized", &trakt.APIError{StatusCode: 401}, "Use the authenticate tool"},
+		{"rate limited with wait", &trakt.APIError{StatusCode: 429, RetryAfter: 90 * time.Second}, "Wait 1m30s"},
+		{"rate limited", &trakt.APIError{StatusCode: 429}, "Wait a few minutes"},
+		{"not found", &trakt.APIError{StatusCode: 404}, "Not found on Trakt"},
+		{"account limit", &trakt.APIError{StatusCode: 420, UpgradeURL: "https://trakt.tv/vip"}, "https://trakt.tv/vip"},
+		{"other", errors.New("boom"), "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorMessage(context.Background(), tt.err); !strings.Contains(got, tt.want) {
+				t.Errorf("errorMessage() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeDate(t *testing.T) {
+	// Wednesday evening
+	now := time.Date(2024, 3, 13, 21, 0, 0, 0, time.Local)
+	tests := []struct {
+		watched time.Time
+		want    string
+	}{
+		{time.Date(2024, 3, 13, 0, 30, 0, 0, time.Local), "today"},
+		{time.Date(2024, 3, 12, 23, 59, 0, 0, time.Local), "yesterday"},
+		{time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local), "Sunday, 3 days ago"},
+		{time.Date(2024, 3, 5, 12, 0, 0, 0, time.Local), "last week"},
+		{time.Date(2024, 2, 20, 12, 0, 0, 0, time.Local), "3 weeks ago"},
+		{time.Date(2024, 1, 20, 12, 0, 0, 0, time.Local), "last month"},
+		{time.Date(2023, 9, 1, 12, 0, 0, 0, time.Local), "6 months ago"},
+		{time.Date(2022, 3, 1, 12, 0, 0, 0, time.Local), "2 years ago"},
+		{time.Date(2024, 3, 14, 9, 0, 0, 0, time.Local), "in the future"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeDate(tt.watched, now); got != tt.want {
+			t.Errorf("relativeDate(%s) = %q, want %q", tt.watched.Format(time.DateTime), got, tt.want)
+		}
+	}
+}
+
+func TestFormatHistory_IDs(t *testing.T) {
+	now := time.Date(2024, 3, 13, 21, 0, 0, 0, time.Local)
+	text := formatHistory([]trakt.HistoryItem{
+		{
+			ID:        9,
+			Type:      "episode",
+			WatchedAt: now,
+			Show:      &trakt.Show{Title: "Breaking Bad", IDs: trakt.ShowIDs{Trakt: 1388, Slug: "breaking-bad"}},
+			Episode:   &trakt.Episode{Season: 1, Number: 2, Title: "Cat's in the Bag...", IDs: trakt.EpisodeIDs{Trakt: 73483}},
+		},
+		{
+			Type:      "movie",
+			WatchedAt: now,
+			Movie:     &trakt.Movie{Title: "Inception", IDs: trakt.MovieIDs{Trakt: 16662}},
+		},
+	}, now)
+
+	for _, want := range []string{
+		"   Show ID: 1388 · Slug: breaking-bad · Episode ID: 73483 · History ID: 9 · https://trakt.tv/shows/breaking-bad/seasons/1/episodes/2\n",
+		"   Trakt ID: 16662 · https://trakt.tv/movies/16662\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+}
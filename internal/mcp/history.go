@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// groupByShow is the get_history groupBy value that aggregates episodes
+// per show.
+const groupByShow = "show"
+
+// showGroup collects the episodes of one show within a page of history.
+type showGroup struct {
+	show        *trakt.Show
+	count       int
+	first, last time.Time // earliest and latest watch
+	firstEp     *trakt.Episode
+	lastEp      *trakt.Episode
+}
+
+// formatHistoryGroups renders history with each show's episodes collapsed
+// into one line, e.g. "Severance - 6 episodes between Mar 2-9", which reads
+// far better than a flat list after a binge. Shows are ordered by their
+// latest watch; movies are listed as usual, in place.
+func formatHistoryGroups(history []trakt.HistoryItem, now time.Time) string {
+	type entry struct {
+		group *showGroup
+		item  trakt.HistoryItem
+	}
+	var entries []entry
+	groups := make(map[int]*showGroup)
+
+	for _, h := range history {
+		if h.Type != "episode" || h.Show == nil || h.Episode == nil {
+			entries = append(entries, entry{item: h})
+			continue
+		}
+		g, ok := groups[h.Show.IDs.Trakt]
+		if !ok {
+			g = &showGroup{show: h.Show, first: h.WatchedAt, last: h.WatchedAt, firstEp: h.Episode, lastEp: h.Episode}
+			groups[h.Show.IDs.Trakt] = g
+			entries = append(entries, entry{group: g})
+		}
+		g.count++
+		// History is newest first, so on a tie the later item is earlier
+		if !h.WatchedAt.After(g.first) {
+			g.first, g.firstEp = h.WatchedAt, h.Episode
+		}
+		if h.WatchedAt.After(g.last) {
+			g.last, g.lastEp = h.WatchedAt, h.Episode
+		}
+	}
+
+	var sb strings.Builder
+	for _, e := range entries {
+		if e.group == nil {
+			sb.WriteString(formatHistoryItem(e.item, now))
+			continue
+		}
+		g := e.group
+		if g.count == 1 {
+			sb.WriteString(fmt.Sprintf("📺 **%s** - 1 episode, S%02dE%02d, on %s\n",
+				g.show.Title, g.firstEp.Season, g.firstEp.Number, shortDateRange(g.first, g.last)))
+		} else {
+			sb.WriteString(fmt.Sprintf("📺 **%s** - %d episodes, S%02dE%02d to S%02dE%02d, %s\n",
+				g.show.Title, g.count, g.firstEp.Season, g.firstEp.Number, g.lastEp.Season, g.lastEp.Number,
+				betweenDates(g.first, g.last)))
+		}
+		sb.WriteString(idLine(
+			idPart("Show ID", int64(g.show.IDs.Trakt)),
+			slugPart(g.show.IDs.Slug),
+			g.show.URL(),
+		))
+	}
+	return sb.String()
+}
+
+// betweenDates describes when a group of watches happened: "on Mar 2" for
+// a single day, otherwise "between Mar 2-9".
+func betweenDates(first, last time.Time) string {
+	if sameDay(first, last) {
+		return "on " + shortDateRange(first, last)
+	}
+	return "between " + shortDateRange(first, last)
+}
+
+// shortDateRange renders a local date range as compactly as it stays clear:
+// "Mar 2", "Mar 2-9", "Feb 28-Mar 3" or "Dec 30, 2023-Jan 2, 2024".
+func shortDateRange(first, last time.Time) string {
+	first, last = first.Local(), last.Local()
+	switch {
+	case sameDay(first, last):
+		return first.Format("Jan 2")
+	case first.Year() != last.Year():
+		return first.Format("Jan 2, 2006") + "-" + last.Format("Jan 2, 2006")
+	case first.Month() == last.Month():
+		return first.Format("Jan 2") + "-" + last.Format("2")
+	default:
+		return first.Format("Jan 2") + "-" + last.Format("Jan 2")
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Local().Date()
+	by, bm, bd := b.Local().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// historyBetween reads the history watched from since up to until, oldest
+// first, with runtimes and genres. It reads a page at a time, so reports
+// over long periods don't depend on a page size.
+func historyBetween(ctx context.Context, client TraktAPI, historyType string, since, until time.Time) ([]trakt.HistoryItem, error) {
+	var history []trakt.HistoryItem
+	err := client.ForEachHistoryItem(ctx, historyType, func(h trakt.HistoryItem) error {
+		history = append(history, h)
+		return nil
+	}, trakt.WithPeriod(since, until), trakt.WithExtended(trakt.ExtendedFull))
+	if err != nil {
+		return nil, err
+	}
+
+	// Trakt lists history newest first
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return history, nil
+}
+
+// playRuntime is the runtime of an episode or movie in minutes, or 0 if
+// unknown.
+func playRuntime(h trakt.HistoryItem) int {
+	switch {
+	case h.Episode != nil && h.Episode.Runtime > 0:
+		return h.Episode.Runtime
+	case h.Episode != nil && h.Show != nil:
+		return h.Show.Runtime
+	case h.Movie != nil:
+		return h.Movie.Runtime
+	}
+	return 0
+}
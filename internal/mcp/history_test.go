@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestFormatHistoryGroups(t *testing.T) {
+	severance := &trakt.Show{Title: "Severance", IDs: trakt.ShowIDs{Trakt: 154997, Slug: "severance"}}
+	at := func(day, hour int) time.Time { return time.Date(2024, 3, day, hour, 0, 0, 0, time.Local) }
+	episode := func(n, day int) trakt.HistoryItem {
+		return trakt.HistoryItem{Type: "episode", Show: severance, Episode: &trakt.Episode{Season: 1, Number: n}, WatchedAt: at(day, 21)}
+	}
+
+	// Newest first, as Trakt returns it
+	history := []trakt.HistoryItem{
+		episode(6, 9),
+		{Type: "movie", Movie: &trakt.Movie{Title: "Dune: Part Two", IDs: trakt.MovieIDs{Trakt: 1}}, WatchedAt: at(8, 20)},
+		episode(5, 8), episode(4, 5), episode(3, 4), episode(2, 2), episode(1, 2),
+		{Type: "episode", Show: &trakt.Show{Title: "Shogun", IDs: trakt.ShowIDs{Trakt: 2}}, Episode: &trakt.Episode{Season: 1, Number: 1}, WatchedAt: at(1, 22)},
+	}
+
+	got := formatHistoryGroups(history, at(10, 12))
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	wants := []string{
+		"📺 **Severance** - 6 episodes, S01E01 to S01E06, between Mar 2-9",
+		"   Show ID: 154997 · Slug: severance · https://trakt.tv/shows/severance",
+		"🎬 Dune: Part Two (",
+		"   Trakt ID: 1",
+		"📺 **Shogun** - 1 episode, S01E01, on Mar 1",
+	}
+	if len(lines) < len(wants) {
+		t.Fatalf("expected at least %d lines, got:\n%s", len(wants), got)
+	}
+	for i, want := range wants {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestShortDateRange(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 12, 0, 0, 0, time.Local) }
+	tests := []struct {
+		first, last time.Time
+		want        string
+	}{
+		{day(2024, 3, 2), day(2024, 3, 2), "Mar 2"},
+		{day(2024, 3, 2), day(2024, 3, 9), "Mar 2-9"},
+		{day(2024, 2, 28), day(2024, 3, 3), "Feb 28-Mar 3"},
+		{day(2023, 12, 30), day(2024, 1, 2), "Dec 30, 2023-Jan 2, 2024"},
+	}
+	for _, tc := range tests {
+		if got := shortDateRange(tc.first, tc.last); got != tc.want {
+			t.Errorf("shortDateRange(%v, %v) = %q, want %q", tc.first, tc.last, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// duplicateWriteWindow is how long a write is remembered; the same write
+	// repeated within it is treated as a retry rather than a new play.
+	duplicateWriteWindow = 2 * time.Minute
+	// recentWriteCapacity bounds the ring of remembered writes.
+	recentWriteCapacity = 64
+)
+
+// writeKey identifies a write by a hash of its content, e.g. the item's
+// Trakt ID and the watched-at time.
+type writeKey [sha256.Size]byte
+
+func newWriteKey(parts ...string) writeKey {
+	return sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+}
+
+// writeGuard remembers recent successful writes in a fixed-size ring so
+// that a model retrying a call it thinks failed gets the earlier result
+// back instead of creating a duplicate play. Writes still in flight are
+// held too, so a retry sent while the first attempt runs waits for it.
+type writeGuard struct {
+	mu       sync.Mutex
+	entries  [recentWriteCapacity]recentWrite
+	next     int
+	inFlight map[writeKey]chan struct{} // closed when the write finishes
+	now      func() time.Time
+}
+
+type recentWrite struct {
+	key    writeKey
+	at     time.Time
+	result ToolCallResult
+}
+
+func newWriteGuard() *writeGuard {
+	return &writeGuard{inFlight: make(map[writeKey]chan struct{}), now: time.Now}
+}
+
+// claim reserves key for a write about to be made, returning true and
+// the earlier result instead if the same write was made within the
+// window. If it's in flight, claim waits for it to finish first, so of
+// two concurrent calls only one writes. A caller given false must call
+// release once the write is done, after remembering it if it succeeded.
+// It fails only if ctx ends while waiting.
+func (g *writeGuard) claim(ctx context.Context, key writeKey) (ToolCallResult, bool, error) {
+	for {
+		g.mu.Lock()
+		if result, ok := g.recent(key); ok {
+			g.mu.Unlock()
+			return result, true, nil
+		}
+		done, busy := g.inFlight[key]
+		if !busy {
+			g.inFlight[key] = make(chan struct{})
+			g.mu.Unlock()
+			return ToolCallResult{}, false, nil
+		}
+		g.mu.Unlock()
+
+		select {
+		case <-done:
+			// Remembered if it succeeded; claimed again if it failed
+		case <-ctx.Done():
+			return ToolCallResult{}, false, ctx.Err()
+		}
+	}
+}
+
+// release ends the write claim reserved key for.
+func (g *writeGuard) release(key writeKey) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if done, ok := g.inFlight[key]; ok {
+		close(done)
+		delete(g.inFlight, key)
+	}
+}
+
+// lookup returns the result of the same write made within the window.
+func (g *writeGuard) lookup(key writeKey) (ToolCallResult, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.recent(key)
+}
+
+// recent is lookup for callers holding g.mu.
+func (g *writeGuard) recent(key writeKey) (ToolCallResult, bool) {
+	cutoff := g.now().Add(-duplicateWriteWindow)
+	for _, e := range g.entries {
+		if e.key == key && e.at.After(cutoff) {
+			return e.result, true
+		}
+	}
+	return ToolCallResult{}, false
+}
+
+// remember records a write's result, overwriting the oldest entry.
+func (g *writeGuard) remember(key writeKey, result ToolCallResult) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.entries[g.next] = recentWrite{key: key, at: g.now(), result: result}
+	g.next = (g.next + 1) % recentWriteCapacity
+}
+
+// forget drops every remembered write, e.g. after one is undone, so logging
+// it again isn't mistaken for a retry.
+func (g *writeGuard) forget() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.entries = [recentWriteCapacity]recentWrite{}
+	g.next = 0
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestWriteGuard_Window(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	guard := newWriteGuard()
+	guard.now = func() time.Time { return now }
+
+	key := newWriteKey("movie", "16662", "")
+	if _, ok := guard.lookup(key); ok {
+		t.Fatal("expected no entry before the first write")
+	}
+
+	guard.remember(key, ToolCallResult{Content: []Content{TextContent("logged")}})
+	if result, ok := guard.lookup(key); !ok || result.Content[0].Text != "logged" {
+		t.Errorf("expected the earlier result, got %+v, %v", result, ok)
+	}
+	if _, ok := guard.lookup(newWriteKey("movie", "16662", "2024-01-01T10:00:00Z")); ok {
+		t.Error("expected a different watchedAt to be a different write")
+	}
+
+	now = now.Add(duplicateWriteWindow + time.Second)
+	if _, ok := guard.lookup(key); ok {
+		t.Error("expected the entry to expire after the window")
+	}
+}
+
+func TestWriteGuard_RingOverwritesOldest(t *testing.T) {
+	guard := newWriteGuard()
+	for i := 0; i <= recentWriteCapacity; i++ {
+		guard.remember(newWriteKey(fmt.Sprint(i)), ToolCallResult{})
+	}
+
+	if _, ok := guard.lookup(newWriteKey("0")); ok {
+		t.Error("expected the oldest write to be forgotten")
+	}
+	if _, ok := guard.lookup(newWriteKey(fmt.Sprint(recentWriteCapacity))); !ok {
+		t.Error("expected the newest write to be remembered")
+	}
+}
+
+func TestWriteGuard_Forget(t *testing.T) {
+	guard := newWriteGuard()
+	key := newWriteKey("movie", "16662", "")
+	guard.remember(key, ToolCallResult{})
+
+	guard.forget()
+	if _, ok := guard.lookup(key); ok {
+		t.Error("expected no entries after forget")
+	}
+}
+
+func TestLogWatch_ConcurrentRetryLogsOnce(t *testing.T) {
+	posted := make(chan struct{})
+	release := make(chan struct{})
+	var posts atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/search"):
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{
+				{Type: "movie", Score: 1000, Movie: &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 287071}}},
+			})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/sync/history"):
+			_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{})
+		case r.Method == http.MethodPost && r.URL.Path == "/sync/history":
+			if posts.Add(1) == 1 {
+				close(posted)
+				<-release
+			}
+			_ = json.NewEncoder(w).Encode(trakt.SyncResponse{Added: trakt.SyncStats{Movies: 1}})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+	_, client := newMockTraktServer(t, handler)
+	logHandler := makeLogWatchHandler(client, func(context.Context, string, string, []trakt.SearchResult) *trakt.SearchResult { return nil }, newWriteGuard())
+	args := json.RawMessage(`{"type":"movie","movieName":"Dune","watchedAt":"2024-01-01T20:00:00Z"}`)
+
+	results := make(chan ToolCallResult, 2)
+	call := func() {
+		result, _ := logHandler(context.Background(), args)
+		results <- result
+	}
+	go call()
+	<-posted
+	// The retry arrives while the first write is still in flight
+	go call()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	var texts []string
+	for range 2 {
+		result := <-results
+		if result.IsError {
+			t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+		}
+		texts = append(texts, result.Content[0].Text)
+	}
+	if n := posts.Load(); n != 1 {
+		t.Errorf("expected one AddToHistory, got %d", n)
+	}
+	if !strings.Contains(texts[1], "already logged moments ago") {
+		t.Errorf("expected the retry told it was already logged, got %q", texts[1])
+	}
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/importer"
+)
+
+func makeImportHistoryHandler(client TraktAPI) ToolHandler {
+	type importArgs struct {
+		Source string `json:"source"`
+		Path   string `json:"path"`
+		DryRun bool   `json:"dryRun"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a importArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		if strings.TrimSpace(a.Path) == "" {
+			return ToolCallResult{
+				Content: []Content{TextContent("Error: path is required")},
+				IsError: true,
+			}, nil
+		}
+		if !slices.Contains(importer.Sources(), a.Source) {
+			return ToolCallResult{
+				Content: []Content{TextContent("Error: source must be one of " + strings.Join(importer.Sources(), ", "))},
+				IsError: true,
+			}, nil
+		}
+		path, err := expandPath(a.Path)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+
+		exp, err := readImport(a.Source, path)
+		if err != nil {
+			return ToolCallResult{
+				Content: []Content{TextContent("Error: " + err.Error())},
+				IsError: true,
+			}, nil
+		}
+		r, err := importer.Import(ctx, client, exp, a.DryRun)
+		text := formatImported(r, importer.Name(a.Source), a.DryRun)
+		if err != nil {
+			return ToolCallResult{
+				Content: []Content{TextContent(text), TextContent("Error: " + err.Error() + ". Run the import again to finish; what's already imported won't be added twice.")},
+				IsError: true,
+			}, nil
+		}
+		return ToolCallResult{Content: []Content{TextContent(text)}}, nil
+	}
+}
+
+// readImport reads the export of source at path.
+func readImport(source, path string) (*importer.Export, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return importer.Parse(source, f)
+}
+
+// formatImported reports what an import from service added, or with
+// dryRun would add, and what it couldn't match.
+func formatImported(r importer.Result, service string, dryRun bool) string {
+	var sb strings.Builder
+	if dryRun {
+		sb.WriteString(fmt.Sprintf("🔍 Importing from %s would add %s plays and %s ratings\n", service, formatCount(r.Plays), formatCount(r.Ratings)))
+	} else {
+		sb.WriteString(fmt.Sprintf("📥 Imported %s plays and %s ratings from %s\n", formatCount(r.Plays), formatCount(r.Ratings), service))
+	}
+	if r.Skipped > 0 {
+		sb.WriteString(fmt.Sprintf("\n%s plays were already in the history.\n", formatCount(r.Skipped)))
+	}
+	if len(r.Unmatched) > 0 {
+		sb.WriteString(fmt.Sprintf("\n**Not found on Trakt** (%s)\n", formatCount(len(r.Unmatched))))
+		for _, u := range r.Unmatched[:min(len(r.Unmatched), diffListLimit)] {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", u.Title, u.Reason))
+		}
+		if n := len(r.Unmatched) - diffListLimit; n > 0 {
+			sb.WriteString(fmt.Sprintf("- …and %s more\n", formatCount(n)))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// importTrakt knows Dune by its TMDB ID and has an empty history.
+type importTrakt struct {
+	fakeTrakt
+	added   []trakt.WatchedItem
+	ratings []trakt.RatingItem
+}
+
+func (f *importTrakt) LookupID(ctx context.Context, idType, id, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error) {
+	if idType == "tmdb" && id == "438631" {
+		return []trakt.SearchResult{{Type: "movie", Movie: &trakt.Movie{IDs: trakt.MovieIDs{Trakt: 2}}}}, nil
+	}
+	return nil, nil
+}
+
+func (f *importTrakt) ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error {
+	return nil
+}
+
+func (f *importTrakt) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	f.added = append(f.added, item)
+	return &trakt.SyncResponse{}, nil
+}
+
+func (f *importTrakt) AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error) {
+	f.ratings = append(f.ratings, item)
+	return &trakt.SyncResponse{}, nil
+}
+
+func TestImportHistoryHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "simkl.json")
+	backup := `{"movies": [
+		{"status": "completed", "last_watched_at": "2026-03-01T20:00:00Z", "user_rating": 9, "movie": {"title": "Dune", "year": 2021, "ids": {"tmdb": "438631"}}},
+		{"status": "completed", "movie": {"title": "Home Video", "ids": {"simkl": 5}}}
+	]}`
+	if err := os.WriteFile(path, []byte(backup), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client := &importTrakt{fakeTrakt: fakeTrakt{authenticated: true}}
+	handler := makeImportHistoryHandler(client)
+
+	args, _ := json.Marshal(map[string]any{"source": "simkl", "path": path, "dryRun": true})
+	result, err := handler(context.Background(), args)
+	if err != nil || result.IsError {
+		t.Fatalf("dry run failed: %v %+v", err, result)
+	}
+	text := result.Content[0].Text
+	if !strings.Contains(text, "Importing from Simkl would add 1 plays and 1 ratings") || !strings.Contains(text, "- Home Video: no TMDB, TVDB or IMDb ID") {
+		t.Errorf("unexpected dry run:\n%s", text)
+	}
+	if len(client.added) != 0 {
+		t.Fatal("expected a dry run to change nothing")
+	}
+
+	args, _ = json.Marshal(map[string]any{"source": "simkl", "path": path})
+	result, err = handler(context.Background(), args)
+	if err != nil || result.IsError {
+		t.Fatalf("import failed: %v %+v", err, result)
+	}
+	if !strings.Contains(result.Content[0].Text, "Imported 1 plays and 1 ratings from Simkl") {
+		t.Errorf("unexpected result:\n%s", result.Content[0].Text)
+	}
+	if len(client.added) != 1 || client.added[0].WatchedAt != "2026-03-01T20:00:00Z" || len(client.ratings) != 1 {
+		t.Errorf("unexpected writes %+v %+v", client.added, client.ratings)
+	}
+}
+
+func TestImportHistoryHandler_InvalidArgs(t *testing.T) {
+	handler := makeImportHistoryHandler(&importTrakt{fakeTrakt: fakeTrakt{authenticated: true}})
+	for _, args := range []string{
+		`{"source": "simkl"}`,
+		`{"source": "netflix", "path": "/tmp/x.json"}`,
+		`{"source": "simkl", "path": "/nonexistent/simkl.json"}`,
+	} {
+		result, err := handler(context.Background(), json.RawMessage(args))
+		if err != nil || !result.IsError {
+			t.Errorf("expected an error for %s, got %+v", args, result)
+		}
+	}
+}
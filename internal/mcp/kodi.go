@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/kodi"
+)
+
+// KodiPollInterval is how often WatchKodi asks Kodi what it's playing.
+const KodiPollInterval = 15 * time.Second
+
+// WatchKodi asks Kodi what it's playing every interval until ctx is
+// cancelled, and scrobbles the movies and episodes it plays to Trakt.
+// Library items are matched by their scrapers' IDs, and others by title.
+// While Kodi can't be reached it's taken to be playing nothing, so an item
+// it was playing is stopped at its last progress.
+func (s *Server) WatchKodi(ctx context.Context, client TraktAPI, k *kodi.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sc := NewScrobbler(client, s.logger)
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if !client.IsAuthenticated() {
+			continue
+		}
+
+		playing, err := k.Playing(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Debug("kodi unreachable", "error", err)
+		}
+		sc.Update(ctx, kodiPlayback(playing))
+	}
+}
+
+// kodiPlayback is what Kodi is playing as a Playback, or nil if it's
+// nothing that could be scrobbled.
+func kodiPlayback(p *kodi.Playing) *Playback {
+	if p == nil {
+		return nil
+	}
+	item := p.Item
+	if item.Type != "movie" && item.Type != "episode" {
+		return nil
+	}
+	key := item.File
+	if key == "" {
+		key = item.Type + "/" + item.ShowTitle + "/" + item.Title
+	}
+	// An episode's year is when it aired, not the show's
+	year := item.Year
+	if item.Type == "episode" {
+		year = 0
+	}
+	return &Playback{
+		Key:       key,
+		Type:      item.Type,
+		Title:     item.Title,
+		ShowTitle: item.ShowTitle,
+		Season:    item.Season,
+		Episode:   item.Episode,
+		Year:      year,
+		IDs:       item.UniqueIDs,
+		Progress:  p.Percentage,
+		Paused:    p.Paused,
+	}
+}
@@ -0,0 +1,435 @@
+// Package mcptest drives an mcp.Server in-process for tests. A Client runs
+// the server's session over pipes, as a real client would over stdio, and
+// offers calls such as CallTool that return decoded results, so a test
+// needn't write JSON-RPC by hand or pick replies out of the output:
+//
+//	c := mcptest.Connect(t, server)
+//	result, err := c.CallTool("get_history", map[string]any{"limit": 5})
+//
+// Notifications the server sends are kept for the test to check, and
+// requests it sends, such as for sampling, are answered by handlers the
+// test sets with Handle.
+package mcptest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+)
+
+// DefaultTimeout bounds how long a call waits for the server's reply.
+const DefaultTimeout = 5 * time.Second
+
+// maxMessageSize bounds a message read from the server.
+const maxMessageSize = 10 * 1024 * 1024
+
+// Notification is a notification the server sent.
+type Notification struct {
+	Method string
+	Params json.RawMessage
+}
+
+// Decode decodes the notification's params into v.
+func (n Notification) Decode(v any) error {
+	return json.Unmarshal(n.Params, v)
+}
+
+// Handler answers a request the server sends to the client, returning its
+// result, or an error that's sent back as a JSON-RPC error.
+type Handler func(params json.RawMessage) (any, error)
+
+// message is any JSON-RPC message, in either direction.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *mcp.Error      `json:"error,omitempty"`
+}
+
+// Client is an MCP client connected to a server in-process.
+type Client struct {
+	// Timeout bounds how long each call waits for its reply; it's
+	// DefaultTimeout unless changed.
+	Timeout time.Duration
+
+	tb   testing.TB
+	in   *io.PipeWriter
+	done chan error
+
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	nextID        int
+	pending       map[string]chan message
+	handlers      map[string]Handler
+	notifications []Notification
+	notified      chan struct{} // closed and replaced on each notification
+	closed        bool
+	err           error
+}
+
+// NewClient starts a session of server with a new client, without the
+// initialize handshake, and ends it when tb's test ends. Most tests want
+// Connect instead.
+func NewClient(tb testing.TB, server *mcp.Server) *Client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &Client{
+		Timeout:  DefaultTimeout,
+		tb:       tb,
+		in:       inW,
+		done:     make(chan error, 1),
+		pending:  make(map[string]chan message),
+		handlers: make(map[string]Handler),
+		notified: make(chan struct{}),
+	}
+	go func() {
+		err := server.RunWithIO(context.Background(), inR, outW)
+		outW.Close()
+		c.done <- err
+	}()
+	go c.read(outR)
+	tb.Cleanup(func() {
+		if err := c.Close(); err != nil {
+			tb.Errorf("mcptest: session ended with %v", err)
+		}
+	})
+	return c
+}
+
+// Connect starts a session of server with a new client that has made the
+// initialize handshake, offering the latest protocol version and no
+// capabilities.
+func Connect(tb testing.TB, server *mcp.Server) *Client {
+	tb.Helper()
+	c := NewClient(tb, server)
+	if _, err := c.Initialize(mcp.InitializeParams{}); err != nil {
+		tb.Fatalf("mcptest: initialize: %v", err)
+	}
+	return c
+}
+
+// Initialize makes the handshake: the initialize request, filled in with
+// the latest protocol version and a client name where params leave them
+// empty, then the initialized notification.
+func (c *Client) Initialize(params mcp.InitializeParams) (*mcp.InitializeResult, error) {
+	if params.ProtocolVersion == "" {
+		params.ProtocolVersion = mcp.LatestProtocolVersion
+	}
+	if params.ClientInfo.Name == "" {
+		params.ClientInfo = mcp.Implementation{Name: "mcptest", Version: "1.0"}
+	}
+	var result mcp.InitializeResult
+	if err := c.Call("initialize", params, &result); err != nil {
+		return nil, err
+	}
+	if err := c.Notify("notifications/initialized", nil); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Call sends a request and decodes its result into result, unless result
+// is nil. A JSON-RPC error reply is returned as an *mcp.Error.
+func (c *Client) Call(method string, params, result any) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return errors.New("mcptest: client closed")
+	}
+	c.nextID++
+	id := strconv.Itoa(c.nextID)
+	reply := make(chan message, 1)
+	c.pending[id] = reply
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(message{ID: json.RawMessage(id), Method: method}, params); err != nil {
+		return err
+	}
+
+	select {
+	case msg, ok := <-reply:
+		if !ok {
+			return fmt.Errorf("mcptest: session ended before %s was answered", method)
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	case <-time.After(c.Timeout):
+		return fmt.Errorf("mcptest: no reply to %s within %v", method, c.Timeout)
+	}
+}
+
+// Notify sends a notification.
+func (c *Client) Notify(method string, params any) error {
+	return c.send(message{Method: method}, params)
+}
+
+// CallTool calls a tool with args, which may be nil, a value to encode or
+// already encoded JSON. A tool that fails returns a result with IsError
+// set, not an error.
+func (c *Client) CallTool(name string, args any) (*mcp.ToolCallResult, error) {
+	return c.CallToolWithProgress(name, args, nil)
+}
+
+// CallToolWithProgress calls a tool as CallTool does, asking for progress
+// notifications with token.
+func (c *Client) CallToolWithProgress(name string, args, token any) (*mcp.ToolCallResult, error) {
+	params := mcp.ToolCallParams{Name: name}
+	if args != nil {
+		raw, err := encode(args)
+		if err != nil {
+			return nil, err
+		}
+		params.Arguments = raw
+	}
+	if token != nil {
+		params.Meta = &mcp.RequestMeta{ProgressToken: token}
+	}
+	var result mcp.ToolCallResult
+	if err := c.Call("tools/call", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTools returns all the server's tools, following its cursors.
+func (c *Client) ListTools() ([]mcp.Tool, error) {
+	var tools []mcp.Tool
+	cursor := ""
+	for {
+		var page mcp.ToolsListResult
+		if err := c.Call("tools/list", cursorParams(cursor), &page); err != nil {
+			return nil, err
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// ListResources returns all the server's resources, following its cursors.
+func (c *Client) ListResources() ([]mcp.Resource, error) {
+	var resources []mcp.Resource
+	cursor := ""
+	for {
+		var page mcp.ResourcesListResult
+		if err := c.Call("resources/list", cursorParams(cursor), &page); err != nil {
+			return nil, err
+		}
+		resources = append(resources, page.Resources...)
+		if page.NextCursor == "" {
+			return resources, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// ReadResource reads the resource at uri.
+func (c *Client) ReadResource(uri string) (*mcp.ResourceReadResult, error) {
+	var result mcp.ResourceReadResult
+	if err := c.Call("resources/read", mcp.ResourceReadParams{URI: uri}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPrompt gets a prompt filled in with args.
+func (c *Client) GetPrompt(name string, args map[string]string) (*mcp.PromptGetResult, error) {
+	var result mcp.PromptGetResult
+	if err := c.Call("prompts/get", mcp.PromptGetParams{Name: name, Arguments: args}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Handle sets h to answer the server's requests for method, such as
+// "sampling/createMessage". Requests without a handler are answered with
+// a method not found error.
+func (c *Client) Handle(method string, h Handler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[method] = h
+}
+
+// Notifications returns the notifications the server has sent so far.
+func (c *Client) Notifications() []Notification {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Notification(nil), c.notifications...)
+}
+
+// WaitForNotification returns the first notification for method the
+// server has sent, waiting up to the client's Timeout for one.
+func (c *Client) WaitForNotification(method string) (Notification, error) {
+	deadline := time.After(c.Timeout)
+	for {
+		c.mu.Lock()
+		for _, n := range c.notifications {
+			if n.Method == method {
+				c.mu.Unlock()
+				return n, nil
+			}
+		}
+		notified, closed := c.notified, c.closed
+		c.mu.Unlock()
+		if closed {
+			return Notification{}, fmt.Errorf("mcptest: session ended without a %s notification", method)
+		}
+
+		select {
+		case <-notified:
+		case <-deadline:
+			return Notification{}, fmt.Errorf("mcptest: no %s notification within %v", method, c.Timeout)
+		}
+	}
+}
+
+// Close ends the session, as a client closing stdin does, and returns the
+// error the server's session ended with. It's called when the test ends;
+// calling it earlier lets a test check the server after the session.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	c.in.Close()
+	err := <-c.done
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+	return err
+}
+
+// send writes a message with params encoded.
+func (c *Client) send(msg message, params any) error {
+	msg.JSONRPC = "2.0"
+	if params != nil {
+		raw, err := encode(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = raw
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.in.Write(append(data, '\n'))
+	return err
+}
+
+// read routes the server's messages until its output ends: replies to
+// their calls, requests to their handlers and notifications to the list.
+func (c *Client) read(out io.Reader) {
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			c.tb.Errorf("mcptest: server sent invalid JSON %q: %v", scanner.Text(), err)
+			continue
+		}
+
+		switch {
+		case msg.Method == "":
+			c.mu.Lock()
+			reply, ok := c.pending[string(msg.ID)]
+			c.mu.Unlock()
+			if ok {
+				reply <- msg
+			}
+		case len(msg.ID) == 0:
+			c.mu.Lock()
+			c.notifications = append(c.notifications, Notification{Method: msg.Method, Params: msg.Params})
+			close(c.notified)
+			c.notified = make(chan struct{})
+			c.mu.Unlock()
+		default:
+			// Answered on another goroutine so a handler may itself call
+			// the server
+			go c.answer(msg)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	close(c.notified)
+	for id, reply := range c.pending {
+		close(reply)
+		delete(c.pending, id)
+	}
+}
+
+// answer replies to a request from the server.
+func (c *Client) answer(req message) {
+	c.mu.Lock()
+	h, ok := c.handlers[req.Method]
+	c.mu.Unlock()
+
+	reply := message{JSONRPC: "2.0", ID: req.ID}
+	if !ok {
+		reply.Error = &mcp.Error{Code: mcp.MethodNotFound, Message: "Method not found: " + req.Method}
+	} else if result, err := h(req.Params); err != nil {
+		reply.Error = &mcp.Error{Code: mcp.InternalError, Message: err.Error()}
+	} else if reply.Result, err = json.Marshal(result); err != nil {
+		reply.Error = &mcp.Error{Code: mcp.InternalError, Message: err.Error()}
+	}
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		c.tb.Errorf("mcptest: encode reply to %s: %v", req.Method, err)
+		return
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, _ = c.in.Write(append(data, '\n'))
+}
+
+// encode returns v as JSON, passing JSON it's given as is.
+func encode(v any) (json.RawMessage, error) {
+	switch v := v.(type) {
+	case json.RawMessage:
+		return v, nil
+	case []byte:
+		return v, nil
+	}
+	return json.Marshal(v)
+}
+
+// cursorParams are the params of a list request for the page at cursor.
+func cursorParams(cursor string) any {
+	if cursor == "" {
+		return nil
+	}
+	return map[string]string{"cursor": cursor}
+}
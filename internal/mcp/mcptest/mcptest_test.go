@@ -0,0 +1,102 @@
+package mcptest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+	"github.com/kofifort/trakt-mcp-go/internal/mcp/mcptest"
+)
+
+func newServer() *mcp.Server {
+	server := mcp.NewServer(nil)
+	server.RegisterTool(mcp.Tool{Name: "echo", InputSchema: mcp.JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (mcp.ToolCallResult, error) {
+			var a struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(args, &a); err != nil {
+				return mcp.ErrorContent(ctx, err), nil
+			}
+			return mcp.ToolCallResult{Content: []mcp.Content{mcp.TextContent(a.Text)}}, nil
+		})
+	return server
+}
+
+func TestClient_CallTool(t *testing.T) {
+	c := mcptest.Connect(t, newServer())
+
+	tools, err := c.ListTools()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 1 || tools[0].Name != "echo" {
+		t.Errorf("expected the echo tool, got %+v", tools)
+	}
+
+	result, err := c.CallTool("echo", map[string]string{"text": "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError || result.Content[0].Text != "hello" {
+		t.Errorf("expected hello echoed, got %+v", result)
+	}
+
+	err = c.Call("no/such/method", nil, nil)
+	var rpcErr *mcp.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != mcp.MethodNotFound {
+		t.Errorf("expected method not found, got %v", err)
+	}
+}
+
+func TestClient_Initialize(t *testing.T) {
+	c := mcptest.NewClient(t, newServer())
+
+	result, err := c.Initialize(mcp.InitializeParams{ProtocolVersion: mcp.ProtocolVersion})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ProtocolVersion != mcp.ProtocolVersion || result.ServerInfo.Name != mcp.ServerName {
+		t.Errorf("unexpected initialize result %+v", result)
+	}
+}
+
+func TestClient_AnswersServerRequests(t *testing.T) {
+	server := newServer()
+	server.RegisterTool(mcp.Tool{Name: "ask", InputSchema: mcp.JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (mcp.ToolCallResult, error) {
+			_ = server.Notify("notifications/message", mcp.LoggingMessageParams{Level: "info", Data: "asking"})
+			resp, err := server.CreateMessage(ctx, mcp.CreateMessageParams{MaxTokens: 10})
+			if err != nil {
+				return mcp.ErrorContent(ctx, err), nil
+			}
+			return mcp.ToolCallResult{Content: []mcp.Content{resp.Content}}, nil
+		})
+
+	c := mcptest.NewClient(t, server)
+	if _, err := c.Initialize(mcp.InitializeParams{Capabilities: mcp.Capabilities{Sampling: &mcp.SamplingCapability{}}}); err != nil {
+		t.Fatal(err)
+	}
+	c.Handle("sampling/createMessage", func(params json.RawMessage) (any, error) {
+		return mcp.CreateMessageResult{Role: "assistant", Content: mcp.TextContent("42")}, nil
+	})
+
+	result, err := c.CallTool("ask", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError || result.Content[0].Text != "42" {
+		t.Errorf("expected the sampled answer, got %+v", result)
+	}
+
+	n, err := c.WaitForNotification("notifications/message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg mcp.LoggingMessageParams
+	if err := n.Decode(&msg); err != nil || msg.Data != "asking" {
+		t.Errorf("unexpected notification %+v (%v)", msg, err)
+	}
+}
@@ -0,0 +1,461 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is used for output when no language is configured, and
+// for any message a catalog doesn't translate.
+const DefaultLanguage = "en"
+
+// Keys of the user-facing messages in the catalogs. Values are fmt formats.
+const (
+	msgCredentialsMissing    = "credentials_missing"
+	msgNotAuthenticated      = "not_authenticated"
+	msgAlreadyAuthenticated  = "already_authenticated"
+	msgAuthBrowser           = "auth_browser"
+	msgAuthDevice            = "auth_device"
+	msgAuthAutoComplete      = "auth_auto_complete"
+	msgAuthManualComplete    = "auth_manual_complete"
+	msgNoResults             = "no_results"
+	msgNoHistory             = "no_history"
+	msgNoShowFound           = "no_show_found"
+	msgNoMovieFound          = "no_movie_found"
+	msgNoShowWithID          = "no_show_with_id"
+	msgNoMovieWithID         = "no_movie_with_id"
+	msgLoggedEpisode         = "logged_episode"
+	msgLoggedMovie           = "logged_movie"
+	msgAlreadyWatchedEpisode = "already_watched_episode"
+	msgAlreadyWatchedMovie   = "already_watched_movie"
+	msgEpisodeNotAdded       = "episode_not_added"
+	msgMovieNotAdded         = "movie_not_added"
+	msgWriteQueued           = "write_queued"
+	msgRepeatedWrite         = "repeated_write"
+	msgDuplicateEpisode      = "duplicate_episode"
+	msgDuplicateMovie        = "duplicate_movie"
+	msgNothingToWatch        = "nothing_to_watch"
+	msgNothingUpcoming       = "nothing_upcoming"
+	msgNewEpisode            = "new_episode"
+	msgNothingInProgress     = "nothing_in_progress"
+	msgNothingStalled        = "nothing_stalled"
+	msgNoSuchUser            = "no_such_user"
+	msgPrivateProfile        = "private_profile"
+	msgNothingWatched        = "nothing_watched"
+	msgBacklogEmpty          = "backlog_empty"
+	msgRated                 = "rated"
+	msgRatingFailed          = "rating_failed"
+	msgRatingQueued          = "rating_queued"
+	msgUndone                = "undone"
+	msgNothingUndone         = "nothing_undone"
+	msgRepeatedUndo          = "repeated_undo"
+	msgConfirmUndo           = "confirm_undo"
+	msgConfirmInvalid        = "confirm_invalid"
+	msgConfirmRestore        = "confirm_restore"
+	msgConfirmRestoreInvalid = "confirm_restore_invalid"
+	msgError                 = "error"
+	msgRequired              = "required"
+	msgAuthenticated         = "authenticated"
+	msgNoAuthInProgress      = "no_auth_in_progress"
+	msgAuthPending           = "auth_pending"
+	msgAuthSlowDown          = "auth_slow_down"
+	msgAuthCodeInvalid       = "auth_code_invalid"
+	msgAuthDenied            = "auth_denied"
+	msgAuthFailedNotice      = "auth_failed_notice"
+	msgAuthExpiredNotice     = "auth_expired_notice"
+	msgAuthDeniedNotice      = "auth_denied_notice"
+	msgAuthTimedOutNotice    = "auth_timed_out_notice"
+	msgNoRefreshToken        = "no_refresh_token"
+	msgRefreshRejected       = "refresh_rejected"
+	msgRefreshed             = "refreshed"
+	msgTokenExpires          = "token_expires"
+	msgWatchType             = "watch_type"
+	msgShowRequired          = "show_required"
+	msgMovieRequired         = "movie_required"
+	msgEpisodeNumbers        = "episode_numbers"
+	msgAbsoluteNotFound      = "absolute_not_found"
+	msgEpisodeNotFound       = "episode_not_found"
+	msgExportType            = "export_type"
+	msgExportFormat          = "export_format"
+	msgLetterboxdMoviesOnly  = "letterboxd_movies_only"
+	msgFileExists            = "file_exists"
+	msgExported              = "exported"
+	msgExportedShows         = "exported_shows"
+	msgExportedMovies        = "exported_movies"
+	msgExportedLetterboxd    = "exported_letterboxd"
+	msgWatchlistEmpty        = "watchlist_empty"
+	msgCredentialsRejected   = "credentials_rejected"
+	msgRateLimitedWait       = "rate_limited_wait"
+	msgRateLimited           = "rate_limited"
+	msgNotFoundOnTrakt       = "not_found_on_trakt"
+	msgAccountLimit          = "account_limit"
+	msgMoreResults           = "more_results"
+	msgHistoryContinues      = "history_continues"
+	msgWatchlistContinues    = "watchlist_continues"
+	msgRatingRange           = "rating_range"
+	msgNoBackup              = "no_backup"
+)
+
+// catalogs holds the messages for each supported language, keyed by
+// message. English is complete; other languages may leave messages out.
+var catalogs = map[string]map[string]string{
+	"en": {
+		msgCredentialsMissing:   "Error: TRAKT_CLIENT_ID and TRAKT_CLIENT_SECRET environment variables must be set",
+		msgNotAuthenticated:     "Error: Not authenticated. Use the authenticate tool first.",
+		msgAlreadyAuthenticated: "✅ Already authenticated with Trakt.",
+		msgAuthBrowser: `🔐 **Trakt Authentication**
+
+Open this URL in a browser on this machine and approve access:
+%s
+
+You'll be notified when authorization completes.`,
+		msgAuthDevice: `🔐 **Trakt Authentication**
+
+Please visit: %s
+Enter code: **%s**
+
+The code expires in %d seconds.
+
+%s`,
+		msgAuthAutoComplete:      "Authorization will be detected automatically once you approve the code; you'll be notified when it completes.",
+		msgAuthManualComplete:    "After authorizing, call the complete_authentication tool to finish signing in.",
+		msgNoResults:             "No results found for: %s",
+		msgNoHistory:             "No watch history found.",
+		msgNoShowFound:           "No show found for: %s",
+		msgNoMovieFound:          "No movie found for: %s",
+		msgNoShowWithID:          "No show found with %s ID %s",
+		msgNoMovieWithID:         "No movie found with %s ID %s",
+		msgLoggedEpisode:         "✅ Logged: **%s** S%02dE%02d - %s",
+		msgLoggedMovie:           "✅ Logged: **%s** (%d)",
+		msgAlreadyWatchedEpisode: "ℹ️ Already watched: **%s** S%02dE%02d - %s",
+		msgAlreadyWatchedMovie:   "ℹ️ Already watched: **%s** (%d)",
+		msgEpisodeNotAdded:       "⚠️ Episode was not added (unknown reason)",
+		msgMovieNotAdded:         "⚠️ Movie was not added (unknown reason)",
+		msgWriteQueued:           "🕓 Trakt can't be reached right now, so **%s** was queued and will be logged as soon as it's back.",
+		msgRepeatedWrite:         "ℹ️ This was already logged moments ago, so it wasn't logged again.",
+		msgDuplicateEpisode:      "⚠️ **%s** S%02dE%02d was already logged on %s at %s, so it wasn't logged again. If this is another viewing, call log_watch again with force set to true.",
+		msgDuplicateMovie:        "⚠️ **%s** (%d) was already logged on %s at %s, so it wasn't logged again. If this is another viewing, call log_watch again with force set to true.",
+		msgNothingToWatch:        "Nothing to suggest: there are no shows in progress, nothing airing today, and your watchlist and recommendations are empty.",
+		msgNothingUpcoming:       "Nothing new for you: no unwatched episodes of your shows aired in the last %d days or air in the next %d.",
+		msgNewEpisode:            "📺 %s S%02dE%02d just aired.",
+		msgNothingInProgress:     "No shows in progress: every show you've started is either finished or hidden from your progress.",
+		msgNothingStalled:        "No stalled shows: you've watched every show in progress in the last %d days.",
+		msgNoSuchUser:            "No Trakt user found: %s",
+		msgPrivateProfile:        "%s's Trakt profile is private. They can make it public, or you can follow them on Trakt if they approve.",
+		msgNothingWatched:        "Nothing logged in this period yet.",
+		msgBacklogEmpty:          "Nothing left to watch on your watchlist.",
+		msgRated:                 "⭐ Rated %d/10",
+		msgRatingFailed:          "⚠️ The rating wasn't saved (%s). Call rate_and_log again to retry; the watch won't be logged twice.",
+		msgRatingQueued:          "🕓 The %d/10 rating was queued and will be saved once Trakt is reachable.",
+		msgUndone:                "↩️ Removed from your history:\n%s",
+		msgNothingUndone:         "⚠️ Nothing was removed: Trakt couldn't find history entry %d.",
+		msgRepeatedUndo:          "ℹ️ This was undone moments ago, so nothing else was removed. To also remove the entry before it, call undo_last_watch again with force set to true.",
+		msgConfirmUndo:           "⚠️ This will remove from your history:\n%s\nNothing has been removed yet. To go ahead, call undo_last_watch again with confirm set to %q within %d minutes.",
+		msgConfirmInvalid:        "Error: that confirmation token isn't valid. It may have expired or been used, or the latest history entry changed since the preview. Nothing was removed; call undo_last_watch without confirm for a new preview.",
+		msgConfirmRestore:        "⚠️ Nothing has been restored yet. To put all this back, call restore again with the same archive and confirm set to %q within %d minutes.",
+		msgConfirmRestoreInvalid: "Error: that confirmation token isn't valid. It may have expired or been used, or was issued for another backup. Nothing was restored; call restore without confirm for a new preview.",
+		msgError:                 "Error: %s",
+		msgRequired:              "Error: %s is required",
+		msgAuthenticated:         "✅ Authenticated with Trakt. You can now use the other tools.",
+		msgNoAuthInProgress:      "Error: No authentication in progress (or the code expired). Use the authenticate tool first.",
+		msgAuthPending:           "⏳ Waiting for authorization. Visit %s, enter code **%s**, then call complete_authentication again.",
+		msgAuthSlowDown:          "⏳ Polling too quickly. Wait at least %d seconds before calling complete_authentication again.",
+		msgAuthCodeInvalid:       "Error: The authentication code is no longer valid. Use the authenticate tool to get a new one.",
+		msgAuthDenied:            "Error: Authorization was denied on Trakt. Use the authenticate tool to try again.",
+		msgAuthFailedNotice:      "Trakt authentication failed: %s",
+		msgAuthExpiredNotice:     "Trakt authentication code expired. Run authenticate again to get a new code.",
+		msgAuthDeniedNotice:      "Trakt authentication was denied.",
+		msgAuthTimedOutNotice:    "Trakt authorization timed out. Run authenticate again to retry.",
+		msgNoRefreshToken:        "Error: No refresh token available. Use the authenticate tool first.",
+		msgRefreshRejected:       "Error: Trakt rejected the refresh token. Use the authenticate tool to sign in again.",
+		msgRefreshed:             "✅ Refreshed Trakt credentials.",
+		msgTokenExpires:          " The new access token expires on %s.",
+		msgWatchType:             "Error: type must be 'episode' or 'movie'",
+		msgShowRequired:          "Error: showName, or the show's traktId, imdbId or tmdbId, is required for episodes",
+		msgMovieRequired:         "Error: movieName, or the movie's traktId, imdbId or tmdbId, is required for movies",
+		msgEpisodeNumbers:        "Error: season must be >= 0 and episode must be positive, or give a positive absoluteEpisode",
+		msgAbsoluteNotFound:      "Episode %d not found for %s. Please verify the absolute episode number, or give the season and episode instead.",
+		msgEpisodeNotFound:       "Episode S%02dE%02d not found for %s. Please verify the season and episode numbers.",
+		msgExportType:            `Error: type must be "shows" or "movies"`,
+		msgExportFormat:          "Error: format must be %q, %q or %q",
+		msgLetterboxdMoviesOnly:  "Error: letterboxd exports have movies only",
+		msgFileExists:            "Error: %s already exists; pass overwrite to replace it",
+		msgExported:              "💾 Exported %s plays to %s (%s)",
+		msgExportedShows:         "💾 Exported %s show plays to %s (%s)",
+		msgExportedMovies:        "💾 Exported %s movie plays to %s (%s)",
+		msgWatchlistEmpty:        "Your watchlist is empty.",
+		msgCredentialsRejected:   "Error: Trakt rejected the credentials. Use the authenticate tool to sign in again. (%s)",
+		msgRateLimitedWait:       "Error: Trakt rate limit reached. Wait %s before trying again. (%s)",
+		msgRateLimited:           "Error: Trakt rate limit reached. Wait a few minutes before trying again. (%s)",
+		msgNotFoundOnTrakt:       "Error: Not found on Trakt. Check the title or ID, or search for it first. (%s)",
+		msgAccountLimit:          "Error: This Trakt account has reached a limit. Upgrade at %s to continue. (%s)",
+		msgMoreResults:           "Showing %d results (page %d). There may be more; ask for page %d to continue.",
+		msgHistoryContinues:      "Showing items %d-%d of the %d on page %d; the rest didn't fit in one response. Call get_history with cursor %q to continue.",
+		msgWatchlistContinues:    "Showing items %d-%d of %d; the rest didn't fit in one response. Call get_watchlist with cursor %q to continue.",
+		msgRatingRange:           "Error: rating must be a whole number from %d to %d",
+		msgNoBackup:              "Error: %s. Take one with the backup tool first.",
+		msgExportedLetterboxd:    "💾 Exported %s movie diary entries to %s (Letterboxd CSV). Import it at https://letterboxd.com/import/",
+	},
+	"de": {
+		msgCredentialsMissing:   "Fehler: Die Umgebungsvariablen TRAKT_CLIENT_ID und TRAKT_CLIENT_SECRET müssen gesetzt sein",
+		msgNotAuthenticated:     "Fehler: Nicht angemeldet. Verwende zuerst das Tool authenticate.",
+		msgAlreadyAuthenticated: "✅ Bereits bei Trakt angemeldet.",
+		msgAuthBrowser: `🔐 **Trakt-Anmeldung**
+
+Öffne diese URL in einem Browser auf diesem Rechner und erlaube den Zugriff:
+%s
+
+Du wirst benachrichtigt, sobald die Anmeldung abgeschlossen ist.`,
+		msgAuthDevice: `🔐 **Trakt-Anmeldung**
+
+Bitte öffne: %s
+Gib den Code ein: **%s**
+
+Der Code läuft in %d Sekunden ab.
+
+%s`,
+		msgAuthAutoComplete:      "Die Freigabe wird automatisch erkannt, sobald du den Code bestätigst; du wirst benachrichtigt, wenn sie abgeschlossen ist.",
+		msgAuthManualComplete:    "Rufe nach der Freigabe das Tool complete_authentication auf, um die Anmeldung abzuschließen.",
+		msgNoResults:             "Keine Ergebnisse für: %s",
+		msgNoHistory:             "Kein Verlauf gefunden.",
+		msgNoShowFound:           "Keine Serie gefunden für: %s",
+		msgNoMovieFound:          "Kein Film gefunden für: %s",
+		msgNoShowWithID:          "Keine Serie mit der %s-ID %s gefunden",
+		msgNoMovieWithID:         "Kein Film mit der %s-ID %s gefunden",
+		msgLoggedEpisode:         "✅ Eingetragen: **%s** S%02dE%02d - %s",
+		msgLoggedMovie:           "✅ Eingetragen: **%s** (%d)",
+		msgAlreadyWatchedEpisode: "ℹ️ Bereits gesehen: **%s** S%02dE%02d - %s",
+		msgAlreadyWatchedMovie:   "ℹ️ Bereits gesehen: **%s** (%d)",
+		msgEpisodeNotAdded:       "⚠️ Die Folge wurde nicht eingetragen (Grund unbekannt)",
+		msgMovieNotAdded:         "⚠️ Der Film wurde nicht eingetragen (Grund unbekannt)",
+		msgWriteQueued:           "🕓 Trakt ist gerade nicht erreichbar, daher wurde **%s** vorgemerkt und wird eingetragen, sobald Trakt wieder da ist.",
+		msgRepeatedWrite:         "ℹ️ Das wurde gerade eben schon eingetragen und deshalb nicht noch einmal.",
+		msgDuplicateEpisode:      "⚠️ **%s** S%02dE%02d wurde am %s um %s schon eingetragen und deshalb nicht noch einmal. Falls du es noch einmal gesehen hast, rufe log_watch erneut mit force auf true auf.",
+		msgDuplicateMovie:        "⚠️ **%s** (%d) wurde am %s um %s schon eingetragen und deshalb nicht noch einmal. Falls du ihn noch einmal gesehen hast, rufe log_watch erneut mit force auf true auf.",
+		msgNothingToWatch:        "Keine Vorschläge: Du schaust gerade keine Serie, heute läuft nichts Neues, und deine Watchlist und Empfehlungen sind leer.",
+		msgNothingUpcoming:       "Nichts Neues für dich: In den letzten %d Tagen lief keine ungesehene Folge deiner Serien, und in den nächsten %d läuft keine.",
+		msgNewEpisode:            "📺 %s S%02dE%02d ist gerade erschienen.",
+		msgNothingInProgress:     "Keine angefangenen Serien: Jede Serie, die du begonnen hast, ist entweder fertig oder in deinem Fortschritt ausgeblendet.",
+		msgNothingStalled:        "Keine liegengebliebenen Serien: Du hast jede angefangene Serie in den letzten %d Tagen geschaut.",
+		msgNoSuchUser:            "Kein Trakt-Nutzer gefunden: %s",
+		msgPrivateProfile:        "Das Trakt-Profil von %s ist privat. Es kann öffentlich gemacht werden, oder du folgst der Person auf Trakt, wenn sie zustimmt.",
+		msgNothingWatched:        "In diesem Zeitraum wurde noch nichts eingetragen.",
+		msgBacklogEmpty:          "Auf deiner Watchlist ist nichts mehr zu schauen.",
+		msgRated:                 "⭐ Mit %d/10 bewertet",
+		msgRatingFailed:          "⚠️ Die Bewertung wurde nicht gespeichert (%s). Rufe rate_and_log erneut auf; der Eintrag wird nicht doppelt angelegt.",
+		msgRatingQueued:          "🕓 Die Bewertung %d/10 wurde vorgemerkt und wird gespeichert, sobald Trakt erreichbar ist.",
+		msgUndone:                "↩️ Aus deinem Verlauf entfernt:\n%s",
+		msgNothingUndone:         "⚠️ Nichts entfernt: Trakt hat den Verlaufseintrag %d nicht gefunden.",
+		msgRepeatedUndo:          "ℹ️ Das wurde gerade eben schon rückgängig gemacht, deshalb wurde nichts weiter entfernt. Um auch den Eintrag davor zu entfernen, rufe undo_last_watch erneut mit force auf true auf.",
+		msgConfirmUndo:           "⚠️ Damit wird aus deinem Verlauf entfernt:\n%s\nNoch wurde nichts entfernt. Um fortzufahren, rufe undo_last_watch erneut mit confirm auf %q auf, spätestens in %d Minuten.",
+		msgConfirmInvalid:        "Fehler: Dieser Bestätigungscode ist nicht gültig. Er ist abgelaufen oder wurde schon benutzt, oder der neueste Verlaufseintrag hat sich seit der Vorschau geändert. Es wurde nichts entfernt; rufe undo_last_watch ohne confirm für eine neue Vorschau auf.",
+		msgConfirmRestore:        "⚠️ Noch wurde nichts wiederhergestellt. Um all das zurückzuholen, rufe restore erneut mit demselben Archiv und confirm auf %q auf, spätestens in %d Minuten.",
+		msgConfirmRestoreInvalid: "Fehler: Dieser Bestätigungscode ist nicht gültig. Er ist abgelaufen oder wurde schon benutzt, oder er gilt für eine andere Sicherung. Es wurde nichts wiederhergestellt; rufe restore ohne confirm für eine neue Vorschau auf.",
+		msgError:                 "Fehler: %s",
+		msgRequired:              "Fehler: %s ist erforderlich",
+		msgAuthenticated:         "✅ Bei Trakt angemeldet. Du kannst jetzt die anderen Tools verwenden.",
+		msgNoAuthInProgress:      "Fehler: Es läuft keine Anmeldung (oder der Code ist abgelaufen). Verwende zuerst das Tool authenticate.",
+		msgAuthPending:           "⏳ Warte auf die Freigabe. Öffne %s, gib den Code **%s** ein und rufe dann complete_authentication erneut auf.",
+		msgAuthSlowDown:          "⏳ Zu häufig abgefragt. Warte mindestens %d Sekunden, bevor du complete_authentication erneut aufrufst.",
+		msgAuthCodeInvalid:       "Fehler: Der Anmeldecode ist nicht mehr gültig. Verwende das Tool authenticate, um einen neuen zu bekommen.",
+		msgAuthDenied:            "Fehler: Die Freigabe wurde auf Trakt abgelehnt. Verwende das Tool authenticate, um es erneut zu versuchen.",
+		msgAuthFailedNotice:      "Die Trakt-Anmeldung ist fehlgeschlagen: %s",
+		msgAuthExpiredNotice:     "Der Trakt-Anmeldecode ist abgelaufen. Führe authenticate erneut aus, um einen neuen Code zu bekommen.",
+		msgAuthDeniedNotice:      "Die Trakt-Anmeldung wurde abgelehnt.",
+		msgAuthTimedOutNotice:    "Die Trakt-Freigabe hat zu lange gedauert. Führe authenticate erneut aus, um es noch einmal zu versuchen.",
+		msgNoRefreshToken:        "Fehler: Kein Refresh-Token vorhanden. Verwende zuerst das Tool authenticate.",
+		msgRefreshRejected:       "Fehler: Trakt hat das Refresh-Token abgelehnt. Verwende das Tool authenticate, um dich neu anzumelden.",
+		msgRefreshed:             "✅ Trakt-Zugangsdaten erneuert.",
+		msgTokenExpires:          " Das neue Zugriffstoken läuft am %s ab.",
+		msgWatchType:             "Fehler: type muss 'episode' oder 'movie' sein",
+		msgShowRequired:          "Fehler: Für Folgen ist showName oder die traktId, imdbId oder tmdbId der Serie erforderlich",
+		msgMovieRequired:         "Fehler: Für Filme ist movieName oder die traktId, imdbId oder tmdbId des Films erforderlich",
+		msgEpisodeNumbers:        "Fehler: season muss >= 0 und episode positiv sein, oder gib eine positive absoluteEpisode an",
+		msgAbsoluteNotFound:      "Folge %d von %s nicht gefunden. Bitte prüfe die absolute Folgennummer, oder gib stattdessen Staffel und Folge an.",
+		msgEpisodeNotFound:       "Folge S%02dE%02d von %s nicht gefunden. Bitte prüfe Staffel- und Folgennummer.",
+		msgExportType:            `Fehler: type muss "shows" oder "movies" sein`,
+		msgExportFormat:          "Fehler: format muss %q, %q oder %q sein",
+		msgLetterboxdMoviesOnly:  "Fehler: Letterboxd-Exporte enthalten nur Filme",
+		msgFileExists:            "Fehler: %s existiert bereits; gib overwrite an, um die Datei zu ersetzen",
+		msgExported:              "💾 %s Einträge nach %s exportiert (%s)",
+		msgExportedShows:         "💾 %s Serieneinträge nach %s exportiert (%s)",
+		msgExportedMovies:        "💾 %s Filmeinträge nach %s exportiert (%s)",
+		msgWatchlistEmpty:        "Deine Watchlist ist leer.",
+		msgCredentialsRejected:   "Fehler: Trakt hat die Zugangsdaten abgelehnt. Melde dich mit dem Tool authenticate erneut an. (%s)",
+		msgRateLimitedWait:       "Fehler: Trakt-Ratenlimit erreicht. Warte %s, bevor du es erneut versuchst. (%s)",
+		msgRateLimited:           "Fehler: Trakt-Ratenlimit erreicht. Warte ein paar Minuten, bevor du es erneut versuchst. (%s)",
+		msgNotFoundOnTrakt:       "Fehler: Auf Trakt nicht gefunden. Prüfe den Titel oder die ID, oder suche zuerst danach. (%s)",
+		msgAccountLimit:          "Fehler: Dieses Trakt-Konto hat ein Limit erreicht. Upgrade unter %s, um fortzufahren. (%s)",
+		msgMoreResults:           "%d Ergebnisse (Seite %d). Es gibt vielleicht mehr; frag nach Seite %d, um fortzufahren.",
+		msgHistoryContinues:      "Einträge %d-%d von %d auf Seite %d; der Rest passte nicht in eine Antwort. Rufe get_history mit cursor %q auf, um fortzufahren.",
+		msgWatchlistContinues:    "Einträge %d-%d von %d; der Rest passte nicht in eine Antwort. Rufe get_watchlist mit cursor %q auf, um fortzufahren.",
+		msgRatingRange:           "Fehler: Die Bewertung muss eine ganze Zahl von %d bis %d sein",
+		msgNoBackup:              "Fehler: %s. Erstelle zuerst eine mit dem Tool backup.",
+		msgExportedLetterboxd:    "💾 %s Filmtagebuch-Einträge nach %s exportiert (Letterboxd-CSV). Importiere sie unter https://letterboxd.com/import/",
+	},
+	"es": {
+		msgCredentialsMissing:   "Error: hay que definir las variables de entorno TRAKT_CLIENT_ID y TRAKT_CLIENT_SECRET",
+		msgNotAuthenticated:     "Error: no has iniciado sesión. Usa primero la herramienta authenticate.",
+		msgAlreadyAuthenticated: "✅ Ya has iniciado sesión en Trakt.",
+		msgAuthBrowser: `🔐 **Inicio de sesión en Trakt**
+
+Abre esta URL en un navegador de este equipo y autoriza el acceso:
+%s
+
+Recibirás un aviso cuando se complete la autorización.`,
+		msgAuthDevice: `🔐 **Inicio de sesión en Trakt**
+
+Visita: %s
+Introduce el código: **%s**
+
+El código caduca en %d segundos.
+
+%s`,
+		msgAuthAutoComplete:      "La autorización se detectará automáticamente cuando apruebes el código; recibirás un aviso cuando se complete.",
+		msgAuthManualComplete:    "Después de autorizar, llama a la herramienta complete_authentication para terminar de iniciar sesión.",
+		msgNoResults:             "No hay resultados para: %s",
+		msgNoHistory:             "No se encontró historial.",
+		msgNoShowFound:           "No se encontró ninguna serie para: %s",
+		msgNoMovieFound:          "No se encontró ninguna película para: %s",
+		msgNoShowWithID:          "No se encontró ninguna serie con el ID de %s %s",
+		msgNoMovieWithID:         "No se encontró ninguna película con el ID de %s %s",
+		msgLoggedEpisode:         "✅ Registrado: **%s** S%02dE%02d - %s",
+		msgLoggedMovie:           "✅ Registrada: **%s** (%d)",
+		msgAlreadyWatchedEpisode: "ℹ️ Ya vista: **%s** S%02dE%02d - %s",
+		msgAlreadyWatchedMovie:   "ℹ️ Ya vista: **%s** (%d)",
+		msgEpisodeNotAdded:       "⚠️ No se registró el episodio (motivo desconocido)",
+		msgMovieNotAdded:         "⚠️ No se registró la película (motivo desconocido)",
+		msgWriteQueued:           "🕓 No se puede conectar con Trakt ahora mismo, así que **%s** quedó en cola y se registrará en cuanto vuelva.",
+		msgRepeatedWrite:         "ℹ️ Esto ya se registró hace un momento, así que no se registró otra vez.",
+		msgDuplicateEpisode:      "⚠️ **%s** S%02dE%02d ya se registró el %s a las %s, así que no se registró otra vez. Si es otro visionado, vuelve a llamar a log_watch con force en true.",
+		msgDuplicateMovie:        "⚠️ **%s** (%d) ya se registró el %s a las %s, así que no se registró otra vez. Si es otro visionado, vuelve a llamar a log_watch con force en true.",
+		msgNothingToWatch:        "No hay sugerencias: no tienes series a medias, hoy no se estrena nada y tu watchlist y tus recomendaciones están vacías.",
+		msgNothingUpcoming:       "Nada nuevo para ti: ningún episodio sin ver de tus series se emitió en los últimos %d días ni se emite en los próximos %d.",
+		msgNewEpisode:            "📺 Acaba de emitirse %s S%02dE%02d.",
+		msgNothingInProgress:     "No tienes series a medias: todas las que empezaste están terminadas u ocultas en tu progreso.",
+		msgNothingStalled:        "No hay series estancadas: has visto todas tus series a medias en los últimos %d días.",
+		msgNoSuchUser:            "No se encontró ningún usuario de Trakt: %s",
+		msgPrivateProfile:        "El perfil de Trakt de %s es privado. Puede hacerlo público, o puedes seguirle en Trakt si lo aprueba.",
+		msgNothingWatched:        "Todavía no hay nada registrado en este periodo.",
+		msgBacklogEmpty:          "No queda nada por ver en tu lista de seguimiento.",
+		msgRated:                 "⭐ Valorada con %d/10",
+		msgRatingFailed:          "⚠️ No se guardó la valoración (%s). Vuelve a llamar a rate_and_log para reintentarlo; no se registrará dos veces.",
+		msgRatingQueued:          "🕓 La valoración %d/10 quedó en cola y se guardará en cuanto Trakt esté disponible.",
+		msgUndone:                "↩️ Eliminado de tu historial:\n%s",
+		msgNothingUndone:         "⚠️ No se eliminó nada: Trakt no encontró la entrada de historial %d.",
+		msgRepeatedUndo:          "ℹ️ Esto ya se deshizo hace un momento, así que no se eliminó nada más. Para eliminar también la entrada anterior, vuelve a llamar a undo_last_watch con force en true.",
+		msgConfirmUndo:           "⚠️ Esto eliminará de tu historial:\n%s\nTodavía no se ha eliminado nada. Para continuar, vuelve a llamar a undo_last_watch con confirm en %q en los próximos %d minutos.",
+		msgConfirmInvalid:        "Error: ese código de confirmación no es válido. Puede haber caducado o ya se usó, o la última entrada del historial cambió desde la vista previa. No se eliminó nada; llama a undo_last_watch sin confirm para obtener una nueva vista previa.",
+		msgConfirmRestore:        "⚠️ Todavía no se ha restaurado nada. Para recuperar todo esto, vuelve a llamar a restore con el mismo archivo y confirm en %q en los próximos %d minutos.",
+		msgConfirmRestoreInvalid: "Error: ese código de confirmación no es válido. Puede haber caducado o ya se usó, o se emitió para otra copia de seguridad. No se restauró nada; llama a restore sin confirm para obtener una nueva vista previa.",
+		msgError:                 "Error: %s",
+		msgRequired:              "Error: %s es obligatorio",
+		msgAuthenticated:         "✅ Has iniciado sesión en Trakt. Ya puedes usar las demás herramientas.",
+		msgNoAuthInProgress:      "Error: no hay ningún inicio de sesión en curso (o el código caducó). Usa primero la herramienta authenticate.",
+		msgAuthPending:           "⏳ Esperando la autorización. Visita %s, introduce el código **%s** y vuelve a llamar a complete_authentication.",
+		msgAuthSlowDown:          "⏳ Consultas demasiado seguidas. Espera al menos %d segundos antes de volver a llamar a complete_authentication.",
+		msgAuthCodeInvalid:       "Error: el código de inicio de sesión ya no es válido. Usa la herramienta authenticate para obtener uno nuevo.",
+		msgAuthDenied:            "Error: se denegó la autorización en Trakt. Usa la herramienta authenticate para intentarlo de nuevo.",
+		msgAuthFailedNotice:      "Falló el inicio de sesión en Trakt: %s",
+		msgAuthExpiredNotice:     "El código de inicio de sesión de Trakt caducó. Vuelve a ejecutar authenticate para obtener uno nuevo.",
+		msgAuthDeniedNotice:      "Se denegó el inicio de sesión en Trakt.",
+		msgAuthTimedOutNotice:    "Se agotó el tiempo de la autorización de Trakt. Vuelve a ejecutar authenticate para reintentarlo.",
+		msgNoRefreshToken:        "Error: no hay ningún token de actualización. Usa primero la herramienta authenticate.",
+		msgRefreshRejected:       "Error: Trakt rechazó el token de actualización. Usa la herramienta authenticate para volver a iniciar sesión.",
+		msgRefreshed:             "✅ Credenciales de Trakt renovadas.",
+		msgTokenExpires:          " El nuevo token de acceso caduca el %s.",
+		msgWatchType:             "Error: type debe ser 'episode' o 'movie'",
+		msgShowRequired:          "Error: para episodios hace falta showName, o el traktId, imdbId o tmdbId de la serie",
+		msgMovieRequired:         "Error: para películas hace falta movieName, o el traktId, imdbId o tmdbId de la película",
+		msgEpisodeNumbers:        "Error: season debe ser >= 0 y episode positivo, o indica un absoluteEpisode positivo",
+		msgAbsoluteNotFound:      "No se encontró el episodio %d de %s. Comprueba el número absoluto del episodio, o indica la temporada y el episodio.",
+		msgEpisodeNotFound:       "No se encontró el episodio S%02dE%02d de %s. Comprueba los números de temporada y episodio.",
+		msgExportType:            `Error: type debe ser "shows" o "movies"`,
+		msgExportFormat:          "Error: format debe ser %q, %q o %q",
+		msgLetterboxdMoviesOnly:  "Error: las exportaciones de Letterboxd solo incluyen películas",
+		msgFileExists:            "Error: %s ya existe; indica overwrite para reemplazarlo",
+		msgExported:              "💾 Se exportaron %s visionados a %s (%s)",
+		msgExportedShows:         "💾 Se exportaron %s visionados de series a %s (%s)",
+		msgExportedMovies:        "💾 Se exportaron %s visionados de películas a %s (%s)",
+		msgWatchlistEmpty:        "Tu lista de seguimiento está vacía.",
+		msgCredentialsRejected:   "Error: Trakt rechazó las credenciales. Usa la herramienta authenticate para volver a iniciar sesión. (%s)",
+		msgRateLimitedWait:       "Error: se alcanzó el límite de solicitudes de Trakt. Espera %s antes de volver a intentarlo. (%s)",
+		msgRateLimited:           "Error: se alcanzó el límite de solicitudes de Trakt. Espera unos minutos antes de volver a intentarlo. (%s)",
+		msgNotFoundOnTrakt:       "Error: no se encontró en Trakt. Revisa el título o el ID, o búscalo primero. (%s)",
+		msgAccountLimit:          "Error: esta cuenta de Trakt alcanzó un límite. Mejórala en %s para continuar. (%s)",
+		msgMoreResults:           "Mostrando %d resultados (página %d). Puede haber más; pide la página %d para continuar.",
+		msgHistoryContinues:      "Mostrando los elementos %d-%d de los %d de la página %d; el resto no cabía en una respuesta. Llama a get_history con cursor %q para continuar.",
+		msgWatchlistContinues:    "Mostrando los elementos %d-%d de %d; el resto no cabía en una respuesta. Llama a get_watchlist con cursor %q para continuar.",
+		msgRatingRange:           "Error: la calificación debe ser un número entero de %d a %d",
+		msgNoBackup:              "Error: %s. Haz una primero con la herramienta backup.",
+		msgExportedLetterboxd:    "💾 Se exportaron %s entradas del diario de películas a %s (CSV de Letterboxd). Impórtalo en https://letterboxd.com/import/",
+	},
+}
+
+// Languages returns the supported language codes, sorted.
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// ParseLanguage converts a setting value such as "de" or "de_DE.UTF-8" to a
+// supported language code. An empty value is DefaultLanguage.
+func ParseLanguage(s string) (string, error) {
+	if s == "" {
+		return DefaultLanguage, nil
+	}
+	lang := strings.ToLower(s)
+	if i := strings.IndexAny(lang, "-_."); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; !ok {
+		return DefaultLanguage, fmt.Errorf("unsupported language %q (want one of %s)", s, strings.Join(Languages(), ", "))
+	}
+	return lang, nil
+}
+
+// SetLanguage selects the language of user-facing tool and resource text.
+// The language should come from ParseLanguage.
+func (s *Server) SetLanguage(lang string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.language = lang
+}
+
+// Language returns the configured output language.
+func (s *Server) Language() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.language == "" {
+		return DefaultLanguage
+	}
+	return s.language
+}
+
+type languageKey struct{}
+
+// withLanguage returns a context that carries the output language to
+// handlers.
+func withLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// msg formats the message key in the context's language, falling back to
+// English when the language doesn't translate it.
+func msg(ctx context.Context, key string, args ...any) string {
+	lang, _ := ctx.Value(languageKey{}).(string)
+	format, ok := catalogs[lang][key]
+	if !ok {
+		format = catalogs[DefaultLanguage][key]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
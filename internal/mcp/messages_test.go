@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// formatVerb matches the fmt verbs in a message, ignoring %%.
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*[a-z]`)
+
+func TestCatalogs_MatchEnglish(t *testing.T) {
+	english := catalogs[DefaultLanguage]
+	for lang, catalog := range catalogs {
+		for key, format := range catalog {
+			want, ok := english[key]
+			if !ok {
+				t.Errorf("%s: %s has no English message", lang, key)
+				continue
+			}
+			// Translations must take the same arguments in the same order
+			got, wantVerbs := formatVerb.FindAllString(format, -1), formatVerb.FindAllString(want, -1)
+			if len(got) != len(wantVerbs) {
+				t.Errorf("%s: %s has verbs %v, English has %v", lang, key, got, wantVerbs)
+				continue
+			}
+			for i := range got {
+				if got[i] != wantVerbs[i] {
+					t.Errorf("%s: %s has verbs %v, English has %v", lang, key, got, wantVerbs)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "en", false},
+		{"de", "de", false},
+		{"de_DE.UTF-8", "de", false},
+		{"es-MX", "es", false},
+		{"EN", "en", false},
+		{"tlh", "en", true},
+	}
+	for _, tc := range tests {
+		got, err := ParseLanguage(tc.in)
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("ParseLanguage(%q) = %q, %v", tc.in, got, err)
+		}
+	}
+}
+
+func TestMsg_FallsBackToEnglish(t *testing.T) {
+	ctx := withLanguage(context.Background(), "de")
+	if got := msg(ctx, msgNoResults, "Dark"); got != "Keine Ergebnisse für: Dark" {
+		t.Errorf("msg() = %q", got)
+	}
+
+	delete(catalogs["de"], msgNoHistory)
+	t.Cleanup(func() { catalogs["de"][msgNoHistory] = "Kein Verlauf gefunden." })
+	if got := msg(ctx, msgNoHistory); got != "No watch history found." {
+		t.Errorf("expected the English message for an untranslated key, got %q", got)
+	}
+	if got := msg(context.Background(), msgNoHistory); got != "No watch history found." {
+		t.Errorf("expected English without a language, got %q", got)
+	}
+}
+
+func TestErrorContent_Localized(t *testing.T) {
+	ctx := withLanguage(context.Background(), "es")
+	result := ErrorContent(ctx, &trakt.APIError{StatusCode: 404})
+	if got := result.Content[0].Text; !strings.HasPrefix(got, "Error: no se encontró en Trakt.") {
+		t.Errorf("expected the Spanish advice, got %q", got)
+	}
+}
+
+func TestServer_LanguageReachesHandlers(t *testing.T) {
+	server := NewServer(nil)
+	server.initialized = true
+	server.SetLanguage("es")
+	RegisterTools(server, &fakeTrakt{})
+
+	result, err := server.handleToolsCall(context.Background(), json.RawMessage(`{"name":"get_history","arguments":{}}`))
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	if got := result.Content[0].Text; got != catalogs["es"][msgNotAuthenticated] {
+		t.Errorf("expected the Spanish message, got %q", got)
+	}
+}
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Metrics counts tool calls and Trakt API requests, exposed in the
+// Prometheus text format by the admin endpoint.
+type Metrics struct {
+	mu          sync.Mutex
+	toolCalls   map[toolOutcome]uint64
+	toolSeconds map[string]float64
+	apiRequests map[string]uint64 // by status code, "error" for transport failures
+	toolErrors  []toolError       // most recent failed calls, oldest first
+}
+
+// maxRecentToolErrors bounds how many failed calls are remembered for
+// server_status.
+const maxRecentToolErrors = 100
+
+type toolError struct {
+	tool string
+	at   time.Time
+}
+
+type toolOutcome struct {
+	tool    string
+	outcome string // "ok" or "error"
+}
+
+func newMetrics() *Metrics {
+	return &Metrics{
+		toolCalls:   make(map[toolOutcome]uint64),
+		toolSeconds: make(map[string]float64),
+		apiRequests: make(map[string]uint64),
+	}
+}
+
+// observeToolCall records one finished tool call.
+func (m *Metrics) observeToolCall(tool string, failed bool, elapsed time.Duration) {
+	outcome := "ok"
+	if failed {
+		outcome = "error"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.toolCalls[toolOutcome{tool, outcome}]++
+	m.toolSeconds[tool] += elapsed.Seconds()
+	if failed {
+		if len(m.toolErrors) == maxRecentToolErrors {
+			m.toolErrors = m.toolErrors[1:]
+		}
+		m.toolErrors = append(m.toolErrors, toolError{tool: tool, at: time.Now()})
+	}
+}
+
+// recentToolErrors counts the remembered failed calls made after since, by tool.
+func (m *Metrics) recentToolErrors(since time.Time) map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]int)
+	for _, e := range m.toolErrors {
+		if e.at.After(since) {
+			counts[e.tool]++
+		}
+	}
+	return counts
+}
+
+// apiErrors counts failed Trakt API requests by status: 4xx and 5xx
+// responses, and "error" for requests that got no response.
+func (m *Metrics) apiErrors() map[string]uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]uint64)
+	for status, n := range m.apiRequests {
+		if code, err := strconv.Atoi(status); err != nil || code >= 400 {
+			counts[status] = n
+		}
+	}
+	return counts
+}
+
+// TraktMiddleware returns client middleware that counts API requests by
+// response status.
+func (m *Metrics) TraktMiddleware() trakt.Middleware {
+	return func(next trakt.RoundTripFunc) trakt.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			status := "error"
+			if err == nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			m.mu.Lock()
+			m.apiRequests[status]++
+			m.mu.Unlock()
+			return resp, err
+		}
+	}
+}
+
+// write renders the metrics, plus the client's rate-limit budget, in the
+// Prometheus text exposition format. Label values are quoted with %q, which
+// escapes backslashes, quotes and newlines the way the format expects.
+func (m *Metrics) write(w io.Writer, client TraktAPI) {
+	m.mu.Lock()
+	calls := make([]toolOutcome, 0, len(m.toolCalls))
+	for k := range m.toolCalls {
+		calls = append(calls, k)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].tool != calls[j].tool {
+			return calls[i].tool < calls[j].tool
+		}
+		return calls[i].outcome < calls[j].outcome
+	})
+
+	metric(w, "trakt_mcp_tool_calls_total", "counter", "Tool calls by tool and outcome.")
+	for _, k := range calls {
+		fmt.Fprintf(w, "trakt_mcp_tool_calls_total{tool=%q,outcome=%q} %d\n", k.tool, k.outcome, m.toolCalls[k])
+	}
+
+	metric(w, "trakt_mcp_tool_call_seconds_total", "counter", "Time spent in tool calls.")
+	for _, tool := range sortedKeys(m.toolSeconds) {
+		fmt.Fprintf(w, "trakt_mcp_tool_call_seconds_total{tool=%q} %g\n", tool, m.toolSeconds[tool])
+	}
+
+	metric(w, "trakt_api_requests_total", "counter", "Trakt API requests by response status.")
+	for _, status := range sortedKeys(m.apiRequests) {
+		fmt.Fprintf(w, "trakt_api_requests_total{status=%q} %d\n", status, m.apiRequests[status])
+	}
+	m.mu.Unlock()
+
+	limits := client.RateLimits()
+	metric(w, "trakt_api_ratelimit_remaining", "gauge", "Requests left in each Trakt rate-limit window.")
+	for _, rl := range limits {
+		fmt.Fprintf(w, "trakt_api_ratelimit_remaining{limit=%q} %d\n", rl.Name, rl.Remaining)
+	}
+	metric(w, "trakt_api_ratelimit_limit", "gauge", "Size of each Trakt rate-limit window.")
+	for _, rl := range limits {
+		fmt.Fprintf(w, "trakt_api_ratelimit_limit{limit=%q} %d\n", rl.Name, rl.Limit)
+	}
+
+	b := CurrentBuild()
+	metric(w, "trakt_mcp_build_info", "gauge", "Build metadata of the running server.")
+	fmt.Fprintf(w, "trakt_mcp_build_info{version=%q,commit=%q} 1\n", b.Version, b.Commit)
+}
+
+func metric(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mirror"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// mirroredTrakt serves the user's history, ratings and watchlist from a
+// local mirror, refreshing it first if it's due, and everything else from
+// the API. Until the mirror has synced once, or if it can't be refreshed
+// and has never synced, reads go to the API too.
+type mirroredTrakt struct {
+	TraktAPI
+	mirror *mirror.Mirror
+}
+
+// Mirrored returns client with its bulk reads served from m. Writes go to
+// the API and make m check for changes on the next read.
+func Mirrored(client TraktAPI, m *mirror.Mirror) TraktAPI {
+	return &mirroredTrakt{TraktAPI: client, mirror: m}
+}
+
+// ready refreshes the mirror and reports whether to read from it. A
+// failed refresh leaves the last copy in use.
+func (c *mirroredTrakt) ready(ctx context.Context) bool {
+	_ = c.mirror.Refresh(ctx)
+	return c.mirror.Synced()
+}
+
+func (c *mirroredTrakt) ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error {
+	if !c.ready(ctx) {
+		return c.TraktAPI.ForEachHistoryItem(ctx, historyType, fn, opts...)
+	}
+	return c.mirror.ForEachHistoryItem(ctx, historyType, fn, opts...)
+}
+
+func (c *mirroredTrakt) GetUserRatings(ctx context.Context, user, ratingType string) ([]trakt.Rating, error) {
+	if user != "me" || !c.ready(ctx) {
+		return c.TraktAPI.GetUserRatings(ctx, user, ratingType)
+	}
+	return c.mirror.Ratings(ratingType), nil
+}
+
+func (c *mirroredTrakt) GetWatchlist(ctx context.Context, watchlistType string, opts ...trakt.RequestOption) ([]trakt.WatchlistItem, error) {
+	if !c.ready(ctx) {
+		return c.TraktAPI.GetWatchlist(ctx, watchlistType, opts...)
+	}
+	return c.mirror.Watchlist(watchlistType), nil
+}
+
+func (c *mirroredTrakt) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	defer c.mirror.Invalidate()
+	return c.TraktAPI.AddToHistory(ctx, item)
+}
+
+func (c *mirroredTrakt) RemoveFromHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	defer c.mirror.Invalidate()
+	return c.TraktAPI.RemoveFromHistory(ctx, item)
+}
+
+func (c *mirroredTrakt) ScrobbleStop(ctx context.Context, s trakt.Scrobble) (*trakt.ScrobbleResponse, error) {
+	defer c.mirror.Invalidate()
+	return c.TraktAPI.ScrobbleStop(ctx, s)
+}
+
+func (c *mirroredTrakt) AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error) {
+	defer c.mirror.Invalidate()
+	return c.TraktAPI.AddRatings(ctx, item)
+}
+
+func (c *mirroredTrakt) AddToWatchlist(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error) {
+	defer c.mirror.Invalidate()
+	return c.TraktAPI.AddToWatchlist(ctx, items)
+}
+
+func (c *mirroredTrakt) AddToCollection(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error) {
+	defer c.mirror.Invalidate()
+	return c.TraktAPI.AddToCollection(ctx, items)
+}
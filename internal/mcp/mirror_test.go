@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mirror"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestMirrored_ReadsFromMirror(t *testing.T) {
+	requests := make(map[string]int)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sync/last_activities":
+			_, _ = w.Write([]byte(`{"all":"2026-03-02T20:00:00.000Z"}`))
+		case "/sync/history":
+			w.Header().Set("X-Pagination-Page", "1")
+			w.Header().Set("X-Pagination-Page-Count", "1")
+			_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{
+				{ID: 1, Type: "movie", WatchedAt: time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC), Movie: &trakt.Movie{Title: "Dune"}},
+			})
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	m, err := mirror.Open(t.TempDir(), client, nil)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	api := Mirrored(client, m)
+
+	for i := 0; i < 2; i++ {
+		plays := 0
+		err := api.ForEachHistoryItem(context.Background(), "", func(trakt.HistoryItem) error {
+			plays++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ForEachHistoryItem failed: %v", err)
+		}
+		if plays != 1 {
+			t.Errorf("expected 1 play, got %d", plays)
+		}
+	}
+
+	if requests["/sync/history"] != 1 || requests["/sync/last_activities"] != 1 {
+		t.Errorf("expected the second read to be served from the mirror, got requests %v", requests)
+	}
+}
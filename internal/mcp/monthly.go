@@ -0,0 +1,190 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Sizes of the lists in a monthly report.
+const (
+	monthlyTopShows = 3
+	monthlyMovies   = 10
+)
+
+// monthStart returns the local midnight starting the month containing t.
+func monthStart(t time.Time) time.Time {
+	y, m, _ := t.Local().Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.Local)
+}
+
+// monthlyReport recaps the month starting at start: what the user watched,
+// the shows they started and finished, and the ratings they gave. A month
+// still under way is reported up to now.
+func monthlyReport(ctx context.Context, client TraktAPI, start, now time.Time) (string, error) {
+	end := start.AddDate(0, 1, 0)
+	if end.After(now) {
+		end = now
+	}
+	history, err := historyBetween(ctx, client, "", start, end)
+	if err != nil {
+		return "", err
+	}
+	started, finished, err := showMilestones(ctx, client, start, end)
+	if err != nil {
+		return "", err
+	}
+	ratings, err := client.GetUserRatings(ctx, "me", "")
+	if err != nil {
+		return "", err
+	}
+	var rated []trakt.Rating
+	for _, r := range ratings {
+		if !r.RatedAt.Before(start) && r.RatedAt.Before(end) {
+			rated = append(rated, r)
+		}
+	}
+
+	if len(history) == 0 && len(rated) == 0 {
+		return msg(ctx, msgNothingWatched), nil
+	}
+	return formatMonthlyReport(history, started, finished, rated, start, now), nil
+}
+
+// showMilestones finds the shows the user started and finished between
+// start and end, from the watched list. A show was started if the
+// earliest of its episodes' last plays falls in the period, so a show
+// rewatched in full counts again; it was finished if every aired episode
+// is watched and the last play falls in the period.
+func showMilestones(ctx context.Context, client TraktAPI, start, end time.Time) (started, finished []*trakt.Show, err error) {
+	in := func(t time.Time) bool { return !t.Before(start) && t.Before(end) }
+	err = client.ForEachWatched(ctx, "shows", func(e trakt.WatchedEntry) error {
+		if e.Show == nil || !in(e.LastWatchedAt) {
+			return nil
+		}
+		var first time.Time
+		for _, s := range e.Seasons {
+			for _, ep := range s.Episodes {
+				if first.IsZero() || ep.LastWatchedAt.Before(first) {
+					first = ep.LastWatchedAt
+				}
+			}
+		}
+		if in(first) {
+			started = append(started, e.Show)
+		}
+		if aired := e.Show.AiredEpisodes; aired > 0 && watchedEpisodes(e) >= aired {
+			finished = append(finished, e.Show)
+		}
+		return nil
+	}, trakt.WithExtended(trakt.ExtendedFull))
+	return started, finished, err
+}
+
+// formatMonthlyReport renders the report as a Markdown list, to paste into
+// a journal as is.
+func formatMonthlyReport(history []trakt.HistoryItem, started, finished []*trakt.Show, rated []trakt.Rating, start, now time.Time) string {
+	wt := sumWatchTime(history)
+
+	var sb strings.Builder
+	if monthStart(now).Equal(start) {
+		sb.WriteString(fmt.Sprintf("🗓️ **%s so far**\n\n", start.Format("January 2006")))
+	} else {
+		sb.WriteString(fmt.Sprintf("🗓️ **%s**\n\n", start.Format("January 2006")))
+	}
+
+	sb.WriteString(fmt.Sprintf("- Watched: %s episodes of %s shows and %s movies, %s in all\n",
+		formatCount(wt.episodes), formatCount(len(wt.shows)), formatCount(wt.movies), formatMinutes(wt.showMinutes+wt.movieMinutes)))
+	sb.WriteString(fmt.Sprintf("- Started: %s\n", showTitles(started)))
+	sb.WriteString(fmt.Sprintf("- Finished: %s\n", showTitles(finished)))
+
+	if len(rated) > 0 {
+		sum := 0
+		for _, r := range rated {
+			sum += r.Rating
+		}
+		sb.WriteString(fmt.Sprintf("- Ratings given: %s, averaging %.1f/10\n", formatCount(len(rated)), float64(sum)/float64(len(rated))))
+	} else {
+		sb.WriteString("- Ratings given: none\n")
+	}
+
+	if len(wt.shows) > 0 {
+		var top []string
+		for _, st := range wt.shows[:min(len(wt.shows), monthlyTopShows)] {
+			top = append(top, fmt.Sprintf("%s (%d episodes, %s)", st.show.Title, st.episodes, formatMinutes(st.minutes)))
+		}
+		sb.WriteString(fmt.Sprintf("- Most watched: %s\n", strings.Join(top, ", ")))
+	}
+
+	var movies []string
+	for _, h := range history {
+		if h.Movie != nil {
+			movies = append(movies, h.Movie.Title+yearSuffix(h.Movie.Year))
+		}
+	}
+	if len(movies) > monthlyMovies {
+		movies = append(movies[:monthlyMovies], fmt.Sprintf("and %d more", len(movies)-monthlyMovies))
+	}
+	if len(movies) > 0 {
+		sb.WriteString(fmt.Sprintf("- Movies: %s\n", strings.Join(movies, ", ")))
+	}
+	return sb.String()
+}
+
+// showTitles lists shows by title, alphabetically, or "none".
+func showTitles(shows []*trakt.Show) string {
+	if len(shows) == 0 {
+		return "none"
+	}
+	titles := make([]string, len(shows))
+	for i, s := range shows {
+		titles[i] = s.Title
+	}
+	sort.Strings(titles)
+	return strings.Join(titles, ", ")
+}
+
+func makeMonthlyReportHandler(client TraktAPI) ToolHandler {
+	type monthlyArgs struct {
+		Month string `json:"month"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a monthlyArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+
+		now := time.Now()
+		// By default the report is on the last full month
+		start := monthStart(now).AddDate(0, -1, 0)
+		if a.Month != "" {
+			t, err := time.ParseInLocation("2006-01", a.Month, time.Local)
+			if err != nil || t.Year() < firstReviewYear || t.After(now) {
+				return ToolCallResult{
+					Content: []Content{TextContent(fmt.Sprintf("Error: month must be YYYY-MM, from %d up to this month", firstReviewYear))},
+					IsError: true,
+				}, nil
+			}
+			start = t
+		}
+
+		report, err := monthlyReport(ctx, client, start, now)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		return ToolCallResult{Content: []Content{TextContent(report)}}, nil
+	}
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestMonthlyReportHandler(t *testing.T) {
+	march := func(day int) time.Time { return time.Date(2026, 3, day, 20, 0, 0, 0, time.Local) }
+	severance := &trakt.Show{Title: "Severance", Runtime: 50, AiredEpisodes: 3, IDs: trakt.ShowIDs{Trakt: 1}}
+	dark := &trakt.Show{Title: "Dark", Runtime: 55, AiredEpisodes: 26, IDs: trakt.ShowIDs{Trakt: 2}}
+	episode := func(show *trakt.Show, n, day int) trakt.HistoryItem {
+		return trakt.HistoryItem{Type: "episode", WatchedAt: march(day), Show: show, Episode: &trakt.Episode{Season: 1, Number: n}}
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sync/history":
+			w.Header().Set("X-Pagination-Page", "1")
+			w.Header().Set("X-Pagination-Page-Count", "1")
+			_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{
+				{Type: "movie", WatchedAt: march(25), Movie: &trakt.Movie{Title: "Dune", Year: 2021, Runtime: 155}},
+				episode(severance, 3, 20),
+				episode(dark, 2, 15),
+				episode(severance, 2, 10),
+				episode(severance, 1, 5),
+			})
+		case "/sync/watched/shows":
+			_ = json.NewEncoder(w).Encode([]trakt.WatchedEntry{
+				{Show: severance, LastWatchedAt: march(20), Seasons: []trakt.WatchedSeason{{Number: 1, Episodes: []trakt.WatchedEpisode{
+					{Number: 1, Plays: 1, LastWatchedAt: march(5)},
+					{Number: 2, Plays: 1, LastWatchedAt: march(10)},
+					{Number: 3, Plays: 1, LastWatchedAt: march(20)},
+				}}}},
+				// Started back in January
+				{Show: dark, LastWatchedAt: march(15), Seasons: []trakt.WatchedSeason{{Number: 1, Episodes: []trakt.WatchedEpisode{
+					{Number: 1, Plays: 1, LastWatchedAt: time.Date(2026, 1, 3, 20, 0, 0, 0, time.Local)},
+					{Number: 2, Plays: 1, LastWatchedAt: march(15)},
+				}}}},
+			})
+		case "/users/me/ratings":
+			_ = json.NewEncoder(w).Encode([]trakt.Rating{
+				{Rating: 9, RatedAt: march(20), Type: "show", Show: severance},
+				{Rating: 6, RatedAt: march(25), Type: "movie", Movie: &trakt.Movie{Title: "Dune"}},
+				{Rating: 4, RatedAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local), Type: "show", Show: dark},
+			})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	reportHandler := makeMonthlyReportHandler(client)
+
+	result, err := reportHandler(context.Background(), json.RawMessage(`{"month":"2026-03"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	text := result.Content[0].Text
+
+	for _, want := range []string{
+		"🗓️ **March 2026**",
+		"- Watched: 4 episodes of 2 shows and 1 movies, 6h in all",
+		"- Started: Severance\n",
+		"- Finished: Severance\n",
+		"- Ratings given: 2, averaging 7.5/10",
+		"- Most watched: Severance (3 episodes, 2h 30m), Dark (1 episodes, 55m)",
+		"- Movies: Dune (2021)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestMonthlyReportHandler_InvalidMonth(t *testing.T) {
+	reportHandler := makeMonthlyReportHandler(&fakeTrakt{authenticated: true})
+	for _, month := range []string{"March", "2026-13", "2003-01", "2999-01"} {
+		result, err := reportHandler(context.Background(), json.RawMessage(`{"month":"`+month+`"}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.IsError {
+			t.Errorf("expected month %q to be rejected", month)
+		}
+	}
+}
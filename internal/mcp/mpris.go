@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/guess"
+	"github.com/kofifort/trakt-mcp-go/internal/mpris"
+)
+
+// MPRISPollInterval is how often WatchPlayers asks the players what
+// they're playing.
+const MPRISPollInterval = 15 * time.Second
+
+// WatchPlayers asks the MPRIS players named in names, such as mpv and
+// VLC, what they're playing every interval until ctx is cancelled, and
+// scrobbles it with sc. What's playing is worked out from the file name,
+// or the title the player gives a stream. If several players are playing,
+// the first is scrobbled.
+func WatchPlayers(ctx context.Context, sc *Scrobbler, names []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if !sc.client.IsAuthenticated() {
+			continue
+		}
+
+		players, err := mpris.Players(ctx, names)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			sc.logger.Warn("reading players failed", "error", err)
+		}
+		sc.Update(ctx, mprisPlayback(players))
+	}
+}
+
+// mprisPlayback is what the first playing player, or else the first
+// paused one, is playing as a Playback, or nil if none is.
+func mprisPlayback(players []mpris.Player) *Playback {
+	var current *mpris.Player
+	for i, p := range players {
+		if p.Status == mpris.StatusPlaying {
+			current = &players[i]
+			break
+		}
+		if p.Status == mpris.StatusPaused && current == nil {
+			current = &players[i]
+		}
+	}
+	if current == nil {
+		return nil
+	}
+
+	name := current.File()
+	if name == "" {
+		name = current.Title
+	}
+	g := guess.Parse(name)
+	if g.Title == "" {
+		return nil
+	}
+	p := &Playback{
+		Key:      current.Name + ":" + name,
+		Type:     "movie",
+		Title:    g.Title,
+		Year:     g.Year,
+		Progress: current.Progress(),
+		Paused:   current.Status == mpris.StatusPaused,
+	}
+	if g.IsEpisode() {
+		p.Type = "episode"
+		p.Title = ""
+		p.ShowTitle = g.Title
+		p.Season = g.Season
+		p.Episode = g.Episode
+	}
+	return p
+}
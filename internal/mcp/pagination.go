@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+)
+
+// defaultPageSize is the number of items returned per page by list methods.
+const defaultPageSize = 50
+
+// parseCursor extracts the cursor from list request params, which may be omitted.
+func parseCursor(params json.RawMessage) (string, *Error) {
+	if len(params) == 0 || string(params) == "null" {
+		return "", nil
+	}
+	var p PaginatedParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return "", &Error{Code: InvalidParams, Message: "Invalid list params"}
+	}
+	return p.Cursor, nil
+}
+
+// paginate sorts items by key and returns the page following cursor, along
+// with the cursor for the next page (empty on the last page).
+//
+// Cursors encode the key of the last item returned rather than an offset, so
+// registering or removing items between requests doesn't skip or repeat others.
+func paginate[T any](items []T, key func(T) string, cursor string, pageSize int) ([]T, string, *Error) {
+	sort.Slice(items, func(i, j int) bool { return key(items[i]) < key(items[j]) })
+
+	start := 0
+	if cursor != "" {
+		after, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, "", &Error{Code: InvalidParams, Message: "Invalid cursor"}
+		}
+		start = sort.Search(len(items), func(i int) bool { return key(items[i]) > string(after) })
+	}
+
+	end := start + pageSize
+	if end >= len(items) {
+		return items[start:], "", nil
+	}
+
+	page := items[start:end]
+	next := base64.RawURLEncoding.EncodeToString([]byte(key(page[len(page)-1])))
+	return page, next, nil
+}
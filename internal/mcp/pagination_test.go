@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	identity := func(s string) string { return s }
+
+	t.Run("walks all pages in order", func(t *testing.T) {
+		items := []string{"e", "c", "a", "d", "b"}
+
+		var got []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > len(items) {
+				t.Fatal("pagination did not terminate")
+			}
+			page, next, err := paginate(items, identity, cursor, 2)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got = append(got, page...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+
+		if fmt.Sprint(got) != "[a b c d e]" {
+			t.Errorf("got %v, want [a b c d e]", got)
+		}
+	})
+
+	t.Run("single page has no cursor", func(t *testing.T) {
+		page, next, err := paginate([]string{"a", "b"}, identity, "", 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page) != 2 || next != "" {
+			t.Errorf("got page %v, next %q", page, next)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, _, err := paginate([]string{"a"}, identity, "!!not-base64!!", 2)
+		if err == nil || err.Code != InvalidParams {
+			t.Errorf("expected InvalidParams error, got %+v", err)
+		}
+	})
+}
+
+func TestServer_ToolsListPagination(t *testing.T) {
+	server := NewServer(nil)
+	server.pageSize = 2
+
+	for _, name := range []string{"a", "b", "c"} {
+		server.RegisterTool(Tool{Name: name, InputSchema: JSONSchema{Type: "object"}},
+			func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+				return ToolCallResult{}, nil
+			})
+	}
+
+	first, err := server.handleToolsList(json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Tools) != 2 || first.NextCursor == "" {
+		t.Fatalf("expected 2 tools and a cursor, got %d tools, cursor %q", len(first.Tools), first.NextCursor)
+	}
+
+	second, err := server.handleToolsList(json.RawMessage(`{"cursor":"` + first.NextCursor + `"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Tools) != 1 || second.Tools[0].Name != "c" || second.NextCursor != "" {
+		t.Errorf("unexpected second page: %+v", second)
+	}
+}
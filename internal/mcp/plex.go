@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// maxPlexPayload bounds a Plex webhook request. Plex attaches a thumbnail
+// to some events, so it's well above the JSON payload's size.
+const maxPlexPayload = 10 << 20
+
+// plexPayload is the part of a Plex webhook payload the scrobbler reads.
+type plexPayload struct {
+	Event   string `json:"event"`
+	Account struct {
+		Title string `json:"title"`
+	} `json:"Account"`
+	Metadata plexMetadata `json:"Metadata"`
+}
+
+type plexMetadata struct {
+	Type             string `json:"type"` // "movie", "episode", "track", ...
+	Title            string `json:"title"`
+	GrandparentTitle string `json:"grandparentTitle"` // an episode's show
+	ParentIndex      int    `json:"parentIndex"`      // an episode's season
+	Index            int    `json:"index"`            // an episode's number
+	Year             int    `json:"year"`
+	// GUID is the agent's ID for the item, e.g.
+	// "com.plexapp.agents.thetvdb://81189/1/1?lang=en" from the legacy
+	// agents or "plex://movie/5d7768..." from the current ones, which put
+	// the external IDs in GUIDs instead.
+	GUID  string `json:"guid"`
+	GUIDs []struct {
+		ID string `json:"id"` // e.g. "imdb://tt0111161", "tmdb://278", "tvdb://81189"
+	} `json:"Guid"`
+}
+
+// RegisterPlexWebhook serves /webhooks/plex beside the HTTP transports,
+// where a Plex Media Server can send its webhooks: each movie or episode
+// Plex scrobbles is logged to the user's Trakt history. The URL Plex calls
+// must carry token as its token query parameter, since Plex can't send
+// headers. If account is set, only scrobbles of the Plex user of that name
+// are logged. Nothing is registered if the server is read-only.
+func RegisterPlexWebhook(s *Server, client TraktAPI, token, account string) {
+	if s.ReadOnly() {
+		return
+	}
+	s.HandleHTTP("POST /webhooks/plex", queryToken(token, plexWebhook(s, client, account, time.Now)))
+}
+
+// queryToken serves h only to requests whose token query parameter is
+// token, answering others as if the endpoint didn't exist.
+func queryToken(token string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// plexWebhook logs the movies and episodes Plex reports scrobbled, and
+// acknowledges every other event without doing anything.
+func plexWebhook(s *Server, client TraktAPI, account string, now func() time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxPlexPayload)
+		var p plexPayload
+		if err := json.Unmarshal([]byte(r.FormValue("payload")), &p); err != nil {
+			http.Error(w, "expected a Plex webhook payload", http.StatusBadRequest)
+			return
+		}
+		if p.Event != "media.scrobble" || (account != "" && !strings.EqualFold(p.Account.Title, account)) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if p.Metadata.Type != "movie" && p.Metadata.Type != "episode" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if !client.IsAuthenticated() {
+			http.Error(w, "not signed in to Trakt", http.StatusServiceUnavailable)
+			return
+		}
+
+		ctx := r.Context()
+		title := plexTitle(p.Metadata)
+		item, err := plexWatchedItem(ctx, client, p.Metadata)
+		if err != nil {
+			s.logger.Warn("plex scrobble not matched", "title", title, "error", err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		item.WatchedAt = formatWatchedAt(now())
+
+		resp, err := client.AddToHistory(ctx, item)
+		switch {
+		case errors.Is(err, errWriteQueued):
+			s.logger.Info("plex scrobble queued", "title", title)
+			w.WriteHeader(http.StatusAccepted)
+		case err != nil:
+			s.logger.Warn("plex scrobble failed", "title", title, "error", err)
+			http.Error(w, fmt.Sprintf("log to history: %v", err), http.StatusBadGateway)
+		case resp.Added.Movies+resp.Added.Episodes == 0:
+			s.logger.Warn("plex scrobble not found on Trakt", "title", title)
+			http.Error(w, "Trakt didn't recognize the item", http.StatusUnprocessableEntity)
+		default:
+			s.logger.Info("plex scrobble logged", "title", title)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+// plexWatchedItem names the movie or episode m in a history write, by the
+// external IDs Plex knows it by.
+func plexWatchedItem(ctx context.Context, client TraktAPI, m plexMetadata) (trakt.WatchedItem, error) {
+	ids := plexGUIDs(m)
+	if m.Type == "movie" {
+		movie := trakt.MovieIDs{IMDB: ids["imdb"]}
+		movie.TMDB, _ = strconv.Atoi(ids["tmdb"])
+		if movie.IMDB == "" && movie.TMDB == 0 {
+			return trakt.WatchedItem{}, errors.New("no IMDb or TMDB ID for the movie")
+		}
+		return trakt.WatchedItem{Movies: []trakt.Movie{{IDs: movie}}}, nil
+	}
+
+	// The current agents give the episode's own IDs
+	var episode trakt.EpisodeIDs
+	episode.IMDB = ids["imdb"]
+	episode.TMDB, _ = strconv.Atoi(ids["tmdb"])
+	episode.TVDB, _ = strconv.Atoi(ids["tvdb"])
+	if episode != (trakt.EpisodeIDs{}) {
+		return trakt.WatchedItem{Episodes: []trakt.Episode{{IDs: episode}}}, nil
+	}
+
+	// The legacy agents give the show's ID with the season and number
+	idType, showID, ok := legacyShowGUID(m.GUID)
+	if !ok {
+		return trakt.WatchedItem{}, errors.New("no TVDB, TMDB or IMDb ID for the episode")
+	}
+	results, err := client.LookupID(ctx, idType, showID, "show")
+	if err != nil {
+		return trakt.WatchedItem{}, fmt.Errorf("look up show: %w", err)
+	}
+	if len(results) == 0 || results[0].Show == nil {
+		return trakt.WatchedItem{}, fmt.Errorf("no show on Trakt with %s ID %s", idType, showID)
+	}
+	ep, err := client.GetEpisode(ctx, strconv.Itoa(results[0].Show.IDs.Trakt), m.ParentIndex, m.Index)
+	if err != nil {
+		return trakt.WatchedItem{}, fmt.Errorf("look up episode: %w", err)
+	}
+	return trakt.WatchedItem{Episodes: []trakt.Episode{{IDs: trakt.EpisodeIDs{Trakt: ep.IDs.Trakt}}}}, nil
+}
+
+// plexGUIDs maps the ID types in m's external GUIDs ("imdb", "tmdb",
+// "tvdb") to the IDs, and adds a legacy movie agent's ID.
+func plexGUIDs(m plexMetadata) map[string]string {
+	ids := make(map[string]string)
+	for _, g := range m.GUIDs {
+		if idType, id, ok := strings.Cut(g.ID, "://"); ok {
+			ids[idType] = id
+		}
+	}
+	if m.Type == "movie" {
+		switch agent, id := legacyGUID(m.GUID); agent {
+		case "imdb":
+			ids["imdb"] = id
+		case "themoviedb":
+			ids["tmdb"] = id
+		}
+	}
+	return ids
+}
+
+// legacyShowGUID returns the ID type and show ID of a legacy agent's
+// episode GUID, such as "com.plexapp.agents.thetvdb://81189/1/1?lang=en".
+func legacyShowGUID(guid string) (idType, id string, ok bool) {
+	agent, path := legacyGUID(guid)
+	show, _, _ := strings.Cut(path, "/")
+	if show == "" {
+		return "", "", false
+	}
+	switch agent {
+	case "thetvdb":
+		return "tvdb", show, true
+	case "themoviedb":
+		return "tmdb", show, true
+	}
+	return "", "", false
+}
+
+// legacyGUID splits a legacy agent's GUID into the agent's short name,
+// e.g. "thetvdb", and the path after it, without the query.
+func legacyGUID(guid string) (agent, path string) {
+	u, err := url.Parse(guid)
+	if err != nil || !strings.HasPrefix(u.Scheme, "com.plexapp.agents.") {
+		return "", ""
+	}
+	return strings.TrimPrefix(u.Scheme, "com.plexapp.agents."), strings.TrimPrefix(u.Host+u.Path, "/")
+}
+
+// plexTitle names m for the log.
+func plexTitle(m plexMetadata) string {
+	if m.Type == "episode" {
+		return fmt.Sprintf("%s S%02dE%02d", m.GrandparentTitle, m.ParentIndex, m.Index)
+	}
+	return m.Title + yearSuffix(m.Year)
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// plexTrakt records history writes and resolves the legacy agents' shows.
+type plexTrakt struct {
+	fakeTrakt
+	written []trakt.WatchedItem
+}
+
+func (f *plexTrakt) LookupID(ctx context.Context, idType, id, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error) {
+	if idType == "tvdb" && id == "81189" {
+		return []trakt.SearchResult{{Type: "show", Show: &trakt.Show{Title: "Breaking Bad", IDs: trakt.ShowIDs{Trakt: 1388}}}}, nil
+	}
+	return nil, nil
+}
+
+func (f *plexTrakt) GetEpisode(ctx context.Context, showID string, season, episode int, opts ...trakt.RequestOption) (*trakt.Episode, error) {
+	return &trakt.Episode{Season: season, Number: episode, IDs: trakt.EpisodeIDs{Trakt: 73482}}, nil
+}
+
+func (f *plexTrakt) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	f.written = append(f.written, item)
+	return &trakt.SyncResponse{Added: trakt.SyncStats{Movies: len(item.Movies), Episodes: len(item.Episodes)}}, nil
+}
+
+// plexRequest builds a webhook request the way Plex sends one, as a
+// multipart form with the JSON in its payload field.
+func plexRequest(t *testing.T, target, payload string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("payload", payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost, target, &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestPlexWebhook(t *testing.T) {
+	now := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	client := &plexTrakt{fakeTrakt: fakeTrakt{authenticated: true}}
+	server := NewServer(nil)
+	server.HandleHTTP("POST /webhooks/plex", queryToken("s3cret", plexWebhook(server, client, "kofi", func() time.Time { return now })))
+	mux := server.httpMux()
+
+	tests := []struct {
+		name     string
+		target   string
+		payload  string
+		wantCode int
+		want     *trakt.WatchedItem
+	}{
+		{
+			name:     "movie by external GUIDs",
+			target:   "/webhooks/plex?token=s3cret",
+			payload:  `{"event":"media.scrobble","Account":{"title":"Kofi"},"Metadata":{"type":"movie","title":"The Shawshank Redemption","year":1994,"guid":"plex://movie/5d7768","Guid":[{"id":"imdb://tt0111161"},{"id":"tmdb://278"}]}}`,
+			wantCode: http.StatusNoContent,
+			want:     &trakt.WatchedItem{WatchedAt: "2026-10-16T21:00:00Z", Movies: []trakt.Movie{{IDs: trakt.MovieIDs{IMDB: "tt0111161", TMDB: 278}}}},
+		},
+		{
+			name:     "episode by external GUIDs",
+			target:   "/webhooks/plex?token=s3cret",
+			payload:  `{"event":"media.scrobble","Account":{"title":"kofi"},"Metadata":{"type":"episode","grandparentTitle":"Severance","parentIndex":2,"index":1,"Guid":[{"id":"tvdb://10177185"}]}}`,
+			wantCode: http.StatusNoContent,
+			want:     &trakt.WatchedItem{WatchedAt: "2026-10-16T21:00:00Z", Episodes: []trakt.Episode{{IDs: trakt.EpisodeIDs{TVDB: 10177185}}}},
+		},
+		{
+			name:     "episode by legacy agent",
+			target:   "/webhooks/plex?token=s3cret",
+			payload:  `{"event":"media.scrobble","Account":{"title":"kofi"},"Metadata":{"type":"episode","grandparentTitle":"Breaking Bad","parentIndex":1,"index":1,"guid":"com.plexapp.agents.thetvdb://81189/1/1?lang=en"}}`,
+			wantCode: http.StatusNoContent,
+			want:     &trakt.WatchedItem{WatchedAt: "2026-10-16T21:00:00Z", Episodes: []trakt.Episode{{IDs: trakt.EpisodeIDs{Trakt: 73482}}}},
+		},
+		{
+			name:     "movie by legacy agent",
+			target:   "/webhooks/plex?token=s3cret",
+			payload:  `{"event":"media.scrobble","Account":{"title":"kofi"},"Metadata":{"type":"movie","title":"Fight Club","guid":"com.plexapp.agents.themoviedb://550?lang=en"}}`,
+			wantCode: http.StatusNoContent,
+			want:     &trakt.WatchedItem{WatchedAt: "2026-10-16T21:00:00Z", Movies: []trakt.Movie{{IDs: trakt.MovieIDs{TMDB: 550}}}},
+		},
+		{
+			name:     "other event",
+			target:   "/webhooks/plex?token=s3cret",
+			payload:  `{"event":"media.play","Account":{"title":"kofi"},"Metadata":{"type":"movie","Guid":[{"id":"imdb://tt0111161"}]}}`,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "other account",
+			target:   "/webhooks/plex?token=s3cret",
+			payload:  `{"event":"media.scrobble","Account":{"title":"guest"},"Metadata":{"type":"movie","Guid":[{"id":"imdb://tt0111161"}]}}`,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "no usable IDs",
+			target:   "/webhooks/plex?token=s3cret",
+			payload:  `{"event":"media.scrobble","Account":{"title":"kofi"},"Metadata":{"type":"movie","title":"Home Video","guid":"local://123"}}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "wrong token",
+			target:   "/webhooks/plex?token=guess",
+			payload:  `{"event":"media.scrobble","Account":{"title":"kofi"},"Metadata":{"type":"movie","Guid":[{"id":"imdb://tt0111161"}]}}`,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.written = nil
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, plexRequest(t, tt.target, tt.payload))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if tt.want == nil {
+				if len(client.written) != 0 {
+					t.Errorf("expected nothing logged, got %+v", client.written)
+				}
+				return
+			}
+			if len(client.written) != 1 {
+				t.Fatalf("expected one history write, got %+v", client.written)
+			}
+			got, want := client.written[0], *tt.want
+			if got.WatchedAt != want.WatchedAt || len(got.Movies) != len(want.Movies) || len(got.Episodes) != len(want.Episodes) {
+				t.Fatalf("expected %+v, got %+v", want, got)
+			}
+			for i := range want.Movies {
+				if got.Movies[i].IDs != want.Movies[i].IDs {
+					t.Errorf("expected movie %+v, got %+v", want.Movies[i].IDs, got.Movies[i].IDs)
+				}
+			}
+			for i := range want.Episodes {
+				if got.Episodes[i].IDs != want.Episodes[i].IDs {
+					t.Errorf("expected episode %+v, got %+v", want.Episodes[i].IDs, got.Episodes[i].IDs)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterPlexWebhook_ReadOnly(t *testing.T) {
+	server := NewServer(nil)
+	server.SetReadOnly(true)
+	RegisterPlexWebhook(server, &plexTrakt{}, "s3cret", "")
+	if len(server.httpRoutes) != 0 {
+		t.Errorf("expected no webhook in read-only mode, got %v", server.httpRoutes)
+	}
+}
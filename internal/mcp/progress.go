@@ -0,0 +1,39 @@
+package mcp
+
+import "context"
+
+type progressTokenKey struct{}
+
+// withProgressToken attaches the caller's progress token to a tool call's context.
+func withProgressToken(ctx context.Context, token any) context.Context {
+	return context.WithValue(ctx, progressTokenKey{}, token)
+}
+
+// progressToken returns the progress token the client sent with the tool
+// call, or nil if it didn't ask for progress updates.
+func progressToken(ctx context.Context) any {
+	return ctx.Value(progressTokenKey{})
+}
+
+// notifyProgress reports progress against token, falling back to an
+// info-level log message when the client supplied no token.
+func (s *Server) notifyProgress(token any, progress, total float64, message string) {
+	var err error
+	if token != nil {
+		err = s.Notify("notifications/progress", ProgressParams{
+			ProgressToken: token,
+			Progress:      progress,
+			Total:         total,
+			Message:       message,
+		})
+	} else {
+		err = s.Notify("notifications/message", LoggingMessageParams{
+			Level:  "info",
+			Logger: ServerName,
+			Data:   message,
+		})
+	}
+	if err != nil {
+		s.logger.Error("failed to send notification", "error", err)
+	}
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/queue"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// errWriteQueued is returned, wrapping the failure, by a write that Trakt
+// couldn't take and that was queued to be sent later instead.
+var errWriteQueued = errors.New("write queued until Trakt is reachable")
+
+// queuedTrakt queues history, rating and watchlist writes that fail because
+// Trakt is unreachable, to be replayed once it's back.
+type queuedTrakt struct {
+	TraktAPI
+	queue *queue.Queue
+}
+
+// Queued returns client with writes that fail because Trakt is
+// unreachable journaled to q rather than lost. Such writes return an error
+// matching errWriteQueued, which tools report as queued.
+func Queued(client TraktAPI, q *queue.Queue) TraktAPI {
+	return &queuedTrakt{TraktAPI: client, queue: q}
+}
+
+func (c *queuedTrakt) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	// A replayed watch must keep the time it was logged, not when Trakt
+	// came back; the fixed time also lets Trakt spot a replay of a write
+	// that did go through
+	now := time.Now()
+	if item.WatchedAt == "" {
+		item.WatchedAt = now.UTC().Format(time.RFC3339)
+	}
+	resp, err := c.TraktAPI.AddToHistory(ctx, item)
+	if !trakt.IsUnavailable(err) {
+		return resp, err
+	}
+	return nil, c.enqueue(queue.Entry{QueuedAt: now, History: &item}, err)
+}
+
+func (c *queuedTrakt) AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error) {
+	now := time.Now()
+	ratedAt := now.UTC().Format(time.RFC3339)
+	for i := range item.Movies {
+		if item.Movies[i].RatedAt == "" {
+			item.Movies[i].RatedAt = ratedAt
+		}
+	}
+	for i := range item.Episodes {
+		if item.Episodes[i].RatedAt == "" {
+			item.Episodes[i].RatedAt = ratedAt
+		}
+	}
+	resp, err := c.TraktAPI.AddRatings(ctx, item)
+	if !trakt.IsUnavailable(err) {
+		return resp, err
+	}
+	return nil, c.enqueue(queue.Entry{QueuedAt: now, Ratings: &item}, err)
+}
+
+func (c *queuedTrakt) AddToWatchlist(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error) {
+	resp, err := c.TraktAPI.AddToWatchlist(ctx, items)
+	if !trakt.IsUnavailable(err) {
+		return resp, err
+	}
+	return nil, c.enqueue(queue.Entry{QueuedAt: time.Now(), Watchlist: &items}, err)
+}
+
+// enqueue journals a write that failed with cause. If it can't be
+// journaled either, cause is returned as is.
+func (c *queuedTrakt) enqueue(e queue.Entry, cause error) error {
+	if err := c.queue.Add(e); err != nil {
+		return fmt.Errorf("%w (queueing it failed too: %v)", cause, err)
+	}
+	return fmt.Errorf("%w: %w", errWriteQueued, cause)
+}
@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/queue"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestRateAndLogHandler_QueuedWhileUnavailable(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/search"):
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{{
+				Type:  "movie",
+				Score: 1000,
+				Movie: &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 287071}},
+			}})
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	q, err := queue.Open(filepath.Join(t.TempDir(), "queue.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("queue.Open failed: %v", err)
+	}
+	rateHandler := makeRateAndLogHandler(Queued(client, q), func(context.Context, string, string, []trakt.SearchResult) *trakt.SearchResult { return nil }, newWriteGuard())
+
+	result, err := rateHandler(context.Background(), json.RawMessage(`{"type":"movie","movieName":"Dune","rating":8}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || len(result.Content) != 2 {
+		t.Fatalf("expected the queued log and rating, got %+v", result)
+	}
+	if !strings.Contains(result.Content[0].Text, "**Dune (2021)** was queued") || !strings.Contains(result.Content[1].Text, "8/10 rating was queued") {
+		t.Errorf("unexpected result: %+v", result.Content)
+	}
+	if q.Len() != 2 {
+		t.Fatalf("expected the watch and rating queued, got %d", q.Len())
+	}
+}
+
+func TestQueued_PassesOtherErrorsThrough(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	q, err := queue.Open(filepath.Join(t.TempDir(), "queue.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("queue.Open failed: %v", err)
+	}
+
+	_, err = Queued(client, q).AddToHistory(context.Background(), trakt.WatchedItem{Movies: []trakt.Movie{{IDs: trakt.MovieIDs{Trakt: 1}}}})
+	if err == nil {
+		t.Fatal("expected the rejected write to fail")
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected a rejected write not to be queued, got %d", q.Len())
+	}
+}
+
+func TestQueued_WatchlistAddQueuedAndReplayed(t *testing.T) {
+	up := false
+	var added []trakt.SyncItems
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var items trakt.SyncItems
+		_ = json.NewDecoder(r.Body).Decode(&items)
+		added = append(added, items)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	q, err := queue.Open(filepath.Join(t.TempDir(), "queue.jsonl"), nil)
+	if err != nil {
+		t.Fatalf("queue.Open failed: %v", err)
+	}
+
+	items := trakt.SyncItems{Movies: []trakt.Movie{{IDs: trakt.MovieIDs{Trakt: 287071}}}}
+	if _, err := Queued(client, q).AddToWatchlist(context.Background(), items); !errors.Is(err, errWriteQueued) {
+		t.Fatalf("expected the watchlist add queued, got %v", err)
+	}
+	if q.Len() != 1 {
+		t.Fatalf("expected 1 queued write, got %d", q.Len())
+	}
+
+	up = true
+	if sent, err := q.Replay(context.Background(), client); err != nil || sent != 1 {
+		t.Fatalf("expected the watchlist add replayed, got %d sent: %v", sent, err)
+	}
+	if len(added) != 1 || len(added[0].Movies) != 1 || added[0].Movies[0].IDs.Trakt != 287071 {
+		t.Errorf("expected the movie added to the watchlist on replay, got %+v", added)
+	}
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Trakt ratings are whole numbers in this range.
+const (
+	minRating = 1
+	maxRating = 10
+)
+
+func makeRateAndLogHandler(client TraktAPI, pick matchPicker, guard *writeGuard) ToolHandler {
+	type rateAndLogArgs struct {
+		logWatchArgs
+		Rating int `json:"rating"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a rateAndLogArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		// Check the rating before logging, so a bad one doesn't leave the
+		// watch logged without it
+		if a.Rating < minRating || a.Rating > maxRating {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgRatingRange, minRating, maxRating))},
+				IsError: true,
+			}, nil
+		}
+
+		result, logged := logWatch(ctx, client, pick, guard, a.logWatchArgs)
+		if logged.episode == nil && logged.movie == nil {
+			return result, nil
+		}
+
+		// Rate even when the watch was already logged, since the rating is
+		// what the retry is for
+		var item trakt.RatingItem
+		if logged.episode != nil {
+			item.Episodes = []trakt.RatedEpisode{{Rating: a.Rating, IDs: trakt.EpisodeIDs{Trakt: logged.episode.IDs.Trakt}}}
+		} else {
+			item.Movies = []trakt.RatedMovie{{Rating: a.Rating, IDs: trakt.MovieIDs{Trakt: logged.movie.IDs.Trakt}}}
+		}
+
+		text := msg(ctx, msgRated, a.Rating)
+		resp, err := client.AddRatings(ctx, item)
+		switch {
+		case errors.Is(err, errWriteQueued):
+			text = msg(ctx, msgRatingQueued, a.Rating)
+		case err != nil:
+			text = msg(ctx, msgRatingFailed, err)
+		case resp.Added.Episodes+resp.Added.Movies == 0:
+			text = msg(ctx, msgRatingFailed, "Trakt didn't accept it")
+		}
+		result.Content = append(result.Content, TextContent(text))
+		return result, nil
+	}
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestRateAndLogHandler(t *testing.T) {
+	var logged, rated atomic.Int32
+	var rating trakt.RatingItem
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/search"):
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{{
+				Type:  "movie",
+				Score: 1000,
+				Movie: &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 287071}},
+			}})
+		case r.URL.Path == "/sync/history":
+			logged.Add(1)
+			var resp trakt.SyncResponse
+			resp.Added.Movies = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/sync/ratings":
+			rated.Add(1)
+			_ = json.NewDecoder(r.Body).Decode(&rating)
+			var resp trakt.SyncResponse
+			resp.Added.Movies = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	rateHandler := makeRateAndLogHandler(client, func(context.Context, string, string, []trakt.SearchResult) *trakt.SearchResult { return nil }, newWriteGuard())
+
+	result, err := rateHandler(context.Background(), json.RawMessage(`{"type":"movie","movieName":"Dune","watchedAt":"yesterday","rating":8}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || len(result.Content) != 2 {
+		t.Fatalf("expected the log and rating results, got %+v", result)
+	}
+	if !strings.Contains(result.Content[0].Text, "Logged: **Dune** (2021)") || result.Content[1].Text != "⭐ Rated 8/10" {
+		t.Errorf("unexpected result: %+v", result.Content)
+	}
+	if len(rating.Movies) != 1 || rating.Movies[0].Rating != 8 || rating.Movies[0].IDs.Trakt != 287071 {
+		t.Errorf("unexpected rating sent: %+v", rating)
+	}
+
+	// A retry to fix the rating rates again without logging a second play
+	result, err = rateHandler(context.Background(), json.RawMessage(`{"type":"movie","movieName":"Dune","watchedAt":"yesterday","rating":9}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logged.Load() != 1 || rated.Load() != 2 || rating.Movies[0].Rating != 9 {
+		t.Errorf("expected 1 log and 2 ratings ending at 9, got %d, %d, %+v", logged.Load(), rated.Load(), rating)
+	}
+}
+
+func TestRateAndLogHandler_InvalidRating(t *testing.T) {
+	var requests atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	rateHandler := makeRateAndLogHandler(client, func(context.Context, string, string, []trakt.SearchResult) *trakt.SearchResult { return nil }, newWriteGuard())
+
+	for _, args := range []string{
+		`{"type":"movie","movieName":"Dune"}`,
+		`{"type":"movie","movieName":"Dune","rating":11}`,
+	} {
+		result, err := rateHandler(context.Background(), json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.IsError || !strings.Contains(result.Content[0].Text, "rating must be") {
+			t.Errorf("%s: expected a rating error, got: %+v", args, result)
+		}
+	}
+	if requests.Load() != 0 {
+		t.Errorf("expected nothing logged for an invalid rating, got %d requests", requests.Load())
+	}
+}
@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Resource URIs exposed by the server.
+const (
+	historyRecentURI = "trakt://history/recent"
+	watchlistURI     = "trakt://watchlist"
+	upNextURI        = "trakt://progress/up-next"
+	weeklyDigestURI  = "trakt://digest/weekly"
+	monthlyReportURI = "trakt://reports/monthly"
+)
+
+const (
+	// recentHistoryLimit is the number of history items included in the recent history resource.
+	recentHistoryLimit = 25
+	// upNextShowLimit caps how many recently watched shows are checked for progress,
+	// since each show costs one extra API call.
+	upNextShowLimit = 10
+)
+
+var errNotAuthenticated = errors.New("not authenticated. Use the authenticate tool first")
+
+// RegisterResources registers all Trakt resources with the MCP server.
+func RegisterResources(s *Server, client TraktAPI) {
+	s.RegisterResource(Resource{
+		URI:         historyRecentURI,
+		Name:        "Recent watch history",
+		Description: "The most recently watched episodes and movies.",
+		MimeType:    "text/plain",
+	}, makeHistoryResourceHandler(client))
+
+	s.RegisterResource(Resource{
+		URI:         watchlistURI,
+		Name:        "Watchlist",
+		Description: "Shows and movies on the user's Trakt watchlist.",
+		MimeType:    "text/plain",
+	}, makeWatchlistResourceHandler(client))
+
+	s.RegisterResource(Resource{
+		URI:         upNextURI,
+		Name:        "Up next",
+		Description: "The next unwatched episode for recently watched shows.",
+		MimeType:    "text/plain",
+	}, makeUpNextResourceHandler(client))
+
+	s.RegisterResource(Resource{
+		URI:         weeklyDigestURI,
+		Name:        "Weekly digest",
+		Description: "The past week: what the user watched, episodes of their shows that aired and they missed, and what airs next week.",
+		MimeType:    "text/plain",
+	}, makeWeeklyDigestResourceHandler(client))
+
+	s.RegisterResource(Resource{
+		URI:         monthlyReportURI,
+		Name:        "Monthly report",
+		Description: "A recap of last month: plays and watch time, shows started and finished, and ratings given.",
+		MimeType:    "text/plain",
+	}, makeMonthlyReportResourceHandler(client))
+}
+
+// textResource wraps plain text as a single-item resources/read result.
+func textResource(uri, text string) ResourceReadResult {
+	return ResourceReadResult{
+		Contents: []ResourceContents{{URI: uri, MimeType: "text/plain", Text: text}},
+	}
+}
+
+func makeHistoryResourceHandler(client TraktAPI) ResourceHandler {
+	return func(ctx context.Context, uri string) (ResourceReadResult, error) {
+		if !client.IsAuthenticated() {
+			return ResourceReadResult{}, errNotAuthenticated
+		}
+
+		history, err := client.GetHistory(ctx, "", recentHistoryLimit)
+		if err != nil {
+			return ResourceReadResult{}, err
+		}
+
+		if len(history) == 0 {
+			return textResource(uri, msg(ctx, msgNoHistory)), nil
+		}
+
+		return textResource(uri, formatHistory(history, time.Now())), nil
+	}
+}
+
+func makeWatchlistResourceHandler(client TraktAPI) ResourceHandler {
+	return func(ctx context.Context, uri string) (ResourceReadResult, error) {
+		if !client.IsAuthenticated() {
+			return ResourceReadResult{}, errNotAuthenticated
+		}
+
+		items, err := client.GetWatchlist(ctx, "")
+		if err != nil {
+			return ResourceReadResult{}, err
+		}
+
+		if len(items) == 0 {
+			return textResource(uri, msg(ctx, msgWatchlistEmpty)), nil
+		}
+
+		var sb strings.Builder
+		for _, item := range items {
+			sb.WriteString(formatWatchlistItem(item))
+		}
+
+		return textResource(uri, sb.String()), nil
+	}
+}
+
+// makeUpNextResourceHandler derives the up-next list from recent show history,
+// looking up watched progress for each distinct show.
+func makeUpNextResourceHandler(client TraktAPI) ResourceHandler {
+	return func(ctx context.Context, uri string) (ResourceReadResult, error) {
+		if !client.IsAuthenticated() {
+			return ResourceReadResult{}, errNotAuthenticated
+		}
+
+		history, err := client.GetHistory(ctx, "shows", recentHistoryLimit)
+		if err != nil {
+			return ResourceReadResult{}, err
+		}
+
+		seen := make(map[int]bool)
+		var sb strings.Builder
+		for _, h := range history {
+			if h.Show == nil || seen[h.Show.IDs.Trakt] {
+				continue
+			}
+			if len(seen) >= upNextShowLimit {
+				break
+			}
+			seen[h.Show.IDs.Trakt] = true
+
+			progress, err := client.GetShowProgress(ctx, fmt.Sprintf("%d", h.Show.IDs.Trakt))
+			if err != nil {
+				return ResourceReadResult{}, err
+			}
+			if progress.NextEpisode == nil {
+				continue
+			}
+
+			ep := progress.NextEpisode
+			sb.WriteString(fmt.Sprintf("📺 %s S%02dE%02d - %s (%d/%d watched)\n",
+				h.Show.Title, ep.Season, ep.Number, ep.Title, progress.Completed, progress.Aired))
+			sb.WriteString(idLine(
+				idPart("Show ID", int64(h.Show.IDs.Trakt)),
+				slugPart(h.Show.IDs.Slug),
+				idPart("Episode ID", int64(ep.IDs.Trakt)),
+				h.Show.EpisodeURL(ep.Season, ep.Number),
+			))
+		}
+
+		if sb.Len() == 0 {
+			return textResource(uri, "Nothing up next."), nil
+		}
+
+		return textResource(uri, sb.String()), nil
+	}
+}
+
+func makeWeeklyDigestResourceHandler(client TraktAPI) ResourceHandler {
+	return func(ctx context.Context, uri string) (ResourceReadResult, error) {
+		if !client.IsAuthenticated() {
+			return ResourceReadResult{}, errNotAuthenticated
+		}
+
+		digest, err := weeklyDigest(ctx, client, time.Now())
+		if err != nil {
+			return ResourceReadResult{}, err
+		}
+		return textResource(uri, digest), nil
+	}
+}
+
+func makeMonthlyReportResourceHandler(client TraktAPI) ResourceHandler {
+	return func(ctx context.Context, uri string) (ResourceReadResult, error) {
+		if !client.IsAuthenticated() {
+			return ResourceReadResult{}, errNotAuthenticated
+		}
+
+		now := time.Now()
+		report, err := monthlyReport(ctx, client, monthStart(now).AddDate(0, -1, 0), now)
+		if err != nil {
+			return ResourceReadResult{}, err
+		}
+		return textResource(uri, report), nil
+	}
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestRegisterResources(t *testing.T) {
+	server := NewServer(nil)
+	client := trakt.NewClient(trakt.Config{}, nil)
+
+	RegisterResources(server, client)
+
+	expected := []string{historyRecentURI, watchlistURI, upNextURI, weeklyDigestURI, monthlyReportURI}
+
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+
+	for _, uri := range expected {
+		if _, ok := server.resources[uri]; !ok {
+			t.Errorf("resource %q not registered", uri)
+		}
+		if _, ok := server.resourceHandlers[uri]; !ok {
+			t.Errorf("handler for %q not registered", uri)
+		}
+	}
+}
+
+func TestResourceHandlers_NotAuthenticated(t *testing.T) {
+	server := NewServer(nil)
+	client := trakt.NewClient(trakt.Config{ClientID: "test"}, nil)
+
+	RegisterResources(server, client)
+
+	for _, uri := range []string{historyRecentURI, watchlistURI, upNextURI, weeklyDigestURI, monthlyReportURI} {
+		t.Run(uri, func(t *testing.T) {
+			server.mu.RLock()
+			handler := server.resourceHandlers[uri]
+			server.mu.RUnlock()
+
+			if _, err := handler(context.Background(), uri); err == nil {
+				t.Error("expected error for unauthenticated client")
+			}
+		})
+	}
+}
+
+func TestWatchlistResource_Success(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync/watchlist" {
+			t.Errorf("expected /sync/watchlist, got %s", r.URL.Path)
+		}
+		items := []trakt.WatchlistItem{
+			{Type: "show", Show: &trakt.Show{Title: "Severance", Year: 2022, IDs: trakt.ShowIDs{Trakt: 154997}}},
+			{Type: "movie", Movie: &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 287071}}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(items)
+	})
+
+	_, client := newMockTraktServer(t, handler)
+
+	server := NewServer(nil)
+	RegisterResources(server, client)
+
+	server.mu.RLock()
+	readHandler := server.resourceHandlers[watchlistURI]
+	server.mu.RUnlock()
+
+	result, err := readHandler(context.Background(), watchlistURI)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Contents) != 1 {
+		t.Fatalf("expected 1 content item, got %d", len(result.Contents))
+	}
+
+	text := result.Contents[0].Text
+	if !strings.Contains(text, "Severance") || !strings.Contains(text, "Dune") {
+		t.Errorf("expected watchlist titles in result, got: %s", text)
+	}
+}
+
+func TestUpNextResource_Success(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/sync/history/shows":
+			show := &trakt.Show{Title: "Breaking Bad", IDs: trakt.ShowIDs{Trakt: 1388}}
+			history := []trakt.HistoryItem{
+				{Type: "episode", Show: show, Episode: &trakt.Episode{Season: 1, Number: 2}},
+				{Type: "episode", Show: show, Episode: &trakt.Episode{Season: 1, Number: 1}},
+			}
+			_ = json.NewEncoder(w).Encode(history)
+
+		case r.URL.Path == "/shows/1388/progress/watched":
+			progress := trakt.ShowProgress{
+				Aired:       62,
+				Completed:   2,
+				NextEpisode: &trakt.Episode{Season: 1, Number: 3, Title: "...And the Bag's in the River"},
+			}
+			_ = json.NewEncoder(w).Encode(progress)
+
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+
+	server := NewServer(nil)
+	RegisterResources(server, client)
+
+	server.mu.RLock()
+	readHandler := server.resourceHandlers[upNextURI]
+	server.mu.RUnlock()
+
+	result, err := readHandler(context.Background(), upNextURI)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	text := result.Contents[0].Text
+	if !strings.Contains(text, "Breaking Bad S01E03") {
+		t.Errorf("expected next episode in result, got: %s", text)
+	}
+	if strings.Count(text, "Breaking Bad") != 1 {
+		t.Errorf("expected show to be listed once, got: %s", text)
+	}
+}
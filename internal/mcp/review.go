@@ -0,0 +1,174 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Sizes of the top lists in year_in_review.
+const (
+	reviewTopShows  = 5
+	reviewTopGenres = 5
+)
+
+// firstReviewYear is the earliest year year_in_review accepts; Trakt
+// launched in 2010.
+const firstReviewYear = 2010
+
+func makeYearInReviewHandler(client TraktAPI) ToolHandler {
+	type reviewArgs struct {
+		Year int `json:"year"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a reviewArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+
+		now := time.Now()
+		if a.Year == 0 {
+			a.Year = now.Year()
+		}
+		if a.Year < firstReviewYear || a.Year > now.Year() {
+			return ToolCallResult{
+				Content: []Content{TextContent(fmt.Sprintf("Error: year must be between %d and %d", firstReviewYear, now.Year()))},
+				IsError: true,
+			}, nil
+		}
+
+		start := time.Date(a.Year, 1, 1, 0, 0, 0, 0, time.Local)
+		end := start.AddDate(1, 0, 0)
+		if end.After(now) {
+			end = now
+		}
+		history, err := historyBetween(ctx, client, "", start, end)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		if len(history) == 0 {
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNothingWatched))}}, nil
+		}
+
+		return ToolCallResult{
+			Content: []Content{TextContent(formatYearInReview(history, a.Year, now))},
+		}, nil
+	}
+}
+
+// formatYearInReview summarizes a year of history, oldest first: totals,
+// top shows and genres, the busiest month, the longest binge, and the
+// first and last watch.
+func formatYearInReview(history []trakt.HistoryItem, year int, now time.Time) string {
+	wt := sumWatchTime(history)
+
+	var sb strings.Builder
+	if year == now.Year() {
+		sb.WriteString(fmt.Sprintf("🎉 **%d so far**\n\n", year))
+	} else {
+		sb.WriteString(fmt.Sprintf("🎉 **%d in review**\n\n", year))
+	}
+	sb.WriteString(fmt.Sprintf("Watched: %s episodes of %s shows and %s movies, %s in all\n",
+		formatCount(wt.episodes), formatCount(len(wt.shows)), formatCount(wt.movies), formatMinutes(wt.showMinutes+wt.movieMinutes)))
+
+	if len(wt.shows) > 0 {
+		sb.WriteString("\n**Top shows**\n")
+		for i, st := range wt.shows[:min(len(wt.shows), reviewTopShows)] {
+			sb.WriteString(fmt.Sprintf("%d. **%s** - %d episodes, %s\n", i+1, st.show.Title, st.episodes, formatMinutes(st.minutes)))
+		}
+	}
+
+	if genres := topGenres(history, reviewTopGenres); len(genres) > 0 {
+		sb.WriteString(fmt.Sprintf("\nTop genres: %s\n", strings.Join(genres, ", ")))
+	} else {
+		sb.WriteString("\n")
+	}
+
+	month, minutes := busiestMonth(history)
+	sb.WriteString(fmt.Sprintf("Busiest month: %s (%s)\n", month, formatMinutes(minutes)))
+	sessions := splitSessions(history, defaultSessionGap*time.Hour)
+	sb.WriteString(fmt.Sprintf("Longest binge: %s\n", describeBinge(biggestSession(sessions))))
+
+	first, last := history[0], history[len(history)-1]
+	sb.WriteString(fmt.Sprintf("First watch: %s on %s\n", playTitle(first), first.WatchedAt.Local().Format("Jan 2")))
+	sb.WriteString(fmt.Sprintf("Last watch: %s on %s\n", playTitle(last), last.WatchedAt.Local().Format("Jan 2")))
+	return sb.String()
+}
+
+// topGenres returns the n genres with the most plays, e.g. "drama (120
+// plays)".
+func topGenres(history []trakt.HistoryItem, n int) []string {
+	counts := make(map[string]int)
+	for _, h := range history {
+		var genres []string
+		switch {
+		case h.Show != nil:
+			genres = h.Show.Genres
+		case h.Movie != nil:
+			genres = h.Movie.Genres
+		}
+		for _, g := range genres {
+			counts[g]++
+		}
+	}
+
+	names := make([]string, 0, len(counts))
+	for g := range counts {
+		names = append(names, g)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	var top []string
+	for _, g := range names[:min(len(names), n)] {
+		top = append(top, fmt.Sprintf("%s (%s plays)", g, formatCount(counts[g])))
+	}
+	return top
+}
+
+// busiestMonth returns the month with the most watch time, or with the
+// most plays if no runtimes are known, and its watch time.
+func busiestMonth(history []trakt.HistoryItem) (time.Month, int) {
+	var minutes, plays [13]int
+	for _, h := range history {
+		m := h.WatchedAt.Local().Month()
+		minutes[m] += playRuntime(h)
+		plays[m]++
+	}
+
+	busiest := time.January
+	for m := time.February; m <= time.December; m++ {
+		if minutes[m] > minutes[busiest] || (minutes[m] == minutes[busiest] && plays[m] > plays[busiest]) {
+			busiest = m
+		}
+	}
+	return busiest, minutes[busiest]
+}
+
+// playTitle names a play, e.g. "Severance S01E01" or "Dune (2021)".
+func playTitle(h trakt.HistoryItem) string {
+	switch {
+	case h.Show != nil && h.Episode != nil:
+		return fmt.Sprintf("%s S%02dE%02d", h.Show.Title, h.Episode.Season, h.Episode.Number)
+	case h.Movie != nil:
+		return h.Movie.Title + yearSuffix(h.Movie.Year)
+	}
+	return "an unknown item"
+}
@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestYearInReviewHandler(t *testing.T) {
+	severance := &trakt.Show{Title: "Severance", Runtime: 50, Genres: []string{"drama", "mystery"}, IDs: trakt.ShowIDs{Trakt: 154997}}
+	bear := &trakt.Show{Title: "The Bear", Runtime: 30, Genres: []string{"comedy", "drama"}, IDs: trakt.ShowIDs{Trakt: 191840}}
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2025, month, day, hour, 0, 0, 0, time.Local)
+	}
+	episode := func(show *trakt.Show, n int, watchedAt time.Time) trakt.HistoryItem {
+		return trakt.HistoryItem{Type: "episode", WatchedAt: watchedAt, Show: show, Episode: &trakt.Episode{Season: 1, Number: n}}
+	}
+
+	var query string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		// Newest first, as Trakt returns history
+		_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{
+			{Type: "movie", WatchedAt: at(12, 30, 22), Movie: &trakt.Movie{Title: "Dune", Year: 2021, Runtime: 120, Genres: []string{"science-fiction"}}},
+			episode(bear, 1, at(6, 1, 20)),
+			episode(severance, 4, at(3, 2, 23)),
+			episode(severance, 3, at(3, 2, 22)),
+			episode(severance, 2, at(3, 2, 21)),
+			episode(severance, 1, at(1, 3, 20)),
+		})
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	result, err := makeYearInReviewHandler(client)(context.Background(), json.RawMessage(`{"year":2025}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	text := result.Content[0].Text
+
+	if !strings.Contains(query, "start_at=") || !strings.Contains(query, "end_at=") {
+		t.Errorf("expected the year to be requested, got %q", query)
+	}
+	for _, want := range []string{
+		"**2025 in review**",
+		"Watched: 5 episodes of 2 shows and 1 movies, 5h 50m in all",
+		"1. **Severance** - 4 episodes, 3h 20m\n2. **The Bear** - 1 episodes, 30m\n",
+		"Top genres: drama (5 plays), mystery (4 plays), comedy (1 plays), science-fiction (1 plays)",
+		"Busiest month: March (2h 30m)",
+		"Longest binge: 3 plays in 2h 50m on Sun, Mar 2 from 20:10, Severance ×3",
+		"First watch: Severance S01E01 on Jan 3",
+		"Last watch: Dune (2021) on Dec 30",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q, got:\n%s", want, text)
+		}
+	}
+
+	result, _ = makeYearInReviewHandler(client)(context.Background(), json.RawMessage(`{"year":1999}`))
+	if !result.IsError {
+		t.Errorf("expected a year before Trakt to fail, got %+v", result)
+	}
+}
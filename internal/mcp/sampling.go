@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSamplingUnsupported is returned when the client did not advertise sampling.
+	ErrSamplingUnsupported = errors.New("client does not support sampling")
+	errSessionClosed       = errors.New("session closed")
+)
+
+// rpcResponse is a response from the client to a server-initiated request.
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// CreateMessage asks the client's LLM to generate a message via sampling.
+func (s *Server) CreateMessage(ctx context.Context, params CreateMessageParams) (*CreateMessageResult, error) {
+	s.mu.RLock()
+	supported := s.clientCapabilities.Sampling != nil
+	s.mu.RUnlock()
+	if !supported {
+		return nil, ErrSamplingUnsupported
+	}
+
+	raw, err := s.request(ctx, "sampling/createMessage", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result CreateMessageResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("decode sampling result: %w", err)
+	}
+	return &result, nil
+}
+
+// request sends a server-initiated request to the client and waits for its
+// response, which the read loop routes back through handleResponse.
+func (s *Server) request(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	paramsData, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshal params: %w", err)
+	}
+
+	s.mu.Lock()
+	if s.pending == nil || s.out == nil {
+		s.mu.Unlock()
+		return nil, errSessionClosed
+	}
+	s.nextRequestID++
+	id := json.RawMessage(fmt.Sprintf(`"srv-%d"`, s.nextRequestID))
+	ch := make(chan rpcResponse, 1)
+	s.pending[string(id)] = ch
+	out := s.out
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		if s.pending != nil {
+			delete(s.pending, string(id))
+		}
+		s.mu.Unlock()
+	}()
+
+	req := &Request{JSONRPC: "2.0", ID: id, Method: method, Params: paramsData}
+	if err := s.writeMessage(out, req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, errSessionClosed
+		}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s failed: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// handleResponse delivers a client response to the request awaiting it.
+func (s *Server) handleResponse(data []byte) {
+	var resp rpcResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		s.logger.Error("failed to parse response", "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	ch, ok := s.pending[string(resp.ID)]
+	if ok {
+		delete(s.pending, string(resp.ID))
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		s.logger.Warn("response for unknown request", "id", string(resp.ID))
+		return
+	}
+	ch <- resp
+}
+
+// closePending fails all outstanding server-initiated requests once the
+// client stops sending, since no responses can arrive.
+func (s *Server) closePending() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.pending {
+		close(ch)
+	}
+	s.pending = nil
+}
@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestCreateMessage_Unsupported(t *testing.T) {
+	server := NewServer(nil)
+
+	_, err := server.CreateMessage(context.Background(), CreateMessageParams{MaxTokens: 10})
+	if !errors.Is(err, ErrSamplingUnsupported) {
+		t.Errorf("expected ErrSamplingUnsupported, got %v", err)
+	}
+}
+
+func TestLogWatch_SamplingPicksAmbiguousShow(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/search"):
+			results := []trakt.SearchResult{
+				{Type: "show", Score: 500, Show: &trakt.Show{Title: "Lost", Year: 2004, IDs: trakt.ShowIDs{Trakt: 73}}},
+				{Type: "show", Score: 450, Show: &trakt.Show{Title: "Lost in Space", Year: 2018, IDs: trakt.ShowIDs{Trakt: 117523}}},
+			}
+			_ = json.NewEncoder(w).Encode(results)
+
+		case r.URL.Path == "/shows/117523/seasons/1/episodes/1":
+			_ = json.NewEncoder(w).Encode(trakt.Episode{Title: "Impact", Season: 1, Number: 1, IDs: trakt.EpisodeIDs{Trakt: 999}})
+
+		case r.URL.Path == "/sync/history/episodes/999":
+			_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{})
+
+		case r.URL.Path == "/sync/history":
+			_ = json.NewEncoder(w).Encode(trakt.SyncResponse{Added: trakt.SyncStats{Episodes: 1}})
+
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+
+	server := NewServer(nil)
+	RegisterTools(server, client)
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		_ = server.RunWithIO(context.Background(), inR, outW)
+		outW.Close()
+		close(done)
+	}()
+
+	out := bufio.NewScanner(outR)
+	send := func(msg string) {
+		t.Helper()
+		if _, err := io.WriteString(inW, msg+"\n"); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	recv := func() map[string]any {
+		t.Helper()
+		if !out.Scan() {
+			t.Fatalf("expected message, got EOF")
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(out.Bytes(), &msg); err != nil {
+			t.Fatalf("failed to decode message: %v", err)
+		}
+		return msg
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{"sampling":{}},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	recv()
+
+	send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"log_watch","arguments":{"type":"episode","showName":"Lost","season":1,"episode":1}}}`)
+
+	sampling := recv()
+	if sampling["method"] != "sampling/createMessage" {
+		t.Fatalf("expected sampling request, got %v", sampling)
+	}
+	id, _ := json.Marshal(sampling["id"])
+	send(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":{"role":"assistant","content":{"type":"text","text":"117523"},"model":"test"}}`)
+
+	var result struct {
+		Result ToolCallResult `json:"result"`
+	}
+	msg := recv()
+	data, _ := json.Marshal(msg)
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to decode tool result: %v", err)
+	}
+
+	inW.Close()
+	<-done
+
+	if result.Result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Result.Content[0].Text)
+	}
+	if !strings.Contains(result.Result.Content[0].Text, "Lost in Space") {
+		t.Errorf("expected sampled show to be logged, got: %s", result.Result.Content[0].Text)
+	}
+}
+
+func TestPickByID(t *testing.T) {
+	results := []trakt.SearchResult{
+		{Type: "show", Show: &trakt.Show{Title: "Lost", Year: 2004, IDs: trakt.ShowIDs{Trakt: 73}}},
+		{Type: "show", Show: &trakt.Show{Title: "Lost in Space", Year: 2018, IDs: trakt.ShowIDs{Trakt: 117523}}},
+	}
+	tests := []struct {
+		reply string
+		want  int // Trakt ID picked, 0 for none
+	}{
+		{"117523", 117523},
+		{"Lost in Space (2018) - Trakt ID: 117523", 117523},
+		{"The 2004 one, 73", 73},
+		{"73, or maybe 117523", 0},
+		{"2018", 0},
+		{"none", 0},
+	}
+	for _, tt := range tests {
+		got := pickByID(tt.reply, results)
+		switch {
+		case tt.want == 0 && got != nil:
+			t.Errorf("pickByID(%q) = %s, want none", tt.reply, got.Show.Title)
+		case tt.want != 0 && (got == nil || got.Show.IDs.Trakt != tt.want):
+			t.Errorf("pickByID(%q) = %+v, want Trakt ID %d", tt.reply, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// maxResolvedPlaybacks bounds the scrobbler's cache of matched items; it
+// is emptied when full.
+const maxResolvedPlaybacks = 1024
+
+// watchedProgress is how far through an item Trakt counts it as watched.
+const watchedProgress = 80
+
+// Playback is a movie or episode a media player reports playing, as the
+// player knows it.
+type Playback struct {
+	Key       string // identifies the item to the player, such as its file
+	Type      string // "movie" or "episode"
+	Title     string // of the movie, or of the episode
+	ShowTitle string
+	Season    int
+	Episode   int
+	Year      int
+	IDs       map[string]string // external IDs by type: "imdb", "tmdb", "tvdb"
+	Progress  float64           // percent played, 0-100
+	Paused    bool
+}
+
+// name names p for the log.
+func (p Playback) name() string {
+	if p.Type == "episode" {
+		return fmt.Sprintf("%s S%02dE%02d", p.ShowTitle, p.Season, p.Episode)
+	}
+	return p.Title + yearSuffix(p.Year)
+}
+
+// Scrobbler turns the successive states a media player reports into Trakt
+// scrobbles: start when an item starts or resumes, pause when it pauses,
+// and stop when it ends or another starts, which Trakt records as a watch
+// past 80%. Items are matched to Trakt once and remembered, including
+// those that can't be matched, so polling a player costs no lookups. A
+// watch Trakt can't be reached to record is logged to history instead,
+// which a Queued client keeps until Trakt is back.
+type Scrobbler struct {
+	client TraktAPI
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	resolved map[string]*trakt.Scrobble // keyed by Playback.Key; nil if unmatched
+	playing  *Playback
+	item     trakt.Scrobble
+}
+
+// NewScrobbler returns a Scrobbler sending scrobbles through client.
+func NewScrobbler(client TraktAPI, logger *slog.Logger) *Scrobbler {
+	return &Scrobbler{client: client, logger: logger, resolved: make(map[string]*trakt.Scrobble)}
+}
+
+// Update records what the player is playing now, nil for nothing, and
+// sends Trakt whatever changed since the last update.
+func (s *Scrobbler) Update(ctx context.Context, p *Playback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.playing != nil && (p == nil || p.Key != s.playing.Key) {
+		s.send(ctx, s.client.ScrobbleStop, "stop", *s.playing)
+		s.playing = nil
+	}
+	if p == nil {
+		return
+	}
+
+	if s.playing == nil {
+		item, ok := s.resolve(ctx, *p)
+		if !ok {
+			return
+		}
+		s.item = item
+		if p.Paused {
+			s.send(ctx, s.client.ScrobblePause, "pause", *p)
+		} else {
+			s.send(ctx, s.client.ScrobbleStart, "start", *p)
+		}
+		s.playing = p
+		return
+	}
+
+	switch {
+	case p.Paused && !s.playing.Paused:
+		s.send(ctx, s.client.ScrobblePause, "pause", *p)
+	case !p.Paused && s.playing.Paused:
+		s.send(ctx, s.client.ScrobbleStart, "start", *p)
+	}
+	s.playing = p
+}
+
+// send sends one scrobble of the current item at p's progress.
+func (s *Scrobbler) send(ctx context.Context, fn func(context.Context, trakt.Scrobble) (*trakt.ScrobbleResponse, error), action string, p Playback) {
+	item := s.item
+	item.Progress = p.Progress
+	resp, err := fn(ctx, item)
+	if action == "stop" && p.Progress >= watchedProgress && trakt.IsUnavailable(err) {
+		s.logWatch(ctx, item, p)
+		return
+	}
+	switch {
+	case errors.Is(err, trakt.ErrConflict):
+		s.logger.Info("already scrobbled", "title", p.name())
+	case err != nil:
+		s.logger.Warn("scrobble failed", "action", action, "title", p.name(), "error", err)
+	case resp.Action == "scrobble":
+		s.logger.Info("scrobbled", "title", p.name(), "progress", p.Progress)
+	default:
+		s.logger.Debug("scrobble sent", "action", resp.Action, "title", p.name(), "progress", p.Progress)
+	}
+}
+
+// logWatch adds an item whose stop Trakt couldn't take to history.
+func (s *Scrobbler) logWatch(ctx context.Context, item trakt.Scrobble, p Playback) {
+	watched := trakt.WatchedItem{WatchedAt: formatWatchedAt(time.Now())}
+	if item.Movie != nil {
+		watched.Movies = []trakt.Movie{*item.Movie}
+	} else {
+		watched.Episodes = []trakt.Episode{*item.Episode}
+	}
+	_, err := s.client.AddToHistory(ctx, watched)
+	switch {
+	case errors.Is(err, errWriteQueued):
+		s.logger.Info("scrobble queued until Trakt is reachable", "title", p.name())
+	case err != nil:
+		s.logger.Warn("scrobble failed", "action", "stop", "title", p.name(), "error", err)
+	default:
+		s.logger.Info("scrobbled", "title", p.name(), "progress", p.Progress)
+	}
+}
+
+// resolve matches p to a Trakt movie or episode, from the cache if it was
+// matched before.
+func (s *Scrobbler) resolve(ctx context.Context, p Playback) (trakt.Scrobble, bool) {
+	if item, ok := s.resolved[p.Key]; ok {
+		if item == nil {
+			return trakt.Scrobble{}, false
+		}
+		return *item, true
+	}
+
+	item, err := resolvePlayback(ctx, s.client, p)
+	if err != nil {
+		s.logger.Warn("couldn't match playback to Trakt", "title", p.name(), "error", err)
+		// Lookups that failed, rather than found nothing, are retried
+		if !errors.Is(err, errNoMatch) {
+			return trakt.Scrobble{}, false
+		}
+	}
+	if len(s.resolved) >= maxResolvedPlaybacks {
+		clear(s.resolved)
+	}
+	s.resolved[p.Key] = item
+	if item == nil {
+		return trakt.Scrobble{}, false
+	}
+	return *item, true
+}
+
+// errNoMatch is returned by resolvePlayback for an item Trakt doesn't have.
+var errNoMatch = errors.New("no match on Trakt")
+
+// resolvePlayback names p for a scrobble: by the external IDs the player
+// knows, or else by searching Trakt for its title.
+func resolvePlayback(ctx context.Context, client TraktAPI, p Playback) (*trakt.Scrobble, error) {
+	switch p.Type {
+	case "movie":
+		ids := trakt.MovieIDs{IMDB: p.IDs["imdb"]}
+		ids.TMDB, _ = strconv.Atoi(p.IDs["tmdb"])
+		if ids.IMDB != "" || ids.TMDB != 0 {
+			return &trakt.Scrobble{Movie: &trakt.Movie{IDs: ids}}, nil
+		}
+		results, err := client.Search(ctx, p.Title, "movie")
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			if r.Movie != nil && (p.Year == 0 || r.Movie.Year == p.Year) {
+				return &trakt.Scrobble{Movie: &trakt.Movie{IDs: trakt.MovieIDs{Trakt: r.Movie.IDs.Trakt}}}, nil
+			}
+		}
+		return nil, errNoMatch
+
+	case "episode":
+		ids := trakt.EpisodeIDs{IMDB: p.IDs["imdb"]}
+		ids.TMDB, _ = strconv.Atoi(p.IDs["tmdb"])
+		ids.TVDB, _ = strconv.Atoi(p.IDs["tvdb"])
+		if ids != (trakt.EpisodeIDs{}) {
+			return &trakt.Scrobble{Episode: &trakt.Episode{IDs: ids}}, nil
+		}
+		if p.ShowTitle == "" || p.Season == 0 && p.Episode == 0 {
+			return nil, errNoMatch
+		}
+		results, err := client.Search(ctx, p.ShowTitle, "show")
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			if r.Show == nil || p.Year != 0 && r.Show.Year != p.Year {
+				continue
+			}
+			// Resolved to the episode's ID, so a watch can be logged to
+			// history too
+			ep, err := client.GetEpisode(ctx, strconv.Itoa(r.Show.IDs.Trakt), p.Season, p.Episode)
+			if errors.Is(err, trakt.ErrNotFound) {
+				return nil, errNoMatch
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &trakt.Scrobble{Episode: &trakt.Episode{IDs: trakt.EpisodeIDs{Trakt: ep.IDs.Trakt}}}, nil
+		}
+		return nil, errNoMatch
+	}
+	return nil, errNoMatch
+}
@@ -0,0 +1,184 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mpris"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// scrobbleTrakt records scrobbles as "action item progress" and counts
+// searches.
+type scrobbleTrakt struct {
+	fakeTrakt
+	sent        []string
+	searches    int
+	unavailable bool // Trakt can't be reached to stop a scrobble
+	logged      []trakt.WatchedItem
+}
+
+func (f *scrobbleTrakt) Search(ctx context.Context, query string, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error) {
+	f.searches++
+	if query == "Dune" {
+		return []trakt.SearchResult{
+			{Type: "movie", Movie: &trakt.Movie{Title: "Dune", Year: 1984, IDs: trakt.MovieIDs{Trakt: 1}}},
+			{Type: "movie", Movie: &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 2}}},
+		}, nil
+	}
+	return nil, nil
+}
+
+func (f *scrobbleTrakt) record(action string, s trakt.Scrobble) (*trakt.ScrobbleResponse, error) {
+	var item string
+	switch {
+	case s.Movie != nil:
+		item = fmt.Sprintf("movie:%d/%s", s.Movie.IDs.Trakt, s.Movie.IDs.IMDB)
+	case s.Episode != nil:
+		item = fmt.Sprintf("episode:%d", s.Episode.IDs.TVDB)
+	}
+	f.sent = append(f.sent, fmt.Sprintf("%s %s %g", action, item, s.Progress))
+	if action == "stop" && s.Progress >= 80 {
+		action = "scrobble"
+	}
+	return &trakt.ScrobbleResponse{Action: action, Progress: s.Progress}, nil
+}
+
+func (f *scrobbleTrakt) ScrobbleStart(ctx context.Context, s trakt.Scrobble) (*trakt.ScrobbleResponse, error) {
+	return f.record("start", s)
+}
+
+func (f *scrobbleTrakt) ScrobblePause(ctx context.Context, s trakt.Scrobble) (*trakt.ScrobbleResponse, error) {
+	return f.record("pause", s)
+}
+
+func (f *scrobbleTrakt) ScrobbleStop(ctx context.Context, s trakt.Scrobble) (*trakt.ScrobbleResponse, error) {
+	if f.unavailable {
+		return nil, &trakt.APIError{StatusCode: http.StatusServiceUnavailable}
+	}
+	return f.record("stop", s)
+}
+
+func (f *scrobbleTrakt) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	f.logged = append(f.logged, item)
+	return nil, errWriteQueued
+}
+
+func TestScrobbler(t *testing.T) {
+	client := &scrobbleTrakt{fakeTrakt: fakeTrakt{authenticated: true}}
+	sc := NewScrobbler(client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	episode := func(progress float64, paused bool) *Playback {
+		return &Playback{Key: "/tv/severance-s01e01.mkv", Type: "episode", ShowTitle: "Severance", Season: 1, Episode: 1,
+			IDs: map[string]string{"tvdb": "8266930"}, Progress: progress, Paused: paused}
+	}
+	dune := func(progress float64) *Playback {
+		return &Playback{Key: "/movies/dune.mkv", Type: "movie", Title: "Dune", Year: 2021, Progress: progress}
+	}
+	home := &Playback{Key: "/home/birthday.mp4", Type: "movie", Title: "Birthday"}
+
+	for _, p := range []*Playback{
+		nil,
+		episode(1, false),
+		episode(20, false), // still playing: nothing to send
+		episode(30, true),
+		episode(30, true),
+		episode(31, false),
+		episode(95, false),
+		dune(2), // a new item stops the last
+		nil,
+		dune(0), // matched from the cache
+		nil,
+		home, // matches nothing, once
+		home,
+	} {
+		sc.Update(ctx, p)
+	}
+
+	want := []string{
+		"start episode:8266930 1",
+		"pause episode:8266930 30",
+		"start episode:8266930 31",
+		"stop episode:8266930 95",
+		"start movie:2/ 2",
+		"stop movie:2/ 2",
+		"start movie:2/ 0",
+		"stop movie:2/ 0",
+	}
+	if got := strings.Join(client.sent, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("unexpected scrobbles:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+	if client.searches != 2 {
+		t.Errorf("expected 2 searches, got %d", client.searches)
+	}
+}
+
+func TestScrobbler_QueuesWatchWhenUnavailable(t *testing.T) {
+	client := &scrobbleTrakt{fakeTrakt: fakeTrakt{authenticated: true}, unavailable: true}
+	sc := NewScrobbler(client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	episode := &Playback{Key: "a", Type: "episode", ShowTitle: "Severance", Season: 1, Episode: 1, IDs: map[string]string{"tvdb": "8266930"}}
+	for _, progress := range []float64{10, 50} {
+		p := *episode
+		p.Progress = progress
+		sc.Update(ctx, &p)
+	}
+	sc.Update(ctx, nil)
+	if len(client.logged) != 0 {
+		t.Fatalf("expected nothing logged for an unfinished episode, got %+v", client.logged)
+	}
+
+	finished := *episode
+	finished.Key, finished.Progress = "b", 92
+	sc.Update(ctx, &finished)
+	sc.Update(ctx, nil)
+	if len(client.logged) != 1 || len(client.logged[0].Episodes) != 1 || client.logged[0].Episodes[0].IDs.TVDB != 8266930 {
+		t.Errorf("expected the finished episode logged to history, got %+v", client.logged)
+	}
+}
+
+func TestMPRISPlayback(t *testing.T) {
+	players := []mpris.Player{
+		{Name: "vlc", Status: mpris.StatusPaused, URL: "file:///videos/Dune.2021.mkv"},
+		{Name: "mpv", Status: mpris.StatusPlaying, Position: 30 * time.Minute, Length: time.Hour, URL: "file:///tv/Severance.S02E03.1080p.mkv"},
+	}
+	p := mprisPlayback(players)
+	if p == nil || p.Type != "episode" || p.ShowTitle != "Severance" || p.Season != 2 || p.Episode != 3 || p.Progress != 50 || p.Paused {
+		t.Errorf("expected the playing episode, got %+v", p)
+	}
+
+	p = mprisPlayback(players[:1])
+	if p == nil || p.Type != "movie" || p.Title != "Dune" || p.Year != 2021 || !p.Paused {
+		t.Errorf("expected the paused movie, got %+v", p)
+	}
+
+	if p := mprisPlayback([]mpris.Player{{Name: "mpv", Status: mpris.StatusStopped, Title: "Dune"}}); p != nil {
+		t.Errorf("expected nothing for a stopped player, got %+v", p)
+	}
+}
+
+func TestResolvePlayback(t *testing.T) {
+	client := &scrobbleTrakt{}
+	ctx := context.Background()
+
+	item, err := resolvePlayback(ctx, client, Playback{Type: "movie", Title: "Dune", IDs: map[string]string{"imdb": "tt1160419", "tmdb": "438631"}})
+	if err != nil || item.Movie == nil || item.Movie.IDs.IMDB != "tt1160419" || item.Movie.IDs.TMDB != 438631 {
+		t.Errorf("expected the movie by its IDs, got %+v, %v", item, err)
+	}
+	if client.searches != 0 {
+		t.Errorf("expected no search for a movie with IDs")
+	}
+
+	client.searches = 0
+	if _, err := resolvePlayback(ctx, client, Playback{Type: "episode", ShowTitle: "Nowhere", Season: 1, Episode: 1}); err != errNoMatch {
+		t.Errorf("expected errNoMatch, got %v", err)
+	}
+}
@@ -4,30 +4,111 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"net/http"
 	"os"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 )
 
 const (
-	ProtocolVersion = "2024-11-05"
-	ServerName      = "trakt-mcp-go"
-	ServerVersion   = "0.1.0"
+	ProtocolVersion       = "2024-11-05" // baseline revision
+	LatestProtocolVersion = "2025-06-18"
+	ServerName            = "trakt-mcp-go"
 )
 
+const (
+	// DefaultToolTimeout is the default limit on a single tool call.
+	DefaultToolTimeout = 60 * time.Second
+	// DefaultDrainTimeout is how long in-flight requests may run after shutdown begins.
+	DefaultDrainTimeout = 10 * time.Second
+	// DefaultMaxConcurrentTools bounds simultaneous tool calls to stay within
+	// Trakt's rate limit.
+	DefaultMaxConcurrentTools = 4
+	// DefaultResponseBudget is the default cap, in bytes, on the text of a
+	// list tool's result, about 12k tokens.
+	DefaultResponseBudget = 50_000
+)
+
+// SupportedProtocolVersions lists the MCP revisions the server can speak,
+// newest first.
+var SupportedProtocolVersions = []string{LatestProtocolVersion, "2025-03-26", ProtocolVersion}
+
+// Protocol revisions that introduced optional features.
+const (
+	annotationsVersion       = "2025-03-26"
+	structuredContentVersion = "2025-06-18"
+	resourceLinkVersion      = "2025-06-18"
+)
+
+// negotiateVersion echoes the client's requested revision if supported, and
+// otherwise offers the latest revision the server supports.
+func negotiateVersion(requested string) string {
+	for _, v := range SupportedProtocolVersions {
+		if v == requested {
+			return v
+		}
+	}
+	return LatestProtocolVersion
+}
+
 // ToolHandler is a function that handles a tool call.
 type ToolHandler func(ctx context.Context, args json.RawMessage) (ToolCallResult, error)
 
+// ResourceHandler is a function that reads a resource.
+type ResourceHandler func(ctx context.Context, uri string) (ResourceReadResult, error)
+
+// PromptHandler is a function that renders a prompt with its arguments.
+type PromptHandler func(ctx context.Context, args map[string]string) (PromptGetResult, error)
+
 // Server is an MCP server that communicates over stdio.
 type Server struct {
-	tools    map[string]Tool
-	handlers map[string]ToolHandler
-	logger   *slog.Logger
-
-	mu          sync.RWMutex
-	initialized bool
+	tools            map[string]Tool
+	handlers         map[string]ToolHandler
+	resources        map[string]Resource
+	resourceHandlers map[string]ResourceHandler
+	prompts          map[string]Prompt
+	promptHandlers   map[string]PromptHandler
+	logger           *slog.Logger
+	pageSize         int
+	framing          Framing
+	tracer           *Tracer
+	toolTimeout      time.Duration
+	drainTimeout     time.Duration
+	toolSem          chan struct{} // bounds concurrent tool calls; nil means unlimited
+	metrics          *Metrics
+	started          time.Time
+	confirmations    *confirmations // outstanding tokens of destructive tools
+
+	mu                 sync.RWMutex
+	initialized        bool // initialize request handled
+	ready              bool // notifications/initialized received
+	strict             bool
+	allowedTools       map[string]bool // nil exposes every registered tool
+	readOnly           bool
+	confirmDestructive bool
+	outputStyle        OutputStyle
+	templates          *Templates // user output templates; nil for built-in output
+	responseBudget     int        // bytes; 0 for unlimited
+	language           string
+	protocolVersion    string    // negotiated MCP revision
+	out                io.Writer // set while RunWithIO is active, for notifications
+
+	httpRoutes map[string]http.Handler // served beside the HTTP transports, guarded by mu
+
+	clientCapabilities Capabilities
+	clientInfo         Implementation
+	pending            map[string]chan rpcResponse // server-initiated requests awaiting replies
+	nextRequestID      int64
+
+	writeMu       sync.Mutex
+	contentLength bool // frame output with Content-Length headers
 }
 
 // NewServer creates a new MCP server.
@@ -36,19 +117,203 @@ func NewServer(logger *slog.Logger) *Server {
 		logger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
 	}
 	return &Server{
-		tools:    make(map[string]Tool),
-		handlers: make(map[string]ToolHandler),
-		logger:   logger,
+		tools:            make(map[string]Tool),
+		handlers:         make(map[string]ToolHandler),
+		resources:        make(map[string]Resource),
+		resourceHandlers: make(map[string]ResourceHandler),
+		prompts:          make(map[string]Prompt),
+		promptHandlers:   make(map[string]PromptHandler),
+		logger:           logger,
+		pageSize:         defaultPageSize,
+		framing:          FramingAuto,
+		metrics:          newMetrics(),
+		started:          time.Now(),
+		confirmations:    newConfirmations(),
+		toolTimeout:      DefaultToolTimeout,
+		drainTimeout:     DefaultDrainTimeout,
+		toolSem:          make(chan struct{}, DefaultMaxConcurrentTools),
+		responseBudget:   DefaultResponseBudget,
 	}
 }
 
-// RegisterTool registers a tool with the server.
+// RegisterTool registers a tool with the server. If a client session is
+// active, it is notified that the tool list changed.
 func (s *Server) RegisterTool(tool Tool, handler ToolHandler) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.tools[tool.Name] = tool
 	s.handlers[tool.Name] = handler
+	s.mu.Unlock()
+
 	s.logger.Debug("registered tool", "name", tool.Name)
+	s.notifyToolsChanged()
+}
+
+// Metrics returns the server's metrics, e.g. to attach
+// Metrics.TraktMiddleware to the Trakt client.
+func (s *Server) Metrics() *Metrics {
+	return s.metrics
+}
+
+// Tools returns the tools exposed to clients, sorted by name.
+func (s *Server) Tools() []Tool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tools := make([]Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		if s.toolAllowed(t.Name) {
+			tools = append(tools, t)
+		}
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// UnregisterTool removes a tool from the server. If a client session is
+// active, it is notified that the tool list changed.
+func (s *Server) UnregisterTool(name string) {
+	s.mu.Lock()
+	_, ok := s.tools[name]
+	delete(s.tools, name)
+	delete(s.handlers, name)
+	s.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	s.logger.Debug("unregistered tool", "name", name)
+	s.notifyToolsChanged()
+}
+
+// Notify sends a JSON-RPC notification to the client. It is a no-op if the
+// server is not running.
+func (s *Server) Notify(method string, params any) error {
+	s.mu.RLock()
+	out := s.out
+	s.mu.RUnlock()
+
+	if out == nil {
+		return nil
+	}
+	return s.writeMessage(out, &Notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// notifyToolsChanged tells an initialized client to refetch tools/list.
+func (s *Server) notifyToolsChanged() {
+	s.mu.RLock()
+	initialized := s.initialized
+	s.mu.RUnlock()
+
+	if !initialized {
+		return
+	}
+	if err := s.Notify("notifications/tools/list_changed", nil); err != nil {
+		s.logger.Error("failed to send notification", "error", err)
+	}
+}
+
+// RegisterResource registers a readable resource with the server.
+func (s *Server) RegisterResource(resource Resource, handler ResourceHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources[resource.URI] = resource
+	s.resourceHandlers[resource.URI] = handler
+	s.logger.Debug("registered resource", "uri", resource.URI)
+}
+
+// RegisterPrompt registers a prompt with the server.
+func (s *Server) RegisterPrompt(prompt Prompt, handler PromptHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompts[prompt.Name] = prompt
+	s.promptHandlers[prompt.Name] = handler
+	s.logger.Debug("registered prompt", "name", prompt.Name)
+}
+
+// SetStrict enables strict handshake mode, in which tool calls and resource
+// reads are rejected until the client has sent notifications/initialized.
+func (s *Server) SetStrict(strict bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.strict = strict
+}
+
+// SetReadOnly enables read-only mode, in which RegisterTools leaves out
+// every tool that modifies the user's Trakt account or writes files. Call
+// it before registering tools.
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readOnly = readOnly
+}
+
+// SetOutputStyle selects whether tool and resource text uses emoji and
+// Markdown (OutputRich, the default) or neither (OutputPlain).
+func (s *Server) SetOutputStyle(style OutputStyle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputStyle = style
+}
+
+// OutputStyle returns the configured output style.
+func (s *Server) OutputStyle() OutputStyle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.outputStyle == "" {
+		return OutputRich
+	}
+	return s.outputStyle
+}
+
+// ReadOnly reports whether read-only mode is enabled.
+func (s *Server) ReadOnly() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.readOnly
+}
+
+// SetToolAllowlist limits the tools exposed to clients to the named ones.
+// Other registered tools are hidden from tools/list and rejected by
+// tools/call. An empty list exposes every tool.
+func (s *Server) SetToolAllowlist(names []string) {
+	var allowed map[string]bool
+	if len(names) > 0 {
+		allowed = make(map[string]bool, len(names))
+		for _, name := range names {
+			allowed[name] = true
+		}
+	}
+
+	s.mu.Lock()
+	changed := !maps.Equal(s.allowedTools, allowed)
+	s.allowedTools = allowed
+	s.mu.Unlock()
+
+	if changed {
+		s.notifyToolsChanged()
+	}
+}
+
+// toolAllowed reports whether a tool is exposed. Callers must hold s.mu.
+func (s *Server) toolAllowed(name string) bool {
+	return s.allowedTools == nil || s.allowedTools[name]
+}
+
+// SetTracer enables protocol tracing of every inbound and outbound message.
+func (s *Server) SetTracer(t *Tracer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tracer = t
+}
+
+// trace records a message if tracing is enabled.
+func (s *Server) trace(direction string, msg []byte) {
+	s.mu.RLock()
+	tracer := s.tracer
+	s.mu.RUnlock()
+	if tracer != nil {
+		tracer.Trace(direction, msg)
+	}
 }
 
 // Run starts the server, reading from stdin and writing to stdout.
@@ -56,62 +321,274 @@ func (s *Server) Run(ctx context.Context) error {
 	return s.RunWithIO(ctx, os.Stdin, os.Stdout)
 }
 
+// SetToolTimeout bounds how long a single tool call may run. Zero disables
+// the limit.
+func (s *Server) SetToolTimeout(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toolTimeout = d
+}
+
+// SetResponseBudget caps the size in bytes of a list tool's text result.
+// Longer results are cut at an item boundary and end with a cursor for
+// the rest. Zero or less removes the cap.
+func (s *Server) SetResponseBudget(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responseBudget = max(n, 0)
+}
+
+// ResponseBudget returns the configured response size cap in bytes.
+func (s *Server) ResponseBudget() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.responseBudget
+}
+
+// SetMaxConcurrentTools limits how many tool calls execute at once; further
+// calls wait for a free slot. Zero or less removes the limit.
+func (s *Server) SetMaxConcurrentTools(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n <= 0 {
+		s.toolSem = nil
+		return
+	}
+	// Keep the current semaphore on a reload that doesn't change the limit
+	if cap(s.toolSem) == n {
+		return
+	}
+	s.toolSem = make(chan struct{}, n)
+}
+
+// SetDrainTimeout sets the grace period in-flight requests get to finish
+// once the server's context is cancelled.
+func (s *Server) SetDrainTimeout(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drainTimeout = d
+}
+
+// SetFraming selects how messages are delimited on streams passed to
+// RunWithIO. The default, FramingAuto, accepts both newline-delimited and
+// Content-Length framed input and answers in kind.
+func (s *Server) SetFraming(f Framing) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.framing = f
+}
+
+// HandleHTTP serves h at pattern, a net/http.ServeMux pattern, beside the
+// sse and ws transports, e.g. for feeds that calendar apps poll. Routes
+// must be added before the transport starts.
+func (s *Server) HandleHTTP(pattern string, h http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.httpRoutes == nil {
+		s.httpRoutes = make(map[string]http.Handler)
+	}
+	s.httpRoutes[pattern] = h
+}
+
+// httpMux returns a mux serving the routes added with HandleHTTP, for an
+// HTTP transport to add its own endpoints to.
+func (s *Server) httpMux() *http.ServeMux {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	mux := http.NewServeMux()
+	for pattern, h := range s.httpRoutes {
+		mux.Handle(pattern, h)
+	}
+	return mux
+}
+
 // RunWithIO starts the server with custom I/O streams (useful for testing).
+//
+// Each request is handled on its own goroutine so a slow tool call does not
+// block unrelated requests; responses are written as they complete. When ctx
+// is cancelled the server stops reading and gives in-flight requests the
+// drain grace period to finish before returning.
 func (s *Server) RunWithIO(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.mu.RLock()
+	framing := s.framing
+	s.mu.RUnlock()
+	return s.serve(ctx, in, out, framing)
+}
+
+// serve runs a session over a stream using the given framing. Message-based
+// transports always feed it newline-delimited input.
+func (s *Server) serve(ctx context.Context, in io.Reader, out io.Writer, framing Framing) error {
+	splitter := &frameSplitter{mode: framing, onDetect: func() {
+		s.writeMu.Lock()
+		s.contentLength = true
+		s.writeMu.Unlock()
+	}}
+
 	scanner := bufio.NewScanner(in)
 	// Increase buffer size for large messages
-	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+	scanner.Buffer(make([]byte, 1024*1024), maxMessageSize)
+	scanner.Split(splitter.split)
+
+	s.writeMu.Lock()
+	s.contentLength = framing == FramingContentLength
+	s.writeMu.Unlock()
 
-	s.logger.Info("server starting", "version", ServerVersion)
+	s.logger.Info("server starting", "version", CurrentBuild().SemVer())
 
-	for scanner.Scan() {
+	// Each run is a new session, so the client must handshake again
+	s.mu.Lock()
+	s.out = out
+	s.initialized = false
+	s.ready = false
+	s.protocolVersion = ""
+	s.clientCapabilities = Capabilities{}
+	s.clientInfo = Implementation{}
+	s.pending = make(map[string]chan rpcResponse)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.out = nil
+		s.mu.Unlock()
+	}()
+
+	// Requests run on a context that survives shutdown so in-flight calls
+	// can finish within the drain grace period
+	reqCtx, cancelRequests := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRequests()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	// Runs before wg.Wait so handlers blocked on the client can finish
+	defer s.closePending()
+
+	// Read on a separate goroutine so cancellation doesn't wait for input
+	lines := make(chan []byte)
+	scanErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	for {
+		var line []byte
 		select {
 		case <-ctx.Done():
+			s.drain(&wg, cancelRequests)
 			return ctx.Err()
-		default:
+		case l, ok := <-lines:
+			if !ok {
+				if err := <-scanErr; err != nil {
+					return fmt.Errorf("scanner error: %w", err)
+				}
+				return nil
+			}
+			line = l
 		}
 
-		line := scanner.Bytes()
 		if len(line) == 0 {
 			continue
 		}
+		s.trace("in", line)
 
-		resp := s.handleMessage(ctx, line)
-		if resp != nil {
-			if err := s.writeResponse(out, resp); err != nil {
-				s.logger.Error("failed to write response", "error", err)
-			}
+		req, errResp := s.parseRequest(line)
+		if req == nil {
+			s.respond(out, errResp)
+			continue
+		}
+
+		// Messages without a method are replies to our own requests
+		if req.Method == "" && !req.IsNotification() {
+			s.handleResponse(line)
+			continue
 		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanner error: %w", err)
+		// The handshake and notifications are handled inline so they take
+		// effect before any later request is dispatched
+		if req.Method == "initialize" || req.IsNotification() {
+			s.respond(out, s.handleRequest(reqCtx, req))
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.respond(out, s.handleRequest(reqCtx, req))
+		}()
 	}
+}
 
-	return nil
+// drain gives in-flight requests the grace period to finish and write their
+// responses, then cancels whatever is still running.
+func (s *Server) drain(wg *sync.WaitGroup, cancelRequests context.CancelFunc) {
+	s.mu.RLock()
+	grace := s.drainTimeout
+	s.mu.RUnlock()
+
+	s.closePending()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	s.logger.Info("draining in-flight requests", "grace", grace)
+	select {
+	case <-done:
+	case <-time.After(grace):
+		s.logger.Warn("drain grace period expired, cancelling remaining requests")
+		cancelRequests()
+	}
 }
 
-func (s *Server) handleMessage(ctx context.Context, data []byte) *Response {
+// parseRequest decodes and validates a JSON-RPC message. On failure it
+// returns the error response to send instead, or none for an invalid
+// message without an id, which has nowhere to be answered.
+func (s *Server) parseRequest(data []byte) (*Request, *Response) {
 	var req Request
 	if err := json.Unmarshal(data, &req); err != nil {
 		s.logger.Error("failed to parse request", "error", err)
-		return &Response{
+		return nil, &Response{
 			JSONRPC: "2.0",
 			Error:   &Error{Code: ParseError, Message: "Parse error"},
 		}
 	}
 
 	if req.JSONRPC != "2.0" {
-		return &Response{
+		if req.IsNotification() {
+			s.logger.Debug("dropping notification with invalid JSON-RPC version", "method", req.Method)
+			return nil, nil
+		}
+		return nil, &Response{
 			JSONRPC: "2.0",
 			ID:      req.ID,
 			Error:   &Error{Code: InvalidRequest, Message: "Invalid JSON-RPC version"},
 		}
 	}
 
+	return &req, nil
+}
+
+// handleRequest dispatches a request and builds its response. Notifications
+// never get a response, even on error.
+func (s *Server) handleRequest(ctx context.Context, req *Request) *Response {
 	s.logger.Debug("handling request", "method", req.Method)
 
 	result, err := s.dispatch(ctx, req.Method, req.Params)
+	if req.IsNotification() {
+		if err != nil {
+			s.logger.Debug("notification failed", "method", req.Method, "error", err.Message)
+		}
+		return nil
+	}
 	if err != nil {
 		return &Response{
 			JSONRPC: "2.0",
@@ -127,17 +604,37 @@ func (s *Server) handleMessage(ctx context.Context, data []byte) *Response {
 	}
 }
 
+// respond writes a response, logging rather than returning write failures.
+func (s *Server) respond(out io.Writer, resp *Response) {
+	if resp == nil {
+		return
+	}
+	if err := s.writeMessage(out, resp); err != nil {
+		s.logger.Error("failed to write response", "error", err)
+	}
+}
+
 func (s *Server) dispatch(ctx context.Context, method string, params json.RawMessage) (any, *Error) {
 	switch method {
 	case "initialize":
 		return s.handleInitialize(params)
-	case "initialized":
-		// Notification, no response needed
+	case "notifications/initialized", "initialized":
+		s.mu.Lock()
+		s.ready = true
+		s.mu.Unlock()
 		return nil, nil
 	case "tools/list":
-		return s.handleToolsList()
+		return s.handleToolsList(params)
 	case "tools/call":
 		return s.handleToolsCall(ctx, params)
+	case "resources/list":
+		return s.handleResourcesList(params)
+	case "resources/read":
+		return s.handleResourcesRead(ctx, params)
+	case "prompts/list":
+		return s.handlePromptsList(params)
+	case "prompts/get":
+		return s.handlePromptsGet(ctx, params)
 	default:
 		return nil, &Error{Code: MethodNotFound, Message: fmt.Sprintf("Method not found: %s", method)}
 	}
@@ -149,47 +646,97 @@ func (s *Server) handleInitialize(params json.RawMessage) (*InitializeResult, *E
 		return nil, &Error{Code: InvalidParams, Message: "Invalid initialize params"}
 	}
 
+	version := negotiateVersion(p.ProtocolVersion)
+
 	s.mu.Lock()
 	s.initialized = true
+	s.protocolVersion = version
+	s.clientCapabilities = p.Capabilities
+	s.clientInfo = p.ClientInfo
 	s.mu.Unlock()
 
 	s.logger.Info("initialized",
 		"client", p.ClientInfo.Name,
 		"clientVersion", p.ClientInfo.Version,
 		"protocolVersion", p.ProtocolVersion,
+		"negotiatedVersion", version,
 	)
 
 	return &InitializeResult{
-		ProtocolVersion: ProtocolVersion,
+		ProtocolVersion: version,
 		Capabilities: Capabilities{
-			Tools: &ToolsCapability{},
+			Tools:     &ToolsCapability{ListChanged: true},
+			Resources: &ResourcesCapability{},
+			Prompts:   &PromptsCapability{},
+			Logging:   &LoggingCapability{},
 		},
 		ServerInfo: Implementation{
 			Name:    ServerName,
-			Version: ServerVersion,
+			Version: CurrentBuild().SemVer(),
 		},
 	}, nil
 }
 
-func (s *Server) handleToolsList() (*ToolsListResult, *Error) {
+// supports reports whether the negotiated protocol revision is at least
+// minVersion. Revisions are dates, so they compare lexically.
+func (s *Server) supports(minVersion string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.protocolVersion >= minVersion
+}
 
+// checkInitialized returns an error if the handshake has not progressed far
+// enough to serve requests. Strict mode additionally requires the client's
+// notifications/initialized and reports violations as InvalidRequest.
+func (s *Server) checkInitialized() *Error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.strict {
+		if !s.initialized || !s.ready {
+			return &Error{Code: InvalidRequest, Message: "Server not initialized"}
+		}
+		return nil
+	}
+	if !s.initialized {
+		return &Error{Code: InternalError, Message: "Server not initialized"}
+	}
+	return nil
+}
+
+func (s *Server) handleToolsList(params json.RawMessage) (*ToolsListResult, *Error) {
+	cursor, perr := parseCursor(params)
+	if perr != nil {
+		return nil, perr
+	}
+
+	annotations := s.supports(annotationsVersion)
+
+	s.mu.RLock()
 	tools := make([]Tool, 0, len(s.tools))
 	for _, t := range s.tools {
+		if !s.toolAllowed(t.Name) {
+			continue
+		}
+		if !annotations {
+			t.Annotations = nil
+		}
 		tools = append(tools, t)
 	}
+	s.mu.RUnlock()
+
+	page, next, perr := paginate(tools, func(t Tool) string { return t.Name }, cursor, s.pageSize)
+	if perr != nil {
+		return nil, perr
+	}
 
-	return &ToolsListResult{Tools: tools}, nil
+	return &ToolsListResult{Tools: page, NextCursor: next}, nil
 }
 
 func (s *Server) handleToolsCall(ctx context.Context, params json.RawMessage) (*ToolCallResult, *Error) {
 	// Verify server is initialized before handling tool calls
-	s.mu.RLock()
-	initialized := s.initialized
-	s.mu.RUnlock()
-	if !initialized {
-		return nil, &Error{Code: InternalError, Message: "Server not initialized"}
+	if err := s.checkInitialized(); err != nil {
+		return nil, err
 	}
 
 	var p ToolCallParams
@@ -198,16 +745,33 @@ func (s *Server) handleToolsCall(ctx context.Context, params json.RawMessage) (*
 	}
 
 	s.mu.RLock()
+	tool := s.tools[p.Name]
 	handler, ok := s.handlers[p.Name]
+	ok = ok && s.toolAllowed(p.Name)
 	s.mu.RUnlock()
 
 	if !ok {
 		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Unknown tool: %s", p.Name)}
 	}
 
+	if err := validateArgs(tool.InputSchema, p.Arguments); err != nil {
+		var data any
+		if ve, ok := err.(*validationError); ok {
+			data = map[string]string{"pointer": ve.Pointer}
+		}
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Invalid arguments: %s", err), Data: data}
+	}
+
 	s.logger.Debug("calling tool", "name", p.Name)
 
-	result, err := handler(ctx, p.Arguments)
+	if p.Meta != nil && p.Meta.ProgressToken != nil {
+		ctx = withProgressToken(ctx, p.Meta.ProgressToken)
+	}
+	ctx = withLanguage(ctx, s.Language())
+
+	start := time.Now()
+	result, err := s.callWithTimeout(ctx, p.Name, handler, p.Arguments)
+	s.metrics.observeToolCall(p.Name, err != nil || result.IsError, time.Since(start))
 	if err != nil {
 		s.logger.Error("tool error", "name", p.Name, "error", err)
 		return &ToolCallResult{
@@ -216,12 +780,188 @@ func (s *Server) handleToolsCall(ctx context.Context, params json.RawMessage) (*
 		}, nil
 	}
 
+	// Older clients only understand the text content blocks
+	if !s.supports(structuredContentVersion) {
+		result.StructuredContent = nil
+	}
+	if !s.supports(resourceLinkVersion) {
+		result.Content = slices.DeleteFunc(result.Content, func(c Content) bool { return c.Type == "resource_link" })
+	}
+	s.applyStyle(&result)
+
+	return &result, nil
+}
+
+// callWithTimeout runs a tool handler under the configured timeout and
+// concurrency limit. The handler runs on its own goroutine so one that
+// ignores cancellation still yields a timely error instead of hanging the
+// request; it keeps its slot until it actually returns, so stuck handlers
+// can't run past the limit.
+func (s *Server) callWithTimeout(ctx context.Context, name string, handler ToolHandler, args json.RawMessage) (ToolCallResult, error) {
+	s.mu.RLock()
+	timeout := s.toolTimeout
+	sem := s.toolSem
+	s.mu.RUnlock()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Time spent queued for a slot counts toward the timeout
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ToolCallResult{}, fmt.Errorf("tool %s: gave up waiting for a free slot: %w", name, ctx.Err())
+		}
+	}
+
+	type outcome struct {
+		result ToolCallResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		if sem != nil {
+			defer func() { <-sem }()
+		}
+		result, err := handler(ctx, args)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ToolCallResult{}, fmt.Errorf("tool %s timed out after %s", name, timeout)
+		}
+		return ToolCallResult{}, ctx.Err()
+	}
+}
+
+func (s *Server) handleResourcesList(params json.RawMessage) (*ResourcesListResult, *Error) {
+	cursor, perr := parseCursor(params)
+	if perr != nil {
+		return nil, perr
+	}
+
+	s.mu.RLock()
+	resources := make([]Resource, 0, len(s.resources))
+	for _, r := range s.resources {
+		resources = append(resources, r)
+	}
+	s.mu.RUnlock()
+
+	page, next, perr := paginate(resources, func(r Resource) string { return r.URI }, cursor, s.pageSize)
+	if perr != nil {
+		return nil, perr
+	}
+
+	return &ResourcesListResult{Resources: page, NextCursor: next}, nil
+}
+
+func (s *Server) handleResourcesRead(ctx context.Context, params json.RawMessage) (*ResourceReadResult, *Error) {
+	if err := s.checkInitialized(); err != nil {
+		return nil, err
+	}
+
+	var p ResourceReadParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &Error{Code: InvalidParams, Message: "Invalid resources/read params"}
+	}
+
+	s.mu.RLock()
+	handler, ok := s.resourceHandlers[p.URI]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, &Error{Code: ResourceNotFound, Message: fmt.Sprintf("Resource not found: %s", p.URI)}
+	}
+
+	s.logger.Debug("reading resource", "uri", p.URI)
+
+	result, err := handler(withLanguage(ctx, s.Language()), p.URI)
+	if err != nil {
+		// Resources have no isError flag, so failures surface as JSON-RPC errors
+		s.logger.Error("resource error", "uri", p.URI, "error", err)
+		return nil, &Error{Code: InternalError, Message: err.Error()}
+	}
+	s.applyResourceStyle(&result)
+
 	return &result, nil
 }
 
-func (s *Server) writeResponse(out io.Writer, resp *Response) error {
-	data, err := json.Marshal(resp)
+func (s *Server) handlePromptsList(params json.RawMessage) (*PromptsListResult, *Error) {
+	cursor, perr := parseCursor(params)
+	if perr != nil {
+		return nil, perr
+	}
+
+	s.mu.RLock()
+	prompts := make([]Prompt, 0, len(s.prompts))
+	for _, p := range s.prompts {
+		prompts = append(prompts, p)
+	}
+	s.mu.RUnlock()
+
+	page, next, perr := paginate(prompts, func(p Prompt) string { return p.Name }, cursor, s.pageSize)
+	if perr != nil {
+		return nil, perr
+	}
+
+	return &PromptsListResult{Prompts: page, NextCursor: next}, nil
+}
+
+func (s *Server) handlePromptsGet(ctx context.Context, params json.RawMessage) (*PromptGetResult, *Error) {
+	if err := s.checkInitialized(); err != nil {
+		return nil, err
+	}
+
+	var p PromptGetParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &Error{Code: InvalidParams, Message: "Invalid prompts/get params"}
+	}
+
+	s.mu.RLock()
+	prompt, ok := s.prompts[p.Name]
+	handler := s.promptHandlers[p.Name]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Unknown prompt: %s", p.Name)}
+	}
+	for _, arg := range prompt.Arguments {
+		if arg.Required && p.Arguments[arg.Name] == "" {
+			return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Missing required argument: %s", arg.Name)}
+		}
+	}
+
+	s.logger.Debug("getting prompt", "name", p.Name)
+
+	result, err := handler(withLanguage(ctx, s.Language()), p.Arguments)
 	if err != nil {
+		s.logger.Error("prompt error", "name", p.Name, "error", err)
+		return nil, &Error{Code: InternalError, Message: err.Error()}
+	}
+
+	return &result, nil
+}
+
+func (s *Server) writeMessage(out io.Writer, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	s.trace("out", data)
+
+	// Serialize writes so concurrent messages don't interleave on the wire
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.contentLength {
+		_, err = fmt.Fprintf(out, "Content-Length: %d\r\n\r\n%s", len(data), data)
 		return err
 	}
 	_, err = fmt.Fprintf(out, "%s\n", data)
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestServer_Initialize(t *testing.T) {
@@ -250,3 +254,704 @@ func TestServer_UninitializedToolCall(t *testing.T) {
 		t.Errorf("expected error code %d, got %d", InternalError, resp.Error.Code)
 	}
 }
+
+func TestServer_ResourcesListAndRead(t *testing.T) {
+	server := NewServer(nil)
+
+	server.RegisterResource(Resource{
+		URI:      "test://greeting",
+		Name:     "Greeting",
+		MimeType: "text/plain",
+	}, func(ctx context.Context, uri string) (ResourceReadResult, error) {
+		return ResourceReadResult{Contents: []ResourceContents{{URI: uri, Text: "hello"}}}, nil
+	})
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	listReq := `{"jsonrpc":"2.0","id":2,"method":"resources/list","params":{}}`
+	readReq := `{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"test://greeting"}}`
+	missingReq := `{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"test://missing"}}`
+	input := initReq + "\n" + listReq + "\n" + readReq + "\n" + missingReq + "\n"
+
+	var buf bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		_ = server.RunWithIO(ctx, strings.NewReader(input), &buf)
+		close(done)
+	}()
+
+	<-done
+
+	lines := responsesByID(t, buf.String())
+	if len(lines) < 4 {
+		t.Fatalf("expected 4 responses, got %d: %s", len(lines), buf.String())
+	}
+
+	var initResp struct {
+		Result InitializeResult `json:"result"`
+	}
+	if err := json.Unmarshal(lines["1"], &initResp); err != nil {
+		t.Fatalf("failed to decode initialize response: %v", err)
+	}
+	if initResp.Result.Capabilities.Resources == nil {
+		t.Error("expected resources capability to be advertised")
+	}
+
+	var listResp struct {
+		Result ResourcesListResult `json:"result"`
+	}
+	if err := json.Unmarshal(lines["2"], &listResp); err != nil {
+		t.Fatalf("failed to decode resources/list response: %v", err)
+	}
+	if len(listResp.Result.Resources) != 1 || listResp.Result.Resources[0].URI != "test://greeting" {
+		t.Errorf("unexpected resources: %+v", listResp.Result.Resources)
+	}
+
+	var readResp struct {
+		Result ResourceReadResult `json:"result"`
+		Error  *Error             `json:"error"`
+	}
+	if err := json.Unmarshal(lines["3"], &readResp); err != nil {
+		t.Fatalf("failed to decode resources/read response: %v", err)
+	}
+	if readResp.Error != nil {
+		t.Fatalf("unexpected error: %v", readResp.Error)
+	}
+	if len(readResp.Result.Contents) != 1 || readResp.Result.Contents[0].Text != "hello" {
+		t.Errorf("unexpected contents: %+v", readResp.Result.Contents)
+	}
+
+	var missingResp Response
+	if err := json.Unmarshal(lines["4"], &missingResp); err != nil {
+		t.Fatalf("failed to decode resources/read response: %v", err)
+	}
+	if missingResp.Error == nil || missingResp.Error.Code != ResourceNotFound {
+		t.Errorf("expected ResourceNotFound error, got %+v", missingResp.Error)
+	}
+}
+
+func TestServer_PromptsListAndGet(t *testing.T) {
+	server := NewServer(nil)
+
+	server.RegisterPrompt(Prompt{
+		Name:      "greet",
+		Arguments: []PromptArgument{{Name: "name", Required: true}},
+	}, func(ctx context.Context, args map[string]string) (PromptGetResult, error) {
+		return PromptGetResult{Messages: []PromptMessage{{Role: "user", Content: TextContent("Say hello to " + args["name"])}}}, nil
+	})
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	listReq := `{"jsonrpc":"2.0","id":2,"method":"prompts/list","params":{}}`
+	getReq := `{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"greet","arguments":{"name":"Sam"}}}`
+	missingArgReq := `{"jsonrpc":"2.0","id":4,"method":"prompts/get","params":{"name":"greet"}}`
+	unknownReq := `{"jsonrpc":"2.0","id":5,"method":"prompts/get","params":{"name":"missing"}}`
+	input := initReq + "\n" + listReq + "\n" + getReq + "\n" + missingArgReq + "\n" + unknownReq + "\n"
+
+	var buf bytes.Buffer
+	if err := server.RunWithIO(context.Background(), strings.NewReader(input), &buf); err != nil {
+		t.Fatalf("RunWithIO failed: %v", err)
+	}
+
+	lines := responsesByID(t, buf.String())
+	if len(lines) < 5 {
+		t.Fatalf("expected 5 responses, got %d: %s", len(lines), buf.String())
+	}
+
+	var initResp struct {
+		Result InitializeResult `json:"result"`
+	}
+	if err := json.Unmarshal(lines["1"], &initResp); err != nil {
+		t.Fatalf("failed to decode initialize response: %v", err)
+	}
+	if initResp.Result.Capabilities.Prompts == nil {
+		t.Error("expected prompts capability to be advertised")
+	}
+
+	var listResp struct {
+		Result PromptsListResult `json:"result"`
+	}
+	if err := json.Unmarshal(lines["2"], &listResp); err != nil {
+		t.Fatalf("failed to decode prompts/list response: %v", err)
+	}
+	if len(listResp.Result.Prompts) != 1 || listResp.Result.Prompts[0].Name != "greet" {
+		t.Errorf("unexpected prompts: %+v", listResp.Result.Prompts)
+	}
+
+	var getResp struct {
+		Result PromptGetResult `json:"result"`
+		Error  *Error          `json:"error"`
+	}
+	if err := json.Unmarshal(lines["3"], &getResp); err != nil {
+		t.Fatalf("failed to decode prompts/get response: %v", err)
+	}
+	if getResp.Error != nil {
+		t.Fatalf("unexpected error: %v", getResp.Error)
+	}
+	if len(getResp.Result.Messages) != 1 || getResp.Result.Messages[0].Content.Text != "Say hello to Sam" {
+		t.Errorf("unexpected messages: %+v", getResp.Result.Messages)
+	}
+
+	for _, id := range []string{"4", "5"} {
+		var resp Response
+		if err := json.Unmarshal(lines[id], &resp); err != nil {
+			t.Fatalf("failed to decode prompts/get response: %v", err)
+		}
+		if resp.Error == nil || resp.Error.Code != InvalidParams {
+			t.Errorf("request %s: expected InvalidParams, got %+v", id, resp.Error)
+		}
+	}
+}
+
+func TestServer_ConcurrentDispatch(t *testing.T) {
+	server := NewServer(nil)
+
+	// The slow tool only finishes once tools/list has been answered, which
+	// deadlocks (and times out) if requests are handled sequentially.
+	out := &notifyWriter{match: `"id":3`, seen: make(chan struct{})}
+	server.RegisterTool(Tool{
+		Name:        "slow",
+		InputSchema: JSONSchema{Type: "object"},
+	}, func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		select {
+		case <-out.seen:
+			return ToolCallResult{Content: []Content{TextContent("done")}}, nil
+		case <-time.After(2 * time.Second):
+			return ToolCallResult{}, errors.New("tools/list was blocked behind slow call")
+		}
+	})
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	callReq := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow","arguments":{}}}`
+	listReq := `{"jsonrpc":"2.0","id":3,"method":"tools/list","params":{}}`
+	input := initReq + "\n" + callReq + "\n" + listReq + "\n"
+
+	if err := server.RunWithIO(context.Background(), strings.NewReader(input), out); err != nil {
+		t.Fatalf("RunWithIO failed: %v", err)
+	}
+
+	lines := responsesByID(t, out.String())
+	var callResp struct {
+		Result ToolCallResult `json:"result"`
+	}
+	if err := json.Unmarshal(lines["2"], &callResp); err != nil {
+		t.Fatalf("failed to decode tools/call response: %v", err)
+	}
+	if callResp.Result.IsError {
+		t.Errorf("slow call failed: %s", callResp.Result.Content[0].Text)
+	}
+}
+
+// responsesByID splits newline-delimited output into raw responses keyed by
+// their JSON-RPC id, since concurrent dispatch doesn't preserve ordering.
+func responsesByID(t *testing.T, output string) map[string]json.RawMessage {
+	t.Helper()
+	byID := make(map[string]json.RawMessage)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var resp struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("failed to decode response %q: %v", line, err)
+		}
+		byID[string(resp.ID)] = json.RawMessage(line)
+	}
+	return byID
+}
+
+// notifyWriter is a goroutine-safe buffer that closes seen once a write
+// containing match has been observed.
+type notifyWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	match string
+	seen  chan struct{}
+	once  sync.Once
+}
+
+func (w *notifyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if strings.Contains(string(p), w.match) {
+		w.once.Do(func() { close(w.seen) })
+	}
+	return w.buf.Write(p)
+}
+
+func (w *notifyWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestServer_NotificationsGetNoResponse(t *testing.T) {
+	server := NewServer(nil)
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	initializedNote := `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+	unknownNote := `{"jsonrpc":"2.0","method":"notifications/unknown"}`
+	input := initReq + "\n" + initializedNote + "\n" + unknownNote + "\n"
+
+	var buf bytes.Buffer
+	if err := server.RunWithIO(context.Background(), strings.NewReader(input), &buf); err != nil {
+		t.Fatalf("RunWithIO failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected only the initialize response, got %d: %s", len(lines), buf.String())
+	}
+}
+
+func TestServer_InvalidMessagesWithoutIDGetNoResponse(t *testing.T) {
+	server := NewServer(nil)
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"1.0","method":"ping"}`,
+		`{"jsonrpc":"1.0","id":null,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":null,"method":"ping"}`,
+		`{"jsonrpc":"1.0","id":3,"method":"ping"}`,
+	}, "\n") + "\n"
+
+	var buf bytes.Buffer
+	if err := server.RunWithIO(context.Background(), strings.NewReader(input), &buf); err != nil {
+		t.Fatalf("RunWithIO failed: %v", err)
+	}
+
+	responses := responsesByID(t, buf.String())
+	if len(responses) != 1 {
+		t.Fatalf("expected only the response to id 3, got %s", buf.String())
+	}
+	var resp Response
+	if err := json.Unmarshal(responses["3"], &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != InvalidRequest {
+		t.Errorf("expected InvalidRequest for id 3, got %+v", resp)
+	}
+}
+
+func TestServer_StrictHandshake(t *testing.T) {
+	newStrictServer := func() *Server {
+		server := NewServer(nil)
+		server.SetStrict(true)
+		server.RegisterTool(Tool{
+			Name:        "test",
+			InputSchema: JSONSchema{Type: "object"},
+		}, func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			return ToolCallResult{Content: []Content{TextContent("ok")}}, nil
+		})
+		return server
+	}
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	initializedNote := `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+	callReq := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"test","arguments":{}}}`
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"before initialize", callReq, true},
+		{"before initialized notification", initReq + "\n" + callReq, true},
+		{"after handshake", initReq + "\n" + initializedNote + "\n" + callReq, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := newStrictServer().RunWithIO(context.Background(), strings.NewReader(tc.input+"\n"), &buf); err != nil {
+				t.Fatalf("RunWithIO failed: %v", err)
+			}
+
+			var resp Response
+			if err := json.Unmarshal(responsesByID(t, buf.String())["2"], &resp); err != nil {
+				t.Fatalf("failed to decode tools/call response: %v", err)
+			}
+
+			if tc.wantErr {
+				if resp.Error == nil || resp.Error.Code != InvalidRequest {
+					t.Errorf("expected InvalidRequest error, got %+v", resp.Error)
+				}
+			} else if resp.Error != nil {
+				t.Errorf("unexpected error: %v", resp.Error)
+			}
+		})
+	}
+}
+
+func TestServer_UnregisterTool(t *testing.T) {
+	server := NewServer(nil)
+	server.RegisterTool(Tool{Name: "test", InputSchema: JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			return ToolCallResult{}, nil
+		})
+
+	server.UnregisterTool("test")
+	server.UnregisterTool("missing") // no-op
+
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+	if _, ok := server.tools["test"]; ok {
+		t.Error("tool should be removed")
+	}
+	if _, ok := server.handlers["test"]; ok {
+		t.Error("handler should be removed")
+	}
+}
+
+func TestServer_ToolAllowlist(t *testing.T) {
+	server := NewServer(nil)
+	for _, name := range []string{"search", "log"} {
+		server.RegisterTool(Tool{Name: name, InputSchema: JSONSchema{Type: "object"}},
+			func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+				return ToolCallResult{Content: []Content{TextContent("ok")}}, nil
+			})
+	}
+	server.SetToolAllowlist([]string{"search"})
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	listReq := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+	callReq := `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"log","arguments":{}}}`
+	input := initReq + "\n" + listReq + "\n" + callReq + "\n"
+
+	var buf bytes.Buffer
+	if err := server.RunWithIO(context.Background(), strings.NewReader(input), &buf); err != nil {
+		t.Fatalf("RunWithIO failed: %v", err)
+	}
+	responses := responsesByID(t, buf.String())
+
+	var list struct {
+		Result ToolsListResult `json:"result"`
+	}
+	if err := json.Unmarshal(responses["2"], &list); err != nil {
+		t.Fatalf("failed to decode tools/list response: %v", err)
+	}
+	if len(list.Result.Tools) != 1 || list.Result.Tools[0].Name != "search" {
+		t.Errorf("expected only the allowed tool, got %+v", list.Result.Tools)
+	}
+
+	var call Response
+	if err := json.Unmarshal(responses["3"], &call); err != nil {
+		t.Fatalf("failed to decode tools/call response: %v", err)
+	}
+	if call.Error == nil || call.Error.Code != InvalidParams {
+		t.Errorf("expected a disallowed tool to be rejected, got %+v", call.Error)
+	}
+}
+
+func TestServer_Tools(t *testing.T) {
+	server := NewServer(nil)
+	for _, name := range []string{"b", "c", "a"} {
+		server.RegisterTool(Tool{Name: name, InputSchema: JSONSchema{Type: "object"}},
+			func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+				return ToolCallResult{}, nil
+			})
+	}
+
+	var names []string
+	for _, tool := range server.Tools() {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "a,b,c" {
+		t.Errorf("expected tools sorted by name, got %v", names)
+	}
+
+	server.SetToolAllowlist([]string{"c"})
+	if tools := server.Tools(); len(tools) != 1 || tools[0].Name != "c" {
+		t.Errorf("expected only the allowed tool, got %+v", tools)
+	}
+}
+
+func TestServer_ToolsListChangedNotification(t *testing.T) {
+	server := NewServer(nil)
+
+	// Calling "unlock" reveals a hidden tool mid-session
+	server.RegisterTool(Tool{Name: "unlock", InputSchema: JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			server.RegisterTool(Tool{Name: "hidden", InputSchema: JSONSchema{Type: "object"}},
+				func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+					return ToolCallResult{}, nil
+				})
+			return ToolCallResult{Content: []Content{TextContent("unlocked")}}, nil
+		})
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	callReq := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"unlock","arguments":{}}}`
+	input := initReq + "\n" + callReq + "\n"
+
+	var buf bytes.Buffer
+	if err := server.RunWithIO(context.Background(), strings.NewReader(input), &buf); err != nil {
+		t.Fatalf("RunWithIO failed: %v", err)
+	}
+
+	var found bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var note Notification
+		if err := json.Unmarshal([]byte(line), &note); err != nil {
+			t.Fatalf("failed to decode message: %v", err)
+		}
+		if note.Method == "notifications/tools/list_changed" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected list_changed notification, got: %s", buf.String())
+	}
+}
+
+func TestServer_ProtocolNegotiation(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{"2024-11-05", "2024-11-05"},
+		{"2025-03-26", "2025-03-26"},
+		{"2025-06-18", "2025-06-18"},
+		{"1999-01-01", LatestProtocolVersion},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.requested, func(t *testing.T) {
+			server := NewServer(nil)
+			result, err := server.handleInitialize(json.RawMessage(`{"protocolVersion":"` + tc.requested + `"}`))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ProtocolVersion != tc.want {
+				t.Errorf("protocolVersion = %q, want %q", result.ProtocolVersion, tc.want)
+			}
+		})
+	}
+}
+
+func TestServer_FeaturesGatedByVersion(t *testing.T) {
+	newServer := func(version string) *Server {
+		server := NewServer(nil)
+		server.RegisterTool(Tool{
+			Name:        "structured",
+			InputSchema: JSONSchema{Type: "object"},
+			Annotations: &ToolAnnotations{ReadOnlyHint: true},
+		}, func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			return ToolCallResult{
+				Content:           []Content{TextContent("ok"), ResourceLinkContent("https://example.com/poster.webp", "poster", "image/webp")},
+				StructuredContent: map[string]int{"count": 1},
+			}, nil
+		})
+		if _, err := server.handleInitialize(json.RawMessage(`{"protocolVersion":"` + version + `"}`)); err != nil {
+			t.Fatalf("initialize failed: %v", err)
+		}
+		return server
+	}
+
+	tests := []struct {
+		version        string
+		wantAnnotation bool
+		wantStructured bool
+		wantLinks      bool
+	}{
+		{"2024-11-05", false, false, false},
+		{"2025-03-26", true, false, false},
+		{"2025-06-18", true, true, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			server := newServer(tc.version)
+
+			list, err := server.handleToolsList(nil)
+			if err != nil {
+				t.Fatalf("tools/list failed: %v", err)
+			}
+			if got := list.Tools[0].Annotations != nil; got != tc.wantAnnotation {
+				t.Errorf("annotations present = %v, want %v", got, tc.wantAnnotation)
+			}
+
+			result, err := server.handleToolsCall(context.Background(), json.RawMessage(`{"name":"structured"}`))
+			if err != nil {
+				t.Fatalf("tools/call failed: %v", err)
+			}
+			if got := result.StructuredContent != nil; got != tc.wantStructured {
+				t.Errorf("structured content present = %v, want %v", got, tc.wantStructured)
+			}
+			if got := len(result.Content) == 2; got != tc.wantLinks {
+				t.Errorf("resource links present = %v, want %v", got, tc.wantLinks)
+			}
+		})
+	}
+}
+
+func TestServer_ToolTimeout(t *testing.T) {
+	server := NewServer(nil)
+	server.SetToolTimeout(50 * time.Millisecond)
+
+	// The handler ignores its context, as a stuck one might
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	server.RegisterTool(Tool{Name: "stuck", InputSchema: JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			<-release
+			return ToolCallResult{}, nil
+		})
+	if _, err := server.handleInitialize(json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	result, rpcErr := server.handleToolsCall(context.Background(), json.RawMessage(`{"name":"stuck"}`))
+	if rpcErr != nil {
+		t.Fatalf("unexpected error: %v", rpcErr)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "timed out") {
+		t.Errorf("expected timeout error result, got %+v", result)
+	}
+}
+
+func TestServer_TimedOutToolKeepsItsSlot(t *testing.T) {
+	server := NewServer(nil)
+	server.SetToolTimeout(50 * time.Millisecond)
+	server.SetMaxConcurrentTools(1)
+
+	release := make(chan struct{})
+	server.RegisterTool(Tool{Name: "stuck", InputSchema: JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			<-release
+			return ToolCallResult{}, nil
+		})
+	server.RegisterTool(Tool{Name: "quick", InputSchema: JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			return ToolCallResult{Content: []Content{TextContent("done")}}, nil
+		})
+	if _, err := server.handleInitialize(json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	if result, _ := server.handleToolsCall(context.Background(), json.RawMessage(`{"name":"stuck"}`)); !result.IsError {
+		t.Fatalf("expected the stuck call to time out, got %+v", result)
+	}
+	// The stuck handler is still running, so the next call can't have its slot
+	result, _ := server.handleToolsCall(context.Background(), json.RawMessage(`{"name":"quick"}`))
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "free slot") {
+		t.Errorf("expected the call to wait for the stuck one's slot, got %+v", result)
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		result, _ = server.handleToolsCall(context.Background(), json.RawMessage(`{"name":"quick"}`))
+		if !result.IsError || time.Now().After(deadline) {
+			break
+		}
+	}
+	if result.IsError {
+		t.Errorf("expected the slot freed once the stuck handler returned, got %+v", result)
+	}
+}
+
+func TestServer_GracefulDrain(t *testing.T) {
+	tests := []struct {
+		name     string
+		grace    time.Duration
+		wantText string
+	}{
+		{"in-flight call finishes", time.Second, "finished"},
+		{"grace period expires", 20 * time.Millisecond, "context canceled"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(nil)
+			server.SetDrainTimeout(tc.grace)
+
+			started := make(chan struct{})
+			server.RegisterTool(Tool{Name: "slow", InputSchema: JSONSchema{Type: "object"}},
+				func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+					close(started)
+					select {
+					case <-time.After(200 * time.Millisecond):
+						return ToolCallResult{Content: []Content{TextContent("finished")}}, nil
+					case <-ctx.Done():
+						return ToolCallResult{}, ctx.Err()
+					}
+				})
+
+			// The input stays open, so only cancellation can end the session
+			in, inW := io.Pipe()
+			t.Cleanup(func() { inW.Close() })
+			out := &notifyWriter{match: `"id":2`, seen: make(chan struct{})}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- server.RunWithIO(ctx, in, out) }()
+
+			_, _ = io.WriteString(inW, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`+"\n")
+			_, _ = io.WriteString(inW, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow"}}`+"\n")
+			<-started
+			cancel()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("expected context.Canceled, got %v", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("server did not shut down")
+			}
+
+			var resp struct {
+				Result ToolCallResult `json:"result"`
+			}
+			if err := json.Unmarshal(responsesByID(t, out.String())["2"], &resp); err != nil {
+				t.Fatalf("expected a response for the in-flight call: %v", err)
+			}
+			if !strings.Contains(resp.Result.Content[0].Text, tc.wantText) {
+				t.Errorf("expected %q, got %q", tc.wantText, resp.Result.Content[0].Text)
+			}
+		})
+	}
+}
+
+func TestServer_MaxConcurrentTools(t *testing.T) {
+	server := NewServer(nil)
+	server.SetMaxConcurrentTools(2)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	server.RegisterTool(Tool{Name: "work", InputSchema: JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return ToolCallResult{}, nil
+		})
+	if _, err := server.handleInitialize(json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := server.handleToolsCall(context.Background(), json.RawMessage(`{"name":"work"}`))
+			if err != nil || result.IsError {
+				t.Errorf("call failed: %v %+v", err, result)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak)
+	}
+}
@@ -0,0 +1,72 @@
+package mcp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/mcp"
+	"github.com/kofifort/trakt-mcp-go/internal/mcp/mcptest"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt/trakttest"
+)
+
+// These tests run whole sessions: a client speaking MCP to the server,
+// which talks to a fake Trakt.
+
+func newSession(t *testing.T) (*mcptest.Client, *trakttest.Server) {
+	t.Helper()
+	ts := trakttest.NewServer(t)
+	server := mcp.NewServer(nil)
+	client := ts.Client()
+	mcp.RegisterTools(server, client)
+	mcp.RegisterResources(server, client)
+	return mcptest.Connect(t, server), ts
+}
+
+func TestSession_LogWatchThenHistory(t *testing.T) {
+	c, ts := newSession(t)
+
+	result, err := c.CallTool("log_watch", map[string]any{"type": "movie", "movieName": "Arrival", "watchedAt": "2026-03-01T20:00:00Z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+	if got := ts.History(); len(got) != 1 || got[0].Movie == nil || got[0].Movie.Title != "Arrival" {
+		t.Fatalf("expected Arrival logged, got %+v", got)
+	}
+
+	result, err = c.CallTool("get_history", map[string]any{"limit": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError || !strings.Contains(result.Content[0].Text, "Arrival") {
+		t.Errorf("expected Arrival in the history, got %+v", result)
+	}
+
+	read, err := c.ReadResource("trakt://history/recent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Contents) != 1 || !strings.Contains(read.Contents[0].Text, "Arrival") {
+		t.Errorf("expected Arrival in the recent history resource, got %+v", read.Contents)
+	}
+}
+
+func TestSession_ListsTools(t *testing.T) {
+	c, _ := newSession(t)
+
+	tools, err := c.ListTools()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	for _, tool := range tools {
+		names[tool.Name] = true
+	}
+	for _, want := range []string{"search_show", "log_watch", "get_history"} {
+		if !names[want] {
+			t.Errorf("expected %s listed, got %d tools", want, len(tools))
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// groupBySession is the get_history groupBy value that analyzes viewing
+// sessions instead of listing a page.
+const groupBySession = "session"
+
+// Defaults of the session analysis: the window of history it reads and
+// the longest break between plays of one session.
+const (
+	defaultSessionDays = 90
+	maxSessionDays     = 730
+	defaultSessionGap  = 3 // hours
+)
+
+// session is a run of plays with no break longer than the session gap.
+type session struct {
+	plays []trakt.HistoryItem // oldest first
+}
+
+// start is when the first play began: its watch time, which Trakt records
+// at the end, less its runtime if known.
+func (s session) start() time.Time {
+	first := s.plays[0]
+	return first.WatchedAt.Add(-time.Duration(playRuntime(first)) * time.Minute)
+}
+
+func (s session) end() time.Time { return s.plays[len(s.plays)-1].WatchedAt }
+
+func (s session) length() time.Duration { return s.end().Sub(s.start()) }
+
+// historySessions analyzes the viewing sessions in the last days of
+// history, whatever page size get_history was called with.
+func historySessions(ctx context.Context, client TraktAPI, historyType string, days, gapHours int) ToolCallResult {
+	if days <= 0 {
+		days = defaultSessionDays
+	}
+	days = min(days, maxSessionDays)
+	if gapHours <= 0 {
+		gapHours = defaultSessionGap
+	}
+
+	now := time.Now()
+	history, err := historyBetween(ctx, client, historyType, now.AddDate(0, 0, -days), now)
+	if err != nil {
+		return ErrorContent(ctx, err)
+	}
+	if len(history) == 0 {
+		return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNoHistory))}}
+	}
+
+	sessions := splitSessions(history, time.Duration(gapHours)*time.Hour)
+	return ToolCallResult{Content: []Content{TextContent(formatSessions(sessions, days, gapHours))}}
+}
+
+// splitSessions groups history, oldest first, into sessions wherever more
+// than gap passes between one play and the next.
+func splitSessions(history []trakt.HistoryItem, gap time.Duration) []session {
+	var sessions []session
+	for i, h := range history {
+		if i == 0 || h.WatchedAt.Sub(history[i-1].WatchedAt) > gap {
+			sessions = append(sessions, session{})
+		}
+		last := &sessions[len(sessions)-1]
+		last.plays = append(last.plays, h)
+	}
+	return sessions
+}
+
+// formatSessions reports how often and how long the user watches over the
+// last days: sessions per week, the average session, and the biggest binge.
+func formatSessions(sessions []session, days, gapHours int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🍿 **Viewing sessions, last %d days**\n", days))
+	sb.WriteString(fmt.Sprintf("(plays less than %d hours apart count as one session)\n\n", gapHours))
+
+	plays := 0
+	var total time.Duration
+	for _, s := range sessions {
+		plays += len(s.plays)
+		total += s.length()
+	}
+
+	sb.WriteString(fmt.Sprintf("Sessions: %s (%.1f per week)\n", formatCount(len(sessions)), float64(len(sessions))*7/float64(days)))
+	avg := total / time.Duration(len(sessions))
+	sb.WriteString(fmt.Sprintf("Average session: %.1f plays, %s\n", float64(plays)/float64(len(sessions)), formatMinutes(int(avg.Minutes()))))
+
+	sb.WriteString(fmt.Sprintf("Biggest binge: %s\n", describeBinge(biggestSession(sessions))))
+	return sb.String()
+}
+
+// biggestSession returns the session with the most plays, the longest
+// of those if there's a tie.
+func biggestSession(sessions []session) session {
+	biggest := sessions[0]
+	for _, s := range sessions[1:] {
+		if len(s.plays) > len(biggest.plays) || (len(s.plays) == len(biggest.plays) && s.length() > biggest.length()) {
+			biggest = s
+		}
+	}
+	return biggest
+}
+
+// describeBinge renders a session as "6 plays in 5h 10m on Sat, Mar 2
+// from 20:05, Severance ×6".
+func describeBinge(s session) string {
+	start := s.start().Local()
+	return fmt.Sprintf("%d plays in %s on %s from %s, %s",
+		len(s.plays), formatMinutes(int(s.length().Minutes())), start.Format("Mon, Jan 2"), start.Format("15:04"), sessionTitles(s))
+}
+
+// sessionTitles names what a session was spent on, e.g. "Severance ×6" or
+// "Severance ×4, Dune".
+func sessionTitles(s session) string {
+	var titles []string
+	counts := make(map[string]int)
+	for _, h := range s.plays {
+		title := ""
+		switch {
+		case h.Show != nil:
+			title = h.Show.Title
+		case h.Movie != nil:
+			title = h.Movie.Title
+		default:
+			continue
+		}
+		if counts[title] == 0 {
+			titles = append(titles, title)
+		}
+		counts[title]++
+	}
+
+	for i, title := range titles {
+		if n := counts[title]; n > 1 {
+			titles[i] = fmt.Sprintf("%s ×%d", title, n)
+		}
+	}
+	return strings.Join(titles, ", ")
+}
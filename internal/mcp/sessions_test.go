@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestSplitSessions(t *testing.T) {
+	base := time.Date(2026, 3, 2, 20, 0, 0, 0, time.UTC)
+	at := func(hours float64) trakt.HistoryItem {
+		return trakt.HistoryItem{WatchedAt: base.Add(time.Duration(hours * float64(time.Hour)))}
+	}
+
+	history := []trakt.HistoryItem{at(0), at(1), at(3.5), at(8), at(30)}
+	sessions := splitSessions(history, 3*time.Hour)
+
+	var sizes []int
+	for _, s := range sessions {
+		sizes = append(sizes, len(s.plays))
+	}
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 1 || sizes[2] != 1 {
+		t.Errorf("expected sessions of 3, 1 and 1 plays, got %v", sizes)
+	}
+}
+
+func TestGetHistoryHandler_Sessions(t *testing.T) {
+	now := time.Now()
+	severance := &trakt.Show{Title: "Severance", Runtime: 50}
+	dune := &trakt.Movie{Title: "Dune", Runtime: 155}
+	episode := func(ago time.Duration) trakt.HistoryItem {
+		return trakt.HistoryItem{Type: "episode", WatchedAt: now.Add(-ago), Show: severance, Episode: &trakt.Episode{Season: 1, Number: 1}}
+	}
+
+	var query string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync/history" {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Pagination-Page", "1")
+		w.Header().Set("X-Pagination-Page-Count", "1")
+		// Newest first, as Trakt returns history
+		_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{
+			episode(24 * time.Hour),
+			episode(25 * time.Hour),
+			episode(26 * time.Hour),
+			episode(27 * time.Hour),
+			{Type: "movie", WatchedAt: now.Add(-10 * 24 * time.Hour), Movie: dune},
+		})
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	historyHandler := makeGetHistoryHandler(NewServer(nil), client)
+
+	result, err := historyHandler(context.Background(), json.RawMessage(`{"groupBy":"session","days":28}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	text := result.Content[0].Text
+
+	if !strings.Contains(query, "start_at=") || !strings.Contains(query, "extended=full") {
+		t.Errorf("expected the window and runtimes to be requested, got %q", query)
+	}
+	for _, want := range []string{
+		"**Viewing sessions, last 28 days**",
+		"Sessions: 2 (0.5 per week)",
+		"Average session: 2.5 plays, 3h 12m",
+		"Biggest binge: 4 plays in 3h 50m",
+		"Severance ×4",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q, got:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SSEHandler serves the legacy HTTP+SSE transport (protocol revision
+// 2024-11-05). Clients open an event stream with GET /sse, receive the URL
+// to post messages to in an "endpoint" event, then POST JSON-RPC messages
+// to /messages. Responses are delivered as "message" events on the stream.
+//
+// The Server holds a single session's handshake state, so only one stream
+// may be open at a time.
+type SSEHandler struct {
+	server *Server
+
+	mu      sync.Mutex
+	session *sseSession
+}
+
+type sseSession struct {
+	id string
+	in *io.PipeWriter
+}
+
+// NewSSEHandler creates an HTTP handler for the legacy SSE transport.
+func NewSSEHandler(s *Server) *SSEHandler {
+	return &SSEHandler{server: s}
+}
+
+// ServeHTTP routes requests to the stream and message endpoints.
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/sse":
+		h.serveStream(w, r)
+	case "/messages":
+		h.serveMessage(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *SSEHandler) serveStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	pr, pw := io.Pipe()
+	h.mu.Lock()
+	if h.session != nil {
+		h.mu.Unlock()
+		http.Error(w, "a session is already active", http.StatusConflict)
+		return
+	}
+	h.session = &sseSession{id: id, in: pw}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		h.session = nil
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	out := &sseWriter{w: w, flusher: flusher}
+	if err := out.event("endpoint", []byte("/messages?sessionId="+id)); err != nil {
+		return
+	}
+
+	// Unblock the dispatch loop when the client disconnects
+	ctx := r.Context()
+	go func() {
+		<-ctx.Done()
+		pw.Close()
+	}()
+
+	h.server.logger.Info("sse session started", "session", id)
+	if err := h.server.serve(ctx, pr, out, FramingLine); err != nil && !errors.Is(err, context.Canceled) {
+		h.server.logger.Error("sse session error", "session", id, "error", err)
+	}
+	h.server.logger.Info("sse session ended", "session", id)
+}
+
+func (h *SSEHandler) serveMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.mu.Lock()
+	session := h.session
+	h.mu.Unlock()
+
+	if session == nil || session.id != r.URL.Query().Get("sessionId") {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	// The dispatch loop is line-delimited, so collapse any pretty-printing
+	var msg bytes.Buffer
+	if err := json.Compact(&msg, body); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	msg.WriteByte('\n')
+
+	if _, err := session.in.Write(msg.Bytes()); err != nil {
+		http.Error(w, "session closed", http.StatusGone)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// sseWriter frames each newline-terminated message from the dispatch loop as
+// a server-sent "message" event.
+type sseWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (sw *sseWriter) Write(p []byte) (int, error) {
+	if err := sw.event("message", bytes.TrimRight(p, "\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (sw *sseWriter) event(name string, data []byte) error {
+	if _, err := fmt.Fprintf(sw.w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+		return err
+	}
+	sw.flusher.Flush()
+	return nil
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// RunSSE serves the legacy HTTP+SSE transport, and the routes added with
+// HandleHTTP, on addr until ctx is cancelled.
+func (s *Server) RunSSE(ctx context.Context, addr string) error {
+	mux := s.httpMux()
+	h := NewSSEHandler(s)
+	mux.Handle("/sse", h)
+	mux.Handle("/messages", h)
+
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+		// Derive request contexts from ctx so open streams end on shutdown
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("sse transport listening", "addr", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("sse server: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// readSSEEvent reads the next event from an SSE stream.
+func readSSEEvent(t *testing.T, r *bufio.Reader) (event, data string) {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			return event, data
+		}
+	}
+}
+
+func TestSSEHandler_RoundTrip(t *testing.T) {
+	server := NewServer(nil)
+	ts := httptest.NewServer(NewSSEHandler(server))
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL + "/sse")
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	stream := bufio.NewReader(resp.Body)
+	event, endpoint := readSSEEvent(t, stream)
+	if event != "endpoint" || !strings.HasPrefix(endpoint, "/messages?sessionId=") {
+		t.Fatalf("unexpected endpoint event: %q %q", event, endpoint)
+	}
+
+	t.Run("second stream rejected", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/sse")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusConflict {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusConflict)
+		}
+	})
+
+	t.Run("unknown session rejected", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+"/messages?sessionId=bogus", "application/json", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+		}
+	})
+
+	// Pretty-printed bodies must survive the line-delimited dispatch loop
+	initReq := `{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "initialize",
+		"params": {"protocolVersion": "2024-11-05", "capabilities": {}, "clientInfo": {"name": "test", "version": "1.0"}}
+	}`
+	post, err := http.Post(ts.URL+endpoint, "application/json", strings.NewReader(initReq))
+	if err != nil {
+		t.Fatalf("failed to post message: %v", err)
+	}
+	post.Body.Close()
+	if post.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", post.StatusCode, http.StatusAccepted)
+	}
+
+	event, data := readSSEEvent(t, stream)
+	if event != "message" {
+		t.Fatalf("event = %q, want message", event)
+	}
+	if !strings.Contains(data, `"id":1`) || !strings.Contains(data, ServerName) {
+		t.Errorf("unexpected initialize response: %s", data)
+	}
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultStalledDays is how long a show in progress goes without a play
+// before get_stalled_shows counts it as stalled.
+const defaultStalledDays = 60
+
+func makeGetStalledShowsHandler(client TraktAPI) ToolHandler {
+	type stalledArgs struct {
+		Days   int    `json:"days"`
+		Format string `json:"format"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a stalledArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		if a.Days <= 0 {
+			a.Days = defaultStalledDays
+		}
+
+		shows, err := showsInProgress(ctx, client)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+
+		now := time.Now()
+		stalled := stalledShows(shows, now.AddDate(0, 0, -a.Days))
+		if a.Format == formatJSON {
+			return jsonResult(completionsJSON(stalled)), nil
+		}
+		if len(stalled) == 0 {
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNothingStalled, a.Days))}}, nil
+		}
+
+		return ToolCallResult{
+			Content: []Content{TextContent(formatStalled(stalled, a.Days, now))},
+		}, nil
+	}
+}
+
+// stalledShows keeps the shows last watched before cutoff, longest stalled
+// first.
+func stalledShows(shows []showCompletion, cutoff time.Time) []showCompletion {
+	var stalled []showCompletion
+	for _, c := range shows {
+		if c.lastWatch.Before(cutoff) {
+			stalled = append(stalled, c)
+		}
+	}
+	sort.SliceStable(stalled, func(i, j int) bool { return stalled[i].lastWatch.Before(stalled[j].lastWatch) })
+	return stalled
+}
+
+func formatStalled(shows []showCompletion, days int, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⏸️ **Stalled shows** (%d with no plays in %d days)\n\n", len(shows), days))
+	for _, c := range shows {
+		note := fmt.Sprintf("stalled for %s, last watched %s", durationWords(now.Sub(c.lastWatch)), c.lastWatch.Local().Format("Jan 2, 2006"))
+		sb.WriteString(formatCompletion(c, note))
+	}
+	return sb.String()
+}
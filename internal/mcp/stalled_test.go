@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestGetStalledShowsHandler(t *testing.T) {
+	now := time.Now()
+	watched := func(title string, id, aired, n int, last time.Time) trakt.WatchedEntry {
+		season := trakt.WatchedSeason{Number: 1}
+		for i := 1; i <= n; i++ {
+			season.Episodes = append(season.Episodes, trakt.WatchedEpisode{Number: i, Plays: 1})
+		}
+		return trakt.WatchedEntry{
+			LastWatchedAt: last,
+			Show:          &trakt.Show{Title: title, AiredEpisodes: aired, IDs: trakt.ShowIDs{Trakt: id}},
+			Seasons:       []trakt.WatchedSeason{season},
+		}
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/sync/watched/shows":
+			_ = json.NewEncoder(w).Encode([]trakt.WatchedEntry{
+				watched("Severance", 1, 19, 9, now.AddDate(0, -3, 0)),
+				watched("The Bear", 2, 28, 27, now.AddDate(-1, 0, 0)),
+				watched("Andor", 3, 24, 12, now.AddDate(0, 0, -10)),
+				watched("Chernobyl", 4, 5, 5, now.AddDate(-2, 0, 0)),
+			})
+		default:
+			_, _ = w.Write([]byte("[]"))
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	stalledHandler := makeGetStalledShowsHandler(client)
+
+	result, err := stalledHandler(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	text := result.Content[0].Text
+
+	if !strings.Contains(text, "**Stalled shows** (2 with no plays in 60 days)") {
+		t.Errorf("expected two stalled shows, got:\n%s", text)
+	}
+	if !strings.Contains(text, "📺 **The Bear** - 27/28 episodes watched (96%)\n   stalled for 12 months, last watched ") {
+		t.Errorf("expected how long The Bear has stalled, got:\n%s", text)
+	}
+	if strings.Index(text, "The Bear") > strings.Index(text, "Severance") {
+		t.Error("expected the longest stalled show first")
+	}
+	if strings.Contains(text, "Andor") || strings.Contains(text, "Chernobyl") {
+		t.Errorf("expected recent and finished shows to be left out, got:\n%s", text)
+	}
+
+	result, _ = stalledHandler(context.Background(), json.RawMessage(`{"days":400}`))
+	if result.IsError || !strings.HasPrefix(result.Content[0].Text, "No stalled shows") {
+		t.Errorf("expected no shows stalled for 400 days, got %+v", result)
+	}
+
+	result, _ = stalledHandler(context.Background(), json.RawMessage(`{"format":"json"}`))
+	var shows []completionJSON
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &shows); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", result.Content[0].Text, err)
+	}
+	if len(shows) != 2 || shows[0].Show.Title != "The Bear" || shows[0].Watched != 27 || shows[0].Percent != 96 || shows[0].LastWatchedAt == nil {
+		t.Errorf("unexpected JSON shows %+v", shows)
+	}
+
+	result, _ = stalledHandler(context.Background(), json.RawMessage(`{"days":400,"format":"json"}`))
+	if result.Content[0].Text != "[]" {
+		t.Errorf("expected [], got %q", result.Content[0].Text)
+	}
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// statusErrorWindow is how far back server_status counts failed tool calls.
+const statusErrorWindow = time.Hour
+
+// RegisterStatusTool registers the server_status tool, which reports the
+// server's own state so a misbehaving session can be debugged from the chat.
+func RegisterStatusTool(s *Server, client TraktAPI) {
+	s.RegisterTool(Tool{
+		Name:        "server_status",
+		Description: "Report the server's state: version, uptime, negotiated protocol, sign-in, cache hit rates, remaining Trakt rate-limit budget and recent errors. Use when the server seems broken or slow.",
+		Annotations: &ToolAnnotations{Title: "Server status", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type:       "object",
+			Properties: map[string]JSONSchema{},
+		},
+	}, func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		return ToolCallResult{
+			Content: []Content{TextContent(formatStatus(s, client, time.Now()))},
+		}, nil
+	})
+}
+
+func formatStatus(s *Server, client TraktAPI, now time.Time) string {
+	s.mu.RLock()
+	protocol := s.protocolVersion
+	clientInfo := s.clientInfo
+	s.mu.RUnlock()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🩺 %s %s\n", ServerName, CurrentBuild().SemVer()))
+	sb.WriteString(fmt.Sprintf("Uptime: %s\n", now.Sub(s.started).Round(time.Second)))
+	if protocol != "" {
+		sb.WriteString(fmt.Sprintf("Protocol: %s", protocol))
+		if clientInfo.Name != "" {
+			sb.WriteString(fmt.Sprintf(" with %s %s", clientInfo.Name, clientInfo.Version))
+		}
+		sb.WriteString("\n")
+	}
+
+	switch {
+	case !client.IsConfigured():
+		sb.WriteString("Authentication: ❌ no API credentials\n")
+	case !client.IsAuthenticated():
+		sb.WriteString("Authentication: ❌ not signed in\n")
+	default:
+		sb.WriteString("Authentication: ✅ signed in\n")
+	}
+
+	sb.WriteString("\nCaches:\n")
+	for _, c := range client.CacheStats() {
+		if c.Hits+c.Misses == 0 {
+			sb.WriteString(fmt.Sprintf("  %s: no lookups yet\n", c.Name))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %.0f%% hit rate (%d of %d)\n",
+			c.Name, c.HitRate()*100, c.Hits, c.Hits+c.Misses))
+	}
+
+	sb.WriteString("\nRate limit:\n")
+	limits := client.RateLimits()
+	if len(limits) == 0 {
+		sb.WriteString("  no responses from Trakt yet\n")
+	}
+	for _, rl := range limits {
+		marker := ""
+		if rl.Low() {
+			marker = " ⚠️"
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %d of %d left until %s%s\n",
+			rl.Name, rl.Remaining, rl.Limit, rl.Until.Local().Format("15:04"), marker))
+	}
+
+	sb.WriteString("\nFailed tool calls in the last hour:\n")
+	toolErrors := s.metrics.recentToolErrors(now.Add(-statusErrorWindow))
+	if len(toolErrors) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, tool := range sortedKeys(toolErrors) {
+		sb.WriteString(fmt.Sprintf("  %s: %d\n", tool, toolErrors[tool]))
+	}
+
+	sb.WriteString("\nFailed Trakt API requests since start:\n")
+	apiErrors := s.metrics.apiErrors()
+	if len(apiErrors) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, status := range sortedKeys(apiErrors) {
+		sb.WriteString(fmt.Sprintf("  %s: %d\n", status, apiErrors[status]))
+	}
+
+	return sb.String()
+}
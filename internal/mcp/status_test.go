@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestFormatStatus(t *testing.T) {
+	server := NewServer(nil)
+	server.started = time.Now().Add(-90 * time.Minute)
+	server.mu.Lock()
+	server.protocolVersion = LatestProtocolVersion
+	server.clientInfo = Implementation{Name: "test", Version: "1.0"}
+	server.mu.Unlock()
+
+	server.metrics.observeToolCall("log_watch", true, time.Second)
+	server.metrics.observeToolCall("search", false, time.Second)
+	server.metrics.mu.Lock()
+	server.metrics.apiRequests["200"] = 5
+	server.metrics.apiRequests["429"] = 2
+	server.metrics.mu.Unlock()
+
+	client := &fakeTrakt{
+		configured:    true,
+		authenticated: true,
+		cacheStats: []trakt.CacheStats{
+			{Name: "lookup", Hits: 3, Misses: 1},
+			{Name: "etag"},
+		},
+		rateLimits: []trakt.RateLimit{{Name: "AUTHED_API_GET_LIMIT", Limit: 1000, Remaining: 50}},
+	}
+
+	text := formatStatus(server, client, time.Now())
+	for _, want := range []string{
+		"Uptime: 1h30m0s",
+		"Protocol: " + LatestProtocolVersion + " with test 1.0",
+		"Authentication: ✅ signed in",
+		"lookup: 75% hit rate (3 of 4)",
+		"etag: no lookups yet",
+		"AUTHED_API_GET_LIMIT: 50 of 1000 left",
+		"⚠️",
+		"log_watch: 1",
+		"429: 2",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "search: ") || strings.Contains(text, "200: ") {
+		t.Errorf("expected only failures to be reported:\n%s", text)
+	}
+}
+
+func TestMetrics_RecentToolErrors(t *testing.T) {
+	m := newMetrics()
+	for i := 0; i < maxRecentToolErrors+5; i++ {
+		m.observeToolCall("search", true, 0)
+	}
+	if n := m.recentToolErrors(time.Now().Add(-time.Minute))["search"]; n != maxRecentToolErrors {
+		t.Errorf("expected %d remembered errors, got %d", maxRecentToolErrors, n)
+	}
+	if n := len(m.recentToolErrors(time.Now().Add(time.Minute))); n != 0 {
+		t.Errorf("expected no errors after the window, got %d", n)
+	}
+}
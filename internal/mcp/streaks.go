@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// streak is a run of consecutive local days with at least one play.
+type streak struct {
+	first, last time.Time // local midnights
+}
+
+func (s streak) days() int {
+	return int(s.last.Sub(s.first).Hours()/24+0.5) + 1
+}
+
+// localDay returns the local midnight starting t's day.
+func localDay(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// findStreaks splits the days with a play, in any order, into streaks,
+// oldest first.
+func findStreaks(days []time.Time) []streak {
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	var streaks []streak
+	for _, d := range days {
+		if n := len(streaks); n > 0 && !d.After(streaks[n-1].last.AddDate(0, 0, 1)) {
+			if d.After(streaks[n-1].last) {
+				streaks[n-1].last = d
+			}
+			continue
+		}
+		streaks = append(streaks, streak{first: d, last: d})
+	}
+	return streaks
+}
+
+func makeGetStreaksHandler(client TraktAPI) ToolHandler {
+	type streaksArgs struct {
+		Type string `json:"type"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a streaksArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		if a.Type != "" && a.Type != "episodes" && a.Type != "movies" {
+			return ToolCallResult{
+				Content: []Content{TextContent(`Error: type must be "episodes" or "movies"`)},
+				IsError: true,
+			}, nil
+		}
+
+		// Only the watch dates matter, so the history is read without
+		// extended info and kept as a set of days
+		seen := make(map[time.Time]bool)
+		var days []time.Time
+		err := client.ForEachHistoryItem(ctx, a.Type, func(h trakt.HistoryItem) error {
+			if d := localDay(h.WatchedAt); !seen[d] {
+				seen[d] = true
+				days = append(days, d)
+			}
+			return nil
+		})
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		if len(days) == 0 {
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNoHistory))}}, nil
+		}
+
+		return ToolCallResult{
+			Content: []Content{TextContent(formatStreaks(findStreaks(days), a.Type, time.Now()))},
+		}, nil
+	}
+}
+
+// formatStreaks reports the current streak, which stays alive through
+// today if the user watched yesterday, and the longest one.
+func formatStreaks(streaks []streak, historyType string, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("🔥 **Viewing streaks**")
+	if historyType != "" {
+		sb.WriteString(fmt.Sprintf(" (%s only)", historyType))
+	}
+	sb.WriteString("\n\n")
+
+	today := localDay(now)
+	latest := streaks[len(streaks)-1]
+	switch {
+	case latest.last.Equal(today):
+		sb.WriteString(fmt.Sprintf("Current streak: %d days, since %s\n", latest.days(), latest.first.Format("Mon, Jan 2")))
+	case latest.last.Equal(today.AddDate(0, 0, -1)):
+		sb.WriteString(fmt.Sprintf("Current streak: %d days, since %s (watch something today to keep it going)\n", latest.days(), latest.first.Format("Mon, Jan 2")))
+	default:
+		sb.WriteString(fmt.Sprintf("Current streak: none, last watched %s\n", relativeDate(latest.last, now)))
+	}
+
+	longest := streaks[0]
+	total := 0
+	for _, s := range streaks {
+		total += s.days()
+		// The most recent of equally long streaks
+		if s.days() >= longest.days() {
+			longest = s
+		}
+	}
+	sb.WriteString(fmt.Sprintf("Longest streak: %d days, %s\n", longest.days(), shortDateRange(longest.first, longest.last)))
+	sb.WriteString(fmt.Sprintf("Days with a play: %s since %s\n", formatCount(total), streaks[0].first.Format("Jan 2, 2006")))
+	return sb.String()
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestFindStreaks(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.Local) }
+
+	// Out of order and across a month end, as history pages arrive
+	streaks := findStreaks([]time.Time{day(3, 2), day(2, 28), day(3, 1), day(3, 10), day(3, 3)})
+	if len(streaks) != 2 {
+		t.Fatalf("expected 2 streaks, got %+v", streaks)
+	}
+	if !streaks[0].first.Equal(day(2, 28)) || streaks[0].days() != 4 {
+		t.Errorf("expected a 4 day streak from Feb 28, got %+v", streaks[0])
+	}
+	if streaks[1].days() != 1 {
+		t.Errorf("expected a 1 day streak, got %+v", streaks[1])
+	}
+}
+
+func TestGetStreaksHandler(t *testing.T) {
+	today := localDay(time.Now())
+	play := func(daysAgo int) trakt.HistoryItem {
+		return trakt.HistoryItem{Type: "episode", WatchedAt: today.AddDate(0, 0, -daysAgo).Add(20 * time.Hour)}
+	}
+
+	var path string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{
+			play(1), play(1), play(2), play(3),
+			play(20), play(21), play(22), play(23), play(24),
+		})
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	result, err := makeGetStreaksHandler(client)(context.Background(), json.RawMessage(`{"type":"episodes"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	text := result.Content[0].Text
+
+	if path != "/sync/history/episodes" {
+		t.Errorf("expected episodes history, got %s", path)
+	}
+	for _, want := range []string{
+		"**Viewing streaks** (episodes only)",
+		"Current streak: 3 days, since " + today.AddDate(0, 0, -3).Format("Mon, Jan 2") + " (watch something today to keep it going)",
+		"Longest streak: 5 days, " + shortDateRange(today.AddDate(0, 0, -24), today.AddDate(0, 0, -20)),
+		"Days with a play: 8 since ",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q, got:\n%s", want, text)
+		}
+	}
+
+	result, _ = makeGetStreaksHandler(client)(context.Background(), json.RawMessage(`{"type":"shows"}`))
+	if !result.IsError {
+		t.Errorf("expected an invalid type to fail, got %+v", result)
+	}
+}
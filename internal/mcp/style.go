@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// OutputStyle selects how tool and resource text is decorated.
+type OutputStyle string
+
+const (
+	// OutputRich uses emoji and Markdown emphasis.
+	OutputRich OutputStyle = "rich"
+	// OutputPlain uses neither, for hosts and terminals that render them
+	// poorly and for screen readers.
+	OutputPlain OutputStyle = "plain"
+)
+
+// ParseOutputStyle converts a setting value to an OutputStyle. An empty
+// value is OutputRich.
+func ParseOutputStyle(s string) (OutputStyle, error) {
+	switch style := OutputStyle(s); style {
+	case "":
+		return OutputRich, nil
+	case OutputRich, OutputPlain:
+		return style, nil
+	default:
+		return OutputRich, fmt.Errorf("unknown output style %q (want rich or plain)", s)
+	}
+}
+
+// plainReplacer turns the emoji used in output into words, or drops them
+// where they're only decoration, and removes Markdown bold markers.
+var plainReplacer = strings.NewReplacer(
+	"✅ ", "[OK] ",
+	"❌ ", "[FAIL] ",
+	"⚠️", "[WARN]",
+	"ℹ️ ", "",
+	"📺 ", "",
+	"🎬 ", "",
+	"🔐 ", "",
+	"🩺 ", "",
+	"⏳ ", "",
+	"**", "",
+)
+
+// plainText strips emoji and Markdown from text. JSON output is left alone,
+// since it holds data rather than decoration.
+func plainText(text string) string {
+	if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		if json.Valid([]byte(trimmed)) {
+			return text
+		}
+	}
+	return plainReplacer.Replace(text)
+}
+
+// applyStyle rewrites a tool result's text for the configured style.
+func (s *Server) applyStyle(result *ToolCallResult) {
+	if s.OutputStyle() != OutputPlain {
+		return
+	}
+	for i, c := range result.Content {
+		if c.Type == "text" {
+			result.Content[i].Text = plainText(c.Text)
+		}
+	}
+}
+
+// applyResourceStyle rewrites a resource's text for the configured style.
+func (s *Server) applyResourceStyle(result *ResourceReadResult) {
+	if s.OutputStyle() != OutputPlain {
+		return
+	}
+	for i, c := range result.Contents {
+		result.Contents[i].Text = plainText(c.Text)
+	}
+}
@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseOutputStyle(t *testing.T) {
+	for in, want := range map[string]OutputStyle{"": OutputRich, "rich": OutputRich, "plain": OutputPlain} {
+		if got, err := ParseOutputStyle(in); err != nil || got != want {
+			t.Errorf("ParseOutputStyle(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseOutputStyle("fancy"); err == nil {
+		t.Error("expected an error for an unknown style")
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := map[string]string{
+		"✅ Logged: **Breaking Bad** S01E01 - Pilot":    "[OK] Logged: Breaking Bad S01E01 - Pilot",
+		"❌ credentials: missing":                       "[FAIL] credentials: missing",
+		"📺 **Dune** (2021) - Show - Trakt ID: 1":       "Dune (2021) - Show - Trakt ID: 1",
+		"  AUTHED: 5 of 1000 left until 15:04 ⚠️":      "  AUTHED: 5 of 1000 left until 15:04 [WARN]",
+		`[{"title":"**not markdown** ✅ in the data"}]`: `[{"title":"**not markdown** ✅ in the data"}]`,
+	}
+	for in, want := range tests {
+		if got := plainText(in); got != want {
+			t.Errorf("plainText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestServer_PlainOutputStyle(t *testing.T) {
+	server := NewServer(nil)
+	server.initialized = true
+	server.SetOutputStyle(OutputPlain)
+	server.RegisterTool(Tool{Name: "log", InputSchema: JSONSchema{Type: "object"}},
+		func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+			return ToolCallResult{Content: []Content{TextContent("✅ Logged: **Dune**")}}, nil
+		})
+
+	result, rpcErr := server.handleToolsCall(context.Background(), json.RawMessage(`{"name":"log","arguments":{}}`))
+	if rpcErr != nil {
+		t.Fatalf("tools/call failed: %v", rpcErr)
+	}
+	if got := result.Content[0].Text; got != "[OK] Logged: Dune" {
+		t.Errorf("expected plain output, got %q", got)
+	}
+}
@@ -0,0 +1,398 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Page sizes for what_should_i_watch.
+const (
+	defaultSuggestionLimit = 5
+	maxSuggestionLimit     = 20
+	// recommendationLimit is how many shows and how many movies are
+	// fetched from Trakt's recommendations to rank with the rest.
+	recommendationLimit = 10
+)
+
+// Scores of the signals what_should_i_watch ranks by. A title picks up the
+// score of every signal that applies to it, so a show that is both up next
+// and airing tonight outranks either alone.
+const (
+	scoreAiringToday   = 50
+	scoreUpNext        = 40
+	scoreRecentlyOn    = 20 // up next and watched in the last week
+	scoreWatchlist     = 20
+	scoreWatchlistYear = 5 // per year on the watchlist, up to scoreWatchlistMax
+	scoreWatchlistMax  = 15
+	scoreRecommended   = 10
+)
+
+// candidate is one reason to watch a show or movie, found by one source.
+type candidate struct {
+	show    *trakt.Show
+	movie   *trakt.Movie
+	episode *trakt.Episode
+	score   float64
+	reason  string
+}
+
+// suggestion is a show or movie what_should_i_watch may put forward, with
+// the reasons collected from each source.
+type suggestion struct {
+	show    *trakt.Show
+	movie   *trakt.Movie
+	episode *trakt.Episode // the episode to watch next, for shows when known
+	score   float64
+	reasons []string
+}
+
+// suggestions merges candidates from several sources by title, keeping
+// the order they were first seen in for stable ranking.
+type suggestions struct {
+	byKey map[string]*suggestion
+	order []*suggestion
+}
+
+func (s *suggestions) add(c candidate) {
+	var key string
+	switch {
+	case c.show != nil:
+		key = fmt.Sprintf("show:%d", c.show.IDs.Trakt)
+	case c.movie != nil:
+		key = fmt.Sprintf("movie:%d", c.movie.IDs.Trakt)
+	default:
+		return
+	}
+	if s.byKey == nil {
+		s.byKey = make(map[string]*suggestion)
+	}
+	sg, ok := s.byKey[key]
+	if !ok {
+		sg = &suggestion{show: c.show, movie: c.movie}
+		s.byKey[key] = sg
+		s.order = append(s.order, sg)
+	}
+	if sg.episode == nil {
+		sg.episode = c.episode
+	}
+	sg.score += c.score
+	sg.reasons = append(sg.reasons, c.reason)
+}
+
+// ranked returns the suggestions by score, best first.
+func (s *suggestions) ranked() []*suggestion {
+	ranked := append([]*suggestion(nil), s.order...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	return ranked
+}
+
+// suggestionSource finds one kind of candidate.
+type suggestionSource struct {
+	name   string // for the note when the source fails, e.g. "watchlist"
+	movies bool   // whether it can suggest movies
+	shows  bool   // whether it can suggest shows
+	fetch  func(ctx context.Context, client TraktAPI, now time.Time) ([]candidate, error)
+}
+
+var suggestionSources = []suggestionSource{
+	{name: "up-next progress", shows: true, fetch: suggestUpNext},
+	{name: "calendar", shows: true, fetch: suggestAiringToday},
+	{name: "watchlist", shows: true, movies: true, fetch: suggestWatchlist},
+	{name: "recommendations", shows: true, movies: true, fetch: suggestRecommended},
+}
+
+func makeWhatShouldIWatchHandler(client TraktAPI) ToolHandler {
+	type whatShouldIWatchArgs struct {
+		Type   string `json:"type"`
+		Limit  int    `json:"limit"`
+		Format string `json:"format"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a whatShouldIWatchArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		if a.Type != "" && a.Type != "show" && a.Type != "movie" {
+			return ToolCallResult{
+				Content: []Content{TextContent("Error: type must be 'show' or 'movie'")},
+				IsError: true,
+			}, nil
+		}
+		if a.Limit <= 0 {
+			a.Limit = defaultSuggestionLimit
+		}
+		a.Limit = min(a.Limit, maxSuggestionLimit)
+
+		var sources []suggestionSource
+		for _, src := range suggestionSources {
+			if a.Type == "" || (a.Type == "show" && src.shows) || (a.Type == "movie" && src.movies) {
+				sources = append(sources, src)
+			}
+		}
+
+		// Each source makes its own round trips, so fetch them side by side
+		now := time.Now()
+		found := make([][]candidate, len(sources))
+		errs := make([]error, len(sources))
+		var wg sync.WaitGroup
+		for i, src := range sources {
+			wg.Add(1)
+			go func(i int, src suggestionSource) {
+				defer wg.Done()
+				found[i], errs[i] = src.fetch(ctx, client, now)
+			}(i, src)
+		}
+		wg.Wait()
+
+		var (
+			all    suggestions
+			failed []string
+		)
+		for i, src := range sources {
+			if errs[i] != nil {
+				failed = append(failed, src.name)
+				continue
+			}
+			for _, c := range found[i] {
+				if (a.Type == "show" && c.show == nil) || (a.Type == "movie" && c.movie == nil) {
+					continue
+				}
+				all.add(c)
+			}
+		}
+		if len(failed) == len(sources) {
+			return ErrorContent(ctx, errs[0]), nil
+		}
+
+		ranked := all.ranked()
+		if len(ranked) > a.Limit {
+			ranked = ranked[:a.Limit]
+		}
+		if a.Format == formatJSON {
+			return jsonResult(suggestionsJSON(ranked)), nil
+		}
+		if len(ranked) == 0 {
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNothingToWatch))}}, nil
+		}
+
+		output := formatSuggestions(ranked)
+		if len(failed) > 0 {
+			output += fmt.Sprintf("\nCouldn't check your %s, so nothing from there is included.\n", strings.Join(failed, " or "))
+		}
+		return ToolCallResult{Content: []Content{TextContent(output)}}, nil
+	}
+}
+
+// suggestionJSON is a suggestion in JSON output.
+type suggestionJSON struct {
+	Show        *trakt.Show    `json:"show,omitempty"`
+	Movie       *trakt.Movie   `json:"movie,omitempty"`
+	NextEpisode *trakt.Episode `json:"next_episode,omitempty"`
+	Score       float64        `json:"score"`
+	Reasons     []string       `json:"reasons"`
+}
+
+// suggestionsJSON converts ranked suggestions for JSON output, as [] when
+// there are none.
+func suggestionsJSON(ranked []*suggestion) []suggestionJSON {
+	out := make([]suggestionJSON, len(ranked))
+	for i, sg := range ranked {
+		out[i] = suggestionJSON{Show: sg.show, Movie: sg.movie, NextEpisode: sg.episode, Score: sg.score, Reasons: orEmpty(sg.reasons)}
+	}
+	return out
+}
+
+// formatSuggestions renders the ranked suggestions as a numbered list, each
+// with its reasons and the IDs log_watch takes.
+func formatSuggestions(ranked []*suggestion) string {
+	var sb strings.Builder
+	sb.WriteString("🍿 **What to watch**\n\n")
+	for i, sg := range ranked {
+		switch {
+		case sg.show != nil && sg.episode != nil:
+			sb.WriteString(fmt.Sprintf("%d. 📺 **%s** S%02dE%02d - %s\n", i+1, sg.show.Title, sg.episode.Season, sg.episode.Number, sg.episode.Title))
+		case sg.show != nil:
+			sb.WriteString(fmt.Sprintf("%d. 📺 **%s**%s\n", i+1, sg.show.Title, yearSuffix(sg.show.Year)))
+		default:
+			sb.WriteString(fmt.Sprintf("%d. 🎬 **%s**%s\n", i+1, sg.movie.Title, yearSuffix(sg.movie.Year)))
+		}
+		sb.WriteString(fmt.Sprintf("   %s\n", strings.Join(sg.reasons, " · ")))
+
+		if sg.show != nil {
+			url := sg.show.URL()
+			var episodeID int64
+			if sg.episode != nil {
+				url = sg.show.EpisodeURL(sg.episode.Season, sg.episode.Number)
+				episodeID = int64(sg.episode.IDs.Trakt)
+			}
+			sb.WriteString(idLine(idPart("Show ID", int64(sg.show.IDs.Trakt)), slugPart(sg.show.IDs.Slug), idPart("Episode ID", episodeID), url))
+		} else {
+			sb.WriteString(idLine(idPart("Trakt ID", int64(sg.movie.IDs.Trakt)), slugPart(sg.movie.IDs.Slug), sg.movie.URL()))
+		}
+	}
+	return sb.String()
+}
+
+// yearSuffix renders a year as " (2016)", or "" when it's unknown.
+func yearSuffix(year int) string {
+	if year <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d)", year)
+}
+
+// suggestUpNext finds the recently watched shows that have episodes left,
+// like the up-next resource. A show whose progress can't be read is
+// skipped rather than failing the source.
+func suggestUpNext(ctx context.Context, client TraktAPI, now time.Time) ([]candidate, error) {
+	history, err := client.GetHistory(ctx, "shows", recentHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var shows []trakt.HistoryItem
+	seen := make(map[int]bool)
+	for _, h := range history {
+		if h.Show == nil || seen[h.Show.IDs.Trakt] {
+			continue
+		}
+		if len(shows) >= upNextShowLimit {
+			break
+		}
+		seen[h.Show.IDs.Trakt] = true
+		shows = append(shows, h)
+	}
+
+	progress, errs := trakt.Batch(ctx, shows, trakt.DefaultBatchParallelism, func(ctx context.Context, h trakt.HistoryItem) (*trakt.ShowProgress, error) {
+		return client.GetShowProgress(ctx, fmt.Sprintf("%d", h.Show.IDs.Trakt))
+	})
+	var found []candidate
+	for i, h := range shows {
+		p := progress[i]
+		if errs[i] != nil || p.NextEpisode == nil {
+			continue
+		}
+		score := float64(scoreUpNext)
+		if now.Sub(h.WatchedAt) < 7*24*time.Hour {
+			score += scoreRecentlyOn
+		}
+		found = append(found, candidate{show: h.Show, episode: p.NextEpisode, score: score, reason: unwatchedReason(p.Aired - p.Completed)})
+	}
+	return found, nil
+}
+
+func unwatchedReason(n int) string {
+	if n == 1 {
+		return "1 unwatched episode"
+	}
+	return fmt.Sprintf("%d unwatched episodes", max(n, 1))
+}
+
+// suggestAiringToday finds the user's shows with an episode airing today.
+// Trakt's calendar days are UTC, so the window is widened by a day on each
+// side and filtered to today in local time.
+func suggestAiringToday(ctx context.Context, client TraktAPI, now time.Time) ([]candidate, error) {
+	entries, err := client.GetMyShowsCalendar(ctx, now.UTC().AddDate(0, 0, -1), 3)
+	if err != nil {
+		return nil, err
+	}
+	var found []candidate
+	for _, e := range entries {
+		if e.Show == nil || e.Episode == nil || !sameDay(e.FirstAired, now) {
+			continue
+		}
+		reason := "aired today"
+		if e.FirstAired.After(now) {
+			reason = fmt.Sprintf("airs tonight at %s", e.FirstAired.Local().Format("15:04"))
+			if e.FirstAired.Local().Hour() < 17 {
+				reason = fmt.Sprintf("airs today at %s", e.FirstAired.Local().Format("15:04"))
+			}
+		}
+		found = append(found, candidate{show: e.Show, episode: e.Episode, score: scoreAiringToday, reason: reason})
+	}
+	return found, nil
+}
+
+// suggestWatchlist finds the shows and movies on the watchlist, nudging the
+// ones that have waited longest.
+func suggestWatchlist(ctx context.Context, client TraktAPI, now time.Time) ([]candidate, error) {
+	items, err := client.GetWatchlist(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var found []candidate
+	for _, item := range items {
+		age := now.Sub(item.ListedAt)
+		score := float64(scoreWatchlist) + min(float64(scoreWatchlistYear)*age.Hours()/(365*24), scoreWatchlistMax)
+		reason := "on your watchlist for " + durationWords(age)
+		if age < 24*time.Hour {
+			reason = "added to your watchlist today"
+		}
+		switch {
+		case item.Type == "show" && item.Show != nil:
+			found = append(found, candidate{show: item.Show, score: score, reason: reason})
+		case item.Type == "movie" && item.Movie != nil:
+			found = append(found, candidate{movie: item.Movie, score: score, reason: reason})
+		}
+	}
+	return found, nil
+}
+
+// suggestRecommended finds Trakt's personal recommendations, in the order
+// Trakt ranks them.
+func suggestRecommended(ctx context.Context, client TraktAPI, now time.Time) ([]candidate, error) {
+	shows, err := client.GetRecommendedShows(ctx, recommendationLimit)
+	if err != nil {
+		return nil, err
+	}
+	movies, err := client.GetRecommendedMovies(ctx, recommendationLimit)
+	if err != nil {
+		return nil, err
+	}
+	const reason = "recommended for you by Trakt"
+	var found []candidate
+	// Interleave so neither kind crowds out the other
+	for i := 0; i < max(len(shows), len(movies)); i++ {
+		bonus := float64(recommendationLimit-i) / recommendationLimit
+		if i < len(shows) {
+			found = append(found, candidate{show: &shows[i], score: scoreRecommended + bonus, reason: reason})
+		}
+		if i < len(movies) {
+			found = append(found, candidate{movie: &movies[i], score: scoreRecommended + bonus, reason: reason})
+		}
+	}
+	return found, nil
+}
+
+// durationWords describes a duration roughly, e.g. "3 days", "5 weeks" or
+// "2 years".
+func durationWords(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	switch {
+	case days < 2:
+		return "a day"
+	case days < 14:
+		return fmt.Sprintf("%d days", days)
+	case days < 60:
+		return fmt.Sprintf("%d weeks", days/7)
+	case days < 730:
+		return fmt.Sprintf("%d months", days/30)
+	default:
+		return fmt.Sprintf("%d years", days/365)
+	}
+}
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// suggestTrakt serves the sources of what_should_i_watch and
+// get_upcoming. A nil list field fails that source.
+type suggestTrakt struct {
+	fakeTrakt
+	progress  map[string]*trakt.ShowProgress
+	calendar  []trakt.CalendarEntry
+	watchlist []trakt.WatchlistItem
+	shows     []trakt.Show
+	movies    []trakt.Movie
+	hidden    []trakt.HiddenItem
+}
+
+var errSourceDown = errors.New("source down")
+
+func (f *suggestTrakt) GetShowProgress(ctx context.Context, showID string) (*trakt.ShowProgress, error) {
+	if p, ok := f.progress[showID]; ok {
+		return p, nil
+	}
+	return &trakt.ShowProgress{}, nil
+}
+
+func (f *suggestTrakt) GetMyShowsCalendar(ctx context.Context, start time.Time, days int, opts ...trakt.RequestOption) ([]trakt.CalendarEntry, error) {
+	if f.calendar == nil {
+		return nil, errSourceDown
+	}
+	return f.calendar, nil
+}
+
+func (f *suggestTrakt) GetHidden(ctx context.Context, section, itemType string) ([]trakt.HiddenItem, error) {
+	return f.hidden, nil
+}
+
+func (f *suggestTrakt) GetWatchlist(ctx context.Context, watchlistType string, opts ...trakt.RequestOption) ([]trakt.WatchlistItem, error) {
+	if f.watchlist == nil {
+		return nil, errSourceDown
+	}
+	return f.watchlist, nil
+}
+
+func (f *suggestTrakt) GetRecommendedShows(ctx context.Context, limit int, opts ...trakt.RequestOption) ([]trakt.Show, error) {
+	if f.shows == nil {
+		return nil, errSourceDown
+	}
+	return f.shows, nil
+}
+
+func (f *suggestTrakt) GetRecommendedMovies(ctx context.Context, limit int, opts ...trakt.RequestOption) ([]trakt.Movie, error) {
+	return f.movies, nil
+}
+
+func newSuggestTrakt(now time.Time) *suggestTrakt {
+	severance := &trakt.Show{Title: "Severance", IDs: trakt.ShowIDs{Trakt: 154997, Slug: "severance"}}
+	bear := &trakt.Show{Title: "The Bear", IDs: trakt.ShowIDs{Trakt: 191840}}
+	return &suggestTrakt{
+		fakeTrakt: fakeTrakt{
+			authenticated: true,
+			getHistory: func(ctx context.Context, historyType string, limit int) ([]trakt.HistoryItem, error) {
+				return []trakt.HistoryItem{
+					{Type: "episode", WatchedAt: now.Add(-48 * time.Hour), Show: severance},
+					{Type: "episode", WatchedAt: now.Add(-30 * 24 * time.Hour), Show: bear},
+				}, nil
+			},
+		},
+		progress: map[string]*trakt.ShowProgress{
+			"154997": {Aired: 12, Completed: 9, NextEpisode: &trakt.Episode{Season: 2, Number: 1, Title: "Hello, Ms. Cobel", IDs: trakt.EpisodeIDs{Trakt: 11}}},
+			"191840": {Aired: 28, Completed: 27, NextEpisode: &trakt.Episode{Season: 3, Number: 10, Title: "Forever", IDs: trakt.EpisodeIDs{Trakt: 12}}},
+		},
+		calendar: []trakt.CalendarEntry{
+			{FirstAired: now, Show: severance, Episode: &trakt.Episode{Season: 2, Number: 4, Title: "Woe's Hollow"}},
+			{FirstAired: now.Add(-72 * time.Hour), Show: bear, Episode: &trakt.Episode{Season: 3, Number: 10}},
+		},
+		watchlist: []trakt.WatchlistItem{
+			{Type: "movie", ListedAt: now.Add(-2 * 366 * 24 * time.Hour), Movie: &trakt.Movie{Title: "Heat", Year: 1995, IDs: trakt.MovieIDs{Trakt: 693}}},
+		},
+		shows:  []trakt.Show{},
+		movies: []trakt.Movie{{Title: "Arrival", Year: 2016, IDs: trakt.MovieIDs{Trakt: 150167}}},
+	}
+}
+
+func TestWhatShouldIWatch_RanksWithReasons(t *testing.T) {
+	now := time.Now()
+	client := newSuggestTrakt(now)
+
+	result, err := makeWhatShouldIWatchHandler(client)(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+	text := result.Content[0].Text
+
+	// Up next, recently watched and airing today beats the rest, and keeps
+	// the episode to watch next rather than the one airing
+	order := []string{"**Severance** S02E01", "**The Bear** S03E10", "**Heat** (1995)", "**Arrival** (2016)"}
+	last := -1
+	for _, want := range order {
+		i := strings.Index(text, want)
+		if i < 0 {
+			t.Fatalf("expected %q in result, got:\n%s", want, text)
+		}
+		if i < last {
+			t.Errorf("expected %q ranked after the previous suggestion, got:\n%s", want, text)
+		}
+		last = i
+	}
+	for _, want := range []string{
+		"3 unwatched episodes · aired today",
+		"1 unwatched episode\n",
+		"on your watchlist for 2 years",
+		"recommended for you by Trakt",
+		"Show ID: 154997 · Slug: severance · Episode ID: 11",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in result, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestWhatShouldIWatch_TypeAndFailedSource(t *testing.T) {
+	client := newSuggestTrakt(time.Now())
+	client.watchlist = nil
+
+	result, err := makeWhatShouldIWatchHandler(client)(context.Background(), json.RawMessage(`{"type":"movie"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].Text
+	if strings.Contains(text, "Severance") || !strings.Contains(text, "Arrival") {
+		t.Errorf("expected only movies, got:\n%s", text)
+	}
+	if !strings.Contains(text, "Couldn't check your watchlist") {
+		t.Errorf("expected a note about the failed watchlist, got:\n%s", text)
+	}
+}
+
+func TestWhatShouldIWatch_AllSourcesFail(t *testing.T) {
+	client := newSuggestTrakt(time.Now())
+	client.watchlist, client.shows = nil, nil
+
+	result, err := makeWhatShouldIWatchHandler(client)(context.Background(), json.RawMessage(`{"type":"movie"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected an error when every source fails, got: %s", result.Content[0].Text)
+	}
+}
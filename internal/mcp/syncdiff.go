@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/backup"
+	"github.com/kofifort/trakt-mcp-go/internal/mirror"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// RegisterSyncDiffTool registers the sync_diff tool, which compares a
+// local copy of the account, the mirror m or a backup in dir, with Trakt.
+// Either may be absent. client must read from the API, not from m.
+func RegisterSyncDiffTool(s *Server, client TraktAPI, m *mirror.Mirror, dir string) {
+	var sources []string
+	if m != nil {
+		sources = append(sources, "mirror")
+	}
+	if dir != "" {
+		sources = append(sources, "backup")
+	}
+	if len(sources) == 0 {
+		return
+	}
+
+	s.RegisterTool(Tool{
+		Name:        "sync_diff",
+		Description: "Compare the user's Trakt account now with a local copy of it, the mirror or a backup, and report what changed since: new plays, removed plays and items, and changed ratings. Use after using other Trakt apps, or to check a session that may have logged the wrong things.",
+		Annotations: &ToolAnnotations{Title: "Compare with Trakt", ReadOnlyHint: true},
+		InputSchema: JSONSchema{
+			Type: "object",
+			Properties: map[string]JSONSchema{
+				"source": {
+					Type:        "string",
+					Description: fmt.Sprintf("Local copy to compare (default: %s)", sources[0]),
+					Enum:        sources,
+				},
+				"archive": {
+					Type:        "string",
+					Description: "File name of a backup in the backup directory, when source is backup (default: the newest backup)",
+				},
+			},
+		},
+	}, makeSyncDiffHandler(client, m, dir))
+}
+
+func makeSyncDiffHandler(client TraktAPI, m *mirror.Mirror, dir string) ToolHandler {
+	type syncDiffArgs struct {
+		Source  string `json:"source"`
+		Archive string `json:"archive"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a syncDiffArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		if a.Source == "" {
+			a.Source = "mirror"
+			if m == nil {
+				a.Source = "backup"
+			}
+		}
+
+		var local *backup.Snapshot
+		var heading string
+		switch {
+		case a.Source == "mirror" && m != nil:
+			if !m.Synced() {
+				return ToolCallResult{
+					Content: []Content{TextContent("Error: the mirror hasn't synced yet, so there's nothing to compare.")},
+					IsError: true,
+				}, nil
+			}
+			var err error
+			if local, err = mirrorSnapshot(ctx, m); err != nil {
+				return ErrorContent(ctx, err), nil
+			}
+			heading = fmt.Sprintf("🔄 **Mirror** (synced %s) compared with Trakt now", local.TakenAt.Local().Format("2006-01-02 15:04"))
+		case a.Source == "backup" && dir != "":
+			path, err := backup.Find(dir, a.Archive)
+			if err != nil {
+				return ToolCallResult{
+					Content: []Content{TextContent(msg(ctx, msgNoBackup, err))},
+					IsError: true,
+				}, nil
+			}
+			if local, err = backup.Load(path); err != nil {
+				return ToolCallResult{
+					Content: []Content{TextContent("Error: " + err.Error())},
+					IsError: true,
+				}, nil
+			}
+			heading = fmt.Sprintf("🔄 **%s** (taken %s) compared with Trakt now", filepath.Base(path), local.TakenAt.Local().Format("2006-01-02 15:04"))
+		default:
+			return ToolCallResult{
+				Content: []Content{TextContent(fmt.Sprintf("Error: no %s is configured to compare with.", a.Source))},
+				IsError: true,
+			}, nil
+		}
+
+		remote, err := backup.Take(ctx, client)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		if a.Source == "mirror" {
+			// The mirror doesn't keep lists
+			remote.Lists = nil
+		}
+
+		return ToolCallResult{Content: []Content{TextContent(formatSyncDiff(heading, backup.Compare(local, remote)))}}, nil
+	}
+}
+
+// mirrorSnapshot copies what the mirror holds, as of its last sync, into a
+// snapshot to compare.
+func mirrorSnapshot(ctx context.Context, m *mirror.Mirror) (*backup.Snapshot, error) {
+	snap := &backup.Snapshot{
+		Version:    backup.FormatVersion,
+		TakenAt:    m.Stats().SyncedAt,
+		Ratings:    m.Ratings(""),
+		Watchlist:  m.Watchlist(""),
+		Collection: m.Collection(),
+	}
+	err := m.ForEachHistoryItem(ctx, "", func(h trakt.HistoryItem) error {
+		snap.History = append(snap.History, h)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// formatSyncDiff reports the changes from a local copy to Trakt.
+func formatSyncDiff(heading string, d backup.Diff) string {
+	var sb strings.Builder
+	sb.WriteString(heading + "\n")
+	if d.Empty() {
+		sb.WriteString("\nNo differences: the local copy matches Trakt.\n")
+		return sb.String()
+	}
+	writeChanges(&sb, "New on Trakt", d.Added)
+	writeChanges(&sb, "Removed from Trakt", d.Removed)
+	writeChanges(&sb, "Ratings changed", d.Changed)
+	return sb.String()
+}
@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/backup"
+	"github.com/kofifort/trakt-mcp-go/internal/mirror"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestSyncDiff_Backup(t *testing.T) {
+	dir := t.TempDir()
+	dune := &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 7}}
+	severance := &trakt.Show{Title: "Severance", IDs: trakt.ShowIDs{Trakt: 1}}
+	watchedAt := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	_, err := backup.Save(dir, &backup.Snapshot{
+		Version: backup.FormatVersion,
+		TakenAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		History: []trakt.HistoryItem{{ID: 1, Type: "movie", WatchedAt: watchedAt, Movie: dune}},
+		Ratings: []trakt.Rating{{Type: "movie", Rating: 8, Movie: dune}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Since the backup, another app logged an episode and the Dune play
+	// was removed and its rating changed
+	client := &backupTrakt{
+		fakeTrakt: fakeTrakt{authenticated: true},
+		history: []trakt.HistoryItem{
+			{ID: 2, Type: "episode", WatchedAt: watchedAt.Add(24 * time.Hour), Show: severance, Episode: &trakt.Episode{Season: 1, Number: 2, IDs: trakt.EpisodeIDs{Trakt: 102}}},
+		},
+		ratings: []trakt.Rating{{Type: "movie", Rating: 6, Movie: dune}},
+	}
+
+	result, err := makeSyncDiffHandler(client, nil, dir)(context.Background(), json.RawMessage(`{}`))
+	if err != nil || result.IsError {
+		t.Fatalf("sync_diff failed: %v %+v", err, result)
+	}
+	text := result.Content[0].Text
+	for _, want := range []string{
+		"**trakt-backup-20260302T090000Z.json.gz** (taken",
+		"**New on Trakt** (1)\n- history: Severance S01E02",
+		"**Removed from Trakt** (1)\n- history: Dune (2021)",
+		"**Ratings changed** (1)\n- ratings: Dune (2021), 8 → 6",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestSyncDiff_MirrorNotSynced(t *testing.T) {
+	m, err := mirror.Open(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &backupTrakt{fakeTrakt: fakeTrakt{authenticated: true}}
+
+	result, err := makeSyncDiffHandler(client, m, "")(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "hasn't synced yet") {
+		t.Errorf("expected an unsynced mirror to be refused, got %+v", result)
+	}
+
+	result, _ = makeSyncDiffHandler(client, m, "")(context.Background(), json.RawMessage(`{"source":"backup"}`))
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "no backup is configured") {
+		t.Errorf("expected a missing backup directory to be reported, got %+v", result)
+	}
+}
+
+func TestRegisterSyncDiffTool_NothingToCompare(t *testing.T) {
+	server := NewServer(nil)
+	RegisterSyncDiffTool(server, &fakeTrakt{}, nil, "")
+
+	if _, ok := server.tools["sync_diff"]; ok {
+		t.Error("sync_diff should not be registered without a mirror or backups")
+	}
+}
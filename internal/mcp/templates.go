@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// templateTools are the tools whose text output a user template can
+// replace. The template for a tool is the file <tool>.tmpl in the template
+// directory.
+var templateTools = []string{"search_show", "get_history"}
+
+// templateFuncs are available to every output template.
+var templateFuncs = template.FuncMap{
+	"date":    func(t time.Time) string { return t.Local().Format("2006-01-02") },
+	"ago":     func(t time.Time) string { return relativeDate(t, time.Now()) },
+	"snippet": snippet,
+	"join":    strings.Join,
+}
+
+// searchTemplateData is what a search_show template is executed with.
+type searchTemplateData struct {
+	Query   string
+	Page    int
+	Limit   int
+	Results []trakt.SearchResult
+}
+
+// historyTemplateData is what a get_history template is executed with.
+type historyTemplateData struct {
+	Items      []trakt.HistoryItem
+	Pagination trakt.Pagination
+}
+
+// Templates holds user templates that reshape tool output, keyed by tool.
+type Templates struct {
+	byTool map[string]*template.Template
+}
+
+// DefaultTemplateDir returns the template directory in the user's config
+// directory, e.g. ~/.config/trakt-mcp/templates on Linux.
+func DefaultTemplateDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "trakt-mcp", "templates"), nil
+}
+
+// LoadTemplates parses the templates in dir. Tools without a template file
+// keep their built-in output, and a missing directory means no templates.
+// Templates that fail to parse are skipped and reported in the error, so
+// the rest still load.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{byTool: make(map[string]*template.Template)}
+	var errs []error
+	for _, tool := range templateTools {
+		path := filepath.Join(dir, tool+".tmpl")
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tmpl, err := template.New(tool).Funcs(templateFuncs).Parse(string(data))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		t.byTool[tool] = tmpl
+	}
+	return t, errors.Join(errs...)
+}
+
+// Tools returns the names of the tools that have a template, sorted.
+func (t *Templates) Tools() []string {
+	names := make([]string, 0, len(t.byTool))
+	for name := range t.byTool {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTemplates replaces the user output templates. Nil restores the
+// built-in output for every tool.
+func (s *Server) SetTemplates(t *Templates) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates = t
+}
+
+// render executes the user template for tool with data. It reports false
+// when there is no template, or when the template fails, so the caller
+// falls back to the built-in output.
+func (s *Server) render(tool string, data any) (string, bool) {
+	s.mu.RLock()
+	templates := s.templates
+	s.mu.RUnlock()
+	if templates == nil {
+		return "", false
+	}
+	tmpl := templates.byTool[tool]
+	if tmpl == nil {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		s.logger.Warn("output template failed, using built-in output", "tool", tool, "error", err)
+		return "", false
+	}
+	return buf.String(), true
+}
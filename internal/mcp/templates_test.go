@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func writeTemplate(t *testing.T, dir, tool, text string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, tool+".tmpl"), []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "search_show", `{{range .Results}}{{.Type}}{{end}}`)
+	writeTemplate(t, dir, "get_history", `{{range .Items}`)
+
+	templates, err := LoadTemplates(dir)
+	if err == nil || !strings.Contains(err.Error(), "get_history.tmpl") {
+		t.Errorf("expected a parse error naming get_history.tmpl, got %v", err)
+	}
+	if tools := templates.Tools(); len(tools) != 1 || tools[0] != "search_show" {
+		t.Errorf("expected only the valid template to load, got %v", tools)
+	}
+
+	// A missing directory just means no templates
+	templates, err = LoadTemplates(filepath.Join(dir, "missing"))
+	if err != nil || len(templates.Tools()) != 0 {
+		t.Errorf("expected no templates and no error, got %v, %v", templates.Tools(), err)
+	}
+}
+
+func TestServer_RenderFallsBackOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "get_history", `{{.Missing}}`)
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+
+	server := NewServer(nil)
+	if _, ok := server.render("get_history", historyTemplateData{}); ok {
+		t.Error("expected no output without templates")
+	}
+	server.SetTemplates(templates)
+	if _, ok := server.render("get_history", historyTemplateData{}); ok {
+		t.Error("expected a failing template to fall back to built-in output")
+	}
+}
+
+func TestGetHistoryHandler_Template(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "get_history",
+		`{{range .Items}}{{.Movie.Title}} on {{date .WatchedAt}}{{"\n"}}{{end}}{{.Pagination.ItemCount}} total`)
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+
+	server := NewServer(nil)
+	server.SetTemplates(templates)
+	client := &fakeTrakt{
+		authenticated: true,
+		getHistory: func(ctx context.Context, historyType string, limit int) ([]trakt.HistoryItem, error) {
+			return []trakt.HistoryItem{{Type: "movie", Movie: &trakt.Movie{Title: "Inception"}}}, nil
+		},
+	}
+
+	result, err := makeGetHistoryHandler(server, client)(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result.Content[0].Text; got != "Inception on 0001-01-01\n1 total" {
+		t.Errorf("unexpected templated output %q", got)
+	}
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/kofifort/trakt-mcp-go/internal/tmdb"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// enrichedTrakt fills in search results from TMDB: posters and backdrops
+// Trakt has none of, movie taglines, and overviews where TMDB's is fuller.
+// Results without a TMDB ID, or that TMDB fails to return, are left as
+// Trakt sent them.
+type enrichedTrakt struct {
+	TraktAPI
+	tmdb *tmdb.Client
+}
+
+// Enriched returns client with its search results enriched from TMDB.
+func Enriched(client TraktAPI, t *tmdb.Client) TraktAPI {
+	return &enrichedTrakt{TraktAPI: client, tmdb: t}
+}
+
+func (c *enrichedTrakt) Search(ctx context.Context, query string, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error) {
+	results, err := c.TraktAPI.Search(ctx, query, searchType, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	forEachTitle(results, func(show *trakt.Show, movie *trakt.Movie) {
+		switch {
+		case show != nil && show.IDs.TMDB != 0:
+			if d, err := c.tmdb.Show(ctx, show.IDs.TMDB); err == nil {
+				enrich(&show.Images, &show.Overview, d)
+			}
+		case movie != nil && movie.IDs.TMDB != 0:
+			if d, err := c.tmdb.Movie(ctx, movie.IDs.TMDB); err == nil {
+				enrich(&movie.Images, &movie.Overview, d)
+				if movie.Tagline == "" {
+					movie.Tagline = d.Tagline
+				}
+			}
+		}
+	})
+	return results, nil
+}
+
+// enrich adds TMDB's artwork where Trakt has none, and its overview if
+// it's longer.
+func enrich(images **trakt.Images, overview *string, d *tmdb.Details) {
+	if len(d.Overview) > len(*overview) {
+		*overview = d.Overview
+	}
+	poster, backdrop := d.PosterURL(), d.BackdropURL()
+	if poster == "" && backdrop == "" {
+		return
+	}
+	if *images == nil {
+		*images = &trakt.Images{}
+	}
+	if poster != "" && (*images).PosterURL() == "" {
+		(*images).Poster = []string{poster}
+	}
+	if backdrop != "" && (*images).FanartURL() == "" {
+		(*images).Fanart = []string{backdrop}
+	}
+}
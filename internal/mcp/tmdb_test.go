@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/tmdb"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// searchTrakt answers every search with results.
+type searchTrakt struct {
+	fakeTrakt
+	results []trakt.SearchResult
+}
+
+func (f *searchTrakt) Search(ctx context.Context, query string, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error) {
+	return f.results, nil
+}
+
+func TestEnriched_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/movie/438631":
+			_, _ = w.Write([]byte(`{"overview":"Paul Atreides, a brilliant and gifted young man born into a great destiny beyond his understanding, must travel to the most dangerous planet in the universe.",
+				"tagline":"Beyond fear, destiny awaits.","poster_path":"/dune.jpg","backdrop_path":"/dune-backdrop.jpg"}`))
+		case "/tv/95396":
+			_, _ = w.Write([]byte(`{"overview":"Short.","poster_path":"/severance.jpg","backdrop_path":"/severance-backdrop.jpg"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := tmdb.NewClient("test-key")
+	client.SetBaseURL(server.URL)
+
+	api := Enriched(&searchTrakt{results: []trakt.SearchResult{
+		{Type: "movie", Movie: &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 1, TMDB: 438631}, Overview: "Paul travels to Arrakis."}},
+		{Type: "show", Show: &trakt.Show{Title: "Severance", Year: 2022, IDs: trakt.ShowIDs{Trakt: 2, TMDB: 95396}, Overview: "Mark leads a team of office workers.",
+			Images: &trakt.Images{Poster: []string{"walter-r2.trakt.tv/severance.jpg.webp"}}}},
+		{Type: "movie", Movie: &trakt.Movie{Title: "Unknown", IDs: trakt.MovieIDs{Trakt: 3, TMDB: 1}}},
+	}}, client)
+
+	result, err := makeSearchHandler(NewServer(nil), api)(context.Background(), json.RawMessage(`{"query":"x"}`))
+	if err != nil || result.IsError {
+		t.Fatalf("search failed: %v %+v", err, result)
+	}
+	text := result.Content[0].Text
+	for _, want := range []string{
+		"   \"Beyond fear, destiny awaits.\"\n   Paul Atreides, a brilliant and gifted young man",
+		"Poster: https://image.tmdb.org/t/p/w500/dune.jpg\n   Backdrop: https://image.tmdb.org/t/p/w1280/dune-backdrop.jpg",
+		// Trakt's poster and longer overview are kept
+		"   Mark leads a team of office workers.",
+		"Poster: https://walter-r2.trakt.tv/severance.jpg.webp\n   Backdrop: https://image.tmdb.org/t/p/w1280/severance-backdrop.jpg",
+		"🎬 **Unknown** - Movie - Trakt ID: 3\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+}
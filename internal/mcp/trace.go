@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTraceMaxSize is the size at which the trace file is rotated.
+	DefaultTraceMaxSize = 10 * 1024 * 1024
+	// traceBackups is the number of rotated trace files kept.
+	traceBackups = 3
+	redacted     = "[REDACTED]"
+)
+
+// sensitiveKeys are JSON object keys whose values are always redacted.
+var sensitiveKeys = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"client_secret": true,
+	"device_code":   true,
+	"authorization": true,
+	"token":         true,
+	"password":      true,
+}
+
+// tokenPattern matches credentials embedded in free text, such as Trakt's
+// 64-character hex tokens and bearer headers.
+var tokenPattern = regexp.MustCompile(`(?i)bearer\s+\S+|\b[0-9a-f]{64}\b`)
+
+// Tracer writes every JSON-RPC message to a size-rotated file with
+// credentials redacted, for diagnosing protocol issues with a client.
+type Tracer struct {
+	path    string
+	maxSize int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// traceEntry is one line of the trace file.
+type traceEntry struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"direction"` // "in" or "out"
+	Message   json.RawMessage `json:"message,omitempty"`
+	Raw       string          `json:"raw,omitempty"` // set when the message isn't valid JSON
+}
+
+// NewTracer opens (or appends to) the trace file at path.
+func NewTracer(path string, maxSize int64) (*Tracer, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultTraceMaxSize
+	}
+	t := &Tracer{path: path, maxSize: maxSize}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Trace records a message. Failures are swallowed so tracing never disturbs
+// the session it observes.
+func (t *Tracer) Trace(direction string, msg []byte) {
+	entry := traceEntry{Time: time.Now().UTC(), Direction: direction}
+	if redactedMsg, err := redactMessage(msg); err == nil {
+		entry.Message = redactedMsg
+	} else {
+		entry.Raw = tokenPattern.ReplaceAllString(string(msg), redacted)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.file == nil {
+		return
+	}
+	if t.size+int64(len(line)) > t.maxSize {
+		if err := t.rotate(); err != nil {
+			return
+		}
+	}
+	n, _ := t.file.Write(line)
+	t.size += int64(n)
+}
+
+// Close closes the trace file.
+func (t *Tracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+func (t *Tracer) open() error {
+	f, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open trace file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat trace file: %w", err)
+	}
+	t.file = f
+	t.size = info.Size()
+	return nil
+}
+
+// rotate shifts path.N to path.N+1, moves the current file to path.1 and
+// starts a fresh one. Callers must hold t.mu.
+func (t *Tracer) rotate() error {
+	t.file.Close()
+	t.file = nil
+
+	for i := traceBackups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", t.path, i), fmt.Sprintf("%s.%d", t.path, i+1))
+	}
+	if err := os.Rename(t.path, t.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return t.open()
+}
+
+// redactMessage replaces credential values in a JSON message.
+func redactMessage(msg []byte) (json.RawMessage, error) {
+	var v any
+	if err := json.Unmarshal(msg, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactValue(v))
+}
+
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if sensitiveKeys[strings.ToLower(k)] {
+				val[k] = redacted
+				continue
+			}
+			val[k] = redactValue(child)
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = redactValue(child)
+		}
+		return val
+	case string:
+		return tokenPattern.ReplaceAllString(val, redacted)
+	default:
+		return v
+	}
+}
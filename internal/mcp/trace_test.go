@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTracer_RedactsCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	tracer, err := NewTracer(path, 0)
+	if err != nil {
+		t.Fatalf("NewTracer failed: %v", err)
+	}
+
+	token := strings.Repeat("ab", 32)
+	tracer.Trace("out", []byte(`{"result":{"access_token":"secret-value","content":[{"text":"Your token is `+token+`"}]}}`))
+	tracer.Trace("in", []byte(`not json Bearer abc123`))
+	tracer.Trace("out", []byte(`{"error":{"code":-32601,"message":"Method not found"}}`))
+	if err := tracer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read trace: %v", err)
+	}
+	trace := string(data)
+
+	for _, secret := range []string{"secret-value", token, "abc123"} {
+		if strings.Contains(trace, secret) {
+			t.Errorf("trace leaked %q:\n%s", secret, trace)
+		}
+	}
+	if !strings.Contains(trace, "-32601") {
+		t.Errorf("error codes should not be redacted:\n%s", trace)
+	}
+	if strings.Count(trace, "\n") != 3 {
+		t.Errorf("expected 3 trace lines, got:\n%s", trace)
+	}
+}
+
+func TestTracer_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	tracer, err := NewTracer(path, 200)
+	if err != nil {
+		t.Fatalf("NewTracer failed: %v", err)
+	}
+	defer tracer.Close()
+
+	msg := []byte(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progress":1}}`)
+	for i := 0; i < 10; i++ {
+		tracer.Trace("out", msg)
+	}
+
+	for _, p := range []string{path, path + ".1", path + ".2", path + ".3"} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("expected %s to exist: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(path + ".4"); !os.IsNotExist(err) {
+		t.Errorf("expected at most %d backups", traceBackups)
+	}
+}
+
+func TestServer_Tracing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	tracer, err := NewTracer(path, 0)
+	if err != nil {
+		t.Fatalf("NewTracer failed: %v", err)
+	}
+
+	server := NewServer(nil)
+	server.SetTracer(tracer)
+
+	input := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}` + "\n"
+	var buf bytes.Buffer
+	if err := server.RunWithIO(context.Background(), strings.NewReader(input), &buf); err != nil {
+		t.Fatalf("RunWithIO failed: %v", err)
+	}
+	tracer.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read trace: %v", err)
+	}
+	if !strings.Contains(string(data), `"direction":"in"`) || !strings.Contains(string(data), `"direction":"out"`) {
+		t.Errorf("expected inbound and outbound entries, got:\n%s", data)
+	}
+}
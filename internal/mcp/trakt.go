@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// TraktAPI is the subset of the Trakt client used by tools and resources.
+// *trakt.Client is the production implementation; tests and embedders can
+// supply their own.
+type TraktAPI interface {
+	IsConfigured() bool
+	IsAuthenticated() bool
+	SetToken(token *trakt.Token)
+	RateLimits() []trakt.RateLimit
+	CacheStats() []trakt.CacheStats
+	Ping(ctx context.Context) error
+
+	// Device and browser authentication
+	GetDeviceCode(ctx context.Context) (*trakt.DeviceCode, error)
+	PollForToken(ctx context.Context, deviceCode string) (*trakt.Token, error)
+	WaitForDeviceToken(ctx context.Context, code *trakt.DeviceCode) (*trakt.Token, error)
+	RefreshToken(ctx context.Context) (*trakt.Token, error)
+	RedirectURI() string
+	AuthorizationURL(redirectURI, state, codeChallenge string) string
+	ExchangeCode(ctx context.Context, code, redirectURI, codeVerifier string) (*trakt.Token, error)
+
+	// Lookups and sync
+	Search(ctx context.Context, query string, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error)
+	LookupID(ctx context.Context, idType, id, searchType string, opts ...trakt.RequestOption) ([]trakt.SearchResult, error)
+	GetAliases(ctx context.Context, kind, id string) ([]trakt.Alias, error)
+	GetSeasons(ctx context.Context, showID string, opts ...trakt.RequestOption) ([]trakt.Season, error)
+	GetEpisode(ctx context.Context, showID string, season, episode int, opts ...trakt.RequestOption) (*trakt.Episode, error)
+	GetHistory(ctx context.Context, historyType string, limit int, opts ...trakt.RequestOption) ([]trakt.HistoryItem, error)
+	GetHistoryPage(ctx context.Context, historyType string, page, limit int) (*trakt.HistoryPage, error)
+	ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error
+	ForEachWatched(ctx context.Context, watchedType string, fn func(trakt.WatchedEntry) error, opts ...trakt.RequestOption) error
+	ForEachUserWatched(ctx context.Context, user, watchedType string, fn func(trakt.WatchedEntry) error, opts ...trakt.RequestOption) error
+	ForEachCollected(ctx context.Context, collectionType string, fn func(trakt.CollectionEntry) error) error
+	GetUserRatings(ctx context.Context, user, ratingType string) ([]trakt.Rating, error)
+	GetWatchlist(ctx context.Context, watchlistType string, opts ...trakt.RequestOption) ([]trakt.WatchlistItem, error)
+	GetLists(ctx context.Context) ([]trakt.List, error)
+	GetListItems(ctx context.Context, listID string) ([]trakt.ListItem, error)
+	GetShowProgress(ctx context.Context, showID string) (*trakt.ShowProgress, error)
+	GetHidden(ctx context.Context, section, itemType string) ([]trakt.HiddenItem, error)
+	GetMyShowsCalendar(ctx context.Context, start time.Time, days int, opts ...trakt.RequestOption) ([]trakt.CalendarEntry, error)
+	GetMyMoviesCalendar(ctx context.Context, start time.Time, days int, opts ...trakt.RequestOption) ([]trakt.MovieCalendarEntry, error)
+	GetRecommendedShows(ctx context.Context, limit int, opts ...trakt.RequestOption) ([]trakt.Show, error)
+	GetRecommendedMovies(ctx context.Context, limit int, opts ...trakt.RequestOption) ([]trakt.Movie, error)
+	AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error)
+	RemoveFromHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error)
+	AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error)
+	AddToWatchlist(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error)
+	AddToCollection(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error)
+	CreateList(ctx context.Context, list trakt.List) (*trakt.List, error)
+	AddListItems(ctx context.Context, listID string, items trakt.SyncItems) (*trakt.SyncResponse, error)
+	ScrobbleStart(ctx context.Context, s trakt.Scrobble) (*trakt.ScrobbleResponse, error)
+	ScrobblePause(ctx context.Context, s trakt.Scrobble) (*trakt.ScrobbleResponse, error)
+	ScrobbleStop(ctx context.Context, s trakt.Scrobble) (*trakt.ScrobbleResponse, error)
+}
+
+var _ TraktAPI = (*trakt.Client)(nil)
@@ -2,7 +2,11 @@
 // MCP uses JSON-RPC 2.0 over stdio for communication with AI assistants.
 package mcp
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
 
 // JSON-RPC 2.0 types
 
@@ -14,6 +18,13 @@ type Request struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 }
 
+// IsNotification reports whether the request has no id, meaning the sender
+// expects no response. A null id can't be answered either, so it counts as
+// none.
+func (r *Request) IsNotification() bool {
+	return len(r.ID) == 0 || string(r.ID) == "null"
+}
+
 // Response represents a JSON-RPC 2.0 response.
 type Response struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -22,6 +33,13 @@ type Response struct {
 	Error   *Error          `json:"error,omitempty"`
 }
 
+// Notification represents a JSON-RPC 2.0 notification sent by the server.
+type Notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
 // Error represents a JSON-RPC 2.0 error.
 type Error struct {
 	Code    int    `json:"code"`
@@ -29,6 +47,10 @@ type Error struct {
 	Data    any    `json:"data,omitempty"`
 }
 
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
 // Standard JSON-RPC 2.0 error codes
 const (
 	ParseError     = -32700
@@ -38,6 +60,11 @@ const (
 	InternalError  = -32603
 )
 
+// MCP-specific error codes
+const (
+	ResourceNotFound = -32002
+)
+
 // MCP Protocol types
 
 // InitializeParams contains parameters for the initialize request.
@@ -54,9 +81,13 @@ type InitializeResult struct {
 	ServerInfo      Implementation `json:"serverInfo"`
 }
 
-// Capabilities describes what the server can do.
+// Capabilities describes what a server or client can do.
 type Capabilities struct {
-	Tools *ToolsCapability `json:"tools,omitempty"`
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
+	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
+	Sampling  *SamplingCapability  `json:"sampling,omitempty"` // client only
+	Logging   *LoggingCapability   `json:"logging,omitempty"`
 }
 
 // ToolsCapability describes tool-related capabilities.
@@ -64,6 +95,23 @@ type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// ResourcesCapability describes resource-related capabilities.
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// PromptsCapability describes prompt-related capabilities.
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// SamplingCapability indicates the client can run LLM completions for the server.
+type SamplingCapability struct{}
+
+// LoggingCapability indicates the server sends notifications/message.
+type LoggingCapability struct{}
+
 // Implementation identifies a client or server.
 type Implementation struct {
 	Name    string `json:"name"`
@@ -72,9 +120,24 @@ type Implementation struct {
 
 // Tool represents an MCP tool that can be called.
 type Tool struct {
-	Name        string     `json:"name"`
-	Description string     `json:"description,omitempty"`
-	InputSchema JSONSchema `json:"inputSchema"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	InputSchema JSONSchema       `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"` // 2025-03-26+
+}
+
+// ToolAnnotations are hints describing a tool's behavior.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"` // defaults to true when omitted
+	IdempotentHint  bool   `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"` // defaults to true when omitted
+}
+
+// boolPtr returns a pointer to b, for the hints that default to true.
+func boolPtr(b bool) *bool {
+	return &b
 }
 
 // JSONSchema is a simplified JSON Schema for tool parameters.
@@ -87,27 +150,58 @@ type JSONSchema struct {
 	AdditionalProperties bool                  `json:"additionalProperties,omitempty"`
 }
 
+// PaginatedParams contains the optional cursor for list requests.
+type PaginatedParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
 // ToolsListResult contains the response to a tools/list request.
 type ToolsListResult struct {
-	Tools []Tool `json:"tools"`
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // ToolCallParams contains parameters for a tools/call request.
 type ToolCallParams struct {
 	Name      string          `json:"name"`
 	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Meta      *RequestMeta    `json:"_meta,omitempty"`
+}
+
+// RequestMeta carries request metadata such as a progress token.
+type RequestMeta struct {
+	ProgressToken any `json:"progressToken,omitempty"` // string or number
+}
+
+// ProgressParams contains parameters for a notifications/progress notification.
+type ProgressParams struct {
+	ProgressToken any     `json:"progressToken"`
+	Progress      float64 `json:"progress"`
+	Total         float64 `json:"total,omitempty"`
+	Message       string  `json:"message,omitempty"`
+}
+
+// LoggingMessageParams contains parameters for a notifications/message notification.
+type LoggingMessageParams struct {
+	Level  string `json:"level"`
+	Logger string `json:"logger,omitempty"`
+	Data   any    `json:"data"`
 }
 
 // ToolCallResult contains the response to a tools/call request.
 type ToolCallResult struct {
-	Content []Content `json:"content"`
-	IsError bool      `json:"isError,omitempty"`
+	Content           []Content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"` // 2025-06-18+
+	IsError           bool      `json:"isError,omitempty"`
 }
 
 // Content represents a piece of content in a tool response.
 type Content struct {
-	Type string `json:"type"` // "text", "image", "resource"
-	Text string `json:"text,omitempty"`
+	Type     string `json:"type"` // "text", "image", "resource", "resource_link"
+	Text     string `json:"text,omitempty"`
+	URI      string `json:"uri,omitempty"`  // resource_link
+	Name     string `json:"name,omitempty"` // resource_link
+	MimeType string `json:"mimeType,omitempty"`
 }
 
 // TextContent creates a text content item.
@@ -115,10 +209,108 @@ func TextContent(text string) Content {
 	return Content{Type: "text", Text: text}
 }
 
+// ResourceLinkContent creates a content item that points at a resource the
+// client can fetch itself, such as an image URL.
+func ResourceLinkContent(uri, name, mimeType string) Content {
+	return Content{Type: "resource_link", URI: uri, Name: name, MimeType: mimeType}
+}
+
 // ErrorContent creates an error content item.
-func ErrorContent(err error) ToolCallResult {
+func ErrorContent(ctx context.Context, err error) ToolCallResult {
 	return ToolCallResult{
-		Content: []Content{TextContent(err.Error())},
+		Content: []Content{TextContent(errorMessage(ctx, err))},
 		IsError: true,
 	}
 }
+
+// Resource describes a readable piece of context exposed by the server.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourcesListResult contains the response to a resources/list request.
+type ResourcesListResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ResourceReadParams contains parameters for a resources/read request.
+type ResourceReadParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceReadResult contains the response to a resources/read request.
+type ResourceReadResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// ResourceContents is the body of a resource returned by resources/read.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// Prompt describes a message template the server offers, which clients
+// typically show as a slash command.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument a prompt accepts.
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptsListResult contains the response to a prompts/list request.
+type PromptsListResult struct {
+	Prompts    []Prompt `json:"prompts"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// PromptGetParams contains parameters for a prompts/get request.
+type PromptGetParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// PromptGetResult contains the response to a prompts/get request.
+type PromptGetResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptMessage is a single message of a prompt.
+type PromptMessage struct {
+	Role    string  `json:"role"` // "user" or "assistant"
+	Content Content `json:"content"`
+}
+
+// CreateMessageParams contains parameters for a sampling/createMessage request.
+type CreateMessageParams struct {
+	Messages       []SamplingMessage `json:"messages"`
+	SystemPrompt   string            `json:"systemPrompt,omitempty"`
+	IncludeContext string            `json:"includeContext,omitempty"` // "none", "thisServer", "allServers"
+	MaxTokens      int               `json:"maxTokens"`
+}
+
+// SamplingMessage is a single message in a sampling conversation.
+type SamplingMessage struct {
+	Role    string  `json:"role"` // "user" or "assistant"
+	Content Content `json:"content"`
+}
+
+// CreateMessageResult contains the client's response to sampling/createMessage.
+type CreateMessageResult struct {
+	Role       string  `json:"role"`
+	Content    Content `json:"content"`
+	Model      string  `json:"model,omitempty"`
+	StopReason string  `json:"stopReason,omitempty"`
+}
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// undoWriteKey identifies undo_last_watch in the write guard. Every undo
+// shares it, since a retry can't be told apart from a second undo by its
+// arguments.
+var undoWriteKey = newWriteKey("undo_last_watch")
+
+func makeUndoLastWatchHandler(s *Server, client TraktAPI, guard *writeGuard) ToolHandler {
+	type undoArgs struct {
+		Force   bool   `json:"force"`
+		Confirm string `json:"confirm"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a undoArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+
+		// A retry would otherwise remove the entry before the one undone.
+		// A confirmation token names the entry, so it needs no such check.
+		if !a.Force && a.Confirm == "" {
+			prev, repeated, err := guard.claim(ctx, undoWriteKey)
+			if err != nil {
+				return ErrorContent(ctx, err), nil
+			}
+			if repeated {
+				return repeatedWrite(prev, msg(ctx, msgRepeatedUndo)), nil
+			}
+			defer guard.release(undoWriteKey)
+		}
+
+		history, err := client.GetHistory(ctx, "", 1)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		if len(history) == 0 {
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNoHistory))}}, nil
+		}
+		last := history[0]
+		now := time.Now()
+
+		if s.ConfirmDestructive() {
+			action := fmt.Sprintf("undo_last_watch:%d", last.ID)
+			if a.Confirm == "" {
+				token, err := s.confirmations.issue(action)
+				if err != nil {
+					return ErrorContent(ctx, fmt.Errorf("create confirmation token: %w", err)), nil
+				}
+				return ToolCallResult{
+					Content: []Content{TextContent(msg(ctx, msgConfirmUndo,
+						formatHistoryItem(last, now), token, int(confirmationTTL.Minutes())))},
+				}, nil
+			}
+			if !s.confirmations.redeem(a.Confirm, action) {
+				return ToolCallResult{
+					Content: []Content{TextContent(msg(ctx, msgConfirmInvalid))},
+					IsError: true,
+				}, nil
+			}
+		}
+
+		resp, err := client.RemoveFromHistory(ctx, trakt.WatchedItem{HistoryIDs: []int64{last.ID}})
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		if resp.Deleted.Episodes+resp.Deleted.Movies == 0 {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNothingUndone, last.ID))},
+				IsError: true,
+			}, nil
+		}
+
+		result := ToolCallResult{
+			Content: []Content{TextContent(msg(ctx, msgUndone, formatHistoryItem(last, now)))},
+		}
+		// The undone watch may be logged again straight away, which
+		// mustn't look like a retry of the original log
+		guard.forget()
+		guard.remember(undoWriteKey, result)
+		return result, nil
+	}
+}
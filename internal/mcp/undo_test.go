@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestUndoLastWatchHandler(t *testing.T) {
+	history := []trakt.HistoryItem{
+		{ID: 102, Type: "episode", WatchedAt: time.Now(),
+			Show:    &trakt.Show{Title: "Severance", IDs: trakt.ShowIDs{Trakt: 154997}},
+			Episode: &trakt.Episode{Season: 2, Number: 4, Title: "Woe's Hollow", IDs: trakt.EpisodeIDs{Trakt: 44}}},
+		{ID: 101, Type: "movie", WatchedAt: time.Now().Add(-time.Hour),
+			Movie: &trakt.Movie{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 287071}}},
+	}
+	var removed []int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/sync/history":
+			_ = json.NewEncoder(w).Encode(history[:1])
+		case "/sync/history/remove":
+			var item trakt.WatchedItem
+			_ = json.NewDecoder(r.Body).Decode(&item)
+			removed = append(removed, item.HistoryIDs...)
+			history = history[1:]
+			var resp trakt.SyncResponse
+			resp.Deleted.Episodes = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	guard := newWriteGuard()
+	undo := makeUndoLastWatchHandler(NewServer(nil), client, guard)
+
+	result, err := undo(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].Text
+	if result.IsError || !strings.Contains(text, "Removed from your history") || !strings.Contains(text, "Severance S02E04 - Woe's Hollow") {
+		t.Errorf("expected the removed episode to be described, got: %s", text)
+	}
+
+	// A retry doesn't remove the entry before it
+	result, err = undo(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Content[0].Text, "nothing else was removed") || len(removed) != 1 {
+		t.Errorf("expected the retry to remove nothing, got %v and: %s", removed, result.Content[0].Text)
+	}
+
+	// Unless forced
+	result, err = undo(context.Background(), json.RawMessage(`{"force":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Content[0].Text, "Dune") || len(removed) != 2 || removed[0] != 102 || removed[1] != 101 {
+		t.Errorf("expected history entries 102 then 101 removed, got %v and: %s", removed, result.Content[0].Text)
+	}
+}
+
+func TestUndoLastWatchHandler_ClearsLoggedWrites(t *testing.T) {
+	guard := newWriteGuard()
+	logKey := newWriteKey("movie", "287071", "")
+	guard.remember(logKey, ToolCallResult{})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sync/history":
+			_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{{ID: 1, Type: "movie", Movie: &trakt.Movie{Title: "Dune"}}})
+		case "/sync/history/remove":
+			var resp trakt.SyncResponse
+			resp.Deleted.Movies = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+	_, client := newMockTraktServer(t, handler)
+
+	if _, err := makeUndoLastWatchHandler(NewServer(nil), client, guard)(context.Background(), json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := guard.lookup(logKey); ok {
+		t.Error("expected logging the undone movie again not to count as a retry")
+	}
+}
+
+func TestUndoLastWatchHandler_Confirmation(t *testing.T) {
+	latest := int64(102)
+	var removed []int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/sync/history":
+			_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{{ID: latest, Type: "movie", Movie: &trakt.Movie{Title: "Dune", Year: 2021}}})
+		case "/sync/history/remove":
+			var item trakt.WatchedItem
+			_ = json.NewDecoder(r.Body).Decode(&item)
+			removed = append(removed, item.HistoryIDs...)
+			var resp trakt.SyncResponse
+			resp.Deleted.Movies = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+	_, client := newMockTraktServer(t, handler)
+	server := NewServer(nil)
+	server.SetConfirmDestructive(true)
+	undo := makeUndoLastWatchHandler(server, client, newWriteGuard())
+
+	preview := func() string {
+		t.Helper()
+		result, err := undo(context.Background(), json.RawMessage(`{}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		text := result.Content[0].Text
+		if !strings.Contains(text, "This will remove") || !strings.Contains(text, "Dune") || len(removed) != 0 {
+			t.Fatalf("expected a preview and nothing removed, got %v and: %s", removed, text)
+		}
+		token := regexp.MustCompile(`confirm set to "([0-9a-f]+)"`).FindStringSubmatch(text)
+		if token == nil {
+			t.Fatalf("expected a confirmation token in: %s", text)
+		}
+		return token[1]
+	}
+	confirm := func(token string) ToolCallResult {
+		t.Helper()
+		result, err := undo(context.Background(), json.RawMessage(`{"confirm":"`+token+`"}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	// The token is bound to the previewed entry
+	token := preview()
+	latest = 103
+	if result := confirm(token); !result.IsError || len(removed) != 0 {
+		t.Errorf("expected a stale token to be refused, got %v and: %s", removed, result.Content[0].Text)
+	}
+
+	token = preview()
+	if result := confirm(token); result.IsError || !strings.Contains(result.Content[0].Text, "Removed from your history") {
+		t.Errorf("expected the entry removed, got: %s", result.Content[0].Text)
+	}
+	if len(removed) != 1 || removed[0] != 103 {
+		t.Errorf("expected history entry 103 removed, got %v", removed)
+	}
+}
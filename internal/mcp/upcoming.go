@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Window of get_upcoming, in days either side of today. Trakt serves at
+// most 33 calendar days per request, which the widest window stays inside.
+const (
+	defaultUpcomingDays = 7
+	maxUpcomingDays     = 14
+)
+
+func makeGetUpcomingHandler(client TraktAPI) ToolHandler {
+	type upcomingArgs struct {
+		Days   int    `json:"days"`
+		Format string `json:"format"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a upcomingArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		if a.Days <= 0 {
+			a.Days = defaultUpcomingDays
+		}
+		a.Days = min(a.Days, maxUpcomingDays)
+
+		now := time.Now()
+		upcoming, from, to, err := upcomingEpisodes(ctx, client, a.Days, now)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		if a.Format == formatJSON {
+			return jsonResult(orEmpty(upcoming)), nil
+		}
+		if len(upcoming) == 0 {
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNothingUpcoming, a.Days, a.Days))}}, nil
+		}
+
+		return ToolCallResult{
+			Content: []Content{TextContent(formatUpcoming(upcoming, from, to, now))},
+		}, nil
+	}
+}
+
+// upcomingEpisodes returns the episodes of the user's shows airing in the
+// days either side of today that they haven't watched, and the first and
+// last moment of that window.
+func upcomingEpisodes(ctx context.Context, client TraktAPI, days int, now time.Time) (upcoming []trakt.CalendarEntry, from, to time.Time, err error) {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	from, end := today.AddDate(0, 0, -days), today.AddDate(0, 0, days+1)
+
+	// Trakt's calendar days are UTC, so fetch a day either side of the
+	// window and filter it in local time
+	entries, err := client.GetMyShowsCalendar(ctx, from.UTC().AddDate(0, 0, -1), 2*days+3)
+	if err != nil {
+		return nil, from, end, err
+	}
+	hidden, err := client.GetHidden(ctx, "calendar", "show")
+	if err != nil {
+		return nil, from, end, err
+	}
+
+	upcoming = unseenEpisodes(ctx, client, filterCalendar(entries, hidden, from, end), now)
+	return upcoming, from, end.Add(-time.Nanosecond), nil
+}
+
+// filterCalendar keeps the entries airing from from up to to, leaving out
+// shows hidden from the calendar, sorted by air time.
+func filterCalendar(entries []trakt.CalendarEntry, hidden []trakt.HiddenItem, from, to time.Time) []trakt.CalendarEntry {
+	hiddenShows := make(map[int]bool)
+	for _, h := range hidden {
+		if h.Show != nil {
+			hiddenShows[h.Show.IDs.Trakt] = true
+		}
+	}
+
+	var kept []trakt.CalendarEntry
+	for _, e := range entries {
+		if e.Show == nil || e.Episode == nil || hiddenShows[e.Show.IDs.Trakt] {
+			continue
+		}
+		if e.FirstAired.Before(from) || !e.FirstAired.Before(to) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].FirstAired.Before(kept[j].FirstAired) })
+	return kept
+}
+
+// unseenEpisodes drops the aired entries the user has already logged,
+// checking the watched progress of each show once. A show whose progress
+// can't be read keeps its episodes, since listing one too many beats
+// hiding a new episode.
+func unseenEpisodes(ctx context.Context, client TraktAPI, entries []trakt.CalendarEntry, now time.Time) []trakt.CalendarEntry {
+	var showIDs []string
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		id := fmt.Sprintf("%d", e.Show.IDs.Trakt)
+		if e.FirstAired.After(now) || seen[id] {
+			continue
+		}
+		seen[id] = true
+		showIDs = append(showIDs, id)
+	}
+
+	results, errs := trakt.Batch(ctx, showIDs, trakt.DefaultBatchParallelism, func(ctx context.Context, id string) (*trakt.ShowProgress, error) {
+		return client.GetShowProgress(ctx, id)
+	})
+	progress := make(map[string]*trakt.ShowProgress)
+	for i, id := range showIDs {
+		if errs[i] == nil {
+			progress[id] = results[i]
+		}
+	}
+
+	var unseen []trakt.CalendarEntry
+	for _, e := range entries {
+		p := progress[fmt.Sprintf("%d", e.Show.IDs.Trakt)]
+		if p != nil && p.Watched(e.Episode.Season, e.Episode.Number) {
+			continue
+		}
+		unseen = append(unseen, e)
+	}
+	return unseen
+}
+
+// formatUpcoming lists the unseen episodes in two parts: those already out
+// and those still to air.
+func formatUpcoming(entries []trakt.CalendarEntry, from, to, now time.Time) string {
+	var aired, coming strings.Builder
+	for _, e := range entries {
+		line := fmt.Sprintf("📺 **%s** S%02dE%02d", e.Show.Title, e.Episode.Season, e.Episode.Number)
+		if e.Episode.Title != "" {
+			line += " - " + e.Episode.Title
+		}
+		ids := idLine(
+			idPart("Show ID", int64(e.Show.IDs.Trakt)),
+			slugPart(e.Show.IDs.Slug),
+			idPart("Episode ID", int64(e.Episode.IDs.Trakt)),
+			e.Show.EpisodeURL(e.Episode.Season, e.Episode.Number),
+		)
+		airs := e.FirstAired.Local()
+		if airs.After(now) {
+			coming.WriteString(fmt.Sprintf("%s (airs %s at %s)\n%s", line, airs.Format("Mon, Jan 2"), airs.Format("15:04"), ids))
+		} else {
+			aired.WriteString(fmt.Sprintf("%s (aired %s)\n%s", line, relativeDate(airs, now), ids))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📅 **New for you, %s**\n", shortDateRange(from, to)))
+	if aired.Len() > 0 {
+		sb.WriteString("\n**Out now, not watched yet**\n")
+		sb.WriteString(aired.String())
+	}
+	if coming.Len() > 0 {
+		sb.WriteString("\n**Coming up**\n")
+		sb.WriteString(coming.String())
+	}
+	return sb.String()
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestGetUpcoming_LeavesOutWatchedAndHidden(t *testing.T) {
+	now := time.Now()
+	severance := &trakt.Show{Title: "Severance", IDs: trakt.ShowIDs{Trakt: 154997, Slug: "severance"}}
+	andor := &trakt.Show{Title: "Andor", IDs: trakt.ShowIDs{Trakt: 147006}}
+	hidden := &trakt.Show{Title: "Hidden Show", IDs: trakt.ShowIDs{Trakt: 1}}
+
+	client := &suggestTrakt{
+		fakeTrakt: fakeTrakt{authenticated: true},
+		progress: map[string]*trakt.ShowProgress{
+			"154997": {Seasons: []trakt.SeasonProgress{{Number: 2, Episodes: []trakt.EpisodeProgress{
+				{Number: 3, Completed: true},
+				{Number: 4, Completed: false},
+			}}}},
+		},
+		calendar: []trakt.CalendarEntry{
+			{FirstAired: now.Add(-48 * time.Hour), Show: severance, Episode: &trakt.Episode{Season: 2, Number: 3, Title: "Who Is Alive?"}},
+			{FirstAired: now, Show: severance, Episode: &trakt.Episode{Season: 2, Number: 4, Title: "Woe's Hollow", IDs: trakt.EpisodeIDs{Trakt: 44}}},
+			{FirstAired: now.Add(72 * time.Hour), Show: andor, Episode: &trakt.Episode{Season: 2, Number: 1}},
+			{FirstAired: now.Add(-24 * time.Hour), Show: hidden, Episode: &trakt.Episode{Season: 1, Number: 1}},
+			{FirstAired: now.Add(30 * 24 * time.Hour), Show: andor, Episode: &trakt.Episode{Season: 2, Number: 9}},
+		},
+		hidden: []trakt.HiddenItem{{Type: "show", Show: hidden}},
+	}
+
+	result, err := makeGetUpcomingHandler(client)(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+	text := result.Content[0].Text
+
+	for _, want := range []string{
+		"**Out now, not watched yet**\n📺 **Severance** S02E04 - Woe's Hollow (aired today)",
+		"Show ID: 154997 · Slug: severance · Episode ID: 44",
+		"**Coming up**\n📺 **Andor** S02E01 (airs ",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in result, got:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"S02E03", "Hidden Show", "S02E09"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("expected %q to be left out, got:\n%s", unwanted, text)
+		}
+	}
+}
+
+func TestGetUpcoming_Nothing(t *testing.T) {
+	client := &suggestTrakt{fakeTrakt: fakeTrakt{authenticated: true}, calendar: []trakt.CalendarEntry{}}
+
+	result, err := makeGetUpcomingHandler(client)(context.Background(), json.RawMessage(`{"days":3}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || !strings.Contains(result.Content[0].Text, "last 3 days or air in the next 3") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// validationError describes the first value that fails schema validation.
+type validationError struct {
+	Pointer string // JSON Pointer (RFC 6901) to the offending value
+	Message string
+}
+
+func (e *validationError) Error() string {
+	if e.Pointer == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Pointer, e.Message)
+}
+
+// validateArgs checks tool arguments against the tool's InputSchema. Missing
+// arguments are treated as an empty object.
+func validateArgs(schema JSONSchema, args json.RawMessage) error {
+	args = bytes.TrimSpace(args)
+	if len(args) == 0 || bytes.Equal(args, []byte("null")) {
+		args = json.RawMessage(`{}`)
+	}
+
+	var v any
+	if err := json.Unmarshal(args, &v); err != nil {
+		return &validationError{Message: "arguments are not valid JSON"}
+	}
+	return validateValue(schema, v, "")
+}
+
+func validateValue(schema JSONSchema, v any, pointer string) error {
+	if schema.Type != "" && !matchesType(schema.Type, v) {
+		return &validationError{
+			Pointer: pointer,
+			Message: fmt.Sprintf("expected %s, got %s", schema.Type, jsonTypeName(v)),
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		str, ok := v.(string)
+		if !ok || !slices.Contains(schema.Enum, str) {
+			return &validationError{
+				Pointer: pointer,
+				Message: fmt.Sprintf("must be one of: %s", strings.Join(schema.Enum, ", ")),
+			}
+		}
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	for _, name := range schema.Required {
+		if _, present := obj[name]; !present {
+			return &validationError{
+				Pointer: pointer + "/" + escapePointer(name),
+				Message: "required field is missing",
+			}
+		}
+	}
+
+	// Check properties in a stable order so the reported error is deterministic
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		value, present := obj[name]
+		if !present {
+			continue
+		}
+		if err := validateValue(schema.Properties[name], value, pointer+"/"+escapePointer(name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func matchesType(schemaType string, v any) bool {
+	switch schemaType {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	default:
+		return true
+	}
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// escapePointer escapes a property name for use as a JSON Pointer token.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestValidateArgs(t *testing.T) {
+	schema := JSONSchema{
+		Type: "object",
+		Properties: map[string]JSONSchema{
+			"type":    {Type: "string", Enum: []string{"episode", "movie"}},
+			"season":  {Type: "number"},
+			"episode": {Type: "integer"},
+			"nested": {
+				Type: "object",
+				Properties: map[string]JSONSchema{
+					"a/b": {Type: "boolean"},
+				},
+			},
+		},
+		Required: []string{"type"},
+	}
+
+	tests := []struct {
+		name        string
+		args        string
+		wantPointer string
+		wantErr     bool
+	}{
+		{"valid", `{"type":"episode","season":1,"episode":2}`, "", false},
+		{"extra properties allowed", `{"type":"movie","other":true}`, "", false},
+		{"missing required", `{"season":1}`, "/type", true},
+		{"missing arguments", ``, "/type", true},
+		{"wrong type", `{"type":"episode","season":"one"}`, "/season", true},
+		{"not in enum", `{"type":"book"}`, "/type", true},
+		{"non-integer", `{"type":"episode","episode":1.5}`, "/episode", true},
+		{"nested with escaping", `{"type":"movie","nested":{"a/b":"yes"}}`, "/nested/a~1b", true},
+		{"not an object", `[]`, "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateArgs(schema, json.RawMessage(tc.args))
+			if !tc.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var ve *validationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("expected validationError, got %v", err)
+			}
+			if ve.Pointer != tc.wantPointer {
+				t.Errorf("Pointer = %q, want %q", ve.Pointer, tc.wantPointer)
+			}
+		})
+	}
+}
+
+func TestServer_ToolsCallValidatesArguments(t *testing.T) {
+	server := NewServer(nil)
+
+	called := false
+	server.RegisterTool(Tool{
+		Name: "typed",
+		InputSchema: JSONSchema{
+			Type:       "object",
+			Properties: map[string]JSONSchema{"limit": {Type: "number"}},
+		},
+	}, func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		called = true
+		return ToolCallResult{}, nil
+	})
+	if _, err := server.handleInitialize(json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+
+	_, rpcErr := server.handleToolsCall(context.Background(), json.RawMessage(`{"name":"typed","arguments":{"limit":"ten"}}`))
+	if rpcErr == nil || rpcErr.Code != InvalidParams {
+		t.Fatalf("expected InvalidParams error, got %+v", rpcErr)
+	}
+	if data, ok := rpcErr.Data.(map[string]string); !ok || data["pointer"] != "/limit" {
+		t.Errorf("expected pointer /limit in error data, got %+v", rpcErr.Data)
+	}
+	if called {
+		t.Error("handler should not run when validation fails")
+	}
+}
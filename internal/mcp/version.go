@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, injected at link time:
+//
+//	go build -ldflags "-X github.com/kofifort/trakt-mcp-go/internal/mcp.ServerVersion=1.2.0 \
+//	  -X github.com/kofifort/trakt-mcp-go/internal/mcp.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/kofifort/trakt-mcp-go/internal/mcp.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// When Commit or BuildDate are left empty they fall back to the VCS details
+// the go command embeds when building from a checkout.
+var (
+	ServerVersion = "0.1.0"
+	Commit        = ""
+	BuildDate     = ""
+)
+
+// BuildInfo identifies the running build.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a dirty checkout
+	GoVersion string `json:"goVersion"`
+}
+
+// CurrentBuild returns the metadata of the running binary.
+func CurrentBuild() BuildInfo {
+	b := BuildInfo{
+		Version:   ServerVersion,
+		Commit:    Commit,
+		Date:      BuildDate,
+		GoVersion: runtime.Version(),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if b.Commit == "" {
+					b.Commit = shortCommit(s.Value)
+				}
+			case "vcs.time":
+				if b.Date == "" {
+					b.Date = s.Value
+				}
+			case "vcs.modified":
+				b.Modified = s.Value == "true"
+			}
+		}
+	}
+	return b
+}
+
+// SemVer returns the version with the commit appended as semver build
+// metadata, e.g. "0.1.0+3549da2", as reported in serverInfo.
+func (b BuildInfo) SemVer() string {
+	if b.Commit == "" {
+		return b.Version
+	}
+	v := b.Version + "+" + b.Commit
+	if b.Modified {
+		v += ".dirty"
+	}
+	return v
+}
+
+func shortCommit(rev string) string {
+	if len(rev) > 7 {
+		return rev[:7]
+	}
+	return rev
+}
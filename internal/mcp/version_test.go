@@ -0,0 +1,36 @@
+package mcp
+
+import "testing"
+
+func TestBuildInfo_SemVer(t *testing.T) {
+	tests := []struct {
+		name string
+		info BuildInfo
+		want string
+	}{
+		{"no commit", BuildInfo{Version: "1.2.0"}, "1.2.0"},
+		{"commit", BuildInfo{Version: "1.2.0", Commit: "abc1234"}, "1.2.0+abc1234"},
+		{"dirty", BuildInfo{Version: "1.2.0", Commit: "abc1234", Modified: true}, "1.2.0+abc1234.dirty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.SemVer(); got != tt.want {
+				t.Errorf("SemVer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentBuild_PrefersInjectedValues(t *testing.T) {
+	oldCommit, oldDate := Commit, BuildDate
+	t.Cleanup(func() { Commit, BuildDate = oldCommit, oldDate })
+	Commit, BuildDate = "feedbee", "2024-01-02T03:04:05Z"
+
+	b := CurrentBuild()
+	if b.Version != ServerVersion || b.Commit != "feedbee" || b.Date != "2024-01-02T03:04:05Z" {
+		t.Errorf("unexpected build info %+v", b)
+	}
+	if b.GoVersion == "" {
+		t.Error("expected the Go version to be set")
+	}
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// releaseGrace allows a watch to predate an item's release by up to a day,
+// since release dates are per region and time zones shift them.
+const releaseGrace = 24 * time.Hour
+
+// localLayouts are the date formats without a zone that watchedAt accepts,
+// read in the local timezone.
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// agoPattern matches relative times such as "3 days ago" or "an hour ago".
+var agoPattern = regexp.MustCompile(`^(\d+|an?) (minute|hour|day|week)s? ago$`)
+
+var agoUnits = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+}
+
+// parseWatchedAt reads a log_watch watchedAt argument: RFC 3339, a local
+// date and time, or a phrase like "yesterday", "last night" or "2 hours
+// ago". It returns the zero time for an empty value, "now" or "today",
+// meaning Trakt should use the current time. Times in the future are
+// rejected. Relative times are truncated to the minute so a retried call
+// produces the same time.
+func parseWatchedAt(s string, now time.Time) (time.Time, error) {
+	t, err := parseWatchedAtValue(strings.TrimSpace(s), now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Allow a little clock skew between the client and the server
+	if t.After(now.Add(time.Minute)) {
+		return time.Time{}, fmt.Errorf("watchedAt %s is in the future; leave it out to log the watch as just now", t.Local().Format("2006-01-02 15:04"))
+	}
+	return t, nil
+}
+
+func parseWatchedAtValue(s string, now time.Time) (time.Time, error) {
+	phrase := strings.ToLower(s)
+	switch phrase {
+	case "", "now", "today", "just now":
+		return time.Time{}, nil
+	case "yesterday":
+		return now.Add(-24 * time.Hour).Truncate(time.Minute), nil
+	case "last night":
+		y, m, d := now.Local().AddDate(0, 0, -1).Date()
+		return time.Date(y, m, d, 21, 0, 0, 0, time.Local), nil
+	}
+	if m := agoPattern.FindStringSubmatch(phrase); m != nil {
+		n := 1
+		if m[1] != "a" && m[1] != "an" {
+			n, _ = strconv.Atoi(m[1])
+		}
+		return now.Add(-time.Duration(n) * agoUnits[m[2]]).Truncate(time.Minute), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("watchedAt %q isn't a date; use ISO 8601 like 2024-03-10T20:00:00Z, a date like 2024-03-10, or a phrase like \"yesterday\" or \"2 hours ago\"", s)
+}
+
+// formatWatchedAt renders a parsed watchedAt for Trakt in UTC, or "" for
+// the zero time so Trakt uses the current time.
+func formatWatchedAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// checkReleased reports an error if a watch at watchedAt predates release,
+// the item's release or first air time. Either being unknown passes.
+func checkReleased(watchedAt time.Time, release *time.Time, what string) error {
+	if watchedAt.IsZero() || release == nil || release.IsZero() {
+		return nil
+	}
+	if watchedAt.Before(release.Add(-releaseGrace)) {
+		return fmt.Errorf("watchedAt %s is before %s came out on %s; check the date",
+			watchedAt.Local().Format("2006-01-02"), what, release.Local().Format("2006-01-02"))
+	}
+	return nil
+}
+
+// movieRelease parses a movie's release date, or returns nil if it's
+// missing or malformed.
+func movieRelease(m *trakt.Movie) *time.Time {
+	t, err := time.Parse("2006-01-02", m.Released)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestParseWatchedAt(t *testing.T) {
+	now := time.Date(2024, 3, 10, 14, 30, 45, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"now", time.Time{}},
+		{"Today", time.Time{}},
+		{"2024-03-09T20:00:00Z", time.Date(2024, 3, 9, 20, 0, 0, 0, time.UTC)},
+		{"2024-03-09T20:00:00+01:00", time.Date(2024, 3, 9, 19, 0, 0, 0, time.UTC)},
+		{"2024-03-09T20:00", time.Date(2024, 3, 9, 20, 0, 0, 0, time.Local)},
+		{"2024-03-09", time.Date(2024, 3, 9, 0, 0, 0, 0, time.Local)},
+		{"yesterday", now.Add(-24 * time.Hour).Truncate(time.Minute)},
+		{"last night", time.Date(2024, 3, 9, 21, 0, 0, 0, time.Local)},
+		{"3 hours ago", now.Add(-3 * time.Hour).Truncate(time.Minute)},
+		{"an hour ago", now.Add(-time.Hour).Truncate(time.Minute)},
+		{"2 weeks ago", now.Add(-14 * 24 * time.Hour).Truncate(time.Minute)},
+	}
+	for _, tc := range tests {
+		got, err := parseWatchedAt(tc.in, now)
+		if err != nil {
+			t.Errorf("parseWatchedAt(%q) failed: %v", tc.in, err)
+			continue
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("parseWatchedAt(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"last tuesday-ish", "2024-13-40", "2024-03-11T09:00:00Z"} {
+		if _, err := parseWatchedAt(bad, now); err == nil {
+			t.Errorf("parseWatchedAt(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFormatWatchedAt(t *testing.T) {
+	if got := formatWatchedAt(time.Time{}); got != "" {
+		t.Errorf("formatWatchedAt(zero) = %q, want empty", got)
+	}
+	at := time.Date(2024, 3, 9, 20, 0, 0, 0, time.FixedZone("CET", 3600))
+	if got := formatWatchedAt(at); got != "2024-03-09T19:00:00Z" {
+		t.Errorf("formatWatchedAt() = %q", got)
+	}
+}
+
+func TestCheckReleased(t *testing.T) {
+	release := time.Date(2010, 7, 16, 0, 0, 0, 0, time.UTC)
+	if err := checkReleased(release.AddDate(0, 0, -3), &release, "Inception"); err == nil {
+		t.Error("expected an error for a watch before the release")
+	}
+	if err := checkReleased(release.Add(-time.Hour), &release, "Inception"); err != nil {
+		t.Errorf("expected a watch within the grace period to pass, got %v", err)
+	}
+	if err := checkReleased(time.Time{}, &release, "Inception"); err != nil {
+		t.Errorf("expected a watch now to pass, got %v", err)
+	}
+	if err := checkReleased(release.AddDate(-1, 0, 0), nil, "Inception"); err != nil {
+		t.Errorf("expected an unknown release to pass, got %v", err)
+	}
+}
+
+func TestLogWatchHandler_WatchedAt(t *testing.T) {
+	var posted string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/search"):
+			_ = json.NewEncoder(w).Encode([]trakt.SearchResult{{
+				Type:  "movie",
+				Score: 1000,
+				Movie: &trakt.Movie{Title: "Inception", Year: 2010, Released: "2010-07-16", IDs: trakt.MovieIDs{Trakt: 16662}},
+			}})
+		case r.URL.Path == "/sync/history":
+			var item trakt.WatchedItem
+			_ = json.NewDecoder(r.Body).Decode(&item)
+			posted = item.WatchedAt
+			var resp trakt.SyncResponse
+			resp.Added.Movies = 1
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+	_, client := newMockTraktServer(t, handler)
+	logHandler := makeLogWatchHandler(client, func(context.Context, string, string, []trakt.SearchResult) *trakt.SearchResult { return nil }, newWriteGuard())
+
+	result, err := logHandler(context.Background(), json.RawMessage(`{"type":"movie","movieName":"Inception","watchedAt":"2024-03-09T20:00:00+01:00"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || posted != "2024-03-09T19:00:00Z" {
+		t.Errorf("expected the time posted in UTC, got %q: %s", posted, result.Content[0].Text)
+	}
+
+	tests := map[string]string{
+		"future":         time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339),
+		"before release": "2009-01-01",
+		"not a date":     "the other day",
+	}
+	for name, watchedAt := range tests {
+		posted = ""
+		result, err := logHandler(context.Background(), json.RawMessage(`{"type":"movie","movieName":"Inception","watchedAt":"`+watchedAt+`"}`))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !result.IsError || posted != "" {
+			t.Errorf("%s: expected a rejection without a write, got: %s", name, result.Content[0].Text)
+		}
+	}
+}
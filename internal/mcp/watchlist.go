@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// formatWatchlistItem describes a show or movie on the watchlist, or
+// returns "" for listed seasons and episodes, which aren't shown.
+func formatWatchlistItem(item trakt.WatchlistItem) string {
+	switch {
+	case item.Type == "show" && item.Show != nil:
+		return fmt.Sprintf("📺 %s (%d) - Trakt ID: %d\n", item.Show.Title, item.Show.Year, item.Show.IDs.Trakt) +
+			idLine(slugPart(item.Show.IDs.Slug), item.Show.URL())
+	case item.Type == "movie" && item.Movie != nil:
+		return fmt.Sprintf("🎬 %s (%d) - Trakt ID: %d\n", item.Movie.Title, item.Movie.Year, item.Movie.IDs.Trakt) +
+			idLine(slugPart(item.Movie.IDs.Slug), item.Movie.URL())
+	}
+	return ""
+}
+
+func makeGetWatchlistHandler(s *Server, client TraktAPI) ToolHandler {
+	type watchlistArgs struct {
+		Type   string `json:"type"`
+		Format string `json:"format"`
+		Cursor string `json:"cursor,omitempty"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a watchlistArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+
+		// A cursor resumes an earlier call that didn't fit in one response
+		offset := 0
+		if cursor := a.Cursor; cursor != "" {
+			a = watchlistArgs{}
+			var err error
+			if offset, err = decodeCursor("get_watchlist", cursor, &a); err != nil {
+				return ToolCallResult{
+					Content: []Content{TextContent(msg(ctx, msgError, err))},
+					IsError: true,
+				}, nil
+			}
+		}
+
+		watchlist, err := client.GetWatchlist(ctx, a.Type)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+
+		if a.Format == formatJSON {
+			return jsonResult(orEmpty(watchlist)), nil
+		}
+
+		var items []string
+		for _, item := range watchlist {
+			if text := formatWatchlistItem(item); text != "" {
+				items = append(items, text)
+			}
+		}
+		items = items[min(offset, len(items)):]
+		if len(items) == 0 {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgWatchlistEmpty))},
+			}, nil
+		}
+
+		// Cut a result too long for the response budget at an item boundary
+		n := fitBudget(items, s.ResponseBudget())
+		text := strings.Join(items[:n], "")
+		if n < len(items) {
+			text += "\n" + msg(ctx, msgWatchlistContinues, offset+1, offset+n, offset+len(items), encodeCursor("get_watchlist", a, offset+n)) + "\n"
+		}
+		return ToolCallResult{
+			Content: []Content{TextContent(text)},
+		}, nil
+	}
+}
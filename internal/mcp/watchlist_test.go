@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// watchlistTrakt serves a fixed watchlist.
+type watchlistTrakt struct {
+	TraktAPI
+	items   []trakt.WatchlistItem
+	gotType string
+}
+
+func (f *watchlistTrakt) IsAuthenticated() bool { return true }
+
+func (f *watchlistTrakt) GetWatchlist(ctx context.Context, watchlistType string, opts ...trakt.RequestOption) ([]trakt.WatchlistItem, error) {
+	f.gotType = watchlistType
+	return f.items, nil
+}
+
+func TestGetWatchlistHandler_CursorContinuesTruncatedResult(t *testing.T) {
+	client := &watchlistTrakt{}
+	for i := 1; i <= 5; i++ {
+		client.items = append(client.items, trakt.WatchlistItem{
+			Type:  "movie",
+			Movie: &trakt.Movie{Title: fmt.Sprintf("Movie %d", i), Year: 2020, IDs: trakt.MovieIDs{Trakt: i}},
+		})
+	}
+	server := NewServer(nil)
+	server.SetResponseBudget(2*len(formatWatchlistItem(client.items[0])) + 1)
+	handler := makeGetWatchlistHandler(server, client)
+
+	result, err := handler(context.Background(), json.RawMessage(`{"type":"movies"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].Text
+	if !strings.Contains(text, "Movie 2") || strings.Contains(text, "Movie 3") {
+		t.Fatalf("expected the first two items only, got: %s", text)
+	}
+	match := regexp.MustCompile(`cursor "([^"]+)"`).FindStringSubmatch(text)
+	if match == nil {
+		t.Fatalf("expected a continuation cursor, got: %s", text)
+	}
+
+	client.gotType = ""
+	result, err = handler(context.Background(), json.RawMessage(`{"cursor":"`+match[1]+`"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text = result.Content[0].Text
+	if strings.Contains(text, "Movie 2") || !strings.Contains(text, "Movie 3") || !strings.Contains(text, "Movie 4") {
+		t.Errorf("expected the continuation to start at the third item, got: %s", text)
+	}
+	if client.gotType != "movies" {
+		t.Errorf("expected the cursor to restore the type, got %q", client.gotType)
+	}
+
+	result, err = handler(context.Background(), json.RawMessage(`{"cursor":"bogus"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected an error for an invalid cursor, got: %s", result.Content[0].Text)
+	}
+}
+
+func TestGetWatchlistHandler_Empty(t *testing.T) {
+	handler := makeGetWatchlistHandler(NewServer(nil), &watchlistTrakt{})
+
+	result, err := handler(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || result.Content[0].Text != catalogs[DefaultLanguage][msgWatchlistEmpty] {
+		t.Errorf("expected the empty watchlist message, got %+v", result)
+	}
+}
@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Periods get_watch_time reports on, each the calendar period to date.
+const (
+	periodWeek  = "week"
+	periodMonth = "month"
+	periodYear  = "year"
+)
+
+// defaultWatchTimeShows is how many shows the per-show breakdown lists.
+const defaultWatchTimeShows = 10
+
+// periodStart returns when the calendar period containing now began, in
+// local time. Weeks start on Monday.
+func periodStart(period string, now time.Time) time.Time {
+	y, m, d := now.Local().Date()
+	switch period {
+	case periodYear:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, time.Local)
+	case periodMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.Local)
+	default:
+		daysSinceMonday := (int(now.Local().Weekday()) + 6) % 7
+		return time.Date(y, m, d-daysSinceMonday, 0, 0, 0, 0, time.Local)
+	}
+}
+
+// showTime is the watch time of one show in a period.
+type showTime struct {
+	show     *trakt.Show
+	episodes int
+	minutes  int
+}
+
+// watchTime totals the runtime of a period's history.
+type watchTime struct {
+	episodes, movies          int
+	showMinutes, movieMinutes int
+	unknown                   int // plays without a known runtime
+	shows                     []*showTime
+}
+
+func sumWatchTime(history []trakt.HistoryItem) watchTime {
+	var wt watchTime
+	byShow := make(map[int]*showTime)
+	for _, h := range history {
+		minutes := playRuntime(h)
+		if minutes == 0 {
+			wt.unknown++
+		}
+		switch {
+		case h.Episode != nil && h.Show != nil:
+			wt.episodes++
+			wt.showMinutes += minutes
+			st, ok := byShow[h.Show.IDs.Trakt]
+			if !ok {
+				st = &showTime{show: h.Show}
+				byShow[h.Show.IDs.Trakt] = st
+				wt.shows = append(wt.shows, st)
+			}
+			st.episodes++
+			st.minutes += minutes
+		case h.Movie != nil:
+			wt.movies++
+			wt.movieMinutes += minutes
+		}
+	}
+	sort.SliceStable(wt.shows, func(i, j int) bool { return wt.shows[i].minutes > wt.shows[j].minutes })
+	return wt
+}
+
+func makeGetWatchTimeHandler(client TraktAPI) ToolHandler {
+	type watchTimeArgs struct {
+		Period string `json:"period"`
+		Limit  int    `json:"limit"`
+	}
+
+	return func(ctx context.Context, args json.RawMessage) (ToolCallResult, error) {
+		if !client.IsAuthenticated() {
+			return ToolCallResult{
+				Content: []Content{TextContent(msg(ctx, msgNotAuthenticated))},
+				IsError: true,
+			}, nil
+		}
+
+		var a watchTimeArgs
+		if err := json.Unmarshal(args, &a); err != nil {
+			return ErrorContent(ctx, fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+		switch a.Period {
+		case "":
+			a.Period = periodWeek
+		case periodWeek, periodMonth, periodYear:
+		default:
+			return ToolCallResult{
+				Content: []Content{TextContent(fmt.Sprintf("Error: period must be %q, %q or %q", periodWeek, periodMonth, periodYear))},
+				IsError: true,
+			}, nil
+		}
+		if a.Limit <= 0 {
+			a.Limit = defaultWatchTimeShows
+		}
+
+		now := time.Now()
+		start := periodStart(a.Period, now)
+		history, err := historyBetween(ctx, client, "", start, now)
+		if err != nil {
+			return ErrorContent(ctx, err), nil
+		}
+		if len(history) == 0 {
+			return ToolCallResult{Content: []Content{TextContent(msg(ctx, msgNothingWatched))}}, nil
+		}
+
+		return ToolCallResult{
+			Content: []Content{TextContent(formatWatchTime(sumWatchTime(history), a.Period, start, now, a.Limit))},
+		}, nil
+	}
+}
+
+func formatWatchTime(wt watchTime, period string, start, now time.Time, limit int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⏱️ **Watch time this %s** (%s)\n\n", period, shortDateRange(start, now)))
+
+	total := wt.showMinutes + wt.movieMinutes
+	sb.WriteString(fmt.Sprintf("Total: %s over %s plays\n", formatMinutes(total), formatCount(wt.episodes+wt.movies)))
+	sb.WriteString(fmt.Sprintf("Shows: %s (%s episodes) · Movies: %s (%s)\n",
+		formatMinutes(wt.showMinutes), formatCount(wt.episodes), formatMinutes(wt.movieMinutes), formatCount(wt.movies)))
+	days := int(now.Sub(start).Hours()/24) + 1
+	sb.WriteString(fmt.Sprintf("Daily average: %s\n", formatMinutes(total/days)))
+	if wt.unknown > 0 {
+		sb.WriteString(fmt.Sprintf("(%s plays have no runtime on Trakt and aren't counted)\n", formatCount(wt.unknown)))
+	}
+
+	if len(wt.shows) > 0 {
+		sb.WriteString("\n**By show**\n")
+	}
+	for i, st := range wt.shows {
+		if i >= limit {
+			sb.WriteString(fmt.Sprintf("... and %d more\n", len(wt.shows)-limit))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("📺 **%s** - %s (%d episodes)\n", st.show.Title, formatMinutes(st.minutes), st.episodes))
+		sb.WriteString(idLine(idPart("Show ID", int64(st.show.IDs.Trakt)), slugPart(st.show.IDs.Slug), st.show.URL()))
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+func TestPeriodStart(t *testing.T) {
+	now := time.Date(2026, 10, 16, 21, 30, 0, 0, time.Local) // a Friday
+	tests := map[string]time.Time{
+		periodWeek:  time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local),
+		periodMonth: time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local),
+		periodYear:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local),
+	}
+	for period, want := range tests {
+		if got := periodStart(period, now); !got.Equal(want) {
+			t.Errorf("periodStart(%q) = %v, want %v", period, got, want)
+		}
+	}
+
+	// A Sunday still belongs to the week that began on Monday
+	sunday := time.Date(2026, 10, 18, 9, 0, 0, 0, time.Local)
+	if got := periodStart(periodWeek, sunday); !got.Equal(tests[periodWeek]) {
+		t.Errorf("periodStart on Sunday = %v, want %v", got, tests[periodWeek])
+	}
+}
+
+func TestGetWatchTimeHandler(t *testing.T) {
+	now := time.Now()
+	severance := &trakt.Show{Title: "Severance", Runtime: 50, IDs: trakt.ShowIDs{Trakt: 154997, Slug: "severance"}}
+	bear := &trakt.Show{Title: "The Bear", Runtime: 30, IDs: trakt.ShowIDs{Trakt: 191840}}
+	episode := func(show *trakt.Show, runtime int) trakt.HistoryItem {
+		return trakt.HistoryItem{Type: "episode", WatchedAt: now, Show: show, Episode: &trakt.Episode{Runtime: runtime}}
+	}
+
+	var query string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]trakt.HistoryItem{
+			episode(severance, 0),
+			episode(severance, 57),
+			episode(bear, 0),
+			{Type: "movie", WatchedAt: now, Movie: &trakt.Movie{Title: "Dune", Runtime: 155}},
+			{Type: "movie", WatchedAt: now, Movie: &trakt.Movie{Title: "Unknown"}},
+		})
+	})
+
+	_, client := newMockTraktServer(t, handler)
+	result, err := makeGetWatchTimeHandler(client)(context.Background(), json.RawMessage(`{"period":"month"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %+v", result)
+	}
+	text := result.Content[0].Text
+
+	if !strings.Contains(query, "start_at=") || !strings.Contains(query, "extended=full") {
+		t.Errorf("expected the period and runtimes to be requested, got %q", query)
+	}
+	for _, want := range []string{
+		"**Watch time this month**",
+		"Total: 4h 52m over 5 plays",
+		"Shows: 2h 17m (3 episodes) · Movies: 2h 35m (2)",
+		"(1 plays have no runtime on Trakt and aren't counted)",
+		"📺 **Severance** - 1h 47m (2 episodes)",
+		"📺 **The Bear** - 30m (1 episodes)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q, got:\n%s", want, text)
+		}
+	}
+
+	result, _ = makeGetWatchTimeHandler(client)(context.Background(), json.RawMessage(`{"period":"decade"}`))
+	if !result.IsError {
+		t.Errorf("expected an invalid period to fail, got %+v", result)
+	}
+}
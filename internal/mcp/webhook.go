@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+	"github.com/kofifort/trakt-mcp-go/internal/webhook"
+)
+
+// notifyingTrakt posts each change Trakt accepts to a webhook.
+type notifyingTrakt struct {
+	TraktAPI
+	hook *webhook.Sender
+	now  func() time.Time
+}
+
+// Notifying returns client with every write that succeeds, such as a
+// watch logged or a rating added, posted to hook. Writes that fail, or
+// that a Queued client holds back while Trakt is unreachable, aren't; a
+// queued write is posted when it's replayed through a Notifying client.
+func Notifying(client TraktAPI, hook *webhook.Sender) TraktAPI {
+	return &notifyingTrakt{TraktAPI: client, hook: hook, now: time.Now}
+}
+
+// send posts an event for a write unless it failed.
+func (c *notifyingTrakt) send(event string, item, result any, err error) {
+	if err != nil {
+		return
+	}
+	c.hook.Send(webhook.Event{Event: event, Time: c.now().UTC(), Item: item, Result: result})
+}
+
+func (c *notifyingTrakt) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	resp, err := c.TraktAPI.AddToHistory(ctx, item)
+	c.send(webhook.HistoryAdded, item, resp, err)
+	return resp, err
+}
+
+func (c *notifyingTrakt) RemoveFromHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	resp, err := c.TraktAPI.RemoveFromHistory(ctx, item)
+	c.send(webhook.HistoryRemoved, item, resp, err)
+	return resp, err
+}
+
+// ScrobbleStop is posted only when Trakt recorded the stop as a watch.
+func (c *notifyingTrakt) ScrobbleStop(ctx context.Context, s trakt.Scrobble) (*trakt.ScrobbleResponse, error) {
+	resp, err := c.TraktAPI.ScrobbleStop(ctx, s)
+	if err == nil && resp.Action == "scrobble" {
+		c.send(webhook.HistoryScrobbled, s, resp, nil)
+	}
+	return resp, err
+}
+
+func (c *notifyingTrakt) AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error) {
+	resp, err := c.TraktAPI.AddRatings(ctx, item)
+	c.send(webhook.RatingsAdded, item, resp, err)
+	return resp, err
+}
+
+func (c *notifyingTrakt) AddToWatchlist(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error) {
+	resp, err := c.TraktAPI.AddToWatchlist(ctx, items)
+	c.send(webhook.WatchlistAdded, items, resp, err)
+	return resp, err
+}
+
+func (c *notifyingTrakt) AddToCollection(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error) {
+	resp, err := c.TraktAPI.AddToCollection(ctx, items)
+	c.send(webhook.CollectionAdded, items, resp, err)
+	return resp, err
+}
@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+	"github.com/kofifort/trakt-mcp-go/internal/webhook"
+)
+
+// writeTrakt accepts ratings and scrobbles, and fails history writes.
+type writeTrakt struct {
+	fakeTrakt
+}
+
+func (f *writeTrakt) AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error) {
+	return &trakt.SyncResponse{Added: trakt.SyncStats{Movies: len(item.Movies)}}, nil
+}
+
+func (f *writeTrakt) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	return nil, errors.New("trakt is down")
+}
+
+func (f *writeTrakt) ScrobbleStop(ctx context.Context, s trakt.Scrobble) (*trakt.ScrobbleResponse, error) {
+	action := "pause"
+	if s.Progress >= 80 {
+		action = "scrobble"
+	}
+	return &trakt.ScrobbleResponse{Action: action, Progress: s.Progress}, nil
+}
+
+func TestNotifying(t *testing.T) {
+	var mu sync.Mutex
+	var events []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&e)
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	hook := webhook.New(srv.URL, "", nil)
+	client := Notifying(&writeTrakt{}, hook)
+	ctx := context.Background()
+	dune := trakt.MovieIDs{Trakt: 2}
+
+	if _, err := client.AddRatings(ctx, trakt.RatingItem{Movies: []trakt.RatedMovie{{Rating: 9, IDs: dune}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AddToHistory(ctx, trakt.WatchedItem{Movies: []trakt.Movie{{IDs: dune}}}); err == nil {
+		t.Fatal("expected the history write to fail")
+	}
+	movie := &trakt.Movie{IDs: dune}
+	_, _ = client.ScrobbleStop(ctx, trakt.Scrobble{Movie: movie, Progress: 40})
+	_, _ = client.ScrobbleStop(ctx, trakt.Scrobble{Movie: movie, Progress: 95})
+	hook.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 {
+		t.Fatalf("expected the rating and the finished scrobble posted, got %+v", events)
+	}
+	kinds := map[string]map[string]any{}
+	for _, e := range events {
+		kinds[e["event"].(string)] = e
+	}
+	rated, ok := kinds[webhook.RatingsAdded]
+	if !ok {
+		t.Fatalf("expected a ratings event, got %+v", events)
+	}
+	result := rated["result"].(map[string]any)["added"].(map[string]any)
+	if result["movies"] != float64(1) {
+		t.Errorf("expected Trakt's response in the event, got %+v", rated)
+	}
+	if _, ok := kinds[webhook.HistoryScrobbled]; !ok {
+		t.Errorf("expected a scrobble event, got %+v", events)
+	}
+}
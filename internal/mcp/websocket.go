@@ -0,0 +1,309 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is the fixed value mixed into the handshake key (RFC 6455 §1.3).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket frame opcodes.
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+var errWebSocketProtocol = errors.New("websocket protocol error")
+
+// WebSocketHandler serves the JSON-RPC stream over a WebSocket (experimental).
+// Each text message carries one JSON-RPC message in either direction.
+//
+// Like the SSE transport, only one connection may be open at a time because
+// the Server holds a single session's handshake state.
+type WebSocketHandler struct {
+	server *Server
+
+	mu     sync.Mutex
+	active bool
+}
+
+// NewWebSocketHandler creates an HTTP handler that upgrades to WebSocket.
+func NewWebSocketHandler(s *Server) *WebSocketHandler {
+	return &WebSocketHandler{server: s}
+}
+
+// ServeHTTP performs the WebSocket handshake and runs a session on the connection.
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet ||
+		!strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!headerContainsToken(r.Header.Get("Connection"), "upgrade") {
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusBadRequest)
+		return
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	h.mu.Lock()
+	if h.active {
+		h.mu.Unlock()
+		http.Error(w, "a session is already active", http.StatusConflict)
+		return
+	}
+	h.active = true
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		h.active = false
+		h.mu.Unlock()
+	}()
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		h.server.logger.Error("websocket hijack failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	accept := sha1.Sum([]byte(key + websocketGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(accept[:]))
+	if err := rw.Flush(); err != nil {
+		return
+	}
+
+	// Hijacked connections aren't tracked by the HTTP server, so close the
+	// connection ourselves when the session or server shuts down
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	ws := &wsConn{conn: conn, br: rw.Reader}
+
+	h.server.logger.Info("websocket session started", "remote", conn.RemoteAddr().String())
+	if err := h.server.serve(ctx, ws, ws, FramingLine); err != nil && !errors.Is(err, context.Canceled) {
+		h.server.logger.Error("websocket session error", "error", err)
+	}
+	h.server.logger.Info("websocket session ended", "remote", conn.RemoteAddr().String())
+}
+
+// wsConn adapts a WebSocket connection to the line-delimited reader and
+// writer expected by RunWithIO.
+type wsConn struct {
+	conn    net.Conn
+	br      *bufio.Reader
+	pending bytes.Buffer // decoded messages not yet consumed by Read
+
+	writeMu sync.Mutex
+}
+
+// Read returns incoming messages, each terminated by a newline. A close
+// frame or protocol error ends the stream.
+func (c *wsConn) Read(p []byte) (int, error) {
+	for c.pending.Len() == 0 {
+		msg, err := c.readMessage()
+		if err != nil {
+			return 0, err
+		}
+		// The dispatch loop is line-delimited, so collapse any pretty-printing.
+		// Invalid JSON is passed through on one line to produce a parse error.
+		if err := json.Compact(&c.pending, msg); err != nil {
+			c.pending.Write(bytes.ReplaceAll(msg, []byte("\n"), []byte(" ")))
+		}
+		c.pending.WriteByte('\n')
+	}
+	return c.pending.Read(p)
+}
+
+// Write sends one newline-terminated message from the dispatch loop as a text frame.
+func (c *wsConn) Write(p []byte) (int, error) {
+	if err := c.writeFrame(wsOpText, bytes.TrimRight(p, "\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// readMessage reads frames until a complete data message is assembled,
+// answering control frames along the way.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			_ = c.writeFrame(wsOpClose, payload)
+			return nil, io.EOF
+		case wsOpText, wsOpBinary, wsOpContinuation:
+		default:
+			return nil, errWebSocketProtocol
+		}
+
+		if len(msg)+len(payload) > maxMessageSize {
+			return nil, fmt.Errorf("%w: message too large", errWebSocketProtocol)
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	// Clients must mask every frame (RFC 6455 §5.1)
+	if !masked {
+		return false, 0, nil, fmt.Errorf("%w: unmasked client frame", errWebSocketProtocol)
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxMessageSize {
+		return false, 0, nil, fmt.Errorf("%w: frame too large", errWebSocketProtocol)
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// writeFrame sends a single unfragmented, unmasked frame.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// headerContainsToken reports whether a comma-separated header contains token.
+func headerContainsToken(header, token string) bool {
+	for _, part := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunWebSocket serves the WebSocket transport at /ws, and the routes added
+// with HandleHTTP, on addr until ctx is cancelled.
+func (s *Server) RunWebSocket(ctx context.Context, addr string) error {
+	mux := s.httpMux()
+	mux.Handle("/ws", NewWebSocketHandler(s))
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("websocket transport listening", "addr", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("websocket server: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
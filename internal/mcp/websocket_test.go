@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// dialWebSocket performs a client handshake against a test server.
+func dialWebSocket(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	req := "GET /ws HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(req)); err != nil {
+		t.Fatalf("handshake write failed: %v", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("handshake read failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+	// Sample key and accept value from RFC 6455 §1.3
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept = %q", got)
+	}
+	return conn, br
+}
+
+// writeClientFrame sends a masked frame, as clients are required to.
+func writeClientFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte) {
+	t.Helper()
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode}
+	if len(payload) < 126 {
+		frame = append(frame, 0x80|byte(len(payload)))
+	} else {
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("frame write failed: %v", err)
+	}
+}
+
+// readServerFrame reads an unmasked frame from the server.
+func readServerFrame(t *testing.T, br *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(br, header); err != nil {
+		t.Fatalf("frame read failed: %v", err)
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(br, ext); err != nil {
+			t.Fatalf("frame read failed: %v", err)
+		}
+		length = int(binary.BigEndian.Uint16(ext))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		t.Fatalf("frame read failed: %v", err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+func TestWebSocketHandler_RoundTrip(t *testing.T) {
+	server := NewServer(nil)
+	mux := http.NewServeMux()
+	mux.Handle("/ws", NewWebSocketHandler(server))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	conn, br := dialWebSocket(t, ts.URL)
+
+	writeClientFrame(t, conn, wsOpPing, []byte("hi"))
+	if opcode, payload := readServerFrame(t, br); opcode != wsOpPong || string(payload) != "hi" {
+		t.Fatalf("expected pong, got opcode %d payload %q", opcode, payload)
+	}
+
+	initReq := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	writeClientFrame(t, conn, wsOpText, []byte(initReq))
+
+	opcode, payload := readServerFrame(t, br)
+	if opcode != wsOpText {
+		t.Fatalf("opcode = %d, want text", opcode)
+	}
+	if !strings.Contains(string(payload), `"id":1`) || !strings.Contains(string(payload), ServerName) {
+		t.Errorf("unexpected initialize response: %s", payload)
+	}
+
+	writeClientFrame(t, conn, wsOpClose, nil)
+	if opcode, _ := readServerFrame(t, br); opcode != wsOpClose {
+		t.Errorf("expected close frame, got opcode %d", opcode)
+	}
+}
+
+func TestWebSocketHandler_RequiresUpgrade(t *testing.T) {
+	ts := httptest.NewServer(NewWebSocketHandler(NewServer(nil)))
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUpgradeRequired)
+	}
+}
@@ -0,0 +1,469 @@
+// Package mirror keeps a local copy of the user's Trakt history, ratings,
+// watchlist and collection, so tools that read them in bulk answer from disk
+// instead of paging through the API.
+//
+// The mirror is refreshed incrementally: Trakt's last activities say which
+// parts of the account changed since the previous sync, and only those are
+// fetched again. New plays are fetched from the newest one the mirror holds,
+// and the whole history is only fetched again if the play count then
+// disagrees with Trakt's, after a removal. The copy is one JSON file, kept
+// in a directory of the user's choosing.
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// fileName is the mirror's file in its directory.
+const fileName = "mirror.json"
+
+// formatVersion is bumped when the file layout changes; a file of another
+// version is discarded and the mirror rebuilt.
+const formatVersion = 1
+
+// CheckInterval is how long Refresh trusts the mirror before checking
+// Trakt's last activities again.
+const CheckInterval = time.Minute
+
+// Source is the Trakt API the mirror syncs from, as trakt.Client does.
+type Source interface {
+	GetLastActivities(ctx context.Context) (*trakt.LastActivities, error)
+	GetHistoryPage(ctx context.Context, historyType string, page, limit int) (*trakt.HistoryPage, error)
+	ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error
+	GetUserRatings(ctx context.Context, user, ratingType string) ([]trakt.Rating, error)
+	GetWatchlist(ctx context.Context, watchlistType string, opts ...trakt.RequestOption) ([]trakt.WatchlistItem, error)
+	ForEachCollected(ctx context.Context, collectionType string, fn func(trakt.CollectionEntry) error) error
+}
+
+// data is the mirrored account.
+type data struct {
+	SyncedAt   time.Time
+	Activities trakt.LastActivities
+	History    []trakt.HistoryItem // newest first
+	Ratings    []trakt.Rating
+	Watchlist  []trakt.WatchlistItem
+	Collection []trakt.CollectionEntry
+}
+
+// Stats describes what the mirror holds.
+type Stats struct {
+	SyncedAt   time.Time // zero before the first sync
+	Plays      int
+	Ratings    int
+	Watchlist  int
+	Collection int
+}
+
+// Mirror is a local copy of a Trakt account. It is safe for concurrent use.
+type Mirror struct {
+	path   string
+	src    Source
+	logger *slog.Logger
+	now    func() time.Time
+
+	syncMu sync.Mutex // held for the length of a sync
+
+	mu      sync.RWMutex
+	data    data
+	checked time.Time // when last activities were last checked
+}
+
+// Open opens the mirror in dir, creating dir if needed and loading the copy
+// saved there by an earlier run. A copy that can't be read is discarded and
+// rebuilt on the next sync.
+func Open(dir string, src Source, logger *slog.Logger) (*Mirror, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create mirror directory: %w", err)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	m := &Mirror{path: filepath.Join(dir, fileName), src: src, logger: logger, now: time.Now}
+
+	d, err := load(m.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		logger.Warn("discarding unreadable mirror", "path", m.path, "error", err)
+	default:
+		m.data = d
+	}
+	return m, nil
+}
+
+// Stats reports what the mirror holds.
+func (m *Mirror) Stats() Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return Stats{
+		SyncedAt:   m.data.SyncedAt,
+		Plays:      len(m.data.History),
+		Ratings:    len(m.data.Ratings),
+		Watchlist:  len(m.data.Watchlist),
+		Collection: len(m.data.Collection),
+	}
+}
+
+// Synced reports whether the mirror holds a copy to read from.
+func (m *Mirror) Synced() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return !m.data.SyncedAt.IsZero()
+}
+
+// Invalidate makes the next Refresh check Trakt for changes, e.g. after a
+// write through the API.
+func (m *Mirror) Invalidate() {
+	m.mu.Lock()
+	m.checked = time.Time{}
+	m.mu.Unlock()
+}
+
+// Refresh syncs the mirror unless it was checked within CheckInterval.
+// Reads that arrive together share one check. A failed sync is logged and
+// leaves the last copy in place.
+func (m *Mirror) Refresh(ctx context.Context) error {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+
+	m.mu.RLock()
+	fresh := !m.checked.IsZero() && m.now().Sub(m.checked) < CheckInterval
+	m.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+	err := m.sync(ctx)
+	if err != nil {
+		m.logger.Warn("mirror sync failed", "error", err)
+	}
+	return err
+}
+
+// Sync brings the mirror up to date with Trakt, fetching only the parts of
+// the account that changed since the last sync, and saves it.
+func (m *Mirror) Sync(ctx context.Context) error {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+	return m.sync(ctx)
+}
+
+func (m *Mirror) sync(ctx context.Context) error {
+	activities, err := m.src.GetLastActivities(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	d := m.data
+	m.mu.RUnlock()
+	first := d.SyncedAt.IsZero()
+	old := d.Activities
+
+	changed := false
+	if first || !activities.Movies.WatchedAt.Equal(old.Movies.WatchedAt) || !activities.Episodes.WatchedAt.Equal(old.Episodes.WatchedAt) {
+		if d.History, err = m.syncHistory(ctx, d.History); err != nil {
+			return fmt.Errorf("sync history: %w", err)
+		}
+		changed = true
+	}
+	if first || ratedChanged(*activities, old) {
+		if d.Ratings, err = m.src.GetUserRatings(ctx, "me", ""); err != nil {
+			return fmt.Errorf("sync ratings: %w", err)
+		}
+		changed = true
+	}
+	if first || watchlistChanged(*activities, old) {
+		if d.Watchlist, err = m.src.GetWatchlist(ctx, "", trakt.WithExtended(trakt.ExtendedFull)); err != nil {
+			return fmt.Errorf("sync watchlist: %w", err)
+		}
+		changed = true
+	}
+	if first || !activities.Movies.CollectedAt.Equal(old.Movies.CollectedAt) || !activities.Episodes.CollectedAt.Equal(old.Episodes.CollectedAt) {
+		if d.Collection, err = m.fetchCollection(ctx); err != nil {
+			return fmt.Errorf("sync collection: %w", err)
+		}
+		changed = true
+	}
+
+	now := m.now()
+	d.Activities = *activities
+	d.SyncedAt = now
+	if changed {
+		if err := save(m.path, d); err != nil {
+			return fmt.Errorf("save mirror: %w", err)
+		}
+		m.logger.Info("mirror synced", "plays", len(d.History), "ratings", len(d.Ratings), "watchlist", len(d.Watchlist), "collection", len(d.Collection))
+	}
+
+	m.mu.Lock()
+	m.data = d
+	m.checked = now
+	m.mu.Unlock()
+	return nil
+}
+
+func ratedChanged(a, b trakt.LastActivities) bool {
+	return !a.Movies.RatedAt.Equal(b.Movies.RatedAt) || !a.Shows.RatedAt.Equal(b.Shows.RatedAt) ||
+		!a.Seasons.RatedAt.Equal(b.Seasons.RatedAt) || !a.Episodes.RatedAt.Equal(b.Episodes.RatedAt)
+}
+
+func watchlistChanged(a, b trakt.LastActivities) bool {
+	return !a.Movies.WatchlistedAt.Equal(b.Movies.WatchlistedAt) || !a.Shows.WatchlistedAt.Equal(b.Shows.WatchlistedAt) ||
+		!a.Seasons.WatchlistedAt.Equal(b.Seasons.WatchlistedAt) || !a.Episodes.WatchlistedAt.Equal(b.Episodes.WatchlistedAt)
+}
+
+// syncHistory adds the plays since the newest one in history. Removals
+// and backdated plays don't show up that way, so if the count then differs
+// from Trakt's the whole history is fetched again.
+func (m *Mirror) syncHistory(ctx context.Context, history []trakt.HistoryItem) ([]trakt.HistoryItem, error) {
+	if len(history) > 0 {
+		known := make(map[int64]bool, len(history))
+		for _, h := range history {
+			known[h.ID] = true
+		}
+		var added []trakt.HistoryItem
+		// Trakt's end_at is exclusive; a day's slack covers clock skew
+		since, until := history[0].WatchedAt, m.now().Add(24*time.Hour)
+		err := m.src.ForEachHistoryItem(ctx, "", func(h trakt.HistoryItem) error {
+			if !known[h.ID] {
+				added = append(added, h)
+			}
+			return nil
+		}, trakt.WithPeriod(since, until), trakt.WithExtended(trakt.ExtendedFull))
+		if err != nil {
+			return nil, err
+		}
+
+		merged := append(added, history...)
+		sortHistory(merged)
+		page, err := m.src.GetHistoryPage(ctx, "", 1, 1)
+		if err != nil {
+			return nil, err
+		}
+		if page.Pagination.ItemCount == len(merged) {
+			return merged, nil
+		}
+		m.logger.Debug("mirror history out of step, fetching all of it", "mirrored", len(merged), "trakt", page.Pagination.ItemCount)
+	}
+
+	var all []trakt.HistoryItem
+	err := m.src.ForEachHistoryItem(ctx, "", func(h trakt.HistoryItem) error {
+		all = append(all, h)
+		return nil
+	}, trakt.WithExtended(trakt.ExtendedFull))
+	if err != nil {
+		return nil, err
+	}
+	sortHistory(all)
+	return all, nil
+}
+
+// sortHistory orders plays newest first, as Trakt lists them.
+func sortHistory(history []trakt.HistoryItem) {
+	sort.SliceStable(history, func(i, j int) bool {
+		if !history[i].WatchedAt.Equal(history[j].WatchedAt) {
+			return history[i].WatchedAt.After(history[j].WatchedAt)
+		}
+		return history[i].ID > history[j].ID
+	})
+}
+
+func (m *Mirror) fetchCollection(ctx context.Context) ([]trakt.CollectionEntry, error) {
+	var collection []trakt.CollectionEntry
+	for _, collectionType := range []string{"movies", "shows"} {
+		err := m.src.ForEachCollected(ctx, collectionType, func(e trakt.CollectionEntry) error {
+			collection = append(collection, e)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return collection, nil
+}
+
+// ForEachHistoryItem calls fn with every mirrored play, newest first, like
+// trakt.Client.ForEachHistoryItem. historyType is "shows" or "movies" to
+// read only those, or empty for both, and WithPeriod limits the plays to a
+// period. Plays always carry extended info.
+func (m *Mirror) ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error {
+	params := url.Values{}
+	for _, opt := range opts {
+		opt(params)
+	}
+	start, _ := time.Parse(time.RFC3339, params.Get("start_at"))
+	end, _ := time.Parse(time.RFC3339, params.Get("end_at"))
+
+	m.mu.RLock()
+	history := m.data.History
+	m.mu.RUnlock()
+
+	for _, h := range history {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		switch {
+		case historyType == "shows" && h.Episode == nil, historyType == "movies" && h.Movie == nil:
+			continue
+		case !end.IsZero() && !h.WatchedAt.Before(end):
+			continue
+		case !start.IsZero() && h.WatchedAt.Before(start):
+			// Newest first, so nothing later is in the period
+			return nil
+		}
+		if err := fn(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ratings returns the mirrored ratings of ratingType, "movies", "shows",
+// "seasons" or "episodes", or all of them if it's empty.
+func (m *Mirror) Ratings(ratingType string) []trakt.Rating {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ratings []trakt.Rating
+	for _, r := range m.data.Ratings {
+		if ratingType == "" || r.Type+"s" == ratingType {
+			ratings = append(ratings, r)
+		}
+	}
+	return ratings
+}
+
+// Watchlist returns the mirrored watchlist items of watchlistType,
+// "movies", "shows", "seasons" or "episodes", or all of them if it's
+// empty.
+func (m *Mirror) Watchlist(watchlistType string) []trakt.WatchlistItem {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []trakt.WatchlistItem
+	for _, item := range m.data.Watchlist {
+		if watchlistType == "" || item.Type+"s" == watchlistType {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Collection returns the mirrored collection: movies, then shows.
+func (m *Mirror) Collection() []trakt.CollectionEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]trakt.CollectionEntry(nil), m.data.Collection...)
+}
+
+// file is the layout of the mirror on disk. Plays refer to their show or
+// movie by Trakt ID so that long histories don't repeat the same metadata
+// thousands of times.
+type file struct {
+	Version    int                     `json:"version"`
+	SyncedAt   time.Time               `json:"synced_at"`
+	Activities trakt.LastActivities    `json:"activities"`
+	Shows      map[int]*trakt.Show     `json:"shows"`
+	Movies     map[int]*trakt.Movie    `json:"movies"`
+	History    []play                  `json:"history"`
+	Ratings    []trakt.Rating          `json:"ratings"`
+	Watchlist  []trakt.WatchlistItem   `json:"watchlist"`
+	Collection []trakt.CollectionEntry `json:"collection"`
+}
+
+type play struct {
+	ID        int64          `json:"id"`
+	WatchedAt time.Time      `json:"watched_at"`
+	Action    string         `json:"action"`
+	Type      string         `json:"type"`
+	Show      int            `json:"show,omitempty"`
+	Movie     int            `json:"movie,omitempty"`
+	Episode   *trakt.Episode `json:"episode,omitempty"`
+}
+
+func save(path string, d data) error {
+	f := file{
+		Version:    formatVersion,
+		SyncedAt:   d.SyncedAt,
+		Activities: d.Activities,
+		Shows:      make(map[int]*trakt.Show),
+		Movies:     make(map[int]*trakt.Movie),
+		History:    make([]play, len(d.History)),
+		Ratings:    d.Ratings,
+		Watchlist:  d.Watchlist,
+		Collection: d.Collection,
+	}
+	for i, h := range d.History {
+		p := play{ID: h.ID, WatchedAt: h.WatchedAt, Action: h.Action, Type: h.Type, Episode: h.Episode}
+		if h.Show != nil {
+			p.Show = h.Show.IDs.Trakt
+			f.Shows[p.Show] = h.Show
+		}
+		if h.Movie != nil {
+			p.Movie = h.Movie.IDs.Trakt
+			f.Movies[p.Movie] = h.Movie
+		}
+		f.History[i] = p
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".mirror-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func load(path string) (data, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return data{}, err
+	}
+	var f file
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return data{}, err
+	}
+	if f.Version != formatVersion {
+		return data{}, fmt.Errorf("mirror format %d, want %d", f.Version, formatVersion)
+	}
+
+	d := data{
+		SyncedAt:   f.SyncedAt,
+		Activities: f.Activities,
+		History:    make([]trakt.HistoryItem, len(f.History)),
+		Ratings:    f.Ratings,
+		Watchlist:  f.Watchlist,
+		Collection: f.Collection,
+	}
+	for i, p := range f.History {
+		d.History[i] = trakt.HistoryItem{
+			ID: p.ID, WatchedAt: p.WatchedAt, Action: p.Action, Type: p.Type,
+			Show: f.Shows[p.Show], Movie: f.Movies[p.Movie], Episode: p.Episode,
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,270 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// fakeSource is a Trakt account that records which lists were fetched.
+type fakeSource struct {
+	activities trakt.LastActivities
+	history    []trakt.HistoryItem // newest first
+	ratings    []trakt.Rating
+	watchlist  []trakt.WatchlistItem
+	collection map[string][]trakt.CollectionEntry
+	fail       error
+
+	calls []string
+}
+
+func (f *fakeSource) GetLastActivities(ctx context.Context) (*trakt.LastActivities, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	a := f.activities
+	return &a, nil
+}
+
+func (f *fakeSource) GetHistoryPage(ctx context.Context, historyType string, page, limit int) (*trakt.HistoryPage, error) {
+	f.calls = append(f.calls, "history count")
+	return &trakt.HistoryPage{Pagination: trakt.Pagination{ItemCount: len(f.history)}}, nil
+}
+
+func (f *fakeSource) ForEachHistoryItem(ctx context.Context, historyType string, fn func(trakt.HistoryItem) error, opts ...trakt.RequestOption) error {
+	params := url.Values{}
+	for _, opt := range opts {
+		opt(params)
+	}
+	start, _ := time.Parse(time.RFC3339, params.Get("start_at"))
+	if start.IsZero() {
+		f.calls = append(f.calls, "history")
+	} else {
+		f.calls = append(f.calls, "history since")
+	}
+	for _, h := range f.history {
+		if h.WatchedAt.Before(start) {
+			continue
+		}
+		if err := fn(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeSource) GetUserRatings(ctx context.Context, user, ratingType string) ([]trakt.Rating, error) {
+	f.calls = append(f.calls, "ratings")
+	return f.ratings, nil
+}
+
+func (f *fakeSource) GetWatchlist(ctx context.Context, watchlistType string, opts ...trakt.RequestOption) ([]trakt.WatchlistItem, error) {
+	f.calls = append(f.calls, "watchlist")
+	return f.watchlist, nil
+}
+
+func (f *fakeSource) ForEachCollected(ctx context.Context, collectionType string, fn func(trakt.CollectionEntry) error) error {
+	f.calls = append(f.calls, "collection "+collectionType)
+	for _, e := range f.collection[collectionType] {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeSource) takeCalls() []string {
+	calls := f.calls
+	f.calls = nil
+	return calls
+}
+
+var (
+	severance = &trakt.Show{Title: "Severance", Runtime: 50, IDs: trakt.ShowIDs{Trakt: 1}}
+	dune      = &trakt.Movie{Title: "Dune", Runtime: 155, IDs: trakt.MovieIDs{Trakt: 2}}
+	day       = func(d int) time.Time { return time.Date(2026, 3, d, 20, 0, 0, 0, time.UTC) }
+)
+
+func episodePlay(id int64, d, number int) trakt.HistoryItem {
+	return trakt.HistoryItem{ID: id, Type: "episode", WatchedAt: day(d), Show: severance, Episode: &trakt.Episode{Season: 1, Number: number}}
+}
+
+func newSource() *fakeSource {
+	return &fakeSource{
+		activities: trakt.LastActivities{
+			Movies:   trakt.ItemActivities{WatchedAt: day(3), RatedAt: day(3)},
+			Episodes: trakt.ItemActivities{WatchedAt: day(2)},
+		},
+		history: []trakt.HistoryItem{
+			{ID: 3, Type: "movie", WatchedAt: day(3), Movie: dune},
+			episodePlay(2, 2, 2),
+			episodePlay(1, 1, 1),
+		},
+		ratings:    []trakt.Rating{{Rating: 9, Type: "movie", Movie: dune}, {Rating: 8, Type: "show", Show: severance}},
+		watchlist:  []trakt.WatchlistItem{{Type: "show", Show: severance}},
+		collection: map[string][]trakt.CollectionEntry{"movies": {{Movie: dune}}},
+	}
+}
+
+func equalCalls(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMirror_SyncsOnlyWhatChanged(t *testing.T) {
+	src := newSource()
+	m, err := Open(t.TempDir(), src, nil)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+	if calls := src.takeCalls(); !equalCalls(calls, "history", "ratings", "watchlist", "collection movies", "collection shows") {
+		t.Errorf("expected the first sync to fetch everything, got %v", calls)
+	}
+	if s := m.Stats(); s.Plays != 3 || s.Ratings != 2 || s.Watchlist != 1 || s.Collection != 1 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+
+	// Nothing changed
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if calls := src.takeCalls(); len(calls) != 0 {
+		t.Errorf("expected no fetches when nothing changed, got %v", calls)
+	}
+
+	// A new play fetches only the plays since the newest one mirrored
+	src.history = append([]trakt.HistoryItem{episodePlay(4, 4, 3)}, src.history...)
+	src.activities.Episodes.WatchedAt = day(4)
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if calls := src.takeCalls(); !equalCalls(calls, "history since", "history count") {
+		t.Errorf("expected an incremental history sync, got %v", calls)
+	}
+	if s := m.Stats(); s.Plays != 4 {
+		t.Errorf("expected 4 plays, got %d", s.Plays)
+	}
+
+	// A removal leaves the count out of step, so the history is fetched again
+	src.history = src.history[:len(src.history)-1]
+	src.activities.Episodes.WatchedAt = day(5)
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if calls := src.takeCalls(); !equalCalls(calls, "history since", "history count", "history") {
+		t.Errorf("expected a full history sync after a removal, got %v", calls)
+	}
+	if s := m.Stats(); s.Plays != 3 {
+		t.Errorf("expected 3 plays, got %d", s.Plays)
+	}
+
+	// A rating fetches only the ratings
+	src.activities.Shows.RatedAt = day(6)
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if calls := src.takeCalls(); !equalCalls(calls, "ratings") {
+		t.Errorf("expected only ratings to be fetched, got %v", calls)
+	}
+}
+
+func TestMirror_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	src := newSource()
+	m, err := Open(dir, src, nil)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	reopened, err := Open(dir, src, nil)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !reopened.Synced() {
+		t.Fatal("expected the saved mirror to be loaded")
+	}
+
+	var titles []string
+	err = reopened.ForEachHistoryItem(context.Background(), "", func(h trakt.HistoryItem) error {
+		if h.Show != nil {
+			titles = append(titles, h.Show.Title)
+		} else {
+			titles = append(titles, h.Movie.Title)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachHistoryItem failed: %v", err)
+	}
+	if !equalCalls(titles, "Dune", "Severance", "Severance") {
+		t.Errorf("expected plays with their shows and movies restored, got %v", titles)
+	}
+	if r := reopened.Ratings("movies"); len(r) != 1 || r[0].Rating != 9 {
+		t.Errorf("unexpected movie ratings: %+v", r)
+	}
+}
+
+func TestMirror_ForEachHistoryItemFilters(t *testing.T) {
+	src := newSource()
+	m, _ := Open(t.TempDir(), src, nil)
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	var ids []int64
+	collect := func(h trakt.HistoryItem) error {
+		ids = append(ids, h.ID)
+		return nil
+	}
+
+	if err := m.ForEachHistoryItem(context.Background(), "shows", collect); err != nil {
+		t.Fatalf("ForEachHistoryItem failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 1 {
+		t.Errorf("expected only episode plays, got %v", ids)
+	}
+
+	ids = nil
+	period := trakt.WithPeriod(day(2), day(3))
+	if err := m.ForEachHistoryItem(context.Background(), "", collect, period); err != nil {
+		t.Fatalf("ForEachHistoryItem failed: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("expected only the play in the period, got %v", ids)
+	}
+}
+
+func TestMirror_RefreshKeepsCopyOnFailure(t *testing.T) {
+	src := newSource()
+	m, _ := Open(t.TempDir(), src, nil)
+	if err := m.Sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	src.fail = errors.New("trakt is down")
+	m.Invalidate()
+	if err := m.Refresh(context.Background()); err == nil {
+		t.Error("expected the failed sync to be reported")
+	}
+	if !m.Synced() || m.Stats().Plays != 3 {
+		t.Errorf("expected the last copy to stay in place, got %+v", m.Stats())
+	}
+}
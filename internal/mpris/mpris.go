@@ -0,0 +1,119 @@
+// Package mpris reads what desktop media players such as mpv and VLC are
+// playing on Linux, through the MPRIS D-Bus interface they implement. It
+// asks playerctl, which most distributions package, rather than speaking
+// D-Bus itself.
+package mpris
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Player statuses.
+const (
+	StatusPlaying = "Playing"
+	StatusPaused  = "Paused"
+	StatusStopped = "Stopped"
+)
+
+// ErrNoPlayerctl is returned when playerctl isn't installed.
+var ErrNoPlayerctl = errors.New("playerctl not found in PATH")
+
+// Player is the state of one MPRIS player.
+type Player struct {
+	Name     string // e.g. "mpv", "vlc"
+	Status   string // StatusPlaying, StatusPaused or StatusStopped
+	Position time.Duration
+	Length   time.Duration // 0 if unknown
+	URL      string        // of the file or stream, if the player says
+	Title    string
+}
+
+// Progress returns how much of the item has been played, in percent, or 0
+// if its length is unknown.
+func (p Player) Progress() float64 {
+	if p.Length <= 0 {
+		return 0
+	}
+	return min(100, 100*float64(p.Position)/float64(p.Length))
+}
+
+// File returns the path of a local file the player is playing, or "".
+func (p Player) File() string {
+	if strings.HasPrefix(p.URL, "/") {
+		return p.URL
+	}
+	u, err := url.Parse(p.URL)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return u.Path
+}
+
+// format is the playerctl template printing a Player per line.
+const format = "{{playerName}}\t{{status}}\t{{position}}\t{{mpris:length}}\t{{xesam:url}}\t{{xesam:title}}"
+
+// Players returns the state of the running players named in names, such
+// as "mpv" or "vlc", or of every player if names is empty.
+func Players(ctx context.Context, names []string) ([]Player, error) {
+	bin, err := exec.LookPath("playerctl")
+	if err != nil {
+		return nil, ErrNoPlayerctl
+	}
+	args := []string{"--all-players"}
+	if len(names) > 0 {
+		args = append(args, "--player="+strings.Join(names, ","))
+	}
+	args = append(args, "metadata", "--format", format)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// playerctl fails when no player is running
+		if strings.Contains(stderr.String(), "No players found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("playerctl: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parse(stdout.String()), nil
+}
+
+// parse reads the lines playerctl prints for format. Lines it can't read
+// are skipped.
+func parse(out string) []Player {
+	var players []Player
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 6)
+		if len(fields) < 6 {
+			continue
+		}
+		players = append(players, Player{
+			Name:     fields[0],
+			Status:   fields[1],
+			Position: microseconds(fields[2]),
+			Length:   microseconds(fields[3]),
+			URL:      fields[4],
+			Title:    fields[5],
+		})
+	}
+	return players
+}
+
+// microseconds parses a duration in microseconds, as MPRIS gives them,
+// or returns 0.
+func microseconds(s string) time.Duration {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(n) * time.Microsecond
+}
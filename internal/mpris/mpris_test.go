@@ -0,0 +1,43 @@
+package mpris
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	out := "mpv\tPlaying\t1500000000\t3000000000\tfile:///home/me/Videos/Severance.S01E01.mkv\tSeverance.S01E01.mkv\n" +
+		"vlc\tPaused\t60000000\t\t/home/me/Videos/Dune (2021).mkv\tDune\n" +
+		"garbage\n"
+
+	players := parse(out)
+	if len(players) != 2 {
+		t.Fatalf("expected 2 players, got %+v", players)
+	}
+
+	mpv := players[0]
+	if mpv.Name != "mpv" || mpv.Status != StatusPlaying || mpv.Position != 25*time.Minute || mpv.Length != 50*time.Minute {
+		t.Errorf("unexpected player %+v", mpv)
+	}
+	if got := mpv.Progress(); got != 50 {
+		t.Errorf("expected 50%% played, got %v", got)
+	}
+	if got := mpv.File(); got != "/home/me/Videos/Severance.S01E01.mkv" {
+		t.Errorf("unexpected file %q", got)
+	}
+
+	vlc := players[1]
+	if vlc.Status != StatusPaused || vlc.Title != "Dune" {
+		t.Errorf("unexpected player %+v", vlc)
+	}
+	if got := vlc.Progress(); got != 0 {
+		t.Errorf("expected no progress without a length, got %v", got)
+	}
+	if got := vlc.File(); got != "/home/me/Videos/Dune (2021).mkv" {
+		t.Errorf("unexpected file %q", got)
+	}
+
+	if got := (Player{URL: "https://example.com/stream"}).File(); got != "" {
+		t.Errorf("expected no file for a stream, got %q", got)
+	}
+}
@@ -0,0 +1,250 @@
+// Package queue journals Trakt writes that couldn't be sent because Trakt
+// was unreachable, and replays them once it's back.
+//
+// The journal is a file of JSON lines, one write per line, appended and
+// synced to disk before a write is reported as queued, so queued writes
+// survive a restart. Writes are replayed in the order they were made.
+package queue
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// ReplayInterval is how often Run tries to replay queued writes.
+const ReplayInterval = time.Minute
+
+// Entry is one queued write: history to add, ratings to save or items to
+// add to the watchlist.
+type Entry struct {
+	QueuedAt  time.Time          `json:"queued_at"`
+	History   *trakt.WatchedItem `json:"history,omitempty"`
+	Ratings   *trakt.RatingItem  `json:"ratings,omitempty"`
+	Watchlist *trakt.SyncItems   `json:"watchlist,omitempty"`
+}
+
+// Sink is where queued writes are replayed to, as trakt.Client does.
+type Sink interface {
+	AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error)
+	AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error)
+	AddToWatchlist(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error)
+}
+
+// Queue is a durable journal of writes waiting for Trakt. It is safe for
+// concurrent use.
+type Queue struct {
+	path   string
+	logger *slog.Logger
+
+	replayMu sync.Mutex // held for the length of a replay
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// DefaultPath returns the default journal location, trakt-mcp/queue.jsonl
+// in the user's config directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "trakt-mcp", "queue.jsonl"), nil
+}
+
+// Open opens the journal at path, loading the writes queued by an earlier
+// run. Lines that can't be read, such as one cut short by a crash, are
+// skipped.
+func Open(path string, logger *slog.Logger) (*Queue, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create queue directory: %w", err)
+	}
+	q := &Queue{path: path, logger: logger}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || (e.History == nil && e.Ratings == nil && e.Watchlist == nil) {
+			logger.Warn("skipping unreadable queued write", "path", path)
+			continue
+		}
+		q.entries = append(q.entries, e)
+	}
+	if len(q.entries) > 0 {
+		logger.Info("writes waiting for Trakt", "count", len(q.entries))
+	}
+	return q, scanner.Err()
+}
+
+// Len returns the number of writes waiting.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// Add journals a write. It is on disk when Add returns without error.
+func (q *Queue) Add(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	f, err := os.OpenFile(q.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	q.entries = append(q.entries, e)
+	return nil
+}
+
+// Replay sends the queued writes to sink in order, stopping at the first
+// one Trakt still can't take; the rest wait for the next replay. Writes
+// Trakt rejects outright are dropped and logged, since sending them again
+// won't help. It returns how many writes went through, and an error only
+// if the journal couldn't be updated.
+func (q *Queue) Replay(ctx context.Context, sink Sink) (int, error) {
+	q.replayMu.Lock()
+	defer q.replayMu.Unlock()
+
+	// Writes are sent without holding the lock, so new ones can be queued
+	// meanwhile; they're only ever appended, after the ones being sent
+	q.mu.Lock()
+	pending := q.entries
+	q.mu.Unlock()
+
+	sent, done := 0, 0
+	for _, e := range pending {
+		var err error
+		switch {
+		case e.History != nil:
+			_, err = sink.AddToHistory(ctx, *e.History)
+		case e.Ratings != nil:
+			_, err = sink.AddRatings(ctx, *e.Ratings)
+		default:
+			_, err = sink.AddToWatchlist(ctx, *e.Watchlist)
+		}
+		if err != nil && (trakt.IsUnavailable(err) || retryable(err)) {
+			q.logger.Debug("trakt still can't take queued writes", "waiting", len(pending)-done, "error", err)
+			break
+		}
+		if err != nil {
+			q.logger.Warn("dropping queued write Trakt rejected", "queued_at", e.QueuedAt, "error", err)
+		} else {
+			sent++
+		}
+		done++
+	}
+	if done == 0 {
+		return 0, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.entries = q.entries[done:]
+	if sent > 0 {
+		q.logger.Info("replayed queued writes", "count", sent, "waiting", len(q.entries))
+	}
+	return sent, q.save()
+}
+
+// retryable reports whether a write failed for a reason that may clear up:
+// a rate limit, or a sign-in that has lapsed.
+func retryable(err error) bool {
+	return errors.Is(err, trakt.ErrRateLimited) || errors.Is(err, trakt.ErrUnauthorized) ||
+		errors.Is(err, trakt.ErrNoRefreshToken) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// save rewrites the journal with the writes still waiting. q.mu must be
+// held.
+func (q *Queue) save() error {
+	if len(q.entries) == 0 {
+		err := os.Remove(q.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, e := range q.entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), ".queue-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), q.path)
+}
+
+// Run replays queued writes every ReplayInterval, and once at the start,
+// until ctx is cancelled.
+func (q *Queue) Run(ctx context.Context, sink Sink) {
+	ticker := time.NewTicker(ReplayInterval)
+	defer ticker.Stop()
+	for {
+		if q.Len() > 0 {
+			if _, err := q.Replay(ctx, sink); err != nil && ctx.Err() == nil {
+				q.logger.Warn("replaying queued writes failed", "error", err)
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
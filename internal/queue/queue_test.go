@@ -0,0 +1,160 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// fakeSink accepts or fails writes, recording the ones it accepted.
+type fakeSink struct {
+	fail      map[int]error // by movie Trakt ID
+	history   []int
+	ratings   []int
+	watchlist []int
+}
+
+func (f *fakeSink) AddToHistory(ctx context.Context, item trakt.WatchedItem) (*trakt.SyncResponse, error) {
+	id := item.Movies[0].IDs.Trakt
+	if err := f.fail[id]; err != nil {
+		return nil, err
+	}
+	f.history = append(f.history, id)
+	return &trakt.SyncResponse{}, nil
+}
+
+func (f *fakeSink) AddRatings(ctx context.Context, item trakt.RatingItem) (*trakt.SyncResponse, error) {
+	id := item.Movies[0].IDs.Trakt
+	if err := f.fail[id]; err != nil {
+		return nil, err
+	}
+	f.ratings = append(f.ratings, id)
+	return &trakt.SyncResponse{}, nil
+}
+
+func (f *fakeSink) AddToWatchlist(ctx context.Context, items trakt.SyncItems) (*trakt.SyncResponse, error) {
+	id := items.Movies[0].IDs.Trakt
+	if err := f.fail[id]; err != nil {
+		return nil, err
+	}
+	f.watchlist = append(f.watchlist, id)
+	return &trakt.SyncResponse{}, nil
+}
+
+func watch(id int) Entry {
+	return Entry{QueuedAt: time.Now(), History: &trakt.WatchedItem{WatchedAt: "2026-03-01T20:00:00Z", Movies: []trakt.Movie{{IDs: trakt.MovieIDs{Trakt: id}}}}}
+}
+
+func rating(id int) Entry {
+	return Entry{QueuedAt: time.Now(), Ratings: &trakt.RatingItem{Movies: []trakt.RatedMovie{{Rating: 8, IDs: trakt.MovieIDs{Trakt: id}}}}}
+}
+
+func watchlisted(id int) Entry {
+	return Entry{QueuedAt: time.Now(), Watchlist: &trakt.SyncItems{Movies: []trakt.Movie{{IDs: trakt.MovieIDs{Trakt: id}}}}}
+}
+
+func TestQueue_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	q, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := q.Add(watch(1)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := q.Add(rating(1)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	reopened, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if reopened.Len() != 2 {
+		t.Fatalf("expected 2 queued writes after reopening, got %d", reopened.Len())
+	}
+
+	sink := &fakeSink{}
+	sent, err := reopened.Replay(context.Background(), sink)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if sent != 2 || len(sink.history) != 1 || len(sink.ratings) != 1 {
+		t.Errorf("expected both writes replayed, got %d: %+v", sent, sink)
+	}
+
+	// Once replayed, the journal is gone
+	if again, _ := Open(path, nil); again.Len() != 0 {
+		t.Errorf("expected an empty queue after replay, got %d", again.Len())
+	}
+}
+
+func TestQueue_ReplayStopsWhileUnavailable(t *testing.T) {
+	q, _ := Open(filepath.Join(t.TempDir(), "queue.jsonl"), nil)
+	for _, id := range []int{1, 2, 3} {
+		if err := q.Add(watch(id)); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	down := &url.Error{Op: "Post", URL: "https://api.trakt.tv/sync/history", Err: errors.New("connection refused")}
+	sink := &fakeSink{fail: map[int]error{2: down}}
+	sent, err := q.Replay(context.Background(), sink)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if sent != 1 || q.Len() != 2 {
+		t.Errorf("expected 1 write sent and 2 waiting, got %d and %d", sent, q.Len())
+	}
+
+	// Back online, the rest go through in order
+	sink.fail = nil
+	if sent, _ := q.Replay(context.Background(), sink); sent != 2 || q.Len() != 0 {
+		t.Errorf("expected the remaining 2 writes sent, got %d with %d waiting", sent, q.Len())
+	}
+	if len(sink.history) != 3 || sink.history[1] != 2 || sink.history[2] != 3 {
+		t.Errorf("expected writes replayed in order, got %v", sink.history)
+	}
+}
+
+func TestQueue_ReplayDropsRejectedWrites(t *testing.T) {
+	q, _ := Open(filepath.Join(t.TempDir(), "queue.jsonl"), nil)
+	_ = q.Add(watch(1))
+	_ = q.Add(watch(2))
+
+	rejected := &trakt.APIError{StatusCode: 422, Description: "unprocessable"}
+	sink := &fakeSink{fail: map[int]error{1: rejected}}
+	sent, err := q.Replay(context.Background(), sink)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if sent != 1 || q.Len() != 0 || len(sink.history) != 1 || sink.history[0] != 2 {
+		t.Errorf("expected the rejected write dropped and the next sent, got %d sent, %d waiting, %v", sent, q.Len(), sink.history)
+	}
+}
+
+func TestQueue_ReplaysWatchlistAdds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	q, _ := Open(path, nil)
+	_ = q.Add(watch(1))
+	_ = q.Add(watchlisted(2))
+
+	// The watchlist add survives a restart like the other writes
+	q, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	sink := &fakeSink{}
+	sent, err := q.Replay(context.Background(), sink)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if sent != 2 || q.Len() != 0 || len(sink.history) != 1 || len(sink.watchlist) != 1 || sink.watchlist[0] != 2 {
+		t.Errorf("expected the watch and watchlist add replayed, got %d sent, history %v, watchlist %v", sent, sink.history, sink.watchlist)
+	}
+}
@@ -0,0 +1,185 @@
+// Package tmdb is a small client for The Movie Database (TMDB) API, used
+// to add artwork and fuller overviews to what Trakt returns. Shows and
+// movies are looked up by the TMDB IDs Trakt already carries, so no search
+// is needed.
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BaseURL        = "https://api.themoviedb.org/3"
+	ImageBaseURL   = "https://image.tmdb.org/t/p"
+	DefaultTimeout = 10 * time.Second
+
+	// cacheTTL is how long details are reused; artwork and overviews
+	// rarely change.
+	cacheTTL = 24 * time.Hour
+	// maxCacheEntries bounds the cache; it is emptied when full.
+	maxCacheEntries = 1024
+
+	posterSize   = "w500"
+	backdropSize = "w1280"
+)
+
+// ErrNotFound is matched by an APIError for an ID TMDB doesn't know.
+var ErrNotFound = errors.New("not found")
+
+// APIError is an error response from TMDB.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("tmdb: %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("tmdb: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is lets errors.Is match an APIError against ErrNotFound.
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// Details is what TMDB knows about a show or movie beyond Trakt's data.
+// Image paths are relative; use PosterURL and BackdropURL.
+type Details struct {
+	Overview     string `json:"overview"`
+	Tagline      string `json:"tagline"`
+	PosterPath   string `json:"poster_path"`
+	BackdropPath string `json:"backdrop_path"`
+}
+
+// PosterURL returns the poster as an https URL, or "" if there is none.
+func (d *Details) PosterURL() string {
+	return imageURL(posterSize, d.PosterPath)
+}
+
+// BackdropURL returns the backdrop as an https URL, or "" if there is none.
+func (d *Details) BackdropURL() string {
+	return imageURL(backdropSize, d.BackdropPath)
+}
+
+func imageURL(size, path string) string {
+	if path == "" {
+		return ""
+	}
+	return ImageBaseURL + "/" + size + path
+}
+
+// Client is a TMDB API client. It is safe for concurrent use.
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cached // keyed by request path
+}
+
+type cached struct {
+	details *Details
+	expires time.Time
+}
+
+// NewClient creates a client authenticating with apiKey, either a v3 API
+// key or a v4 read access token.
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:     apiKey,
+		baseURL:    BaseURL,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		now:        time.Now,
+		cache:      make(map[string]cached),
+	}
+}
+
+// SetBaseURL sets the base URL for API requests, e.g. a test server.
+func (c *Client) SetBaseURL(url string) {
+	c.baseURL = strings.TrimRight(url, "/")
+}
+
+// Movie returns the details of the movie with TMDB ID id.
+func (c *Client) Movie(ctx context.Context, id int) (*Details, error) {
+	return c.details(ctx, fmt.Sprintf("/movie/%d", id))
+}
+
+// Show returns the details of the TV show with TMDB ID id.
+func (c *Client) Show(ctx context.Context, id int) (*Details, error) {
+	return c.details(ctx, fmt.Sprintf("/tv/%d", id))
+}
+
+func (c *Client) details(ctx context.Context, path string) (*Details, error) {
+	c.mu.Lock()
+	entry, ok := c.cache[path]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.details, nil
+	}
+
+	var d Details
+	if err := c.get(ctx, path, &d); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if len(c.cache) >= maxCacheEntries {
+		clear(c.cache)
+	}
+	c.cache[path] = cached{details: &d, expires: c.now().Add(cacheTTL)}
+	c.mu.Unlock()
+	return &d, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, result any) error {
+	u := c.baseURL + path
+	// v4 read access tokens are JWTs; v3 keys go in the query
+	bearer := strings.HasPrefix(c.apiKey, "eyJ")
+	if !bearer {
+		u += "?api_key=" + url.QueryEscape(c.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("tmdb: GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("tmdb: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			StatusMessage string `json:"status_message"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return &APIError{StatusCode: resp.StatusCode, Message: e.StatusMessage}
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("tmdb: decode response: %w", err)
+	}
+	return nil
+}
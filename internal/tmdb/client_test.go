@@ -0,0 +1,98 @@
+package tmdb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestClient creates a client with a mock server
+func newTestClient(t *testing.T, apiKey string, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient(apiKey)
+	client.SetBaseURL(server.URL)
+	return client
+}
+
+func TestClient_Movie(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, "test-key", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/movie/438631" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("api_key"); got != "test-key" {
+			t.Errorf("expected the v3 key in the query, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":438631,"title":"Dune","overview":"Paul Atreides, a brilliant young man...","tagline":"Beyond fear, destiny awaits.",
+			"poster_path":"/d5NXSklXo0qyIYkgV94XAgMIckC.jpg","backdrop_path":"/jYEW5xZkZk2WTrdbMGAPFuBqbDc.jpg"}`))
+	}))
+
+	d, err := client.Movie(context.Background(), 438631)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Tagline != "Beyond fear, destiny awaits." {
+		t.Errorf("unexpected tagline %q", d.Tagline)
+	}
+	if got := d.PosterURL(); got != "https://image.tmdb.org/t/p/w500/d5NXSklXo0qyIYkgV94XAgMIckC.jpg" {
+		t.Errorf("unexpected poster URL %q", got)
+	}
+	if got := d.BackdropURL(); got != "https://image.tmdb.org/t/p/w1280/jYEW5xZkZk2WTrdbMGAPFuBqbDc.jpg" {
+		t.Errorf("unexpected backdrop URL %q", got)
+	}
+
+	// Served from the cache the second time
+	if _, err := client.Movie(context.Background(), 438631); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestClient_ShowWithReadAccessToken(t *testing.T) {
+	token := "eyJhbGciOiJIUzI1NiJ9.test"
+	client := newTestClient(t, token, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tv/95396" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer "+token {
+			t.Errorf("expected the v4 token as a bearer token, got %q", got)
+		}
+		if r.URL.RawQuery != "" {
+			t.Errorf("expected no query, got %q", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"overview":"Mark leads a team of office workers...","poster_path":"","backdrop_path":null}`))
+	}))
+
+	d, err := client.Show(context.Background(), 95396)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.PosterURL() != "" || d.BackdropURL() != "" {
+		t.Errorf("expected no artwork, got %q and %q", d.PosterURL(), d.BackdropURL())
+	}
+}
+
+func TestClient_NotFound(t *testing.T) {
+	client := newTestClient(t, "test-key", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status_code":34,"status_message":"The resource you requested could not be found."}`))
+	}))
+
+	_, err := client.Movie(context.Background(), 1)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if got := err.Error(); got != "tmdb: 404: The resource you requested could not be found." {
+		t.Errorf("unexpected message %q", got)
+	}
+}
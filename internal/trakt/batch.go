@@ -0,0 +1,61 @@
+package trakt
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchParallelism bounds the requests a batch helper keeps in
+// flight, staying well inside Trakt's rate limits.
+const DefaultBatchParallelism = 4
+
+// Batch calls fn for every item with at most parallelism calls running at
+// once. Results and errors are returned in input order; errs[i] is nil when
+// items[i] succeeded. Items not yet started when ctx is cancelled fail with
+// the context's error.
+func Batch[T, R any](ctx context.Context, items []T, parallelism int, fn func(context.Context, T) (R, error)) (results []R, errs []error) {
+	if parallelism <= 0 {
+		parallelism = DefaultBatchParallelism
+	}
+
+	results = make([]R, len(items))
+	errs = make([]error, len(items))
+	sem := make(chan struct{}, parallelism)
+
+	var wg sync.WaitGroup
+	for i, item := range items {
+		// select picks randomly when both cases are ready, so check for
+		// cancellation first to avoid starting work on a dead context
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(items); j++ {
+				errs[j] = err
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, item T) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i], errs[i] = fn(ctx, item)
+		}(i, item)
+	}
+	wg.Wait()
+	return results, errs
+}
+
+// SearchBatch runs several searches concurrently, e.g. to resolve the
+// titles of a bulk import. See Batch for how results and errors line up.
+func (c *Client) SearchBatch(ctx context.Context, queries []string, searchType string, opts ...RequestOption) ([][]SearchResult, []error) {
+	return Batch(ctx, queries, DefaultBatchParallelism, func(ctx context.Context, query string) ([]SearchResult, error) {
+		return c.Search(ctx, query, searchType, opts...)
+	})
+}
@@ -0,0 +1,79 @@
+package trakt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatch_BoundedParallelism(t *testing.T) {
+	var running, peak atomic.Int32
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	results, errs := Batch(context.Background(), items, 3, func(ctx context.Context, n int) (int, error) {
+		cur := running.Add(1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		if n == 5 {
+			return 0, errors.New("boom")
+		}
+		return n * 10, nil
+	})
+
+	if got := peak.Load(); got > 3 {
+		t.Errorf("expected at most 3 concurrent calls, saw %d", got)
+	}
+	for i, n := range items {
+		if n == 5 {
+			if errs[i] == nil {
+				t.Errorf("expected error for item %d", n)
+			}
+			continue
+		}
+		if errs[i] != nil || results[i] != n*10 {
+			t.Errorf("item %d: got %d, %v", n, results[i], errs[i])
+		}
+	}
+}
+
+func TestBatch_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, errs := Batch(ctx, []string{"a", "b"}, 1, func(ctx context.Context, s string) (string, error) {
+		return s, nil
+	})
+	for i, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("item %d: expected context.Canceled, got %v", i, err)
+		}
+	}
+}
+
+func TestClient_SearchBatch(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.URL.Query().Get("query"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"type":"movie","score":1000,"movie":{"title":"M","ids":{"trakt":` + strconv.Itoa(id) + `}}}]`))
+	}))
+
+	results, errs := client.SearchBatch(context.Background(), []string{"1", "2", "3"}, "movie")
+	for i, want := range []int{1, 2, 3} {
+		if errs[i] != nil {
+			t.Fatalf("query %d failed: %v", want, errs[i])
+		}
+		if got := results[i][0].Movie.IDs.Trakt; got != want {
+			t.Errorf("result %d: expected ID %d, got %d", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package trakt
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is how long metadata lookups are served from memory.
+	DefaultCacheTTL = 15 * time.Minute
+	// maxCacheEntries bounds the lookup cache; the least recently used entry goes first.
+	maxCacheEntries = 512
+)
+
+// lruCache is a size-bounded, least-recently-used cache whose entries
+// expire after a fixed TTL. It stores encoded responses keyed by request path.
+type lruCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	ll         *list.List // front is most recently used
+	items      map[string]*list.Element
+	now        func() time.Time
+}
+
+type cacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func newLRUCache(maxEntries int, ttl time.Duration) *lruCache {
+	return &lruCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (c *lruCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if c.now().After(entry.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *lruCache) put(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.value, entry.expires = value, expires
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.maxEntries {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *lruCache) getTTL() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ttl
+}
+
+// setTTL changes the lifetime of new entries; zero disables caching.
+func (c *lruCache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+	if ttl <= 0 {
+		c.ll.Init()
+		clear(c.items)
+	}
+}
+
+// remove deletes el. Callers must hold c.mu.
+func (c *lruCache) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*cacheEntry).key)
+}
+
+// CacheStats counts hits and misses for one of the client's caches.
+type CacheStats struct {
+	Name   string `json:"name"`
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+// HitRate returns the share of lookups served from the cache, or zero
+// before the first lookup.
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// CacheStats reports hits and misses for the in-memory lookup cache, the
+// disk cache (when enabled) and conditional requests revalidated by ETag.
+func (c *Client) CacheStats() []CacheStats {
+	c.mu.RLock()
+	haveDisk := c.disk != nil
+	c.mu.RUnlock()
+
+	stats := []CacheStats{c.lookupStats.stats("lookup")}
+	if haveDisk {
+		stats = append(stats, c.diskStats.stats("disk"))
+	}
+	return append(stats, c.etagStats.stats("etag"))
+}
+
+// cacheCounter counts hits and misses for one cache.
+type cacheCounter struct {
+	hits, misses atomic.Uint64
+}
+
+func (c *cacheCounter) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+func (c *cacheCounter) stats(name string) CacheStats {
+	return CacheStats{Name: name, Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
@@ -0,0 +1,84 @@
+package trakt
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUCache_Expiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newLRUCache(10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.put("a", []byte("1"))
+	if v, ok := cache.get("a"); !ok || string(v) != "1" {
+		t.Fatalf("expected fresh entry, got %q, %v", v, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.get("a"); ok {
+		t.Error("expected entry to expire")
+	}
+}
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newLRUCache(2, time.Minute)
+
+	cache.put("a", []byte("1"))
+	cache.put("b", []byte("2"))
+	cache.get("a") // a is now more recent than b
+	cache.put("c", []byte("3"))
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.get(key); !ok {
+			t.Errorf("expected %s to be cached", key)
+		}
+	}
+}
+
+func TestClient_LookupCache(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"type":"show","score":1000,"show":{"title":"Breaking Bad","year":2008,"ids":{"trakt":1388}}}]`))
+	}))
+
+	for i := 0; i < 2; i++ {
+		results, err := client.Search(context.Background(), "breaking bad", "show")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Show.IDs.Trakt != 1388 {
+			t.Errorf("unexpected results: %+v", results)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 API call, got %d", got)
+	}
+
+	// Different parameters are cached separately
+	if _, err := client.Search(context.Background(), "breaking bad", "movie"); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 API calls, got %d", got)
+	}
+
+	stats := client.CacheStats()
+	if len(stats) != 2 || stats[0].Name != "lookup" || stats[1].Name != "etag" {
+		t.Fatalf("expected lookup and etag stats without a disk cache, got %+v", stats)
+	}
+	if stats[0].Hits != 1 || stats[0].Misses != 2 {
+		t.Errorf("expected 1 hit and 2 misses, got %+v", stats[0])
+	}
+	if rate := stats[0].HitRate(); rate < 0.33 || rate > 0.34 {
+		t.Errorf("expected a hit rate of 1/3, got %v", rate)
+	}
+}
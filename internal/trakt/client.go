@@ -2,14 +2,22 @@ package trakt
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,17 +25,91 @@ const (
 	BaseURL        = "https://api.trakt.tv"
 	APIVersion     = "2"
 	DefaultTimeout = 30 * time.Second
+
+	// AuthorizeURL is the browser page where users approve authorization-code requests.
+	AuthorizeURL = "https://trakt.tv/oauth/authorize"
+	// DefaultRedirectURI is the local callback for the browser flow. It must
+	// be registered as a redirect URI on the Trakt application.
+	DefaultRedirectURI = "http://127.0.0.1:8976/callback"
+
+	// DefaultMaxRetries is how many times a rate-limited request is retried.
+	DefaultMaxRetries = 3
+	// maxRetryWait caps a single Retry-After wait; longer waits fail fast instead.
+	maxRetryWait = time.Minute
+
+	// refreshMargin is how long before expiry an access token is proactively refreshed.
+	refreshMargin = 24 * time.Hour
+	// oobRedirectURI is the redirect URI registered for device-flow apps.
+	oobRedirectURI = "urn:ietf:wg:oauth:2.0:oob"
 )
 
+// Device authorization outcomes reported while polling for a token.
+var (
+	ErrAuthorizationPending = errors.New("authorization_pending: the user has not authorized the device yet")
+	ErrSlowDown             = errors.New("slow_down: polling too frequently")
+	ErrExpiredToken         = errors.New("expired_token: the device code has expired")
+	ErrAccessDenied         = errors.New("access_denied: the user denied the request")
+	ErrInvalidDeviceCode    = errors.New("invalid device code")
+	ErrDeviceCodeUsed       = errors.New("device code has already been used")
+)
+
+// ErrNoRefreshToken is returned when a refresh is requested without a refresh token.
+var ErrNoRefreshToken = errors.New("no refresh token available")
+
+// Sentinel errors matched by APIError through errors.Is.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrConflict     = errors.New("conflict")
+)
+
+// maxErrorDescription caps how much of an error body is kept.
+const maxErrorDescription = 200
+
 // APIError represents an error from the Trakt API.
 type APIError struct {
 	StatusCode int
 	Method     string
 	Path       string
+
+	// Description is the error message from a JSON error body, if any.
+	// Other body content is discarded since it may echo credentials.
+	Description string
+	// RetryAfter is the wait requested by a Retry-After header.
+	RetryAfter time.Duration
+	// RateLimit is the raw X-Ratelimit header sent with 429 responses.
+	RateLimit string
+	// UpgradeURL is the X-Upgrade-URL sent when a free account hits a limit (420).
+	UpgradeURL string
 }
 
 func (e *APIError) Error() string {
-	return fmt.Sprintf("trakt API error: %s %s returned status %d", e.Method, e.Path, e.StatusCode)
+	msg := fmt.Sprintf("trakt API error: %s %s returned status %d", e.Method, e.Path, e.StatusCode)
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	return msg
+}
+
+// Is lets errors.Is match an APIError against ErrUnauthorized, ErrForbidden,
+// ErrNotFound, ErrRateLimited and ErrConflict.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	default:
+		return false
+	}
 }
 
 // IsAuthError returns true if this is an authentication error.
@@ -40,27 +122,120 @@ func (e *APIError) IsRateLimited() bool {
 	return e.StatusCode == 429
 }
 
+// IsServerError returns true if Trakt, or the CDN in front of it, failed
+// to handle the request.
+func (e *APIError) IsServerError() bool {
+	return e.StatusCode >= 500
+}
+
+// IsUnavailable reports whether err means Trakt couldn't be reached or
+// couldn't handle the request, so that it may well succeed later: a
+// network failure, a timeout or a server error. A cancelled request
+// isn't.
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsServerError()
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// newAPIError builds an APIError from a failed response.
+func newAPIError(method, path string, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode:  resp.StatusCode,
+		Method:      method,
+		Path:        path,
+		Description: errorDescription(body),
+		RateLimit:   resp.Header.Get("X-Ratelimit"),
+		UpgradeURL:  resp.Header.Get("X-Upgrade-URL"),
+	}
+	if wait, ok := parseRetryAfter(resp.Header, time.Now()); ok {
+		apiErr.RetryAfter = wait
+	}
+	return apiErr
+}
+
+// errorDescription extracts the message from a JSON error body such as
+// {"error":"invalid_grant","error_description":"..."}.
+func errorDescription(body []byte) string {
+	var payload struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+		Message          string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+
+	desc := payload.ErrorDescription
+	if desc == "" {
+		desc = payload.Message
+	}
+	if desc == "" {
+		desc = payload.Error
+	}
+	if r := []rune(desc); len(r) > maxErrorDescription {
+		desc = string(r[:maxErrorDescription]) + "…"
+	}
+	return desc
+}
+
 // Config holds the Trakt API configuration.
 type Config struct {
 	ClientID     string
 	ClientSecret string
 	AccessToken  string
 	RefreshToken string
+	RedirectURI  string // browser flow callback; defaults to DefaultRedirectURI
+	APIURL       string // API base URL; defaults to BaseURL
+	OAuthURL     string // site hosting /oauth/authorize; defaults to https://trakt.tv
 }
 
 // ConfigFromEnv creates a Config from environment variables.
 func ConfigFromEnv() Config {
+	return ConfigFromLookup(os.Getenv)
+}
+
+// ConfigFromLookup creates a Config from settings named like the
+// environment variables ConfigFromEnv reads, e.g. to layer a config file
+// under the environment.
+func ConfigFromLookup(get func(name string) string) Config {
 	return Config{
-		ClientID:     os.Getenv("TRAKT_CLIENT_ID"),
-		ClientSecret: os.Getenv("TRAKT_CLIENT_SECRET"),
-		AccessToken:  os.Getenv("TRAKT_ACCESS_TOKEN"),
-		RefreshToken: os.Getenv("TRAKT_REFRESH_TOKEN"),
+		ClientID:     get("TRAKT_CLIENT_ID"),
+		ClientSecret: get("TRAKT_CLIENT_SECRET"),
+		AccessToken:  get("TRAKT_ACCESS_TOKEN"),
+		RefreshToken: get("TRAKT_REFRESH_TOKEN"),
+		RedirectURI:  get("TRAKT_REDIRECT_URI"),
+		APIURL:       get("TRAKT_API_URL"),
+		OAuthURL:     get("TRAKT_OAUTH_URL"),
 	}
 }
 
 // Client is a Trakt API client.
 type Client struct {
-	config     Config
+	mu          sync.RWMutex // guards config tokens, tokenExpiry and store, which change after authentication
+	config      Config
+	tokenExpiry time.Time // zero when unknown, e.g. for tokens from the environment
+	store       TokenStore
+
+	refreshMu sync.Mutex // serializes token refreshes
+
+	maxRetries int
+	etags      *etagCache
+	lookups    *lruCache  // decoded metadata lookups, skipping the network entirely
+	disk       *diskCache // optional second tier that survives restarts
+	middleware []Middleware
+	rateLimits map[string]RateLimit // latest X-Ratelimit budget per bucket, guarded by mu
+
+	lookupStats cacheCounter
+	diskStats   cacheCounter
+	etagStats   cacheCounter // If-None-Match requests answered with 304
+
 	httpClient *http.Client
 	logger     *slog.Logger
 	baseURL    string // defaults to BaseURL, can be overridden for testing
@@ -71,52 +246,214 @@ func NewClient(config Config, logger *slog.Logger) *Client {
 	if logger == nil {
 		logger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
 	}
+	baseURL := BaseURL
+	if config.APIURL != "" {
+		baseURL = strings.TrimRight(config.APIURL, "/")
+	}
 	return &Client{
 		config: config,
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
-		logger:  logger,
-		baseURL: BaseURL,
+		logger:     logger,
+		baseURL:    baseURL,
+		maxRetries: DefaultMaxRetries,
+		etags:      newETagCache(),
+		lookups:    newLRUCache(maxCacheEntries, DefaultCacheTTL),
+	}
+}
+
+// SetCacheTTL sets how long search and metadata lookups are reused from
+// memory (and disk, if enabled). Zero disables the cache.
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.lookups.setTTL(ttl)
+}
+
+// SetCacheDir enables a persistent lookup cache in dir, so metadata stays
+// warm across restarts.
+func (c *Client) SetCacheDir(dir string) error {
+	disk, err := newDiskCache(dir)
+	if err != nil {
+		return err
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.disk = disk
+	return nil
+}
+
+// SetMaxRetries sets how many times a request rejected with 429 is retried
+// after waiting for its Retry-After delay. Zero disables retries.
+func (c *Client) SetMaxRetries(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxRetries = max(n, 0)
 }
 
 // IsConfigured returns true if the client has API credentials.
 func (c *Client) IsConfigured() bool {
-	return c.config.ClientID != ""
+	clientID, _ := c.credentials()
+	return clientID != ""
+}
+
+// SetCredentials replaces the API client ID and secret, e.g. when the
+// configuration is reloaded.
+func (c *Client) SetCredentials(clientID, clientSecret string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.ClientID = clientID
+	c.config.ClientSecret = clientSecret
+}
+
+// credentials returns the API client ID and secret.
+func (c *Client) credentials() (clientID, clientSecret string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config.ClientID, c.config.ClientSecret
 }
 
 // IsAuthenticated returns true if the client has an access token.
 func (c *Client) IsAuthenticated() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.config.AccessToken != ""
 }
 
-// SetBaseURL sets the base URL for API requests. Used for testing.
+// Ping checks that the API answers. When signed in it fetches the user's
+// settings, which also confirms Trakt accepts the access token.
+func (c *Client) Ping(ctx context.Context) error {
+	path := "/genres/movies"
+	if c.IsAuthenticated() {
+		path = "/users/settings"
+	}
+	return c.get(ctx, path, nil)
+}
+
+// SetToken stores the tokens from a completed OAuth exchange and persists
+// them to the token store, if one is set.
+func (c *Client) SetToken(token *Token) {
+	c.mu.Lock()
+	c.applyToken(token)
+	store := c.store
+	c.mu.Unlock()
+
+	if store != nil {
+		if err := store.Save(token); err != nil {
+			c.logger.Error("failed to save token", "error", err)
+		}
+	}
+}
+
+// SetTokenStore sets where tokens are persisted after authentication and
+// refresh. A token already in the store replaces the configured one, since
+// it holds the most recently rotated credentials. The store is used even if
+// loading fails, so a corrupt file is replaced on the next save.
+func (c *Client) SetTokenStore(store TokenStore) error {
+	token, err := store.Load()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store = store
+	if err != nil {
+		return err
+	}
+	if token != nil && token.AccessToken != "" {
+		c.applyToken(token)
+	}
+	return nil
+}
+
+// ReloadToken re-reads the token store, picking up a sign-in made by
+// another process or a hand-edited token file. It does nothing without a store.
+func (c *Client) ReloadToken() error {
+	c.mu.RLock()
+	store := c.store
+	c.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+
+	token, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if token == nil || token.AccessToken == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.applyToken(token)
+	return nil
+}
+
+// applyToken updates the in-memory credentials. Callers must hold c.mu.
+func (c *Client) applyToken(token *Token) {
+	// Cached user data may belong to a different account now
+	if token.AccessToken != c.config.AccessToken {
+		c.etags.clear()
+	}
+	c.config.AccessToken = token.AccessToken
+	c.config.RefreshToken = token.RefreshToken
+	c.tokenExpiry = time.Time{}
+	if token.CreatedAt > 0 && token.ExpiresIn > 0 {
+		c.tokenExpiry = time.Unix(token.CreatedAt, 0).Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+}
+
+// SetBaseURL sets the base URL for API requests, e.g. a staging endpoint or
+// a test server.
 func (c *Client) SetBaseURL(url string) {
-	c.baseURL = url
+	c.baseURL = strings.TrimRight(url, "/")
 }
 
 // Search searches for shows or movies.
-func (c *Client) Search(ctx context.Context, query string, searchType string) ([]SearchResult, error) {
+func (c *Client) Search(ctx context.Context, query string, searchType string, opts ...RequestOption) ([]SearchResult, error) {
 	if searchType == "" {
 		searchType = "show,movie"
 	}
 
 	params := url.Values{}
 	params.Set("query", query)
+	applyOptions(params, opts)
 
 	path := fmt.Sprintf("/search/%s?%s", searchType, params.Encode())
 
 	var results []SearchResult
-	if err := c.get(ctx, path, &results); err != nil {
+	if err := c.getCached(ctx, path, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// LookupID finds the items with an ID. idType is "trakt", "imdb", "tmdb" or
+// "tvdb"; searchType narrows the results to "show", "movie" or "episode",
+// and matters for TMDB and TVDB IDs, which are only unique per type.
+func (c *Client) LookupID(ctx context.Context, idType, id, searchType string, opts ...RequestOption) ([]SearchResult, error) {
+	params := url.Values{}
+	if searchType != "" {
+		params.Set("type", searchType)
+	}
+	applyOptions(params, opts)
+
+	path := fmt.Sprintf("/search/%s/%s", url.PathEscape(idType), url.PathEscape(id))
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	var results []SearchResult
+	if err := c.getCached(ctx, path, &results); err != nil {
 		return nil, err
 	}
 
 	return results, nil
 }
 
-// GetHistory retrieves watch history.
-func (c *Client) GetHistory(ctx context.Context, historyType string, limit int) ([]HistoryItem, error) {
+// GetHistory retrieves watch history. historyType may be "movies",
+// "shows" or "episodes", optionally followed by a Trakt ID for the plays
+// of one item, e.g. "movies/16662".
+func (c *Client) GetHistory(ctx context.Context, historyType string, limit int, opts ...RequestOption) ([]HistoryItem, error) {
 	path := "/sync/history"
 	if historyType != "" {
 		path = fmt.Sprintf("/sync/history/%s", historyType)
@@ -126,6 +463,7 @@ func (c *Client) GetHistory(ctx context.Context, historyType string, limit int)
 	if limit > 0 {
 		params.Set("limit", fmt.Sprintf("%d", limit))
 	}
+	applyOptions(params, opts)
 
 	if len(params) > 0 {
 		path = fmt.Sprintf("%s?%s", path, params.Encode())
@@ -139,6 +477,327 @@ func (c *Client) GetHistory(ctx context.Context, historyType string, limit int)
 	return history, nil
 }
 
+// historyPageLimit is the page size used when walking the full history.
+const historyPageLimit = 100
+
+// GetHistoryPage retrieves one page of watch history. Pages start at 1.
+func (c *Client) GetHistoryPage(ctx context.Context, historyType string, page, limit int) (*HistoryPage, error) {
+	path := "/sync/history"
+	if historyType != "" {
+		path = fmt.Sprintf("/sync/history/%s", historyType)
+	}
+
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	path = fmt.Sprintf("%s?%s", path, params.Encode())
+
+	var history []HistoryItem
+	pagination, err := c.getPage(ctx, path, &history)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistoryPage{Items: history}
+	if pagination != nil {
+		result.Pagination = *pagination
+	} else {
+		// Unpaginated responses hold everything in one page
+		result.Pagination = Pagination{Page: page, Limit: limit, PageCount: page, ItemCount: len(history)}
+	}
+	return result, nil
+}
+
+// ForEachHistoryPage calls fn with each page of watch history in turn,
+// stopping after the last page or at the first error fn returns.
+func (c *Client) ForEachHistoryPage(ctx context.Context, historyType string, fn func(*HistoryPage) error) error {
+	for page := 1; ; page++ {
+		result, err := c.GetHistoryPage(ctx, historyType, page, historyPageLimit)
+		if err != nil {
+			return err
+		}
+		if err := fn(result); err != nil {
+			return err
+		}
+		if len(result.Items) == 0 || page >= result.Pagination.PageCount {
+			return nil
+		}
+	}
+}
+
+// ForEachHistoryItem calls fn with every item of the complete watch history,
+// decoding each page as it streams in so memory stays flat however long the
+// history is. It stops at the first error fn returns. WithPeriod limits it
+// to the items watched in a period.
+func (c *Client) ForEachHistoryItem(ctx context.Context, historyType string, fn func(HistoryItem) error, opts ...RequestOption) error {
+	path := "/sync/history"
+	if historyType != "" {
+		path = fmt.Sprintf("/sync/history/%s", historyType)
+	}
+
+	for page := 1; ; page++ {
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("limit", strconv.Itoa(historyPageLimit))
+		applyOptions(params, opts)
+
+		stream := &arrayStream[HistoryItem]{fn: fn}
+		pagination, err := c.getPage(ctx, path+"?"+params.Encode(), stream)
+		if err != nil {
+			return err
+		}
+		if stream.count == 0 || pagination == nil || page >= pagination.PageCount {
+			return nil
+		}
+	}
+}
+
+// GetHistoryAll retrieves the complete watch history across all pages.
+func (c *Client) GetHistoryAll(ctx context.Context, historyType string) ([]HistoryItem, error) {
+	var all []HistoryItem
+	err := c.ForEachHistoryItem(ctx, historyType, func(item HistoryItem) error {
+		all = append(all, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ForEachWatched calls fn with every movie or show the user has watched,
+// streaming the response since Trakt returns the whole list at once.
+// watchedType is "movies" or "shows". WithExtended(ExtendedFull) adds each
+// show's aired episode count and runtime.
+func (c *Client) ForEachWatched(ctx context.Context, watchedType string, fn func(WatchedEntry) error, opts ...RequestOption) error {
+	path := withOptions(fmt.Sprintf("/sync/watched/%s", watchedType), opts)
+	return c.get(ctx, path, &arrayStream[WatchedEntry]{fn: fn})
+}
+
+// ForEachCollected calls fn with every movie or show in the user's
+// collection, streaming the response like ForEachWatched.
+// collectionType is "movies" or "shows".
+func (c *Client) ForEachCollected(ctx context.Context, collectionType string, fn func(CollectionEntry) error) error {
+	return c.get(ctx, fmt.Sprintf("/sync/collection/%s", collectionType), &arrayStream[CollectionEntry]{fn: fn})
+}
+
+// GetWatchlist retrieves the user's watchlist.
+func (c *Client) GetWatchlist(ctx context.Context, watchlistType string, opts ...RequestOption) ([]WatchlistItem, error) {
+	path := "/sync/watchlist"
+	if watchlistType != "" {
+		path = fmt.Sprintf("/sync/watchlist/%s", watchlistType)
+	}
+	path = withOptions(path, opts)
+
+	var items []WatchlistItem
+	if err := c.get(ctx, path, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// AddToWatchlist adds items to the user's watchlist.
+func (c *Client) AddToWatchlist(ctx context.Context, items SyncItems) (*SyncResponse, error) {
+	var resp SyncResponse
+	if err := c.post(ctx, "/sync/watchlist", items, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddToCollection adds items to the user's collection.
+func (c *Client) AddToCollection(ctx context.Context, items SyncItems) (*SyncResponse, error) {
+	var resp SyncResponse
+	if err := c.post(ctx, "/sync/collection", items, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetLists retrieves the signed-in user's personal lists.
+func (c *Client) GetLists(ctx context.Context) ([]List, error) {
+	var lists []List
+	if err := c.get(ctx, userPath("me", "lists"), &lists); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// GetListItems retrieves the items on one of the signed-in user's lists,
+// by Trakt ID or slug.
+func (c *Client) GetListItems(ctx context.Context, listID string) ([]ListItem, error) {
+	var items []ListItem
+	if err := c.get(ctx, userPath("me", "lists/"+url.PathEscape(listID)+"/items"), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CreateList creates a personal list with list's name, description and
+// privacy, and returns it as created.
+func (c *Client) CreateList(ctx context.Context, list List) (*List, error) {
+	body := map[string]string{"name": list.Name, "description": list.Description, "privacy": list.Privacy}
+	if list.Privacy == "" {
+		body["privacy"] = "private"
+	}
+
+	var created List
+	if err := c.post(ctx, userPath("me", "lists"), body, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// AddListItems adds items to one of the signed-in user's lists, by Trakt ID
+// or slug.
+func (c *Client) AddListItems(ctx context.Context, listID string, items SyncItems) (*SyncResponse, error) {
+	var resp SyncResponse
+	if err := c.post(ctx, userPath("me", "lists/"+url.PathEscape(listID)+"/items"), items, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetLastActivities retrieves when each part of the user's account last
+// changed.
+func (c *Client) GetLastActivities(ctx context.Context) (*LastActivities, error) {
+	var activities LastActivities
+	if err := c.get(ctx, "/sync/last_activities", &activities); err != nil {
+		return nil, err
+	}
+	return &activities, nil
+}
+
+// GetShowProgress retrieves the user's watched progress for a show,
+// including which episodes of each season they've watched.
+func (c *Client) GetShowProgress(ctx context.Context, showID string) (*ShowProgress, error) {
+	path := fmt.Sprintf("/shows/%s/progress/watched", showID)
+
+	var progress ShowProgress
+	if err := c.get(ctx, path, &progress); err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+// GetMyShowsCalendar retrieves the episodes of the user's shows airing in
+// the days starting on start's date, which Trakt reads as a UTC date.
+// Trakt allows at most 33 days.
+func (c *Client) GetMyShowsCalendar(ctx context.Context, start time.Time, days int, opts ...RequestOption) ([]CalendarEntry, error) {
+	path := withOptions(fmt.Sprintf("/calendars/my/shows/%s/%d", start.Format("2006-01-02"), days), opts)
+
+	var entries []CalendarEntry
+	if err := c.get(ctx, path, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetMyMoviesCalendar retrieves the movies on the user's watchlist or
+// collection released in the days starting on start's date. Trakt allows
+// at most 33 days.
+func (c *Client) GetMyMoviesCalendar(ctx context.Context, start time.Time, days int, opts ...RequestOption) ([]MovieCalendarEntry, error) {
+	path := withOptions(fmt.Sprintf("/calendars/my/movies/%s/%d", start.Format("2006-01-02"), days), opts)
+
+	var entries []MovieCalendarEntry
+	if err := c.get(ctx, path, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// hiddenPageLimit is the page size used when listing hidden items.
+const hiddenPageLimit = 100
+
+// GetHidden retrieves everything the user hid from a section of Trakt:
+// "calendar", "progress_watched", "progress_collected" or
+// "recommendations". itemType narrows it to "show", "movie" or "season".
+func (c *Client) GetHidden(ctx context.Context, section, itemType string) ([]HiddenItem, error) {
+	var all []HiddenItem
+	for page := 1; ; page++ {
+		params := url.Values{}
+		if itemType != "" {
+			params.Set("type", itemType)
+		}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("limit", strconv.Itoa(hiddenPageLimit))
+
+		var items []HiddenItem
+		pagination, err := c.getPage(ctx, fmt.Sprintf("/users/hidden/%s?%s", section, params.Encode()), &items)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) == 0 || pagination == nil || page >= pagination.PageCount {
+			return all, nil
+		}
+	}
+}
+
+// userPath routes rest to a user's profile data. user is a username or
+// slug, or "me" for the signed-in user. Other users' data is readable if
+// their profile is public or the signed-in user follows them.
+func userPath(user, rest string) string {
+	return fmt.Sprintf("/users/%s/%s", url.PathEscape(user), rest)
+}
+
+// ForEachUserWatched is ForEachWatched for any user's watched list.
+func (c *Client) ForEachUserWatched(ctx context.Context, user, watchedType string, fn func(WatchedEntry) error, opts ...RequestOption) error {
+	path := withOptions(userPath(user, "watched/"+watchedType), opts)
+	return c.get(ctx, path, &arrayStream[WatchedEntry]{fn: fn})
+}
+
+// GetUserRatings retrieves a user's ratings. ratingType is "movies",
+// "shows", "seasons" or "episodes", or empty for all of them.
+func (c *Client) GetUserRatings(ctx context.Context, user, ratingType string) ([]Rating, error) {
+	rest := "ratings"
+	if ratingType != "" {
+		rest += "/" + ratingType
+	}
+
+	var ratings []Rating
+	if err := c.get(ctx, userPath(user, rest), &ratings); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// GetRecommendedShows retrieves up to limit shows Trakt recommends for the
+// user, leaving out shows they've collected.
+func (c *Client) GetRecommendedShows(ctx context.Context, limit int, opts ...RequestOption) ([]Show, error) {
+	var shows []Show
+	if err := c.get(ctx, recommendationsPath("shows", limit, opts), &shows); err != nil {
+		return nil, err
+	}
+	return shows, nil
+}
+
+// GetRecommendedMovies retrieves up to limit movies Trakt recommends for
+// the user, leaving out movies they've collected.
+func (c *Client) GetRecommendedMovies(ctx context.Context, limit int, opts ...RequestOption) ([]Movie, error) {
+	var movies []Movie
+	if err := c.get(ctx, recommendationsPath("movies", limit, opts), &movies); err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+func recommendationsPath(kind string, limit int, opts []RequestOption) string {
+	params := url.Values{}
+	params.Set("ignore_collected", "true")
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	applyOptions(params, opts)
+	return fmt.Sprintf("/recommendations/%s?%s", kind, params.Encode())
+}
+
 // AddToHistory adds items to watch history.
 func (c *Client) AddToHistory(ctx context.Context, item WatchedItem) (*SyncResponse, error) {
 	var resp SyncResponse
@@ -148,6 +807,15 @@ func (c *Client) AddToHistory(ctx context.Context, item WatchedItem) (*SyncRespo
 	return &resp, nil
 }
 
+// AddRatings rates items, replacing any earlier ratings of them.
+func (c *Client) AddRatings(ctx context.Context, item RatingItem) (*SyncResponse, error) {
+	var resp SyncResponse
+	if err := c.post(ctx, "/sync/ratings", item, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // RemoveFromHistory removes items from watch history.
 func (c *Client) RemoveFromHistory(ctx context.Context, item WatchedItem) (*SyncResponse, error) {
 	var resp SyncResponse
@@ -157,24 +825,76 @@ func (c *Client) RemoveFromHistory(ctx context.Context, item WatchedItem) (*Sync
 	return &resp, nil
 }
 
+// ScrobbleStart tells Trakt the user started or resumed playing an item.
+func (c *Client) ScrobbleStart(ctx context.Context, s Scrobble) (*ScrobbleResponse, error) {
+	return c.scrobble(ctx, "start", s)
+}
+
+// ScrobblePause tells Trakt the user paused an item.
+func (c *Client) ScrobblePause(ctx context.Context, s Scrobble) (*ScrobbleResponse, error) {
+	return c.scrobble(ctx, "pause", s)
+}
+
+// ScrobbleStop tells Trakt the user stopped playing an item. Trakt adds
+// it to history if at least 80% was played, and otherwise keeps the
+// progress as if paused. Stopping an item Trakt just scrobbled fails with
+// ErrConflict.
+func (c *Client) ScrobbleStop(ctx context.Context, s Scrobble) (*ScrobbleResponse, error) {
+	return c.scrobble(ctx, "stop", s)
+}
+
+func (c *Client) scrobble(ctx context.Context, action string, s Scrobble) (*ScrobbleResponse, error) {
+	var resp ScrobbleResponse
+	if err := c.post(ctx, "/scrobble/"+action, s, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // GetShow retrieves a show by Trakt ID or slug.
-func (c *Client) GetShow(ctx context.Context, id string) (*Show, error) {
-	path := fmt.Sprintf("/shows/%s", id)
+func (c *Client) GetShow(ctx context.Context, id string, opts ...RequestOption) (*Show, error) {
+	path := withOptions(fmt.Sprintf("/shows/%s", id), opts)
 
 	var show Show
-	if err := c.get(ctx, path, &show); err != nil {
+	if err := c.getCached(ctx, path, &show); err != nil {
 		return nil, err
 	}
 
 	return &show, nil
 }
 
+// GetAliases retrieves the other titles of a show or movie. kind is "shows"
+// or "movies".
+func (c *Client) GetAliases(ctx context.Context, kind, id string) ([]Alias, error) {
+	path := fmt.Sprintf("/%s/%s/aliases", kind, id)
+
+	var aliases []Alias
+	if err := c.getCached(ctx, path, &aliases); err != nil {
+		return nil, err
+	}
+
+	return aliases, nil
+}
+
+// GetSeasons retrieves a show's seasons, specials first. Request
+// WithExtended(ExtendedEpisodes) to include their episodes.
+func (c *Client) GetSeasons(ctx context.Context, showID string, opts ...RequestOption) ([]Season, error) {
+	path := withOptions(fmt.Sprintf("/shows/%s/seasons", showID), opts)
+
+	var seasons []Season
+	if err := c.getCached(ctx, path, &seasons); err != nil {
+		return nil, err
+	}
+
+	return seasons, nil
+}
+
 // GetEpisode retrieves a specific episode of a show.
-func (c *Client) GetEpisode(ctx context.Context, showID string, season, episode int) (*Episode, error) {
-	path := fmt.Sprintf("/shows/%s/seasons/%d/episodes/%d", showID, season, episode)
+func (c *Client) GetEpisode(ctx context.Context, showID string, season, episode int, opts ...RequestOption) (*Episode, error) {
+	path := withOptions(fmt.Sprintf("/shows/%s/seasons/%d/episodes/%d", showID, season, episode), opts)
 
 	var ep Episode
-	if err := c.get(ctx, path, &ep); err != nil {
+	if err := c.getCached(ctx, path, &ep); err != nil {
 		return nil, err
 	}
 
@@ -182,11 +902,11 @@ func (c *Client) GetEpisode(ctx context.Context, showID string, season, episode
 }
 
 // GetMovie retrieves a movie by Trakt ID or slug.
-func (c *Client) GetMovie(ctx context.Context, id string) (*Movie, error) {
-	path := fmt.Sprintf("/movies/%s", id)
+func (c *Client) GetMovie(ctx context.Context, id string, opts ...RequestOption) (*Movie, error) {
+	path := withOptions(fmt.Sprintf("/movies/%s", id), opts)
 
 	var movie Movie
-	if err := c.get(ctx, path, &movie); err != nil {
+	if err := c.getCached(ctx, path, &movie); err != nil {
 		return nil, err
 	}
 
@@ -195,8 +915,9 @@ func (c *Client) GetMovie(ctx context.Context, id string) (*Movie, error) {
 
 // GetDeviceCode initiates device authentication.
 func (c *Client) GetDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	clientID, _ := c.credentials()
 	body := map[string]string{
-		"client_id": c.config.ClientID,
+		"client_id": clientID,
 	}
 
 	var code DeviceCode
@@ -207,67 +928,457 @@ func (c *Client) GetDeviceCode(ctx context.Context) (*DeviceCode, error) {
 	return &code, nil
 }
 
+// RedirectURI returns the callback URI used by the browser authorization flow.
+func (c *Client) RedirectURI() string {
+	if c.config.RedirectURI != "" {
+		return c.config.RedirectURI
+	}
+	return DefaultRedirectURI
+}
+
+// AuthorizationURL builds the browser URL for an authorization-code request
+// protected by PKCE (RFC 7636) with an S256 code challenge.
+func (c *Client) AuthorizationURL(redirectURI, state, codeChallenge string) string {
+	clientID, _ := c.credentials()
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", clientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("state", state)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	authorizeURL := AuthorizeURL
+	if c.config.OAuthURL != "" {
+		authorizeURL = strings.TrimRight(c.config.OAuthURL, "/") + "/oauth/authorize"
+	}
+	return authorizeURL + "?" + q.Encode()
+}
+
+// ExchangeCode trades an authorization code from the browser flow for tokens.
+func (c *Client) ExchangeCode(ctx context.Context, code, redirectURI, codeVerifier string) (*Token, error) {
+	clientID, clientSecret := c.credentials()
+	body := map[string]string{
+		"code":          code,
+		"client_id":     clientID,
+		"client_secret": clientSecret,
+		"redirect_uri":  redirectURI,
+		"grant_type":    "authorization_code",
+		"code_verifier": codeVerifier,
+	}
+
+	var token Token
+	if err := c.post(ctx, "/oauth/token", body, &token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// NewPKCE generates a PKCE code verifier and its S256 challenge.
+func NewPKCE() (verifier, challenge string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("generate code verifier: %w", err)
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
 // PollForToken polls for OAuth token after device code authorization.
+// Trakt reports the authorization state through status codes, which are
+// mapped to ErrAuthorizationPending, ErrSlowDown, ErrExpiredToken,
+// ErrAccessDenied, ErrInvalidDeviceCode, and ErrDeviceCodeUsed.
 func (c *Client) PollForToken(ctx context.Context, deviceCode string) (*Token, error) {
+	clientID, clientSecret := c.credentials()
 	body := map[string]string{
 		"code":          deviceCode,
-		"client_id":     c.config.ClientID,
-		"client_secret": c.config.ClientSecret,
+		"client_id":     clientID,
+		"client_secret": clientSecret,
 	}
 
 	var token Token
 	if err := c.post(ctx, "/oauth/device/token", body, &token); err != nil {
-		return nil, err
+		return nil, deviceTokenError(err)
 	}
 
 	return &token, nil
 }
 
+// WaitForDeviceToken polls until the user authorizes the device code,
+// honoring the polling interval (and any slow_down requests) and giving up
+// when the code expires.
+func (c *Client) WaitForDeviceToken(ctx context.Context, code *DeviceCode) (*Token, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+	defer cancel()
+
+	for {
+		token, err := c.PollForToken(ctx, code.DeviceCode)
+		switch {
+		case err == nil:
+			return token, nil
+		case errors.Is(err, ErrSlowDown):
+			interval += 5 * time.Second
+		case !errors.Is(err, ErrAuthorizationPending):
+			return nil, err
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, ErrExpiredToken
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// deviceTokenError maps device token status codes to their OAuth errors.
+func deviceTokenError(err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch apiErr.StatusCode {
+	case http.StatusBadRequest:
+		return ErrAuthorizationPending
+	case http.StatusNotFound:
+		return ErrInvalidDeviceCode
+	case http.StatusConflict:
+		return ErrDeviceCodeUsed
+	case http.StatusGone:
+		return ErrExpiredToken
+	case http.StatusTeapot:
+		return ErrAccessDenied
+	case http.StatusTooManyRequests:
+		return ErrSlowDown
+	default:
+		return err
+	}
+}
+
 // HTTP helpers
 
 func (c *Client) get(ctx context.Context, path string, result any) error {
+	_, err := c.do(ctx, http.MethodGet, path, nil, result)
+	return err
+}
+
+// getCached is get for responses that don't depend on the user, served from
+// the lookup cache when fresh.
+func (c *Client) getCached(ctx context.Context, path string, result any) error {
+	ttl := c.lookups.getTTL()
+	if ttl <= 0 {
+		return c.get(ctx, path, result)
+	}
+
+	c.mu.RLock()
+	disk := c.disk
+	c.mu.RUnlock()
+
+	data, ok := c.lookups.get(path)
+	c.lookupStats.record(ok)
+	if ok {
+		return json.Unmarshal(data, result)
+	}
+	if disk != nil {
+		data, ok := disk.get(path)
+		c.diskStats.record(ok)
+		if ok {
+			if err := json.Unmarshal(data, result); err == nil {
+				c.lookups.put(path, data)
+				return nil
+			}
+		}
+	}
+
+	if err := c.get(ctx, path, result); err != nil {
+		return err
+	}
+	if data, err := json.Marshal(result); err == nil {
+		c.lookups.put(path, data)
+		if disk != nil {
+			disk.put(path, data, ttl)
+		}
+	}
+	return nil
+}
+
+// getPage is get for paginated endpoints.
+func (c *Client) getPage(ctx context.Context, path string, result any) (*Pagination, error) {
 	return c.do(ctx, http.MethodGet, path, nil, result)
 }
 
 func (c *Client) post(ctx context.Context, path string, body any, result any) error {
-	return c.do(ctx, http.MethodPost, path, body, result)
+	_, err := c.do(ctx, http.MethodPost, path, body, result)
+	return err
 }
 
-func (c *Client) do(ctx context.Context, method, path string, body any, result any) error {
-	var bodyReader io.Reader
+// do sends an API request, refreshing the access token first if it is about
+// to expire, and once more followed by a single retry if Trakt rejects it.
+// The returned pagination is nil unless the endpoint is paginated.
+func (c *Client) do(ctx context.Context, method, path string, body any, result any) (*Pagination, error) {
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
+		var err error
+		data, err = json.Marshal(body)
 		if err != nil {
-			return fmt.Errorf("marshal body: %w", err)
+			return nil, fmt.Errorf("marshal body: %w", err)
+		}
+	}
+
+	// Token endpoints authenticate with the request body, not the bearer token
+	if strings.HasPrefix(path, "/oauth/") {
+		header, err := c.send(ctx, method, path, data, "", result)
+		return parsePagination(header), err
+	}
+
+	accessToken, needsRefresh := c.tokenState()
+	if needsRefresh {
+		if err := c.refreshAccessToken(ctx, accessToken); err != nil {
+			c.logger.Warn("proactive token refresh failed", "error", err)
 		}
+		accessToken, _ = c.tokenState()
+	}
+
+	header, err := c.sendWithRetry(ctx, method, path, data, accessToken, result)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || !c.canRefresh() {
+		return parsePagination(header), err
+	}
+
+	if refreshErr := c.refreshAccessToken(ctx, accessToken); refreshErr != nil {
+		c.logger.Warn("token refresh failed", "error", refreshErr)
+		return nil, err
+	}
+	accessToken, _ = c.tokenState()
+	header, err = c.sendWithRetry(ctx, method, path, data, accessToken, result)
+	return parsePagination(header), err
+}
+
+// sendWithRetry sends a request, waiting out 429 responses that carry a
+// Retry-After header. Waits longer than maxRetryWait, or past the context's
+// deadline, return the rate-limit error instead.
+func (c *Client) sendWithRetry(ctx context.Context, method, path string, data []byte, accessToken string, result any) (http.Header, error) {
+	c.mu.RLock()
+	retries := c.maxRetries
+	c.mu.RUnlock()
+
+	for attempt := 0; ; attempt++ {
+		header, err := c.send(ctx, method, path, data, accessToken, result)
+		var apiErr *APIError
+		if attempt >= retries || !errors.As(err, &apiErr) || !apiErr.IsRateLimited() {
+			return header, err
+		}
+
+		wait, ok := parseRetryAfter(header, time.Now())
+		if !ok || wait > maxRetryWait {
+			return header, err
+		}
+		if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Until(deadline) < wait {
+			return header, err
+		}
+
+		c.logger.Warn("trakt rate limit hit, retrying", "path", path, "wait", wait, "attempt", attempt+1)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return header, err
+		}
+	}
+}
+
+// responseBody returns a reader over a response body, decompressing it if
+// the server gzipped it.
+func responseBody(resp *http.Response) (io.ReadCloser, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return io.NopCloser(resp.Body), nil
+	}
+	return gzip.NewReader(resp.Body)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// parsePagination reads Trakt's X-Pagination-* headers, returning nil when
+// the response isn't paginated.
+func parsePagination(header http.Header) *Pagination {
+	if header == nil || header.Get("X-Pagination-Page") == "" {
+		return nil
+	}
+	atoi := func(name string) int {
+		n, _ := strconv.Atoi(header.Get(name))
+		return n
+	}
+	return &Pagination{
+		Page:      atoi("X-Pagination-Page"),
+		Limit:     atoi("X-Pagination-Limit"),
+		PageCount: atoi("X-Pagination-Page-Count"),
+		ItemCount: atoi("X-Pagination-Item-Count"),
+	}
+}
+
+// tokenState returns the current access token and whether it is close
+// enough to expiry to refresh before use.
+func (c *Client) tokenState() (accessToken string, needsRefresh bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	needsRefresh = c.config.RefreshToken != "" && !c.tokenExpiry.IsZero() &&
+		time.Until(c.tokenExpiry) < refreshMargin
+	return c.config.AccessToken, needsRefresh
+}
+
+func (c *Client) canRefresh() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config.RefreshToken != "" && c.config.ClientSecret != ""
+}
+
+// RefreshToken exchanges the refresh token for new credentials, replacing
+// and persisting the client's tokens.
+func (c *Client) RefreshToken(ctx context.Context) (*Token, error) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	return c.exchangeRefreshToken(ctx)
+}
+
+// refreshAccessToken refreshes on behalf of a failing or expiring request.
+// staleToken is the access token the caller saw; if another request has
+// already replaced it, the refresh is skipped.
+func (c *Client) refreshAccessToken(ctx context.Context, staleToken string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	c.mu.RLock()
+	current := c.config.AccessToken
+	c.mu.RUnlock()
+	if current != staleToken {
+		return nil
+	}
+
+	_, err := c.exchangeRefreshToken(ctx)
+	return err
+}
+
+// exchangeRefreshToken performs the refresh_token grant. Callers must hold c.refreshMu.
+func (c *Client) exchangeRefreshToken(ctx context.Context) (*Token, error) {
+	c.mu.RLock()
+	body := map[string]string{
+		"refresh_token": c.config.RefreshToken,
+		"client_id":     c.config.ClientID,
+		"client_secret": c.config.ClientSecret,
+		"redirect_uri":  oobRedirectURI,
+		"grant_type":    "refresh_token",
+	}
+	c.mu.RUnlock()
+
+	if body["refresh_token"] == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal body: %w", err)
+	}
+
+	var token Token
+	if _, err := c.send(ctx, http.MethodPost, "/oauth/token", data, "", &token); err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("refreshed trakt access token")
+	c.SetToken(&token)
+	return &token, nil
+}
+
+// send performs a single HTTP round trip.
+func (c *Client) send(ctx context.Context, method, path string, data []byte, accessToken string, result any) (http.Header, error) {
+	var bodyReader io.Reader
+	if data != nil {
 		bodyReader = bytes.NewReader(data)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	// Set required headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("trakt-api-version", APIVersion)
-	req.Header.Set("trakt-api-key", c.config.ClientID)
+	clientID, _ := c.credentials()
+	req.Header.Set("trakt-api-key", clientID)
+	// Requested explicitly (rather than left to http.Transport) so responses
+	// are decompressed the same way whatever transport or middleware is used
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
 
-	if c.config.AccessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.config.AccessToken)
+	stream, streaming := result.(streamResult)
+
+	cached, haveCached := etagEntry{}, false
+	if method == http.MethodGet && !streaming {
+		if cached, haveCached = c.etags.get(path); haveCached {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
 	}
 
 	c.logger.Debug("trakt request", "method", method, "path", path)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.roundTrip(req)
 	if err != nil {
-		return fmt.Errorf("http request: %w", err)
+		return nil, fmt.Errorf("http request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.recordRateLimit(resp.Header)
+
+	body, err := responseBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	defer body.Close()
+
+	if streaming && resp.StatusCode < 300 {
+		return resp.Header, stream.decodeStream(body)
+	}
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(body)
 	if err != nil {
-		return fmt.Errorf("read response: %w", err)
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if haveCached {
+		c.etagStats.record(resp.StatusCode == http.StatusNotModified)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && haveCached:
+		c.logger.Debug("trakt cache hit", "path", path)
+		respBody = cached.body
+	case method == http.MethodGet && resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		c.etags.put(path, resp.Header.Get("ETag"), respBody)
 	}
 
 	if resp.StatusCode >= 400 {
@@ -278,14 +1389,14 @@ func (c *Client) do(ctx context.Context, method, path string, body any, result a
 			"path", path,
 		)
 		// Return sanitized error - don't leak response body which may contain tokens
-		return &APIError{StatusCode: resp.StatusCode, Method: method, Path: path}
+		return resp.Header, newAPIError(method, path, resp, respBody)
 	}
 
 	if result != nil && len(respBody) > 0 {
 		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("unmarshal response: %w", err)
+			return resp.Header, fmt.Errorf("unmarshal response: %w", err)
 		}
 	}
 
-	return nil
+	return resp.Header, nil
 }
@@ -1,12 +1,20 @@
 package trakt
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // newTestClient creates a client with a mock server
@@ -136,6 +144,58 @@ func TestClient_GetHistory(t *testing.T) {
 	})
 }
 
+func TestClient_GetWatchlist(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync/watchlist/movies" {
+			t.Errorf("expected /sync/watchlist/movies, got %s", r.URL.Path)
+		}
+
+		items := []WatchlistItem{
+			{Rank: 1, Type: "movie", Movie: &Movie{Title: "Dune", Year: 2021}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(items)
+	})
+
+	client := newTestClient(t, handler)
+
+	items, err := client.GetWatchlist(context.Background(), "movies")
+	if err != nil {
+		t.Fatalf("GetWatchlist failed: %v", err)
+	}
+
+	if len(items) != 1 || items[0].Movie.Title != "Dune" {
+		t.Errorf("unexpected watchlist: %+v", items)
+	}
+}
+
+func TestClient_GetShowProgress(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shows/1388/progress/watched" {
+			t.Errorf("expected /shows/1388/progress/watched, got %s", r.URL.Path)
+		}
+
+		progress := ShowProgress{
+			Aired:       62,
+			Completed:   10,
+			NextEpisode: &Episode{Season: 2, Number: 4, Title: "Down"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(progress)
+	})
+
+	client := newTestClient(t, handler)
+
+	progress, err := client.GetShowProgress(context.Background(), "1388")
+	if err != nil {
+		t.Fatalf("GetShowProgress failed: %v", err)
+	}
+
+	if progress.NextEpisode == nil || progress.NextEpisode.Number != 4 {
+		t.Errorf("unexpected next episode: %+v", progress.NextEpisode)
+	}
+}
+
 func TestClient_AddToHistory(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -210,6 +270,46 @@ func TestClient_RemoveFromHistory(t *testing.T) {
 	}
 }
 
+func TestClient_Scrobble(t *testing.T) {
+	var paths []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var s Scrobble
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			t.Errorf("failed to parse request body: %v", err)
+		}
+		if s.Show == nil || s.Show.IDs.Trakt != 1388 || s.Episode == nil || s.Episode.Season != 1 || s.Episode.Number != 2 {
+			t.Errorf("unexpected scrobble %+v", s)
+		}
+		if r.URL.Path == "/scrobble/stop" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"watched_at":"2026-10-16T20:00:00.000Z","expires_at":"2026-10-16T21:00:00.000Z"}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"id":0,"action":%q,"progress":%v}`, strings.TrimPrefix(r.URL.Path, "/scrobble/"), s.Progress)
+	})
+
+	client := newTestClient(t, handler)
+	s := Scrobble{Show: &Show{IDs: ShowIDs{Trakt: 1388}}, Episode: &Episode{Season: 1, Number: 2}, Progress: 12.5}
+
+	resp, err := client.ScrobbleStart(context.Background(), s)
+	if err != nil {
+		t.Fatalf("ScrobbleStart failed: %v", err)
+	}
+	if resp.Action != "start" || resp.Progress != 12.5 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if _, err := client.ScrobblePause(context.Background(), s); err != nil {
+		t.Fatalf("ScrobblePause failed: %v", err)
+	}
+	if _, err := client.ScrobbleStop(context.Background(), s); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if got := strings.Join(paths, " "); got != "/scrobble/start /scrobble/pause /scrobble/stop" {
+		t.Errorf("unexpected requests %s", got)
+	}
+}
+
 func TestClient_GetShow(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/shows/1388" {
@@ -362,6 +462,581 @@ func TestClient_PollForToken(t *testing.T) {
 	}
 }
 
+func TestClient_PollForToken_DeviceErrors(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       error
+	}{
+		{400, ErrAuthorizationPending},
+		{404, ErrInvalidDeviceCode},
+		{409, ErrDeviceCodeUsed},
+		{410, ErrExpiredToken},
+		{418, ErrAccessDenied},
+		{429, ErrSlowDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want.Error(), func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+
+			_, err := client.PollForToken(context.Background(), "device123")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestClient_WaitForDeviceToken(t *testing.T) {
+	var polls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if polls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadRequest) // authorization_pending
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Token{AccessToken: "access123", RefreshToken: "refresh456"})
+	})
+
+	client := newTestClient(t, handler)
+
+	token, err := client.WaitForDeviceToken(context.Background(), &DeviceCode{DeviceCode: "device123", ExpiresIn: 30})
+	if err != nil {
+		t.Fatalf("WaitForDeviceToken failed: %v", err)
+	}
+	if token.AccessToken != "access123" {
+		t.Errorf("expected access123, got %s", token.AccessToken)
+	}
+	if got := polls.Load(); got != 2 {
+		t.Errorf("expected 2 polls, got %d", got)
+	}
+}
+
+func TestClient_WaitForDeviceToken_Denied(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	_, err := client.WaitForDeviceToken(context.Background(), &DeviceCode{DeviceCode: "device123", ExpiresIn: 30})
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("expected ErrAccessDenied, got %v", err)
+	}
+}
+
+func TestClient_SetToken(t *testing.T) {
+	var gotAuth string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	client.SetToken(&Token{AccessToken: "fresh-token"})
+	if _, err := client.GetHistory(context.Background(), "", 1); err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if gotAuth != "Bearer fresh-token" {
+		t.Errorf("expected new token in Authorization header, got %q", gotAuth)
+	}
+}
+
+// memoryTokenStore is an in-memory TokenStore for tests.
+type memoryTokenStore struct {
+	token *Token
+}
+
+func (m *memoryTokenStore) Load() (*Token, error) { return m.token, nil }
+
+func (m *memoryTokenStore) Save(token *Token) error {
+	m.token = token
+	return nil
+}
+
+func TestClient_RefreshOnUnauthorized(t *testing.T) {
+	var refreshes atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			refreshes.Add(1)
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["grant_type"] != "refresh_token" || body["refresh_token"] != "old-refresh" {
+				t.Errorf("unexpected refresh body: %v", body)
+			}
+			_ = json.NewEncoder(w).Encode(Token{AccessToken: "new-access", RefreshToken: "new-refresh"})
+		case "/sync/history":
+			if r.Header.Get("Authorization") != "Bearer new-access" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+
+	client := newTestClient(t, handler)
+	client.config.ClientSecret = "secret"
+	client.config.RefreshToken = "old-refresh"
+	store := &memoryTokenStore{}
+	client.store = store
+
+	if _, err := client.GetHistory(context.Background(), "", 10); err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if got := refreshes.Load(); got != 1 {
+		t.Errorf("expected 1 refresh, got %d", got)
+	}
+	if store.token == nil || store.token.RefreshToken != "new-refresh" {
+		t.Errorf("expected rotated token to be persisted, got %+v", store.token)
+	}
+}
+
+func TestClient_ProactiveRefresh(t *testing.T) {
+	var refreshed atomic.Bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/oauth/token" {
+			refreshed.Store(true)
+			_ = json.NewEncoder(w).Encode(Token{AccessToken: "new-access", RefreshToken: "new-refresh"})
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer new-access" {
+			t.Errorf("expected refreshed token, got %q", got)
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	client := newTestClient(t, handler)
+	client.config.ClientSecret = "secret"
+	// Issued three months ago with a three month lifetime, so about to expire
+	client.applyToken(&Token{
+		AccessToken:  "old-access",
+		RefreshToken: "old-refresh",
+		ExpiresIn:    7776000,
+		CreatedAt:    time.Now().Add(-7776000*time.Second + time.Hour).Unix(),
+	})
+
+	if _, err := client.GetHistory(context.Background(), "", 10); err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if !refreshed.Load() {
+		t.Error("expected token to be refreshed before the request")
+	}
+}
+
+func TestClient_RefreshToken(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" {
+			t.Errorf("expected /oauth/token, got %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("refresh should not send the bearer token")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Token{AccessToken: "new-access", RefreshToken: "new-refresh"})
+	}))
+	client.config.RefreshToken = "old-refresh"
+
+	token, err := client.RefreshToken(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	if token.AccessToken != "new-access" {
+		t.Errorf("expected new-access, got %s", token.AccessToken)
+	}
+	if got, _ := client.tokenState(); got != "new-access" {
+		t.Errorf("expected client to use the new token, got %q", got)
+	}
+}
+
+func TestClient_RefreshToken_NoRefreshToken(t *testing.T) {
+	client := NewClient(Config{ClientID: "id"}, nil)
+
+	if _, err := client.RefreshToken(context.Background()); !errors.Is(err, ErrNoRefreshToken) {
+		t.Errorf("expected ErrNoRefreshToken, got %v", err)
+	}
+}
+
+func TestClient_SetTokenStore_LoadsStoredToken(t *testing.T) {
+	client := NewClient(Config{ClientID: "id", AccessToken: "env-token"}, nil)
+
+	if err := client.SetTokenStore(&memoryTokenStore{token: &Token{AccessToken: "stored-token"}}); err != nil {
+		t.Fatalf("SetTokenStore failed: %v", err)
+	}
+	if got, _ := client.tokenState(); got != "stored-token" {
+		t.Errorf("expected stored token to win, got %q", got)
+	}
+}
+
+func TestClient_ReloadToken(t *testing.T) {
+	client := NewClient(Config{ClientID: "id"}, nil)
+	if err := client.ReloadToken(); err != nil {
+		t.Fatalf("ReloadToken without a store failed: %v", err)
+	}
+
+	store := &memoryTokenStore{token: &Token{AccessToken: "first"}}
+	if err := client.SetTokenStore(store); err != nil {
+		t.Fatalf("SetTokenStore failed: %v", err)
+	}
+
+	// Another process signs in and rewrites the store
+	store.token = &Token{AccessToken: "second"}
+	if err := client.ReloadToken(); err != nil {
+		t.Fatalf("ReloadToken failed: %v", err)
+	}
+	if got, _ := client.tokenState(); got != "second" {
+		t.Errorf("expected reloaded token, got %q", got)
+	}
+}
+
+func TestClient_SetCredentials(t *testing.T) {
+	var gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("trakt-api-key")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{APIURL: server.URL}, nil)
+	if client.IsConfigured() {
+		t.Fatal("expected client without credentials to be unconfigured")
+	}
+
+	client.SetCredentials("new-id", "new-secret")
+	if !client.IsConfigured() {
+		t.Fatal("expected client to be configured after SetCredentials")
+	}
+	if _, err := client.Search(context.Background(), "test", ""); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if gotKey != "new-id" {
+		t.Errorf("expected new client ID in trakt-api-key header, got %q", gotKey)
+	}
+}
+
+func TestClient_AuthorizationURL(t *testing.T) {
+	client := NewClient(Config{ClientID: "id"}, nil)
+
+	u, err := url.Parse(client.AuthorizationURL(client.RedirectURI(), "state123", "challenge"))
+	if err != nil {
+		t.Fatalf("invalid URL: %v", err)
+	}
+	q := u.Query()
+	if q.Get("redirect_uri") != DefaultRedirectURI {
+		t.Errorf("expected default redirect URI, got %s", q.Get("redirect_uri"))
+	}
+	if q.Get("client_id") != "id" || q.Get("state") != "state123" || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("unexpected query: %s", u.RawQuery)
+	}
+}
+
+// pagedHistoryHandler serves itemCount history items in pages, with Trakt's pagination headers.
+func pagedHistoryHandler(t *testing.T, itemCount int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if page < 1 || limit < 1 {
+			t.Errorf("expected page and limit, got %s", r.URL.RawQuery)
+			return
+		}
+		pageCount := (itemCount + limit - 1) / limit
+
+		var items []HistoryItem
+		for id := (page-1)*limit + 1; id <= page*limit && id <= itemCount; id++ {
+			items = append(items, HistoryItem{ID: int64(id)})
+		}
+
+		w.Header().Set("X-Pagination-Page", strconv.Itoa(page))
+		w.Header().Set("X-Pagination-Limit", strconv.Itoa(limit))
+		w.Header().Set("X-Pagination-Page-Count", strconv.Itoa(pageCount))
+		w.Header().Set("X-Pagination-Item-Count", strconv.Itoa(itemCount))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(items)
+	})
+}
+
+func TestClient_GetHistoryPage(t *testing.T) {
+	client := newTestClient(t, pagedHistoryHandler(t, 25))
+
+	page, err := client.GetHistoryPage(context.Background(), "", 2, 10)
+	if err != nil {
+		t.Fatalf("GetHistoryPage failed: %v", err)
+	}
+
+	want := Pagination{Page: 2, Limit: 10, PageCount: 3, ItemCount: 25}
+	if page.Pagination != want {
+		t.Errorf("expected %+v, got %+v", want, page.Pagination)
+	}
+	if len(page.Items) != 10 || page.Items[0].ID != 11 {
+		t.Errorf("unexpected items: %+v", page.Items)
+	}
+}
+
+func TestClient_GetHistoryAll(t *testing.T) {
+	client := newTestClient(t, pagedHistoryHandler(t, 250))
+
+	history, err := client.GetHistoryAll(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetHistoryAll failed: %v", err)
+	}
+	if len(history) != 250 {
+		t.Fatalf("expected 250 items, got %d", len(history))
+	}
+	if history[249].ID != 250 {
+		t.Errorf("expected last item ID 250, got %d", history[249].ID)
+	}
+}
+
+func TestClient_ForEachHistoryPage_StopsOnError(t *testing.T) {
+	client := newTestClient(t, pagedHistoryHandler(t, 250))
+
+	errStop := errors.New("stop")
+	pages := 0
+	err := client.ForEachHistoryPage(context.Background(), "", func(*HistoryPage) error {
+		pages++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected errStop, got %v", err)
+	}
+	if pages != 1 {
+		t.Errorf("expected iteration to stop after 1 page, got %d", pages)
+	}
+}
+
+func TestClient_GetShow_ExtendedFull(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("extended"); got != "full" {
+			t.Errorf("expected extended=full, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"title": "Breaking Bad", "year": 2008, "ids": {"trakt": 1388},
+			"overview": "A chemistry teacher turns to crime.", "runtime": 45,
+			"status": "ended", "first_aired": "2008-01-20T02:00:00.000Z",
+			"rating": 9.3, "votes": 50000, "genres": ["drama", "crime"]
+		}`))
+	}))
+
+	show, err := client.GetShow(context.Background(), "breaking-bad", WithExtended(ExtendedFull))
+	if err != nil {
+		t.Fatalf("GetShow failed: %v", err)
+	}
+	if show.Runtime != 45 || show.Status != "ended" || show.Votes != 50000 || len(show.Genres) != 2 {
+		t.Errorf("extended fields not decoded: %+v", show)
+	}
+	if show.FirstAired == nil || show.FirstAired.Year() != 2008 {
+		t.Errorf("expected first_aired in 2008, got %v", show.FirstAired)
+	}
+}
+
+func TestClient_Search_ExtendedFull(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("query") != "dune" || q.Get("extended") != "full" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	if _, err := client.Search(context.Background(), "dune", "movie", WithExtended(ExtendedFull)); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+}
+
+func TestClient_Search_Paging(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("page") != "2" || q.Get("limit") != "25" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	if _, err := client.Search(context.Background(), "dune", "movie", WithPage(2), WithLimit(25)); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+}
+
+func TestClient_RetriesRateLimited(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	if _, err := client.GetHistory(context.Background(), "", 10); err != nil {
+		t.Fatalf("expected retries to succeed, got: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestClient_RetriesRateLimited_GivesUp(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxRetries int
+		wantCalls  int32
+	}{
+		{"retries exhausted", "0", 2, 3},
+		{"retries disabled", "0", 0, 1},
+		{"wait too long", "3600", 3, 1},
+		{"no Retry-After", "", 3, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			client.SetMaxRetries(tt.maxRetries)
+
+			_, err := client.GetHistory(context.Background(), "", 10)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || !apiErr.IsRateLimited() {
+				t.Errorf("expected rate limit error, got %v", err)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("expected %d attempts, got %d", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.value != "" {
+			header.Set("Retry-After", tt.value)
+		}
+		got, ok := parseRetryAfter(header, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestClient_ETagCache(t *testing.T) {
+	var fullResponses, notModified atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullResponses.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"title":"Breaking Bad","year":2008,"ids":{"trakt":1388}}`))
+	}))
+	client.SetCacheTTL(0) // exercise the conditional requests, not the lookup cache
+
+	for i := 0; i < 3; i++ {
+		show, err := client.GetShow(context.Background(), "breaking-bad")
+		if err != nil {
+			t.Fatalf("GetShow failed: %v", err)
+		}
+		if show.Title != "Breaking Bad" {
+			t.Errorf("request %d: expected cached title, got %q", i, show.Title)
+		}
+	}
+
+	if fullResponses.Load() != 1 || notModified.Load() != 2 {
+		t.Errorf("expected 1 full response and 2 cache hits, got %d and %d", fullResponses.Load(), notModified.Load())
+	}
+
+	// A different user must not see the previous user's cached data
+	client.SetToken(&Token{AccessToken: "other-user"})
+	if _, err := client.GetShow(context.Background(), "breaking-bad"); err != nil {
+		t.Fatalf("GetShow failed: %v", err)
+	}
+	if fullResponses.Load() != 2 {
+		t.Errorf("expected cache to be cleared after the token changed")
+	}
+}
+
+func TestClient_URLOverrides(t *testing.T) {
+	var hit atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit.Store(r.URL.Path == "/sync/history")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(Config{
+		ClientID:    "id",
+		AccessToken: "token",
+		APIURL:      server.URL + "/",
+		OAuthURL:    "https://staging.trakt.example",
+	}, nil)
+
+	if _, err := client.GetHistory(context.Background(), "", 1); err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if !hit.Load() {
+		t.Error("expected request to go to the overridden API URL")
+	}
+
+	authURL := client.AuthorizationURL(client.RedirectURI(), "state", "challenge")
+	if !strings.HasPrefix(authURL, "https://staging.trakt.example/oauth/authorize?") {
+		t.Errorf("expected overridden authorize URL, got %s", authURL)
+	}
+}
+
+func TestClient_GzipResponses(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("expected gzip to be accepted, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_, _ = zw.Write([]byte(`[{"id":1,"type":"movie"}]`))
+		_ = zw.Close()
+	}))
+
+	history, err := client.GetHistory(context.Background(), "", 1)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].ID != 1 {
+		t.Errorf("expected decompressed history, got %+v", history)
+	}
+}
+
 func TestClient_HTTPErrors(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -481,6 +1156,63 @@ func TestAPIError(t *testing.T) {
 	})
 }
 
+func TestAPIError_Details(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.Header().Set("X-Ratelimit", `{"name":"AUTHED_API_GET_LIMIT","period":300,"limit":1000,"remaining":0}`)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":"rate_limited","error_description":"Slow down"}`))
+	}))
+
+	_, err := client.GetHistory(context.Background(), "", 1)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %T", err)
+	}
+	if apiErr.Description != "Slow down" {
+		t.Errorf("Description = %q, want %q", apiErr.Description, "Slow down")
+	}
+	if apiErr.RetryAfter != time.Hour {
+		t.Errorf("RetryAfter = %v, want 1h", apiErr.RetryAfter)
+	}
+	if !strings.Contains(apiErr.RateLimit, "AUTHED_API_GET_LIMIT") {
+		t.Errorf("RateLimit = %q", apiErr.RateLimit)
+	}
+	if !strings.HasSuffix(err.Error(), ": Slow down") {
+		t.Errorf("expected description in message, got %q", err.Error())
+	}
+}
+
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{401, ErrUnauthorized},
+		{403, ErrForbidden},
+		{404, ErrNotFound},
+		{429, ErrRateLimited},
+		{409, ErrConflict},
+	}
+
+	for _, tt := range tests {
+		err := error(&APIError{StatusCode: tt.status})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("status %d: expected errors.Is(%v)", tt.status, tt.want)
+		}
+		if tt.want != ErrNotFound && errors.Is(err, ErrNotFound) {
+			t.Errorf("status %d unexpectedly matched ErrNotFound", tt.status)
+		}
+	}
+}
+
+func TestErrorDescription_IgnoresNonJSON(t *testing.T) {
+	if got := errorDescription([]byte("<html>access_token=abc</html>")); got != "" {
+		t.Errorf("expected non-JSON bodies to be dropped, got %q", got)
+	}
+}
+
 func TestConfigFromEnv(t *testing.T) {
 	t.Setenv("TRAKT_CLIENT_ID", "test-id")
 	t.Setenv("TRAKT_CLIENT_SECRET", "test-secret")
@@ -526,3 +1258,313 @@ func TestClient_NewClient(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_Ping(t *testing.T) {
+	var paths []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	client.SetToken(&Token{})
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+
+	// Signed in, Ping confirms the token; signed out, any cheap endpoint does
+	if len(paths) != 2 || paths[0] != "/users/settings" || paths[1] != "/genres/movies" {
+		t.Errorf("unexpected paths %v", paths)
+	}
+}
+
+func TestClient_LookupID(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/imdb/tt0903747" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("type"); got != "show" {
+			t.Errorf("expected type=show, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"type":"show","show":{"title":"Breaking Bad","year":2008,"ids":{"trakt":1388,"imdb":"tt0903747"}}}]`))
+	})
+
+	client := newTestClient(t, handler)
+
+	results, err := client.LookupID(context.Background(), "imdb", "tt0903747", "show")
+	if err != nil {
+		t.Fatalf("LookupID failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Show == nil || results[0].Show.IDs.Trakt != 1388 {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestClient_GetSeasons(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shows/37696/seasons" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("extended"); got != "full,episodes" {
+			t.Errorf("expected extended=full,episodes, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"number":1,"ids":{"trakt":1},"episodes":[{"season":1,"number":1,"number_abs":1,"title":"Romance Dawn","ids":{"trakt":7}}]}]`))
+	})
+
+	client := newTestClient(t, handler)
+
+	seasons, err := client.GetSeasons(context.Background(), "37696", WithExtended(ExtendedFull, ExtendedEpisodes))
+	if err != nil {
+		t.Fatalf("GetSeasons failed: %v", err)
+	}
+	if len(seasons) != 1 || len(seasons[0].Episodes) != 1 || seasons[0].Episodes[0].NumberAbs != 1 {
+		t.Errorf("unexpected seasons %+v", seasons)
+	}
+}
+
+func TestClient_GetMyShowsCalendar(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/calendars/my/shows/2024-03-10/7" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"first_aired":"2024-03-11T01:00:00.000Z","episode":{"season":2,"number":3,"title":"Who Is Alive?","ids":{"trakt":9}},"show":{"title":"Severance","ids":{"trakt":154997}}}]`))
+	})
+
+	client := newTestClient(t, handler)
+
+	entries, err := client.GetMyShowsCalendar(context.Background(), time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), 7)
+	if err != nil {
+		t.Fatalf("GetMyShowsCalendar failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Show.Title != "Severance" || entries[0].Episode.Number != 3 || entries[0].FirstAired.IsZero() {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
+
+func TestClient_GetMyMoviesCalendar(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/calendars/my/movies/2026-12-01/33" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"released":"2026-12-18","movie":{"title":"Dune: Part Three","year":2026,"ids":{"trakt":7}}}]`))
+	})
+
+	client := newTestClient(t, handler)
+
+	entries, err := client.GetMyMoviesCalendar(context.Background(), time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), 33)
+	if err != nil {
+		t.Fatalf("GetMyMoviesCalendar failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Released != "2026-12-18" || entries[0].Movie.Title != "Dune: Part Three" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
+
+func TestClient_GetHidden(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/hidden/calendar" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("type"); got != "show" {
+			t.Errorf("expected type=show, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		page := r.URL.Query().Get("page")
+		w.Header().Set("X-Pagination-Page", page)
+		w.Header().Set("X-Pagination-Page-Count", "2")
+		_, _ = w.Write([]byte(`[{"hidden_at":"2024-03-10T12:00:00.000Z","type":"show","show":{"title":"Show ` + page + `","ids":{"trakt":` + page + `}}}]`))
+	})
+
+	client := newTestClient(t, handler)
+
+	hidden, err := client.GetHidden(context.Background(), "calendar", "show")
+	if err != nil {
+		t.Fatalf("GetHidden failed: %v", err)
+	}
+	if len(hidden) != 2 || hidden[0].Show.Title != "Show 1" || hidden[1].Show.IDs.Trakt != 2 {
+		t.Errorf("expected both pages, got %+v", hidden)
+	}
+}
+
+func TestClient_UserData(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/users/sean%20r/ratings":
+			_, _ = w.Write([]byte(`[{"rated_at":"2024-03-10T12:00:00.000Z","rating":9,"type":"movie","movie":{"title":"Dune","ids":{"trakt":287071}}}]`))
+		case "/users/me/watched/shows":
+			_, _ = w.Write([]byte(`[{"plays":3,"show":{"title":"Severance","ids":{"trakt":154997}}}]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+	})
+
+	client := newTestClient(t, handler)
+
+	ratings, err := client.GetUserRatings(context.Background(), "sean r", "")
+	if err != nil {
+		t.Fatalf("GetUserRatings failed: %v", err)
+	}
+	if len(ratings) != 1 || ratings[0].Rating != 9 || ratings[0].Type != "movie" || ratings[0].Movie.Title != "Dune" {
+		t.Errorf("unexpected ratings: %+v", ratings)
+	}
+
+	var shows []string
+	err = client.ForEachUserWatched(context.Background(), "me", "shows", func(e WatchedEntry) error {
+		shows = append(shows, e.Show.Title)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachUserWatched failed: %v", err)
+	}
+	if len(shows) != 1 || shows[0] != "Severance" {
+		t.Errorf("unexpected watched shows: %v", shows)
+	}
+}
+
+func TestClient_GetLastActivities(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync/last_activities" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"all":"2026-03-02T20:00:00.000Z","movies":{"watched_at":"2026-03-01T19:00:00.000Z","rated_at":"2026-02-01T00:00:00.000Z"},"shows":{"rated_at":"2026-03-02T20:00:00.000Z"}}`))
+	})
+
+	client := newTestClient(t, handler)
+	activities, err := client.GetLastActivities(context.Background())
+	if err != nil {
+		t.Fatalf("GetLastActivities failed: %v", err)
+	}
+	if activities.Movies.WatchedAt.Day() != 1 || activities.Shows.RatedAt.Day() != 2 || !activities.Episodes.WatchedAt.IsZero() {
+		t.Errorf("unexpected activities: %+v", activities)
+	}
+}
+
+func TestClient_Lists(t *testing.T) {
+	var added SyncItems
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/users/me/lists":
+			_, _ = w.Write([]byte(`[{"name":"Sci-fi","privacy":"private","item_count":1,"ids":{"trakt":42,"slug":"sci-fi"}}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/users/me/lists/42/items":
+			_, _ = w.Write([]byte(`[{"rank":1,"type":"movie","movie":{"title":"Arrival","year":2016,"ids":{"trakt":3}}}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/users/me/lists/42/items":
+			_ = json.NewDecoder(r.Body).Decode(&added)
+			_, _ = w.Write([]byte(`{"added":{"movies":1}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	client := newTestClient(t, handler)
+	lists, err := client.GetLists(context.Background())
+	if err != nil {
+		t.Fatalf("GetLists failed: %v", err)
+	}
+	if len(lists) != 1 || lists[0].Name != "Sci-fi" || lists[0].IDs.Trakt != 42 {
+		t.Fatalf("unexpected lists: %+v", lists)
+	}
+
+	items, err := client.GetListItems(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("GetListItems failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Movie == nil || items[0].Movie.Title != "Arrival" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+
+	resp, err := client.AddListItems(context.Background(), "42", SyncItems{Movies: []Movie{{IDs: MovieIDs{Trakt: 3}}}})
+	if err != nil {
+		t.Fatalf("AddListItems failed: %v", err)
+	}
+	if resp.Added.Movies != 1 || len(added.Movies) != 1 || added.Movies[0].IDs.Trakt != 3 {
+		t.Errorf("unexpected add: %+v, sent %+v", resp, added)
+	}
+}
+
+func TestClient_GetRecommendations(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("ignore_collected"); got != "true" {
+			t.Errorf("expected ignore_collected=true, got %q", got)
+		}
+		if got := r.URL.Query().Get("limit"); got != "5" {
+			t.Errorf("expected limit=5, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/recommendations/shows":
+			_, _ = w.Write([]byte(`[{"title":"Dark","year":2017,"ids":{"trakt":70523}}]`))
+		case "/recommendations/movies":
+			_, _ = w.Write([]byte(`[{"title":"Arrival","year":2016,"ids":{"trakt":150167}}]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	client := newTestClient(t, handler)
+
+	shows, err := client.GetRecommendedShows(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("GetRecommendedShows failed: %v", err)
+	}
+	if len(shows) != 1 || shows[0].Title != "Dark" {
+		t.Errorf("unexpected shows %+v", shows)
+	}
+
+	movies, err := client.GetRecommendedMovies(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("GetRecommendedMovies failed: %v", err)
+	}
+	if len(movies) != 1 || movies[0].Title != "Arrival" {
+		t.Errorf("unexpected movies %+v", movies)
+	}
+}
+
+func TestClient_GetAliases(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shows/1420/aliases" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"title":"Shingeki no Kyojin","country":"jp"}]`))
+	})
+
+	client := newTestClient(t, handler)
+
+	aliases, err := client.GetAliases(context.Background(), "shows", "1420")
+	if err != nil {
+		t.Fatalf("GetAliases failed: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0].Title != "Shingeki no Kyojin" || aliases[0].Country != "jp" {
+		t.Errorf("unexpected aliases %+v", aliases)
+	}
+}
+
+func TestClient_GetHistory_ItemPeriod(t *testing.T) {
+	start := time.Date(2024, 3, 10, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync/history/movies/16662" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("start_at") != "2024-03-09T23:00:00Z" || q.Get("end_at") != "2024-03-10T23:00:00Z" {
+			t.Errorf("unexpected period %s to %s", q.Get("start_at"), q.Get("end_at"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	client := newTestClient(t, handler)
+
+	if _, err := client.GetHistory(context.Background(), "movies/16662", 1, WithPeriod(start, start.AddDate(0, 0, 1))); err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+}
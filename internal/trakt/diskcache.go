@@ -0,0 +1,104 @@
+package trakt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// diskCache persists lookup responses as one JSON file per request path so
+// warm metadata survives restarts of the server process.
+type diskCache struct {
+	dir string
+	now func() time.Time
+}
+
+type diskEntry struct {
+	Path    string          `json:"path"`
+	Expires time.Time       `json:"expires"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// newDiskCache creates dir if needed and removes entries that have expired.
+func newDiskCache(dir string) (*diskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+	c := &diskCache{dir: dir, now: time.Now}
+	c.prune()
+	return c, nil
+}
+
+func (c *diskCache) file(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *diskCache) get(path string) ([]byte, bool) {
+	entry, ok := c.read(c.file(path))
+	if !ok || entry.Path != path {
+		return nil, false
+	}
+	if c.now().After(entry.Expires) {
+		os.Remove(c.file(path))
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+// put stores value until ttl elapses. Write failures only cost a future
+// cache miss, so they are ignored.
+func (c *diskCache) put(path string, value []byte, ttl time.Duration) {
+	data, err := json.Marshal(diskEntry{Path: path, Expires: c.now().Add(ttl), Value: value})
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), c.file(path))
+}
+
+func (c *diskCache) read(file string) (diskEntry, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return diskEntry{}, false
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return diskEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *diskCache) prune() {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	now := c.now()
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		file := filepath.Join(c.dir, f.Name())
+		if entry, ok := c.read(file); !ok || now.After(entry.Expires) {
+			os.Remove(file)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package trakt
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_DiskCacheSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"title":"Inception","year":2010,"ids":{"trakt":16662}}`))
+	})
+
+	for i := 0; i < 2; i++ {
+		client := newTestClient(t, handler) // a fresh process each time
+		if err := client.SetCacheDir(dir); err != nil {
+			t.Fatalf("SetCacheDir failed: %v", err)
+		}
+
+		movie, err := client.GetMovie(context.Background(), "inception-2010")
+		if err != nil {
+			t.Fatalf("GetMovie failed: %v", err)
+		}
+		if movie.Title != "Inception" {
+			t.Errorf("expected Inception, got %q", movie.Title)
+		}
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected the second client to use the disk cache, got %d API calls", got)
+	}
+}
+
+func TestDiskCache_ExpiredEntriesPruned(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := newDiskCache(dir)
+	if err != nil {
+		t.Fatalf("newDiskCache failed: %v", err)
+	}
+
+	cache.put("/movies/old", []byte(`{}`), -time.Minute)
+	cache.put("/movies/new", []byte(`{}`), time.Hour)
+
+	if _, ok := cache.get("/movies/old"); ok {
+		t.Error("expected expired entry to miss")
+	}
+	if _, err := newDiskCache(dir); err != nil {
+		t.Fatalf("newDiskCache failed: %v", err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read cache dir: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected only the fresh entry to remain, got %d files", len(files))
+	}
+}
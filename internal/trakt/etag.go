@@ -0,0 +1,49 @@
+package trakt
+
+import "sync"
+
+// maxETagEntries bounds the number of cached GET responses.
+const maxETagEntries = 256
+
+// etagCache remembers GET response bodies by path so repeated requests can
+// be made conditional with If-None-Match; a 304 reply reuses the body.
+type etagCache struct {
+	mu      sync.Mutex
+	entries map[string]etagEntry
+}
+
+type etagEntry struct {
+	etag string
+	body []byte
+}
+
+func newETagCache() *etagCache {
+	return &etagCache{entries: make(map[string]etagEntry)}
+}
+
+func (c *etagCache) get(path string) (etagEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[path]
+	return e, ok
+}
+
+func (c *etagCache) put(path, etag string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[path]; !exists && len(c.entries) >= maxETagEntries {
+		// Evict an arbitrary entry; a miss only costs a full response
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[path] = etagEntry{etag: etag, body: body}
+}
+
+// clear drops every entry, e.g. when the signed-in user may have changed.
+func (c *etagCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
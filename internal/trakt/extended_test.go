@@ -0,0 +1,75 @@
+package trakt_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt/trakttest"
+)
+
+// These tests decode responses recorded from Trakt, so a change to the
+// types that stops a field from decoding shows up here. Re-record with
+// TRAKTTEST_RECORD=1 and real credentials in the environment.
+
+func TestExtended_Show(t *testing.T) {
+	client := trakttest.CassetteClient(t, "testdata/cassettes/extended.json")
+
+	show, err := client.GetShow(context.Background(), "breaking-bad", trakt.WithExtended(trakt.ExtendedFull, trakt.ExtendedImages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if show.IDs.Trakt != 1388 || show.IDs.TVDB != 81189 || show.IDs.IMDB != "tt0903747" {
+		t.Errorf("unexpected IDs %+v", show.IDs)
+	}
+	if show.Overview == "" || show.Runtime == 0 || show.Status != "ended" || show.Votes == 0 || show.Rating == 0 {
+		t.Errorf("expected the full fields decoded, got %+v", show)
+	}
+	if show.AiredEpisodes != 62 || !slices.Contains(show.Genres, "drama") {
+		t.Errorf("aired episodes %d, genres %v", show.AiredEpisodes, show.Genres)
+	}
+	if want := time.Date(2008, 1, 21, 2, 0, 0, 0, time.UTC); show.FirstAired == nil || !show.FirstAired.Equal(want) {
+		t.Errorf("first aired %v, want %v", show.FirstAired, want)
+	}
+	if show.Images.PosterURL() == "" || len(show.Images.Logo) == 0 {
+		t.Errorf("expected artwork decoded, got %+v", show.Images)
+	}
+}
+
+func TestExtended_Episode(t *testing.T) {
+	client := trakttest.CassetteClient(t, "testdata/cassettes/extended.json")
+	ctx := context.Background()
+
+	ep, err := client.GetEpisode(ctx, "breaking-bad", 1, 1, trakt.WithExtended(trakt.ExtendedFull))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep.Title != "Pilot" || ep.NumberAbs != 1 || ep.Runtime == 0 || ep.Overview == "" || ep.FirstAired == nil {
+		t.Errorf("expected the full fields decoded, got %+v", ep)
+	}
+
+	seasons, err := client.GetSeasons(ctx, "breaking-bad", trakt.WithExtended(trakt.ExtendedEpisodes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seasons) != 2 || seasons[0].Number != 0 || len(seasons[1].Episodes) == 0 || seasons[1].Episodes[0].IDs.Trakt != 73482 {
+		t.Errorf("expected specials then season 1 with its episodes, got %+v", seasons)
+	}
+}
+
+func TestExtended_Movie(t *testing.T) {
+	client := trakttest.CassetteClient(t, "testdata/cassettes/extended.json")
+
+	movie, err := client.GetMovie(context.Background(), "dune-2021", trakt.WithExtended(trakt.ExtendedFull))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if movie.Tagline != "It begins." || movie.Released != "2021-10-22" || movie.Status != "released" {
+		t.Errorf("expected the full fields decoded, got %+v", movie)
+	}
+	if movie.Runtime != 155 || movie.Overview == "" || !slices.Contains(movie.Genres, "science-fiction") {
+		t.Errorf("expected the full fields decoded, got %+v", movie)
+	}
+}
@@ -0,0 +1,32 @@
+package trakt
+
+import "net/http"
+
+// RoundTripFunc sends one HTTP request.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripFunc to observe or alter requests and
+// responses, e.g. for logging, metrics, or extra headers.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Use appends middleware to the client's chain. The first middleware added
+// sees each request first and its response last. Retries and token refreshes
+// pass through the chain as separate requests.
+func (c *Client) Use(mw ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middleware = append(c.middleware, mw...)
+}
+
+// roundTrip sends req through the middleware chain.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.RLock()
+	chain := c.middleware
+	c.mu.RUnlock()
+
+	next := RoundTripFunc(c.httpClient.Do)
+	for i := len(chain) - 1; i >= 0; i-- {
+		next = chain[i](next)
+	}
+	return next(req)
+}
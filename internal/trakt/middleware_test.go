@@ -0,0 +1,41 @@
+package trakt
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestClient_Middleware(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Test"); got != "outer,inner" {
+			t.Errorf("expected both middlewares to set the header in order, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				if v := req.Header.Get("X-Test"); v != "" {
+					req.Header.Set("X-Test", v+","+name)
+				} else {
+					req.Header.Set("X-Test", name)
+				}
+				resp, err := next(req)
+				order = append(order, name)
+				return resp, err
+			}
+		}
+	}
+	client.Use(tag("outer"), tag("inner"))
+
+	if _, err := client.GetHistory(context.Background(), "", 1); err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(order) != 2 || order[0] != "inner" || order[1] != "outer" {
+		t.Errorf("expected responses to unwind inner then outer, got %v", order)
+	}
+}
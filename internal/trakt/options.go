@@ -0,0 +1,70 @@
+package trakt
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Extended info levels accepted by the extended query parameter.
+const (
+	ExtendedFull     = "full"
+	ExtendedImages   = "images"
+	ExtendedEpisodes = "episodes" // seasons only: include each season's episodes
+)
+
+// RequestOption customizes the query of an API request.
+type RequestOption func(url.Values)
+
+// WithExtended requests extra fields, e.g. WithExtended(ExtendedFull) for
+// overview, runtime, ratings and genres, or WithExtended(ExtendedFull,
+// ExtendedImages) to add artwork as well.
+func WithExtended(levels ...string) RequestOption {
+	return func(params url.Values) {
+		params.Set("extended", strings.Join(levels, ","))
+	}
+}
+
+// WithPage requests one page of a paginated endpoint, such as search.
+// Pages start at 1.
+func WithPage(page int) RequestOption {
+	return func(params url.Values) {
+		params.Set("page", strconv.Itoa(page))
+	}
+}
+
+// WithLimit sets the page size of a paginated endpoint. Trakt returns 10
+// items per page when no limit is given.
+func WithLimit(limit int) RequestOption {
+	return func(params url.Values) {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+}
+
+// WithPeriod limits history to items watched from start up to end.
+func WithPeriod(start, end time.Time) RequestOption {
+	return func(params url.Values) {
+		params.Set("start_at", start.UTC().Format(time.RFC3339))
+		params.Set("end_at", end.UTC().Format(time.RFC3339))
+	}
+}
+
+func applyOptions(params url.Values, opts []RequestOption) {
+	for _, opt := range opts {
+		opt(params)
+	}
+}
+
+// withOptions appends the options' query to a path that has none yet.
+func withOptions(path string, opts []RequestOption) string {
+	if len(opts) == 0 {
+		return path
+	}
+	params := url.Values{}
+	applyOptions(params, opts)
+	if len(params) == 0 {
+		return path
+	}
+	return path + "?" + params.Encode()
+}
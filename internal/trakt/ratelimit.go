@@ -0,0 +1,72 @@
+package trakt
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// rateLimitLowFraction is the share of a limit's budget below which the
+// client starts logging warnings.
+const rateLimitLowFraction = 0.1
+
+// RateLimit is the request budget for one of Trakt's rate-limit buckets, as
+// reported in the X-Ratelimit header of every response.
+type RateLimit struct {
+	Name      string    `json:"name"`
+	Period    int       `json:"period"` // seconds
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Until     time.Time `json:"until"` // when the budget resets
+}
+
+// Low reports whether less than a tenth of the budget remains.
+func (r RateLimit) Low() bool {
+	return r.Limit > 0 && float64(r.Remaining) < float64(r.Limit)*rateLimitLowFraction
+}
+
+// RateLimits returns the most recently reported budget for each rate-limit
+// bucket seen so far, sorted by name. It is empty until the first response.
+func (c *Client) RateLimits() []RateLimit {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	limits := make([]RateLimit, 0, len(c.rateLimits))
+	for _, rl := range c.rateLimits {
+		limits = append(limits, rl)
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Name < limits[j].Name })
+	return limits
+}
+
+// recordRateLimit stores the budget reported in a response, warning once
+// each time a bucket drops into its low range.
+func (c *Client) recordRateLimit(header http.Header) {
+	raw := header.Get("X-Ratelimit")
+	if raw == "" {
+		return
+	}
+	var rl RateLimit
+	if err := json.Unmarshal([]byte(raw), &rl); err != nil || rl.Name == "" {
+		c.logger.Debug("ignoring malformed X-Ratelimit header", "error", err)
+		return
+	}
+
+	c.mu.Lock()
+	prev, seen := c.rateLimits[rl.Name]
+	if c.rateLimits == nil {
+		c.rateLimits = make(map[string]RateLimit)
+	}
+	c.rateLimits[rl.Name] = rl
+	c.mu.Unlock()
+
+	if rl.Low() && (!seen || !prev.Low()) {
+		c.logger.Warn("trakt rate limit budget low",
+			"limit", rl.Name,
+			"remaining", rl.Remaining,
+			"of", rl.Limit,
+			"resets", rl.Until,
+		)
+	}
+}
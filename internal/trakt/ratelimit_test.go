@@ -0,0 +1,69 @@
+package trakt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_RateLimits(t *testing.T) {
+	remaining := []int{500, 90, 80}
+	var call int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit", fmt.Sprintf(
+			`{"name":"AUTHED_API_GET_LIMIT","period":300,"limit":1000,"remaining":%d,"until":"2024-03-01T12:05:00Z"}`,
+			remaining[call]))
+		call++
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	var logs bytes.Buffer
+	client.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	if got := client.RateLimits(); len(got) != 0 {
+		t.Fatalf("expected no budgets before the first request, got %v", got)
+	}
+
+	for range remaining {
+		if _, err := client.GetHistory(context.Background(), "", 1); err != nil {
+			t.Fatalf("GetHistory failed: %v", err)
+		}
+	}
+
+	limits := client.RateLimits()
+	if len(limits) != 1 {
+		t.Fatalf("expected 1 budget, got %d", len(limits))
+	}
+	rl := limits[0]
+	if rl.Name != "AUTHED_API_GET_LIMIT" || rl.Limit != 1000 || rl.Remaining != 80 || rl.Period != 300 {
+		t.Errorf("unexpected budget %+v", rl)
+	}
+	if rl.Until.IsZero() {
+		t.Error("expected reset time to be parsed")
+	}
+	if !rl.Low() {
+		t.Error("expected 80/1000 to be low")
+	}
+
+	// Only the first response to cross into the low range warns
+	if n := strings.Count(logs.String(), "rate limit budget low"); n != 1 {
+		t.Errorf("expected 1 low-budget warning, got %d:\n%s", n, logs.String())
+	}
+}
+
+func TestClient_RateLimits_IgnoresMalformedHeader(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit", "not json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	if _, err := client.GetHistory(context.Background(), "", 1); err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if got := client.RateLimits(); len(got) != 0 {
+		t.Errorf("expected no budgets, got %v", got)
+	}
+}
@@ -0,0 +1,50 @@
+package trakt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// streamResult is a result that decodes the response body as it arrives
+// instead of from a fully buffered copy. send hands such results the body
+// directly, skipping the ETag cache, which needs the whole body in memory.
+type streamResult interface {
+	decodeStream(r io.Reader) error
+}
+
+// arrayStream decodes a JSON array one element at a time, passing each to fn.
+// An error from fn stops decoding and is returned unwrapped.
+type arrayStream[T any] struct {
+	fn    func(T) error
+	count int // elements decoded so far
+}
+
+func (s *arrayStream[T]) decodeStream(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		s.count++
+		if err := s.fn(item); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if tok != want {
+		return fmt.Errorf("decode response: expected %q, got %v", want, tok)
+	}
+	return nil
+}
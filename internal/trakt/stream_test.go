@@ -0,0 +1,108 @@
+package trakt
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestArrayStream(t *testing.T) {
+	var got []int
+	s := &arrayStream[int]{fn: func(n int) error {
+		got = append(got, n)
+		return nil
+	}}
+	if err := s.decodeStream(strings.NewReader(`[1, 2, 3]`)); err != nil {
+		t.Fatalf("decodeStream failed: %v", err)
+	}
+	if len(got) != 3 || got[2] != 3 || s.count != 3 {
+		t.Errorf("unexpected result %v (count %d)", got, s.count)
+	}
+
+	t.Run("not an array", func(t *testing.T) {
+		s := &arrayStream[int]{fn: func(int) error { return nil }}
+		if err := s.decodeStream(strings.NewReader(`{"a":1}`)); err == nil {
+			t.Error("expected error for an object body")
+		}
+	})
+
+	t.Run("callback error stops decoding", func(t *testing.T) {
+		stop := errors.New("stop")
+		var calls int
+		s := &arrayStream[int]{fn: func(int) error {
+			calls++
+			return stop
+		}}
+		if err := s.decodeStream(strings.NewReader(`[1, 2, 3]`)); !errors.Is(err, stop) {
+			t.Errorf("expected callback error, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+	})
+}
+
+func TestClient_ForEachHistoryItem(t *testing.T) {
+	client := newTestClient(t, pagedHistoryHandler(t, 250))
+
+	var ids []int64
+	err := client.ForEachHistoryItem(context.Background(), "", func(item HistoryItem) error {
+		ids = append(ids, item.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachHistoryItem failed: %v", err)
+	}
+	if len(ids) != 250 || ids[0] != 1 || ids[249] != 250 {
+		t.Errorf("expected items 1..250 in order, got %d items", len(ids))
+	}
+}
+
+func TestClient_ForEachWatched(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync/watched/shows" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_, _ = zw.Write([]byte(`[
+			{"plays":3,"last_watched_at":"2024-01-02T20:00:00.000Z","show":{"title":"Severance","ids":{"trakt":1}},
+			 "seasons":[{"number":1,"episodes":[{"number":1,"plays":2},{"number":2,"plays":1}]}]},
+			{"plays":1,"show":{"title":"Andor","ids":{"trakt":2}}}
+		]`))
+		_ = zw.Close()
+	}))
+
+	var entries []WatchedEntry
+	err := client.ForEachWatched(context.Background(), "shows", func(e WatchedEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachWatched failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Show.Title != "Severance" || len(entries[0].Seasons[0].Episodes) != 2 {
+		t.Errorf("unexpected first entry %+v", entries[0])
+	}
+}
+
+func TestClient_ForEachCollected_Error(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	err := client.ForEachCollected(context.Background(), "movies", func(CollectionEntry) error {
+		t.Error("callback should not run for an error response")
+		return nil
+	})
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package trakt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// pbkdf2Iterations is the PBKDF2-HMAC-SHA256 work factor for new token files.
+var pbkdf2Iterations = 600_000
+
+// ErrTokenFileEncrypted is returned when an encrypted token file is loaded
+// without a passphrase.
+var ErrTokenFileEncrypted = errors.New("token file is encrypted; set TRAKT_TOKEN_PASSPHRASE")
+
+// TokenStore persists OAuth tokens between runs.
+type TokenStore interface {
+	// Load returns the stored token, or nil if none has been saved.
+	Load() (*Token, error)
+	Save(token *Token) error
+}
+
+// FileTokenStore keeps the token as JSON in a file readable only by the
+// owner. With a passphrase the token is sealed with AES-256-GCM under a
+// PBKDF2-derived key, for config directories that are synced elsewhere.
+type FileTokenStore struct {
+	Path       string
+	Passphrase string // optional; encrypts the file at rest
+}
+
+// encryptedToken is the on-disk envelope of an encrypted token file.
+type encryptedToken struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewFileTokenStore creates a store backed by the file at path.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+// DefaultTokenPath returns the token file location under the user's config directory.
+func DefaultTokenPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "trakt-mcp", "token.json"), nil
+}
+
+// Load reads the token file. A missing file is not an error. Plaintext files
+// are still read when a passphrase is set and get encrypted on the next save.
+func (s *FileTokenStore) Load() (*Token, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read token file: %w", err)
+	}
+
+	var envelope encryptedToken
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.Ciphertext != nil {
+		if s.Passphrase == "" {
+			return nil, ErrTokenFileEncrypted
+		}
+		if data, err = s.open(&envelope); err != nil {
+			return nil, err
+		}
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("parse token file: %w", err)
+	}
+	return &token, nil
+}
+
+// Save writes the token file atomically so a crash never leaves it truncated.
+func (s *FileTokenStore) Save(token *Token) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal token: %w", err)
+	}
+	if s.Passphrase != "" {
+		if data, err = s.seal(data); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return fmt.Errorf("create token directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".token-*.json")
+	if err != nil {
+		return fmt.Errorf("create token file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write token file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write token file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("replace token file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileTokenStore) seal(plaintext []byte) ([]byte, error) {
+	envelope := encryptedToken{
+		Version:    1,
+		KDF:        "pbkdf2-sha256",
+		Iterations: pbkdf2Iterations,
+		Salt:       make([]byte, 16),
+	}
+	if _, err := rand.Read(envelope.Salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	aead, err := newTokenCipher(s.Passphrase, envelope.Salt, envelope.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	envelope.Ciphertext = aead.Seal(nil, envelope.Nonce, plaintext, nil)
+
+	return json.MarshalIndent(envelope, "", "  ")
+}
+
+func (s *FileTokenStore) open(envelope *encryptedToken) ([]byte, error) {
+	if envelope.Version != 1 || envelope.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported token file format (version %d, kdf %q)", envelope.Version, envelope.KDF)
+	}
+
+	aead, err := newTokenCipher(s.Passphrase, envelope.Salt, envelope.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope.Nonce) != aead.NonceSize() {
+		return nil, errors.New("decrypt token file: invalid nonce")
+	}
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("decrypt token file: wrong passphrase or corrupted file")
+	}
+	return plaintext, nil
+}
+
+func newTokenCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(passphrase), salt, iterations, 32))
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key per RFC 8018 §5.2 with HMAC-SHA256.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
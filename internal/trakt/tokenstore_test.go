@@ -0,0 +1,119 @@
+package trakt
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileTokenStore_RoundTrip(t *testing.T) {
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "nested", "token.json"))
+
+	token, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load on missing file failed: %v", err)
+	}
+	if token != nil {
+		t.Fatalf("expected no token, got %+v", token)
+	}
+
+	want := &Token{AccessToken: "access123", RefreshToken: "refresh456", ExpiresIn: 7776000, CreatedAt: 1704067200}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	info, err := os.Stat(store.Path)
+	if err != nil {
+		t.Fatalf("stat token file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected mode 0600, got %o", perm)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if *got != *want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestFileTokenStore_Encrypted(t *testing.T) {
+	defer func(n int) { pbkdf2Iterations = n }(pbkdf2Iterations)
+	pbkdf2Iterations = 1000 // keep the test fast
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	store := &FileTokenStore{Path: path, Passphrase: "correct horse"}
+
+	want := &Token{AccessToken: "access123", RefreshToken: "refresh456"}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read token file: %v", err)
+	}
+	if strings.Contains(string(data), "access123") {
+		t.Error("token file contains the plaintext access token")
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if *got != *want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if _, err := (&FileTokenStore{Path: path}).Load(); !errors.Is(err, ErrTokenFileEncrypted) {
+		t.Errorf("expected ErrTokenFileEncrypted without a passphrase, got %v", err)
+	}
+	if _, err := (&FileTokenStore{Path: path, Passphrase: "wrong"}).Load(); err == nil {
+		t.Error("expected an error with the wrong passphrase")
+	}
+}
+
+func TestFileTokenStore_EncryptsPlaintextOnSave(t *testing.T) {
+	defer func(n int) { pbkdf2Iterations = n }(pbkdf2Iterations)
+	pbkdf2Iterations = 1000
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	if err := NewFileTokenStore(path).Save(&Token{AccessToken: "access123"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	store := &FileTokenStore{Path: path, Passphrase: "secret"}
+	token, err := store.Load()
+	if err != nil || token.AccessToken != "access123" {
+		t.Fatalf("expected plaintext file to load with a passphrase, got %+v, %v", token, err)
+	}
+	if err := store.Save(token); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := NewFileTokenStore(path).Load(); !errors.Is(err, ErrTokenFileEncrypted) {
+		t.Errorf("expected file to be encrypted after saving, got %v", err)
+	}
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// Test vectors for PBKDF2-HMAC-SHA256 with P="password", S="salt"
+	tests := []struct {
+		iterations int
+		want       string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+	}
+
+	for _, tt := range tests {
+		got := hex.EncodeToString(pbkdf2SHA256([]byte("password"), []byte("salt"), tt.iterations, 32))
+		if got != tt.want {
+			t.Errorf("iterations=%d: got %s, want %s", tt.iterations, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package trakttest_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt/trakttest"
+)
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassettes", "search.json")
+
+	recorded := &trakttest.Cassette{}
+	client := srv.Client()
+	client.Use(trakttest.Record(recorded))
+	if _, err := client.Search(ctx, "breaking", "show"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RefreshToken(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorded.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{trakttest.ClientID, trakttest.ClientSecret, trakttest.AccessToken, trakttest.RefreshToken} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %q redacted from the cassette:\n%s", secret, data)
+		}
+	}
+
+	// Replaying needs no server
+	replay := trakt.NewClient(trakt.Config{ClientID: "anyone", APIURL: "http://127.0.0.1:0"}, nil)
+	trakttest.UseCassette(t, replay, path)
+	results, err := replay.Search(ctx, "breaking", "show")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Show == nil || results[0].Show.IDs.Trakt != 1388 {
+		t.Errorf("expected the recorded search replayed, got %+v", results)
+	}
+}
+
+func TestReplay_UnrecordedRequest(t *testing.T) {
+	c := &trakttest.Cassette{Interactions: []trakttest.Interaction{{
+		Request:  trakttest.RecordedRequest{Method: "GET", URI: "/movies/dune-2021"},
+		Response: trakttest.RecordedResponse{Status: 200, Body: []byte(`{"title":"Dune","year":2021}`)},
+	}}}
+	ft := &fakeTB{TB: t}
+	client := trakt.NewClient(trakt.Config{ClientID: "anyone", APIURL: "http://127.0.0.1:0"}, nil)
+	client.Use(trakttest.Replay(ft, c))
+	ctx := context.Background()
+
+	movie, err := client.GetMovie(ctx, "dune-2021")
+	if err != nil || movie.Title != "Dune" {
+		t.Fatalf("expected the recorded movie, got %+v, %v", movie, err)
+	}
+	if _, err := client.GetMovie(ctx, "arrival-2016"); err == nil {
+		t.Error("expected an error for a request the cassette lacks")
+	}
+	if !ft.failed {
+		t.Error("expected the test failed for a request the cassette lacks")
+	}
+}
+
+// fakeTB records failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeTB) Errorf(format string, args ...any) { f.failed = true }
@@ -0,0 +1,48 @@
+package trakttest
+
+import "github.com/kofifort/trakt-mcp-go/internal/trakt"
+
+// Catalog returns the shows and movies a new Server serves: Breaking Bad
+// and Severance, with their first seasons' opening episodes, and the
+// movies Dune and Arrival. Each call returns fresh copies, so tests may
+// change them.
+func Catalog() ([]Show, []trakt.Movie) {
+	shows := []Show{
+		{
+			Show: trakt.Show{
+				Title: "Breaking Bad",
+				Year:  2008,
+				IDs:   trakt.ShowIDs{Trakt: 1388, Slug: "breaking-bad", TVDB: 81189, IMDB: "tt0903747", TMDB: 1396},
+			},
+			Seasons: []trakt.Season{{
+				Number: 1,
+				IDs:    trakt.SeasonIDs{Trakt: 3950, TVDB: 30272, TMDB: 3572},
+				Episodes: []trakt.Episode{
+					{Season: 1, Number: 1, Title: "Pilot", IDs: trakt.EpisodeIDs{Trakt: 73482, TVDB: 349232, IMDB: "tt0959621", TMDB: 62085}},
+					{Season: 1, Number: 2, Title: "Cat's in the Bag...", IDs: trakt.EpisodeIDs{Trakt: 73483, TVDB: 349235, IMDB: "tt1054724", TMDB: 62086}},
+					{Season: 1, Number: 3, Title: "...And the Bag's in the River", IDs: trakt.EpisodeIDs{Trakt: 73484, TVDB: 349236, IMDB: "tt1054725", TMDB: 62087}},
+				},
+			}},
+		},
+		{
+			Show: trakt.Show{
+				Title: "Severance",
+				Year:  2022,
+				IDs:   trakt.ShowIDs{Trakt: 154997, Slug: "severance", TVDB: 371980, IMDB: "tt11280740", TMDB: 95396},
+			},
+			Seasons: []trakt.Season{{
+				Number: 1,
+				IDs:    trakt.SeasonIDs{Trakt: 212463, TVDB: 1924233, TMDB: 211598},
+				Episodes: []trakt.Episode{
+					{Season: 1, Number: 1, Title: "Good News About Hell", IDs: trakt.EpisodeIDs{Trakt: 5352468, TVDB: 8780626, IMDB: "tt11650328", TMDB: 1979307}},
+					{Season: 1, Number: 2, Title: "Half Loop", IDs: trakt.EpisodeIDs{Trakt: 5352469, TVDB: 8780627, IMDB: "tt11650330", TMDB: 3482567}},
+				},
+			}},
+		},
+	}
+	movies := []trakt.Movie{
+		{Title: "Dune", Year: 2021, IDs: trakt.MovieIDs{Trakt: 287071, Slug: "dune-2021", IMDB: "tt1160419", TMDB: 438631}},
+		{Title: "Arrival", Year: 2016, IDs: trakt.MovieIDs{Trakt: 210803, Slug: "arrival-2016", IMDB: "tt2543164", TMDB: 329865}},
+	}
+	return shows, movies
+}
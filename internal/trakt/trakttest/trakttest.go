@@ -0,0 +1,941 @@
+// Package trakttest provides a fake Trakt API server for tests of code
+// built on the trakt client. It answers the search, show and movie
+// lookups, history, ratings and watchlist sync, scrobble and OAuth
+// endpoints over real HTTP from an in-memory catalog and account, so a
+// test can drive a *trakt.Client end to end and then check what it
+// changed, without writing its own httptest handlers:
+//
+//	srv := trakttest.NewServer(t)
+//	client := srv.Client()
+//	// ... log a watch through client ...
+//	if got := srv.History(); len(got) != 1 { ... }
+//
+// The catalog starts as Catalog returns it. Requests need the ClientID
+// API key, and those to the account the access token the server issued
+// or AccessToken, as Trakt would require.
+//
+// Where a test needs Trakt's real responses rather than the fake's, a
+// Cassette replays ones recorded from the live API; see UseCassette.
+package trakttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+)
+
+// Credentials the server accepts.
+const (
+	ClientID     = "trakttest-client-id"
+	ClientSecret = "trakttest-client-secret"
+	AccessToken  = "trakttest-access-token"
+	RefreshToken = "trakttest-refresh-token"
+	DeviceCode   = "trakttest-device-code"
+	UserCode     = "TEST1234"
+)
+
+// Show is a show in the catalog, with its seasons and their episodes.
+type Show struct {
+	trakt.Show
+	Seasons []trakt.Season
+}
+
+// Request is a request the server received.
+type Request struct {
+	Method string
+	Path   string // with the query, if any
+	Body   []byte
+}
+
+// Server is a fake Trakt API. Its methods may be called while it serves
+// requests.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	shows     []Show
+	movies    []trakt.Movie
+	history   []trakt.HistoryItem
+	ratings   []trakt.Rating
+	watchlist []trakt.WatchlistItem
+	tokens    map[string]bool // access tokens accepted
+	refresh   map[string]bool // refresh tokens accepted
+	issued    int
+	nextID    int64
+	pending   int // device token polls to answer as pending
+	faults    map[string][]int
+	requests  []Request
+	now       func() time.Time
+}
+
+// NewServer starts a fake Trakt API serving the catalog Catalog returns
+// and an empty account, and closes it when tb's test ends.
+func NewServer(tb testing.TB) *Server {
+	shows, movies := Catalog()
+	s := &Server{
+		shows:   shows,
+		movies:  movies,
+		tokens:  map[string]bool{AccessToken: true},
+		refresh: map[string]bool{RefreshToken: true},
+		nextID:  1,
+		faults:  make(map[string][]int),
+		now:     time.Now,
+	}
+	s.Server = httptest.NewServer(s.routes())
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Client returns a trakt client for the server, signed in with
+// AccessToken.
+func (s *Server) Client() *trakt.Client {
+	client := trakt.NewClient(trakt.Config{
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		AccessToken:  AccessToken,
+		RefreshToken: RefreshToken,
+		APIURL:       s.URL,
+	}, nil)
+	return client
+}
+
+// AddShow adds a show to the catalog.
+func (s *Server) AddShow(show Show) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shows = append(s.shows, show)
+}
+
+// AddMovie adds a movie to the catalog.
+func (s *Server) AddMovie(movie trakt.Movie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.movies = append(s.movies, movie)
+}
+
+// AddHistory adds plays to the account's history, giving them IDs if they
+// have none.
+func (s *Server) AddHistory(items ...trakt.HistoryItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, h := range items {
+		if h.ID == 0 {
+			h.ID = s.nextID
+		}
+		s.nextID = max(s.nextID, h.ID) + 1
+		s.history = append(s.history, h)
+	}
+}
+
+// History returns the account's history, newest first.
+func (s *Server) History() []trakt.HistoryItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedHistory()
+}
+
+// AddRatings adds ratings to the account.
+func (s *Server) AddRatings(ratings ...trakt.Rating) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ratings = append(s.ratings, ratings...)
+}
+
+// Ratings returns the account's ratings.
+func (s *Server) Ratings() []trakt.Rating {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]trakt.Rating(nil), s.ratings...)
+}
+
+// Watchlist returns the account's watchlist.
+func (s *Server) Watchlist() []trakt.WatchlistItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]trakt.WatchlistItem(nil), s.watchlist...)
+}
+
+// SetPendingPolls makes the device flow answer n polls for a token as
+// still waiting for the user before approving it.
+func (s *Server) SetPendingPolls(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = n
+}
+
+// Expire makes the server turn away an access token, as Trakt does once
+// it expires or is revoked, so that a client has to refresh it.
+func (s *Server) Expire(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, accessToken)
+}
+
+// Fail makes the server answer the next request to path, such as
+// "/sync/history", with status instead, once for each status given, in
+// order. The query isn't considered.
+func (s *Server) Fail(method, path string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := method + " " + path
+	s.faults[key] = append(s.faults[key], statuses...)
+}
+
+// Requests returns the requests the server has received, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// routes maps Trakt's endpoints to the server's handlers.
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search/{types}", s.search)
+	mux.HandleFunc("GET /search/{idType}/{id}", s.lookup)
+	mux.HandleFunc("GET /shows/{id}", s.getShow)
+	mux.HandleFunc("GET /shows/{id}/seasons", s.getSeasons)
+	mux.HandleFunc("GET /shows/{id}/seasons/{season}/episodes/{episode}", s.getEpisode)
+	mux.HandleFunc("GET /movies/{id}", s.getMovie)
+	mux.HandleFunc("GET /sync/history", s.authed(s.getHistory))
+	mux.HandleFunc("GET /sync/history/{rest...}", s.authed(s.getHistory))
+	mux.HandleFunc("POST /sync/history", s.authed(s.addHistory))
+	mux.HandleFunc("POST /sync/history/remove", s.authed(s.removeHistory))
+	mux.HandleFunc("GET /users/me/ratings", s.authed(s.getRatings))
+	mux.HandleFunc("GET /users/me/ratings/{type}", s.authed(s.getRatings))
+	mux.HandleFunc("POST /sync/ratings", s.authed(s.addRatings))
+	mux.HandleFunc("GET /sync/watchlist", s.authed(s.getWatchlist))
+	mux.HandleFunc("GET /sync/watchlist/{type}", s.authed(s.getWatchlist))
+	mux.HandleFunc("POST /sync/watchlist", s.authed(s.addWatchlist))
+	mux.HandleFunc("POST /scrobble/{action}", s.authed(s.scrobble))
+	mux.HandleFunc("POST /oauth/device/code", s.deviceCode)
+	mux.HandleFunc("POST /oauth/device/token", s.deviceToken)
+	mux.HandleFunc("POST /oauth/token", s.token)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.RequestURI(), Body: body})
+		key := r.Method + " " + r.URL.Path
+		var status int
+		if faults := s.faults[key]; len(faults) > 0 {
+			status, s.faults[key] = faults[0], faults[1:]
+		}
+		s.mu.Unlock()
+
+		switch {
+		case status != 0:
+			w.WriteHeader(status)
+		case !strings.HasPrefix(r.URL.Path, "/oauth/") && r.Header.Get("trakt-api-key") != ClientID:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			mux.ServeHTTP(w, r)
+		}
+	})
+}
+
+// authed makes h answer 401 unless the request carries an access token
+// the server accepts.
+func (s *Server) authed(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		ok := s.tokens[token]
+		s.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// paginate writes the page of items the request asks for, with Trakt's
+// pagination headers. Without a page or limit, everything is written.
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	q := r.URL.Query()
+	if q.Get("page") == "" && q.Get("limit") == "" {
+		writeJSON(w, http.StatusOK, nonNil(items))
+		return
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	page = max(page, 1)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 10
+	}
+	pages := max((len(items)+limit-1)/limit, 1)
+	start := min((page-1)*limit, len(items))
+	end := min(start+limit, len(items))
+
+	h := w.Header()
+	h.Set("X-Pagination-Page", strconv.Itoa(page))
+	h.Set("X-Pagination-Limit", strconv.Itoa(limit))
+	h.Set("X-Pagination-Page-Count", strconv.Itoa(pages))
+	h.Set("X-Pagination-Item-Count", strconv.Itoa(len(items)))
+	writeJSON(w, http.StatusOK, nonNil(items[start:end]))
+}
+
+// nonNil makes an empty list encode as [] rather than null, as Trakt's do.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	types := strings.Split(r.PathValue("types"), ",")
+	score := func(title string) float64 {
+		if strings.ToLower(title) == query {
+			return 1000
+		}
+		return 100
+	}
+
+	s.mu.Lock()
+	var results []trakt.SearchResult
+	for _, t := range types {
+		switch t {
+		case "show":
+			for _, sh := range s.shows {
+				if strings.Contains(strings.ToLower(sh.Title), query) {
+					show := sh.Show
+					results = append(results, trakt.SearchResult{Type: "show", Score: score(show.Title), Show: &show})
+				}
+			}
+		case "movie":
+			for _, m := range s.movies {
+				if strings.Contains(strings.ToLower(m.Title), query) {
+					movie := m
+					results = append(results, trakt.SearchResult{Type: "movie", Score: score(movie.Title), Movie: &movie})
+				}
+			}
+		case "episode":
+			for _, sh := range s.shows {
+				for _, season := range sh.Seasons {
+					for _, e := range season.Episodes {
+						if strings.Contains(strings.ToLower(e.Title), query) {
+							show, ep := sh.Show, e
+							results = append(results, trakt.SearchResult{Type: "episode", Score: score(ep.Title), Show: &show, Episode: &ep})
+						}
+					}
+				}
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	paginate(w, r, results)
+}
+
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) {
+	idType, id := r.PathValue("idType"), r.PathValue("id")
+	want := r.URL.Query().Get("type")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var results []trakt.SearchResult
+	for _, sh := range s.shows {
+		if (want == "" || want == "show") && showHasID(sh.IDs, idType, id) {
+			show := sh.Show
+			results = append(results, trakt.SearchResult{Type: "show", Show: &show})
+		}
+		for _, season := range sh.Seasons {
+			for _, e := range season.Episodes {
+				if (want == "" || want == "episode") && episodeHasID(e.IDs, idType, id) {
+					show, ep := sh.Show, e
+					results = append(results, trakt.SearchResult{Type: "episode", Show: &show, Episode: &ep})
+				}
+			}
+		}
+	}
+	for _, m := range s.movies {
+		if (want == "" || want == "movie") && movieHasID(m.IDs, idType, id) {
+			movie := m
+			results = append(results, trakt.SearchResult{Type: "movie", Movie: &movie})
+		}
+	}
+	writeJSON(w, http.StatusOK, nonNil(results))
+}
+
+func showHasID(ids trakt.ShowIDs, idType, id string) bool {
+	switch idType {
+	case "trakt":
+		return strconv.Itoa(ids.Trakt) == id
+	case "tvdb":
+		return ids.TVDB != 0 && strconv.Itoa(ids.TVDB) == id
+	case "tmdb":
+		return ids.TMDB != 0 && strconv.Itoa(ids.TMDB) == id
+	case "imdb":
+		return ids.IMDB != "" && ids.IMDB == id
+	}
+	return false
+}
+
+func movieHasID(ids trakt.MovieIDs, idType, id string) bool {
+	switch idType {
+	case "trakt":
+		return strconv.Itoa(ids.Trakt) == id
+	case "tmdb":
+		return ids.TMDB != 0 && strconv.Itoa(ids.TMDB) == id
+	case "imdb":
+		return ids.IMDB != "" && ids.IMDB == id
+	}
+	return false
+}
+
+func episodeHasID(ids trakt.EpisodeIDs, idType, id string) bool {
+	switch idType {
+	case "trakt":
+		return strconv.Itoa(ids.Trakt) == id
+	case "tvdb":
+		return ids.TVDB != 0 && strconv.Itoa(ids.TVDB) == id
+	case "tmdb":
+		return ids.TMDB != 0 && strconv.Itoa(ids.TMDB) == id
+	case "imdb":
+		return ids.IMDB != "" && ids.IMDB == id
+	}
+	return false
+}
+
+// findShow returns the show with a Trakt ID, slug or IMDb ID, as Trakt's
+// show endpoints take. The caller holds s.mu.
+func (s *Server) findShow(id string) *Show {
+	for i, sh := range s.shows {
+		if strconv.Itoa(sh.IDs.Trakt) == id || sh.IDs.Slug == id || sh.IDs.IMDB != "" && sh.IDs.IMDB == id {
+			return &s.shows[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) getShow(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sh := s.findShow(r.PathValue("id"))
+	if sh == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, sh.Show)
+}
+
+func (s *Server) getSeasons(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sh := s.findShow(r.PathValue("id"))
+	if sh == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	withEpisodes := strings.Contains(r.URL.Query().Get("extended"), "episodes")
+	seasons := make([]trakt.Season, 0, len(sh.Seasons))
+	for _, season := range sh.Seasons {
+		if !withEpisodes {
+			season.Episodes = nil
+		}
+		seasons = append(seasons, season)
+	}
+	writeJSON(w, http.StatusOK, seasons)
+}
+
+func (s *Server) getEpisode(w http.ResponseWriter, r *http.Request) {
+	season, _ := strconv.Atoi(r.PathValue("season"))
+	number, _ := strconv.Atoi(r.PathValue("episode"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sh := s.findShow(r.PathValue("id")); sh != nil {
+		if _, ep := sh.episode(season, number); ep != nil {
+			writeJSON(w, http.StatusOK, ep)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+// episode returns the episode numbered number in season, or nil.
+func (sh *Show) episode(season, number int) (*trakt.Show, *trakt.Episode) {
+	for _, s := range sh.Seasons {
+		if s.Number != season {
+			continue
+		}
+		for i, e := range s.Episodes {
+			if e.Number == number {
+				return &sh.Show, &s.Episodes[i]
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) getMovie(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.movies {
+		if strconv.Itoa(m.IDs.Trakt) == id || m.IDs.Slug == id || m.IDs.IMDB != "" && m.IDs.IMDB == id {
+			writeJSON(w, http.StatusOK, m)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+// findShowByIDs returns the catalog's show with any of ids. The caller
+// holds s.mu.
+func (s *Server) findShowByIDs(ids trakt.ShowIDs) *Show {
+	for i, sh := range s.shows {
+		if ids.Trakt != 0 && sh.IDs.Trakt == ids.Trakt || ids.Slug != "" && sh.IDs.Slug == ids.Slug || ids.TVDB != 0 && sh.IDs.TVDB == ids.TVDB ||
+			ids.IMDB != "" && sh.IDs.IMDB == ids.IMDB || ids.TMDB != 0 && sh.IDs.TMDB == ids.TMDB {
+			return &s.shows[i]
+		}
+	}
+	return nil
+}
+
+// findMovie returns the catalog's movie with any of ids. The caller holds
+// s.mu.
+func (s *Server) findMovie(ids trakt.MovieIDs) *trakt.Movie {
+	for i, m := range s.movies {
+		if ids.Trakt != 0 && m.IDs.Trakt == ids.Trakt || ids.Slug != "" && m.IDs.Slug == ids.Slug ||
+			ids.IMDB != "" && m.IDs.IMDB == ids.IMDB || ids.TMDB != 0 && m.IDs.TMDB == ids.TMDB {
+			return &s.movies[i]
+		}
+	}
+	return nil
+}
+
+// findEpisode returns the catalog's episode with any of ids, and its
+// show. The caller holds s.mu.
+func (s *Server) findEpisode(ids trakt.EpisodeIDs) (*trakt.Show, *trakt.Episode) {
+	for i := range s.shows {
+		for _, season := range s.shows[i].Seasons {
+			for j, e := range season.Episodes {
+				if ids.Trakt != 0 && e.IDs.Trakt == ids.Trakt || ids.TVDB != 0 && e.IDs.TVDB == ids.TVDB ||
+					ids.IMDB != "" && e.IDs.IMDB == ids.IMDB || ids.TMDB != 0 && e.IDs.TMDB == ids.TMDB {
+					return &s.shows[i].Show, &season.Episodes[j]
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// sortedHistory returns the history newest first. The caller holds s.mu.
+func (s *Server) sortedHistory() []trakt.HistoryItem {
+	items := append([]trakt.HistoryItem(nil), s.history...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].WatchedAt.After(items[j].WatchedAt) })
+	return items
+}
+
+func (s *Server) getHistory(w http.ResponseWriter, r *http.Request) {
+	// rest is a type, "movies", "shows" or "episodes", and maybe an ID
+	kind, id, _ := strings.Cut(r.PathValue("rest"), "/")
+	q := r.URL.Query()
+	start, _ := time.Parse(time.RFC3339, q.Get("start_at"))
+	end, _ := time.Parse(time.RFC3339, q.Get("end_at"))
+
+	s.mu.Lock()
+	var items []trakt.HistoryItem
+	for _, h := range s.sortedHistory() {
+		switch {
+		case kind == "movies" && (h.Movie == nil || id != "" && strconv.Itoa(h.Movie.IDs.Trakt) != id),
+			kind == "shows" && (h.Show == nil || id != "" && strconv.Itoa(h.Show.IDs.Trakt) != id),
+			kind == "episodes" && (h.Episode == nil || id != "" && strconv.Itoa(h.Episode.IDs.Trakt) != id),
+			!start.IsZero() && h.WatchedAt.Before(start),
+			!end.IsZero() && !h.WatchedAt.Before(end):
+			continue
+		}
+		items = append(items, h)
+	}
+	s.mu.Unlock()
+	paginate(w, r, items)
+}
+
+// watchedAt parses the time a history write gives, now if it gives none.
+// Plays at "released" are dated to the first of the year of the movie or
+// the Unix epoch for episodes, as the catalog has no release dates.
+func (s *Server) watchedAt(at string, year int) (time.Time, error) {
+	switch at {
+	case "":
+		return s.now().UTC().Truncate(time.Second), nil
+	case "released":
+		return time.Date(max(year, 1970), 1, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Parse(time.RFC3339, at)
+}
+
+func (s *Server) addHistory(w http.ResponseWriter, r *http.Request) {
+	var item trakt.WatchedItem
+	if !readJSON(w, r, &item) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var resp trakt.SyncResponse
+	for _, m := range item.Movies {
+		movie := s.findMovie(m.IDs)
+		if movie == nil {
+			resp.NotFound.Movies = append(resp.NotFound.Movies, m)
+			continue
+		}
+		at, err := s.watchedAt(item.WatchedAt, movie.Year)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		found := *movie
+		s.history = append(s.history, trakt.HistoryItem{ID: s.nextID, WatchedAt: at, Action: "watch", Type: "movie", Movie: &found})
+		s.nextID++
+		resp.Added.Movies++
+	}
+	for _, e := range item.Episodes {
+		show, ep := s.findEpisode(e.IDs)
+		if ep == nil {
+			resp.NotFound.Episodes = append(resp.NotFound.Episodes, e)
+			continue
+		}
+		at, err := s.watchedAt(item.WatchedAt, 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		foundShow, foundEp := *show, *ep
+		s.history = append(s.history, trakt.HistoryItem{ID: s.nextID, WatchedAt: at, Action: "watch", Type: "episode", Show: &foundShow, Episode: &foundEp})
+		s.nextID++
+		resp.Added.Episodes++
+	}
+	resp.NotFound.Shows = item.Shows // whole shows aren't supported
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) removeHistory(w http.ResponseWriter, r *http.Request) {
+	var item trakt.WatchedItem
+	if !readJSON(w, r, &item) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remove := func(h trakt.HistoryItem) bool {
+		for _, id := range item.HistoryIDs {
+			if h.ID == id {
+				return true
+			}
+		}
+		for _, m := range item.Movies {
+			if h.Movie != nil && h.Movie.IDs.Trakt == m.IDs.Trakt {
+				return true
+			}
+		}
+		for _, e := range item.Episodes {
+			if h.Episode != nil && h.Episode.IDs.Trakt == e.IDs.Trakt {
+				return true
+			}
+		}
+		return false
+	}
+	var resp trakt.SyncResponse
+	kept := s.history[:0]
+	for _, h := range s.history {
+		switch {
+		case !remove(h):
+			kept = append(kept, h)
+		case h.Movie != nil:
+			resp.Deleted.Movies++
+		default:
+			resp.Deleted.Episodes++
+		}
+	}
+	s.history = kept
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getRatings(w http.ResponseWriter, r *http.Request) {
+	kind := strings.TrimSuffix(r.PathValue("type"), "s")
+	s.mu.Lock()
+	var ratings []trakt.Rating
+	for _, rt := range s.ratings {
+		if kind == "" || rt.Type == kind {
+			ratings = append(ratings, rt)
+		}
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, nonNil(ratings))
+}
+
+func (s *Server) addRatings(w http.ResponseWriter, r *http.Request) {
+	var item trakt.RatingItem
+	if !readJSON(w, r, &item) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ratedAt := func(at string) time.Time {
+		if t, err := time.Parse(time.RFC3339, at); err == nil {
+			return t
+		}
+		return s.now().UTC().Truncate(time.Second)
+	}
+	var resp trakt.SyncResponse
+	for _, m := range item.Movies {
+		movie := s.findMovie(m.IDs)
+		if movie == nil {
+			resp.NotFound.Movies = append(resp.NotFound.Movies, trakt.Movie{IDs: m.IDs})
+			continue
+		}
+		found := *movie
+		s.setRating(trakt.Rating{Rating: m.Rating, RatedAt: ratedAt(m.RatedAt), Type: "movie", Movie: &found})
+		resp.Added.Movies++
+	}
+	for _, e := range item.Episodes {
+		show, ep := s.findEpisode(e.IDs)
+		if ep == nil {
+			resp.NotFound.Episodes = append(resp.NotFound.Episodes, trakt.Episode{IDs: e.IDs})
+			continue
+		}
+		foundShow, foundEp := *show, *ep
+		s.setRating(trakt.Rating{Rating: e.Rating, RatedAt: ratedAt(e.RatedAt), Type: "episode", Show: &foundShow, Episode: &foundEp})
+		resp.Added.Episodes++
+	}
+	for _, sh := range item.Shows {
+		show := s.findShowByIDs(sh.IDs)
+		if show == nil {
+			resp.NotFound.Shows = append(resp.NotFound.Shows, trakt.Show{IDs: sh.IDs})
+			continue
+		}
+		found := show.Show
+		s.setRating(trakt.Rating{Rating: sh.Rating, RatedAt: ratedAt(sh.RatedAt), Type: "show", Show: &found})
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// setRating adds a rating, replacing any of the same item. The caller
+// holds s.mu.
+func (s *Server) setRating(rt trakt.Rating) {
+	for i, old := range s.ratings {
+		if old.Type == rt.Type && ratedID(old) == ratedID(rt) {
+			s.ratings[i] = rt
+			return
+		}
+	}
+	s.ratings = append(s.ratings, rt)
+}
+
+// ratedID is the Trakt ID of what a rating rates.
+func ratedID(rt trakt.Rating) int {
+	switch {
+	case rt.Episode != nil:
+		return rt.Episode.IDs.Trakt
+	case rt.Movie != nil:
+		return rt.Movie.IDs.Trakt
+	case rt.Show != nil:
+		return rt.Show.IDs.Trakt
+	}
+	return 0
+}
+
+func (s *Server) getWatchlist(w http.ResponseWriter, r *http.Request) {
+	kind := strings.TrimSuffix(r.PathValue("type"), "s")
+	s.mu.Lock()
+	var items []trakt.WatchlistItem
+	for _, it := range s.watchlist {
+		if kind == "" || it.Type == kind {
+			items = append(items, it)
+		}
+	}
+	s.mu.Unlock()
+	paginate(w, r, items)
+}
+
+func (s *Server) addWatchlist(w http.ResponseWriter, r *http.Request) {
+	var items trakt.SyncItems
+	if !readJSON(w, r, &items) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var resp trakt.SyncResponse
+	for _, m := range items.Movies {
+		movie := s.findMovie(m.IDs)
+		if movie == nil {
+			resp.NotFound.Movies = append(resp.NotFound.Movies, m)
+			continue
+		}
+		found := *movie
+		s.watchlist = append(s.watchlist, trakt.WatchlistItem{Rank: len(s.watchlist) + 1, ListedAt: s.now().UTC(), Type: "movie", Movie: &found})
+		resp.Added.Movies++
+	}
+	for _, sh := range items.Shows {
+		show := s.findShowByIDs(sh.IDs)
+		if show == nil {
+			resp.NotFound.Shows = append(resp.NotFound.Shows, trakt.Show{IDs: sh.IDs})
+			continue
+		}
+		found := show.Show
+		s.watchlist = append(s.watchlist, trakt.WatchlistItem{Rank: len(s.watchlist) + 1, ListedAt: s.now().UTC(), Type: "show", Show: &found})
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// scrobble answers a scrobble, logging a stop past 80% as a watch.
+func (s *Server) scrobble(w http.ResponseWriter, r *http.Request) {
+	var sc trakt.Scrobble
+	if !readJSON(w, r, &sc) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := trakt.ScrobbleResponse{ID: s.nextID, Action: r.PathValue("action"), Progress: sc.Progress}
+	item := trakt.HistoryItem{ID: s.nextID, WatchedAt: s.now().UTC().Truncate(time.Second), Action: "scrobble"}
+	switch {
+	case sc.Movie != nil:
+		movie := s.findMovie(sc.Movie.IDs)
+		if movie == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		found := *movie
+		resp.Movie, item.Movie, item.Type = &found, &found, "movie"
+	case sc.Episode != nil:
+		show, ep := s.findEpisode(sc.Episode.IDs)
+		if ep == nil && sc.Show != nil {
+			if sh := s.findShowByIDs(sc.Show.IDs); sh != nil {
+				show, ep = sh.episode(sc.Episode.Season, sc.Episode.Number)
+			}
+		}
+		if ep == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		foundShow, foundEp := *show, *ep
+		resp.Show, resp.Episode = &foundShow, &foundEp
+		item.Show, item.Episode, item.Type = &foundShow, &foundEp, "episode"
+	default:
+		http.Error(w, "nothing to scrobble", http.StatusBadRequest)
+		return
+	}
+	if resp.Action == "stop" {
+		resp.Action = "pause"
+		if sc.Progress >= 80 {
+			resp.Action = "scrobble"
+			s.history = append(s.history, item)
+		}
+	}
+	s.nextID++
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) deviceCode(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, trakt.DeviceCode{
+		DeviceCode:      DeviceCode,
+		UserCode:        UserCode,
+		VerificationURL: "https://trakt.tv/activate",
+		ExpiresIn:       600,
+		Interval:        1,
+	})
+}
+
+func (s *Server) deviceToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Code     string `json:"code"`
+		ClientID string `json:"client_id"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Code != DeviceCode || body.ClientID != ClientID {
+		w.WriteHeader(http.StatusNotFound) // invalid device code
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending > 0 {
+		s.pending--
+		w.WriteHeader(http.StatusBadRequest) // authorization pending
+		return
+	}
+	writeJSON(w, http.StatusOK, s.issue())
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		GrantType    string `json:"grant_type"`
+		RefreshToken string `json:"refresh_token"`
+		Code         string `json:"code"`
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	valid := body.ClientID == ClientID && body.ClientSecret == ClientSecret
+	switch body.GrantType {
+	case "refresh_token":
+		valid = valid && s.refresh[body.RefreshToken]
+	case "authorization_code":
+		valid = valid && body.Code != ""
+	default:
+		valid = false
+	}
+	if !valid {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.issue())
+}
+
+// issue makes a new pair of tokens the server accepts. The caller holds
+// s.mu.
+func (s *Server) issue() trakt.Token {
+	s.issued++
+	token := trakt.Token{
+		AccessToken:  fmt.Sprintf("%s-%d", AccessToken, s.issued),
+		TokenType:    "bearer",
+		ExpiresIn:    7776000,
+		RefreshToken: fmt.Sprintf("%s-%d", RefreshToken, s.issued),
+		Scope:        "public",
+		CreatedAt:    s.now().Unix(),
+	}
+	s.tokens[token.AccessToken] = true
+	s.refresh[token.RefreshToken] = true
+	return token
+}
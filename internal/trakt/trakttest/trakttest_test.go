@@ -0,0 +1,208 @@
+package trakttest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kofifort/trakt-mcp-go/internal/trakt"
+	"github.com/kofifort/trakt-mcp-go/internal/trakt/trakttest"
+)
+
+func TestServer_Search(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	ctx := context.Background()
+
+	results, err := srv.Client().Search(ctx, "dune", "movie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Movie == nil || results[0].Movie.IDs.Trakt != 287071 || results[0].Score < 1000 {
+		t.Fatalf("expected Dune as an exact match, got %+v", results)
+	}
+
+	results, err = srv.Client().LookupID(ctx, "tvdb", "81189", "show")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Show == nil || results[0].Show.Title != "Breaking Bad" {
+		t.Fatalf("expected Breaking Bad by its TVDB ID, got %+v", results)
+	}
+}
+
+func TestServer_History(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	client := srv.Client()
+	ctx := context.Background()
+
+	resp, err := client.AddToHistory(ctx, trakt.WatchedItem{
+		WatchedAt: "2026-03-01T20:00:00Z",
+		Movies:    []trakt.Movie{{IDs: trakt.MovieIDs{TMDB: 438631}}, {IDs: trakt.MovieIDs{Trakt: 1}}},
+		Episodes:  []trakt.Episode{{IDs: trakt.EpisodeIDs{Trakt: 73482}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Added.Movies != 1 || resp.Added.Episodes != 1 || len(resp.NotFound.Movies) != 1 {
+		t.Fatalf("unexpected sync response %+v", resp)
+	}
+
+	history, err := client.GetHistory(ctx, "movies", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Movie.Title != "Dune" || !history[0].WatchedAt.Equal(time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the Dune play, got %+v", history)
+	}
+
+	if _, err := client.RemoveFromHistory(ctx, trakt.WatchedItem{HistoryIDs: []int64{history[0].ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.History(); len(got) != 1 || got[0].Episode == nil || got[0].Episode.Title != "Pilot" {
+		t.Errorf("expected only the episode left, got %+v", got)
+	}
+}
+
+func TestServer_Paginates(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	_, movies := trakttest.Catalog()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		movie := movies[i%len(movies)]
+		srv.AddHistory(trakt.HistoryItem{WatchedAt: start.AddDate(0, 0, i), Action: "watch", Type: "movie", Movie: &movie})
+	}
+
+	page, err := srv.Client().GetHistoryPage(context.Background(), "", 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Pagination.PageCount != 3 || page.Pagination.ItemCount != 5 {
+		t.Fatalf("unexpected pagination %+v", page.Pagination)
+	}
+	if len(page.Items) != 2 || !page.Items[0].WatchedAt.Equal(start.AddDate(0, 0, 2)) {
+		t.Errorf("expected the third and fourth newest plays, got %+v", page.Items)
+	}
+}
+
+func TestServer_Scrobble(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	client := srv.Client()
+	ctx := context.Background()
+	show := &trakt.Show{IDs: trakt.ShowIDs{Trakt: 154997}}
+	episode := &trakt.Episode{Season: 1, Number: 2}
+
+	resp, err := client.ScrobbleStop(ctx, trakt.Scrobble{Show: show, Episode: episode, Progress: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Action != "pause" || len(srv.History()) != 0 {
+		t.Fatalf("expected a stop at 50%% kept as paused, got %+v", resp)
+	}
+
+	resp, err = client.ScrobbleStop(ctx, trakt.Scrobble{Show: show, Episode: episode, Progress: 92})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Action != "scrobble" || resp.Episode == nil || resp.Episode.Title != "Half Loop" {
+		t.Fatalf("expected Half Loop scrobbled, got %+v", resp)
+	}
+	if got := srv.History(); len(got) != 1 || got[0].Action != "scrobble" {
+		t.Errorf("expected the scrobble in history, got %+v", got)
+	}
+}
+
+func TestServer_Ratings(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	client := srv.Client()
+	ctx := context.Background()
+
+	for _, rating := range []int{7, 9} {
+		if _, err := client.AddRatings(ctx, trakt.RatingItem{Movies: []trakt.RatedMovie{{Rating: rating, IDs: trakt.MovieIDs{IMDB: "tt2543164"}}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ratings := srv.Ratings()
+	if len(ratings) != 1 || ratings[0].Rating != 9 || ratings[0].Movie.Title != "Arrival" {
+		t.Errorf("expected the later rating to replace the first, got %+v", ratings)
+	}
+}
+
+func TestServer_RequiresAuth(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	client := trakt.NewClient(trakt.Config{ClientID: trakttest.ClientID, APIURL: srv.URL}, nil)
+
+	_, err := client.GetHistory(context.Background(), "", 10)
+	var apiErr *trakt.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %v", err)
+	}
+
+	client = trakt.NewClient(trakt.Config{ClientID: "someone-else", APIURL: srv.URL}, nil)
+	if _, err := client.Search(context.Background(), "dune", ""); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for an unknown API key, got %v", err)
+	}
+}
+
+func TestServer_RefreshesExpiredTokens(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	client := srv.Client()
+	srv.Expire(trakttest.AccessToken)
+
+	if _, err := client.GetHistory(context.Background(), "", 10); err != nil {
+		t.Fatalf("expected the client to refresh its token, got %v", err)
+	}
+	var refreshed bool
+	for _, r := range srv.Requests() {
+		refreshed = refreshed || r.Path == "/oauth/token"
+	}
+	if !refreshed {
+		t.Errorf("expected a refresh, got requests %+v", srv.Requests())
+	}
+}
+
+func TestServer_DeviceFlow(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	client := trakt.NewClient(trakt.Config{ClientID: trakttest.ClientID, ClientSecret: trakttest.ClientSecret, APIURL: srv.URL}, nil)
+	ctx := context.Background()
+	srv.SetPendingPolls(1)
+
+	code, err := client.GetDeviceCode(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.UserCode != trakttest.UserCode {
+		t.Errorf("user code = %q, want %q", code.UserCode, trakttest.UserCode)
+	}
+	if _, err := client.PollForToken(ctx, code.DeviceCode); !errors.Is(err, trakt.ErrAuthorizationPending) {
+		t.Fatalf("expected the first poll pending, got %v", err)
+	}
+	token, err := client.PollForToken(ctx, code.DeviceCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.SetToken(token)
+	if _, err := client.GetHistory(ctx, "", 10); err != nil {
+		t.Errorf("expected the issued token accepted, got %v", err)
+	}
+}
+
+func TestServer_Fail(t *testing.T) {
+	srv := trakttest.NewServer(t)
+	srv.Fail(http.MethodPost, "/sync/history", http.StatusBadGateway)
+	client := srv.Client()
+	item := trakt.WatchedItem{Movies: []trakt.Movie{{IDs: trakt.MovieIDs{Trakt: 287071}}}}
+
+	var apiErr *trakt.APIError
+	if _, err := client.AddToHistory(context.Background(), item); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected the injected 502, got %v", err)
+	}
+	if _, err := client.AddToHistory(context.Background(), item); err != nil {
+		t.Fatalf("expected the fault used up, got %v", err)
+	}
+	if got := srv.History(); len(got) != 1 {
+		t.Errorf("expected one play logged, got %+v", got)
+	}
+}
@@ -1,13 +1,31 @@
 // Package trakt provides a client for the Trakt.tv API.
 package trakt
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Show represents a TV show from Trakt.
 type Show struct {
-	Title string   `json:"title"`
-	Year  int      `json:"year"`
-	IDs   ShowIDs  `json:"ids"`
+	Title string  `json:"title"`
+	Year  int     `json:"year"`
+	IDs   ShowIDs `json:"ids"`
+
+	// Populated with extended=full
+	Overview   string     `json:"overview,omitempty"`
+	Runtime    int        `json:"runtime,omitempty"` // minutes per episode
+	Status     string     `json:"status,omitempty"`  // e.g. "returning series", "ended"
+	FirstAired *time.Time `json:"first_aired,omitempty"`
+	Rating     float64    `json:"rating,omitempty"`
+	Votes      int        `json:"votes,omitempty"`
+	Genres     []string   `json:"genres,omitempty"`
+	// AiredEpisodes counts the regular episodes aired so far, leaving out specials
+	AiredEpisodes int `json:"aired_episodes,omitempty"`
+
+	// Populated with extended=images
+	Images *Images `json:"images,omitempty"`
 }
 
 // ShowIDs contains various IDs for a show.
@@ -24,6 +42,19 @@ type Movie struct {
 	Title string   `json:"title"`
 	Year  int      `json:"year"`
 	IDs   MovieIDs `json:"ids"`
+
+	// Populated with extended=full
+	Overview string   `json:"overview,omitempty"`
+	Tagline  string   `json:"tagline,omitempty"`
+	Runtime  int      `json:"runtime,omitempty"`  // minutes
+	Status   string   `json:"status,omitempty"`   // e.g. "released", "in production"
+	Released string   `json:"released,omitempty"` // YYYY-MM-DD
+	Rating   float64  `json:"rating,omitempty"`
+	Votes    int      `json:"votes,omitempty"`
+	Genres   []string `json:"genres,omitempty"`
+
+	// Populated with extended=images
+	Images *Images `json:"images,omitempty"`
 }
 
 // MovieIDs contains various IDs for a movie.
@@ -36,10 +67,143 @@ type MovieIDs struct {
 
 // Episode represents a TV episode from Trakt.
 type Episode struct {
-	Season  int        `json:"season"`
-	Number  int        `json:"number"`
-	Title   string     `json:"title"`
-	IDs     EpisodeIDs `json:"ids"`
+	Season int        `json:"season"`
+	Number int        `json:"number"`
+	Title  string     `json:"title"`
+	IDs    EpisodeIDs `json:"ids"`
+
+	// Populated with extended=full
+	NumberAbs  int        `json:"number_abs,omitempty"` // absolute number across seasons, mostly set for anime
+	Overview   string     `json:"overview,omitempty"`
+	Runtime    int        `json:"runtime,omitempty"` // minutes
+	FirstAired *time.Time `json:"first_aired,omitempty"`
+	Rating     float64    `json:"rating,omitempty"`
+	Votes      int        `json:"votes,omitempty"`
+
+	// Populated with extended=images
+	Images *Images `json:"images,omitempty"`
+}
+
+// Alias is another title a show or movie is known by, such as a
+// translated or romanized title.
+type Alias struct {
+	Title   string `json:"title"`
+	Country string `json:"country"` // ISO 3166-1 alpha-2, e.g. "jp"
+}
+
+// Season is a season of a show. Season 0 holds the specials.
+type Season struct {
+	Number int       `json:"number"`
+	IDs    SeasonIDs `json:"ids"`
+
+	// Populated with extended=full
+	Title         string `json:"title,omitempty"`
+	EpisodeCount  int    `json:"episode_count,omitempty"`
+	AiredEpisodes int    `json:"aired_episodes,omitempty"`
+
+	// Populated with extended=episodes
+	Episodes []Episode `json:"episodes,omitempty"`
+}
+
+// SeasonIDs contains various IDs for a season.
+type SeasonIDs struct {
+	Trakt int `json:"trakt"`
+	TVDB  int `json:"tvdb"`
+	TMDB  int `json:"tmdb"`
+}
+
+// Images holds artwork URLs. Trakt returns them without a scheme, e.g.
+// "walter-r2.trakt.tv/images/shows/000/001/388/posters/medium/abc.jpg.webp".
+type Images struct {
+	Poster     []string `json:"poster,omitempty"`
+	Fanart     []string `json:"fanart,omitempty"`
+	Logo       []string `json:"logo,omitempty"`
+	Banner     []string `json:"banner,omitempty"`
+	Thumb      []string `json:"thumb,omitempty"`
+	Screenshot []string `json:"screenshot,omitempty"`
+}
+
+// PosterURL returns the first poster as an https URL, or "" if there is none.
+func (i *Images) PosterURL() string {
+	if i == nil {
+		return ""
+	}
+	return imageURL(i.Poster)
+}
+
+// FanartURL returns the first fanart, a wide background image, as an
+// https URL, or "" if there is none.
+func (i *Images) FanartURL() string {
+	if i == nil {
+		return ""
+	}
+	return imageURL(i.Fanart)
+}
+
+// LogoURL returns the first logo as an https URL, or "" if there is none.
+func (i *Images) LogoURL() string {
+	if i == nil {
+		return ""
+	}
+	return imageURL(i.Logo)
+}
+
+// ThumbURL returns the first thumbnail (or screenshot, for episodes) as an
+// https URL, or "" if there is none.
+func (i *Images) ThumbURL() string {
+	if i == nil {
+		return ""
+	}
+	if u := imageURL(i.Thumb); u != "" {
+		return u
+	}
+	return imageURL(i.Screenshot)
+}
+
+func imageURL(paths []string) string {
+	if len(paths) == 0 || paths[0] == "" {
+		return ""
+	}
+	if strings.HasPrefix(paths[0], "http://") || strings.HasPrefix(paths[0], "https://") {
+		return paths[0]
+	}
+	return "https://" + paths[0]
+}
+
+// SiteURL is the Trakt website, which show and movie links point to.
+const SiteURL = "https://trakt.tv"
+
+// URL returns the show's page on trakt.tv, or "" if it has no IDs.
+func (s *Show) URL() string {
+	return siteURL("shows", s.IDs.Slug, s.IDs.Trakt)
+}
+
+// EpisodeURL returns the page of one of the show's episodes on trakt.tv,
+// or "" if the show has no IDs.
+func (s *Show) EpisodeURL(season, number int) string {
+	u := s.URL()
+	if u == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/seasons/%d/episodes/%d", u, season, number)
+}
+
+// URL returns the movie's page on trakt.tv, or "" if it has no IDs.
+func (m *Movie) URL() string {
+	return siteURL("movies", m.IDs.Slug, m.IDs.Trakt)
+}
+
+// siteURL links to an item by slug, falling back to its Trakt ID, which
+// the site also accepts.
+func siteURL(kind, slug string, traktID int) string {
+	switch {
+	case slug != "":
+		return fmt.Sprintf("%s/%s/%s", SiteURL, kind, slug)
+	case traktID != 0:
+		return fmt.Sprintf("%s/%s/%d", SiteURL, kind, traktID)
+	default:
+		return ""
+	}
 }
 
 // EpisodeIDs contains various IDs for an episode.
@@ -50,12 +214,41 @@ type EpisodeIDs struct {
 	TMDB  int    `json:"tmdb"`
 }
 
-// SearchResult represents a search result from Trakt.
+// Person represents a cast or crew member from Trakt.
+type Person struct {
+	Name string    `json:"name"`
+	IDs  PersonIDs `json:"ids"`
+
+	// Populated with extended=full
+	Biography          string `json:"biography,omitempty"`
+	Birthday           string `json:"birthday,omitempty"` // YYYY-MM-DD
+	Death              string `json:"death,omitempty"`    // YYYY-MM-DD
+	Birthplace         string `json:"birthplace,omitempty"`
+	KnownForDepartment string `json:"known_for_department,omitempty"` // e.g. "acting", "directing"
+}
+
+// PersonIDs contains various IDs for a person.
+type PersonIDs struct {
+	Trakt int    `json:"trakt"`
+	Slug  string `json:"slug"`
+	IMDB  string `json:"imdb"`
+	TMDB  int    `json:"tmdb"`
+}
+
+// URL returns the person's page on trakt.tv, or "" if they have no IDs.
+func (p *Person) URL() string {
+	return siteURL("people", p.IDs.Slug, p.IDs.Trakt)
+}
+
+// SearchResult represents a search result from Trakt. An episode result
+// sets both Episode and the Show it belongs to.
 type SearchResult struct {
-	Type  string  `json:"type"` // "show", "movie", "episode"
-	Score float64 `json:"score"`
-	Show  *Show   `json:"show,omitempty"`
-	Movie *Movie  `json:"movie,omitempty"`
+	Type    string   `json:"type"` // "show", "movie", "episode", "person"
+	Score   float64  `json:"score"`
+	Show    *Show    `json:"show,omitempty"`
+	Movie   *Movie   `json:"movie,omitempty"`
+	Episode *Episode `json:"episode,omitempty"`
+	Person  *Person  `json:"person,omitempty"`
 }
 
 // HistoryItem represents an item in the watch history.
@@ -69,12 +262,263 @@ type HistoryItem struct {
 	Movie     *Movie    `json:"movie,omitempty"`
 }
 
-// WatchedItem represents an item to sync as watched.
+// HistoryPage is one page of watch history.
+type HistoryPage struct {
+	Items      []HistoryItem
+	Pagination Pagination
+}
+
+// Pagination describes a page of results, from Trakt's X-Pagination-* headers.
+type Pagination struct {
+	Page      int
+	Limit     int
+	PageCount int
+	ItemCount int
+}
+
+// WatchedEntry is a movie or show from the user's watched list. Shows carry
+// per-episode play counts grouped by season.
+type WatchedEntry struct {
+	Plays         int             `json:"plays"`
+	LastWatchedAt time.Time       `json:"last_watched_at"`
+	LastUpdatedAt time.Time       `json:"last_updated_at"`
+	Show          *Show           `json:"show,omitempty"`
+	Movie         *Movie          `json:"movie,omitempty"`
+	Seasons       []WatchedSeason `json:"seasons,omitempty"`
+}
+
+// WatchedSeason lists the watched episodes of one season.
+type WatchedSeason struct {
+	Number   int              `json:"number"`
+	Episodes []WatchedEpisode `json:"episodes"`
+}
+
+// WatchedEpisode is the play count of one watched episode.
+type WatchedEpisode struct {
+	Number        int       `json:"number"`
+	Plays         int       `json:"plays"`
+	LastWatchedAt time.Time `json:"last_watched_at"`
+}
+
+// CollectionEntry is a movie or show in the user's collection. Movies set
+// CollectedAt; shows set LastCollectedAt and list their collected episodes.
+type CollectionEntry struct {
+	CollectedAt     time.Time         `json:"collected_at"`
+	LastCollectedAt time.Time         `json:"last_collected_at"`
+	Show            *Show             `json:"show,omitempty"`
+	Movie           *Movie            `json:"movie,omitempty"`
+	Seasons         []CollectedSeason `json:"seasons,omitempty"`
+}
+
+// CollectedSeason lists the collected episodes of one season.
+type CollectedSeason struct {
+	Number   int                `json:"number"`
+	Episodes []CollectedEpisode `json:"episodes"`
+}
+
+// CollectedEpisode is one collected episode.
+type CollectedEpisode struct {
+	Number      int       `json:"number"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// WatchlistItem represents an item on the user's watchlist.
+type WatchlistItem struct {
+	Rank     int       `json:"rank"`
+	ListedAt time.Time `json:"listed_at"`
+	Type     string    `json:"type"` // "show", "movie", "season", "episode"
+	Show     *Show     `json:"show,omitempty"`
+	Movie    *Movie    `json:"movie,omitempty"`
+	Episode  *Episode  `json:"episode,omitempty"`
+}
+
+// List is one of the user's personal lists.
+type List struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Privacy     string  `json:"privacy,omitempty"` // "private", "friends", "public"
+	ItemCount   int     `json:"item_count,omitempty"`
+	IDs         ListIDs `json:"ids"`
+}
+
+// ListIDs identifies a personal list.
+type ListIDs struct {
+	Trakt int    `json:"trakt"`
+	Slug  string `json:"slug"`
+}
+
+// ListItem is an entry on a personal list.
+type ListItem struct {
+	Rank     int       `json:"rank"`
+	ListedAt time.Time `json:"listed_at"`
+	Type     string    `json:"type"` // "show", "movie", "season", "episode", "person"
+	Show     *Show     `json:"show,omitempty"`
+	Movie    *Movie    `json:"movie,omitempty"`
+	Episode  *Episode  `json:"episode,omitempty"`
+}
+
+// LastActivities reports when each part of the user's account last
+// changed, so a sync can skip the parts that haven't.
+type LastActivities struct {
+	All      time.Time      `json:"all"`
+	Movies   ItemActivities `json:"movies"`
+	Episodes ItemActivities `json:"episodes"`
+	Shows    ItemActivities `json:"shows"`
+	Seasons  ItemActivities `json:"seasons"`
+}
+
+// ItemActivities are the last changes to one kind of item. Times an item
+// kind doesn't have, such as a show's watched_at, are zero.
+type ItemActivities struct {
+	WatchedAt     time.Time `json:"watched_at"`
+	CollectedAt   time.Time `json:"collected_at"`
+	RatedAt       time.Time `json:"rated_at"`
+	WatchlistedAt time.Time `json:"watchlisted_at"`
+}
+
+// CalendarEntry is an episode airing on the user's calendar.
+type CalendarEntry struct {
+	FirstAired time.Time `json:"first_aired"`
+	Episode    *Episode  `json:"episode"`
+	Show       *Show     `json:"show"`
+}
+
+// MovieCalendarEntry is a movie release on the user's movie calendar.
+type MovieCalendarEntry struct {
+	Released string `json:"released"` // YYYY-MM-DD
+	Movie    *Movie `json:"movie"`
+}
+
+// ShowProgress represents the user's watched progress for a show.
+type ShowProgress struct {
+	Aired         int              `json:"aired"`
+	Completed     int              `json:"completed"`
+	LastWatchedAt *time.Time       `json:"last_watched_at,omitempty"`
+	NextEpisode   *Episode         `json:"next_episode,omitempty"`
+	LastEpisode   *Episode         `json:"last_episode,omitempty"`
+	Seasons       []SeasonProgress `json:"seasons,omitempty"`
+}
+
+// SeasonProgress is the user's watched progress for one season.
+type SeasonProgress struct {
+	Number    int               `json:"number"`
+	Aired     int               `json:"aired"`
+	Completed int               `json:"completed"`
+	Episodes  []EpisodeProgress `json:"episodes,omitempty"`
+}
+
+// EpisodeProgress reports whether the user has watched an episode.
+type EpisodeProgress struct {
+	Number        int        `json:"number"`
+	Completed     bool       `json:"completed"`
+	LastWatchedAt *time.Time `json:"last_watched_at,omitempty"`
+}
+
+// Watched reports whether the progress marks an episode as watched.
+func (p *ShowProgress) Watched(season, number int) bool {
+	for _, s := range p.Seasons {
+		if s.Number != season {
+			continue
+		}
+		for _, e := range s.Episodes {
+			if e.Number == number {
+				return e.Completed
+			}
+		}
+	}
+	return false
+}
+
+// HiddenItem is a show, movie or season the user hid from a section of
+// Trakt, such as the calendar or progress.
+type HiddenItem struct {
+	HiddenAt time.Time `json:"hidden_at"`
+	Type     string    `json:"type"` // "show", "movie", "season"
+	Show     *Show     `json:"show,omitempty"`
+	Movie    *Movie    `json:"movie,omitempty"`
+}
+
+// WatchedItem represents an item to sync as watched, or to remove from
+// history. Removal can also name history entries by HistoryIDs.
 type WatchedItem struct {
-	WatchedAt string    `json:"watched_at,omitempty"` // ISO 8601
-	Movies    []Movie   `json:"movies,omitempty"`
-	Shows     []Show    `json:"shows,omitempty"`
-	Episodes  []Episode `json:"episodes,omitempty"`
+	WatchedAt  string    `json:"watched_at,omitempty"` // ISO 8601
+	Movies     []Movie   `json:"movies,omitempty"`
+	Shows      []Show    `json:"shows,omitempty"`
+	Episodes   []Episode `json:"episodes,omitempty"`
+	HistoryIDs []int64   `json:"ids,omitempty"` // removal only
+}
+
+// RatingItem represents ratings to sync. Ratings run from 1 to 10.
+type RatingItem struct {
+	Movies   []RatedMovie   `json:"movies,omitempty"`
+	Shows    []RatedShow    `json:"shows,omitempty"`
+	Episodes []RatedEpisode `json:"episodes,omitempty"`
+}
+
+// RatedMovie is a rating of a movie.
+type RatedMovie struct {
+	Rating  int      `json:"rating"`
+	RatedAt string   `json:"rated_at,omitempty"` // ISO 8601; empty for now
+	IDs     MovieIDs `json:"ids"`
+}
+
+// RatedShow is a rating of a show as a whole.
+type RatedShow struct {
+	Rating  int     `json:"rating"`
+	RatedAt string  `json:"rated_at,omitempty"` // ISO 8601; empty for now
+	IDs     ShowIDs `json:"ids"`
+}
+
+// RatedEpisode is a rating of an episode.
+type RatedEpisode struct {
+	Rating  int        `json:"rating"`
+	RatedAt string     `json:"rated_at,omitempty"` // ISO 8601; empty for now
+	IDs     EpisodeIDs `json:"ids"`
+}
+
+// SyncItems are items to add to the watchlist, the collection or a
+// personal list.
+type SyncItems struct {
+	Movies   []Movie    `json:"movies,omitempty"`
+	Shows    []SyncShow `json:"shows,omitempty"`
+	Episodes []Episode  `json:"episodes,omitempty"`
+}
+
+// SyncShow is a show in SyncItems: the whole show, or only the given
+// episodes of it if Seasons is set.
+type SyncShow struct {
+	IDs     ShowIDs      `json:"ids"`
+	Seasons []SyncSeason `json:"seasons,omitempty"`
+}
+
+// SyncSeason names episodes of a SyncShow by season and number.
+type SyncSeason struct {
+	Number   int           `json:"number"`
+	Episodes []SyncEpisode `json:"episodes,omitempty"`
+}
+
+// Scrobble is an item the user is playing: a movie, an episode by its
+// IDs, or a show's episode by season and number.
+type Scrobble struct {
+	Movie    *Movie   `json:"movie,omitempty"`
+	Show     *Show    `json:"show,omitempty"`
+	Episode  *Episode `json:"episode,omitempty"`
+	Progress float64  `json:"progress"` // percent played, 0-100
+}
+
+// ScrobbleResponse is Trakt's record of a scrobble.
+type ScrobbleResponse struct {
+	ID       int64    `json:"id"`
+	Action   string   `json:"action"` // "start", "pause" or "scrobble"
+	Progress float64  `json:"progress"`
+	Movie    *Movie   `json:"movie,omitempty"`
+	Show     *Show    `json:"show,omitempty"`
+	Episode  *Episode `json:"episode,omitempty"`
+}
+
+// SyncEpisode is an episode of a SyncSeason.
+type SyncEpisode struct {
+	Number int `json:"number"`
 }
 
 // SyncResponse represents the response from a sync operation.
@@ -117,8 +561,12 @@ type Token struct {
 	CreatedAt    int64  `json:"created_at"`
 }
 
-// Rating represents a rating for content.
+// Rating represents a rating for content, as listed in a user's ratings.
 type Rating struct {
-	Rating   int       `json:"rating"` // 1-10
-	RatedAt  time.Time `json:"rated_at"`
+	Rating  int       `json:"rating"` // 1-10
+	RatedAt time.Time `json:"rated_at"`
+	Type    string    `json:"type,omitempty"` // "movie", "show", "season", "episode"
+	Show    *Show     `json:"show,omitempty"`
+	Movie   *Movie    `json:"movie,omitempty"`
+	Episode *Episode  `json:"episode,omitempty"`
 }
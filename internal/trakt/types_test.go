@@ -0,0 +1,76 @@
+package trakt
+
+import "testing"
+
+func TestImages_URLs(t *testing.T) {
+	tests := []struct {
+		name       string
+		images     *Images
+		wantPoster string
+		wantThumb  string
+	}{
+		{"nil", nil, "", ""},
+		{"empty", &Images{}, "", ""},
+		{
+			"schemeless",
+			&Images{Poster: []string{"walter-r2.trakt.tv/images/poster.jpg.webp"}, Thumb: []string{"walter-r2.trakt.tv/images/thumb.jpg.webp"}},
+			"https://walter-r2.trakt.tv/images/poster.jpg.webp",
+			"https://walter-r2.trakt.tv/images/thumb.jpg.webp",
+		},
+		{
+			"screenshot fallback",
+			&Images{Screenshot: []string{"https://example.com/shot.jpg"}},
+			"",
+			"https://example.com/shot.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.images.PosterURL(); got != tt.wantPoster {
+				t.Errorf("PosterURL() = %q, want %q", got, tt.wantPoster)
+			}
+			if got := tt.images.ThumbURL(); got != tt.wantThumb {
+				t.Errorf("ThumbURL() = %q, want %q", got, tt.wantThumb)
+			}
+		})
+	}
+}
+
+func TestSiteURLs(t *testing.T) {
+	show := &Show{IDs: ShowIDs{Trakt: 1388, Slug: "breaking-bad"}}
+	if got := show.URL(); got != "https://trakt.tv/shows/breaking-bad" {
+		t.Errorf("Show.URL() = %q", got)
+	}
+	movie := &Movie{IDs: MovieIDs{Trakt: 16662}}
+	if got := movie.URL(); got != "https://trakt.tv/movies/16662" {
+		t.Errorf("Movie.URL() without a slug = %q", got)
+	}
+	if got := show.EpisodeURL(1, 2); got != "https://trakt.tv/shows/breaking-bad/seasons/1/episodes/2" {
+		t.Errorf("Show.EpisodeURL() = %q", got)
+	}
+	if got := (&Movie{}).URL(); got != "" {
+		t.Errorf("Movie.URL() without IDs = %q", got)
+	}
+}
+
+func TestShowProgress_Watched(t *testing.T) {
+	p := &ShowProgress{Seasons: []SeasonProgress{
+		{Number: 1, Episodes: []EpisodeProgress{{Number: 1, Completed: true}, {Number: 2}}},
+	}}
+
+	tests := []struct {
+		season, number int
+		want           bool
+	}{
+		{1, 1, true},
+		{1, 2, false},
+		{1, 3, false},
+		{2, 1, false},
+	}
+	for _, tt := range tests {
+		if got := p.Watched(tt.season, tt.number); got != tt.want {
+			t.Errorf("Watched(%d, %d) = %v, want %v", tt.season, tt.number, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package webhook tells another system about changes made to the Trakt
+// account, such as a watch logged or a rating added, by posting each one
+// as JSON to a URL. With a secret, each post is signed so the receiver can
+// check it came from this server: the X-Trakt-MCP-Signature-256 header
+// holds "sha256=" and the hex HMAC-SHA256 of the body under the secret.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each attempt to deliver an event.
+const DefaultTimeout = 10 * time.Second
+
+// maxAttempts is how many times an event is posted before it's given up.
+const maxAttempts = 3
+
+// retryDelay is the wait before the second attempt, doubling after.
+const retryDelay = time.Second
+
+// SignatureHeader carries the signature of a signed post.
+const SignatureHeader = "X-Trakt-MCP-Signature-256"
+
+// Event is a change made to the account, as posted.
+type Event struct {
+	Event  string    `json:"event"` // such as "history.added"; see the Event constants
+	Time   time.Time `json:"time"`
+	Item   any       `json:"item"`             // what was sent to Trakt
+	Result any       `json:"result,omitempty"` // what Trakt said it changed
+}
+
+// The kinds of events.
+const (
+	HistoryAdded     = "history.added"
+	HistoryRemoved   = "history.removed"
+	HistoryScrobbled = "history.scrobbled"
+	RatingsAdded     = "ratings.added"
+	WatchlistAdded   = "watchlist.added"
+	CollectionAdded  = "collection.added"
+)
+
+// Sender posts events to a URL in the background.
+type Sender struct {
+	url        string
+	secret     string
+	logger     *slog.Logger
+	httpClient *http.Client
+	retryDelay time.Duration
+
+	wg sync.WaitGroup
+}
+
+// New returns a Sender posting to url, signing posts with secret unless
+// it's empty.
+func New(url, secret string, logger *slog.Logger) *Sender {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Sender{
+		url:        url,
+		secret:     secret,
+		logger:     logger,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		retryDelay: retryDelay,
+	}
+}
+
+// Send posts e without waiting for it to be delivered. Failed posts are
+// retried a few times and then logged.
+func (s *Sender) Send(e Event) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		s.logger.Warn("webhook event not sent", "event", e.Event, "error", err)
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		delay := s.retryDelay
+		for attempt := 1; ; attempt++ {
+			err := s.post(e.Event, body)
+			if err == nil {
+				return
+			}
+			if attempt == maxAttempts || !retryable(err) {
+				s.logger.Warn("webhook delivery failed", "event", e.Event, "attempts", attempt, "error", err)
+				return
+			}
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}()
+}
+
+// Wait waits for the events sent so far to be delivered or given up.
+func (s *Sender) Wait() {
+	s.wg.Wait()
+}
+
+// statusError is a post the receiver answered with an error status.
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("webhook: %d %s", int(e), http.StatusText(int(e)))
+}
+
+// retryable reports whether a failed post may succeed if tried again:
+// one that didn't get through, or that the receiver turned away as busy
+// or broken rather than as bad.
+func retryable(err error) bool {
+	code, ok := err.(statusError)
+	return !ok || code == http.StatusTooManyRequests || code >= 500
+}
+
+// post makes one attempt to deliver body.
+func (s *Sender) post(event string, body []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Trakt-MCP-Event", event)
+	if s.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.secret, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return statusError(resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the signature header value of body under secret, for
+// receivers to compare with the one they're sent.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSender(t *testing.T) {
+	var mu sync.Mutex
+	var bodies [][]byte
+	var signatures, events []string
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway) // retried
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get(SignatureHeader))
+		events = append(events, r.Header.Get("X-Trakt-MCP-Event"))
+	}))
+	defer srv.Close()
+
+	s := New(srv.URL, "s3cret", nil)
+	s.retryDelay = time.Millisecond
+	at := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	s.Send(Event{Event: RatingsAdded, Time: at, Item: map[string]int{"rating": 9}})
+	s.Wait()
+
+	if calls != 2 || len(bodies) != 1 {
+		t.Fatalf("expected one delivery after a retry, got %d calls", calls)
+	}
+	var got Event
+	if err := json.Unmarshal(bodies[0], &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Event != RatingsAdded || !got.Time.Equal(at) || events[0] != RatingsAdded {
+		t.Errorf("unexpected event %+v (header %q)", got, events[0])
+	}
+	if want := Sign("s3cret", bodies[0]); signatures[0] != want {
+		t.Errorf("signature = %q, want %q", signatures[0], want)
+	}
+}
+
+func TestSender_GivesUpOnClientErrors(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if r.Header.Get(SignatureHeader) != "" {
+			t.Error("expected no signature without a secret")
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	s := New(srv.URL, "", nil)
+	s.retryDelay = time.Millisecond
+	s.Send(Event{Event: HistoryAdded})
+	s.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected no retries after a 404, got %d calls", calls)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// From RFC 4231, test case 2
+	got := Sign("Jefe", []byte("what do ya want for nothing?"))
+	if want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"; got != want {
+		t.Errorf("Sign = %q, want %q", got, want)
+	}
+}